@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 
 	"go.uber.org/zap"
@@ -13,58 +12,187 @@ import (
 	"github.com/initia-labs/opinit-bots/challenger"
 	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
 	"github.com/initia-labs/opinit-bots/db"
+	"github.com/initia-labs/opinit-bots/db/migration"
 	"github.com/initia-labs/opinit-bots/executor"
 	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/logging"
+	"github.com/initia-labs/opinit-bots/node/metrics"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 func LoadJsonConfig(path string, config bottypes.Config) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	data, err := io.ReadAll(file)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
 	err = json.Unmarshal(data, config)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to parse the config %s: %w", path, err)
 	}
 
 	return nil
 }
 
-func NewBot(botType bottypes.BotType, logger *zap.Logger, homePath string, configPath string) (bottypes.Bot, error) {
+// LoadConfig loads the typed config of the bot, *executortypes.Config or *challengertypes.Config, from the json file.
+// The config is not validated.
+func LoadConfig(botType bottypes.BotType, path string) (bottypes.Config, error) {
+	var cfg bottypes.Config
+	switch botType {
+	case bottypes.BotTypeExecutor:
+		cfg = &executortypes.Config{}
+	case bottypes.BotTypeChallenger:
+		cfg = &challengertypes.Config{}
+	default:
+		return nil, fmt.Errorf("invalid bot type: %s", botType)
+	}
+
+	err := LoadJsonConfig(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ValidateConfig loads the config of the bot and returns all the problems of the config.
+func ValidateConfig(botType bottypes.BotType, path string) error {
+	cfg, err := LoadConfig(botType, path)
+	if err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// NewBot creates the bot with the logger built from the log config of the bot.
+// The log level is the default level of the logs overriding the config, if it is not empty.
+func NewBot(botType bottypes.BotType, logLevel string, homePath string, configPath string) (bottypes.Bot, error) {
 	err := botType.Validate()
 	if err != nil {
 		return nil, err
 	}
 
+	cfg, err := LoadConfig(botType, configPath)
+	if err != nil {
+		return nil, err
+	}
+	// report all the problems of the config before any component is created
+	err = cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s:\n%w", configPath, err)
+	}
+
 	db, err := db.NewDB(GetDBPath(homePath, botType))
 	if err != nil {
 		return nil, err
 	}
 
-	switch botType {
-	case bottypes.BotTypeExecutor:
-		cfg := &executortypes.Config{}
-		err := LoadJsonConfig(configPath, cfg)
+	switch cfg := cfg.(type) {
+	case *executortypes.Config:
+		logger, logLevels, err := newLogger(cfg.Log, logLevel)
 		if err != nil {
 			return nil, err
 		}
-		return executor.NewExecutor(cfg, db, logger.Named("executor"), homePath), nil
-	case bottypes.BotTypeChallenger:
-		cfg := &challengertypes.Config{}
-		err := LoadJsonConfig(configPath, cfg)
+		metrics.Init(cfg.Metrics)
+		if len(cfg.Bridges) > 0 {
+			for _, bridge := range cfg.Bridges {
+				err = migration.Init(executor.Schemas(executor.BridgeDB(db, bridge.ID))...)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return executor.NewMultiExecutor(cfg, db, logger.Named("executor"), logLevels, homePath)
+		}
+		err = migration.Init(executor.Schemas(db)...)
+		if err != nil {
+			return nil, err
+		}
+		ex, err := executor.NewExecutor(cfg, db, logger.Named("executor"), logLevels, homePath)
+		if err != nil {
+			return nil, err
+		}
+		return ex, nil
+	case *challengertypes.Config:
+		logger, logLevels, err := newLogger(cfg.Log, logLevel)
 		if err != nil {
 			return nil, err
 		}
-		return challenger.NewChallenger(cfg, db, logger.Named("challenger"), homePath), nil
+		metrics.Init(cfg.Metrics)
+		err = migration.Init(challenger.Schemas(db)...)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := challenger.NewChallenger(cfg, db, logger.Named("challenger"), logLevels, homePath)
+		if err != nil {
+			return nil, err
+		}
+		return ch, nil
 	}
 	return nil, errors.New("not providing bot name")
 }
 
+// newLogger builds the logger of the log config, where the log level overrides the default level if it is not empty.
+func newLogger(cfg logging.Config, logLevel string) (*zap.Logger, *logging.Levels, error) {
+	if logLevel != "" {
+		cfg.Level = logLevel
+	}
+	return logging.NewLogger(cfg)
+}
+
 func GetDBPath(homePath string, botName bottypes.BotType) string {
 	return fmt.Sprintf(homePath+"/%s.db", botName)
 }
+
+// MigrateDB applies the pending schema migrations of the db of the bot, including the dbs of the bridges
+// of the executor config. The bot must be stopped.
+func MigrateDB(botType bottypes.BotType, homePath string, configPath string, logger *zap.Logger) error {
+	err := botType.Validate()
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(botType, configPath)
+	if err != nil {
+		return err
+	}
+
+	db, err := db.NewDB(GetDBPath(homePath, botType))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch cfg := cfg.(type) {
+	case *executortypes.Config:
+		for _, bridge := range cfg.Bridges {
+			err = migration.Run(logger.With(zap.String("bridge", bridge.ID)), executor.Schemas(executor.BridgeDB(db, bridge.ID))...)
+			if err != nil {
+				return err
+			}
+		}
+		if len(cfg.Bridges) > 0 {
+			return nil
+		}
+		return migration.Run(logger, executor.Schemas(db)...)
+	case *challengertypes.Config:
+		return migration.Run(logger, challenger.Schemas(db)...)
+	}
+	return errors.New("unknown bot type")
+}
+
+// RestoreDB replaces the db of the bot with the snapshot, after checking that the snapshot is not
+// migrated by a newer bot. The bot must be stopped. It returns the path the replaced db is moved to.
+func RestoreDB(botType bottypes.BotType, homePath string, snapshotPath string) (string, error) {
+	err := botType.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	return db.RestoreSnapshot(snapshotPath, GetDBPath(homePath, botType), func(snapDB types.DB) error {
+		switch botType {
+		case bottypes.BotTypeExecutor:
+			return migration.Check(executor.Schemas(snapDB)...)
+		case bottypes.BotTypeChallenger:
+			return migration.Check(challenger.Schemas(snapDB)...)
+		}
+		return errors.New("unknown bot type")
+	})
+}
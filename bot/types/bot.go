@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"time"
 )
 
 type Bot interface {
@@ -9,3 +10,11 @@ type Bot interface {
 	Start(context.Context) error
 	Close()
 }
+
+// GracefulBot is the bot which flushes its state in order before it is stopped.
+type GracefulBot interface {
+	Bot
+	// Shutdown flushes the state of the bot and stops it by the stop function.
+	// Each stage of the shutdown is bounded by the stage timeout.
+	Shutdown(ctx context.Context, stageTimeout time.Duration, stop context.CancelFunc) error
+}
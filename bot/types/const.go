@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"path/filepath"
 )
 
 type BotType string
@@ -27,3 +28,8 @@ func BotTypeFromString(name string) BotType {
 	}
 	panic("unknown bot type")
 }
+
+// BackupDir returns the directory of the db snapshots of the bot in the home directory.
+func (b BotType) BackupDir(homePath string) string {
+	return filepath.Join(homePath, "backups", string(b))
+}
@@ -11,10 +11,15 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/initia-labs/opinit-bots/challenger/child"
 	"github.com/initia-labs/opinit-bots/challenger/host"
+	"github.com/initia-labs/opinit-bots/db/integrity"
+	"github.com/initia-labs/opinit-bots/logging"
 	"github.com/initia-labs/opinit-bots/server"
+	"github.com/initia-labs/opinit-bots/server/health"
 
 	bottypes "github.com/initia-labs/opinit-bots/bot/types"
 	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
 
 	"github.com/initia-labs/opinit-bots/types"
 	"go.uber.org/zap"
@@ -32,7 +37,10 @@ type Challenger struct {
 	cfg    *challengertypes.Config
 	db     types.DB
 	server *server.Server
+	health *health.Checker
 	logger *zap.Logger
+	// logLevels changes the levels of the component loggers at runtime
+	logLevels *logging.Levels
 
 	homePath string
 
@@ -46,30 +54,42 @@ type Challenger struct {
 	latestChallenges   []challengertypes.Challenge
 }
 
-func NewChallenger(cfg *challengertypes.Config, db types.DB, logger *zap.Logger, homePath string) *Challenger {
+func NewChallenger(cfg *challengertypes.Config, db types.DB, logger *zap.Logger, logLevels *logging.Levels, homePath string) (*Challenger, error) {
 	err := cfg.Validate()
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	h, err := host.NewHostV1(
+		cfg.L1NodeConfig(homePath),
+		db.WithPrefix([]byte(types.HostName)),
+		logger.Named(types.HostName),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the host")
+	}
+	ch, err := child.NewChildV1(
+		cfg.L2NodeConfig(homePath),
+		db.WithPrefix([]byte(types.ChildName)),
+		logger.Named(types.ChildName),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the child")
 	}
 
 	challengeCh := make(chan challengertypes.Challenge)
 	return &Challenger{
-		host: host.NewHostV1(
-			cfg.L1NodeConfig(homePath),
-			db.WithPrefix([]byte(types.HostName)),
-			logger.Named(types.HostName),
-		),
-		child: child.NewChildV1(
-			cfg.L2NodeConfig(homePath),
-			db.WithPrefix([]byte(types.ChildName)),
-			logger.Named(types.ChildName),
-		),
+		host:  h,
+		child: ch,
 
 		cfg:    cfg,
 		db:     db,
 		server: server.NewServer(cfg.Server),
+		health: health.NewChecker(cfg.Server.Health, db, logger.Named("health")),
 		logger: logger,
 
+		logLevels: logLevels,
+
 		homePath: homePath,
 
 		challengeCh:        challengeCh,
@@ -79,11 +99,18 @@ func NewChallenger(cfg *challengertypes.Config, db types.DB, logger *zap.Logger,
 
 		latestChallengesMu: &sync.Mutex{},
 		latestChallenges:   make([]challengertypes.Challenge, 0),
-	}
+	}, nil
 }
 
 func (c *Challenger) Initialize(ctx context.Context) error {
-	childBridgeInfo, err := c.child.QueryBridgeInfo(ctx)
+	if c.cfg.CheckDBIntegrity {
+		_, err := integrity.Run(c.db, c.logger, types.RepairDB(ctx), IntegrityChecks(c.db)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	childBridgeInfo, err := c.child.QueryBridgeInfo(ctx, 0)
 	if err != nil {
 		return err
 	}
@@ -91,7 +118,7 @@ func (c *Challenger) Initialize(ctx context.Context) error {
 		return errors.New("bridge info is not set")
 	}
 
-	bridgeInfo, err := c.host.QueryBridgeConfig(ctx, childBridgeInfo.BridgeId)
+	bridgeInfo, err := c.host.QueryBridgeConfig(ctx, childBridgeInfo.BridgeId, 0)
 	if err != nil {
 		return err
 	}
@@ -108,7 +135,25 @@ func (c *Challenger) Initialize(ctx context.Context) error {
 	}
 
 	var initialBlockTime time.Time
-	hostInitialBlockTime, err := c.host.Initialize(ctx, hostProcessedHeight, c.child, *bridgeInfo, c)
+	var expectedChainInfo *hostprovider.ExpectedChainInfo
+	if c.cfg.SkipChainVerification {
+		c.logger.Warn("skip verifying the chain id and the bridge of the l1 node")
+	} else {
+		expectedChainInfo = &hostprovider.ExpectedChainInfo{
+			L2ChainID:    c.cfg.L2Node.ChainID,
+			L2BridgeInfo: childBridgeInfo,
+		}
+	}
+
+	// the host signs MsgDeleteOutput with the challenger key of the bridge
+	var hostKeyringConfig *btypes.KeyringConfig
+	if c.cfg.DeleteOutput {
+		hostKeyringConfig = &btypes.KeyringConfig{
+			Address: bridgeInfo.BridgeConfig.Challenger,
+		}
+	}
+
+	hostInitialBlockTime, err := c.host.Initialize(ctx, hostProcessedHeight, c.child, *bridgeInfo, expectedChainInfo, c, hostKeyringConfig)
 	if err != nil {
 		return err
 	}
@@ -146,6 +191,11 @@ func (c *Challenger) Initialize(ctx context.Context) error {
 		return err
 	}
 
+	stats, err := c.GetDBStats()
+	if err != nil {
+		return err
+	}
+	c.logger.Info("db stats", zap.Any("stats", stats))
 	return nil
 }
 
@@ -181,10 +231,13 @@ func (c *Challenger) Start(ctx context.Context) error {
 
 	c.host.Start(ctx)
 	c.child.Start(ctx)
+	c.health.Start(ctx)
 	return errGrp.Wait()
 }
 
 func (c *Challenger) Close() {
+	_ = c.host.Node().Close()
+	_ = c.child.Node().Close()
 	c.db.Close()
 }
 
@@ -197,6 +250,20 @@ func (c *Challenger) RegisterQuerier() {
 
 		return ctx.JSON(status)
 	})
+	c.server.RegisterQuerier("/status/challenger", func(ctx *fiber.Ctx) error {
+		status, err := c.GetChallengerStatus()
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(status)
+	})
+	c.server.RegisterQuerier("/status/db", func(ctx *fiber.Ctx) error {
+		stats, err := c.GetDBStats()
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(stats)
+	})
 	c.server.RegisterQuerier("/challenges/:page", func(ctx *fiber.Ctx) error {
 		pageStr := ctx.Params("page")
 		if pageStr == "" {
@@ -228,13 +295,19 @@ func (c *Challenger) RegisterQuerier() {
 		}
 		return ctx.JSON(pendingEvents)
 	})
+	c.server.RegisterBackupHandler(c.db, bottypes.BotTypeChallenger.BackupDir(c.homePath))
+
+	c.health.AddNode(types.HostName, c.host.Node())
+	c.health.AddNode(types.ChildName, c.child.Node())
+	c.server.RegisterHealthHandlers(c.health)
+	c.server.RegisterLogLevelHandlers(c.logLevels)
 }
 
 func (c *Challenger) getProcessedHeights(ctx context.Context, bridgeId uint64) (l1ProcessedHeight int64, l2ProcessedHeight int64, processedOutputIndex uint64, err error) {
 	var outputL1BlockNumber int64
 	// get the last submitted output height before the start height from the host
 	if c.cfg.L2StartHeight != 0 {
-		output, err := c.host.QueryLastFinalizedOutput(ctx, bridgeId)
+		output, err := c.host.QueryLastFinalizedOutput(ctx, bridgeId, 0)
 		if err != nil {
 			return 0, 0, 0, err
 		} else if output != nil {
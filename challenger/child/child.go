@@ -48,12 +48,17 @@ type Child struct {
 func NewChildV1(
 	cfg nodetypes.NodeConfig,
 	db types.DB, logger *zap.Logger,
-) *Child {
+) (*Child, error) {
+	baseChild, err := childprovider.NewBaseChildV1(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Child{
-		BaseChild:    childprovider.NewBaseChildV1(cfg, db, logger),
+		BaseChild:    baseChild,
 		eventHandler: eventhandler.NewChallengeEventHandler(db, logger),
 		eventQueue:   make([]challengertypes.ChallengeEvent, 0),
-	}
+	}, nil
 }
 
 func (ch *Child) Initialize(
@@ -71,14 +76,22 @@ func (ch *Child) Initialize(
 		bridgeInfo,
 		nil,
 		nil,
+		nil,
 		true,
 	)
 	if err != nil {
 		return time.Time{}, err
 	}
+	err = ch.DeleteFutureWorkingTrees(processedHeight)
+	if err != nil {
+		return time.Time{}, err
+	}
 	ch.host = host
 	ch.challenger = challenger
-	ch.registerHandlers()
+	err = ch.registerHandlers()
+	if err != nil {
+		return time.Time{}, err
+	}
 
 	err = ch.eventHandler.Initialize(bridgeInfo.BridgeConfig.SubmissionInterval)
 	if err != nil {
@@ -97,12 +110,23 @@ func (ch *Child) Initialize(
 	return blockTime, nil
 }
 
-func (ch *Child) registerHandlers() {
-	ch.Node().RegisterBeginBlockHandler(ch.beginBlockHandler)
-	ch.Node().RegisterTxHandler(ch.txHandler)
-	ch.Node().RegisterEventHandler(opchildtypes.EventTypeFinalizeTokenDeposit, ch.finalizeDepositHandler)
-	ch.Node().RegisterEventHandler(opchildtypes.EventTypeInitiateTokenWithdrawal, ch.initiateWithdrawalHandler)
-	ch.Node().RegisterEndBlockHandler(ch.endBlockHandler)
+func (ch *Child) registerHandlers() error {
+	if err := ch.Node().RegisterBeginBlockHandler(ch.beginBlockHandler); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterTxHandler(ch.txHandler); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEventHandler(opchildtypes.EventTypeFinalizeTokenDeposit, ch.finalizeDepositHandler); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEventHandlerWithDedup(opchildtypes.EventTypeInitiateTokenWithdrawal, ch.initiateWithdrawalHandler, childprovider.InitiateWithdrawalKey); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEndBlockHandler(ch.endBlockHandler); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (ch *Child) PendingEventsToRawKV(events []challengertypes.ChallengeEvent, delete bool) ([]types.RawKV, error) {
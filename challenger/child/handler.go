@@ -3,10 +3,10 @@ package child
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
-	"github.com/initia-labs/opinit-bots/txutils"
 	"github.com/initia-labs/opinit-bots/types"
 
 	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
@@ -92,12 +92,12 @@ func (ch *Child) endBlockHandler(_ context.Context, args nodetypes.EndBlockArgs)
 
 	err = ch.DB().RawBatchSet(batchKVs...)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", nodetypes.ErrFatalDB, err)
 	}
 
 	ch.eventHandler.DeletePendingEvents(processedEvents)
 	ch.eventHandler.SetPendingEvents(timeoutEvents)
-	ch.challenger.SendPendingChallenges(challenges)
+	ch.challenger.SendPendingChallenges(pendingChallenges)
 	return nil
 }
 
@@ -106,15 +106,12 @@ func (ch *Child) txHandler(_ context.Context, args nodetypes.TxHandlerArgs) erro
 	if !args.Success {
 		return nil
 	}
-	txConfig := ch.Node().GetTxConfig()
-
-	tx, err := txutils.DecodeTx(txConfig, args.Tx)
+	msgs, err := ch.Node().DecodeTxMsgs(args.Tx)
 	if err != nil {
 		// if tx is not oracle tx, tx parse error is expected
 		// ignore decoding error
 		return nil
 	}
-	msgs := tx.GetMsgs()
 	if len(msgs) > 1 {
 		// we only expect one message for oracle tx
 		return nil
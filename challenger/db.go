@@ -85,31 +85,12 @@ func (c *Challenger) loadChallenges() (challenges []challengertypes.Challenge, e
 	return
 }
 
+// DeleteFutureChallenges deletes the challenges after the initial block time.
 func (c *Challenger) DeleteFutureChallenges(initialBlockTime time.Time) error {
-	deletingKeys := make([][]byte, 0)
-	iterErr := c.db.PrefixedReverseIterate(challengertypes.ChallengeKey, nil, func(key []byte, _ []byte) (stop bool, err error) {
-		ts, _, err := challengertypes.ParseChallenge(key)
-		if err != nil {
-			return true, err
-		}
-		if !ts.After(initialBlockTime) {
-			return true, nil
-		}
-
-		deletingKeys = append(deletingKeys, key)
-		return false, nil
-	})
-	if iterErr != nil {
-		return iterErr
-	}
-
-	for _, key := range deletingKeys {
-		err := c.db.Delete(key)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	// the challenges are ordered by the event time
+	start := challengertypes.PrefixedChallengeEventTime(initialBlockTime.Add(time.Nanosecond))
+	_, err := c.db.PrefixedDeleteRange(challengertypes.ChallengeKey, start, nil)
+	return err
 }
 
 func ResetHeights(db types.DB) error {
@@ -149,39 +130,11 @@ func ResetHeight(db types.DB, nodeName string) error {
 }
 
 func DeletePendingEvents(db types.DB) error {
-	deletingKeys := make([][]byte, 0)
-	iterErr := db.PrefixedIterate(challengertypes.PendingEventKey, nil, func(key []byte, _ []byte) (stop bool, err error) {
-		deletingKeys = append(deletingKeys, key)
-		return false, nil
-	})
-	if iterErr != nil {
-		return iterErr
-	}
-
-	for _, key := range deletingKeys {
-		err := db.Delete(key)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	_, err := db.PrefixedDeleteRange(challengertypes.PendingEventKey, nil, nil)
+	return err
 }
 
 func DeletePendingChallenges(db types.DB) error {
-	deletingKeys := make([][]byte, 0)
-	iterErr := db.PrefixedIterate(challengertypes.PendingChallengeKey, nil, func(key []byte, _ []byte) (stop bool, err error) {
-		deletingKeys = append(deletingKeys, key)
-		return false, nil
-	})
-	if iterErr != nil {
-		return iterErr
-	}
-
-	for _, key := range deletingKeys {
-		err := db.Delete(key)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	_, err := db.PrefixedDeleteRange(challengertypes.PendingChallengeKey, nil, nil)
+	return err
 }
@@ -30,6 +30,7 @@ func (ch *ChallengeEventHandler) CheckValue(events []challengertypes.ChallengeEv
 				Id:        event.Id(),
 				Log:       fmt.Sprintf("pending event does not match; expected: %s, got: %s", pendingEvent.String(), event.String()),
 				Time:      event.EventTime(),
+				Mismatch:  true,
 			})
 		} else {
 			ch.logger.Info("pending event matched", zap.String("event", pendingEvent.String()))
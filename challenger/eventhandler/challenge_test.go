@@ -0,0 +1,88 @@
+package eventhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
+	"github.com/initia-labs/opinit-bots/db"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func testOutput(outputIndex uint64, storageRoot byte) *challengertypes.Output {
+	blockHash := [32]byte{byte(outputIndex)}
+	outputRoot := ophosttypes.GenerateOutputRoot(1, []byte{storageRoot, 31: 0}, blockHash[:])
+	return challengertypes.NewOutput(int64(outputIndex*10), outputIndex, outputRoot[:], time.Unix(int64(outputIndex), 0))
+}
+
+func newTestEventHandler(t *testing.T) *ChallengeEventHandler {
+	h := NewChallengeEventHandler(db.NewMemDB(), zap.NewNop())
+	require.NoError(t, h.Initialize(time.Hour))
+	return h
+}
+
+func Test_CheckValueOutput(t *testing.T) {
+	h := newTestEventHandler(t)
+
+	// the outputs proposed on l1
+	h.SetPendingEvents([]challengertypes.ChallengeEvent{testOutput(1, 1), testOutput(2, 2)})
+
+	// the output root computed from the tree of the child matches
+	challenges, processed, err := h.CheckValue([]challengertypes.ChallengeEvent{testOutput(1, 1)})
+	require.NoError(t, err)
+	require.Empty(t, challenges)
+	require.Len(t, processed, 1)
+	require.Equal(t, challengertypes.ChallengeId{Type: challengertypes.EventTypeOutput, Id: 1}, processed[0].Id())
+	h.DeletePendingEvents(processed)
+
+	// the output root computed from the different storage root doesn't match
+	challenges, processed, err = h.CheckValue([]challengertypes.ChallengeEvent{testOutput(2, 3)})
+	require.NoError(t, err)
+	require.Len(t, challenges, 1)
+	require.True(t, challenges[0].Mismatch)
+	require.Equal(t, challengertypes.ChallengeId{Type: challengertypes.EventTypeOutput, Id: 2}, challenges[0].Id)
+	require.Len(t, processed, 1)
+	h.DeletePendingEvents(processed)
+	require.Empty(t, h.GetAllPendingEvents())
+}
+
+func Test_CheckValueDeferred(t *testing.T) {
+	h := newTestEventHandler(t)
+
+	// the child computes the output before the host syncs the proposal, so it tries later
+	_, _, err := h.CheckValue([]challengertypes.ChallengeEvent{testOutput(1, 1)})
+	require.ErrorIs(t, err, nodetypes.ErrIgnoreAndTryLater)
+
+	// the proposal waits for the child behind the l2 block number of the output
+	h.SetPendingEvents([]challengertypes.ChallengeEvent{testOutput(1, 1)})
+	challenges, processed, err := h.CheckValue(nil)
+	require.NoError(t, err)
+	require.Empty(t, challenges)
+	require.Empty(t, processed)
+	require.Len(t, h.GetAllPendingEvents(), 1)
+
+	challenges, timeoutEvents := h.CheckTimeout(time.Unix(1, 0).Add(time.Minute), h.GetAllPendingEvents())
+	require.Empty(t, challenges)
+	require.Empty(t, timeoutEvents)
+
+	challenges, processed, err = h.CheckValue([]challengertypes.ChallengeEvent{testOutput(1, 1)})
+	require.NoError(t, err)
+	require.Empty(t, challenges)
+	require.Len(t, processed, 1)
+}
+
+func Test_CheckTimeoutOutput(t *testing.T) {
+	h := newTestEventHandler(t)
+	h.SetPendingEvents([]challengertypes.ChallengeEvent{testOutput(1, 1)})
+
+	challenges, timeoutEvents := h.CheckTimeout(time.Unix(1, 0).Add(2*time.Hour), h.GetAllPendingEvents())
+	require.Len(t, challenges, 1)
+	require.False(t, challenges[0].Mismatch)
+	require.Len(t, timeoutEvents, 1)
+	require.True(t, timeoutEvents[0].IsTimeout())
+}
@@ -3,8 +3,12 @@ package challenger
 import (
 	"context"
 	"sort"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 	"github.com/initia-labs/opinit-bots/types"
 	"go.uber.org/zap"
 )
@@ -72,6 +76,39 @@ func (c *Challenger) handleChallenge(challenge challengertypes.Challenge) error
 	// TODO: warning log or send to alerting system
 	c.logger.Error("challenge", zap.Any("challenge", challenge))
 
+	if c.cfg.DeleteOutput && challenge.Mismatch && challenge.Id.Type == challengertypes.EventTypeOutput {
+		return c.deleteOutput(c.host, challenge.Id.Id)
+	}
+	return nil
+}
+
+type outputDeleter interface {
+	BridgeId() uint64
+	GetMsgDeleteOutput(bridgeId uint64, outputIndex uint64) (sdk.Msg, string, error)
+	BroadcastMsgs(btypes.ProcessedMsgs) error
+}
+
+// deleteOutput broadcasts the msg deleting the output whose root doesn't match. The msg is not saved,
+// so it is discarded if it fails, e.g. the output is already deleted by another challenger.
+func (c *Challenger) deleteOutput(host outputDeleter, outputIndex uint64) error {
+	msg, sender, err := host.GetMsgDeleteOutput(host.BridgeId(), outputIndex)
+	if err != nil {
+		return err
+	} else if msg == nil {
+		c.logger.Warn("skip deleting output as the challenger key is not set", zap.Uint64("output_index", outputIndex))
+		return nil
+	}
+
+	err = host.BroadcastMsgs(btypes.ProcessedMsgs{
+		Sender:    sender,
+		Msgs:      []sdk.Msg{msg},
+		Timestamp: time.Now().UnixNano(),
+		Save:      false,
+	}.WithTraceID())
+	if err != nil {
+		return err
+	}
+	c.logger.Warn("delete output", zap.Uint64("output_index", outputIndex))
 	return nil
 }
 
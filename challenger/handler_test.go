@@ -0,0 +1,72 @@
+package challenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+type mockOutputDeleter struct {
+	keySet      bool
+	broadcasted []btypes.ProcessedMsgs
+}
+
+func (m *mockOutputDeleter) BridgeId() uint64 {
+	return 1
+}
+
+func (m *mockOutputDeleter) GetMsgDeleteOutput(bridgeId uint64, outputIndex uint64) (sdk.Msg, string, error) {
+	if !m.keySet {
+		return nil, "", nil
+	}
+	return ophosttypes.NewMsgDeleteOutput("challenger", bridgeId, outputIndex), "challenger", nil
+}
+
+func (m *mockOutputDeleter) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	m.broadcasted = append(m.broadcasted, msgs)
+	return nil
+}
+
+func Test_DeleteOutput(t *testing.T) {
+	c := &Challenger{cfg: &challengertypes.Config{DeleteOutput: true}, logger: zap.NewNop()}
+
+	host := &mockOutputDeleter{keySet: true}
+	require.NoError(t, c.deleteOutput(host, 3))
+	require.Len(t, host.broadcasted, 1)
+	require.Equal(t, "challenger", host.broadcasted[0].Sender)
+	require.False(t, host.broadcasted[0].Save)
+	require.Equal(t, []sdk.Msg{ophosttypes.NewMsgDeleteOutput("challenger", 1, 3)}, host.broadcasted[0].Msgs)
+
+	// the challenger key is not set
+	host = &mockOutputDeleter{}
+	require.NoError(t, c.deleteOutput(host, 3))
+	require.Empty(t, host.broadcasted)
+}
+
+func Test_HandleChallengeWithoutDeleteOutput(t *testing.T) {
+	mismatch := challengertypes.Challenge{
+		Id:       challengertypes.ChallengeId{Type: challengertypes.EventTypeOutput, Id: 1},
+		Mismatch: true,
+	}
+	timeout := mismatch
+	timeout.Mismatch = false
+
+	// the host is not used unless the mismatched output is deleted
+	c := &Challenger{cfg: &challengertypes.Config{}, logger: zap.NewNop()}
+	require.NoError(t, c.handleChallenge(mismatch))
+
+	c.cfg.DeleteOutput = true
+	require.NoError(t, c.handleChallenge(timeout))
+	require.NoError(t, c.handleChallenge(challengertypes.Challenge{
+		Id:       challengertypes.ChallengeId{Type: challengertypes.EventTypeDeposit, Id: 1},
+		Mismatch: true,
+	}))
+}
@@ -2,6 +2,7 @@ package host
 
 import (
 	"context"
+	"fmt"
 
 	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
@@ -65,7 +66,7 @@ func (h *Host) endBlockHandler(_ context.Context, args nodetypes.EndBlockArgs) e
 
 	err = h.DB().RawBatchSet(batchKVs...)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", nodetypes.ErrFatalDB, err)
 	}
 
 	h.child.SetPendingEvents(h.eventQueue)
@@ -8,6 +8,7 @@ import (
 
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
 
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	"github.com/initia-labs/opinit-bots/types"
 
@@ -45,24 +46,32 @@ type Host struct {
 func NewHostV1(
 	cfg nodetypes.NodeConfig,
 	db types.DB, logger *zap.Logger,
-) *Host {
+) (*Host, error) {
+	baseHost, err := hostprovider.NewBaseHostV1(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Host{
-		BaseHost:                hostprovider.NewBaseHostV1(cfg, db, logger),
+		BaseHost:                baseHost,
 		eventHandler:            eventhandler.NewChallengeEventHandler(db, logger),
 		eventQueue:              make([]challengertypes.ChallengeEvent, 0),
 		outputPendingEventQueue: make([]challengertypes.ChallengeEvent, 0),
-	}
+	}, nil
 }
 
-func (h *Host) Initialize(ctx context.Context, processedHeight int64, child childNode, bridgeInfo ophosttypes.QueryBridgeResponse, challenger challenger) (time.Time, error) {
-	err := h.BaseHost.Initialize(ctx, processedHeight, bridgeInfo, nil)
+func (h *Host) Initialize(ctx context.Context, processedHeight int64, child childNode, bridgeInfo ophosttypes.QueryBridgeResponse, expectedChainInfo *hostprovider.ExpectedChainInfo, challenger challenger, keyringConfig *btypes.KeyringConfig) (time.Time, error) {
+	err := h.BaseHost.Initialize(ctx, processedHeight, bridgeInfo, expectedChainInfo, keyringConfig)
 	if err != nil {
 		return time.Time{}, err
 	}
 	h.child = child
 	h.challenger = challenger
 	// TODO: ignore l1Sequence less than child's last l1 sequence
-	h.registerHandlers()
+	err = h.registerHandlers()
+	if err != nil {
+		return time.Time{}, err
+	}
 
 	err = h.eventHandler.Initialize(bridgeInfo.BridgeConfig.SubmissionInterval)
 	if err != nil {
@@ -81,12 +90,23 @@ func (h *Host) Initialize(ctx context.Context, processedHeight int64, child chil
 	return blockTime, nil
 }
 
-func (h *Host) registerHandlers() {
-	h.Node().RegisterBeginBlockHandler(h.beginBlockHandler)
-	h.Node().RegisterTxHandler(h.txHandler)
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeInitiateTokenDeposit, h.initiateDepositHandler)
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeProposeOutput, h.proposeOutputHandler)
-	h.Node().RegisterEndBlockHandler(h.endBlockHandler)
+func (h *Host) registerHandlers() error {
+	if err := h.Node().RegisterBeginBlockHandler(h.beginBlockHandler); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterTxHandler(h.txHandler); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeInitiateTokenDeposit, h.initiateDepositHandler); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeProposeOutput, h.proposeOutputHandler); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEndBlockHandler(h.endBlockHandler); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (h *Host) QuerySyncedOutput(ctx context.Context, bridgeId uint64, outputIndex uint64) (*ophosttypes.QueryOutputProposalResponse, error) {
@@ -0,0 +1,15 @@
+package challenger
+
+import (
+	"github.com/initia-labs/opinit-bots/db/integrity"
+	"github.com/initia-labs/opinit-bots/merkle"
+	"github.com/initia-labs/opinit-bots/node"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// IntegrityChecks returns the integrity checks of the challenger db, which are run at startup if enabled.
+func IntegrityChecks(db types.DB) []integrity.Check {
+	checks := node.IntegrityChecks(types.HostName, db.WithPrefix([]byte(types.HostName)))
+	checks = append(checks, node.IntegrityChecks(types.ChildName, db.WithPrefix([]byte(types.ChildName)))...)
+	return append(checks, merkle.IntegrityChecks(db.WithPrefix([]byte(types.ChildName)).WithPrefix([]byte(types.MerkleName)))...)
+}
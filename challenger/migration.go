@@ -0,0 +1,17 @@
+package challenger
+
+import (
+	"github.com/initia-labs/opinit-bots/db/migration"
+	"github.com/initia-labs/opinit-bots/merkle"
+	"github.com/initia-labs/opinit-bots/node"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// Schemas returns the schemas of the challenger db, whose pending migrations are applied by `opinitd migrate schema`.
+func Schemas(db types.DB) []migration.Schema {
+	return []migration.Schema{
+		node.Schema(db.WithPrefix([]byte(types.HostName))),
+		node.Schema(db.WithPrefix([]byte(types.ChildName))),
+		merkle.Schema(db.WithPrefix([]byte(types.ChildName)).WithPrefix([]byte(types.MerkleName))),
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/initia-labs/opinit-bots/challenger/child"
 	"github.com/initia-labs/opinit-bots/challenger/host"
 	challengertypes "github.com/initia-labs/opinit-bots/challenger/types"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 type Status struct {
@@ -33,3 +35,47 @@ func (c Challenger) GetStatus() (Status, error) {
 	s.LatestChallenges = c.getLatestChallenges()
 	return s, nil
 }
+
+// ChallengerStatus is the status of the verification of the outputs proposed on l1.
+type ChallengerStatus struct {
+	DeleteOutput bool `json:"delete_output"`
+	// PendingOutputs are the outputs waiting for the child to compute their roots at the l2 block numbers.
+	PendingOutputs []challengertypes.ChallengeEvent `json:"pending_outputs"`
+	// OutputChallenges are the outputs of the latest challenges whose roots don't match the computed ones.
+	OutputChallenges []challengertypes.Challenge `json:"output_challenges"`
+}
+
+func (c Challenger) GetChallengerStatus() (ChallengerStatus, error) {
+	s := ChallengerStatus{
+		DeleteOutput:     c.cfg.DeleteOutput,
+		PendingOutputs:   make([]challengertypes.ChallengeEvent, 0),
+		OutputChallenges: make([]challengertypes.Challenge, 0),
+	}
+
+	pendingEvents, err := c.child.GetAllPendingEvents()
+	if err != nil {
+		return ChallengerStatus{}, err
+	}
+	for _, event := range pendingEvents {
+		if event.Type() == challengertypes.EventTypeOutput {
+			s.PendingOutputs = append(s.PendingOutputs, event)
+		}
+	}
+
+	for _, challenge := range c.getLatestChallenges() {
+		if challenge.Mismatch && challenge.Id.Type == challengertypes.EventTypeOutput {
+			s.OutputChallenges = append(s.OutputChallenges, challenge)
+		}
+	}
+	return s, nil
+}
+
+// GetDBStats returns the approximate usage of the db by the components, where the child includes its merkle.
+func (c Challenger) GetDBStats() ([]types.PrefixStats, error) {
+	return c.db.Stats(
+		dbtypes.PrefixOf(types.HostName),
+		dbtypes.PrefixOf(types.ChildName),
+		dbtypes.PrefixOf(types.ChildName, types.MerkleName),
+		dbtypes.PrefixOf(string(challengertypes.ChallengeKey)),
+	)
+}
@@ -15,6 +15,8 @@ type Challenge struct {
 	Id        ChallengeId `json:"id"`
 	Log       string      `json:"log"`
 	Time      time.Time   `json:"timestamp"`
+	// Mismatch is true if the event doesn't match the pending event, false if the event is timed out.
+	Mismatch bool `json:"mismatch"`
 }
 
 func (c Challenge) Marshal() ([]byte, error) {
@@ -1,8 +1,12 @@
 package types
 
 import (
-	"errors"
+	"time"
 
+	"github.com/initia-labs/opinit-bots/config"
+	"github.com/initia-labs/opinit-bots/logging"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/metrics"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	servertypes "github.com/initia-labs/opinit-bots/server/types"
 )
@@ -11,19 +15,31 @@ type NodeConfig struct {
 	ChainID      string `json:"chain_id"`
 	Bech32Prefix string `json:"bech32_prefix"`
 	RPCAddress   string `json:"rpc_address"`
+	// GasPrice, GasAdjustment and TxTimeout are used to broadcast the txs of the challenger,
+	// only required for the l1 node with `delete_output`.
+	GasPrice      string  `json:"gas_price"`
+	GasAdjustment float64 `json:"gas_adjustment"`
+	TxTimeout     int64   `json:"tx_timeout"` // seconds
+	// LenientTxDecoding decodes the txs with the msgs of the unknown type urls with a warning,
+	// instead of failing to decode them.
+	LenientTxDecoding bool `json:"lenient_tx_decoding"`
 }
 
 func (nc NodeConfig) Validate() error {
-	if nc.ChainID == "" {
-		return errors.New("chain ID is required")
+	var problems config.Problems
+	problems.Add("chain_id", config.ValidateChainID(nc.ChainID))
+	problems.Add("bech32_prefix", config.ValidateBech32Prefix(nc.Bech32Prefix))
+	problems.Add("rpc_address", config.ValidateURL(nc.RPCAddress, config.RPCSchemes...))
+	if nc.GasPrice != "" {
+		problems.Add("gas_price", config.ValidateGasPrice(nc.GasPrice))
 	}
-	if nc.Bech32Prefix == "" {
-		return errors.New("bech32 prefix is required")
+	if nc.GasAdjustment < 0 {
+		problems.Addf("gas_adjustment", "must be greater than or equal to 0")
 	}
-	if nc.RPCAddress == "" {
-		return errors.New("RPC address is required")
+	if nc.TxTimeout < 0 {
+		problems.Addf("tx_timeout", "must be greater than or equal to 0")
 	}
-	return nil
+	return problems.Err()
 }
 
 type Config struct {
@@ -33,6 +49,12 @@ type Config struct {
 	// Server is the configuration for the server.
 	Server servertypes.ServerConfig `json:"server"`
 
+	// Metrics is the configuration for the prometheus metrics.
+	Metrics metrics.Config `json:"metrics"`
+
+	// Log is the configuration for the logs.
+	Log logging.Config `json:"log"`
+
 	// L1Node is the configuration for the l1 node.
 	L1Node NodeConfig `json:"l1_node"`
 	// L2Node is the configuration for the l2 node.
@@ -50,6 +72,20 @@ type Config struct {
 	// L2 starts from the last submitted output l2 block number + 1 before L2StartHeight.
 	// L1 starts from the block number of the output tx + 1
 	L2StartHeight int64 `json:"l2_start_height"`
+
+	// SkipChainVerification is the flag to skip verifying the chain id of the l1 node and the bridge
+	// registered on the l2 chain at startup. It can be useful for the test networks where they mismatch.
+	SkipChainVerification bool `json:"skip_chain_verification"`
+
+	// CheckDBIntegrity is the flag to decode the critical records of the db at startup, such as the sync info
+	// and the trees, to report the records corrupted by a power loss. The bot refuses to start on the corrupted
+	// records unless it is started with --repair-db, which moves them to the quarantine prefix.
+	CheckDBIntegrity bool `json:"check_db_integrity"`
+
+	// DeleteOutput is the flag to broadcast MsgDeleteOutput on l1 for the output whose root doesn't match
+	// the root computed by the challenger. The key of the challenger address of the bridge must be added
+	// to the keyring of the l1 chain, and the gas price of the l1 node must be set.
+	DeleteOutput bool `json:"delete_output"`
 }
 
 func DefaultConfig() *Config {
@@ -61,12 +97,20 @@ func DefaultConfig() *Config {
 			AllowOrigins: "*",
 			AllowHeaders: "Origin, Content-Type, Accept",
 			AllowMethods: "GET",
+			Health:       servertypes.DefaultHealthConfig(),
 		},
 
+		Metrics: metrics.DefaultConfig(),
+
+		Log: logging.DefaultConfig(),
+
 		L1Node: NodeConfig{
-			ChainID:      "testnet-l1-1",
-			Bech32Prefix: "init",
-			RPCAddress:   "tcp://localhost:26657",
+			ChainID:       "testnet-l1-1",
+			Bech32Prefix:  "init",
+			RPCAddress:    "tcp://localhost:26657",
+			GasPrice:      "0.15uinit",
+			GasAdjustment: 1.5,
+			TxTimeout:     60,
 		},
 
 		L2Node: NodeConfig{
@@ -81,34 +125,33 @@ func DefaultConfig() *Config {
 }
 
 func (cfg Config) Validate() error {
+	var problems config.Problems
 	if cfg.Version == 0 {
-		return errors.New("version is required")
+		problems.Addf("version", "version is required")
+	} else if cfg.Version != 1 {
+		problems.Addf("version", "only version 1 is supported")
 	}
 
-	if cfg.Version != 1 {
-		return errors.New("only version 1 is supported")
-	}
-
-	if err := cfg.Server.Validate(); err != nil {
-		return err
-	}
-
-	if err := cfg.L1Node.Validate(); err != nil {
-		return err
-	}
-
-	if err := cfg.L2Node.Validate(); err != nil {
-		return err
-	}
+	problems.Add("server", cfg.Server.Validate())
+	problems.Add("metrics", cfg.Metrics.Validate())
+	problems.Add("log", cfg.Log.Validate())
+	problems.Add("l1_node", cfg.L1Node.Validate())
+	problems.Add("l2_node", cfg.L2Node.Validate())
 
 	if cfg.L1StartHeight < 0 {
-		return errors.New("l1 start height must be greater than or equal to 0")
+		problems.Addf("l1_start_height", "l1 start height must be greater than or equal to 0")
 	}
 
 	if cfg.L2StartHeight < 0 {
-		return errors.New("l2 start height must be greater than or equal to 0")
+		problems.Addf("l2_start_height", "l2 start height must be greater than or equal to 0")
+	}
+
+	if cfg.DeleteOutput {
+		if err := cfg.L1NodeConfig("").BroadcasterConfig.Validate(); err != nil {
+			problems.Addf("l1_node", "invalid l1 node config to delete outputs: %w", err)
+		}
 	}
-	return nil
+	return problems.Err()
 }
 
 func (cfg Config) L1NodeConfig(homePath string) nodetypes.NodeConfig {
@@ -116,6 +159,20 @@ func (cfg Config) L1NodeConfig(homePath string) nodetypes.NodeConfig {
 		RPC:          cfg.L1Node.RPCAddress,
 		ProcessType:  nodetypes.PROCESS_TYPE_DEFAULT,
 		Bech32Prefix: cfg.L1Node.Bech32Prefix,
+
+		LenientTxDecoding: cfg.L1Node.LenientTxDecoding,
+	}
+
+	if cfg.DeleteOutput {
+		nc.ChainID = cfg.L1Node.ChainID
+		nc.BroadcasterConfig = &btypes.BroadcasterConfig{
+			ChainID:       cfg.L1Node.ChainID,
+			GasPrice:      cfg.L1Node.GasPrice,
+			GasAdjustment: cfg.L1Node.GasAdjustment,
+			TxTimeout:     time.Duration(cfg.L1Node.TxTimeout) * time.Second,
+			Bech32Prefix:  cfg.L1Node.Bech32Prefix,
+			HomePath:      homePath,
+		}
 	}
 	return nc
 }
@@ -125,6 +182,8 @@ func (cfg Config) L2NodeConfig(homePath string) nodetypes.NodeConfig {
 		RPC:          cfg.L2Node.RPCAddress,
 		ProcessType:  nodetypes.PROCESS_TYPE_DEFAULT,
 		Bech32Prefix: cfg.L2Node.Bech32Prefix,
+
+		LenientTxDecoding: cfg.L2Node.LenientTxDecoding,
 	}
 	return nc
 }
@@ -120,6 +120,31 @@ func New(remote, wsEndpoint string) (*HTTP, error) {
 	return NewWithClient(remote, wsEndpoint, httpClient)
 }
 
+// NewWithTimeout creates the client of New whose every call is bounded by the given timeout,
+// so a hung node doesn't block the caller without the deadline of the context.
+func NewWithTimeout(remote, wsEndpoint string, timeout time.Duration) (*HTTP, error) {
+	c, err := New(remote, wsEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		c.baseRPCClient = &baseRPCClient{caller: timeoutCaller{caller: c.rpc, timeout: timeout}}
+	}
+	return c, nil
+}
+
+// timeoutCaller bounds every call of the caller by the timeout.
+type timeoutCaller struct {
+	caller  jsonrpcclient.Caller
+	timeout time.Duration
+}
+
+func (c timeoutCaller) Call(ctx context.Context, method string, params map[string]interface{}, result interface{}) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.caller.Call(ctx, method, params, result)
+}
+
 // NewWithClient allows for setting a custom http client (See New).
 // An error is returned on invalid remote. The function panics when remote is nil.
 func NewWithClient(remote, wsEndpoint string, client *http.Client) (*HTTP, error) {
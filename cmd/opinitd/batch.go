@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/initia-labs/opinit-bots/bot"
+	bottypes "github.com/initia-labs/opinit-bots/bot/types"
+	"github.com/initia-labs/opinit-bots/executor/batch/reader"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/node/rpcclient"
+	"github.com/initia-labs/opinit-bots/provider/child"
+	"github.com/initia-labs/opinit-bots/txutils"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// batchCmd represents the batch command
+func batchCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "inspect the batches submitted to the DA",
+	}
+
+	cmd.AddCommand(
+		batchVerifyCmd(ctx),
+		batchResubmitCmd(ctx),
+	)
+	return cmd
+}
+
+func batchVerifyCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [header-file] [chunk-files...]",
+		Args:  cobra.MinimumNArgs(2),
+		Short: "Verify the batch submitted to the DA against the l2 node",
+		Long: `Verify the batch submitted to the DA against the l2 node.
+The files contain the raw batch data submitted to the DA, the header and the chunks.
+The blocks and the commit decoded from the batch are compared with the ones of the l2 node in the executor config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := getConfigPath(cmd, ctx.homePath, string(bottypes.BotTypeExecutor))
+			if err != nil {
+				return err
+			}
+
+			cfg := &executortypes.Config{}
+			err = bot.LoadJsonConfig(configPath, cfg)
+			if err != nil {
+				return err
+			}
+
+			headerData, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			header, err := executortypes.UnmarshalBatchDataHeader(headerData)
+			if err != nil {
+				return err
+			}
+
+			chunks := make([][]byte, 0, len(args)-1)
+			for _, chunkFile := range args[1:] {
+				chunk, err := os.ReadFile(chunkFile)
+				if err != nil {
+					return err
+				}
+				chunks = append(chunks, chunk)
+			}
+
+			l2Config := cfg.L2NodeConfig(ctx.homePath)
+			cdc, txConfig, err := child.GetCodec(l2Config.Bech32Prefix)
+			if err != nil {
+				return err
+			}
+			rpcClient, err := rpcclient.NewRPCClient(cdc, l2Config.RPC)
+			if err != nil {
+				return err
+			}
+
+			decodeTxMsgs := func(txBytes []byte) ([]sdk.Msg, error) {
+				msgs, _, err := txutils.DecodeTxMsgs(cdc, txConfig, txBytes, l2Config.LenientTxDecoding)
+				return msgs, err
+			}
+			err = reader.VerifyBatch(cmd.Context(), rpcClient, txConfig, decodeTxMsgs, header, chunks)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("batch %d-%d is verified\n", header.Start, header.End)
+			return nil
+		},
+	}
+
+	cmd = configFlag(ctx.v, cmd)
+	return cmd
+}
+
+func batchResubmitCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resubmit [start] [end]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Re-submit the archived batches within the l2 block range to the DA",
+		Long: `Re-submit the archived batches within the l2 block range [start, end] through the current DA node
+of the running executor, e.g. when the submitted data is pruned from the DA.
+It calls the POST /admin/batches/archive/resubmit endpoint of the server address in the executor config,
+which requires batch_archive.dir and enable_local_admin of the server config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid start: %w", err)
+			}
+			end, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid end: %w", err)
+			} else if end < start {
+				return fmt.Errorf("end %d is before start %d", end, start)
+			}
+
+			configPath, err := getConfigPath(cmd, ctx.homePath, string(bottypes.BotTypeExecutor))
+			if err != nil {
+				return err
+			}
+
+			cfg := &executortypes.Config{}
+			err = bot.LoadJsonConfig(configPath, cfg)
+			if err != nil {
+				return err
+			}
+
+			routePath := "/admin/batches/archive/resubmit"
+			bridge, err := cmd.Flags().GetString(flagBridge)
+			if err != nil {
+				return err
+			} else if bridge != "" {
+				routePath = fmt.Sprintf("/%s/%s%s", types.BridgesName, bridge, routePath)
+			}
+			endpoint, err := localAdminURL(cfg.Server.Address, routePath)
+			if err != nil {
+				return err
+			}
+			endpoint.RawQuery = url.Values{
+				"start": []string{args[0]},
+				"end":   []string{args[1]},
+			}.Encode()
+
+			req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, endpoint.String(), nil)
+			if err != nil {
+				return err
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to call the admin endpoint of the running executor: %w", err)
+			}
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				return err
+			} else if res.StatusCode != http.StatusOK {
+				return fmt.Errorf("failed to resubmit the archived batches: %s: %s", res.Status, body)
+			}
+			fmt.Println(string(body))
+			return nil
+		},
+	}
+
+	cmd = configFlag(ctx.v, cmd)
+	return bridgeFlag(cmd)
+}
+
+// localAdminURL returns the url of the admin route of the server address, which is served only to the
+// loopback addresses, so the unspecified host is replaced with the loopback address.
+func localAdminURL(address string, routePath string) (*url.URL, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server address: %w", err)
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return &url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(host, port),
+		Path:   routePath,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/initia-labs/opinit-bots/bot"
+	bottypes "github.com/initia-labs/opinit-bots/bot/types"
+	"github.com/initia-labs/opinit-bots/config"
+)
+
+func configCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage a bot's config",
+	}
+	cmd.AddCommand(
+		configValidateCmd(ctx),
+	)
+	return cmd
+}
+
+func configValidateCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [bot-name]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Validate a bot's config and print all the problems.",
+		Long: `Validate a bot's config without connecting to the nodes, and print all the problems at once,
+such as the invalid rpc addresses, gas prices, bech32 prefixes and intervals.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			botType := bottypes.BotTypeFromString(args[0])
+			if err := botType.Validate(); err != nil {
+				return err
+			}
+
+			configPath, err := getConfigPath(cmd, ctx.homePath, args[0])
+			if err != nil {
+				return err
+			}
+
+			err = bot.ValidateConfig(botType, configPath)
+			var problems config.Errors
+			if errors.As(err, &problems) {
+				for _, problem := range problems {
+					fmt.Fprintln(cmd.ErrOrStderr(), problem.Error())
+				}
+				return fmt.Errorf("%s has %d problems", configPath, len(problems))
+			} else if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", configPath)
+			return nil
+		},
+	}
+	cmd = configFlag(ctx.v, cmd)
+	return cmd
+}
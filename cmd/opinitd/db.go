@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -14,23 +15,37 @@ import (
 	"github.com/initia-labs/opinit-bots/provider/child"
 	"github.com/initia-labs/opinit-bots/types"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 // migrationCmd handles the one-time migration of withdrawal data for v0.1.5, v0.1.9
 // TODO: Remove this command in the future
 func migrationCmd(ctx *cmdContext) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "migrate",
-		Args:  cobra.ExactArgs(1),
+		Use:   "migrate [version] [bot-name]",
+		Args:  cobra.RangeArgs(1, 2),
 		Short: "Run database migrations",
 		Long: `Run database migrations
 v0.1.5: Store the sequence number so that it can be accessed by address
 v0.1.9-1: Delete finalized trees and create new finalized trees from working trees
 v0.1.9-2: Fill block hash of finalized tree 
+The v0.1.9 migrations must run before the schema migrations, which encode the merkle trees in binary.
+schema: Apply the pending schema migrations of the bot db, executor by default.
+The bot refuses to start until the schema migrations are applied.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			version := args[0]
 			switch version {
+			case "schema":
+				botType := bottypes.BotTypeExecutor
+				if len(args) > 1 {
+					botType = bottypes.BotTypeFromString(args[1])
+				}
+				configPath, err := getConfigPath(cmd, ctx.homePath, string(botType))
+				if err != nil {
+					return err
+				}
+				return bot.MigrateDB(botType, ctx.homePath, configPath, ctx.logger)
 			case "v0.1.5":
 				// Run migration for v0.1.5
 				db, err := db.NewDB(bot.GetDBPath(ctx.homePath, bottypes.BotTypeExecutor))
@@ -99,3 +114,126 @@ v0.1.9-2: Fill block hash of finalized tree
 	cmd.Flags().Duration(flagPollingInterval, 100*time.Millisecond, "Polling interval in milliseconds")
 	return cmd
 }
+
+func dbCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Back up and restore a bot's db",
+	}
+	cmd.AddCommand(
+		dbBackupCmd(ctx),
+		dbRestoreCmd(ctx),
+		dbDryRunCmd(ctx),
+	)
+	return cmd
+}
+
+func dbBackupCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [bot-name] [snapshot-name]",
+		Args:  cobra.RangeArgs(1, 2),
+		Short: "Write a snapshot of a bot's db to the backup directory.",
+		Long: `Write a snapshot of a bot's db to the backup directory of the home directory.
+The snapshot name defaults to the current time.
+
+The db is locked while the bot is running. To back up the running bot,
+use the POST /admin/db/backup endpoint with enable_local_admin of the server config.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			botType := bottypes.BotTypeFromString(args[0])
+			if err := botType.Validate(); err != nil {
+				return err
+			}
+
+			var name string
+			if len(args) > 1 {
+				name = args[1]
+			}
+			snapshotPath, err := db.SnapshotPath(botType.BackupDir(ctx.homePath), name)
+			if err != nil {
+				return err
+			}
+
+			botDB, err := db.NewDB(bot.GetDBPath(ctx.homePath, botType))
+			if err != nil {
+				return fmt.Errorf("failed to open db; use the admin endpoint to back up the running bot: %w", err)
+			}
+			defer botDB.Close()
+
+			err = botDB.Snapshot(snapshotPath)
+			if err != nil {
+				return err
+			}
+			manifest, err := db.ReadSnapshotManifest(snapshotPath)
+			if err != nil {
+				return err
+			}
+			ctx.logger.Info("db backed up", zap.String("path", snapshotPath), zap.Uint64("key_count", manifest.KeyCount))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func dbRestoreCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [bot-name] [snapshot-path]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Replace a bot's db with a snapshot.",
+		Long: `Replace a bot's db with a snapshot. The bot must be stopped.
+The snapshot is validated with its manifest and its schema versions before the db is replaced,
+and the replaced db is kept next to the db.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			botType := bottypes.BotTypeFromString(args[0])
+			if err := botType.Validate(); err != nil {
+				return err
+			}
+
+			replacedPath, err := bot.RestoreDB(botType, ctx.homePath, args[1])
+			if err != nil {
+				return err
+			}
+			ctx.logger.Info("db restored", zap.String("snapshot", args[1]), zap.String("replaced_db", replacedPath))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func dbDryRunCmd(ctx *cmdContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dry-run",
+		Args:  cobra.NoArgs,
+		Short: "Print the msgs recorded by the executor in the dry-run mode.",
+		Long: `Print the msgs recorded instead of being broadcasted by the executor in the dry-run mode
+as json by the node names. The bot must be stopped. The bridge instance of the executor running
+multiple bridges is selected with --bridge.
+
+To inspect the running bot, use the GET /dry_run endpoint.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := db.NewDB(bot.GetDBPath(ctx.homePath, bottypes.BotTypeExecutor))
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			bridgeDB, err := executorDB(cmd, db)
+			if err != nil {
+				return err
+			}
+			records, err := executor.DryRunRecords(bridgeDB)
+			if err != nil {
+				return err
+			}
+			bz, err := json.MarshalIndent(records, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(bz))
+			return nil
+		},
+	}
+	return bridgeFlag(cmd)
+}
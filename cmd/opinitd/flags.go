@@ -9,11 +9,15 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/initia-labs/opinit-bots/executor"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 const (
 	flagHome       = "home"
 	flagConfigName = "config"
+	flagBridge     = "bridge"
 )
 
 var defaultHome = filepath.Join(os.Getenv("HOME"), ".opinit")
@@ -41,3 +45,21 @@ func getConfigPath(cmd *cobra.Command, homePath, botName string) (string, error)
 	}
 	return configPath, nil
 }
+
+// bridgeFlag adds the flag selecting the bridge instance of the executor running multiple bridges.
+func bridgeFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagBridge, "", "The id of the bridge instance in the bridges of the executor config. default: the single bridge")
+	return cmd
+}
+
+// executorDB returns the db of the bridge instance selected by the bridge flag,
+// or the db itself for the single bridge.
+func executorDB(cmd *cobra.Command, db types.DB) (types.DB, error) {
+	bridge, err := cmd.Flags().GetString(flagBridge)
+	if err != nil {
+		return nil, err
+	} else if bridge == "" {
+		return db, nil
+	}
+	return executor.BridgeDB(db, bridge), nil
+}
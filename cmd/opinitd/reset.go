@@ -51,6 +51,7 @@ func resetHeightsCmd(ctx *cmdContext) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		Short: "Reset bot's all height info.",
 		Long: `Reset bot's all height info.
+The bridge instance of the executor running multiple bridges is selected with --bridge.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			botType := bottypes.BotTypeFromString(args[0])
@@ -65,14 +66,18 @@ func resetHeightsCmd(ctx *cmdContext) *cobra.Command {
 
 			switch botType {
 			case bottypes.BotTypeExecutor:
-				return executor.ResetHeights(db)
+				bridgeDB, err := executorDB(cmd, db)
+				if err != nil {
+					return err
+				}
+				return executor.ResetHeights(bridgeDB)
 			case bottypes.BotTypeChallenger:
 				return challenger.ResetHeights(db)
 			}
 			return errors.New("unknown bot type")
 		},
 	}
-	return cmd
+	return bridgeFlag(cmd)
 }
 
 func resetHeightCmd(ctx *cmdContext) *cobra.Command {
@@ -89,6 +94,8 @@ Executor node types:
 Challenger node types: 
 - host
 - child
+
+The bridge instance of the executor running multiple bridges is selected with --bridge.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			botType := bottypes.BotTypeFromString(args[0])
@@ -103,12 +110,16 @@ Challenger node types:
 
 			switch botType {
 			case bottypes.BotTypeExecutor:
-				return executor.ResetHeight(db, args[1])
+				bridgeDB, err := executorDB(cmd, db)
+				if err != nil {
+					return err
+				}
+				return executor.ResetHeight(bridgeDB, args[1])
 			case bottypes.BotTypeChallenger:
 				return challenger.ResetHeight(db, args[1])
 			}
 			return errors.New("unknown bot type")
 		},
 	}
-	return cmd
+	return bridgeFlag(cmd)
 }
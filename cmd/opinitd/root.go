@@ -5,8 +5,8 @@ import (
 	"github.com/spf13/viper"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
+	"github.com/initia-labs/opinit-bots/logging"
 	"github.com/initia-labs/opinit-bots/version"
 )
 
@@ -49,31 +49,16 @@ func NewRootCmd() *cobra.Command {
 		resetHeightsCmd(ctx),
 		resetHeightCmd(ctx),
 		migrationCmd(ctx),
+		dbCmd(ctx),
 		txCmd(ctx),
+		batchCmd(ctx),
+		configCmd(ctx),
 		version.NewVersionCommand(),
 	)
 	return rootCmd
 }
 
 func getLogger(logLevel string) (*zap.Logger, error) {
-	level := zap.InfoLevel
-	switch logLevel {
-	case "debug":
-		level = zap.DebugLevel
-	case "warn":
-		level = zap.WarnLevel
-	case "error":
-		level = zap.ErrorLevel
-	case "panic":
-		level = zap.PanicLevel
-	case "fatal":
-		level = zap.FatalLevel
-	}
-
-	config := zap.NewDevelopmentConfig()
-	config.Level = zap.NewAtomicLevelAt(level)
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-
-	return config.Build()
+	logger, _, err := logging.NewLogger(logging.Config{Level: logLevel})
+	return logger, err
 }
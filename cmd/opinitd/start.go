@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -13,11 +14,18 @@ import (
 
 	"github.com/initia-labs/opinit-bots/bot"
 	bottypes "github.com/initia-labs/opinit-bots/bot/types"
+	"github.com/initia-labs/opinit-bots/node/metrics"
 	"github.com/initia-labs/opinit-bots/types"
 )
 
 const (
-	flagPollingInterval = "polling-interval"
+	flagPollingInterval          = "polling-interval"
+	flagNoAutoRewind             = "no-auto-rewind"
+	flagRepairWithdrawalGap      = "repair-withdrawal-gap"
+	flagConfirmDestructiveRewind = "confirm-destructive-rewind"
+	flagRepairDB                 = "repair-db"
+	flagFailFast                 = "fail-fast"
+	flagShutdownStageTimeout     = "shutdown-stage-timeout"
 )
 
 func startCmd(ctx *cmdContext) *cobra.Command {
@@ -41,13 +49,17 @@ Currently supported bots:
 				return err
 			}
 
-			bot, err := bot.NewBot(botType, ctx.logger, ctx.homePath, configPath)
+			bot, err := bot.NewBot(botType, ctx.v.GetString("log-level"), ctx.homePath, configPath)
 			if err != nil {
 				return err
 			}
 
+			shutdownStageTimeout, err := cmd.Flags().GetDuration(flagShutdownStageTimeout)
+			if err != nil {
+				return err
+			}
 			cmdCtx, botDone := context.WithCancel(cmd.Context())
-			gracefulShutdown(botDone)
+			waitShutdown := gracefulBotShutdown(bot, botDone, shutdownStageTimeout)
 
 			errGrp, ctx := errgroup.WithContext(cmdCtx)
 			ctx = types.WithErrGrp(ctx, errGrp)
@@ -56,16 +68,52 @@ Currently supported bots:
 				return err
 			}
 			ctx = types.WithPollingInterval(ctx, interval)
+			noAutoRewind, err := cmd.Flags().GetBool(flagNoAutoRewind)
+			if err != nil {
+				return err
+			}
+			ctx = types.WithNoAutoRewind(ctx, noAutoRewind)
+			repairWithdrawalGap, err := cmd.Flags().GetBool(flagRepairWithdrawalGap)
+			if err != nil {
+				return err
+			}
+			ctx = types.WithRepairWithdrawalGap(ctx, repairWithdrawalGap)
+			confirmDestructiveRewind, err := cmd.Flags().GetBool(flagConfirmDestructiveRewind)
+			if err != nil {
+				return err
+			}
+			ctx = types.WithConfirmDestructiveRewind(ctx, confirmDestructiveRewind)
+			repairDB, err := cmd.Flags().GetBool(flagRepairDB)
+			if err != nil {
+				return err
+			}
+			ctx = types.WithRepairDB(ctx, repairDB)
+			failFast, err := cmd.Flags().GetBool(flagFailFast)
+			if err != nil {
+				return err
+			}
+			ctx = types.WithFailFast(ctx, failFast)
+			errGrp.Go(func() error {
+				return metrics.StartServer(ctx)
+			})
 			err = bot.Initialize(ctx)
 			if err != nil {
 				return err
 			}
-			return bot.Start(ctx)
+			err = bot.Start(ctx)
+			waitShutdown()
+			return err
 		},
 	}
 
 	cmd = configFlag(ctx.v, cmd)
 	cmd.Flags().Duration(flagPollingInterval, 100*time.Millisecond, "Polling interval in milliseconds")
+	cmd.Flags().Bool(flagNoAutoRewind, false, "Only report the divergence of the local state from the chain without rewinding")
+	cmd.Flags().Bool(flagRepairWithdrawalGap, false, "Backfill the missing withdrawals from the chain instead of halting on a withdrawal sequence gap")
+	cmd.Flags().Bool(flagConfirmDestructiveRewind, false, "Confirm the deletion of the withdrawals after the restarting height")
+	cmd.Flags().Bool(flagRepairDB, false, "Move the undecodable records found by the db integrity check to the quarantine prefix")
+	cmd.Flags().Bool(flagFailFast, false, "Stop all bridges of the executor running multiple bridges when one of them fails")
+	cmd.Flags().Duration(flagShutdownStageTimeout, 30*time.Second, "Timeout of each stage of the graceful shutdown")
 	return cmd
 }
 
@@ -78,3 +126,40 @@ func gracefulShutdown(done context.CancelFunc) {
 		done()
 	}()
 }
+
+// gracefulBotShutdown stops the bot on the signal. The bot supporting the graceful shutdown flushes its state
+// in order before it is stopped, and the second signal stops it immediately. The returned function waits
+// for the graceful shutdown in progress to be completed.
+func gracefulBotShutdown(bot bottypes.Bot, done context.CancelFunc, stageTimeout time.Duration) (wait func()) {
+	signalChannel := make(chan os.Signal, 2)
+	signal.Notify(signalChannel, os.Interrupt, syscall.SIGTERM)
+
+	shuttingDown := &atomic.Bool{}
+	shutdownDone := make(chan struct{})
+	go func() {
+		<-signalChannel
+		fmt.Println("Received signal to stop. Shutting down...")
+		gracefulBot, ok := bot.(bottypes.GracefulBot)
+		if !ok {
+			done()
+			return
+		}
+
+		shuttingDown.Store(true)
+		defer close(shutdownDone)
+		go func() {
+			<-signalChannel
+			fmt.Println("Received second signal to stop. Stopping immediately...")
+			done()
+		}()
+		if err := gracefulBot.Shutdown(context.Background(), stageTimeout, done); err != nil {
+			fmt.Printf("Graceful shutdown is not completed: %s\n", err.Error())
+		}
+	}()
+
+	return func() {
+		if shuttingDown.Load() {
+			<-shutdownDone
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problems collects the problems of a config, so that all of them are reported at once
+// instead of the first one.
+type Problems struct {
+	errs Errors
+}
+
+// Add adds the error as a problem of the field. The problems of the nested config are added
+// one by one with the field prefixed. It does nothing if the error is nil.
+func (p *Problems) Add(field string, err error) {
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(Errors); ok {
+		for _, err := range errs {
+			p.Add(field, err)
+		}
+		return
+	}
+	if field != "" {
+		err = fmt.Errorf("%s: %w", field, err)
+	}
+	p.errs = append(p.errs, err)
+}
+
+// Addf adds the formatted error as a problem of the field.
+func (p *Problems) Addf(field string, format string, args ...any) {
+	p.Add(field, fmt.Errorf(format, args...))
+}
+
+// Err returns the problems as Errors, or nil if there is no problem.
+func (p *Problems) Err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return p.errs
+}
+
+// Errors is the problems of a config, printed one per line.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RPCSchemes are the schemes of the rpc addresses accepted by the rpc client.
+var RPCSchemes = []string{"tcp", "http", "https", "unix"}
+
+// ValidateChainID checks the chain id is not empty and has no surrounding spaces.
+func ValidateChainID(chainID string) error {
+	if strings.TrimSpace(chainID) == "" {
+		return errors.New("chain id is required")
+	} else if strings.TrimSpace(chainID) != chainID {
+		return fmt.Errorf("chain id %q must not have leading or trailing spaces", chainID)
+	}
+	return nil
+}
+
+// ValidateURL checks the address is a url with one of the schemes and a host.
+func ValidateURL(addr string, schemes ...string) error {
+	if addr == "" {
+		return errors.New("address is required")
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	} else if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("invalid scheme of address %q, must be one of %s", addr, strings.Join(schemes, ", "))
+	} else if u.Scheme == "unix" {
+		if u.Path == "" {
+			return fmt.Errorf("address %q has no socket path", addr)
+		}
+	} else if u.Host == "" {
+		return fmt.Errorf("address %q has no host", addr)
+	}
+	return nil
+}
+
+// ValidateGRPCAddress checks the address is a host and port, optionally with the http, https or tcp scheme.
+func ValidateGRPCAddress(addr string) error {
+	hostPort := addr
+	for _, scheme := range []string{"http://", "https://", "tcp://"} {
+		hostPort = strings.TrimPrefix(hostPort, scheme)
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return fmt.Errorf("invalid grpc address %q, must be host:port: %w", addr, err)
+	} else if host == "" || port == "" {
+		return fmt.Errorf("invalid grpc address %q, must be host:port", addr)
+	}
+	return nil
+}
+
+// ValidateGasPrice checks the gas price is a list of the dec coins with the valid denominations, e.g. "0.15uinit".
+func ValidateGasPrice(gasPrice string) error {
+	if strings.TrimSpace(gasPrice) == "" {
+		return errors.New("gas price is required")
+	} else if _, err := sdk.ParseDecCoins(gasPrice); err != nil {
+		return fmt.Errorf("invalid gas price %q: %w", gasPrice, err)
+	}
+	return nil
+}
+
+// ValidateBech32Prefix checks the prefix is a lowercase human readable part of the bech32 addresses.
+func ValidateBech32Prefix(prefix string) error {
+	if prefix == "" {
+		return errors.New("bech32 prefix is required")
+	} else if len(prefix) > 83 {
+		return fmt.Errorf("bech32 prefix %q is too long", prefix)
+	}
+	for _, c := range prefix {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("invalid bech32 prefix %q, must have only lowercase letters and digits", prefix)
+		}
+	}
+	return nil
+}
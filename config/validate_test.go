@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateURL(t *testing.T) {
+	for _, addr := range []string{"tcp://localhost:26657", "http://127.0.0.1:26657", "https://rpc.initia.xyz", "unix:///tmp/node.sock"} {
+		require.NoError(t, ValidateURL(addr, RPCSchemes...), addr)
+	}
+	for _, addr := range []string{"", "localhost:26657", "ws://localhost:26657", "tcp://", "unix://", "http://local host"} {
+		require.Error(t, ValidateURL(addr, RPCSchemes...), addr)
+	}
+}
+
+func Test_ValidateGRPCAddress(t *testing.T) {
+	for _, addr := range []string{"localhost:9090", "http://localhost:9090", "tcp://127.0.0.1:9090", "grpc.initia.xyz:443"} {
+		require.NoError(t, ValidateGRPCAddress(addr), addr)
+	}
+	for _, addr := range []string{"", "localhost", ":9090", "localhost:", "grpc://localhost:9090"} {
+		require.Error(t, ValidateGRPCAddress(addr), addr)
+	}
+}
+
+func Test_ValidateGasPrice(t *testing.T) {
+	for _, gasPrice := range []string{"0.15uinit", "0uinit", "0.002utia", "0.15uinit,0.01uusdc"} {
+		require.NoError(t, ValidateGasPrice(gasPrice), gasPrice)
+	}
+	for _, gasPrice := range []string{"", "0.15", "uinit", "-1uinit", "0.15UINIT!"} {
+		require.Error(t, ValidateGasPrice(gasPrice), gasPrice)
+	}
+}
+
+func Test_ValidateBech32Prefix(t *testing.T) {
+	for _, prefix := range []string{"init", "celestia", "cosmos1"} {
+		require.NoError(t, ValidateBech32Prefix(prefix), prefix)
+	}
+	for _, prefix := range []string{"", "Init", "init1 ", "in-it", string(make([]byte, 84))} {
+		require.Error(t, ValidateBech32Prefix(prefix), prefix)
+	}
+}
+
+func Test_ValidateChainID(t *testing.T) {
+	require.NoError(t, ValidateChainID("initiation-2"))
+	require.Error(t, ValidateChainID(""))
+	require.Error(t, ValidateChainID("  "))
+	require.Error(t, ValidateChainID(" initiation-2"))
+}
+
+func Test_Problems(t *testing.T) {
+	var nested Problems
+	nested.Add("chain_id", errors.New("chain id is required"))
+	nested.Add("rpc_address", nil)
+	nested.Addf("gas_price", "invalid gas price %q", "0.15")
+
+	var problems Problems
+	require.NoError(t, problems.Err())
+	problems.Add("l1_node", nested.Err())
+	problems.Addf("max_chunks", "max chunks must be greater than 0")
+
+	err := problems.Err()
+	require.Error(t, err)
+	require.Equal(t, `l1_node: chain_id: chain id is required
+l1_node: gas_price: invalid gas price "0.15"
+max_chunks: max chunks must be greater than 0`, err.Error())
+
+	var errs Errors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 3)
+}
@@ -12,10 +12,15 @@ import (
 
 var _ types.DB = (*LevelDB)(nil)
 
+// DefaultDeleteBatchSize is the default number of the keys deleted in a batch by PrefixedDeleteRange.
+const DefaultDeleteBatchSize = 10000
+
 type LevelDB struct {
 	db     *leveldb.DB
 	path   string
 	prefix []byte
+
+	deleteBatchSize int
 }
 
 func NewDB(path string) (types.DB, error) {
@@ -25,8 +30,9 @@ func NewDB(path string) (types.DB, error) {
 	}
 
 	return &LevelDB{
-		db:   db,
-		path: path,
+		db:              db,
+		path:            path,
+		deleteBatchSize: DefaultDeleteBatchSize,
 	}, nil
 }
 
@@ -87,8 +93,22 @@ func (db *LevelDB) Close() error {
 // PrefixedIterate iterates over the key-value pairs in the database with prefixing the keys.
 //
 // @dev: `LevelDB.prefix + prefix` is used as the prefix for the iteration.
-func (db *LevelDB) PrefixedIterate(prefix []byte, start []byte, cb func(key, value []byte) (stop bool, err error)) (iterErr error) {
-	iter := db.db.NewIterator(util.BytesPrefix(db.PrefixedKey(prefix)), nil)
+func (db *LevelDB) PrefixedIterate(prefix []byte, start []byte, cb func(key, value []byte) (stop bool, err error)) error {
+	return db.PrefixedRangeIterate(prefix, start, nil, cb)
+}
+
+// PrefixedRangeIterate iterates over the key-value pairs of the prefix in the range [start, end).
+// The iteration starts from the first key of the prefix if start is nil, and ends at the last key
+// of the prefix if end is nil.
+//
+// @dev: `LevelDB.prefix + prefix` is used as the prefix for the iteration.
+func (db *LevelDB) PrefixedRangeIterate(prefix []byte, start []byte, end []byte, cb func(key, value []byte) (stop bool, err error)) (iterErr error) {
+	r := db.prefixedRange(prefix, start, end)
+	if r == nil {
+		return nil
+	}
+
+	iter := db.db.NewIterator(r, nil)
 	defer func() {
 		iter.Release()
 		if iterErr == nil {
@@ -96,26 +116,33 @@ func (db *LevelDB) PrefixedIterate(prefix []byte, start []byte, cb func(key, val
 		}
 	}()
 
-	if start != nil {
-		iter.Seek(db.PrefixedKey(start))
-	} else {
-		iter.First()
-	}
-
-	for iter.Valid() {
+	for ok := iter.First(); ok; ok = iter.Next() {
 		key := db.UnprefixedKey(bytes.Clone(iter.Key()))
 		if stop, err := cb(key, bytes.Clone(iter.Value())); err != nil {
 			return err
 		} else if stop {
 			break
 		}
-		iter.Next()
 	}
 	return
 }
 
+// PrefixedReverseIterate iterates over the key-value pairs of the prefix in reverse order, from start inclusive.
+// The iteration starts from the last key of the prefix if start is nil.
+//
+// @dev: `LevelDB.prefix + prefix` is used as the prefix for the iteration.
 func (db *LevelDB) PrefixedReverseIterate(prefix []byte, start []byte, cb func(key, value []byte) (stop bool, err error)) (iterErr error) {
-	iter := db.db.NewIterator(util.BytesPrefix(db.PrefixedKey(prefix)), nil)
+	var end []byte
+	if start != nil {
+		// the smallest key after start, to include start
+		end = append(bytes.Clone(start), 0)
+	}
+	r := db.prefixedRange(prefix, nil, end)
+	if r == nil {
+		return nil
+	}
+
+	iter := db.db.NewIterator(r, nil)
 	defer func() {
 		iter.Release()
 		if iterErr == nil {
@@ -123,53 +150,64 @@ func (db *LevelDB) PrefixedReverseIterate(prefix []byte, start []byte, cb func(k
 		}
 	}()
 
-	if start != nil {
-		if ok := iter.Seek(db.PrefixedKey(start)); ok || iter.Last() {
-			// if the valid key is not found, the iterator will be at the last key
-			// if the key is found, the iterator will be at the key
-			// or the previous key if the key is not found
-			if ok && !bytes.Equal(db.PrefixedKey(start), iter.Key()) {
-				iter.Prev()
-			}
-		}
-	} else {
-		iter.Last()
-	}
-
-	for iter.Valid() {
+	for ok := iter.Last(); ok; ok = iter.Prev() {
 		key := db.UnprefixedKey(bytes.Clone(iter.Key()))
 		if stop, err := cb(key, bytes.Clone(iter.Value())); err != nil {
 			return err
 		} else if stop {
 			break
 		}
-
-		iter.Prev()
 	}
 	return
 }
 
-// SeekPrevInclusiveKey seeks the previous key-value pair in the database with prefixing the keys.
+// prefixedRange returns the range of the prefix bounded by [start, end), or nil if the range is empty.
+func (db *LevelDB) prefixedRange(prefix []byte, start []byte, end []byte) *util.Range {
+	return prefixedRange(db.prefix, prefix, start, end)
+}
+
+// PrefixedDeleteRange deletes the keys of the prefix in the range [start, end) and returns the number of the deleted keys.
+// The keys are collected before the deletion, then deleted in batches of the delete batch size,
+// so each batch is applied atomically.
 //
 // @dev: `LevelDB.prefix + prefix` is used as the prefix for the iteration.
-func (db *LevelDB) SeekPrevInclusiveKey(prefix []byte, key []byte) (k []byte, v []byte, err error) {
-	iter := db.db.NewIterator(util.BytesPrefix(db.PrefixedKey(prefix)), nil)
-	defer func() {
-		iter.Release()
-		if err == nil {
-			err = iter.Error()
+func (db *LevelDB) PrefixedDeleteRange(prefix []byte, start []byte, end []byte) (int, error) {
+	keys := make([][]byte, 0)
+	err := db.PrefixedRangeIterate(prefix, start, end, func(key, _ []byte) (bool, error) {
+		keys = append(keys, key)
+		return false, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	batchSize := max(db.deleteBatchSize, 1)
+	for i := 0; i < len(keys); i += batchSize {
+		batch := new(leveldb.Batch)
+		for _, key := range keys[i:min(i+batchSize, len(keys))] {
+			batch.Delete(db.PrefixedKey(key))
 		}
-	}()
-	if ok := iter.Seek(db.PrefixedKey(key)); ok || iter.Last() {
-		// if the valid key is not found, the iterator will be at the last key
-		// if the key is found, the iterator will be at the key
-		// or the previous key if the key is not found
-		if ok && !bytes.Equal(db.PrefixedKey(key), iter.Key()) {
-			iter.Prev()
+		if err := db.db.Write(batch, nil); err != nil {
+			return i, err
 		}
-		k = db.UnprefixedKey(bytes.Clone(iter.Key()))
-		v = bytes.Clone(iter.Value())
-	} else {
+	}
+	return len(keys), nil
+}
+
+// SetDeleteBatchSize sets the number of the keys deleted in a batch by PrefixedDeleteRange.
+func (db *LevelDB) SetDeleteBatchSize(size int) {
+	db.deleteBatchSize = size
+}
+
+// SeekPrevInclusiveKey seeks the previous key-value pair in the database with prefixing the keys.
+//
+// @dev: `LevelDB.prefix + prefix` is used as the prefix for the iteration.
+func (db *LevelDB) SeekPrevInclusiveKey(prefix []byte, key []byte) (k []byte, v []byte, err error) {
+	err = db.PrefixedReverseIterate(prefix, key, func(key, value []byte) (bool, error) {
+		k, v = key, value
+		return true, nil
+	})
+	if err == nil && k == nil {
 		err = dbtypes.ErrNotFound
 	}
 	return k, v, err
@@ -178,20 +216,21 @@ func (db *LevelDB) SeekPrevInclusiveKey(prefix []byte, key []byte) (k []byte, v
 // WithPrefix returns a new LevelDB with the given prefix.
 func (db *LevelDB) WithPrefix(prefix []byte) types.DB {
 	return &LevelDB{
-		db:     db.db,
-		prefix: db.PrefixedKey(prefix),
+		db:              db.db,
+		prefix:          db.PrefixedKey(prefix),
+		deleteBatchSize: db.deleteBatchSize,
 	}
 }
 
 // PrefixedKey prefixes the key with the LevelDB.prefix.
 func (db LevelDB) PrefixedKey(key []byte) []byte {
-	return append(append(db.prefix, dbtypes.Splitter), key...)
+	return prefixedKey(db.prefix, key)
 }
 
 // UnprefixedKey remove the prefix from the key, only
 // if the key has the prefix.
 func (db LevelDB) UnprefixedKey(key []byte) []byte {
-	return bytes.TrimPrefix(key, append(db.prefix, dbtypes.Splitter))
+	return unprefixedKey(db.prefix, key)
 }
 
 func (db LevelDB) GetPath() string {
@@ -199,9 +238,44 @@ func (db LevelDB) GetPath() string {
 }
 
 func (db LevelDB) GetPrefix() []byte {
-	splits := bytes.Split(db.prefix, []byte{dbtypes.Splitter})
+	return lastPrefix(db.prefix)
+}
+
+// The keys of the db prefixes are shared by the backends, so they have the same key ordering.
+
+// prefixedKey allocates the key, as appending to the db prefix may overwrite the keys prefixed before.
+func prefixedKey(dbPrefix []byte, key []byte) []byte {
+	prefixed := make([]byte, 0, len(dbPrefix)+1+len(key))
+	return append(append(append(prefixed, dbPrefix...), dbtypes.Splitter), key...)
+}
+
+func unprefixedKey(dbPrefix []byte, key []byte) []byte {
+	return bytes.TrimPrefix(key, append(dbPrefix, dbtypes.Splitter))
+}
+
+func lastPrefix(dbPrefix []byte) []byte {
+	splits := bytes.Split(dbPrefix, []byte{dbtypes.Splitter})
 	if len(splits) == 0 {
 		return nil
 	}
 	return splits[len(splits)-1]
 }
+
+// prefixedRange returns the range of the prefix of the db prefix bounded by [start, end), or nil if the range is empty.
+func prefixedRange(dbPrefix []byte, prefix []byte, start []byte, end []byte) *util.Range {
+	r := util.BytesPrefix(prefixedKey(dbPrefix, prefix))
+	if start != nil {
+		if prefixedStart := prefixedKey(dbPrefix, start); bytes.Compare(prefixedStart, r.Start) > 0 {
+			r.Start = prefixedStart
+		}
+	}
+	if end != nil {
+		if prefixedEnd := prefixedKey(dbPrefix, end); r.Limit == nil || bytes.Compare(prefixedEnd, r.Limit) < 0 {
+			r.Limit = prefixedEnd
+		}
+	}
+	if r.Limit != nil && bytes.Compare(r.Start, r.Limit) >= 0 {
+		return nil
+	}
+	return r
+}
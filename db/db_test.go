@@ -0,0 +1,311 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// testBackends are the backends which the conformance tests of the types.DB run against.
+var testBackends = []struct {
+	name  string
+	newDB func(t *testing.T) types.DB
+}{
+	{"leveldb", newTestLevelDB},
+	{"memdb", func(*testing.T) types.DB { return NewMemDB() }},
+}
+
+func newTestLevelDB(t *testing.T) types.DB {
+	db, err := NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// forEachBackend runs the test against each backend.
+func forEachBackend(t *testing.T, test func(t *testing.T, newDB func(t *testing.T) types.DB)) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			test(t, backend.newDB)
+		})
+	}
+}
+
+func newTestDB(t *testing.T, newDB func(t *testing.T) types.DB) types.DB {
+	db := newDB(t)
+
+	// the keys of the db prefix which has the test db prefix as its prefix
+	require.NoError(t, db.WithPrefix([]byte("tests")).Set([]byte("a/2"), []byte("other")))
+
+	db = db.WithPrefix([]byte("test"))
+	for _, key := range []string{"a/1", "a/3", "a/5", "ab/2", "b/1"} {
+		require.NoError(t, db.Set([]byte(key), []byte("value-"+key)))
+	}
+	return db
+}
+
+func collect(t *testing.T, iterate func(cb func(key, value []byte) (bool, error)) error) []string {
+	keys := make([]string, 0)
+	err := iterate(func(key, value []byte) (bool, error) {
+		require.Equal(t, "value-"+string(key), string(value))
+		keys = append(keys, string(key))
+		return false, nil
+	})
+	require.NoError(t, err)
+	return keys
+}
+
+func Test_PrefixedRangeIterate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newDB func(t *testing.T) types.DB) {
+		db := newTestDB(t, newDB)
+
+		cases := []struct {
+			name       string
+			prefix     string
+			start, end []byte
+			expected   []string
+		}{
+			{"whole prefix", "a/", nil, nil, []string{"a/1", "a/3", "a/5"}},
+			{"start inclusive", "a/", []byte("a/3"), nil, []string{"a/3", "a/5"}},
+			{"end exclusive", "a/", nil, []byte("a/5"), []string{"a/1", "a/3"}},
+			{"bounded", "a/", []byte("a/2"), []byte("a/4"), []string{"a/3"}},
+			{"exact boundary", "a/", []byte("a/3"), []byte("a/3"), []string{}},
+			{"start after end", "a/", []byte("a/5"), []byte("a/1"), []string{}},
+			{"start before prefix", "a/", []byte("0"), []byte("a/2"), []string{"a/1"}},
+			{"end after prefix", "a/", []byte("a/4"), []byte("c"), []string{"a/5"}},
+			{"range out of prefix", "a/", []byte("b"), []byte("c"), []string{}},
+			{"empty prefix", "c/", nil, nil, []string{}},
+			{"prefix of other prefix", "a", nil, nil, []string{"a/1", "a/3", "a/5", "ab/2"}},
+			{"prefix with splitter", "a/", []byte("a"), []byte("b"), []string{"a/1", "a/3", "a/5"}},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				keys := collect(t, func(cb func(key, value []byte) (bool, error)) error {
+					return db.PrefixedRangeIterate([]byte(tc.prefix), tc.start, tc.end, cb)
+				})
+				require.Equal(t, tc.expected, keys)
+			})
+		}
+
+		// PrefixedIterate is the range iteration without the end
+		keys := collect(t, func(cb func(key, value []byte) (bool, error)) error {
+			return db.PrefixedIterate([]byte("a/"), []byte("a/2"), cb)
+		})
+		require.Equal(t, []string{"a/3", "a/5"}, keys)
+
+		// stop the iteration
+		count := 0
+		require.NoError(t, db.PrefixedRangeIterate([]byte("a/"), nil, nil, func(_, _ []byte) (bool, error) {
+			count++
+			return count == 2, nil
+		}))
+		require.Equal(t, 2, count)
+	})
+}
+
+func Test_PrefixedReverseIterate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newDB func(t *testing.T) types.DB) {
+		db := newTestDB(t, newDB)
+
+		cases := []struct {
+			name     string
+			prefix   string
+			start    []byte
+			expected []string
+		}{
+			{"whole prefix", "a/", nil, []string{"a/5", "a/3", "a/1"}},
+			{"start inclusive", "a/", []byte("a/3"), []string{"a/3", "a/1"}},
+			{"start between keys", "a/", []byte("a/4"), []string{"a/3", "a/1"}},
+			{"start before prefix", "a/", []byte("0"), []string{}},
+			{"start before first key", "a/", []byte("a/0"), []string{}},
+			{"start after prefix", "a/", []byte("c"), []string{"a/5", "a/3", "a/1"}},
+			{"empty prefix", "c/", nil, []string{}},
+			{"prefix of other prefix", "a", nil, []string{"ab/2", "a/5", "a/3", "a/1"}},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				keys := collect(t, func(cb func(key, value []byte) (bool, error)) error {
+					return db.PrefixedReverseIterate([]byte(tc.prefix), tc.start, cb)
+				})
+				require.Equal(t, tc.expected, keys)
+			})
+		}
+	})
+}
+
+func Test_SeekPrevInclusiveKey(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newDB func(t *testing.T) types.DB) {
+		db := newTestDB(t, newDB)
+
+		cases := []struct {
+			name     string
+			prefix   string
+			key      string
+			expected string
+		}{
+			{"exact key", "a/", "a/3", "a/3"},
+			{"between keys", "a/", "a/4", "a/3"},
+			{"after last key", "a/", "a/9", "a/5"},
+			{"after prefix", "a/", "b", "a/5"},
+			{"before first key", "a/", "a/0", ""},
+			{"empty prefix", "c/", "c/1", ""},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				key, value, err := db.SeekPrevInclusiveKey([]byte(tc.prefix), []byte(tc.key))
+				if tc.expected == "" {
+					require.ErrorIs(t, err, dbtypes.ErrNotFound)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, string(key))
+				require.Equal(t, "value-"+tc.expected, string(value))
+			})
+		}
+	})
+}
+
+func Test_PrefixedDeleteRange(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newDB func(t *testing.T) types.DB) {
+		testPrefixedDeleteRange(t, newTestDB(t, newDB))
+	})
+
+	for _, batchSize := range []int{1, 2} {
+		db := newTestDB(t, newTestLevelDB)
+		db.(*LevelDB).SetDeleteBatchSize(batchSize)
+
+		// the prefix db inherits the batch size
+		require.Equal(t, batchSize, db.WithPrefix([]byte("sub")).(*LevelDB).deleteBatchSize)
+		testPrefixedDeleteRange(t, db)
+	}
+}
+
+func testPrefixedDeleteRange(t *testing.T, db types.DB) {
+	count, err := db.PrefixedDeleteRange([]byte("a/"), []byte("a/3"), []byte("a/3"))
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	count, err = db.PrefixedDeleteRange([]byte("a/"), []byte("a/2"), nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	keys := collect(t, func(cb func(key, value []byte) (bool, error)) error {
+		return db.PrefixedIterate(nil, nil, cb)
+	})
+	require.Equal(t, []string{"a/1", "ab/2", "b/1"}, keys)
+
+	// the prefix of the other prefix
+	count, err = db.PrefixedDeleteRange([]byte("a"), nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	keys = collect(t, func(cb func(key, value []byte) (bool, error)) error {
+		return db.PrefixedIterate(nil, nil, cb)
+	})
+	require.Equal(t, []string{"b/1"}, keys)
+}
+
+func Test_GetSetDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newDB func(t *testing.T) types.DB) {
+		db := newTestDB(t, newDB)
+
+		value, err := db.Get([]byte("a/1"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("value-a/1"), value)
+
+		// the keys of the other db prefixes are not visible
+		_, err = db.Get([]byte("a/2"))
+		require.ErrorIs(t, err, dbtypes.ErrNotFound)
+
+		require.NoError(t, db.Delete([]byte("a/1")))
+		_, err = db.Get([]byte("a/1"))
+		require.ErrorIs(t, err, dbtypes.ErrNotFound)
+
+		// the nil value is deleted in the batch
+		require.NoError(t, db.BatchSet(
+			types.KV{Key: []byte("a/3"), Value: nil},
+			types.KV{Key: []byte("c/1"), Value: []byte("value-c/1")},
+		))
+		require.NoError(t, db.RawBatchSet(types.RawKV{Key: db.PrefixedKey([]byte("a/5")), Value: nil}))
+		keys := collect(t, func(cb func(key, value []byte) (bool, error)) error {
+			return db.PrefixedIterate(nil, nil, cb)
+		})
+		require.Equal(t, []string{"ab/2", "b/1", "c/1"}, keys)
+
+		sub := db.WithPrefix([]byte("sub"))
+		require.Equal(t, []byte("sub"), sub.GetPrefix())
+		require.NoError(t, sub.Set([]byte("key"), []byte("value")))
+		value, err = db.Get([]byte("sub/key"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), value)
+		require.Equal(t, []byte("key"), sub.UnprefixedKey(sub.PrefixedKey([]byte("key"))))
+	})
+}
+
+func Test_WriteWhileIterate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newDB func(t *testing.T) types.DB) {
+		db := newTestDB(t, newDB)
+
+		// the iteration doesn't see the writes made during it
+		keys := collect(t, func(cb func(key, value []byte) (bool, error)) error {
+			return db.PrefixedIterate([]byte("a/"), nil, func(key, value []byte) (bool, error) {
+				if err := db.Delete(key); err != nil {
+					return true, err
+				}
+				if err := db.Set([]byte("a/9"), []byte("value-a/9")); err != nil {
+					return true, err
+				}
+				return cb(key, value)
+			})
+		})
+		require.Equal(t, []string{"a/1", "a/3", "a/5"}, keys)
+
+		keys = collect(t, func(cb func(key, value []byte) (bool, error)) error {
+			return db.PrefixedIterate([]byte("a/"), nil, cb)
+		})
+		require.Equal(t, []string{"a/9"}, keys)
+	})
+}
+
+func Benchmark_PrefixedDeleteRange(b *testing.B) {
+	const numKeys = 10_000
+	newDB := func() types.DB {
+		db, err := NewDB(b.TempDir())
+		require.NoError(b, err)
+		b.Cleanup(func() { db.Close() })
+
+		kvs := make([]types.KV, 0, numKeys)
+		for i := uint64(0); i < numKeys; i++ {
+			kvs = append(kvs, types.KV{Key: append([]byte("key/"), dbtypes.FromUint64Key(i)...), Value: []byte("value")})
+		}
+		require.NoError(b, db.BatchSet(kvs...))
+		return db
+	}
+
+	// delete the keys one by one in the iteration as the rewinds did
+	b.Run("per-key", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			db := newDB()
+			b.StartTimer()
+			err := db.PrefixedIterate([]byte("key"), nil, func(key, _ []byte) (bool, error) {
+				return false, db.Delete(key)
+			})
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			db := newDB()
+			b.StartTimer()
+			count, err := db.PrefixedDeleteRange([]byte("key"), nil, nil)
+			require.NoError(b, err)
+			require.Equal(b, numKeys, count)
+		}
+	})
+}
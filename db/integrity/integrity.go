@@ -0,0 +1,116 @@
+package integrity
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// QuarantineKey is the prefix of the root db which the undecodable records are moved to by the repair.
+var QuarantineKey = []byte("quarantine")
+
+// ErrCorruptedDB is returned when the db has the undecodable records which are not repaired.
+var ErrCorruptedDB = errors.New("db has undecodable records")
+
+// PrefixedQuarantineKey returns the quarantine key of the raw key of a record.
+func PrefixedQuarantineKey(rawKey []byte) []byte {
+	return append(append(QuarantineKey, dbtypes.Splitter), rawKey...)
+}
+
+// Check decodes the records under the prefix of the db.
+type Check struct {
+	Name   string
+	DB     types.DB
+	Prefix []byte
+	// Decode returns an error if the value is not decodable.
+	Decode func(key, value []byte) error
+	// SampleSize is the maximum number of the records to decode, from the last one which is the most
+	// likely to be corrupted by a power loss. If it is 0, all the records are decoded.
+	SampleSize int
+}
+
+// BadRecord is an undecodable record, whose key is the hex of the raw key.
+type BadRecord struct {
+	Check       string `json:"check"`
+	Key         string `json:"key"`
+	Error       string `json:"error"`
+	Quarantined bool   `json:"quarantined"`
+}
+
+type Report struct {
+	Checked    int         `json:"checked"`
+	BadRecords []BadRecord `json:"bad_records"`
+}
+
+// Run decodes the records of the checks. With the repair, the undecodable records are moved to
+// the quarantine prefix of the db, so they are not read by the handlers; otherwise it returns ErrCorruptedDB.
+func Run(db types.DB, logger *zap.Logger, repair bool, checks ...Check) (Report, error) {
+	report := Report{BadRecords: make([]BadRecord, 0)}
+	for _, check := range checks {
+		checked, badKVs, err := runCheck(check)
+		if err != nil {
+			return report, fmt.Errorf("failed to check %s records: %w", check.Name, err)
+		}
+		report.Checked += checked
+
+		for _, bad := range badKVs {
+			record := BadRecord{
+				Check: check.Name,
+				Key:   hex.EncodeToString(bad.kv.Key),
+				Error: bad.err.Error(),
+			}
+			if repair {
+				err := db.RawBatchSet(
+					types.RawKV{Key: bad.kv.Key, Value: nil},
+					types.RawKV{Key: db.PrefixedKey(PrefixedQuarantineKey(bad.kv.Key)), Value: bad.kv.Value},
+				)
+				if err != nil {
+					return report, err
+				}
+				record.Quarantined = true
+			}
+			logger.Error("undecodable db record",
+				zap.String("check", record.Check),
+				zap.String("key", record.Key),
+				zap.String("error", record.Error),
+				zap.Bool("quarantined", record.Quarantined),
+			)
+			report.BadRecords = append(report.BadRecords, record)
+		}
+	}
+
+	logger.Info("db integrity checked", zap.Int("checked", report.Checked), zap.Int("bad_records", len(report.BadRecords)))
+	if len(report.BadRecords) > 0 && !repair {
+		return report, fmt.Errorf("%w: %d records; restart with the repair to quarantine them", ErrCorruptedDB, len(report.BadRecords))
+	}
+	return report, nil
+}
+
+type badKV struct {
+	kv  types.RawKV
+	err error
+}
+
+// runCheck decodes the records of the check from the last one, and returns the undecodable ones with their raw keys.
+func runCheck(check Check) (checked int, badKVs []badKV, err error) {
+	err = check.DB.PrefixedReverseIterate(check.Prefix, nil, func(key, value []byte) (bool, error) {
+		checked++
+		if err := check.Decode(key, value); err != nil {
+			if value == nil {
+				// the nil value is the deletion in the batch
+				value = []byte{}
+			}
+			badKVs = append(badKVs, badKV{
+				kv:  types.RawKV{Key: check.DB.PrefixedKey(key), Value: value},
+				err: err,
+			})
+		}
+		return check.SampleSize > 0 && checked >= check.SampleSize, nil
+	})
+	return checked, badKVs, err
+}
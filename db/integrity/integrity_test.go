@@ -0,0 +1,111 @@
+package integrity
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func uint64Check(db types.DB, sampleSize int) Check {
+	return Check{
+		Name:   "values",
+		DB:     db,
+		Prefix: []byte("value"),
+		Decode: func(_, value []byte) error {
+			_, err := dbtypes.ToUint64(value)
+			return err
+		},
+		SampleSize: sampleSize,
+	}
+}
+
+func setValues(t *testing.T, db types.DB, values ...string) {
+	for i, value := range values {
+		require.NoError(t, db.Set(append([]byte("value/"), dbtypes.FromUint64Key(uint64(i))...), []byte(value)))
+	}
+}
+
+func Test_Run(t *testing.T) {
+	root := db.NewMemDB()
+	nodeDB := root.WithPrefix([]byte("node"))
+	setValues(t, nodeDB, "1", "garbage", "3", "")
+
+	report, err := Run(root, zap.NewNop(), false, uint64Check(nodeDB, 0))
+	require.ErrorIs(t, err, ErrCorruptedDB)
+	require.Equal(t, 4, report.Checked)
+	require.Len(t, report.BadRecords, 2)
+	for _, record := range report.BadRecords {
+		require.Equal(t, "values", record.Check)
+		require.False(t, record.Quarantined)
+		require.NotEmpty(t, record.Error)
+	}
+
+	// nothing is moved without the repair
+	_, err = nodeDB.Get(append([]byte("value/"), dbtypes.FromUint64Key(1)...))
+	require.NoError(t, err)
+
+	report, err = Run(root, zap.NewNop(), true, uint64Check(nodeDB, 0))
+	require.NoError(t, err)
+	require.Len(t, report.BadRecords, 2)
+	for _, index := range []uint64{1, 3} {
+		key := append([]byte("value/"), dbtypes.FromUint64Key(index)...)
+		_, err = nodeDB.Get(key)
+		require.ErrorIs(t, err, dbtypes.ErrNotFound)
+
+		// the record is moved to the quarantine prefix with its raw key
+		require.True(t, record(report, nodeDB.PrefixedKey(key)).Quarantined)
+		_, err = root.Get(PrefixedQuarantineKey(nodeDB.PrefixedKey(key)))
+		require.NoError(t, err)
+	}
+
+	// the quarantined records are not checked again
+	report, err = Run(root, zap.NewNop(), false, uint64Check(nodeDB, 0))
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Checked)
+	require.Empty(t, report.BadRecords)
+}
+
+func record(report Report, rawKey []byte) BadRecord {
+	for _, record := range report.BadRecords {
+		if record.Key == hex.EncodeToString(rawKey) {
+			return record
+		}
+	}
+	return BadRecord{}
+}
+
+func Test_RunSampled(t *testing.T) {
+	root := db.NewMemDB()
+	setValues(t, root, "garbage", "2", "3", "4")
+
+	// only the last records are decoded
+	report, err := Run(root, zap.NewNop(), false, uint64Check(root, 2))
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Checked)
+
+	report, err = Run(root, zap.NewNop(), false, uint64Check(root, 4))
+	require.ErrorIs(t, err, ErrCorruptedDB)
+	require.Equal(t, 4, report.Checked)
+	require.Len(t, report.BadRecords, 1)
+}
+
+type failingDB struct {
+	types.DB
+}
+
+func (failingDB) PrefixedReverseIterate([]byte, []byte, func([]byte, []byte) (bool, error)) error {
+	return errors.New("io error")
+}
+
+func Test_RunIterationError(t *testing.T) {
+	root := db.NewMemDB()
+	_, err := Run(root, zap.NewNop(), true, uint64Check(failingDB{root}, 0))
+	require.ErrorContains(t, err, "failed to check values records: io error")
+}
@@ -0,0 +1,278 @@
+package db
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/google/btree"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+var _ types.DB = (*MemDB)(nil)
+
+// memDBDegree is the degree of the btree of the MemDB.
+const memDBDegree = 32
+
+type memItem struct {
+	key   []byte
+	value []byte
+}
+
+func memItemLess(a, b memItem) bool {
+	return bytes.Compare(a.key, b.key) < 0
+}
+
+// memStore is the btree shared by the prefixes of a MemDB.
+type memStore struct {
+	mtx  sync.Mutex
+	tree *btree.BTreeG[memItem]
+}
+
+// snapshot returns a copy-on-write clone of the tree, which is not affected by the later writes.
+// The iterations run on the snapshot, so the callbacks can write to the db like the leveldb iterators.
+func (s *memStore) snapshot() *btree.BTreeG[memItem] {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.tree.Clone()
+}
+
+// write applies the key-value pairs atomically, where a nil value is a deletion.
+func (s *memStore) write(kvs ...types.RawKV) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, kv := range kvs {
+		if kv.Value == nil {
+			s.tree.Delete(memItem{key: kv.Key})
+		} else {
+			s.tree.ReplaceOrInsert(memItem{key: bytes.Clone(kv.Key), value: bytes.Clone(kv.Value)})
+		}
+	}
+}
+
+// MemDB is an in-memory types.DB backed by a btree, which has the same key ordering as the LevelDB.
+// It is the recommended backend for the tests, as it doesn't need a temp dir.
+type MemDB struct {
+	store  *memStore
+	prefix []byte
+}
+
+func NewMemDB() *MemDB {
+	return &MemDB{
+		store: &memStore{tree: btree.NewG(memDBDegree, memItemLess)},
+	}
+}
+
+// RawBatchSet sets the key-value pairs in the database without prefixing the keys.
+func (db *MemDB) RawBatchSet(kvs ...types.RawKV) error {
+	db.store.write(kvs...)
+	return nil
+}
+
+// BatchSet sets the key-value pairs in the database with prefixing the keys.
+func (db *MemDB) BatchSet(kvs ...types.KV) error {
+	rawKVs := make([]types.RawKV, 0, len(kvs))
+	for _, kv := range kvs {
+		rawKVs = append(rawKVs, types.RawKV{Key: db.PrefixedKey(kv.Key), Value: kv.Value})
+	}
+	db.store.write(rawKVs...)
+	return nil
+}
+
+// Set sets the key-value pair in the database with prefixing the key.
+func (db *MemDB) Set(key []byte, value []byte) error {
+	if value == nil {
+		// leveldb stores the nil value as an empty value
+		value = []byte{}
+	}
+	db.store.write(types.RawKV{Key: db.PrefixedKey(key), Value: value})
+	return nil
+}
+
+// Get gets the value of the key in the database with prefixing the key.
+func (db *MemDB) Get(key []byte) ([]byte, error) {
+	db.store.mtx.Lock()
+	item, ok := db.store.tree.Get(memItem{key: db.PrefixedKey(key)})
+	db.store.mtx.Unlock()
+	if !ok {
+		return nil, dbtypes.ErrNotFound
+	}
+	return bytes.Clone(item.value), nil
+}
+
+// Delete deletes the key in the database with prefixing the key.
+func (db *MemDB) Delete(key []byte) error {
+	db.store.write(types.RawKV{Key: db.PrefixedKey(key)})
+	return nil
+}
+
+// Close does nothing, as the data is dropped with the MemDB.
+func (db *MemDB) Close() error {
+	return nil
+}
+
+// PrefixedIterate iterates over the key-value pairs in the database with prefixing the keys.
+//
+// @dev: `MemDB.prefix + prefix` is used as the prefix for the iteration.
+func (db *MemDB) PrefixedIterate(prefix []byte, start []byte, cb func(key, value []byte) (stop bool, err error)) error {
+	return db.PrefixedRangeIterate(prefix, start, nil, cb)
+}
+
+// PrefixedRangeIterate iterates over the key-value pairs of the prefix in the range [start, end).
+//
+// @dev: `MemDB.prefix + prefix` is used as the prefix for the iteration.
+func (db *MemDB) PrefixedRangeIterate(prefix []byte, start []byte, end []byte, cb func(key, value []byte) (stop bool, err error)) error {
+	r := prefixedRange(db.prefix, prefix, start, end)
+	if r == nil {
+		return nil
+	}
+	return db.iterate(r, false, cb)
+}
+
+// PrefixedReverseIterate iterates over the key-value pairs of the prefix in reverse order, from start inclusive.
+//
+// @dev: `MemDB.prefix + prefix` is used as the prefix for the iteration.
+func (db *MemDB) PrefixedReverseIterate(prefix []byte, start []byte, cb func(key, value []byte) (stop bool, err error)) error {
+	var end []byte
+	if start != nil {
+		// the smallest key after start, to include start
+		end = append(bytes.Clone(start), 0)
+	}
+	r := prefixedRange(db.prefix, prefix, nil, end)
+	if r == nil {
+		return nil
+	}
+	return db.iterate(r, true, cb)
+}
+
+// iterate iterates over the snapshot of the range, which is not empty.
+func (db *MemDB) iterate(r *util.Range, reverse bool, cb func(key, value []byte) (stop bool, err error)) (iterErr error) {
+	fn := func(item memItem) bool {
+		stop, err := cb(db.UnprefixedKey(bytes.Clone(item.key)), bytes.Clone(item.value))
+		if err != nil {
+			iterErr = err
+			return false
+		}
+		return !stop
+	}
+
+	tree := db.store.snapshot()
+	start := memItem{key: r.Start}
+	if !reverse {
+		if r.Limit == nil {
+			tree.AscendGreaterOrEqual(start, fn)
+		} else {
+			tree.AscendRange(start, memItem{key: r.Limit}, fn)
+		}
+		return
+	}
+
+	reverseFn := func(item memItem) bool {
+		if r.Limit != nil && bytes.Compare(item.key, r.Limit) >= 0 {
+			// the limit is exclusive
+			return true
+		} else if bytes.Compare(item.key, r.Start) < 0 {
+			return false
+		}
+		return fn(item)
+	}
+	if r.Limit == nil {
+		tree.Descend(reverseFn)
+	} else {
+		tree.DescendLessOrEqual(memItem{key: r.Limit}, reverseFn)
+	}
+	return
+}
+
+// PrefixedDeleteRange deletes the keys of the prefix in the range [start, end) atomically
+// and returns the number of the deleted keys.
+//
+// @dev: `MemDB.prefix + prefix` is used as the prefix for the iteration.
+func (db *MemDB) PrefixedDeleteRange(prefix []byte, start []byte, end []byte) (int, error) {
+	kvs := make([]types.RawKV, 0)
+	err := db.PrefixedRangeIterate(prefix, start, end, func(key, _ []byte) (bool, error) {
+		kvs = append(kvs, types.RawKV{Key: db.PrefixedKey(key)})
+		return false, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	db.store.write(kvs...)
+	return len(kvs), nil
+}
+
+// SeekPrevInclusiveKey seeks the previous key-value pair in the database with prefixing the keys.
+//
+// @dev: `MemDB.prefix + prefix` is used as the prefix for the iteration.
+func (db *MemDB) SeekPrevInclusiveKey(prefix []byte, key []byte) (k []byte, v []byte, err error) {
+	err = db.PrefixedReverseIterate(prefix, key, func(key, value []byte) (bool, error) {
+		k, v = key, value
+		return true, nil
+	})
+	if err == nil && k == nil {
+		err = dbtypes.ErrNotFound
+	}
+	return k, v, err
+}
+
+// WithPrefix returns a new MemDB with the given prefix, which shares the data.
+func (db *MemDB) WithPrefix(prefix []byte) types.DB {
+	return &MemDB{
+		store:  db.store,
+		prefix: db.PrefixedKey(prefix),
+	}
+}
+
+// PrefixedKey prefixes the key with the MemDB.prefix.
+func (db MemDB) PrefixedKey(key []byte) []byte {
+	return prefixedKey(db.prefix, key)
+}
+
+// UnprefixedKey remove the prefix from the key, only
+// if the key has the prefix.
+func (db MemDB) UnprefixedKey(key []byte) []byte {
+	return unprefixedKey(db.prefix, key)
+}
+
+// GetPath returns an empty path, as the MemDB is not stored on disk.
+func (db MemDB) GetPath() string {
+	return ""
+}
+
+func (db MemDB) GetPrefix() []byte {
+	return lastPrefix(db.prefix)
+}
+
+// Snapshot writes the whole database to a new leveldb at dstPath, which can be opened with OpenSnapshot.
+func (db *MemDB) Snapshot(dstPath string) error {
+	tree := db.store.snapshot()
+	return writeSnapshot(dstPath, func(cb func(key, value []byte) error) (err error) {
+		tree.Ascend(func(item memItem) bool {
+			err = cb(item.key, item.value)
+			return err == nil
+		})
+		return err
+	})
+}
+
+// Stats returns the exact key counts and sizes of the prefixes. The disk sizes are always 0.
+//
+// @dev: `MemDB.prefix + prefix` is used as the prefix for the statistics.
+func (db *MemDB) Stats(prefixes ...[]byte) ([]types.PrefixStats, error) {
+	stats := make([]types.PrefixStats, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		s := types.PrefixStats{Prefix: string(prefix)}
+		err := db.PrefixedIterate(prefix, nil, func(key, value []byte) (bool, error) {
+			s.KeyCount++
+			s.RawSize += uint64(len(db.PrefixedKey(key)) + len(value))
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
@@ -0,0 +1,91 @@
+package migration
+
+import (
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// batchWrites are the writes of a migration buffered by the raw keys, where a nil value is a deletion.
+type batchWrites struct {
+	kvs    []types.RawKV
+	values map[string][]byte
+}
+
+func (w *batchWrites) add(key []byte, value []byte) {
+	w.kvs = append(w.kvs, types.RawKV{Key: key, Value: value})
+	w.values[string(key)] = value
+}
+
+// batchDB buffers the writes of a migration to apply them atomically with the schema version.
+// Get reads the buffered writes, while the iterations read only the data before the migration.
+type batchDB struct {
+	types.DB
+
+	writes *batchWrites
+}
+
+var _ types.DB = batchDB{}
+
+func newBatchDB(db types.DB) batchDB {
+	return batchDB{
+		DB:     db,
+		writes: &batchWrites{values: make(map[string][]byte)},
+	}
+}
+
+func (b batchDB) Get(key []byte) ([]byte, error) {
+	if value, ok := b.writes.values[string(b.PrefixedKey(key))]; ok {
+		if value == nil {
+			return nil, dbtypes.ErrNotFound
+		}
+		return value, nil
+	}
+	return b.DB.Get(key)
+}
+
+func (b batchDB) Set(key []byte, value []byte) error {
+	b.writes.add(b.PrefixedKey(key), value)
+	return nil
+}
+
+func (b batchDB) Delete(key []byte) error {
+	b.writes.add(b.PrefixedKey(key), nil)
+	return nil
+}
+
+func (b batchDB) BatchSet(kvs ...types.KV) error {
+	for _, kv := range kvs {
+		b.writes.add(b.PrefixedKey(kv.Key), kv.Value)
+	}
+	return nil
+}
+
+func (b batchDB) RawBatchSet(kvs ...types.RawKV) error {
+	for _, kv := range kvs {
+		b.writes.add(kv.Key, kv.Value)
+	}
+	return nil
+}
+
+func (b batchDB) PrefixedDeleteRange(prefix []byte, start []byte, end []byte) (int, error) {
+	count := 0
+	err := b.PrefixedRangeIterate(prefix, start, end, func(key, _ []byte) (bool, error) {
+		b.writes.add(b.PrefixedKey(key), nil)
+		count++
+		return false, nil
+	})
+	return count, err
+}
+
+// WithPrefix returns the batch db of the prefix, which shares the buffered writes.
+func (b batchDB) WithPrefix(prefix []byte) types.DB {
+	return batchDB{
+		DB:     b.DB.WithPrefix(prefix),
+		writes: b.writes,
+	}
+}
+
+// Close does nothing, as the db is owned by the runner of the migrations.
+func (b batchDB) Close() error {
+	return nil
+}
@@ -0,0 +1,158 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// SchemaVersionKey is the key of the schema version, which is stored under the prefix of the schema.
+var SchemaVersionKey = []byte("schema_version")
+
+// ErrSchemaVersionTooNew is returned when the db is migrated by a newer bot than the running one.
+var ErrSchemaVersionTooNew = errors.New("db schema version is newer than the bot")
+
+// ErrSchemaMigrationPending is returned when the db has the data of an older schema version,
+// which must be migrated by `opinitd migrate schema` before the bot starts.
+var ErrSchemaMigrationPending = errors.New("db schema migration is pending")
+
+// Migration upgrades the data under the prefix of a schema by one version.
+type Migration interface {
+	Up(db types.DB) error
+}
+
+// MigrationFn is a Migration of a function.
+type MigrationFn func(db types.DB) error
+
+func (fn MigrationFn) Up(db types.DB) error {
+	return fn(db)
+}
+
+// Schema is the on-disk schema of the data under a prefix of the db. The migrations upgrade the data
+// from the version of their index to the next one, so the latest version is the number of the migrations.
+type Schema struct {
+	Name       string
+	DB         types.DB
+	Migrations []Migration
+}
+
+// Version returns the latest schema version of the running bot.
+func (s Schema) Version() uint64 {
+	return uint64(len(s.Migrations))
+}
+
+func PrefixedSchemaVersionKey(name string) []byte {
+	return append(append(SchemaVersionKey, dbtypes.Splitter), []byte(name)...)
+}
+
+// GetSchemaVersion returns the schema version stored in the db. The db which has no schema version
+// is created before the schema versioning, so its version is 0.
+func GetSchemaVersion(db types.DB, name string) (uint64, error) {
+	value, err := db.Get(PrefixedSchemaVersionKey(name))
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return dbtypes.ToUint64(value)
+}
+
+// Run applies the pending migrations of the schemas in order, which is called by `opinitd migrate schema`. Each migration is applied atomically
+// with its schema version in a batch, so a failed migration is retried from the same version on the next run.
+// It refuses to run if the schema version of the db is newer than the one of the running bot.
+func Run(logger *zap.Logger, schemas ...Schema) error {
+	for _, schema := range schemas {
+		version, err := checkSchemaVersion(schema)
+		if err != nil {
+			return err
+		}
+
+		for ; version < schema.Version(); version++ {
+			batch := newBatchDB(schema.DB)
+			err := schema.Migrations[version].Up(batch)
+			if err != nil {
+				return fmt.Errorf("failed to migrate %s schema to version %d: %w", schema.Name, version+1, err)
+			}
+
+			kvs := append(batch.writes.kvs, types.RawKV{
+				Key:   schema.DB.PrefixedKey(PrefixedSchemaVersionKey(schema.Name)),
+				Value: dbtypes.FromUint64(version + 1),
+			})
+			err = schema.DB.RawBatchSet(kvs...)
+			if err != nil {
+				return err
+			}
+
+			logger.Info("db schema migrated",
+				zap.String("schema", schema.Name),
+				zap.String("prefix", string(schema.DB.GetPrefix())),
+				zap.Uint64("version", version+1),
+			)
+		}
+	}
+	return nil
+}
+
+// Init checks that the schemas of the db are at the versions of the running bot before the bot starts.
+// The schema without data, e.g. of the new db, is set to the latest version as it has nothing to migrate,
+// while the schema with the data of an older version must be migrated by `opinitd migrate schema`.
+func Init(schemas ...Schema) error {
+	for _, schema := range schemas {
+		version, err := checkSchemaVersion(schema)
+		if err != nil {
+			return err
+		} else if version == schema.Version() {
+			continue
+		}
+
+		empty, err := isEmpty(schema.DB)
+		if err != nil {
+			return err
+		} else if !empty {
+			return fmt.Errorf("%w: %s schema version of the db %d, bot %d; run `opinitd migrate schema`",
+				ErrSchemaMigrationPending, schema.Name, version, schema.Version())
+		}
+		err = schema.DB.Set(PrefixedSchemaVersionKey(schema.Name), dbtypes.FromUint64(schema.Version()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmpty returns true if the db has no data under its prefix.
+func isEmpty(db types.DB) (bool, error) {
+	empty := true
+	err := db.PrefixedIterate(nil, nil, func(_, _ []byte) (bool, error) {
+		empty = false
+		return true, nil
+	})
+	return empty, err
+}
+
+// Check checks that the schema versions of the db are not newer than the ones of the running bot,
+// without applying the migrations.
+func Check(schemas ...Schema) error {
+	for _, schema := range schemas {
+		if _, err := checkSchemaVersion(schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchemaVersion returns the schema version of the db, or an error if it is newer than the one of the running bot.
+func checkSchemaVersion(schema Schema) (uint64, error) {
+	version, err := GetSchemaVersion(schema.DB, schema.Name)
+	if err != nil {
+		return 0, err
+	} else if version > schema.Version() {
+		return 0, fmt.Errorf("%w: %s schema version of the db %d, bot %d; upgrade the bot",
+			ErrSchemaVersionTooNew, schema.Name, version, schema.Version())
+	}
+	return version, nil
+}
@@ -0,0 +1,136 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_Run(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	schemaDB := db.WithPrefix([]byte("node"))
+	require.NoError(t, schemaDB.Set([]byte("key"), []byte("v0")))
+
+	migrations := []Migration{
+		MigrationFn(func(db types.DB) error {
+			value, err := db.Get([]byte("key"))
+			require.NoError(t, err)
+			require.Equal(t, []byte("v0"), value)
+			return db.Set([]byte("key"), []byte("v1"))
+		}),
+		MigrationFn(func(db types.DB) error {
+			// the buffered writes are visible to the migration
+			require.NoError(t, db.Set([]byte("tmp"), []byte("tmp")))
+			value, err := db.Get([]byte("tmp"))
+			require.NoError(t, err)
+			require.Equal(t, []byte("tmp"), value)
+			require.NoError(t, db.Delete([]byte("tmp")))
+			_, err = db.Get([]byte("tmp"))
+			require.ErrorIs(t, err, dbtypes.ErrNotFound)
+
+			value, err = db.Get([]byte("key"))
+			require.NoError(t, err)
+			require.Equal(t, []byte("v1"), value)
+			return db.WithPrefix([]byte("sub")).Set([]byte("key"), []byte("v2"))
+		}),
+	}
+
+	// the db without the schema version is at version 0
+	version, err := GetSchemaVersion(schemaDB, "node")
+	require.NoError(t, err)
+	require.Zero(t, version)
+
+	require.NoError(t, Run(zap.NewNop(), Schema{Name: "node", DB: schemaDB, Migrations: migrations[:1]}))
+	version, err = GetSchemaVersion(schemaDB, "node")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), version)
+
+	// the applied migrations are skipped
+	require.NoError(t, Run(zap.NewNop(), Schema{Name: "node", DB: schemaDB, Migrations: migrations}))
+	version, err = GetSchemaVersion(schemaDB, "node")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), version)
+
+	value, err := schemaDB.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), value)
+	value, err = schemaDB.WithPrefix([]byte("sub")).Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), value)
+	_, err = schemaDB.Get([]byte("tmp"))
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+
+	// the older bot refuses to run
+	err = Run(zap.NewNop(), Schema{Name: "node", DB: schemaDB, Migrations: migrations[:1]})
+	require.ErrorIs(t, err, ErrSchemaVersionTooNew)
+
+	// the schemas of the same name under the other prefixes are versioned apart
+	version, err = GetSchemaVersion(db.WithPrefix([]byte("other")), "node")
+	require.NoError(t, err)
+	require.Zero(t, version)
+}
+
+func Test_RunFailedMigration(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations := []Migration{
+		MigrationFn(func(db types.DB) error {
+			require.NoError(t, db.Set([]byte("key1"), []byte("value")))
+			require.NoError(t, db.Set([]byte("key2"), []byte("value")))
+			return errors.New("failed")
+		}),
+	}
+
+	// the writes of the failed migration are discarded with its schema version
+	require.Error(t, Run(zap.NewNop(), Schema{Name: "merkle", DB: db, Migrations: migrations}))
+	version, err := GetSchemaVersion(db, "merkle")
+	require.NoError(t, err)
+	require.Zero(t, version)
+	_, err = db.Get([]byte("key1"))
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+}
+
+func Test_Init(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations := []Migration{
+		MigrationFn(func(db types.DB) error {
+			return db.Set([]byte("key"), []byte("v1"))
+		}),
+	}
+
+	// the schema without data is set to the latest version
+	freshDB := db.WithPrefix([]byte("fresh"))
+	require.NoError(t, Init(Schema{Name: "node", DB: freshDB, Migrations: migrations}))
+	version, err := GetSchemaVersion(freshDB, "node")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), version)
+	_, err = freshDB.Get([]byte("key"))
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+
+	// the schema with the data of an older version must be migrated first
+	schemaDB := db.WithPrefix([]byte("node"))
+	require.NoError(t, schemaDB.Set([]byte("key"), []byte("v0")))
+	err = Init(Schema{Name: "node", DB: schemaDB, Migrations: migrations})
+	require.ErrorIs(t, err, ErrSchemaMigrationPending)
+
+	require.NoError(t, Run(zap.NewNop(), Schema{Name: "node", DB: schemaDB, Migrations: migrations}))
+	require.NoError(t, Init(Schema{Name: "node", DB: schemaDB, Migrations: migrations}))
+
+	// the older bot refuses to run
+	err = Init(Schema{Name: "node", DB: schemaDB})
+	require.ErrorIs(t, err, ErrSchemaVersionTooNew)
+}
@@ -0,0 +1,150 @@
+package pruning
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// WatermarkKey is the prefix of the last prune watermarks of the policies.
+var WatermarkKey = []byte("prune_watermark")
+
+func PrefixedWatermarkKey(name string) []byte {
+	return append(append(WatermarkKey, dbtypes.Splitter), []byte(name)...)
+}
+
+// Policy prunes a class of the data. Prune deletes the data from the watermark of the last prune,
+// and returns the new watermark with the number of the deleted keys.
+type Policy interface {
+	Name() string
+	Prune(ctx context.Context, watermark uint64) (uint64, int, error)
+}
+
+// Pruner runs the pruning policies periodically, and records the watermark of each policy
+// so the policies resume from it after a restart.
+type Pruner struct {
+	db       types.DB
+	logger   *zap.Logger
+	interval time.Duration
+	policies []Policy
+}
+
+func NewPruner(db types.DB, logger *zap.Logger, interval time.Duration, policies ...Policy) *Pruner {
+	return &Pruner{
+		db:       db,
+		logger:   logger,
+		interval: interval,
+		policies: policies,
+	}
+}
+
+// GetWatermark returns the watermark of the last prune of the policy, which is 0 if it has never run.
+func (p Pruner) GetWatermark(name string) (uint64, error) {
+	value, err := p.db.Get(PrefixedWatermarkKey(name))
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return dbtypes.ToUint64(value)
+}
+
+// Prune runs the policies once in order. The failure of a policy is logged and doesn't stop the others.
+func (p Pruner) Prune(ctx context.Context) error {
+	var errs []error
+	for _, policy := range p.policies {
+		if err := p.prunePolicy(ctx, policy); err != nil {
+			p.logger.Error("failed to prune", zap.String("policy", policy.Name()), zap.String("error", err.Error()))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p Pruner) prunePolicy(ctx context.Context, policy Policy) error {
+	watermark, err := p.GetWatermark(policy.Name())
+	if err != nil {
+		return err
+	}
+
+	next, count, err := policy.Prune(ctx, watermark)
+	if err != nil {
+		return err
+	} else if next == watermark {
+		return nil
+	}
+
+	err = p.db.Set(PrefixedWatermarkKey(policy.Name()), dbtypes.FromUint64(next))
+	if err != nil {
+		return err
+	}
+	p.logger.Info("pruned",
+		zap.String("policy", policy.Name()),
+		zap.Int("count", count),
+		zap.Uint64("watermark", next),
+	)
+	return nil
+}
+
+// Start runs the policies at every interval until the context is done.
+func (p *Pruner) Start(ctx context.Context) {
+	types.ErrGrp(ctx).Go(func() error {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			// the errors are logged, and the policies are retried at the next interval
+			_ = p.Prune(ctx)
+		}
+	})
+}
+
+// RangePolicy prunes the keys under the prefix ordered by a uint64, such as the timestamps or the versions,
+// which are below the cutoff.
+type RangePolicy struct {
+	PolicyName string
+	DB         types.DB
+	Prefix     []byte
+	// KeyFn returns the key of the uint64 under the prefix.
+	KeyFn func(uint64) []byte
+	// CutoffFn returns the uint64 from which the keys are kept.
+	CutoffFn func(ctx context.Context) (uint64, error)
+}
+
+var _ Policy = RangePolicy{}
+
+func (r RangePolicy) Name() string {
+	return r.PolicyName
+}
+
+func (r RangePolicy) Prune(ctx context.Context, watermark uint64) (uint64, int, error) {
+	cutoff, err := r.CutoffFn(ctx)
+	if err != nil {
+		return watermark, 0, err
+	} else if cutoff <= watermark {
+		return watermark, 0, nil
+	}
+
+	count, err := r.DB.PrefixedDeleteRange(r.Prefix, r.KeyFn(watermark), r.KeyFn(cutoff))
+	if err != nil {
+		return watermark, count, err
+	}
+	return cutoff, count, nil
+}
+
+// RetentionCutoff returns the cutoff of the keys by the unix nano timestamps, which keeps the keys in the retention.
+func RetentionCutoff(now func() time.Time, retention time.Duration) func(context.Context) (uint64, error) {
+	return func(context.Context) (uint64, error) {
+		return types.SafeInt64ToUint64(now().Add(-retention).UnixNano())
+	}
+}
@@ -0,0 +1,158 @@
+package pruning
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func prefixedKey(prefix string) func(uint64) []byte {
+	return func(v uint64) []byte {
+		return append([]byte(prefix+"/"), dbtypes.FromUint64Key(v)...)
+	}
+}
+
+func keys(t *testing.T, db types.DB, prefix string) []uint64 {
+	values := make([]uint64, 0)
+	err := db.PrefixedIterate([]byte(prefix), nil, func(key, _ []byte) (bool, error) {
+		values = append(values, dbtypes.ToUint64Key(key[len(key)-8:]))
+		return false, nil
+	})
+	require.NoError(t, err)
+	return values
+}
+
+// countingDB counts the keys iterated by the range deletions.
+type countingDB struct {
+	types.DB
+	iterated *int
+}
+
+func (c countingDB) PrefixedDeleteRange(prefix []byte, start []byte, end []byte) (int, error) {
+	err := c.PrefixedRangeIterate(prefix, start, end, func(_, _ []byte) (bool, error) {
+		*c.iterated++
+		return false, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return c.DB.PrefixedDeleteRange(prefix, start, end)
+}
+
+func Test_RangePolicy(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	for i := uint64(1); i <= 10; i++ {
+		require.NoError(t, db.Set(prefixedKey("version")(i), []byte("value")))
+	}
+
+	iterated := 0
+	latest := uint64(10)
+	policy := RangePolicy{
+		PolicyName: "versions",
+		DB:         countingDB{DB: db, iterated: &iterated},
+		Prefix:     []byte("version"),
+		KeyFn:      prefixedKey("version"),
+		// keep the last 3 versions
+		CutoffFn: func(context.Context) (uint64, error) {
+			return latest - 2, nil
+		},
+	}
+	pruner := NewPruner(db, zap.NewNop(), time.Hour, policy)
+
+	require.NoError(t, pruner.Prune(context.Background()))
+	require.Equal(t, []uint64{8, 9, 10}, keys(t, db, "version"))
+	watermark, err := pruner.GetWatermark("versions")
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), watermark)
+	require.Equal(t, 7, iterated)
+
+	// the restarted pruner resumes from the watermark
+	for i := uint64(11); i <= 12; i++ {
+		require.NoError(t, db.Set(prefixedKey("version")(i), []byte("value")))
+	}
+	// the keys below the watermark, e.g. written by a rewind, are not rescanned
+	require.NoError(t, db.Set(prefixedKey("version")(1), []byte("value")))
+	latest = 12
+	iterated = 0
+	pruner = NewPruner(db, zap.NewNop(), time.Hour, policy)
+	require.NoError(t, pruner.Prune(context.Background()))
+	require.Equal(t, []uint64{1, 10, 11, 12}, keys(t, db, "version"))
+	require.Equal(t, 2, iterated)
+
+	// nothing to prune below the watermark
+	latest = 5
+	require.NoError(t, pruner.Prune(context.Background()))
+	watermark, err = pruner.GetWatermark("versions")
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), watermark)
+}
+
+func Test_RetentionCutoff(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Unix(0, 0).Add(8 * 24 * time.Hour)
+	for _, ts := range []time.Time{
+		now.Add(-8 * 24 * time.Hour),
+		now.Add(-7*24*time.Hour - time.Nanosecond),
+		now.Add(-7 * 24 * time.Hour),
+		now.Add(-time.Hour),
+	} {
+		require.NoError(t, db.Set(prefixedKey("processed_msgs")(uint64(ts.UnixNano())), []byte("msgs")))
+	}
+
+	pruner := NewPruner(db, zap.NewNop(), time.Hour, RangePolicy{
+		PolicyName: "processed_msgs",
+		DB:         db,
+		Prefix:     []byte("processed_msgs"),
+		KeyFn:      prefixedKey("processed_msgs"),
+		CutoffFn:   RetentionCutoff(func() time.Time { return now }, 7*24*time.Hour),
+	})
+	require.NoError(t, pruner.Prune(context.Background()))
+	require.Equal(t, []uint64{
+		uint64(now.Add(-7 * 24 * time.Hour).UnixNano()),
+		uint64(now.Add(-time.Hour).UnixNano()),
+	}, keys(t, db, "processed_msgs"))
+}
+
+type failingPolicy struct{}
+
+func (failingPolicy) Name() string { return "failing" }
+
+func (failingPolicy) Prune(_ context.Context, watermark uint64) (uint64, int, error) {
+	return watermark, 0, errors.New("l1 unavailable")
+}
+
+func Test_PrunerFailingPolicy(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.Set(prefixedKey("version")(1), []byte("value")))
+	pruner := NewPruner(db, zap.NewNop(), time.Hour, failingPolicy{}, RangePolicy{
+		PolicyName: "versions",
+		DB:         db,
+		Prefix:     []byte("version"),
+		KeyFn:      prefixedKey("version"),
+		CutoffFn:   func(context.Context) (uint64, error) { return 2, nil },
+	})
+
+	// the failure of a policy doesn't stop the others
+	require.Error(t, pruner.Prune(context.Background()))
+	require.Empty(t, keys(t, db, "version"))
+	watermark, err := pruner.GetWatermark("failing")
+	require.NoError(t, err)
+	require.Zero(t, watermark)
+}
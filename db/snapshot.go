@@ -0,0 +1,197 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// SnapshotManifestFileName is the name of the manifest file written in the snapshot directory.
+const SnapshotManifestFileName = "opinit_snapshot.json"
+
+// snapshotBatchSize is the number of the key-value pairs written to the snapshot in a batch.
+const snapshotBatchSize = 10000
+
+// SnapshotManifest is written with the snapshot to validate it before the restore.
+type SnapshotManifest struct {
+	KeyCount  uint64    `json:"key_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Snapshot writes a consistent snapshot of the whole database to a new leveldb at dstPath,
+// while the database is being written. The prefix of the LevelDB is not applied,
+// so every prefix shares the same snapshot.
+func (db *LevelDB) Snapshot(dstPath string) error {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	return writeSnapshot(dstPath, func(cb func(key, value []byte) error) error {
+		iter := snap.NewIterator(nil, nil)
+		defer iter.Release()
+
+		for iter.Next() {
+			if err := cb(iter.Key(), iter.Value()); err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+}
+
+// writeSnapshot writes the key-value pairs of the iteration to a new leveldb at dstPath with the manifest.
+func writeSnapshot(dstPath string, iterate func(cb func(key, value []byte) error) error) (err error) {
+	if _, err := os.Stat(dstPath); err == nil {
+		return fmt.Errorf("snapshot path already exists: %s", dstPath)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	dst, err := leveldb.OpenFile(dstPath, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.RemoveAll(dstPath)
+		}
+	}()
+
+	manifest := SnapshotManifest{CreatedAt: time.Now().UTC()}
+	batch := new(leveldb.Batch)
+	err = iterate(func(key, value []byte) error {
+		// the batch copies the key and the value
+		batch.Put(key, value)
+		manifest.KeyCount++
+
+		if batch.Len() >= snapshotBatchSize {
+			if err := dst.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := dst.Write(batch, nil); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dstPath, SnapshotManifestFileName), data, 0o600)
+}
+
+// ReadSnapshotManifest reads the manifest of the snapshot at the path.
+func ReadSnapshotManifest(path string) (SnapshotManifest, error) {
+	data, err := os.ReadFile(filepath.Join(path, SnapshotManifestFileName))
+	if err != nil {
+		return SnapshotManifest{}, err
+	}
+
+	var manifest SnapshotManifest
+	err = json.Unmarshal(data, &manifest)
+	return manifest, err
+}
+
+// OpenSnapshot opens the snapshot at the path read-only after checking the number of its keys
+// against the manifest, which fails if the snapshot is torn or modified.
+func OpenSnapshot(path string) (types.DB, SnapshotManifest, error) {
+	manifest, err := ReadSnapshotManifest(path)
+	if err != nil {
+		return nil, SnapshotManifest{}, fmt.Errorf("failed to read snapshot manifest: %w", err)
+	}
+
+	snapDB, err := leveldb.OpenFile(path, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+	if err != nil {
+		return nil, SnapshotManifest{}, err
+	}
+
+	var keyCount uint64
+	iter := snapDB.NewIterator(nil, nil)
+	for iter.Next() {
+		keyCount++
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		snapDB.Close()
+		return nil, SnapshotManifest{}, err
+	} else if keyCount != manifest.KeyCount {
+		snapDB.Close()
+		return nil, SnapshotManifest{}, fmt.Errorf("snapshot key count mismatch: %d, manifest: %d", keyCount, manifest.KeyCount)
+	}
+
+	return &LevelDB{
+		db:              snapDB,
+		path:            path,
+		deleteBatchSize: DefaultDeleteBatchSize,
+	}, manifest, nil
+}
+
+// RestoreSnapshot replaces the database at dbPath with the snapshot, after validating the snapshot
+// with its manifest and the validate function. The replaced database is kept at the returned path.
+// The database must not be opened while it is restored.
+func RestoreSnapshot(snapshotPath string, dbPath string, validate func(types.DB) error) (backupPath string, err error) {
+	snapDB, _, err := OpenSnapshot(snapshotPath)
+	if err != nil {
+		return "", err
+	}
+	defer snapDB.Close()
+
+	if validate != nil {
+		if err := validate(snapDB); err != nil {
+			return "", err
+		}
+	}
+
+	// copy the snapshot next to the database first, so the database is replaced only by a complete copy
+	// and the snapshot can be restored again
+	restorePath := dbPath + ".restore"
+	if err := os.RemoveAll(restorePath); err != nil {
+		return "", err
+	}
+	if err := snapDB.Snapshot(restorePath); err != nil {
+		return "", err
+	}
+	if err := os.Remove(filepath.Join(restorePath, SnapshotManifestFileName)); err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		backupPath = fmt.Sprintf("%s.replaced-%d", dbPath, time.Now().Unix())
+		if err := os.Rename(dbPath, backupPath); err != nil {
+			return "", err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return backupPath, os.Rename(restorePath, dbPath)
+}
+
+// SnapshotPath returns the path of the snapshot of the name in the backup directory. The name
+// defaults to the current time.
+func SnapshotPath(backupDir string, name string) (string, error) {
+	if name == "" {
+		name = time.Now().UTC().Format("20060102T150405Z")
+	} else if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid snapshot name: %s", name)
+	}
+	return filepath.Join(backupDir, name), nil
+}
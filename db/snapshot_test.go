@@ -0,0 +1,166 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_Snapshot(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	// each write sets the keys of both prefixes atomically, so a consistent snapshot has the same number of keys of both
+	write := func(i int) error {
+		key := []byte(fmt.Sprintf("%08d", i))
+		return db.RawBatchSet(
+			types.RawKV{Key: db.WithPrefix([]byte("a")).PrefixedKey(key), Value: key},
+			types.RawKV{Key: db.WithPrefix([]byte("b")).PrefixedKey(key), Value: key},
+		)
+	}
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, write(i))
+	}
+
+	// back up under the write load
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1000; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err := write(i); err != nil {
+				panic(err)
+			}
+		}
+	}()
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot")
+	err = db.WithPrefix([]byte("a")).Snapshot(snapshotPath)
+	close(stop)
+	wg.Wait()
+	require.NoError(t, err)
+
+	// the snapshot path must be new
+	require.Error(t, db.Snapshot(snapshotPath))
+
+	snapDB, manifest, err := OpenSnapshot(snapshotPath)
+	require.NoError(t, err)
+
+	counts := make(map[string]uint64)
+	for _, prefix := range []string{"a", "b"} {
+		err := snapDB.WithPrefix([]byte(prefix)).PrefixedIterate(nil, nil, func(key, value []byte) (bool, error) {
+			require.Equal(t, key, value)
+			counts[prefix]++
+			return false, nil
+		})
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, counts["a"], uint64(1000))
+	require.Equal(t, counts["a"], counts["b"])
+	require.Equal(t, counts["a"]+counts["b"], manifest.KeyCount)
+
+	// the snapshot is read-only
+	require.Error(t, snapDB.Set([]byte("key"), []byte("value")))
+	require.NoError(t, snapDB.Close())
+
+	// the torn snapshot is rejected
+	manifest.KeyCount++
+	require.NoError(t, os.WriteFile(filepath.Join(snapshotPath, SnapshotManifestFileName), []byte(fmt.Sprintf(`{"key_count":%d}`, manifest.KeyCount)), 0o600))
+	_, _, err = OpenSnapshot(snapshotPath)
+	require.ErrorContains(t, err, "key count mismatch")
+}
+
+func Test_RestoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "executor.db")
+	snapshotPath := filepath.Join(dir, "snapshot")
+
+	db, err := NewDB(dbPath)
+	require.NoError(t, err)
+	require.NoError(t, db.Set([]byte("key"), []byte("snapshot")))
+	require.NoError(t, db.Snapshot(snapshotPath))
+	require.NoError(t, db.Set([]byte("key"), []byte("live")))
+	require.NoError(t, db.Close())
+
+	// the invalid snapshot does not replace the db
+	_, err = RestoreSnapshot(snapshotPath, dbPath, func(types.DB) error {
+		return errors.New("schema version too new")
+	})
+	require.Error(t, err)
+
+	backupPath, err := RestoreSnapshot(snapshotPath, dbPath, func(snapDB types.DB) error {
+		value, err := snapDB.Get([]byte("key"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("snapshot"), value)
+		return nil
+	})
+	require.NoError(t, err)
+
+	db, err = NewDB(dbPath)
+	require.NoError(t, err)
+	value, err := db.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("snapshot"), value)
+	_, err = os.Stat(filepath.Join(dbPath, SnapshotManifestFileName))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, db.Close())
+
+	// the replaced db is kept
+	db, err = NewDB(backupPath)
+	require.NoError(t, err)
+	value, err = db.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("live"), value)
+	_, err = db.Get([]byte("missing"))
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+	require.NoError(t, db.Close())
+}
+
+func Test_SnapshotPath(t *testing.T) {
+	path, err := SnapshotPath("backups", "daily")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("backups", "daily"), path)
+
+	path, err = SnapshotPath("backups", "")
+	require.NoError(t, err)
+	require.Equal(t, "backups", filepath.Dir(path))
+
+	for _, name := range []string{"../daily", "a/b", "..", "."} {
+		_, err := SnapshotPath("backups", name)
+		require.Error(t, err, name)
+	}
+}
+
+func Test_MemDBSnapshot(t *testing.T) {
+	db := NewMemDB()
+	for _, prefix := range []string{"a", "b"} {
+		require.NoError(t, db.WithPrefix([]byte(prefix)).Set([]byte("key"), []byte("value-"+prefix)))
+	}
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot")
+	require.NoError(t, db.Snapshot(snapshotPath))
+
+	snapDB, manifest, err := OpenSnapshot(snapshotPath)
+	require.NoError(t, err)
+	defer snapDB.Close()
+	require.Equal(t, uint64(2), manifest.KeyCount)
+
+	value, err := snapDB.WithPrefix([]byte("b")).Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value-b"), value)
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"bytes"
+
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// statsSampleSize is the maximum number of the keys scanned for the statistics of a prefix.
+const statsSampleSize = 100_000
+
+// Stats returns the approximate key counts and sizes of the prefixes. The keys of each prefix are scanned
+// up to the sample size, and the statistics of the prefixes over the sample size are extrapolated
+// with the disk size of the scanned keys.
+//
+// @dev: `LevelDB.prefix + prefix` is used as the prefix for the statistics.
+func (db *LevelDB) Stats(prefixes ...[]byte) ([]types.PrefixStats, error) {
+	stats := make([]types.PrefixStats, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		s, err := db.prefixStats(prefix, statsSampleSize)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func (db *LevelDB) prefixStats(prefix []byte, sampleSize uint64) (types.PrefixStats, error) {
+	stats := types.PrefixStats{Prefix: string(prefix)}
+
+	r := util.BytesPrefix(db.PrefixedKey(prefix))
+	sizes, err := db.db.SizeOf([]util.Range{*r})
+	if err != nil {
+		return types.PrefixStats{}, err
+	}
+	stats.DiskSize = types.MustInt64ToUint64(sizes.Sum())
+
+	iter := db.db.NewIterator(r, nil)
+	defer iter.Release()
+
+	// the first key after the sample
+	var sampleLimit []byte
+	for iter.Next() {
+		if stats.KeyCount == sampleSize {
+			stats.Sampled = true
+			sampleLimit = bytes.Clone(iter.Key())
+			break
+		}
+		stats.KeyCount++
+		stats.RawSize += uint64(len(iter.Key()) + len(iter.Value()))
+	}
+	if err := iter.Error(); err != nil {
+		return types.PrefixStats{}, err
+	}
+	if !stats.Sampled {
+		return stats, nil
+	}
+
+	// extrapolate the sample with the ratio of the disk sizes, which is unknown
+	// if the sampled keys are not flushed to disk yet
+	sampledRange := util.Range{Start: r.Start, Limit: sampleLimit}
+	sampledSizes, err := db.db.SizeOf([]util.Range{sampledRange})
+	if err != nil {
+		return types.PrefixStats{}, err
+	}
+	if sampledSize := sampledSizes.Sum(); sampledSize > 0 && stats.DiskSize > uint64(sampledSize) {
+		ratio := float64(stats.DiskSize) / float64(sampledSize)
+		stats.KeyCount = uint64(float64(stats.KeyCount) * ratio)
+		stats.RawSize = uint64(float64(stats.RawSize) * ratio)
+	}
+	return stats, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_Stats(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	child := db.WithPrefix([]byte(types.ChildName))
+	merkle := child.WithPrefix([]byte(types.MerkleName))
+	for i := uint64(0); i < 100; i++ {
+		require.NoError(t, merkle.Set(dbtypes.FromUint64Key(i), make([]byte, 100)))
+	}
+	require.NoError(t, child.Set([]byte("withdrawal"), []byte("value")))
+	require.NoError(t, db.WithPrefix([]byte("child_other")).Set([]byte("key"), []byte("value")))
+
+	stats, err := db.Stats(
+		dbtypes.PrefixOf(types.ChildName),
+		dbtypes.PrefixOf(types.ChildName, types.MerkleName),
+		dbtypes.PrefixOf(types.HostName),
+	)
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+
+	require.Equal(t, "child/", stats[0].Prefix)
+	require.Equal(t, uint64(101), stats[0].KeyCount)
+	require.Equal(t, "child/merkle/", stats[1].Prefix)
+	require.Equal(t, uint64(100), stats[1].KeyCount)
+	require.Equal(t, types.PrefixStats{Prefix: "host/"}, stats[2])
+	for _, s := range stats[:2] {
+		require.False(t, s.Sampled)
+		require.Greater(t, s.RawSize, 100*s.KeyCount/2)
+	}
+	require.Greater(t, stats[0].RawSize, stats[1].RawSize)
+
+	// the sizes on disk are known after the flush
+	require.NoError(t, db.(*LevelDB).db.CompactRange(util.Range{}))
+	stats, err = db.Stats(dbtypes.PrefixOf(types.ChildName, types.MerkleName))
+	require.NoError(t, err)
+	require.Greater(t, stats[0].DiskSize, uint64(0))
+}
+
+func Test_StatsSampled(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	kvs := make([]types.KV, 0)
+	for i := uint64(0); i < 100_000; i++ {
+		kvs = append(kvs, types.KV{Key: append([]byte("node/"), dbtypes.FromUint64Key(i)...), Value: dbtypes.FromUint64Key(i * 7919)})
+	}
+	require.NoError(t, db.BatchSet(kvs...))
+	require.NoError(t, db.(*LevelDB).db.CompactRange(util.Range{}))
+
+	exact, err := db.(*LevelDB).prefixStats([]byte("node/"), 1_000_000)
+	require.NoError(t, err)
+	require.False(t, exact.Sampled)
+	require.Equal(t, uint64(100_000), exact.KeyCount)
+
+	sampled, err := db.(*LevelDB).prefixStats([]byte("node/"), 10_000)
+	require.NoError(t, err)
+	require.True(t, sampled.Sampled)
+	require.Equal(t, exact.DiskSize, sampled.DiskSize)
+
+	// the estimate is only sanity checked, as the disk sizes are approximate
+	require.Greater(t, sampled.KeyCount, exact.KeyCount/2)
+	require.Less(t, sampled.KeyCount, exact.KeyCount*2)
+	require.Greater(t, sampled.RawSize, exact.RawSize/2)
+	require.Less(t, sampled.RawSize, exact.RawSize*2)
+}
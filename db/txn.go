@@ -0,0 +1,109 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// ErrInvalidTxn is returned when a txn misses an entry which must be committed with another one.
+var ErrInvalidTxn = errors.New("invalid db txn")
+
+// TxnEntryType is the type of the kvs appended to a Txn.
+type TxnEntryType uint8
+
+const (
+	// TxnEntryOther is the kvs which have no pairing rules, such as the withdrawals.
+	TxnEntryOther TxnEntryType = iota
+	TxnEntrySyncInfo
+	TxnEntryWorkingTree
+	TxnEntryFinalizedTree
+	TxnEntryProcessedMsgs
+	TxnEntrySubmissionInfo
+)
+
+func (t TxnEntryType) String() string {
+	switch t {
+	case TxnEntryOther:
+		return "other"
+	case TxnEntrySyncInfo:
+		return "sync_info"
+	case TxnEntryWorkingTree:
+		return "working_tree"
+	case TxnEntryFinalizedTree:
+		return "finalized_tree"
+	case TxnEntryProcessedMsgs:
+		return "processed_msgs"
+	case TxnEntrySubmissionInfo:
+		return "submission_info"
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(t))
+}
+
+// txnPairings are the entries which must be committed with each entry type. The state of a block is
+// only consistent with the sync info of the block, so it is never committed without the sync info.
+var txnPairings = map[TxnEntryType][]TxnEntryType{
+	TxnEntryWorkingTree: {TxnEntrySyncInfo},
+	// the next working tree starts from the finalized one
+	TxnEntryFinalizedTree:  {TxnEntrySyncInfo, TxnEntryWorkingTree},
+	TxnEntryProcessedMsgs:  {TxnEntrySyncInfo},
+	TxnEntrySubmissionInfo: {TxnEntrySyncInfo},
+}
+
+// Txn builds the kvs of an atomic write across the prefixed stores. The entries are typed,
+// so the missing pairings are caught before the commit instead of leaving a partial state.
+type Txn struct {
+	db      types.DB
+	kvs     []types.RawKV
+	entries map[TxnEntryType]bool
+}
+
+func NewTxn(db types.DB) *Txn {
+	return &Txn{
+		db:      db,
+		kvs:     make([]types.RawKV, 0),
+		entries: make(map[TxnEntryType]bool),
+	}
+}
+
+// Add appends the kvs of the entry type. The entry is recorded even without the kvs,
+// e.g. the finalization of an empty tree.
+func (t *Txn) Add(entryType TxnEntryType, kvs ...types.RawKV) {
+	t.kvs = append(t.kvs, kvs...)
+	t.entries[entryType] = true
+}
+
+// Has returns whether the entry type is added to the txn.
+func (t *Txn) Has(entryType TxnEntryType) bool {
+	return t.entries[entryType]
+}
+
+// KVs returns the kvs of the txn in the order of the additions.
+func (t *Txn) KVs() []types.RawKV {
+	return t.kvs
+}
+
+// Validate checks that every entry of the txn is accompanied by its pairings.
+func (t *Txn) Validate() error {
+	// iterate the entry types in order for a deterministic error
+	for entryType := TxnEntryOther; entryType <= TxnEntrySubmissionInfo; entryType++ {
+		if !t.entries[entryType] {
+			continue
+		}
+		for _, pairing := range txnPairings[entryType] {
+			if !t.entries[pairing] {
+				return fmt.Errorf("%w: %s entry without %s entry", ErrInvalidTxn, entryType, pairing)
+			}
+		}
+	}
+	return nil
+}
+
+// Commit validates the txn and writes its kvs atomically.
+func (t *Txn) Commit() error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	return t.db.RawBatchSet(t.kvs...)
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_TxnValidate(t *testing.T) {
+	kv := func(db types.DB, key string) types.RawKV {
+		return types.RawKV{Key: db.PrefixedKey([]byte(key)), Value: []byte("value")}
+	}
+
+	cases := []struct {
+		name    string
+		entries []TxnEntryType
+		err     string
+	}{
+		{"sync info only", []TxnEntryType{TxnEntrySyncInfo}, ""},
+		{"other only", []TxnEntryType{TxnEntryOther}, ""},
+		{"end block", []TxnEntryType{TxnEntryOther, TxnEntryFinalizedTree, TxnEntryWorkingTree, TxnEntrySyncInfo, TxnEntryProcessedMsgs}, ""},
+		{"finalized tree without sync info", []TxnEntryType{TxnEntryFinalizedTree, TxnEntrySubmissionInfo}, "finalized_tree entry without sync_info entry"},
+		{"finalized tree without working tree", []TxnEntryType{TxnEntryFinalizedTree, TxnEntrySyncInfo}, "finalized_tree entry without working_tree entry"},
+		{"working tree without sync info", []TxnEntryType{TxnEntryWorkingTree}, "working_tree entry without sync_info entry"},
+		{"processed msgs without sync info", []TxnEntryType{TxnEntryOther, TxnEntryProcessedMsgs}, "processed_msgs entry without sync_info entry"},
+		{"submission info without sync info", []TxnEntryType{TxnEntrySubmissionInfo}, "submission_info entry without sync_info entry"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := NewDB(t.TempDir())
+			require.NoError(t, err)
+			defer db.Close()
+
+			txn := NewTxn(db)
+			for _, entry := range tc.entries {
+				txn.Add(entry, kv(db, entry.String()))
+			}
+
+			err = txn.Commit()
+			if tc.err == "" {
+				require.NoError(t, err)
+				for _, entry := range tc.entries {
+					value, err := db.Get([]byte(entry.String()))
+					require.NoError(t, err)
+					require.Equal(t, []byte("value"), value)
+				}
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidTxn)
+			require.ErrorContains(t, err, tc.err)
+			// nothing is written on the invalid txn
+			for _, entry := range tc.entries {
+				_, err := db.Get([]byte(entry.String()))
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func Test_TxnEmptyEntry(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	// the finalization of an empty tree has no kvs, but still requires the pairings
+	txn := NewTxn(db)
+	txn.Add(TxnEntryFinalizedTree)
+	require.True(t, txn.Has(TxnEntryFinalizedTree))
+	require.Empty(t, txn.KVs())
+	require.ErrorIs(t, txn.Validate(), ErrInvalidTxn)
+}
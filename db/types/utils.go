@@ -42,3 +42,13 @@ func FromUint64Key(v uint64) []byte {
 func ToUint64Key(data []byte) (v uint64) {
 	return binary.BigEndian.Uint64(data)
 }
+
+// PrefixOf returns the prefix of the keys of the db of the nested prefix names, which ends with the splitter
+// not to match the other prefixes starting with the last name.
+func PrefixOf(names ...string) []byte {
+	prefix := make([]byte, 0)
+	for _, name := range names {
+		prefix = append(append(prefix, []byte(name)...), Splitter)
+	}
+	return prefix
+}
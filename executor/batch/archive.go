@@ -0,0 +1,361 @@
+package batch
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+const (
+	// archiveFileExt is the extension of the archived batch files named `{start}-{end}-{sha256}.batch`.
+	archiveFileExt = ".batch"
+	// archiveQueueSize is the number of the finalized batches waiting to be archived. The batches finalized
+	// while the queue is full are not archived, so the block processing is never blocked by the archive.
+	archiveQueueSize = 16
+)
+
+// ErrBatchArchiveDisabled is returned by the archive operations if the archive directory is not configured.
+var ErrBatchArchiveDisabled = errors.New("batch archive is disabled")
+
+// ArchivedBatch is the finalized batch copied to the archive directory.
+type ArchivedBatch struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+	// Hash is the sha256 hash of the archived file, which names the file.
+	Hash       string    `json:"hash"`
+	Size       int64     `json:"size"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+func (b ArchivedBatch) fileName() string {
+	return fmt.Sprintf("%020d-%020d-%s%s", b.Start, b.End, b.Hash, archiveFileExt)
+}
+
+type archiveRequest struct {
+	start     uint64
+	end       uint64
+	batchData [][]byte
+}
+
+// batchArchive copies the header and the chunks of the finalized batches to the archive directory
+// in the background, and deletes the old batches by the retention policy.
+type batchArchive struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+
+	logger  *zap.Logger
+	metrics *batchMetrics
+
+	queue chan archiveRequest
+	// mu serializes the file operations of the archive writer and the readers
+	mu *sync.Mutex
+}
+
+func newBatchArchive(cfg executortypes.BatchArchiveConfig, homePath string, logger *zap.Logger, metrics *batchMetrics) *batchArchive {
+	dir := cfg.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(homePath, dir)
+	}
+	return &batchArchive{
+		dir:      dir,
+		maxBytes: cfg.MaxBytes,
+		maxAge:   time.Duration(cfg.MaxAge) * time.Second,
+		logger:   logger,
+		metrics:  metrics,
+		queue:    make(chan archiveRequest, archiveQueueSize),
+		mu:       &sync.Mutex{},
+	}
+}
+
+// Start runs the archive writer until the context is done. The failures are logged and counted,
+// and never halt the batch submission.
+func (a *batchArchive) Start(ctx context.Context) {
+	types.ErrGrp(ctx).Go(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case req := <-a.queue:
+				a.handle(req, time.Now())
+			}
+		}
+	})
+}
+
+// enqueue queues the batch data to be archived without blocking.
+func (a *batchArchive) enqueue(start uint64, end uint64, batchData [][]byte) {
+	select {
+	case a.queue <- archiveRequest{start: start, end: end, batchData: batchData}:
+	default:
+		a.logger.Error("batch archive queue is full; skip archiving the batch",
+			zap.Uint64("batch_start", start),
+			zap.Uint64("batch_end", end),
+		)
+		a.metrics.Archives.WithLabelValues("failed").Inc()
+	}
+}
+
+func (a *batchArchive) handle(req archiveRequest, now time.Time) {
+	archived, err := a.write(req.start, req.end, req.batchData, now)
+	if err != nil {
+		a.logger.Error("failed to archive batch",
+			zap.Uint64("batch_start", req.start),
+			zap.Uint64("batch_end", req.end),
+			zap.String("error", err.Error()),
+		)
+		a.metrics.Archives.WithLabelValues("failed").Inc()
+		return
+	}
+	a.metrics.Archives.WithLabelValues("archived").Inc()
+	a.logger.Debug("batch archived",
+		zap.Uint64("batch_start", archived.Start),
+		zap.Uint64("batch_end", archived.End),
+		zap.String("hash", archived.Hash),
+	)
+
+	if err := a.prune(now); err != nil {
+		a.logger.Error("failed to prune batch archive", zap.String("error", err.Error()))
+	}
+}
+
+// write writes the header and the chunks of the batch, each prepended with its length, to the file named
+// by its heights and its hash. The file is written to a temp file and renamed, so a partial file is never archived.
+func (a *batchArchive) write(start uint64, end uint64, batchData [][]byte, now time.Time) (ArchivedBatch, error) {
+	var content bytes.Buffer
+	for _, data := range batchData {
+		content.Write(prependLength(data))
+	}
+	hash := sha256.Sum256(content.Bytes())
+	archived := ArchivedBatch{
+		Start:      start,
+		End:        end,
+		Hash:       hex.EncodeToString(hash[:]),
+		Size:       int64(content.Len()),
+		ArchivedAt: now,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	err := os.MkdirAll(a.dir, 0750)
+	if err != nil {
+		return ArchivedBatch{}, errors.Wrap(err, "failed to create batch archive dir")
+	}
+	filePath := filepath.Join(a.dir, archived.fileName())
+	if _, err := os.Stat(filePath); err == nil {
+		// the same batch is already archived
+		return archived, nil
+	}
+
+	tempPath := filePath + ".tmp"
+	err = os.WriteFile(tempPath, content.Bytes(), 0640)
+	if err != nil {
+		return ArchivedBatch{}, errors.Wrap(err, "failed to write archived batch")
+	}
+	err = os.Chtimes(tempPath, now, now)
+	if err != nil {
+		return ArchivedBatch{}, err
+	}
+	return archived, os.Rename(tempPath, filePath)
+}
+
+// list returns the archived batches in the order of the start height.
+func (a *batchArchive) list() ([]ArchivedBatch, error) {
+	entries, err := os.ReadDir(a.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	batches := make([]ArchivedBatch, 0, len(entries))
+	for _, entry := range entries {
+		archived, ok := parseArchiveFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		archived.Size = info.Size()
+		archived.ArchivedAt = info.ModTime()
+		batches = append(batches, archived)
+	}
+	slices.SortFunc(batches, func(a, b ArchivedBatch) int {
+		if c := cmp.Compare(a.Start, b.Start); c != 0 {
+			return c
+		}
+		return a.ArchivedAt.Compare(b.ArchivedAt)
+	})
+	return batches, nil
+}
+
+func parseArchiveFileName(name string) (ArchivedBatch, bool) {
+	parts := strings.Split(strings.TrimSuffix(name, archiveFileExt), "-")
+	if !strings.HasSuffix(name, archiveFileExt) || len(parts) != 3 {
+		return ArchivedBatch{}, false
+	}
+	start, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return ArchivedBatch{}, false
+	}
+	end, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return ArchivedBatch{}, false
+	}
+	return ArchivedBatch{Start: start, End: end, Hash: parts[2]}, true
+}
+
+// read reads the header and the chunks of the archived batch, which are verified against the hash of the file.
+func (a *batchArchive) read(archived ArchivedBatch) ([][]byte, error) {
+	a.mu.Lock()
+	content, err := os.ReadFile(filepath.Join(a.dir, archived.fileName()))
+	a.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(content)
+	if hex.EncodeToString(hash[:]) != archived.Hash {
+		return nil, fmt.Errorf("archived batch %d-%d is corrupted: hash mismatch", archived.Start, archived.End)
+	}
+
+	batchData := make([][]byte, 0)
+	for offset := 0; offset < len(content); {
+		if len(content)-offset < 8 {
+			return nil, fmt.Errorf("archived batch %d-%d is corrupted: truncated length", archived.Start, archived.End)
+		}
+		length := binary.LittleEndian.Uint64(content[offset : offset+8])
+		offset += 8
+		if length > uint64(len(content)-offset) {
+			return nil, fmt.Errorf("archived batch %d-%d is corrupted: truncated data", archived.Start, archived.End)
+		}
+		batchData = append(batchData, content[offset:offset+int(length)])
+		offset += int(length)
+	}
+	return batchData, nil
+}
+
+// prune deletes the archived batches older than the max age, then the oldest batches
+// until the total size is below the max bytes.
+func (a *batchArchive) prune(now time.Time) error {
+	if a.maxAge == 0 && a.maxBytes == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	batches, err := a.list()
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(batches, func(a, b ArchivedBatch) int {
+		return a.ArchivedAt.Compare(b.ArchivedAt)
+	})
+
+	totalSize := int64(0)
+	for _, archived := range batches {
+		totalSize += archived.Size
+	}
+	for _, archived := range batches {
+		expired := a.maxAge != 0 && now.Sub(archived.ArchivedAt) > a.maxAge
+		if !expired && (a.maxBytes == 0 || totalSize <= a.maxBytes) {
+			break
+		}
+		err := os.Remove(filepath.Join(a.dir, archived.fileName()))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		totalSize -= archived.Size
+		a.logger.Debug("delete archived batch",
+			zap.Uint64("batch_start", archived.Start),
+			zap.Uint64("batch_end", archived.End),
+		)
+	}
+	return nil
+}
+
+// ArchivedBatches returns the batches in the archive directory in the order of the start height.
+func (bs *BatchSubmitter) ArchivedBatches() ([]ArchivedBatch, error) {
+	if bs.archive == nil {
+		return nil, ErrBatchArchiveDisabled
+	}
+
+	bs.archive.mu.Lock()
+	defer bs.archive.mu.Unlock()
+	return bs.archive.list()
+}
+
+// ResubmitArchivedBatches re-submits the archived batches within the l2 block range [start, end] through
+// the current DA node, e.g. when the submitted data is pruned from the DA. The msgs are saved before the
+// broadcast, so they are retried by the broadcaster of the DA after a restart, but they are not tracked as
+// the pending batches. It returns the batches re-submitted before the failure with the error.
+func (bs *BatchSubmitter) ResubmitArchivedBatches(start uint64, end uint64) ([]ArchivedBatch, error) {
+	batches, err := bs.ArchivedBatches()
+	if err != nil {
+		return nil, err
+	}
+
+	da := bs.DA()
+	resubmitted := make([]ArchivedBatch, 0)
+	for i, archived := range batches {
+		if archived.Start < start || archived.End > end {
+			continue
+		} else if i+1 < len(batches) && batches[i+1].Start == archived.Start {
+			// the batch rebuilt from the same height is archived again, and only the last one is re-submitted
+			continue
+		}
+
+		batchData, err := bs.archive.read(archived)
+		if err != nil {
+			return resubmitted, err
+		}
+		processedMsgs, err := createBatchMsgs(da, batchData, true)
+		if err != nil {
+			return resubmitted, err
+		}
+		kvs, err := da.ProcessedMsgsToRawKV(processedMsgs, false)
+		if err != nil {
+			return resubmitted, err
+		}
+		err = bs.db.RawBatchSet(kvs...)
+		if err != nil {
+			return resubmitted, err
+		}
+		for _, msgs := range processedMsgs {
+			err = da.BroadcastMsgs(msgs)
+			if err != nil {
+				return resubmitted, err
+			}
+		}
+
+		bs.logger.Info("resubmit archived batch",
+			zap.Uint64("batch_start", archived.Start),
+			zap.Uint64("batch_end", archived.End),
+			zap.String("hash", archived.Hash),
+			zap.Int("txs", len(processedMsgs)),
+		)
+		resubmitted = append(resubmitted, archived)
+	}
+	return resubmitted, nil
+}
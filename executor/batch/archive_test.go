@@ -0,0 +1,217 @@
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/keys"
+	"github.com/initia-labs/opinit-bots/node"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// recordingDA records the batch data broadcasted to the DA.
+type recordingDA struct {
+	NoopDA
+
+	// the db where the msgs are saved, which is checked before the broadcast if set
+	db types.DB
+
+	mu        sync.Mutex
+	submitted [][]byte
+}
+
+func processedMsgsKey(msgs btypes.ProcessedMsgs) []byte {
+	return []byte("processed_msgs/" + msgs.TraceID)
+}
+
+func (m *recordingDA) ProcessedMsgsToRawKV(processedMsgs []btypes.ProcessedMsgs, _ bool) ([]types.RawKV, error) {
+	if m.db == nil {
+		return nil, nil
+	}
+	kvs := make([]types.RawKV, 0, len(processedMsgs))
+	for _, msgs := range processedMsgs {
+		kvs = append(kvs, types.RawKV{Key: m.db.PrefixedKey(processedMsgsKey(msgs)), Value: []byte(msgs.Sender)})
+	}
+	return kvs, nil
+}
+
+func (m *recordingDA) CreateBatchMsg(data []byte) (sdk.Msg, string, error) {
+	return &ophosttypes.MsgRecordBatch{Submitter: "submitter", BridgeId: 1, BatchBytes: data}, "submitter", nil
+}
+
+func (m *recordingDA) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	if m.db != nil {
+		if _, err := m.db.Get(processedMsgsKey(msgs)); err != nil {
+			return fmt.Errorf("msgs not saved before the broadcast: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, msg := range msgs.Msgs {
+		m.submitted = append(m.submitted, msg.(*ophosttypes.MsgRecordBatch).BatchBytes)
+	}
+	return nil
+}
+
+func newTestArchiveBatchSubmitter(t *testing.T, cfg executortypes.BatchArchiveConfig) (*BatchSubmitter, *recordingDA) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	chain := nodetest.NewFakeClient("l2-1")
+	chain.AppendBlocks(10)
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces})
+	require.NoError(t, err)
+	n, err := node.NewNodeWithRPCClient(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "l2-1",
+		ProcessType:  nodetypes.PROCESS_TYPE_RAW,
+		Bech32Prefix: "init",
+	}, db, zap.NewNop(), cdc, txConfig, chain)
+	require.NoError(t, err)
+
+	da := &recordingDA{}
+	bs := &BatchSubmitter{
+		node:           n,
+		daMu:           &sync.RWMutex{},
+		da:             da,
+		db:             db,
+		logger:         zap.NewNop(),
+		batchCfg:       executortypes.BatchConfig{MaxChunkSize: 100, Archive: cfg},
+		localBatchInfo: &executortypes.LocalBatchInfo{},
+		batchHash:      sha256.New(),
+		compression:    executortypes.BatchCompressionGzip,
+		chunkStatesMu:  &sync.Mutex{},
+		metrics:        newBatchMetrics(""),
+		history:        newBatchHistory(),
+		homePath:       t.TempDir(),
+	}
+	bs.archive = newBatchArchive(cfg, bs.homePath, bs.logger, bs.metrics)
+	require.NoError(t, bs.openBatchTempFile())
+	require.NoError(t, bs.resetBatchWriter())
+	t.Cleanup(bs.Close)
+	return bs, da
+}
+
+func Test_BatchArchive(t *testing.T) {
+	bs, da := newTestArchiveBatchSubmitter(t, executortypes.BatchArchiveConfig{Dir: "archive"})
+	blocks := testBlocks(t, 5)
+
+	// finalize and submit the batch, which is archived in the background
+	bs.localBatchInfo.Start = 1
+	bs.localBatchInfo.End = 5
+	for _, block := range blocks {
+		_, err := bs.handleBatch(block)
+		require.NoError(t, err)
+	}
+	require.NoError(t, bs.finalizeBatch(context.Background(), 5))
+	require.Greater(t, len(bs.processedMsgs), 2)
+	for _, processedMsgs := range bs.processedMsgs {
+		da.BroadcastMsgs(processedMsgs)
+	}
+	submitted := da.submitted
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errGrp, ctx := errgroup.WithContext(ctx)
+	bs.archive.Start(types.WithErrGrp(ctx, errGrp))
+	require.Eventually(t, func() bool {
+		archived, err := bs.ArchivedBatches()
+		return err == nil && len(archived) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, errGrp.Wait())
+
+	archived, err := bs.ArchivedBatches()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), archived[0].Start)
+	require.Equal(t, uint64(5), archived[0].End)
+	require.FileExists(t, filepath.Join(bs.homePath, "archive", archived[0].fileName()))
+
+	// the submitted data is lost on the DA, and the batch is re-submitted from the archive to the new DA
+	// the msgs are saved before the broadcast
+	newDA := &recordingDA{db: bs.db}
+	bs.da = newDA
+	resubmitted, err := bs.ResubmitArchivedBatches(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, archived, resubmitted)
+	require.Equal(t, submitted, newDA.submitted)
+
+	// the batches out of the range are not re-submitted
+	resubmitted, err = bs.ResubmitArchivedBatches(2, 10)
+	require.NoError(t, err)
+	require.Empty(t, resubmitted)
+	require.Len(t, newDA.submitted, len(submitted))
+
+	// the corrupted archive is not re-submitted
+	filePath := filepath.Join(bs.homePath, "archive", archived[0].fileName())
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	content[len(content)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(filePath, content, 0640))
+	_, err = bs.ResubmitArchivedBatches(1, 10)
+	require.ErrorContains(t, err, "hash mismatch")
+
+	// disabled
+	bs.archive = nil
+	_, err = bs.ArchivedBatches()
+	require.ErrorIs(t, err, ErrBatchArchiveDisabled)
+}
+
+func Test_BatchArchiveRetention(t *testing.T) {
+	dir := t.TempDir()
+	archive := newBatchArchive(executortypes.BatchArchiveConfig{Dir: dir, MaxBytes: 250, MaxAge: 60}, "", zap.NewNop(), newBatchMetrics(""))
+
+	now := time.Unix(10000, 0)
+	batch := func(start uint64, archivedAt time.Time) {
+		_, err := archive.write(start, start+9, [][]byte{make([]byte, 92)}, archivedAt)
+		require.NoError(t, err)
+	}
+	starts := func() []uint64 {
+		batches, err := archive.list()
+		require.NoError(t, err)
+		starts := make([]uint64, 0, len(batches))
+		for _, archived := range batches {
+			starts = append(starts, archived.Start)
+		}
+		return starts
+	}
+
+	// the batch older than the max age is deleted
+	batch(1, now.Add(-2*time.Minute))
+	batch(11, now.Add(-30*time.Second))
+	require.NoError(t, archive.prune(now))
+	require.Equal(t, []uint64{11}, starts())
+
+	// the oldest batches are deleted above the max bytes
+	batch(21, now.Add(-20*time.Second))
+	batch(31, now.Add(-10*time.Second))
+	require.Equal(t, []uint64{11, 21, 31}, starts())
+	require.NoError(t, archive.prune(now))
+	require.Equal(t, []uint64{21, 31}, starts())
+
+	// the same batch is archived once
+	batch(31, now)
+	require.Equal(t, []uint64{21, 31}, starts())
+}
@@ -0,0 +1,75 @@
+package batch
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// pendingBatchBytes returns the compressed size of the finalized batches which are not confirmed on the DA yet,
+// excluding the chunks parked in the dead letters of the DA node, which don't drain until they are retried.
+func (bs *BatchSubmitter) pendingBatchBytes() (int64, error) {
+	parkedTraceIDs, err := bs.DA().DeadLetterTraceIDs()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to load dead letters of the DA node")
+	}
+
+	bs.chunkStatesMu.Lock()
+	defer bs.chunkStatesMu.Unlock()
+
+	size := int64(0)
+	for _, state := range bs.chunkStates {
+		size += state.PendingSize(parkedTraceIDs)
+	}
+	return size, nil
+}
+
+// waitForPendingBatches blocks the processing of the block while the pending batches exceed
+// the max pending batch bytes, so the batches don't pile up while the DA submission falls behind.
+func (bs *BatchSubmitter) waitForPendingBatches(ctx context.Context, blockHeight int64) error {
+	pending, err := bs.pendingBatchBytes()
+	if err != nil {
+		return err
+	}
+	bs.metrics.PendingBytes.Set(float64(pending))
+
+	maxPending := bs.batchCfg.MaxPendingBatchBytes
+	if maxPending == 0 || bs.batchCfg.DryRun || pending <= maxPending {
+		return nil
+	}
+
+	bs.logger.Warn("pause batch processing until the pending batches are submitted",
+		zap.Int64("height", blockHeight),
+		zap.Int64("pending_batch_bytes", pending),
+		zap.Int64("max_pending_batch_bytes", maxPending),
+	)
+	bs.metrics.Paused.Set(1)
+	defer bs.metrics.Paused.Set(0)
+
+	pausedAt := time.Now()
+	ticker := time.NewTicker(types.PollingInterval(ctx))
+	defer ticker.Stop()
+	for pending > maxPending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		pending, err = bs.pendingBatchBytes()
+		if err != nil {
+			return err
+		}
+		bs.metrics.PendingBytes.Set(float64(pending))
+	}
+
+	bs.logger.Info("resume batch processing",
+		zap.Int64("height", blockHeight),
+		zap.Int64("pending_batch_bytes", pending),
+		zap.Duration("paused", time.Since(pausedAt)),
+	)
+	return nil
+}
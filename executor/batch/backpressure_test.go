@@ -0,0 +1,196 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// stalledDA holds the broadcasted msgs until they are released, as the congested DA does.
+type stalledDA struct {
+	NoopDA
+
+	mu      sync.Mutex
+	handler nodetypes.TxConfirmedHandlerFn
+	held    []btypes.ProcessedMsgs
+}
+
+func (m *stalledDA) RegisterTxConfirmedHandler(fn nodetypes.TxConfirmedHandlerFn) {
+	m.handler = fn
+}
+
+func (m *stalledDA) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.held = append(m.held, msgs)
+	return nil
+}
+
+// release confirms all the held msgs.
+func (m *stalledDA) release() error {
+	m.mu.Lock()
+	held := m.held
+	m.held = nil
+	m.mu.Unlock()
+
+	for _, msgs := range held {
+		if err := m.handler(context.Background(), nodetypes.TxConfirmedArgs{TraceID: msgs.TraceID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Test_PendingBatchesBackPressure(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	bs := &BatchSubmitter{
+		db:             db,
+		logger:         zap.NewNop(),
+		batchCfg:       executortypes.BatchConfig{MaxPendingBatchBytes: 100},
+		daMu:           &sync.RWMutex{},
+		chunkStatesMu:  &sync.Mutex{},
+		history:        newBatchHistory(),
+		metrics:        newBatchMetrics(""),
+		localBatchInfo: &executortypes.LocalBatchInfo{},
+	}
+	da := &stalledDA{}
+	require.NoError(t, bs.SetDANode(da, nil))
+
+	// each block finalizes a batch of 60 bytes, so the processing pauses after 2 pending batches
+	var processedMu sync.Mutex
+	processed := make([]int64, 0)
+	lastProcessed := func() int64 {
+		processedMu.Lock()
+		defer processedMu.Unlock()
+		if len(processed) == 0 {
+			return 0
+		}
+		return processed[len(processed)-1]
+	}
+
+	ctx, cancel := context.WithCancel(types.WithPollingInterval(context.Background(), time.Millisecond))
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		for height := int64(1); height <= 6; height++ {
+			if err := bs.waitForPendingBatches(ctx, height); err != nil {
+				done <- err
+				return
+			}
+
+			state := executortypes.BatchChunkState{
+				Start:     uint64(height),
+				End:       uint64(height),
+				TraceIDs:  []string{fmt.Sprintf("batch-%d", height)},
+				Confirmed: make([]bool, 1),
+				Size:      60,
+			}
+			if err := bs.saveChunkState(state); err != nil {
+				done <- err
+				return
+			}
+			bs.trackChunkState(state)
+			da.BroadcastMsgs(btypes.ProcessedMsgs{TraceID: state.TraceIDs[0], Save: true})
+
+			processedMu.Lock()
+			processed = append(processed, height)
+			processedMu.Unlock()
+		}
+		done <- nil
+	}()
+
+	for _, pausedHeight := range []int64{2, 4} {
+		// the height doesn't advance while the DA is stalled
+		require.Eventually(t, func() bool { return lastProcessed() == pausedHeight }, 5*time.Second, time.Millisecond)
+		require.Never(t, func() bool { return lastProcessed() != pausedHeight }, 50*time.Millisecond, time.Millisecond)
+		require.Equal(t, int64(120), pendingBatchBytes(t, bs))
+
+		// the processing resumes after the backlog drains
+		require.NoError(t, da.release())
+	}
+
+	require.NoError(t, <-done)
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6}, processed)
+	require.Equal(t, uint64(4), bs.LastSubmittedBatch().End)
+	require.Equal(t, int64(120), pendingBatchBytes(t, bs))
+}
+
+func pendingBatchBytes(t *testing.T, bs *BatchSubmitter) int64 {
+	pending, err := bs.pendingBatchBytes()
+	require.NoError(t, err)
+	return pending
+}
+
+func Test_PendingBatchesBackPressureDisabled(t *testing.T) {
+	bs := &BatchSubmitter{
+		logger:        zap.NewNop(),
+		da:            NewNoopDA(),
+		daMu:          &sync.RWMutex{},
+		chunkStatesMu: &sync.Mutex{},
+		chunkStates:   []*executortypes.BatchChunkState{{Size: 1000}},
+		metrics:       newBatchMetrics(""),
+	}
+
+	// the processing is not paused without the limit
+	require.NoError(t, bs.waitForPendingBatches(context.Background(), 1))
+
+	// the pause is stopped by the context
+	bs.batchCfg.MaxPendingBatchBytes = 100
+	ctx, cancel := context.WithTimeout(types.WithPollingInterval(context.Background(), time.Millisecond), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, bs.waitForPendingBatches(ctx, 1), context.DeadlineExceeded)
+
+	// the batches recorded in the dry-run mode are never confirmed, so the processing is not paused
+	bs.batchCfg.DryRun = true
+	require.NoError(t, bs.waitForPendingBatches(ctx, 1))
+}
+
+// parkedDA parks the msgs of the trace ids in the dead letters.
+type parkedDA struct {
+	NoopDA
+
+	parked map[string]struct{}
+}
+
+func (m parkedDA) DeadLetterTraceIDs() (map[string]struct{}, error) {
+	return m.parked, nil
+}
+
+func Test_PendingBatchBytesParkedChunks(t *testing.T) {
+	bs := &BatchSubmitter{
+		da:            parkedDA{parked: map[string]struct{}{"chunk-1": {}, "chunk-3": {}}},
+		daMu:          &sync.RWMutex{},
+		chunkStatesMu: &sync.Mutex{},
+		chunkStates: []*executortypes.BatchChunkState{
+			{
+				TraceIDs:   []string{"header", "chunk-1", "chunk-2"},
+				Confirmed:  []bool{true, false, false},
+				Size:       100,
+				ChunkSizes: []int64{10, 50, 40},
+			},
+			// the chunk sizes are not recorded in the legacy states
+			{
+				TraceIDs:  []string{"header-2", "chunk-3"},
+				Confirmed: []bool{false, false},
+				Size:      30,
+			},
+		},
+	}
+
+	// the parked chunk doesn't drain until it is retried
+	require.Equal(t, int64(80), pendingBatchBytes(t, bs))
+}
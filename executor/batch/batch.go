@@ -1,9 +1,10 @@
 package batch
 
 import (
-	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"errors"
+	"hash"
 	"os"
 	"sync"
 
@@ -21,7 +22,7 @@ import (
 )
 
 type hostNode interface {
-	QueryBatchInfos(context.Context, uint64) (*ophosttypes.QueryBatchInfosResponse, error)
+	QueryBatchInfos(context.Context, uint64, int64) (*ophosttypes.QueryBatchInfosResponse, error)
 }
 
 type BatchSubmitter struct {
@@ -29,9 +30,22 @@ type BatchSubmitter struct {
 
 	node *node.Node
 	host hostNode
-	da   executortypes.DANode
 
-	bridgeInfo ophosttypes.QueryBridgeResponse
+	// the DA node is switched by the batch info rotation while the status is queried
+	daMu      *sync.RWMutex
+	da        executortypes.DANode
+	daFactory DANodeFactoryFn
+
+	// secondary is the DA node which also receives the batches while the DA is migrated,
+	// whose confirmations are tracked apart from the primary DA node.
+	secondary       executortypes.DANode
+	secondaryMsgs   []btypes.ProcessedMsgs
+	secondaryMu     *sync.Mutex
+	secondaryStates []*executortypes.BatchChunkState
+
+	// bridge info can be updated by the host events while the batch submitter reads it
+	bridgeInfoMu *sync.RWMutex
+	bridgeInfo   *ophosttypes.QueryBridgeResponse
 
 	cfg      nodetypes.NodeConfig
 	batchCfg executortypes.BatchConfig
@@ -40,13 +54,41 @@ type BatchSubmitter struct {
 
 	opchildQueryClient opchildtypes.QueryClient
 
-	batchInfoMu    *sync.Mutex
-	batchInfos     []ophosttypes.BatchInfoWithOutput
-	batchWriter    *gzip.Writer
-	batchFile      *os.File
+	batchInfoMu *sync.Mutex
+	batchInfos  []ophosttypes.BatchInfoWithOutput
+	batchWriter batchWriter
+	batchFile   *os.File
+	// batchHash is the hash of the compressed bytes written to the batch file
+	batchHash hash.Hash
+	// rawBatchSize is the size of the batch in progress before the compression
+	rawBatchSize   int64
 	localBatchInfo *executortypes.LocalBatchInfo
 
+	// compression is the configured algorithm, which is applied from the next batch,
+	// while writerCompression is the algorithm of the batch in progress.
+	compression       executortypes.BatchCompression
+	writerCompression executortypes.BatchCompression
+	// batchVersion is the configured payload format, which is applied from the next batch like the compression,
+	// while writerBatchVersion is the format of the batch in progress.
+	batchVersion       executortypes.BatchVersion
+	writerBatchVersion executortypes.BatchVersion
+	// blockEvents is the allowlist of the event types written after each block in the BatchVersionBlockEvents format.
+	blockEvents map[string]struct{}
+
 	processedMsgs []btypes.ProcessedMsgs
+	// finalizedChunkState is the chunk state of the batch finalized in the current block,
+	// which is saved with the processed msgs.
+	finalizedChunkState *executortypes.BatchChunkState
+
+	// chunk states are updated by the DA tx confirmations while the batch submitter finalizes batches
+	chunkStatesMu      *sync.Mutex
+	chunkStates        []*executortypes.BatchChunkState
+	lastSubmittedBatch *executortypes.BatchChunkState
+
+	metrics *batchMetrics
+	history *batchHistory
+	// archive copies the finalized batches to the archive directory, or nil if it is disabled
+	archive *batchArchive
 
 	chainID  string
 	homePath string
@@ -60,23 +102,39 @@ func NewBatchSubmitterV1(
 	batchCfg executortypes.BatchConfig,
 	db types.DB, logger *zap.Logger,
 	chainID, homePath string,
-) *BatchSubmitter {
-	appCodec, txConfig, err := childprovider.GetCodec(cfg.Bech32Prefix)
+) (*BatchSubmitter, error) {
+	appCodec, txConfig, err := childprovider.GetCodec(cfg.Bech32Prefix, cfg.CodecModules...)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	compression, err := executortypes.BatchCompressionFromString(batchCfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	batchVersion := executortypes.BatchVersionBlocks
+	blockEvents := make(map[string]struct{}, len(batchCfg.BlockEvents))
+	for _, eventType := range batchCfg.BlockEvents {
+		batchVersion = executortypes.BatchVersionBlockEvents
+		blockEvents[eventType] = struct{}{}
 	}
 
 	cfg.BroadcasterConfig = nil
 	cfg.ProcessType = nodetypes.PROCESS_TYPE_RAW
 	node, err := node.NewNode(cfg, db, logger, appCodec, txConfig)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	ch := &BatchSubmitter{
 		version: 1,
 
 		node: node,
+		daMu: &sync.RWMutex{},
+
+		bridgeInfoMu: &sync.RWMutex{},
+		bridgeInfo:   &ophosttypes.QueryBridgeResponse{},
 
 		cfg:      cfg,
 		batchCfg: batchCfg,
@@ -84,16 +142,28 @@ func NewBatchSubmitterV1(
 		db:     db,
 		logger: logger,
 
-		opchildQueryClient: opchildtypes.NewQueryClient(node.GetRPCClient()),
+		opchildQueryClient: opchildtypes.NewQueryClient(node.QueryConn()),
 
 		batchInfoMu:    &sync.Mutex{},
 		localBatchInfo: &executortypes.LocalBatchInfo{},
+		batchHash:      sha256.New(),
+		compression:    compression,
+		batchVersion:   batchVersion,
+		blockEvents:    blockEvents,
 
 		processedMsgs: make([]btypes.ProcessedMsgs, 0),
+		chunkStatesMu: &sync.Mutex{},
+		secondaryMsgs: make([]btypes.ProcessedMsgs, 0),
+		secondaryMu:   &sync.Mutex{},
+		metrics:       newBatchMetrics(cfg.Bridge),
+		history:       newBatchHistory(),
 		homePath:      homePath,
 		chainID:       chainID,
 	}
-	return ch
+	if batchCfg.Archive.Dir != "" {
+		ch.archive = newBatchArchive(batchCfg.Archive, homePath, logger, ch.metrics)
+	}
+	return ch, nil
 }
 
 func (bs *BatchSubmitter) Initialize(ctx context.Context, processedHeight int64, host hostNode, bridgeInfo ophosttypes.QueryBridgeResponse) error {
@@ -102,9 +172,9 @@ func (bs *BatchSubmitter) Initialize(ctx context.Context, processedHeight int64,
 		return err
 	}
 	bs.host = host
-	bs.bridgeInfo = bridgeInfo
+	bs.SetBridgeInfo(bridgeInfo)
 
-	res, err := bs.host.QueryBatchInfos(ctx, bridgeInfo.BridgeId)
+	res, err := bs.host.QueryBatchInfos(ctx, bridgeInfo.BridgeId, 0)
 	if err != nil {
 		return err
 	}
@@ -119,8 +189,7 @@ func (bs *BatchSubmitter) Initialize(ctx context.Context, processedHeight int64,
 		bs.DequeueBatchInfo()
 	}
 
-	fileFlag := os.O_CREATE | os.O_RDWR | os.O_APPEND
-	bs.batchFile, err = os.OpenFile(bs.homePath+"/batch", fileFlag, 0640)
+	err = bs.openBatchTempFile()
 	if err != nil {
 		return err
 	}
@@ -129,6 +198,9 @@ func (bs *BatchSubmitter) Initialize(ctx context.Context, processedHeight int64,
 		bs.localBatchInfo.Start = bs.node.GetHeight()
 		bs.localBatchInfo.End = 0
 		bs.localBatchInfo.BatchFileSize = 0
+		bs.localBatchInfo.BlockOffsets = nil
+		bs.localBatchInfo.Compression = bs.compression.String()
+		bs.localBatchInfo.Version = bs.batchVersion
 
 		err = bs.saveLocalBatchInfo()
 		if err != nil {
@@ -143,27 +215,74 @@ func (bs *BatchSubmitter) Initialize(ctx context.Context, processedHeight int64,
 		if err != nil {
 			return err
 		}
+	} else {
+		err = bs.loadLocalBatchInfo()
+		if err != nil {
+			return err
+		}
+	}
+
+	// the batch in progress keeps its compression and format, the configured ones are applied from the next batch
+	bs.writerCompression, err = executortypes.BatchCompressionFromString(bs.localBatchInfo.Compression)
+	if err != nil {
+		return err
 	}
-	// linux command gzip use level 6 as default
-	bs.batchWriter, err = gzip.NewWriterLevel(bs.batchFile, 6)
+	bs.writerBatchVersion = bs.localBatchInfo.Version
+	bs.batchWriter, err = newBatchWriter(bs.writerCompression, bs.batchWriterTarget(), bs.batchCfg.CompressionLevel)
 	if err != nil {
 		return err
 	}
 
-	bs.node.RegisterRawBlockHandler(bs.rawBlockHandler)
+	if !bs.node.HeightInitialized() {
+		err = bs.recoverBatchFile(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = bs.node.RegisterRawBlockHandler(bs.rawBlockHandler)
+	if err != nil {
+		return err
+	}
+	bs.updateProgress()
 	return nil
 }
 
-func (bs *BatchSubmitter) SetDANode(da executortypes.DANode) {
+// SetDANode sets the DA node, and resumes tracking the chunks of the batches which are not submitted yet.
+// If the dual submission is enabled, the batches are also submitted to the secondary DA node, and only
+// the confirmations of the primary DA node advance the submission.
+func (bs *BatchSubmitter) SetDANode(da executortypes.DANode, secondary executortypes.DANode) error {
+	if bs.batchCfg.DualSubmit {
+		if secondary == nil {
+			return errors.New("secondary DA node is required for the dual submission")
+		}
+		bs.secondary = secondary
+		bs.secondary.RegisterTxConfirmedHandler(bs.secondaryTxConfirmedHandler)
+	}
+
 	bs.da = da
+	bs.da.RegisterTxConfirmedHandler(bs.txConfirmedHandler)
+	return bs.resumeChunkStates()
 }
 
 func (bs *BatchSubmitter) Start(ctx context.Context) {
 	bs.logger.Info("batch start", zap.Int64("height", bs.node.GetHeight()))
+	if bs.archive != nil {
+		bs.archive.Start(ctx)
+	}
 	bs.node.Start(ctx)
 }
 
 func (bs *BatchSubmitter) Close() {
+	// the DA node may be the host node, whose connection is closed again without an effect
+	for _, da := range []executortypes.DANode{bs.da, bs.secondary} {
+		if daNode, ok := da.(interface{ Node() *node.Node }); ok {
+			_ = daNode.Node().Close()
+		}
+	}
+	if bs.node != nil {
+		_ = bs.node.Close()
+	}
 	if bs.batchWriter != nil {
 		bs.batchWriter.Close()
 	}
@@ -173,7 +292,15 @@ func (bs *BatchSubmitter) Close() {
 }
 
 func (bs *BatchSubmitter) SetBridgeInfo(bridgeInfo ophosttypes.QueryBridgeResponse) {
-	bs.bridgeInfo = bridgeInfo
+	bs.bridgeInfoMu.Lock()
+	defer bs.bridgeInfoMu.Unlock()
+	*bs.bridgeInfo = bridgeInfo
+}
+
+func (bs BatchSubmitter) BridgeInfo() ophosttypes.QueryBridgeResponse {
+	bs.bridgeInfoMu.RLock()
+	defer bs.bridgeInfoMu.RUnlock()
+	return *bs.bridgeInfo
 }
 
 func (bs *BatchSubmitter) ChainID() string {
@@ -181,6 +308,8 @@ func (bs *BatchSubmitter) ChainID() string {
 }
 
 func (bs *BatchSubmitter) DA() executortypes.DANode {
+	bs.daMu.RLock()
+	defer bs.daMu.RUnlock()
 	return bs.da
 }
 
@@ -0,0 +1,97 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// DANodeFactoryFn creates the DA node which submits the batches of the batch info.
+type DANodeFactoryFn func(context.Context, ophosttypes.BatchInfoWithOutput) (executortypes.DANode, error)
+
+// SetDANodeFactory sets the factory of the DA node, which is used to switch the DA node
+// when the batch info is rotated.
+func (bs *BatchSubmitter) SetDANodeFactory(fn DANodeFactoryFn) {
+	bs.daFactory = fn
+}
+
+// isBatchInfoBoundary returns true if the block is the last block of the current batch info.
+func (bs *BatchSubmitter) isBatchInfoBoundary(blockHeight int64) bool {
+	nextBatchInfo := bs.NextBatchInfo()
+	return nextBatchInfo != nil && types.MustUint64ToInt64(nextBatchInfo.Output.L2BlockNumber) == blockHeight
+}
+
+// rotateBatchInfo switches to the next batch info, which applies to the blocks after its l2 block number.
+// The batch finalized at the l2 block number is the last batch of the current batch info, so the DA node
+// is switched before the next block. If the batch info is updated after the blocks of the next batch info
+// are batched, the batch is rebuilt from the block where the batch info is changed.
+func (bs *BatchSubmitter) rotateBatchInfo(ctx context.Context, blockHeight int64, nextBatchInfo ophosttypes.BatchInfoWithOutput) error {
+	boundary := types.MustUint64ToInt64(nextBatchInfo.Output.L2BlockNumber)
+	switch {
+	case bs.localBatchInfo.End == boundary,
+		bs.localBatchInfo.End == 0 && bs.localBatchInfo.Start == blockHeight && blockHeight == boundary+1:
+		// the batches of the current batch info are finalized
+		return bs.switchDANode(ctx, nextBatchInfo)
+	case bs.localBatchInfo.End == 0 && bs.localBatchInfo.Start <= boundary:
+		// the batch in progress has the blocks of both batch infos,
+		// so rebuild it to be finalized at the boundary with the current batch info
+		err := bs.rewindBatch()
+		if err != nil {
+			return errors.Wrap(err, "failed to rewind batch")
+		}
+		return fmt.Errorf("batch info updated at %d: rebuild the batch from %d", boundary, bs.localBatchInfo.Start)
+	default:
+		// the blocks of the next batch info are batched with the current batch info,
+		// so rebuild them with the next batch info
+		bs.localBatchInfo.Start = boundary + 1
+		bs.localBatchInfo.BlockOffsets = nil
+		err := bs.rewindBatch()
+		if err != nil {
+			return errors.Wrap(err, "failed to rewind batch")
+		}
+		err = bs.switchDANode(ctx, nextBatchInfo)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("batch info updated at %d: rebuild the batch from %d", boundary, bs.localBatchInfo.Start)
+	}
+}
+
+// switchDANode creates and starts the DA node of the next batch info if the DA or the submitter is changed,
+// and dequeues the current batch info.
+// The previous DA node keeps running to confirm the submitted batches.
+func (bs *BatchSubmitter) switchDANode(ctx context.Context, nextBatchInfo ophosttypes.BatchInfoWithOutput) error {
+	if bs.BatchInfo().BatchInfo == nextBatchInfo.BatchInfo {
+		// the DA and the submitter are not changed, so keep the DA node
+		bs.DequeueBatchInfo()
+		return nil
+	} else if bs.daFactory == nil {
+		return errors.New("DA node factory is not set")
+	}
+
+	da, err := bs.daFactory(ctx, nextBatchInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to create DA node of the next batch info")
+	}
+	da.RegisterTxConfirmedHandler(bs.txConfirmedHandler)
+	da.Start(ctx)
+
+	bs.daMu.Lock()
+	bs.da = da
+	bs.daMu.Unlock()
+	bs.DequeueBatchInfo()
+
+	bs.logger.Info("batch info rotated",
+		zap.String("chain_type", nextBatchInfo.BatchInfo.ChainType.String()),
+		zap.String("submitter", nextBatchInfo.BatchInfo.Submitter),
+		zap.Uint64("l2_block_number", nextBatchInfo.Output.L2BlockNumber),
+	)
+	return nil
+}
@@ -0,0 +1,164 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+func newTestRotationBatchSubmitter(t *testing.T) (*BatchSubmitter, *[]ophosttypes.BatchInfoWithOutput) {
+	bs := newTestFileBatchSubmitter(t, 1)
+	bs.batchCfg.MaxChunks = 100
+	bs.bridgeInfoMu = &sync.RWMutex{}
+	bs.bridgeInfo = &ophosttypes.QueryBridgeResponse{}
+	bs.daMu = &sync.RWMutex{}
+	bs.da = NewNoopDA()
+	bs.batchInfoMu = &sync.Mutex{}
+	bs.batchInfos = []ophosttypes.BatchInfoWithOutput{{
+		BatchInfo: ophosttypes.BatchInfo{ChainType: ophosttypes.BatchInfo_CHAIN_TYPE_INITIA, Submitter: "init1old"},
+	}}
+
+	created := make([]ophosttypes.BatchInfoWithOutput, 0)
+	bs.SetDANodeFactory(func(_ context.Context, batchInfo ophosttypes.BatchInfoWithOutput) (executortypes.DANode, error) {
+		created = append(created, batchInfo)
+		return &mockDA{}, nil
+	})
+	return bs, &created
+}
+
+// processBlock writes the block to the batch as the raw block handler does, and finalizes the batch
+// if the submission is triggered. It returns the trigger.
+func processBlock(t *testing.T, bs *BatchSubmitter, height int64, block []byte) (string, error) {
+	err := bs.prepareBatch(context.Background(), height)
+	if err != nil {
+		return "", err
+	}
+	_, err = bs.handleBatch(block)
+	require.NoError(t, err)
+
+	trigger := bs.submissionTrigger(height, height+100, bs.localBatchInfo.LastSubmissionTime, 0)
+	if trigger != "" {
+		bs.localBatchInfo.End = height
+	}
+	require.NoError(t, bs.saveLocalBatchInfo())
+	bs.node.SetSyncInfo(height)
+	return trigger, nil
+}
+
+func Test_BatchInfoRotation(t *testing.T) {
+	blocks := testBlocks(t, 10)
+
+	bs, created := newTestRotationBatchSubmitter(t)
+	bs.localBatchInfo.Start = 1
+	require.NoError(t, bs.saveLocalBatchInfo())
+
+	for height := int64(1); height <= 3; height++ {
+		trigger, err := processBlock(t, bs, height, blocks[height-1])
+		require.NoError(t, err)
+		require.Empty(t, trigger)
+	}
+
+	// the governance changes the DA to celestia after the block 5
+	bs.UpdateBatchInfo("CELESTIA", "celestia1new", 2, 5)
+	require.NotNil(t, bs.NextBatchInfo())
+
+	for height := int64(4); height <= 5; height++ {
+		trigger, err := processBlock(t, bs, height, blocks[height-1])
+		require.NoError(t, err)
+		if height == 5 {
+			// the last batch of the old batch info is finalized at the boundary
+			require.Equal(t, "batch_info_update", trigger)
+		} else {
+			require.Empty(t, trigger)
+		}
+	}
+	require.Empty(t, *created)
+	require.IsType(t, &NoopDA{}, bs.DA())
+
+	// the next block starts a fresh batch with the new DA
+	trigger, err := processBlock(t, bs, 6, blocks[5])
+	require.NoError(t, err)
+	require.Empty(t, trigger)
+	require.Len(t, *created, 1)
+	require.Equal(t, "celestia1new", (*created)[0].BatchInfo.Submitter)
+	require.IsType(t, &mockDA{}, bs.DA())
+	require.Nil(t, bs.NextBatchInfo())
+	require.Equal(t, ophosttypes.BatchInfo_CHAIN_TYPE_CELESTIA, bs.BatchInfo().BatchInfo.ChainType)
+	require.Equal(t, int64(6), bs.localBatchInfo.Start)
+}
+
+func Test_BatchInfoRotationAfterBoundary(t *testing.T) {
+	blocks := testBlocks(t, 10)
+
+	// the batch in progress has the blocks of both batch infos
+	bs, created := newTestRotationBatchSubmitter(t)
+	bs.localBatchInfo.Start = 3
+	require.NoError(t, bs.saveLocalBatchInfo())
+	for height := int64(3); height <= 7; height++ {
+		_, err := processBlock(t, bs, height, blocks[height-1])
+		require.NoError(t, err)
+	}
+
+	bs.UpdateBatchInfo("CELESTIA", "celestia1new", 2, 5)
+	_, err := processBlock(t, bs, 8, blocks[7])
+	require.ErrorContains(t, err, "rebuild the batch from 3")
+
+	// the batch is rebuilt from its start to be finalized at the boundary with the old DA
+	require.Equal(t, int64(3), bs.node.GetHeight())
+	require.Empty(t, *created)
+	require.NotNil(t, bs.NextBatchInfo())
+	for height := int64(3); height <= 5; height++ {
+		trigger, err := processBlock(t, bs, height, blocks[height-1])
+		require.NoError(t, err)
+		if height == 5 {
+			require.Equal(t, "batch_info_update", trigger)
+		}
+	}
+	_, err = processBlock(t, bs, 6, blocks[5])
+	require.NoError(t, err)
+	require.Len(t, *created, 1)
+	require.Equal(t, int64(6), bs.localBatchInfo.Start)
+
+	// the blocks of the new batch info are already finalized with the old DA
+	bs, created = newTestRotationBatchSubmitter(t)
+	bs.localBatchInfo.Start = 7
+	require.NoError(t, bs.saveLocalBatchInfo())
+	for height := int64(7); height <= 8; height++ {
+		_, err := processBlock(t, bs, height, blocks[height-1])
+		require.NoError(t, err)
+	}
+
+	bs.UpdateBatchInfo("CELESTIA", "celestia1new", 2, 5)
+	_, err = processBlock(t, bs, 9, blocks[8])
+	require.ErrorContains(t, err, "rebuild the batch from 6")
+
+	// the DA is switched and the blocks after the boundary are rebuilt with the new DA
+	require.Equal(t, int64(6), bs.node.GetHeight())
+	require.Len(t, *created, 1)
+	require.Nil(t, bs.NextBatchInfo())
+	for height := int64(6); height <= 9; height++ {
+		trigger, err := processBlock(t, bs, height, blocks[height-1])
+		require.NoError(t, err)
+		require.Empty(t, trigger)
+	}
+	require.Equal(t, int64(6), bs.localBatchInfo.Start)
+}
+
+func Test_BatchInfoRotationSameDA(t *testing.T) {
+	bs, created := newTestRotationBatchSubmitter(t)
+	bs.localBatchInfo.End = 5
+	require.NoError(t, bs.saveLocalBatchInfo())
+
+	// the batch info is re-registered without changes
+	bs.UpdateBatchInfo("INITIA", "init1old", 2, 5)
+	require.NoError(t, bs.prepareBatch(context.Background(), 6))
+	require.Empty(t, *created)
+	require.Nil(t, bs.NextBatchInfo())
+	require.IsType(t, &NoopDA{}, bs.DA())
+}
@@ -0,0 +1,201 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// splitBatchFile splits the compressed batch file into the chunks of at most maxChunkSize bytes,
+// which fit the max tx or blob size of the DA, and returns them with their checksums.
+func splitBatchFile(file io.ReaderAt, maxChunkSize int64) (chunks [][]byte, checksums [][]byte, err error) {
+	batchBuffer := make([]byte, maxChunkSize)
+
+	// TODO: improve this logic to avoid hold all the batch data in memory
+	for offset := int64(0); ; {
+		readLength, err := file.ReadAt(batchBuffer, offset)
+		if err != nil && err != io.EOF {
+			return nil, nil, err
+		} else if readLength == 0 {
+			break
+		}
+
+		// trim the buffer to the actual read length
+		chunk := bytes.Clone(batchBuffer[:readLength])
+		chunks = append(chunks, chunk)
+
+		checksum := executortypes.GetChecksumFromChunk(chunk)
+		checksums = append(checksums, checksum[:])
+		if int64(readLength) < maxChunkSize {
+			break
+		}
+		offset += int64(readLength)
+	}
+	return chunks, checksums, nil
+}
+
+// txConfirmedHandler marks the header or the chunk included in the confirmed DA tx.
+func (bs *BatchSubmitter) txConfirmedHandler(_ context.Context, args nodetypes.TxConfirmedArgs) error {
+	return bs.confirmChunk(args.TraceID, args.TxHash, types.MustInt64ToUint64(args.GasUsed), args.BlockTime)
+}
+
+// confirmChunk marks the header or the chunk of the trace id as confirmed. The batch is marked
+// as submitted and its chunk state is removed when the header and all the chunks are confirmed.
+func (bs *BatchSubmitter) confirmChunk(traceID string, txHash string, gasUsed uint64, blockTime time.Time) error {
+	bs.chunkStatesMu.Lock()
+	defer bs.chunkStatesMu.Unlock()
+
+	for i, state := range bs.chunkStates {
+		index := slices.Index(state.TraceIDs, traceID)
+		if index == -1 {
+			continue
+		} else if state.Confirmed[index] {
+			return nil
+		}
+
+		state.Confirmed[index] = true
+		if len(state.TxHashes) != len(state.TraceIDs) {
+			// the states saved before the tx hashes are recorded
+			state.TxHashes = make([]string, len(state.TraceIDs))
+		}
+		state.TxHashes[index] = txHash
+		state.GasUsed += gasUsed
+		if !state.IsSubmitted() {
+			bs.logger.Debug("batch chunk confirmed",
+				zap.Uint64("batch_start", state.Start),
+				zap.Uint64("batch_end", state.End),
+				zap.Int("confirmed", state.ConfirmedChunks()),
+				zap.Int("total", len(state.TraceIDs)),
+			)
+			return bs.saveChunkState(*state)
+		}
+
+		err := bs.markBatchSubmitted(*state)
+		if err != nil {
+			return err
+		}
+		bs.history.add(BatchSubmission{
+			Start:       state.Start,
+			End:         state.End,
+			TxHashes:    state.TxHashes,
+			GasUsed:     state.GasUsed,
+			SubmittedAt: blockTime,
+		})
+		bs.chunkStates = slices.Delete(bs.chunkStates, i, i+1)
+		return nil
+	}
+	return nil
+}
+
+// markBatchSubmitted replaces the chunk state of the batch with the last submitted batch.
+func (bs *BatchSubmitter) markBatchSubmitted(state executortypes.BatchChunkState) error {
+	err := bs.deleteChunkState(state.Start)
+	if err != nil {
+		return err
+	}
+	err = bs.saveLastSubmittedBatch(state)
+	if err != nil {
+		return err
+	}
+	bs.lastSubmittedBatch = &state
+
+	bs.logger.Info("batch submitted",
+		zap.Uint64("batch_start", state.Start),
+		zap.Uint64("batch_end", state.End),
+		zap.Int("chunks", len(state.TraceIDs)-1),
+	)
+	return nil
+}
+
+// resumeChunkStates loads the chunk states of the batches which are not submitted yet.
+// The chunks which landed while the bot was down are not held by the broadcaster of the DA anymore,
+// so they are marked as confirmed, and the rest are re-broadcasted by the broadcaster from its saved msgs.
+func (bs *BatchSubmitter) resumeChunkStates() error {
+	states, err := bs.loadChunkStates()
+	if err != nil {
+		return err
+	}
+
+	bs.lastSubmittedBatch, err = bs.loadLastSubmittedBatch()
+	if err != nil {
+		return err
+	}
+
+	unconfirmed, err := bs.da.UnconfirmedTraceIDs()
+	if err != nil {
+		return errors.Wrap(err, "failed to load unconfirmed trace ids")
+	}
+
+	bs.chunkStatesMu.Lock()
+	defer bs.chunkStatesMu.Unlock()
+
+	bs.chunkStates = bs.chunkStates[:0]
+	for _, state := range states {
+		for i, traceID := range state.TraceIDs {
+			if _, ok := unconfirmed[traceID]; !ok {
+				state.Confirmed[i] = true
+			}
+		}
+
+		if state.IsSubmitted() {
+			err := bs.markBatchSubmitted(state)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := bs.saveChunkState(state)
+		if err != nil {
+			return err
+		}
+		bs.logger.Info("resume batch submission",
+			zap.Uint64("batch_start", state.Start),
+			zap.Uint64("batch_end", state.End),
+			zap.Int("confirmed", state.ConfirmedChunks()),
+			zap.Int("total", len(state.TraceIDs)),
+		)
+		bs.chunkStates = append(bs.chunkStates, &state)
+	}
+	return nil
+}
+
+// trackChunkState starts tracking the chunk state of the finalized batch after it is saved.
+func (bs *BatchSubmitter) trackChunkState(state executortypes.BatchChunkState) {
+	bs.chunkStatesMu.Lock()
+	defer bs.chunkStatesMu.Unlock()
+
+	bs.chunkStates = append(bs.chunkStates, &state)
+}
+
+// PendingChunkStates returns the chunk states of the batches which are not submitted yet.
+func (bs *BatchSubmitter) PendingChunkStates() []executortypes.BatchChunkState {
+	bs.chunkStatesMu.Lock()
+	defer bs.chunkStatesMu.Unlock()
+
+	states := make([]executortypes.BatchChunkState, 0, len(bs.chunkStates))
+	for _, state := range bs.chunkStates {
+		copied := *state
+		copied.Confirmed = slices.Clone(state.Confirmed)
+		copied.TxHashes = slices.Clone(state.TxHashes)
+		states = append(states, copied)
+	}
+	return states
+}
+
+// LastSubmittedBatch returns the last batch whose header and chunks are all confirmed.
+func (bs *BatchSubmitter) LastSubmittedBatch() *executortypes.BatchChunkState {
+	bs.chunkStatesMu.Lock()
+	defer bs.chunkStatesMu.Unlock()
+
+	return bs.lastSubmittedBatch
+}
@@ -0,0 +1,205 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+type mockDA struct {
+	NoopDA
+
+	unconfirmed map[string]struct{}
+}
+
+func (m mockDA) UnconfirmedTraceIDs() (map[string]struct{}, error) {
+	return m.unconfirmed, nil
+}
+
+// dropFirstDA drops the first submission of every trace id and confirms the resubmissions.
+type dropFirstDA struct {
+	NoopDA
+
+	handler   nodetypes.TxConfirmedHandlerFn
+	submitted map[string]bool
+	dropped   []btypes.ProcessedMsgs
+}
+
+func (m *dropFirstDA) RegisterTxConfirmedHandler(fn nodetypes.TxConfirmedHandlerFn) {
+	m.handler = fn
+}
+
+func (m *dropFirstDA) UnconfirmedTraceIDs() (map[string]struct{}, error) {
+	return map[string]struct{}{}, nil
+}
+
+func (m *dropFirstDA) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	if !m.submitted[msgs.TraceID] {
+		m.submitted[msgs.TraceID] = true
+		m.dropped = append(m.dropped, msgs)
+		return nil
+	}
+	_ = m.handler(context.Background(), nodetypes.TxConfirmedArgs{TraceID: msgs.TraceID})
+	return nil
+}
+
+func Test_ChunkReassembly(t *testing.T) {
+	blocks := testBlocks(t, 20)
+	compressed := compress(t, executortypes.BatchCompressionZstd, 0, blocks)
+
+	// force chunking with a tiny limit
+	chunks, checksums, err := splitBatchFile(bytes.NewReader(compressed), 64)
+	require.NoError(t, err)
+	require.Equal(t, (len(compressed)+63)/64, len(chunks))
+	require.Len(t, checksums, len(chunks))
+
+	headerData := executortypes.MarshalBatchDataHeader(1, 20, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlocks, checksums)
+	chunkData := make([][]byte, 0, len(chunks))
+	for i, chunk := range chunks {
+		require.LessOrEqual(t, len(chunk), 64)
+		chunkData = append(chunkData, executortypes.MarshalBatchDataChunk(1, 20, uint64(i), uint64(len(chunks)), chunk))
+	}
+
+	// the reader receives the chunks in any order
+	rand.New(rand.NewSource(1)).Shuffle(len(chunkData), func(i, j int) {
+		chunkData[i], chunkData[j] = chunkData[j], chunkData[i]
+	})
+
+	header, err := executortypes.UnmarshalBatchDataHeader(headerData)
+	require.NoError(t, err)
+	batchChunks := make([]executortypes.BatchDataChunk, 0, len(chunkData))
+	for _, data := range chunkData {
+		chunk, err := executortypes.UnmarshalBatchDataChunk(data)
+		require.NoError(t, err)
+		batchChunks = append(batchChunks, chunk)
+	}
+
+	reassembled, err := executortypes.ReassembleBatchChunks(header, batchChunks)
+	require.NoError(t, err)
+	require.Equal(t, compressed, reassembled)
+	require.Equal(t, batchData(blocks), decompress(t, header.Compression, reassembled))
+
+	// missing chunk
+	_, err = executortypes.ReassembleBatchChunks(header, batchChunks[1:])
+	require.Error(t, err)
+
+	// corrupted chunk
+	batchChunks[0].ChunkData = append(bytes.Clone(batchChunks[0].ChunkData), 0)
+	_, err = executortypes.ReassembleBatchChunks(header, batchChunks)
+	require.Error(t, err)
+}
+
+func Test_ChunkState(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+
+	bs := &BatchSubmitter{
+		db:            db,
+		logger:        zap.NewNop(),
+		chunkStatesMu: &sync.Mutex{},
+		history:       newBatchHistory(),
+	}
+
+	state := executortypes.BatchChunkState{
+		Start:     1,
+		End:       10,
+		TraceIDs:  []string{"header", "chunk0", "chunk1"},
+		Confirmed: make([]bool, 3),
+	}
+	kv, err := bs.chunkStateToRawKV(state)
+	require.NoError(t, err)
+	require.NoError(t, db.RawBatchSet(kv))
+	bs.trackChunkState(state)
+
+	// unknown trace id is ignored
+	require.NoError(t, bs.confirmChunk("unknown", "", 0, time.Time{}))
+
+	require.NoError(t, bs.confirmChunk("chunk1", "", 0, time.Time{}))
+	require.NoError(t, bs.confirmChunk("header", "", 0, time.Time{}))
+	states := bs.PendingChunkStates()
+	require.Len(t, states, 1)
+	require.Equal(t, []bool{true, false, true}, states[0].Confirmed)
+	require.Nil(t, bs.LastSubmittedBatch())
+
+	// restart with the chunk still held by the broadcaster
+	bs.da = mockDA{unconfirmed: map[string]struct{}{"chunk0": {}}}
+	require.NoError(t, bs.resumeChunkStates())
+	states = bs.PendingChunkStates()
+	require.Len(t, states, 1)
+	require.Equal(t, 2, states[0].ConfirmedChunks())
+
+	// the last chunk confirms the batch
+	require.NoError(t, bs.confirmChunk("chunk0", "", 0, time.Time{}))
+	require.Empty(t, bs.PendingChunkStates())
+	require.NotNil(t, bs.LastSubmittedBatch())
+	require.Equal(t, uint64(10), bs.LastSubmittedBatch().End)
+
+	states, err = bs.loadChunkStates()
+	require.NoError(t, err)
+	require.Empty(t, states)
+
+	// the chunks landed while the bot was down are confirmed on restart
+	state = executortypes.BatchChunkState{
+		Start:     11,
+		End:       20,
+		TraceIDs:  []string{"header2", "chunk2"},
+		Confirmed: make([]bool, 2),
+	}
+	require.NoError(t, bs.saveChunkState(state))
+	bs.da = mockDA{unconfirmed: map[string]struct{}{}}
+	require.NoError(t, bs.resumeChunkStates())
+	require.Empty(t, bs.PendingChunkStates())
+	require.Equal(t, uint64(20), bs.LastSubmittedBatch().End)
+}
+
+func Test_ResubmittedChunkConfirmation(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+
+	bs := &BatchSubmitter{
+		db:            db,
+		logger:        zap.NewNop(),
+		chunkStatesMu: &sync.Mutex{},
+		history:       newBatchHistory(),
+	}
+	da := &dropFirstDA{submitted: make(map[string]bool)}
+	require.NoError(t, bs.SetDANode(da, nil))
+
+	state := executortypes.BatchChunkState{
+		Start:     1,
+		End:       10,
+		TraceIDs:  []string{"header", "chunk0"},
+		Confirmed: make([]bool, 2),
+	}
+	require.NoError(t, bs.saveChunkState(state))
+	bs.trackChunkState(state)
+
+	for _, traceID := range state.TraceIDs {
+		da.BroadcastMsgs(btypes.ProcessedMsgs{TraceID: traceID, Save: true})
+	}
+
+	// the dropped submissions leave the batch pending
+	require.Len(t, da.dropped, 2)
+	require.Len(t, bs.PendingChunkStates(), 1)
+	require.Nil(t, bs.LastSubmittedBatch())
+
+	// the expired txs are resubmitted by the broadcaster with the same trace ids
+	for _, msgs := range da.dropped {
+		msgs.Resubmissions++
+		da.BroadcastMsgs(msgs)
+	}
+	require.Empty(t, bs.PendingChunkStates())
+	require.NotNil(t, bs.LastSubmittedBatch())
+	require.Equal(t, uint64(10), bs.LastSubmittedBatch().End)
+}
@@ -0,0 +1,74 @@
+package batch
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+// batchWriter is the compression writer of the batch file.
+type batchWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+type compressor struct {
+	// defaultLevel is used when the level is not configured
+	defaultLevel int
+	newWriter    func(w io.Writer, level int) (batchWriter, error)
+	newReader    func(r io.Reader) (io.ReadCloser, error)
+}
+
+// compressors is the registry of the batch compression algorithms.
+var compressors = map[executortypes.BatchCompression]compressor{
+	executortypes.BatchCompressionGzip: {
+		// linux command gzip use level 6 as default
+		defaultLevel: 6,
+		newWriter: func(w io.Writer, level int) (batchWriter, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	executortypes.BatchCompressionZstd: {
+		defaultLevel: 3,
+		newWriter: func(w io.Writer, level int) (batchWriter, error) {
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+	},
+}
+
+// newBatchWriter creates the compression writer of the algorithm. If the level is 0, the default level is used.
+func newBatchWriter(compression executortypes.BatchCompression, w io.Writer, level int) (batchWriter, error) {
+	c, ok := compressors[compression]
+	if !ok {
+		return nil, fmt.Errorf("unknown batch compression: %s", compression)
+	}
+	if level == 0 {
+		level = c.defaultLevel
+	}
+	return c.newWriter(w, level)
+}
+
+// NewBatchReader creates the decompression reader of the batch data compressed with the algorithm
+// recorded in the batch header.
+func NewBatchReader(compression executortypes.BatchCompression, r io.Reader) (io.ReadCloser, error) {
+	c, ok := compressors[compression]
+	if !ok {
+		return nil, fmt.Errorf("unknown batch compression: %s", compression)
+	}
+	return c.newReader(r)
+}
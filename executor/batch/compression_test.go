@@ -0,0 +1,161 @@
+package batch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/gogoproto/proto"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+// testBlocks generates the l2 blocks which have the txs similar to each other like the real blocks.
+func testBlocks(t testing.TB, count int) [][]byte {
+	r := rand.New(rand.NewSource(1))
+	blocks := make([][]byte, 0, count)
+	for height := 1; height <= count; height++ {
+		txs := make([][]byte, 0, 10)
+		for i := 0; i < 10; i++ {
+			tx := []byte(fmt.Sprintf(`{"body":{"messages":[{"@type":"/cosmos.bank.v1beta1.MsgSend","from_address":"init1sender%d","to_address":"init1receiver%d","amount":[{"denom":"uinit","amount":"%d"}]}]},"signature":"`, r.Intn(100), r.Intn(100), r.Intn(1000000)))
+			signature := make([]byte, 64)
+			r.Read(signature)
+			tx = append(tx, signature...)
+			txs = append(txs, tx)
+		}
+
+		block := &cmtproto.Block{
+			Header: cmtproto.Header{
+				ChainID: "l2-1",
+				Height:  int64(height),
+				Time:    time.Unix(int64(height), 0).UTC(),
+			},
+			Data: cmtproto.Data{Txs: txs},
+		}
+		blockBytes, err := proto.Marshal(block)
+		require.NoError(t, err)
+		blocks = append(blocks, blockBytes)
+	}
+	return blocks
+}
+
+func compress(t testing.TB, compression executortypes.BatchCompression, level int, blocks [][]byte) []byte {
+	var buf bytes.Buffer
+	writer, err := newBatchWriter(compression, &buf, level)
+	require.NoError(t, err)
+	for _, block := range blocks {
+		_, err := writer.Write(prependLength(block))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func batchData(blocks [][]byte) []byte {
+	data := make([]byte, 0)
+	for _, block := range blocks {
+		data = append(data, prependLength(block)...)
+	}
+	return data
+}
+
+func decompress(t testing.TB, compression executortypes.BatchCompression, data []byte) []byte {
+	reader, err := NewBatchReader(compression, bytes.NewReader(data))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return decompressed
+}
+
+func Test_CompressionRoundTrip(t *testing.T) {
+	blocks := testBlocks(t, 20)
+	expected := batchData(blocks)
+
+	for _, compression := range []executortypes.BatchCompression{executortypes.BatchCompressionGzip, executortypes.BatchCompressionZstd} {
+		for _, level := range []int{0, 1, 9} {
+			data := compress(t, compression, level, blocks)
+			require.Equal(t, expected, decompress(t, compression, data), "%s level %d", compression, level)
+		}
+	}
+
+	_, err := newBatchWriter(executortypes.BatchCompression(100), &bytes.Buffer{}, 0)
+	require.Error(t, err)
+	_, err = NewBatchReader(executortypes.BatchCompression(100), &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func Test_CompressionChangeAtBatchBoundary(t *testing.T) {
+	batchFile, err := os.OpenFile(path.Join(t.TempDir(), "batch"), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0640)
+	require.NoError(t, err)
+	t.Cleanup(func() { batchFile.Close() })
+
+	writer, err := newBatchWriter(executortypes.BatchCompressionGzip, batchFile, 0)
+	require.NoError(t, err)
+
+	// the compression is changed to zstd while the gzip batch is in progress
+	bs := &BatchSubmitter{
+		logger:            zap.NewNop(),
+		batchFile:         batchFile,
+		batchWriter:       writer,
+		localBatchInfo:    &executortypes.LocalBatchInfo{Compression: "gzip"},
+		batchHash:         sha256.New(),
+		compression:       executortypes.BatchCompressionZstd,
+		writerCompression: executortypes.BatchCompressionGzip,
+		metrics:           newBatchMetrics(""),
+	}
+	blocks := testBlocks(t, 4)
+	readBatchFile := func() []byte {
+		require.NoError(t, bs.batchWriter.Close())
+		data, err := os.ReadFile(batchFile.Name())
+		require.NoError(t, err)
+		return decompress(t, bs.writerCompression, data)
+	}
+
+	// the batch in progress keeps the gzip compression
+	for _, block := range blocks[:2] {
+		_, err := bs.handleBatch(block)
+		require.NoError(t, err)
+	}
+	require.Equal(t, batchData(blocks[:2]), readBatchFile())
+
+	// the next batch is compressed with zstd
+	require.NoError(t, batchFile.Truncate(0))
+	require.NoError(t, bs.resetBatchWriter())
+	require.Equal(t, executortypes.BatchCompressionZstd, bs.writerCompression)
+	require.Equal(t, "zstd", bs.localBatchInfo.Compression)
+	for _, block := range blocks[2:] {
+		_, err := bs.handleBatch(block)
+		require.NoError(t, err)
+	}
+	require.Equal(t, batchData(blocks[2:]), readBatchFile())
+}
+
+func Benchmark_Compression(b *testing.B) {
+	blocks := testBlocks(b, 100)
+	rawSize := len(batchData(blocks))
+
+	for _, compression := range []executortypes.BatchCompression{executortypes.BatchCompressionGzip, executortypes.BatchCompressionZstd} {
+		for _, level := range []int{1, 0, 9} {
+			b.Run(fmt.Sprintf("%s-level-%d", compression, level), func(b *testing.B) {
+				var size int
+				for i := 0; i < b.N; i++ {
+					size = len(compress(b, compression, level, blocks))
+				}
+				b.ReportMetric(float64(size), "bytes")
+				b.ReportMetric(float64(size)/float64(rawSize), "ratio")
+			})
+		}
+	}
+}
@@ -4,10 +4,17 @@ import (
 	"encoding/json"
 
 	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	"github.com/initia-labs/opinit-bots/types"
 )
 
-var LocalBatchInfoKey = []byte("local_batch_info")
+var (
+	LocalBatchInfoKey = []byte("local_batch_info")
+
+	BatchChunkStateKey = []byte("batch_chunk_state")
+
+	LastSubmittedBatchKey = []byte("last_submitted_batch")
+)
 
 func (bs *BatchSubmitter) loadLocalBatchInfo() error {
 	val, err := bs.db.Get(LocalBatchInfoKey)
@@ -38,3 +45,67 @@ func (bs *BatchSubmitter) saveLocalBatchInfo() error {
 	}
 	return bs.db.Set(LocalBatchInfoKey, value)
 }
+
+func prefixedBatchChunkStateKey(start uint64) []byte {
+	return append(append(BatchChunkStateKey, dbtypes.Splitter), dbtypes.FromUint64Key(start)...)
+}
+
+func (bs *BatchSubmitter) chunkStateToRawKV(state executortypes.BatchChunkState) (types.RawKV, error) {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return types.RawKV{}, err
+	}
+	return types.RawKV{
+		Key:   bs.db.PrefixedKey(prefixedBatchChunkStateKey(state.Start)),
+		Value: value,
+	}, nil
+}
+
+func (bs *BatchSubmitter) saveChunkState(state executortypes.BatchChunkState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return bs.db.Set(prefixedBatchChunkStateKey(state.Start), value)
+}
+
+func (bs *BatchSubmitter) deleteChunkState(start uint64) error {
+	return bs.db.Delete(prefixedBatchChunkStateKey(start))
+}
+
+func (bs *BatchSubmitter) loadChunkStates() (states []executortypes.BatchChunkState, err error) {
+	iterErr := bs.db.PrefixedIterate(append(BatchChunkStateKey, dbtypes.Splitter), nil, func(_, value []byte) (stop bool, err error) {
+		var state executortypes.BatchChunkState
+		err = json.Unmarshal(value, &state)
+		if err != nil {
+			return true, err
+		}
+		states = append(states, state)
+		return false, nil
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	return states, nil
+}
+
+func (bs *BatchSubmitter) saveLastSubmittedBatch(state executortypes.BatchChunkState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return bs.db.Set(LastSubmittedBatchKey, value)
+}
+
+func (bs *BatchSubmitter) loadLastSubmittedBatch() (*executortypes.BatchChunkState, error) {
+	val, err := bs.db.Get(LastSubmittedBatchKey)
+	if err == dbtypes.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := &executortypes.BatchChunkState{}
+	err = json.Unmarshal(val, state)
+	return state, err
+}
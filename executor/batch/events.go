@@ -0,0 +1,46 @@
+package batch
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// queryBlockEvents queries the results of the block and marshals its events of the allowed types,
+// which are written after the block bytes in the BatchVersionBlockEvents format.
+func (bs *BatchSubmitter) queryBlockEvents(ctx context.Context, height int64) ([]byte, error) {
+	res, err := bs.node.GetRPCClient().QueryBlockResults(ctx, height)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query block results")
+	}
+	return proto.Marshal(FilterBlockEvents(res, bs.blockEvents))
+}
+
+// FilterBlockEvents returns the compact block events which keep only the events of the allowed types.
+// The finalize block events are kept in Events, and the tx events are kept in TxResults by the tx index,
+// whose results other than the events are dropped.
+func FilterBlockEvents(res *coretypes.ResultBlockResults, eventTypes map[string]struct{}) *abcitypes.ResponseFinalizeBlock {
+	filter := func(events []abcitypes.Event) []abcitypes.Event {
+		filtered := make([]abcitypes.Event, 0)
+		for _, event := range events {
+			if _, ok := eventTypes[event.Type]; ok {
+				filtered = append(filtered, event)
+			}
+		}
+		return filtered
+	}
+
+	blockEvents := &abcitypes.ResponseFinalizeBlock{
+		Events:    filter(res.FinalizeBlockEvents),
+		TxResults: make([]*abcitypes.ExecTxResult, 0, len(res.TxsResults)),
+	}
+	for _, txResult := range res.TxsResults {
+		blockEvents.TxResults = append(blockEvents.TxResults, &abcitypes.ExecTxResult{Events: filter(txResult.Events)})
+	}
+	return blockEvents
+}
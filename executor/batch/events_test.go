@@ -0,0 +1,106 @@
+package batch
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/gogoproto/proto"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+// testBlockResults generates the block results which have the oracle votes in the finalize block events
+// and the transfer events in each tx like the real blocks.
+func testBlockResults(count int) []*coretypes.ResultBlockResults {
+	r := rand.New(rand.NewSource(1))
+	results := make([]*coretypes.ResultBlockResults, 0, count)
+	for height := 1; height <= count; height++ {
+		res := &coretypes.ResultBlockResults{Height: int64(height)}
+		for i := 0; i < 5; i++ {
+			res.FinalizeBlockEvents = append(res.FinalizeBlockEvents, abcitypes.Event{
+				Type: "oracle_vote",
+				Attributes: []abcitypes.EventAttribute{
+					{Key: "validator", Value: fmt.Sprintf("initvaloper1validator%d", i), Index: true},
+					{Key: "price", Value: fmt.Sprintf("%d", r.Intn(1000000))},
+				},
+			})
+		}
+		for i := 0; i < 10; i++ {
+			res.TxsResults = append(res.TxsResults, &abcitypes.ExecTxResult{
+				GasUsed: int64(r.Intn(100000)),
+				Events: []abcitypes.Event{{
+					Type: "transfer",
+					Attributes: []abcitypes.EventAttribute{
+						{Key: "recipient", Value: fmt.Sprintf("init1receiver%d", r.Intn(100))},
+						{Key: "amount", Value: fmt.Sprintf("%duinit", r.Intn(1000000))},
+					},
+				}},
+			})
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+func Test_FilterBlockEvents(t *testing.T) {
+	res := testBlockResults(1)[0]
+
+	events := FilterBlockEvents(res, map[string]struct{}{"oracle_vote": {}})
+	require.Len(t, events.Events, 5)
+	require.Equal(t, res.FinalizeBlockEvents, events.Events)
+	// the tx results are kept by the tx index without the events of the other types
+	require.Len(t, events.TxResults, 10)
+	for _, txResult := range events.TxResults {
+		require.Empty(t, txResult.Events)
+		require.Zero(t, txResult.GasUsed)
+	}
+
+	events = FilterBlockEvents(res, map[string]struct{}{"transfer": {}})
+	require.Empty(t, events.Events)
+	for i, txResult := range events.TxResults {
+		require.Equal(t, res.TxsResults[i].Events, txResult.Events)
+	}
+
+	bz, err := proto.Marshal(events)
+	require.NoError(t, err)
+	decoded := new(abcitypes.ResponseFinalizeBlock)
+	require.NoError(t, proto.Unmarshal(bz, decoded))
+	require.Equal(t, events.TxResults[0].Events, decoded.TxResults[0].Events)
+}
+
+// Benchmark_BlockEvents measures the size impact of the block events on the compressed batch.
+func Benchmark_BlockEvents(b *testing.B) {
+	blocks := testBlocks(b, 100)
+	results := testBlockResults(100)
+
+	for _, eventTypes := range [][]string{nil, {"oracle_vote"}, {"oracle_vote", "transfer"}} {
+		entries := make([][]byte, 0, len(blocks)*2)
+		for i, block := range blocks {
+			entries = append(entries, block)
+			if eventTypes == nil {
+				continue
+			}
+
+			allowlist := make(map[string]struct{})
+			for _, eventType := range eventTypes {
+				allowlist[eventType] = struct{}{}
+			}
+			bz, err := proto.Marshal(FilterBlockEvents(results[i], allowlist))
+			require.NoError(b, err)
+			entries = append(entries, bz)
+		}
+
+		b.Run(fmt.Sprintf("events-%v", eventTypes), func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				size = len(compress(b, executortypes.BatchCompressionZstd, 0, entries))
+			}
+			b.ReportMetric(float64(size), "bytes")
+		})
+	}
+}
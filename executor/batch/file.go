@@ -0,0 +1,176 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+const (
+	// batchTempFileName is the file of the batch in progress, which is appended block by block.
+	batchTempFileName = "batch.tmp"
+	// batchFileName is the file of the finalized batch, which is renamed from the temp file atomically.
+	batchFileName = "batch"
+)
+
+var errBatchChecksumMismatch = errors.New("batch file checksum mismatch")
+
+// openBatchTempFile opens the temp file of the batch in progress.
+func (bs *BatchSubmitter) openBatchTempFile() error {
+	file, err := os.OpenFile(path.Join(bs.homePath, batchTempFileName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to open batch temp file")
+	}
+	bs.batchFile = file
+	return nil
+}
+
+// batchWriterTarget returns the writer of the compressed batch, which also hashes the written bytes
+// to validate the batch file before the submission.
+func (bs *BatchSubmitter) batchWriterTarget() io.Writer {
+	bs.batchHash.Reset()
+	return io.MultiWriter(bs.batchFile, bs.batchHash)
+}
+
+// finalizeBatchFile fsyncs the temp file of the batch and renames it to the finalized batch file atomically.
+// The chunks read from the finalized file are validated against the checksum of the written bytes,
+// and the new temp file is opened for the next batch.
+func (bs *BatchSubmitter) finalizeBatchFile() (chunks [][]byte, checksums [][]byte, err error) {
+	err = bs.batchFile.Sync()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to sync batch file")
+	}
+	checksum := bs.batchHash.Sum(nil)
+
+	err = os.Rename(path.Join(bs.homePath, batchTempFileName), path.Join(bs.homePath, batchFileName))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to rename batch file")
+	}
+
+	// the renamed file is still open to read the chunks
+	finalizedFile := bs.batchFile
+	defer finalizedFile.Close()
+	err = bs.openBatchTempFile()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chunks, checksums, err = splitBatchFile(finalizedFile, bs.batchCfg.MaxChunkSize)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to split batch file")
+	}
+
+	hash := sha256.New()
+	for _, chunk := range chunks {
+		hash.Write(chunk)
+	}
+	if !bytes.Equal(hash.Sum(nil), checksum) {
+		return nil, nil, errBatchChecksumMismatch
+	}
+	bs.localBatchInfo.Checksum = checksum
+	return chunks, checksums, nil
+}
+
+// rewindBatch discards the batch file and rewinds the sync info to restart the batch in progress
+// from its start height, which is the last durable height recorded in the db.
+func (bs *BatchSubmitter) rewindBatch() error {
+	if bs.batchFile != nil {
+		bs.batchFile.Close()
+	}
+	err := bs.openBatchTempFile()
+	if err != nil {
+		return err
+	}
+	err = bs.batchFile.Truncate(0)
+	if err != nil {
+		return errors.Wrap(err, "failed to truncate batch file")
+	}
+	_, err = bs.batchFile.Seek(0, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to seek batch file")
+	}
+
+	bs.localBatchInfo.End = 0
+	bs.localBatchInfo.BatchFileSize = 0
+	bs.localBatchInfo.Checksum = nil
+	// the block offsets are kept to verify the rebuilt blocks, unless the payload format is changed
+	if bs.localBatchInfo.Version != bs.batchVersion {
+		bs.localBatchInfo.BlockOffsets = nil
+	}
+	err = bs.resetBatchWriter()
+	if err != nil {
+		return err
+	}
+	err = bs.saveLocalBatchInfo()
+	if err != nil {
+		return err
+	}
+
+	err = bs.node.SaveSyncInfo(bs.localBatchInfo.Start - 1)
+	if err != nil {
+		return errors.Wrap(err, "failed to save sync info")
+	}
+	bs.node.SetSyncInfo(bs.localBatchInfo.Start - 1)
+	return nil
+}
+
+// recoverBatchFile discards the batch in progress on startup. The compression stream of the batch
+// can't be continued after the restart, the tail written after the last durable block may be torn,
+// and the file is lost when the bot is moved to another machine, so the batch is rebuilt from
+// its start height recorded in the db by re-fetching the blocks from the node. The rebuilt blocks
+// are verified against the block offsets recorded in the db.
+func (bs *BatchSubmitter) recoverBatchFile(ctx context.Context) error {
+	if bs.localBatchInfo.End != 0 || bs.localBatchInfo.Start == 0 {
+		// no batch in progress, the next block starts a new batch
+		return nil
+	}
+
+	info, err := bs.batchFile.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to get batch file stat")
+	}
+	bs.logger.Warn("rebuild the batch in progress",
+		zap.Int64("batch_start", bs.localBatchInfo.Start),
+		zap.Int64("durable_size", bs.localBatchInfo.BatchFileSize),
+		zap.Int64("file_size", info.Size()),
+		zap.Bool("torn", info.Size() != bs.localBatchInfo.BatchFileSize),
+		zap.Int("recorded_blocks", len(bs.localBatchInfo.BlockOffsets)),
+	)
+
+	status, err := bs.node.GetRPCClient().Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to query node status")
+	} else if status.SyncInfo.EarliestBlockHeight > bs.localBatchInfo.Start {
+		return fmt.Errorf("%w: the blocks of the batch in progress from %d are required, but the earliest block height of the node is %d",
+			types.ErrHeightPruned, bs.localBatchInfo.Start, status.SyncInfo.EarliestBlockHeight)
+	}
+	return bs.rewindBatch()
+}
+
+// recordBlockOffset records the raw offset of the batch payload at the end of the block, which is persisted
+// with the local batch info. The block rebuilt after the rewind must end at the recorded offset.
+func (bs *BatchSubmitter) recordBlockOffset(blockHeight int64) error {
+	index := blockHeight - bs.localBatchInfo.Start
+	offsets := bs.localBatchInfo.BlockOffsets
+	switch {
+	case index < 0 || index > int64(len(offsets)):
+		// the offsets of the batch started before the offsets are recorded are not complete
+		return nil
+	case index < int64(len(offsets)):
+		if offsets[index] != bs.rawBatchSize {
+			return fmt.Errorf("rebuilt block %d diverges from the batch recorded in the db; offset: %d, recorded: %d", blockHeight, bs.rawBatchSize, offsets[index])
+		}
+		return nil
+	}
+	bs.localBatchInfo.BlockOffsets = append(offsets, bs.rawBatchSize)
+	return nil
+}
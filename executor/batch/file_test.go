@@ -0,0 +1,217 @@
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/keys"
+	"github.com/initia-labs/opinit-bots/node"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// newMockStatusServer serves the status of the l2 node with the earliest block height.
+func newMockStatusServer(t *testing.T, earliestHeight int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "status" {
+			http.Error(w, "unknown method", http.StatusNotFound)
+			return
+		}
+
+		res, err := cmtjson.Marshal(&rpccoretypes.ResultStatus{SyncInfo: rpccoretypes.SyncInfo{
+			EarliestBlockHeight: earliestHeight,
+			LatestBlockHeight:   100,
+		}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + string(res) + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestFileBatchSubmitter(t *testing.T, earliestHeight int64) *BatchSubmitter {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces})
+	require.NoError(t, err)
+	n, err := node.NewNode(nodetypes.NodeConfig{
+		RPC:          newMockStatusServer(t, earliestHeight).URL,
+		ChainID:      "l2-1",
+		ProcessType:  nodetypes.PROCESS_TYPE_RAW,
+		Bech32Prefix: "init",
+	}, db, zap.NewNop(), cdc, txConfig)
+	require.NoError(t, err)
+
+	bs := &BatchSubmitter{
+		node:           n,
+		db:             db,
+		logger:         zap.NewNop(),
+		batchCfg:       executortypes.BatchConfig{MaxChunkSize: 100},
+		localBatchInfo: &executortypes.LocalBatchInfo{},
+		batchHash:      sha256.New(),
+		compression:    executortypes.BatchCompressionGzip,
+		chunkStatesMu:  &sync.Mutex{},
+		metrics:        newBatchMetrics(""),
+		history:        newBatchHistory(),
+		homePath:       t.TempDir(),
+	}
+	require.NoError(t, bs.openBatchTempFile())
+	require.NoError(t, bs.resetBatchWriter())
+	t.Cleanup(bs.Close)
+	return bs
+}
+
+func Test_RecoverBatchFile(t *testing.T) {
+	blocks := testBlocks(t, 10)
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10; i++ {
+		bs := newTestFileBatchSubmitter(t, 1)
+		bs.localBatchInfo.Start = 5
+		bs.node.SetSyncInfo(14)
+
+		for _, block := range blocks {
+			_, err := bs.handleBatch(block)
+			require.NoError(t, err)
+		}
+		fileSize, err := bs.batchFileSize(true)
+		require.NoError(t, err)
+		bs.localBatchInfo.BatchFileSize = fileSize
+
+		// crash at a random offset of the batch file
+		require.NoError(t, bs.batchFile.Truncate(r.Int63n(fileSize+1)))
+		require.NoError(t, bs.recoverBatchFile(context.Background()))
+
+		// the batch restarts from its start height with the empty file
+		require.Equal(t, int64(5), bs.node.GetHeight())
+		require.Equal(t, int64(0), bs.localBatchInfo.BatchFileSize)
+		require.Equal(t, int64(0), bs.localBatchInfo.End)
+		info, err := bs.batchFile.Stat()
+		require.NoError(t, err)
+		require.Equal(t, int64(0), info.Size())
+
+		// the rebuilt batch is a valid compression stream
+		for _, block := range blocks {
+			_, err := bs.handleBatch(block)
+			require.NoError(t, err)
+		}
+		require.NoError(t, bs.batchWriter.Close())
+		chunks, _, err := bs.finalizeBatchFile()
+		require.NoError(t, err)
+
+		data := make([]byte, 0)
+		for _, chunk := range chunks {
+			data = append(data, chunk...)
+		}
+		require.Equal(t, batchData(blocks), decompress(t, executortypes.BatchCompressionGzip, data))
+		finalized, err := os.ReadFile(path.Join(bs.homePath, batchFileName))
+		require.NoError(t, err)
+		require.Equal(t, data, finalized)
+		require.Equal(t, sha256.Sum256(data), [32]byte(bs.localBatchInfo.Checksum))
+	}
+}
+
+func Test_FinalizeBatchFileChecksumMismatch(t *testing.T) {
+	blocks := testBlocks(t, 10)
+	r := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 10; i++ {
+		bs := newTestFileBatchSubmitter(t, 1)
+		for _, block := range blocks {
+			_, err := bs.handleBatch(block)
+			require.NoError(t, err)
+		}
+		require.NoError(t, bs.batchWriter.Close())
+		fileSize, err := bs.batchFileSize(false)
+		require.NoError(t, err)
+
+		// torn write of the batch file
+		require.NoError(t, bs.batchFile.Truncate(r.Int63n(fileSize)))
+		_, _, err = bs.finalizeBatchFile()
+		require.ErrorIs(t, err, errBatchChecksumMismatch)
+	}
+}
+
+func Test_RecoverLostBatchFile(t *testing.T) {
+	blocks := testBlocks(t, 10)
+
+	for _, tc := range []struct {
+		name           string
+		earliestHeight int64
+		pruned         bool
+	}{
+		{"blocks available", 5, false},
+		{"blocks pruned", 6, true},
+	} {
+		bs := newTestFileBatchSubmitter(t, tc.earliestHeight)
+		bs.localBatchInfo.Start = 5
+		bs.node.SetSyncInfo(14)
+		for i, block := range blocks {
+			_, err := bs.handleBatch(block)
+			require.NoError(t, err)
+			require.NoError(t, bs.recordBlockOffset(int64(5+i)))
+		}
+		fileSize, err := bs.batchFileSize(true)
+		require.NoError(t, err)
+		bs.localBatchInfo.BatchFileSize = fileSize
+		require.NoError(t, bs.saveLocalBatchInfo())
+
+		// the bot is moved to another machine without the batch file
+		bs.batchFile.Close()
+		require.NoError(t, os.Remove(path.Join(bs.homePath, batchTempFileName)))
+		require.NoError(t, bs.openBatchTempFile())
+
+		err = bs.recoverBatchFile(context.Background())
+		if tc.pruned {
+			require.ErrorIs(t, err, types.ErrHeightPruned, tc.name)
+			require.Equal(t, int64(15), bs.node.GetHeight(), tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+
+		// the blocks of the batch are re-fetched from its start height
+		require.Equal(t, int64(5), bs.node.GetHeight(), tc.name)
+		require.Equal(t, int64(0), bs.localBatchInfo.BatchFileSize, tc.name)
+
+		// the rebuilt blocks are verified against the block offsets recorded in the db
+		bs.localBatchInfo = &executortypes.LocalBatchInfo{}
+		require.NoError(t, bs.loadLocalBatchInfo(), tc.name)
+		require.Len(t, bs.localBatchInfo.BlockOffsets, len(blocks), tc.name)
+		for i, block := range blocks[:5] {
+			_, err := bs.handleBatch(block)
+			require.NoError(t, err, tc.name)
+			require.NoError(t, bs.recordBlockOffset(int64(5+i)), tc.name)
+		}
+		_, err = bs.handleBatch(blocks[6])
+		require.NoError(t, err, tc.name)
+		require.ErrorContains(t, bs.recordBlockOffset(10), "diverges from the batch recorded in the db", tc.name)
+	}
+}
@@ -0,0 +1,74 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+// fitBatchGas simulates the header and the largest chunk of the batch, and splits the batch into the smaller
+// chunks until the estimated gas of each tx fits the max batch gas. The chunks are returned as they are
+// if the max batch gas is not set or the DA node can't simulate the batch msgs. The batch is not split into
+// more chunks than the max chunks.
+func (bs *BatchSubmitter) fitBatchGas(ctx context.Context, chunks [][]byte, checksums [][]byte) ([][]byte, [][]byte, error) {
+	da := bs.DA()
+	simulator, ok := da.(executortypes.BatchMsgSimulator)
+	if bs.batchCfg.MaxBatchGas == 0 || !ok {
+		return chunks, checksums, nil
+	}
+
+	for {
+		batchData, err := bs.marshalBatchData(chunks, checksums)
+		if err != nil {
+			return nil, nil, err
+		}
+		headerGas, err := bs.simulateBatchData(ctx, da, simulator, batchData[0])
+		if err != nil {
+			return nil, nil, err
+		} else if headerGas > bs.batchCfg.MaxBatchGas {
+			return nil, nil, fmt.Errorf("estimated gas %d of the batch header exceeds the max batch gas %d", headerGas, bs.batchCfg.MaxBatchGas)
+		} else if len(chunks) == 0 {
+			return chunks, checksums, nil
+		}
+
+		// the chunks have the same size except the last one, so the first chunk is the largest
+		chunkGas, err := bs.simulateBatchData(ctx, da, simulator, batchData[1])
+		if err != nil {
+			return nil, nil, err
+		} else if chunkGas <= bs.batchCfg.MaxBatchGas {
+			return chunks, checksums, nil
+		}
+
+		chunkSize := int64(len(chunks[0])) / 2
+		if chunkSize == 0 {
+			return nil, nil, fmt.Errorf("estimated gas %d of the batch chunk exceeds the max batch gas %d", chunkGas, bs.batchCfg.MaxBatchGas)
+		}
+		bs.logger.Info("split batch chunks to fit the max batch gas",
+			zap.Int64("batch_start", bs.localBatchInfo.Start),
+			zap.Int64("batch_end", bs.localBatchInfo.End),
+			zap.Uint64("estimated_gas", chunkGas),
+			zap.Uint64("max_batch_gas", bs.batchCfg.MaxBatchGas),
+			zap.Int64("chunk_size", chunkSize),
+		)
+
+		chunks, checksums, err = splitBatchFile(bytes.NewReader(bytes.Join(chunks, nil)), chunkSize)
+		if err != nil {
+			return nil, nil, err
+		} else if int64(len(chunks)) > bs.batchCfg.MaxChunks {
+			return nil, nil, fmt.Errorf("%d chunks to fit the max batch gas %d exceed the max chunks %d", len(chunks), bs.batchCfg.MaxBatchGas, bs.batchCfg.MaxChunks)
+		}
+	}
+}
+
+// simulateBatchData returns the estimated gas of the batch msg of the data, or 0 if the DA key is not set.
+func (bs *BatchSubmitter) simulateBatchData(ctx context.Context, da executortypes.DANode, simulator executortypes.BatchMsgSimulator, data []byte) (uint64, error) {
+	msg, sender, err := da.CreateBatchMsg(data)
+	if err != nil || msg == nil {
+		return 0, err
+	}
+	return simulator.SimulateBatchMsg(ctx, msg, sender)
+}
@@ -0,0 +1,120 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// gasDA simulates the batch msgs with the gas proportional to the size of the batch bytes.
+type gasDA struct {
+	NoopDA
+
+	simulated int
+}
+
+var _ executortypes.BatchMsgSimulator = &gasDA{}
+
+func (m *gasDA) CreateBatchMsg(data []byte) (sdk.Msg, string, error) {
+	return ophosttypes.NewMsgRecordBatch("init1submitter", 1, data), "init1submitter", nil
+}
+
+func (m *gasDA) SimulateBatchMsg(_ context.Context, msg sdk.Msg, _ string) (uint64, error) {
+	m.simulated++
+	return batchGas(msg.(*ophosttypes.MsgRecordBatch).BatchBytes), nil
+}
+
+func batchGas(data []byte) uint64 {
+	return 1000 + 10*uint64(len(data))
+}
+
+func Test_FitBatchGas(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(data)
+	chunks, checksums, err := splitBatchFile(bytes.NewReader(data), 1000)
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+
+	checksum := sha256.Sum256(data)
+
+	bs := &BatchSubmitter{
+		logger:         zap.NewNop(),
+		batchCfg:       executortypes.BatchConfig{MaxChunkSize: 1000, MaxChunks: 4},
+		daMu:           &sync.RWMutex{},
+		localBatchInfo: &executortypes.LocalBatchInfo{Start: 1, End: 10, Checksum: checksum[:]},
+		rawBatchSize:   2000,
+	}
+
+	// the batch is not simulated without the max batch gas or the simulator
+	bs.da = &gasDA{}
+	fitted, _, err := bs.fitBatchGas(context.Background(), chunks, checksums)
+	require.NoError(t, err)
+	require.Equal(t, chunks, fitted)
+	require.Zero(t, bs.da.(*gasDA).simulated)
+
+	bs.batchCfg.MaxBatchGas = batchGas(make([]byte, 300))
+	bs.da = NewNoopDA()
+	fitted, _, err = bs.fitBatchGas(context.Background(), chunks, checksums)
+	require.NoError(t, err)
+	require.Equal(t, chunks, fitted)
+
+	// the chunks are halved until every batch tx fits the max batch gas
+	bs.da = &gasDA{}
+	fitted, fittedChecksums, err := bs.fitBatchGas(context.Background(), chunks, checksums)
+	require.NoError(t, err)
+	require.Len(t, fitted, 4)
+	require.Len(t, fittedChecksums, 4)
+
+	batchData, err := bs.marshalBatchData(fitted, fittedChecksums)
+	require.NoError(t, err)
+	for _, bz := range batchData {
+		require.LessOrEqual(t, batchGas(bz), bs.batchCfg.MaxBatchGas)
+	}
+
+	// the header has the checksum of the batch file
+	header, err := executortypes.UnmarshalBatchDataHeader(batchData[0])
+	require.NoError(t, err)
+	require.Equal(t, checksum[:], header.PayloadChecksum)
+	batchChunks := make([]executortypes.BatchDataChunk, 0, len(fitted))
+	for _, bz := range batchData[1:] {
+		chunk, err := executortypes.UnmarshalBatchDataChunk(bz)
+		require.NoError(t, err)
+		batchChunks = append(batchChunks, chunk)
+	}
+	reassembled, err := executortypes.ReassembleBatchChunks(header, batchChunks)
+	require.NoError(t, err)
+	require.Equal(t, data, reassembled)
+
+	// the legacy header has no commitments if the committed header is disabled
+	bs.batchCfg.DisableCommittedHeader = true
+	batchData, err = bs.marshalBatchData(fitted, fittedChecksums)
+	require.NoError(t, err)
+	header, err = executortypes.UnmarshalBatchDataHeader(batchData[0])
+	require.NoError(t, err)
+	require.False(t, header.Committed())
+	require.Equal(t, fittedChecksums, header.Checksums)
+	bs.batchCfg.DisableCommittedHeader = false
+
+	// the batch is not split into more chunks than the max chunks
+	bs.batchCfg.MaxChunks = 3
+	_, _, err = bs.fitBatchGas(context.Background(), chunks, checksums)
+	require.ErrorContains(t, err, "exceed the max chunks 3")
+	bs.batchCfg.MaxChunks = 4
+
+	// the header must fit the max batch gas by itself
+	bs.batchCfg.MaxBatchGas = batchGas(nil)
+	_, _, err = bs.fitBatchGas(context.Background(), chunks, checksums)
+	require.Error(t, err)
+}
@@ -1,10 +1,8 @@
 package batch
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"time"
 
 	"github.com/pkg/errors"
@@ -25,21 +23,29 @@ import (
 )
 
 func (bs *BatchSubmitter) rawBlockHandler(ctx context.Context, args nodetypes.RawBlockArgs) error {
+	// the block is processed after the backlog drains, so no block is skipped
+	err := bs.waitForPendingBatches(ctx, args.BlockHeight)
+	if err != nil {
+		return err
+	}
+
 	// clear processed messages
 	bs.processedMsgs = bs.processedMsgs[:0]
+	bs.finalizedChunkState = nil
+	bs.secondaryMsgs = bs.secondaryMsgs[:0]
 
 	pbb := new(cmtproto.Block)
-	err := proto.Unmarshal(args.BlockBytes, pbb)
+	err = proto.Unmarshal(args.BlockBytes, pbb)
 	if err != nil {
 		return errors.Wrap(err, "failed to unmarshal block")
 	}
 
-	err = bs.prepareBatch(args.BlockHeight)
+	err = bs.prepareBatch(ctx, args.BlockHeight)
 	if err != nil {
 		return errors.Wrap(err, "failed to prepare batch")
 	}
 
-	blockBytes, err := bs.emptyOracleData(pbb)
+	blockBytes, err := EmptyOracleData(bs.node.GetTxConfig(), bs.node.DecodeTxMsgs, pbb)
 	if err != nil {
 		return err
 	}
@@ -49,6 +55,22 @@ func (bs *BatchSubmitter) rawBlockHandler(ctx context.Context, args nodetypes.Ra
 		return errors.Wrap(err, "failed to handle batch")
 	}
 
+	if bs.writerBatchVersion == executortypes.BatchVersionBlockEvents {
+		eventsBytes, err := bs.queryBlockEvents(ctx, args.BlockHeight)
+		if err != nil {
+			return err
+		}
+		_, err = bs.handleBatch(eventsBytes)
+		if err != nil {
+			return errors.Wrap(err, "failed to handle block events")
+		}
+	}
+
+	err = bs.recordBlockOffset(args.BlockHeight)
+	if err != nil {
+		return err
+	}
+
 	err = bs.checkBatch(ctx, args.BlockHeight, args.LatestHeight, pbb.Header.Time)
 	if err != nil {
 		return errors.Wrap(err, "failed to check batch")
@@ -69,18 +91,34 @@ func (bs *BatchSubmitter) rawBlockHandler(ctx context.Context, args nodetypes.Ra
 	}
 	batchKVs = append(batchKVs, kv)
 
+	if bs.finalizedChunkState != nil {
+		kv, err := bs.chunkStateToRawKV(*bs.finalizedChunkState)
+		if err != nil {
+			return err
+		}
+		batchKVs = append(batchKVs, kv)
+	}
+
 	err = bs.db.RawBatchSet(batchKVs...)
 	if err != nil {
-		return errors.Wrap(err, "failed to set raw batch")
+		return errors.Wrap(fmt.Errorf("%w: %w", nodetypes.ErrFatalDB, err), "failed to set raw batch")
 	}
+	// track the chunks before broadcasting, so no confirmation is missed
+	if bs.finalizedChunkState != nil {
+		bs.trackChunkState(*bs.finalizedChunkState)
+	}
+	bs.updateProgress()
 	// broadcast processed messages
 	for _, processedMsg := range bs.processedMsgs {
-		bs.da.BroadcastMsgs(processedMsg)
+		err = bs.da.BroadcastMsgs(processedMsg)
+		if err != nil {
+			return err
+		}
 	}
-	return nil
+	return bs.submitSecondaryBatch()
 }
 
-func (bs *BatchSubmitter) prepareBatch(blockHeight int64) error {
+func (bs *BatchSubmitter) prepareBatch(ctx context.Context, blockHeight int64) error {
 	err := bs.loadLocalBatchInfo()
 	if err != nil {
 		return err
@@ -88,40 +126,10 @@ func (bs *BatchSubmitter) prepareBatch(blockHeight int64) error {
 
 	// check whether the requested block height is reached to the l2 block number of the next batch info.
 	if nextBatchInfo := bs.NextBatchInfo(); nextBatchInfo != nil && types.MustUint64ToInt64(nextBatchInfo.Output.L2BlockNumber) < blockHeight {
-		// if the next batch info is reached, finalize the current batch and update the batch info.
-		if bs.batchWriter != nil {
-			err := bs.batchWriter.Close()
-			if err != nil {
-				return errors.Wrap(err, "failed to close batch writer")
-			}
-		}
-		err := bs.batchFile.Truncate(0)
-		if err != nil {
-			return errors.Wrap(err, "failed to truncate batch file")
-		}
-		_, err = bs.batchFile.Seek(0, 0)
-		if err != nil {
-			return errors.Wrap(err, "failed to seek batch file")
-		}
-
-		// save sync info
-		err = bs.node.SaveSyncInfo(types.MustUint64ToInt64(nextBatchInfo.Output.L2BlockNumber))
-		if err != nil {
-			return errors.Wrap(err, "failed to save sync info")
-		}
-		bs.localBatchInfo.Start = types.MustUint64ToInt64(nextBatchInfo.Output.L2BlockNumber) + 1
-		bs.localBatchInfo.End = 0
-		bs.localBatchInfo.BatchFileSize = 0
-		err = bs.saveLocalBatchInfo()
+		err := bs.rotateBatchInfo(ctx, blockHeight, *nextBatchInfo)
 		if err != nil {
 			return err
 		}
-		// set last processed block height to l2 block number
-		bs.node.SetSyncInfo(types.MustUint64ToInt64(nextBatchInfo.Output.L2BlockNumber))
-		bs.DequeueBatchInfo()
-
-		// error will restart block process from nextBatchInfo.Output.L2BlockNumber + 1
-		panic(fmt.Errorf("batch info updated: reset from %d", nextBatchInfo.Output.L2BlockNumber))
 	}
 
 	if bs.localBatchInfo.End != 0 {
@@ -138,15 +146,119 @@ func (bs *BatchSubmitter) prepareBatch(blockHeight int64) error {
 		bs.localBatchInfo.BatchFileSize = 0
 		bs.localBatchInfo.Start = blockHeight
 		bs.localBatchInfo.End = 0
+		bs.localBatchInfo.BlockOffsets = nil
 
-		bs.batchWriter.Reset(bs.batchFile)
+		err = bs.resetBatchWriter()
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// resetBatchWriter resets the batch writer for the new batch at the batch boundary,
+// where the configured compression takes effect.
+func (bs *BatchSubmitter) resetBatchWriter() error {
+	bs.localBatchInfo.Compression = bs.compression.String()
+	bs.localBatchInfo.Version = bs.batchVersion
+	bs.writerBatchVersion = bs.batchVersion
+	bs.rawBatchSize = 0
+	if bs.batchWriter != nil && bs.writerCompression == bs.compression {
+		bs.batchWriter.Reset(bs.batchWriterTarget())
+		return nil
+	}
+
+	writer, err := newBatchWriter(bs.compression, bs.batchWriterTarget(), bs.batchCfg.CompressionLevel)
+	if err != nil {
+		return errors.Wrap(err, "failed to create batch writer")
+	}
+	if bs.writerCompression != bs.compression {
+		bs.logger.Info("change batch compression",
+			zap.String("from", bs.writerCompression.String()),
+			zap.String("to", bs.compression.String()),
+			zap.Int64("start", bs.localBatchInfo.Start),
+		)
+	}
+	bs.batchWriter = writer
+	bs.writerCompression = bs.compression
+	return nil
+}
+
 // write block bytes to batch file
 func (bs *BatchSubmitter) handleBatch(blockBytes []byte) (int, error) {
-	return bs.batchWriter.Write(prependLength(blockBytes))
+	n, err := bs.batchWriter.Write(prependLength(blockBytes))
+	bs.metrics.BytesWritten.Add(float64(n))
+	bs.rawBatchSize += int64(n)
+	return n, err
+}
+
+// createBatchMsgs creates the processed msgs of the header and the chunks of the batch for the DA node.
+func createBatchMsgs(da executortypes.DANode, batchData [][]byte, save bool) ([]btypes.ProcessedMsgs, error) {
+	processedMsgs := make([]btypes.ProcessedMsgs, 0, len(batchData))
+	for _, data := range batchData {
+		msg, sender, err := da.CreateBatchMsg(data)
+		if err != nil {
+			return nil, err
+		} else if msg != nil {
+			processedMsgs = append(processedMsgs, btypes.ProcessedMsgs{
+				Sender:    sender,
+				Msgs:      []sdk.Msg{msg},
+				Timestamp: time.Now().UnixNano(),
+				Save:      save,
+			}.WithTraceID())
+		}
+	}
+	return processedMsgs, nil
+}
+
+// marshalBatchData marshals the header and the chunks of the batch in order, the header first.
+// The header commits to the lengths and the checksum of the compressed payload, which is the checksum
+// of the batch file validated at the finalization, so a reader can detect a substituted payload.
+// If the committed header is disabled, the header is marshaled in the legacy layout without the commitments.
+func (bs *BatchSubmitter) marshalBatchData(chunks [][]byte, checksums [][]byte) ([][]byte, error) {
+	compressedLength := 0
+	for _, chunk := range chunks {
+		compressedLength += len(chunk)
+	}
+
+	var header []byte
+	if bs.batchCfg.DisableCommittedHeader {
+		header = executortypes.MarshalBatchDataHeader(
+			types.MustInt64ToUint64(bs.localBatchInfo.Start),
+			types.MustInt64ToUint64(bs.localBatchInfo.End),
+			bs.writerCompression,
+			bs.writerBatchVersion,
+			checksums,
+		)
+	} else {
+		var err error
+		header, err = executortypes.MarshalCommittedBatchDataHeader(executortypes.BatchDataHeader{
+			Start:            types.MustInt64ToUint64(bs.localBatchInfo.Start),
+			End:              types.MustInt64ToUint64(bs.localBatchInfo.End),
+			Compression:      bs.writerCompression,
+			Version:          bs.writerBatchVersion,
+			Checksums:        checksums,
+			RawLength:        types.MustInt64ToUint64(bs.rawBatchSize),
+			CompressedLength: types.MustInt64ToUint64(int64(compressedLength)),
+			PayloadChecksum:  bs.localBatchInfo.Checksum,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	batchData := make([][]byte, 0, len(chunks)+1)
+	batchData = append(batchData, header)
+	for i, chunk := range chunks {
+		batchData = append(batchData, executortypes.MarshalBatchDataChunk(
+			types.MustInt64ToUint64(bs.localBatchInfo.Start),
+			types.MustInt64ToUint64(bs.localBatchInfo.End),
+			types.MustInt64ToUint64(int64(i)),
+			types.MustInt64ToUint64(int64(len(checksums))),
+			chunk,
+		))
+	}
+	return batchData, nil
 }
 
 // finalize batch and create batch messages
@@ -156,7 +268,9 @@ func (bs *BatchSubmitter) finalizeBatch(ctx context.Context, blockHeight int64)
 	if err != nil {
 		return errors.Wrap(err, "failed to query raw commit")
 	}
-	_, err = bs.batchWriter.Write(prependLength(rawCommit))
+	n, err := bs.batchWriter.Write(prependLength(rawCommit))
+	bs.metrics.BytesWritten.Add(float64(n))
+	bs.rawBatchSize += int64(n)
 	if err != nil {
 		return errors.Wrap(err, "failed to write raw commit")
 	}
@@ -170,68 +284,50 @@ func (bs *BatchSubmitter) finalizeBatch(ctx context.Context, blockHeight int64)
 	}
 	bs.localBatchInfo.BatchFileSize = fileSize
 
-	batchBuffer := make([]byte, bs.batchCfg.MaxChunkSize)
-	checksums := make([][]byte, 0)
-
-	// TODO: improve this logic to avoid hold all the batch data in memory
-	chunks := make([][]byte, 0)
-	for offset := int64(0); ; {
-		readLength, err := bs.batchFile.ReadAt(batchBuffer, offset)
-		if err != nil && err != io.EOF {
-			return err
-		} else if readLength == 0 {
-			break
-		}
-
-		// trim the buffer to the actual read length
-		chunk := bytes.Clone(batchBuffer[:readLength])
-		chunks = append(chunks, chunk)
-
-		checksum := executortypes.GetChecksumFromChunk(chunk)
-		checksums = append(checksums, checksum[:])
-		if int64(readLength) < bs.batchCfg.MaxChunkSize {
-			break
-		}
-		offset += int64(readLength)
+	chunks, checksums, err := bs.finalizeBatchFile()
+	if err != nil {
+		return err
 	}
 
-	headerData := executortypes.MarshalBatchDataHeader(
-		types.MustInt64ToUint64(bs.localBatchInfo.Start),
-		types.MustInt64ToUint64(bs.localBatchInfo.End),
-		checksums,
-	)
-
-	msg, sender, err := bs.da.CreateBatchMsg(headerData)
+	chunks, checksums, err = bs.fitBatchGas(ctx, chunks, checksums)
 	if err != nil {
 		return err
-	} else if msg != nil {
-		bs.processedMsgs = append(bs.processedMsgs, btypes.ProcessedMsgs{
-			Sender:    sender,
-			Msgs:      []sdk.Msg{msg},
-			Timestamp: time.Now().UnixNano(),
-			Save:      true,
-		})
+	}
+	batchData, err := bs.marshalBatchData(chunks, checksums)
+	if err != nil {
+		return err
+	}
+	if bs.archive != nil {
+		bs.archive.enqueue(types.MustInt64ToUint64(bs.localBatchInfo.Start), types.MustInt64ToUint64(bs.localBatchInfo.End), batchData)
 	}
 
-	for i, chunk := range chunks {
-		chunkData := executortypes.MarshalBatchDataChunk(
-			types.MustInt64ToUint64(bs.localBatchInfo.Start),
-			types.MustInt64ToUint64(bs.localBatchInfo.End),
-			types.MustInt64ToUint64(int64(i)),
-			types.MustInt64ToUint64(int64(len(checksums))),
-			chunk,
-		)
-		msg, sender, err := bs.da.CreateBatchMsg(chunkData)
-		if err != nil {
-			return err
-		} else if msg != nil {
-			bs.processedMsgs = append(bs.processedMsgs, btypes.ProcessedMsgs{
-				Sender:    sender,
-				Msgs:      []sdk.Msg{msg},
-				Timestamp: time.Now().UnixNano(),
-				Save:      true,
-			})
+	processedMsgs, err := createBatchMsgs(bs.da, batchData, true)
+	if err != nil {
+		return err
+	}
+	bs.processedMsgs = append(bs.processedMsgs, processedMsgs...)
+	bs.finalizeSecondaryBatch(batchData)
+
+	if len(bs.processedMsgs) != 0 {
+		state := executortypes.BatchChunkState{
+			Start:     types.MustInt64ToUint64(bs.localBatchInfo.Start),
+			End:       types.MustInt64ToUint64(bs.localBatchInfo.End),
+			TraceIDs:  make([]string, 0, len(bs.processedMsgs)),
+			Confirmed: make([]bool, len(bs.processedMsgs)),
+			TxHashes:  make([]string, len(bs.processedMsgs)),
+			Size:      bs.localBatchInfo.BatchFileSize,
+		}
+		for _, processedMsgs := range bs.processedMsgs {
+			state.TraceIDs = append(state.TraceIDs, processedMsgs.TraceID)
 		}
+		// the sizes are recorded if the msgs are the header and the chunks in order
+		if len(bs.processedMsgs) == len(batchData) {
+			state.ChunkSizes = make([]int64, 0, len(batchData))
+			for _, data := range batchData {
+				state.ChunkSizes = append(state.ChunkSizes, int64(len(data)))
+			}
+		}
+		bs.finalizedChunkState = &state
 	}
 
 	bs.logger.Info("finalize batch",
@@ -252,27 +348,62 @@ func (bs *BatchSubmitter) checkBatch(ctx context.Context, blockHeight int64, lat
 	}
 
 	bs.localBatchInfo.BatchFileSize = fileSize
-	// if the block time is after the last submission time + submission interval * 2/3
-	// or the block time is after the last submission time + max submission time
-	// or the batch file size is greater than (max chunks - 1) * max chunk size
-	// then finalize the batch
-	if (blockHeight == latestHeight && blockTime.After(bs.localBatchInfo.LastSubmissionTime.Add(bs.bridgeInfo.BridgeConfig.SubmissionInterval*2/3))) ||
-		(blockHeight == latestHeight && blockTime.After(bs.localBatchInfo.LastSubmissionTime.Add(time.Duration(bs.batchCfg.MaxSubmissionTime)*time.Second))) ||
-		fileSize > (bs.batchCfg.MaxChunks-1)*bs.batchCfg.MaxChunkSize {
+	if trigger := bs.submissionTrigger(blockHeight, latestHeight, blockTime, fileSize); trigger != "" {
+		bs.logger.Info("batch submission triggered",
+			zap.String("trigger", trigger),
+			zap.Int64("batch_start", bs.localBatchInfo.Start),
+			zap.Int64("batch_end", blockHeight),
+			zap.Int64("batch_file_size", fileSize),
+		)
+		bs.metrics.SubmissionTriggers.WithLabelValues(trigger).Inc()
 
 		// finalize the batch
-		bs.LastBatchEndBlockNumber = blockHeight
+		prevBatchInfo := *bs.localBatchInfo
 		bs.localBatchInfo.LastSubmissionTime = blockTime
 		bs.localBatchInfo.End = blockHeight
 
 		err := bs.finalizeBatch(ctx, blockHeight)
-		if err != nil {
+		if errors.Is(err, errBatchChecksumMismatch) {
+			// the batch file is corrupted, so rebuild the batch from its start height
+			*bs.localBatchInfo = prevBatchInfo
+			bs.logger.Error("rebuild the corrupted batch", zap.Int64("batch_start", bs.localBatchInfo.Start))
+			if rewindErr := bs.rewindBatch(); rewindErr != nil {
+				return errors.Wrap(rewindErr, "failed to rewind batch")
+			}
+			return err
+		} else if err != nil {
 			return errors.Wrap(err, "failed to finalize batch")
 		}
+		bs.LastBatchEndBlockNumber = blockHeight
 	}
 	return nil
 }
 
+// submissionTrigger returns the trigger which finalizes the batch after the block, or empty if no trigger fires.
+// The batch always contains the block, so it is never empty.
+func (bs *BatchSubmitter) submissionTrigger(blockHeight int64, latestHeight int64, blockTime time.Time, fileSize int64) string {
+	switch {
+	// the last batch of the current batch info ends at the l2 block number of the next batch info
+	case bs.isBatchInfoBoundary(blockHeight):
+		return "batch_info_update"
+	// the batch file must fit in the max chunks
+	case fileSize > (bs.batchCfg.MaxChunks-1)*bs.batchCfg.MaxChunkSize:
+		return "max_chunks"
+	case bs.batchCfg.MaxBatchBytes > 0 && fileSize >= bs.batchCfg.MaxBatchBytes:
+		return "max_batch_bytes"
+	case bs.batchCfg.MaxBatchBlocks > 0 && blockHeight-bs.localBatchInfo.Start+1 >= bs.batchCfg.MaxBatchBlocks:
+		return "max_batch_blocks"
+	// the time triggers are checked only at the latest height, not to submit a batch per block while syncing
+	case blockHeight != latestHeight:
+		return ""
+	case blockTime.After(bs.localBatchInfo.LastSubmissionTime.Add(bs.BridgeInfo().BridgeConfig.SubmissionInterval * 2 / 3)):
+		return "submission_interval"
+	case blockTime.After(bs.localBatchInfo.LastSubmissionTime.Add(time.Duration(bs.batchCfg.MaxSubmissionTime) * time.Second)):
+		return "max_submission_time"
+	}
+	return ""
+}
+
 func (bs *BatchSubmitter) batchFileSize(flush bool) (int64, error) {
 	if bs.batchFile == nil {
 		return 0, errors.New("batch file is not initialized")
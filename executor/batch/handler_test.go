@@ -0,0 +1,110 @@
+package batch
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+func Test_SubmissionTrigger(t *testing.T) {
+	lastSubmission := time.Unix(1000, 0)
+	bs := &BatchSubmitter{
+		bridgeInfoMu: &sync.RWMutex{},
+		bridgeInfo: &ophosttypes.QueryBridgeResponse{
+			BridgeConfig: ophosttypes.BridgeConfig{SubmissionInterval: 300 * time.Second},
+		},
+		localBatchInfo: &executortypes.LocalBatchInfo{
+			Start:              101,
+			LastSubmissionTime: lastSubmission,
+		},
+		batchInfoMu: &sync.Mutex{},
+		batchInfos:  []ophosttypes.BatchInfoWithOutput{{}},
+	}
+
+	cfg := executortypes.BatchConfig{
+		MaxChunks:         10,
+		MaxChunkSize:      100,
+		MaxSubmissionTime: 3600,
+	}
+	before := lastSubmission.Add(time.Second)
+
+	cases := []struct {
+		name         string
+		cfg          func(executortypes.BatchConfig) executortypes.BatchConfig
+		blockHeight  int64
+		latestHeight int64
+		blockTime    time.Time
+		fileSize     int64
+		expected     string
+	}{
+		{"no trigger", nil, 110, 110, before, 100, ""},
+		{"max chunks", nil, 110, 200, before, 901, "max_chunks"},
+		{"max batch bytes", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBytes = 500
+			return c
+		}, 110, 200, before, 500, "max_batch_bytes"},
+		{"max batch bytes not reached", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBytes = 500
+			return c
+		}, 110, 200, before, 499, ""},
+		{"max batch blocks", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBlocks = 10
+			return c
+		}, 110, 200, before, 100, "max_batch_blocks"},
+		{"max batch blocks not reached", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBlocks = 10
+			return c
+		}, 109, 200, before, 100, ""},
+		{"single block batch", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBlocks = 1
+			return c
+		}, 101, 200, before, 100, "max_batch_blocks"},
+		{"submission interval", nil, 110, 110, lastSubmission.Add(201 * time.Second), 100, "submission_interval"},
+		{"submission interval while syncing", nil, 110, 200, lastSubmission.Add(201 * time.Second), 100, ""},
+		{"max submission time", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxSubmissionTime = 60
+			return c
+		}, 110, 110, lastSubmission.Add(61 * time.Second), 100, "max_submission_time"},
+		{"combined size triggers", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBytes = 500
+			c.MaxBatchBlocks = 10
+			return c
+		}, 110, 110, lastSubmission.Add(201 * time.Second), 600, "max_batch_bytes"},
+		{"combined blocks and time triggers", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBytes = 500
+			c.MaxBatchBlocks = 10
+			return c
+		}, 110, 110, lastSubmission.Add(201 * time.Second), 100, "max_batch_blocks"},
+		{"combined time trigger", func(c executortypes.BatchConfig) executortypes.BatchConfig {
+			c.MaxBatchBytes = 500
+			c.MaxBatchBlocks = 100
+			return c
+		}, 110, 110, lastSubmission.Add(201 * time.Second), 100, "submission_interval"},
+	}
+	for _, tc := range cases {
+		bs.batchCfg = cfg
+		if tc.cfg != nil {
+			bs.batchCfg = tc.cfg(cfg)
+		}
+		require.Equal(t, tc.expected, bs.submissionTrigger(tc.blockHeight, tc.latestHeight, tc.blockTime, tc.fileSize), tc.name)
+	}
+}
+
+func Test_SubmissionTriggerBatchInfoBoundary(t *testing.T) {
+	bs := &BatchSubmitter{
+		batchCfg:       executortypes.BatchConfig{MaxChunks: 10, MaxChunkSize: 100},
+		localBatchInfo: &executortypes.LocalBatchInfo{Start: 101},
+		batchInfoMu:    &sync.Mutex{},
+		batchInfos:     []ophosttypes.BatchInfoWithOutput{{}, {Output: ophosttypes.Output{L2BlockNumber: 110}}},
+	}
+
+	require.Equal(t, "", bs.submissionTrigger(109, 200, time.Time{}, 100))
+	// the batch info boundary precedes the other triggers
+	require.Equal(t, "batch_info_update", bs.submissionTrigger(110, 200, time.Time{}, 1000))
+}
@@ -0,0 +1,61 @@
+package batch
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/initia-labs/opinit-bots/node/metrics"
+)
+
+type batchMetrics struct {
+	BytesWritten       prometheus.Counter
+	SubmissionTriggers *prometheus.CounterVec
+	// SecondarySubmissions counts the batches confirmed or failed on the secondary DA.
+	SecondarySubmissions *prometheus.CounterVec
+	// PendingBytes is the compressed size of the finalized batches which are not confirmed on the DA yet.
+	PendingBytes prometheus.Gauge
+	// Paused is 1 while the block processing is paused by the pending batches.
+	Paused prometheus.Gauge
+	// Archives counts the finalized batches archived or failed to be archived.
+	Archives *prometheus.CounterVec
+}
+
+func newBatchMetrics(bridge string) *batchMetrics {
+	return &batchMetrics{
+		BytesWritten: metrics.Register(bridge, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "batch",
+			Name:      "bytes_written_total",
+			Help:      "The number of uncompressed bytes written to the batch file.",
+		})),
+		SubmissionTriggers: metrics.Register(bridge, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "batch",
+			Name:      "submission_triggers_total",
+			Help:      "The number of the finalized batches by the trigger which fired.",
+		}, []string{"trigger"})),
+		SecondarySubmissions: metrics.Register(bridge, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "batch",
+			Name:      "secondary_submissions_total",
+			Help:      "The number of the batches submitted to the secondary DA by the result, confirmed or failed.",
+		}, []string{"result"})),
+		PendingBytes: metrics.Register(bridge, prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "batch",
+			Name:      "pending_bytes",
+			Help:      "The compressed size of the finalized batches which are not confirmed on the DA yet.",
+		})),
+		Paused: metrics.Register(bridge, prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "batch",
+			Name:      "paused",
+			Help:      "1 while the block processing is paused until the pending batches are confirmed on the DA.",
+		})),
+		Archives: metrics.Register(bridge, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "batch",
+			Name:      "archives_total",
+			Help:      "The number of the finalized batches copied to the archive by the result, archived or failed.",
+		}, []string{"result"})),
+	}
+}
@@ -23,8 +23,15 @@ func NewNoopDA() *NoopDA {
 func (n NoopDA) Start(_ context.Context)                          {}
 func (n NoopDA) HasKey() bool                                     { return false }
 func (n NoopDA) CreateBatchMsg(_ []byte) (sdk.Msg, string, error) { return nil, "", nil }
-func (n NoopDA) BroadcastMsgs(nil btypes.ProcessedMsgs)           {}
+func (n NoopDA) BroadcastMsgs(_ btypes.ProcessedMsgs) error       { return nil }
 func (n NoopDA) ProcessedMsgsToRawKV(_ []btypes.ProcessedMsgs, _ bool) ([]types.RawKV, error) {
 	return nil, nil
 }
 func (n NoopDA) GetNodeStatus() (nodetypes.Status, error) { return nodetypes.Status{}, nil }
+func (n NoopDA) UnconfirmedTraceIDs() (map[string]struct{}, error) {
+	return nil, nil
+}
+func (n NoopDA) DeadLetterTraceIDs() (map[string]struct{}, error) {
+	return nil, nil
+}
+func (n NoopDA) RegisterTxConfirmedHandler(_ nodetypes.TxConfirmedHandlerFn) {}
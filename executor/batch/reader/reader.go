@@ -0,0 +1,227 @@
+package reader
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/gogoproto/proto"
+
+	"github.com/cosmos/cosmos-sdk/client"
+
+	"github.com/initia-labs/opinit-bots/executor/batch"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// RawBlock is a l2 block decoded from the batch. Bytes are the block bytes written to the batch,
+// whose oracle data is emptied.
+type RawBlock struct {
+	Height int64
+	Bytes  []byte
+	// Events are the block events of the allowed types, which are nil if the batch has the blocks only.
+	Events *abcitypes.ResponseFinalizeBlock
+}
+
+// BlockQuerier queries the raw blocks and commits of the l2 chain.
+type BlockQuerier interface {
+	QueryBlockBulk(ctx context.Context, start int64, end int64) ([][]byte, error)
+	QueryRawCommit(ctx context.Context, height int64) ([]byte, error)
+}
+
+// DecodeBatch validates the chunks against the checksums and the commitments of the header, decompresses the batch
+// with the compression of the header and splits it into the blocks, with their events if the version
+// of the header has them. The chunks are the batch data submitted to the DA, which can be given in any order.
+func DecodeBatch(header executortypes.BatchDataHeader, chunks [][]byte) ([]RawBlock, error) {
+	blocks, _, err := decodeBatch(header, chunks)
+	return blocks, err
+}
+
+// decodeBatch decodes the blocks and the raw commit of the last block from the batch.
+func decodeBatch(header executortypes.BatchDataHeader, chunks [][]byte) ([]RawBlock, []byte, error) {
+	batchChunks := make([]executortypes.BatchDataChunk, 0, len(chunks))
+	for _, data := range chunks {
+		chunk, err := executortypes.UnmarshalBatchDataChunk(data)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to unmarshal batch chunk")
+		}
+		batchChunks = append(batchChunks, chunk)
+	}
+
+	compressed, err := executortypes.ReassembleBatchChunks(header, batchChunks)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to reassemble batch chunks")
+	}
+
+	reader, err := batch.NewBatchReader(header.Compression, bytes.NewReader(compressed))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	counter := &countingReader{r: reader}
+	entries, err := splitLengthPrefixed(counter)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decompress batch")
+	} else if header.Committed() && counter.n != header.RawLength {
+		return nil, nil, fmt.Errorf("raw length mismatch: %d, expected: %d", counter.n, header.RawLength)
+	}
+	// the batch ends with the raw commit of the last block
+	if len(entries) < 2 {
+		return nil, nil, fmt.Errorf("batch has no blocks: %d entries", len(entries))
+	}
+	rawCommit := entries[len(entries)-1]
+	entries = entries[:len(entries)-1]
+
+	var blockEvents [][]byte
+	switch header.Version {
+	case executortypes.BatchVersionBlocks:
+	case executortypes.BatchVersionBlockEvents:
+		// each block is followed by its events
+		if len(entries)%2 != 0 {
+			return nil, nil, fmt.Errorf("block without events: %d entries", len(entries))
+		}
+		blockEntries := make([][]byte, 0, len(entries)/2)
+		blockEvents = make([][]byte, 0, len(entries)/2)
+		for i := 0; i < len(entries); i += 2 {
+			blockEntries = append(blockEntries, entries[i])
+			blockEvents = append(blockEvents, entries[i+1])
+		}
+		entries = blockEntries
+	default:
+		return nil, nil, fmt.Errorf("unknown batch version: %d", header.Version)
+	}
+
+	start, err := types.SafeUint64ToInt64(header.Start)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := types.SafeUint64ToInt64(header.End)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blocks := make([]RawBlock, 0, len(entries))
+	for i, blockBytes := range entries {
+		pbb := new(cmtproto.Block)
+		if err := proto.Unmarshal(blockBytes, pbb); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal block: %d", i)
+		}
+
+		expected := start + int64(i)
+		if pbb.Header.Height != expected {
+			return nil, nil, fmt.Errorf("non-contiguous block height: %d, expected: %d", pbb.Header.Height, expected)
+		}
+		block := RawBlock{Height: pbb.Header.Height, Bytes: blockBytes}
+		if blockEvents != nil {
+			block.Events = new(abcitypes.ResponseFinalizeBlock)
+			if err := proto.Unmarshal(blockEvents[i], block.Events); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to unmarshal block events: %d", block.Height)
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	if last := blocks[len(blocks)-1].Height; last != end {
+		return nil, nil, fmt.Errorf("invalid last block height: %d, expected: %d", last, end)
+	}
+
+	commit := new(cmtproto.Commit)
+	if err := proto.Unmarshal(rawCommit, commit); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal commit")
+	} else if commit.Height != end {
+		return nil, nil, fmt.Errorf("invalid commit height: %d, expected: %d", commit.Height, end)
+	}
+	return blocks, rawCommit, nil
+}
+
+// countingReader counts the bytes read from the decompressed batch.
+type countingReader struct {
+	r io.Reader
+	n uint64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+// splitLengthPrefixed splits the decompressed batch into the length prefixed entries.
+func splitLengthPrefixed(r io.Reader) ([][]byte, error) {
+	entries := make([][]byte, 0)
+	lengthBytes := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(r, lengthBytes)
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		length, err := types.SafeUint64ToInt64(binary.LittleEndian.Uint64(lengthBytes))
+		if err != nil {
+			return nil, err
+		}
+
+		// copy instead of allocating the length up front, as the length is not trusted
+		var buf bytes.Buffer
+		n, err := io.CopyN(&buf, r, length)
+		if err != nil {
+			return nil, errors.Wrapf(err, "truncated entry: %d of %d bytes", n, length)
+		}
+		entries = append(entries, buf.Bytes())
+	}
+}
+
+// VerifyBatch decodes the batch and compares the blocks and the commit with the ones queried
+// from the l2 node byte by byte. The oracle data of the queried blocks is emptied with the
+// tx config and the msgs decoder as the batch submitter does.
+func VerifyBatch(
+	ctx context.Context,
+	querier BlockQuerier,
+	txConfig client.TxConfig,
+	decodeTxMsgs batch.TxMsgsDecoder,
+	header executortypes.BatchDataHeader,
+	chunks [][]byte,
+) error {
+	blocks, rawCommit, err := decodeBatch(header, chunks)
+	if err != nil {
+		return err
+	}
+
+	start, end := blocks[0].Height, blocks[len(blocks)-1].Height
+	nodeBlocks, err := querier.QueryBlockBulk(ctx, start, end)
+	if err != nil {
+		return errors.Wrap(err, "failed to query blocks")
+	} else if len(nodeBlocks) != len(blocks) {
+		return fmt.Errorf("invalid number of queried blocks: %d, expected: %d", len(nodeBlocks), len(blocks))
+	}
+
+	for i, blockBytes := range nodeBlocks {
+		pbb := new(cmtproto.Block)
+		if err := proto.Unmarshal(blockBytes, pbb); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal queried block: %d", blocks[i].Height)
+		}
+		expected, err := batch.EmptyOracleData(txConfig, decodeTxMsgs, pbb)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(expected, blocks[i].Bytes) {
+			return fmt.Errorf("block mismatch at height: %d", blocks[i].Height)
+		}
+	}
+
+	nodeCommit, err := querier.QueryRawCommit(ctx, end)
+	if err != nil {
+		return errors.Wrap(err, "failed to query commit")
+	} else if !bytes.Equal(nodeCommit, rawCommit) {
+		return fmt.Errorf("commit mismatch at height: %d", end)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package reader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/gogoproto/proto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/provider/child"
+	"github.com/initia-labs/opinit-bots/txutils"
+)
+
+type mockQuerier struct {
+	blocks map[int64][]byte
+	commit []byte
+}
+
+func (m mockQuerier) QueryBlockBulk(_ context.Context, start int64, end int64) ([][]byte, error) {
+	blocks := make([][]byte, 0)
+	for height := start; height <= end; height++ {
+		blocks = append(blocks, m.blocks[height])
+	}
+	return blocks, nil
+}
+
+func (m mockQuerier) QueryRawCommit(_ context.Context, _ int64) ([]byte, error) {
+	return m.commit, nil
+}
+
+func testBlocks(t *testing.T, start, end int64) map[int64][]byte {
+	blocks := make(map[int64][]byte)
+	for height := start; height <= end; height++ {
+		block := &cmtproto.Block{
+			Header: cmtproto.Header{ChainID: "l2-1", Height: height},
+			Data:   cmtproto.Data{Txs: [][]byte{[]byte(fmt.Sprintf("tx%d", height))}},
+		}
+		bz, err := proto.Marshal(block)
+		require.NoError(t, err)
+		blocks[height] = bz
+	}
+	return blocks
+}
+
+func testCommit(t *testing.T, height int64) []byte {
+	bz, err := proto.Marshal(&cmtproto.Commit{Height: height})
+	require.NoError(t, err)
+	return bz
+}
+
+// writeBatch writes the entries as the batch submitter does and splits the compressed
+// batch into the header and the chunks submitted to the DA.
+func writeBatch(t *testing.T, compression executortypes.BatchCompression, version executortypes.BatchVersion, start, end uint64, entries [][]byte, chunkSize int) (executortypes.BatchDataHeader, [][]byte) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	var err error
+	switch compression {
+	case executortypes.BatchCompressionGzip:
+		writer = gzip.NewWriter(&buf)
+	case executortypes.BatchCompressionZstd:
+		writer, err = zstd.NewWriter(&buf)
+		require.NoError(t, err)
+	}
+	for _, entry := range entries {
+		_, err := writer.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(entry))))
+		require.NoError(t, err)
+		_, err = writer.Write(entry)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	compressed := buf.Bytes()
+	checksums := make([][]byte, 0)
+	chunks := make([][]byte, 0)
+	for offset := 0; offset < len(compressed); offset += chunkSize {
+		chunk := compressed[offset:min(offset+chunkSize, len(compressed))]
+		checksum := executortypes.GetChecksumFromChunk(chunk)
+		checksums = append(checksums, checksum[:])
+		chunks = append(chunks, chunk)
+	}
+	for i, chunk := range chunks {
+		chunks[i] = executortypes.MarshalBatchDataChunk(start, end, uint64(i), uint64(len(checksums)), chunk)
+	}
+
+	rawLength := 0
+	for _, entry := range entries {
+		rawLength += 8 + len(entry)
+	}
+	payloadChecksum := sha256.Sum256(compressed)
+	headerData, err := executortypes.MarshalCommittedBatchDataHeader(executortypes.BatchDataHeader{
+		Start:            start,
+		End:              end,
+		Compression:      compression,
+		Version:          version,
+		Checksums:        checksums,
+		RawLength:        uint64(rawLength),
+		CompressedLength: uint64(len(compressed)),
+		PayloadChecksum:  payloadChecksum[:],
+	})
+	require.NoError(t, err)
+	header, err := executortypes.UnmarshalBatchDataHeader(headerData)
+	require.NoError(t, err)
+	return header, chunks
+}
+
+func Test_DecodeBatch(t *testing.T) {
+	blocks := testBlocks(t, 11, 20)
+	entries := make([][]byte, 0)
+	for height := int64(11); height <= 20; height++ {
+		entries = append(entries, blocks[height])
+	}
+	entries = append(entries, testCommit(t, 20))
+
+	for _, compression := range []executortypes.BatchCompression{executortypes.BatchCompressionGzip, executortypes.BatchCompressionZstd} {
+		header, chunks := writeBatch(t, compression, executortypes.BatchVersionBlocks, 11, 20, entries, 32)
+		require.Greater(t, len(chunks), 1)
+
+		// the chunks can be given in any order
+		chunks[0], chunks[1] = chunks[1], chunks[0]
+		decoded, err := DecodeBatch(header, chunks)
+		require.NoError(t, err)
+		require.Len(t, decoded, 10)
+		for i, block := range decoded {
+			require.Equal(t, int64(11+i), block.Height)
+			require.Equal(t, blocks[block.Height], block.Bytes)
+		}
+
+		// corrupted chunk
+		corrupted := bytes.Clone(chunks[0])
+		corrupted[len(corrupted)-1] ^= 0xff
+		_, err = DecodeBatch(header, append([][]byte{corrupted}, chunks[1:]...))
+		require.ErrorContains(t, err, "checksum mismatch")
+	}
+
+	// the header with the different commitments of the payload
+	header, chunks := writeBatch(t, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlocks, 11, 20, entries, 32)
+	tampered := header
+	tampered.RawLength--
+	_, err := DecodeBatch(tampered, chunks)
+	require.ErrorContains(t, err, "raw length mismatch")
+	tampered = header
+	tampered.PayloadChecksum = make([]byte, 32)
+	_, err = DecodeBatch(tampered, chunks)
+	require.ErrorContains(t, err, "payload checksum mismatch")
+
+	// the payload substituted with another batch of the same range fails the verification
+	otherHeader, otherChunks := writeBatch(t, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlocks, 11, 20, append(append([][]byte{}, entries[:9]...), blocks[20][:len(blocks[20])-1], entries[10]), 32)
+	tampered = otherHeader
+	tampered.RawLength = header.RawLength
+	tampered.PayloadChecksum = header.PayloadChecksum
+	_, err = DecodeBatch(tampered, otherChunks)
+	require.ErrorContains(t, err, "payload checksum mismatch")
+
+	// the legacy header without the commitments still decodes
+	legacy := header
+	legacy.RawLength, legacy.CompressedLength, legacy.PayloadChecksum = 0, 0, nil
+	decoded, err := DecodeBatch(legacy, chunks)
+	require.NoError(t, err)
+	require.Len(t, decoded, 10)
+
+	// missing block in the range
+	gapped := append(append([][]byte{}, entries[:3]...), entries[4:]...)
+	header, chunks = writeBatch(t, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlocks, 11, 20, gapped, 1024)
+	_, err = DecodeBatch(header, chunks)
+	require.ErrorContains(t, err, "non-contiguous block height")
+
+	// commit of another height
+	wrongCommit := append(append([][]byte{}, entries[:10]...), testCommit(t, 19))
+	header, chunks = writeBatch(t, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlocks, 11, 20, wrongCommit, 1024)
+	_, err = DecodeBatch(header, chunks)
+	require.ErrorContains(t, err, "invalid commit height")
+}
+
+func Test_DecodeBatchBlockEvents(t *testing.T) {
+	blocks := testBlocks(t, 1, 3)
+	entries := make([][]byte, 0)
+	for height := int64(1); height <= 3; height++ {
+		events := &abcitypes.ResponseFinalizeBlock{
+			Events:    []abcitypes.Event{{Type: "oracle", Attributes: []abcitypes.EventAttribute{{Key: "height", Value: fmt.Sprint(height)}}}},
+			TxResults: []*abcitypes.ExecTxResult{{}},
+		}
+		bz, err := proto.Marshal(events)
+		require.NoError(t, err)
+		entries = append(entries, blocks[height], bz)
+	}
+	entries = append(entries, testCommit(t, 3))
+
+	header, chunks := writeBatch(t, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlockEvents, 1, 3, entries, 64)
+	decoded, err := DecodeBatch(header, chunks)
+	require.NoError(t, err)
+	require.Len(t, decoded, 3)
+	for i, block := range decoded {
+		require.Equal(t, int64(1+i), block.Height)
+		require.Equal(t, blocks[block.Height], block.Bytes)
+		require.NotNil(t, block.Events)
+		require.Equal(t, "oracle", block.Events.Events[0].Type)
+		require.Equal(t, fmt.Sprint(block.Height), block.Events.Events[0].Attributes[0].Value)
+		require.Len(t, block.Events.TxResults, 1)
+	}
+
+	// the blocks only batch has no events
+	header, chunks = writeBatch(t, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlocks, 1, 3,
+		[][]byte{blocks[1], blocks[2], blocks[3], testCommit(t, 3)}, 64)
+	decoded, err = DecodeBatch(header, chunks)
+	require.NoError(t, err)
+	for _, block := range decoded {
+		require.Nil(t, block.Events)
+	}
+
+	// the events of the last block are missing
+	header, chunks = writeBatch(t, executortypes.BatchCompressionZstd, executortypes.BatchVersionBlockEvents, 1, 3,
+		append(append([][]byte{}, entries[:5]...), testCommit(t, 3)), 64)
+	_, err = DecodeBatch(header, chunks)
+	require.ErrorContains(t, err, "block without events")
+}
+
+func Test_VerifyBatch(t *testing.T) {
+	cdc, txConfig, err := child.GetCodec("init")
+	require.NoError(t, err)
+	decodeTxMsgs := func(txBytes []byte) ([]sdk.Msg, error) {
+		msgs, _, err := txutils.DecodeTxMsgs(cdc, txConfig, txBytes, false)
+		return msgs, err
+	}
+
+	blocks := testBlocks(t, 1, 5)
+	entries := make([][]byte, 0)
+	for height := int64(1); height <= 5; height++ {
+		entries = append(entries, blocks[height])
+	}
+	commit := testCommit(t, 5)
+	entries = append(entries, commit)
+	header, chunks := writeBatch(t, executortypes.BatchCompressionGzip, executortypes.BatchVersionBlocks, 1, 5, entries, 64)
+
+	querier := mockQuerier{blocks: blocks, commit: commit}
+	require.NoError(t, VerifyBatch(context.Background(), querier, txConfig, decodeTxMsgs, header, chunks))
+
+	// the node has a different block
+	querier.blocks = testBlocks(t, 1, 5)
+	querier.blocks[3] = testBlocks(t, 4, 4)[4]
+	require.ErrorContains(t, VerifyBatch(context.Background(), querier, txConfig, decodeTxMsgs, header, chunks), "block mismatch at height: 3")
+}
@@ -0,0 +1,118 @@
+package batch
+
+import (
+	"context"
+	"slices"
+
+	"go.uber.org/zap"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// SecondaryDA returns the DA node which receives the batches in addition to the primary DA node
+// if the dual submission is enabled, or nil.
+func (bs *BatchSubmitter) SecondaryDA() executortypes.DANode {
+	return bs.secondary
+}
+
+// finalizeSecondaryBatch creates the msgs of the finalized batch for the secondary DA node.
+// The failures of the secondary DA node are counted without halting the batch submission.
+func (bs *BatchSubmitter) finalizeSecondaryBatch(batchData [][]byte) {
+	if bs.secondary == nil {
+		return
+	}
+
+	processedMsgs, err := createBatchMsgs(bs.secondary, batchData, false)
+	if err != nil {
+		bs.logger.Warn("failed to create batch msgs of the secondary DA",
+			zap.Int64("batch_start", bs.localBatchInfo.Start),
+			zap.Int64("batch_end", bs.localBatchInfo.End),
+			zap.String("error", err.Error()),
+		)
+		bs.metrics.SecondarySubmissions.WithLabelValues("failed").Inc()
+		return
+	}
+	bs.secondaryMsgs = append(bs.secondaryMsgs, processedMsgs...)
+}
+
+// submitSecondaryBatch tracks and broadcasts the msgs of the secondary DA node. The msgs are not saved,
+// so the secondary broadcaster discards them on failures instead of retrying.
+func (bs *BatchSubmitter) submitSecondaryBatch() error {
+	if bs.secondary == nil || len(bs.secondaryMsgs) == 0 {
+		return nil
+	}
+
+	state := &executortypes.BatchChunkState{
+		Start:     types.MustInt64ToUint64(bs.localBatchInfo.Start),
+		End:       types.MustInt64ToUint64(bs.localBatchInfo.End),
+		TraceIDs:  make([]string, 0, len(bs.secondaryMsgs)),
+		Confirmed: make([]bool, len(bs.secondaryMsgs)),
+	}
+	for _, processedMsgs := range bs.secondaryMsgs {
+		state.TraceIDs = append(state.TraceIDs, processedMsgs.TraceID)
+	}
+
+	bs.secondaryMu.Lock()
+	bs.secondaryStates = append(bs.secondaryStates, state)
+	bs.secondaryMu.Unlock()
+
+	for _, processedMsgs := range bs.secondaryMsgs {
+		err := bs.secondary.BroadcastMsgs(processedMsgs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secondaryTxConfirmedHandler tracks the confirmations of the secondary DA node. The DA txs of
+// the account are included in order, so the chunks broadcasted before the confirmed one and still
+// not confirmed are discarded by the secondary broadcaster, and their batches are counted as failed.
+func (bs *BatchSubmitter) secondaryTxConfirmedHandler(_ context.Context, args nodetypes.TxConfirmedArgs) error {
+	bs.secondaryMu.Lock()
+	defer bs.secondaryMu.Unlock()
+
+	for i, state := range bs.secondaryStates {
+		index := slices.Index(state.TraceIDs, args.TraceID)
+		if index == -1 {
+			continue
+		}
+		state.Confirmed[index] = true
+
+		failed := i
+		if slices.Contains(state.Confirmed[:index], false) {
+			failed = i + 1
+		}
+		for _, dropped := range bs.secondaryStates[:failed] {
+			bs.logger.Warn("batch is not submitted to the secondary DA",
+				zap.Uint64("batch_start", dropped.Start),
+				zap.Uint64("batch_end", dropped.End),
+				zap.Int("confirmed", dropped.ConfirmedChunks()),
+				zap.Int("total", len(dropped.TraceIDs)),
+			)
+			bs.metrics.SecondarySubmissions.WithLabelValues("failed").Inc()
+		}
+		bs.secondaryStates = bs.secondaryStates[failed:]
+
+		if failed == i && state.IsSubmitted() {
+			bs.logger.Debug("batch submitted to the secondary DA",
+				zap.Uint64("batch_start", state.Start),
+				zap.Uint64("batch_end", state.End),
+			)
+			bs.metrics.SecondarySubmissions.WithLabelValues("confirmed").Inc()
+			bs.secondaryStates = bs.secondaryStates[1:]
+		}
+		return nil
+	}
+	return nil
+}
+
+// PendingSecondaryBatches returns the number of the batches which are not confirmed by the secondary DA node yet.
+func (bs *BatchSubmitter) PendingSecondaryBatches() int {
+	bs.secondaryMu.Lock()
+	defer bs.secondaryMu.Unlock()
+
+	return len(bs.secondaryStates)
+}
@@ -0,0 +1,135 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// lossyDA confirms the broadcasted msgs in order except the ones at the dropped broadcast indexes,
+// which are discarded as the broadcaster does with the unsaved msgs.
+type lossyDA struct {
+	NoopDA
+
+	handler    nodetypes.TxConfirmedHandlerFn
+	broadcasts int
+	drop       map[int]bool
+	createErr  error
+}
+
+func (m *lossyDA) RegisterTxConfirmedHandler(fn nodetypes.TxConfirmedHandlerFn) {
+	m.handler = fn
+}
+
+func (m *lossyDA) CreateBatchMsg(data []byte) (sdk.Msg, string, error) {
+	if m.createErr != nil {
+		return nil, "", m.createErr
+	}
+	return &ophosttypes.MsgRecordBatch{Submitter: "init1secondary", BridgeId: 1, BatchBytes: data}, "init1secondary", nil
+}
+
+func (m *lossyDA) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	index := m.broadcasts
+	m.broadcasts++
+	if m.drop[index] {
+		return nil
+	}
+	_ = m.handler(context.Background(), nodetypes.TxConfirmedArgs{TraceID: msgs.TraceID})
+	return nil
+}
+
+func Test_DualSubmit(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+
+	bs := &BatchSubmitter{
+		db:             db,
+		logger:         zap.NewNop(),
+		batchCfg:       executortypes.BatchConfig{DualSubmit: true},
+		chunkStatesMu:  &sync.Mutex{},
+		secondaryMu:    &sync.Mutex{},
+		history:        newBatchHistory(),
+		metrics:        newBatchMetrics(""),
+		localBatchInfo: &executortypes.LocalBatchInfo{},
+	}
+
+	// the secondary DA node is required
+	require.Error(t, bs.SetDANode(&dropFirstDA{submitted: make(map[string]bool)}, nil))
+
+	primary := &dropFirstDA{submitted: make(map[string]bool)}
+	secondary := &lossyDA{drop: map[int]bool{0: true}}
+	require.NoError(t, bs.SetDANode(primary, secondary))
+	require.Equal(t, secondary, bs.SecondaryDA())
+
+	submit := func(start, end int64, batchData ...[]byte) {
+		bs.localBatchInfo.Start, bs.localBatchInfo.End = start, end
+		bs.secondaryMsgs = bs.secondaryMsgs[:0]
+
+		state := executortypes.BatchChunkState{
+			Start:     uint64(start),
+			End:       uint64(end),
+			TraceIDs:  make([]string, 0, len(batchData)),
+			Confirmed: make([]bool, len(batchData)),
+		}
+		for _, data := range batchData {
+			state.TraceIDs = append(state.TraceIDs, "primary-"+string(data))
+		}
+		require.NoError(t, bs.saveChunkState(state))
+		bs.trackChunkState(state)
+
+		bs.finalizeSecondaryBatch(batchData)
+		for _, traceID := range state.TraceIDs {
+			primary.BroadcastMsgs(btypes.ProcessedMsgs{TraceID: traceID, Save: true})
+		}
+		bs.submitSecondaryBatch()
+	}
+
+	// the secondary drops the header of the first batch, so the batch fails on the secondary
+	// when its chunk is confirmed
+	submit(1, 10, []byte("header1"), []byte("chunk1"))
+	require.Equal(t, 0, bs.PendingSecondaryBatches())
+	require.Len(t, bs.PendingChunkStates(), 1)
+
+	// the second batch is confirmed on the secondary while the primary still drops it
+	submit(11, 20, []byte("header2"), []byte("chunk2"))
+	require.Equal(t, 0, bs.PendingSecondaryBatches())
+	require.Len(t, bs.PendingChunkStates(), 2)
+	require.Nil(t, bs.LastSubmittedBatch())
+
+	// the failures of creating the secondary msgs do not block the primary
+	secondary.createErr = errors.New("secondary DA unavailable")
+	submit(21, 30, []byte("header3"))
+	require.Equal(t, 0, bs.PendingSecondaryBatches())
+	require.Len(t, bs.PendingChunkStates(), 3)
+	secondary.createErr = nil
+
+	// the secondary drops the last chunk of the fourth batch, which stays pending
+	secondary.drop[secondary.broadcasts+1] = true
+	submit(31, 40, []byte("header4"), []byte("chunk4"))
+	require.Equal(t, 1, bs.PendingSecondaryBatches())
+
+	// the next confirmation on the secondary discards the pending batch
+	submit(41, 50, []byte("header5"))
+	require.Equal(t, 0, bs.PendingSecondaryBatches())
+
+	// only the primary confirmations advance the submission
+	for _, msgs := range primary.dropped {
+		msgs.Resubmissions++
+		primary.BroadcastMsgs(msgs)
+	}
+	require.Empty(t, bs.PendingChunkStates())
+	require.Equal(t, uint64(50), bs.LastSubmittedBatch().End)
+}
@@ -2,9 +2,11 @@ package batch
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 )
 
@@ -15,6 +17,9 @@ type Status struct {
 	BatchStartBlockNumber   int64                 `json:"batch_start_block_number"`
 	BatchEndBlockNumber     int64                 `json:"batch_end_block_number"`
 	LastBatchSubmissionTime time.Time             `json:"last_batch_submission_time"`
+	// PendingBatches are the chunk states of the finalized batches which are not confirmed on the DA yet.
+	PendingBatches     []executortypes.BatchChunkState `json:"pending_batches"`
+	LastSubmittedBatch *executortypes.BatchChunkState  `json:"last_submitted_batch,omitempty"`
 }
 
 func (bs BatchSubmitter) GetStatus() (Status, error) {
@@ -33,5 +38,130 @@ func (bs BatchSubmitter) GetStatus() (Status, error) {
 		BatchStartBlockNumber:   bs.localBatchInfo.Start,
 		BatchEndBlockNumber:     bs.localBatchInfo.End,
 		LastBatchSubmissionTime: bs.localBatchInfo.LastSubmissionTime,
+		PendingBatches:          bs.PendingChunkStates(),
+		LastSubmittedBatch:      bs.LastSubmittedBatch(),
 	}, nil
 }
+
+// batchHistorySize is the number of the last submissions kept in the batch history.
+const batchHistorySize = 50
+
+// BatchSubmission is the batch whose header and chunks are all confirmed on the DA.
+type BatchSubmission struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+	// TxHashes are the hashes of the DA txs of the header and the chunks in order, the header first.
+	TxHashes []string `json:"tx_hashes"`
+	// GasUsed is the gas used by the DA txs of the header and the chunks.
+	GasUsed     uint64    `json:"gas_used"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// BatchProgress is the progress of the batch in progress and the submissions since the bot started.
+type BatchProgress struct {
+	BatchStart int64 `json:"batch_start"`
+	// BatchEnd is 0 while the batch is in progress.
+	BatchEnd int64 `json:"batch_end"`
+	// RawBytes are the bytes of the blocks written to the batch before the compression.
+	RawBytes        int64 `json:"raw_bytes"`
+	CompressedBytes int64 `json:"compressed_bytes"`
+
+	LastSubmissionTime   time.Time `json:"last_submission_time"`
+	LastSubmissionTxHash string    `json:"last_submission_tx_hash"`
+	// SubmittedBatches is the number of the batches submitted since the bot started.
+	SubmittedBatches uint64 `json:"submitted_batches"`
+}
+
+// BatchStatus is the snapshot of the node status, the active batch info and the batch progress.
+type BatchStatus struct {
+	nodetypes.NodeStatus
+	BatchProgress
+
+	BatchInfo ophosttypes.BatchInfo `json:"batch_info"`
+}
+
+// batchHistory keeps the progress updated by the block loop and the last submissions confirmed
+// by the DA, so the status can be read without blocking the loop.
+type batchHistory struct {
+	mu          *sync.RWMutex
+	progress    BatchProgress
+	submissions []BatchSubmission
+}
+
+func newBatchHistory() *batchHistory {
+	return &batchHistory{
+		mu:          &sync.RWMutex{},
+		submissions: make([]BatchSubmission, 0, batchHistorySize),
+	}
+}
+
+func (h *batchHistory) updateProgress(fn func(*BatchProgress)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fn(&h.progress)
+}
+
+// add appends the submission, and drops the oldest one if the history is full.
+func (h *batchHistory) add(submission BatchSubmission) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.submissions) == batchHistorySize {
+		h.submissions = append(h.submissions[:0], h.submissions[1:]...)
+	}
+	h.submissions = append(h.submissions, submission)
+
+	h.progress.SubmittedBatches++
+	h.progress.LastSubmissionTime = submission.SubmittedAt
+	if len(submission.TxHashes) != 0 {
+		h.progress.LastSubmissionTxHash = submission.TxHashes[len(submission.TxHashes)-1]
+	}
+}
+
+func (h *batchHistory) load() (BatchProgress, []BatchSubmission) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	// the latest submission first
+	submissions := make([]BatchSubmission, 0, len(h.submissions))
+	for i := len(h.submissions) - 1; i >= 0; i-- {
+		submissions = append(submissions, h.submissions[i])
+	}
+	return h.progress, submissions
+}
+
+// updateProgress updates the progress of the batch in progress at the end of the block.
+func (bs *BatchSubmitter) updateProgress() {
+	bs.history.updateProgress(func(p *BatchProgress) {
+		p.BatchStart = bs.localBatchInfo.Start
+		p.BatchEnd = bs.localBatchInfo.End
+		p.RawBytes = bs.rawBatchSize
+		p.CompressedBytes = bs.localBatchInfo.BatchFileSize
+	})
+}
+
+// Status returns the snapshot of the node status, the active batch info and the batch progress
+// for the query server. It does not block the block process loop.
+func (bs *BatchSubmitter) Status() (BatchStatus, error) {
+	if bs.node == nil {
+		return BatchStatus{}, errors.New("node is not initialized")
+	}
+
+	nodeStatus, err := bs.node.Status()
+	if err != nil {
+		return BatchStatus{}, err
+	}
+	progress, _ := bs.history.load()
+	return BatchStatus{
+		NodeStatus:    nodeStatus,
+		BatchProgress: progress,
+		BatchInfo:     bs.BatchInfo().BatchInfo,
+	}, nil
+}
+
+// BatchHistory returns the last submissions of the batches, the latest first.
+func (bs *BatchSubmitter) BatchHistory() []BatchSubmission {
+	_, submissions := bs.history.load()
+	return submissions
+}
@@ -0,0 +1,83 @@
+package batch
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+func Test_BatchHistory(t *testing.T) {
+	bs := newTestFileBatchSubmitter(t, 1)
+	bs.batchInfoMu = &sync.Mutex{}
+	bs.batchInfos = []ophosttypes.BatchInfoWithOutput{{
+		BatchInfo: ophosttypes.BatchInfo{ChainType: ophosttypes.BatchInfo_CHAIN_TYPE_CELESTIA, Submitter: "celestia1submitter"},
+	}}
+
+	// the batch in progress
+	bs.localBatchInfo.Start = 1
+	for _, block := range testBlocks(t, 3) {
+		_, err := bs.handleBatch(block)
+		require.NoError(t, err)
+	}
+	fileSize, err := bs.batchFileSize(true)
+	require.NoError(t, err)
+	bs.localBatchInfo.BatchFileSize = fileSize
+	bs.updateProgress()
+
+	status, err := bs.Status()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.BatchStart)
+	require.Equal(t, int64(0), status.BatchEnd)
+	require.Greater(t, status.RawBytes, status.CompressedBytes)
+	require.Equal(t, fileSize, status.CompressedBytes)
+	require.Equal(t, "celestia1submitter", status.BatchInfo.Submitter)
+	require.Equal(t, uint64(0), status.SubmittedBatches)
+	require.Empty(t, bs.BatchHistory())
+
+	// submit two batches
+	states := []executortypes.BatchChunkState{
+		{Start: 1, End: 10, TraceIDs: []string{"header1", "chunk1"}, Confirmed: make([]bool, 2), TxHashes: make([]string, 2)},
+		{Start: 11, End: 20, TraceIDs: []string{"header2", "chunk2"}, Confirmed: make([]bool, 2), TxHashes: make([]string, 2)},
+	}
+	for _, state := range states {
+		require.NoError(t, bs.saveChunkState(state))
+		bs.trackChunkState(state)
+	}
+
+	submittedAt := time.Unix(1000, 0).UTC()
+	require.NoError(t, bs.confirmChunk("header1", "TX1", 100, submittedAt))
+	require.NoError(t, bs.confirmChunk("header2", "TX2", 200, submittedAt))
+	require.NoError(t, bs.confirmChunk("chunk1", "TX3", 1000, submittedAt.Add(time.Second)))
+	require.NoError(t, bs.confirmChunk("chunk2", "TX4", 2000, submittedAt.Add(2*time.Second)))
+
+	history := bs.BatchHistory()
+	require.Equal(t, []BatchSubmission{
+		{Start: 11, End: 20, TxHashes: []string{"TX2", "TX4"}, GasUsed: 2200, SubmittedAt: submittedAt.Add(2 * time.Second)},
+		{Start: 1, End: 10, TxHashes: []string{"TX1", "TX3"}, GasUsed: 1100, SubmittedAt: submittedAt.Add(time.Second)},
+	}, history)
+
+	status, err = bs.Status()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), status.SubmittedBatches)
+	require.Equal(t, "TX4", status.LastSubmissionTxHash)
+	require.Equal(t, submittedAt.Add(2*time.Second), status.LastSubmissionTime)
+
+	// only the last submissions are kept
+	for i := uint64(0); i < batchHistorySize; i++ {
+		bs.history.add(BatchSubmission{Start: 21 + i, End: 21 + i})
+	}
+	history = bs.BatchHistory()
+	require.Len(t, history, batchHistorySize)
+	require.Equal(t, uint64(20+batchHistorySize), history[0].Start)
+	require.Equal(t, uint64(21), history[batchHistorySize-1].Start)
+
+	status, err = bs.Status()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2+batchHistorySize), status.SubmittedBatches)
+}
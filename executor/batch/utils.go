@@ -6,6 +6,7 @@ import (
 	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	"github.com/cosmos/gogoproto/proto"
 
+	"github.com/cosmos/cosmos-sdk/client"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
@@ -19,23 +20,30 @@ func prependLength(data []byte) []byte {
 	return append(lengthBytes, data...)
 }
 
-// emptyOracleData converts the MsgUpdateOracle messages's data field to empty
-// to decrease the size of the batch.
-func (bs *BatchSubmitter) emptyOracleData(pbb *cmtproto.Block) ([]byte, error) {
+// TxMsgsDecoder decodes the msgs of the tx bytes, e.g. Node.DecodeTxMsgs with the lenient tx decoding of the config.
+type TxMsgsDecoder func(txBytes []byte) ([]sdk.Msg, error)
+
+// EmptyOracleData converts the MsgUpdateOracle messages's data field to empty
+// to decrease the size of the batch, and returns the block bytes written to the batch.
+// The msgs are decoded by decodeTxMsgs, and only the oracle txs are decoded with the tx config to be converted.
+func EmptyOracleData(txConfig client.TxConfig, decodeTxMsgs TxMsgsDecoder, pbb *cmtproto.Block) ([]byte, error) {
 	for i, txBytes := range pbb.Data.GetTxs() {
-		txConfig := bs.node.GetTxConfig()
-		tx, err := txutils.DecodeTx(txConfig, txBytes)
+		msgs, err := decodeTxMsgs(txBytes)
 		if err != nil {
 			// ignore not registered tx in codec
 			continue
+		} else if len(msgs) != 1 {
+			continue
+		} else if _, ok := msgs[0].(*opchildtypes.MsgUpdateOracle); !ok {
+			continue
 		}
 
-		msgs := tx.GetMsgs()
-		if len(msgs) != 1 {
+		tx, err := txutils.DecodeTx(txConfig, txBytes)
+		if err != nil {
+			// the other parts of the tx are not registered in codec
 			continue
 		}
-
-		if msg, ok := msgs[0].(*opchildtypes.MsgUpdateOracle); ok {
+		if msg, ok := tx.GetMsgs()[0].(*opchildtypes.MsgUpdateOracle); ok {
 			msg.Data = []byte{}
 			tx, err := txutils.ChangeMsgsFromTx(txConfig, tx, []sdk.Msg{msg})
 			if err != nil {
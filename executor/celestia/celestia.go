@@ -3,6 +3,8 @@ package celestia
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 
 	"go.uber.org/zap"
@@ -35,8 +37,10 @@ type Celestia struct {
 	node  *node.Node
 	batch batchNode
 
-	bridgeId  uint64
-	namespace sh.Namespace
+	bridgeId uint64
+	// namespaceConfig is the source of the namespace id, see executortypes.ValidateCelestiaNamespace.
+	namespaceConfig string
+	namespace       sh.Namespace
 
 	cfg    nodetypes.NodeConfig
 	db     types.DB
@@ -44,12 +48,14 @@ type Celestia struct {
 }
 
 func NewDACelestia(
-	version uint8, cfg nodetypes.NodeConfig,
+	version uint8, cfg nodetypes.NodeConfig, namespaceConfig string,
 	db types.DB, logger *zap.Logger,
-) *Celestia {
+) (*Celestia, error) {
 	c := &Celestia{
 		version: version,
 
+		namespaceConfig: namespaceConfig,
+
 		cfg:    cfg,
 		db:     db,
 		logger: logger,
@@ -57,16 +63,16 @@ func NewDACelestia(
 
 	appCodec, txConfig, err := createCodec(cfg.Bech32Prefix)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	node, err := node.NewNode(cfg, db, logger, appCodec, txConfig)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	c.node = node
-	return c
+	return c, nil
 }
 
 func createCodec(bech32Prefix string) (codec.Codec, client.TxConfig, error) {
@@ -87,38 +93,56 @@ func (c *Celestia) Initialize(ctx context.Context, batch batchNode, bridgeId uin
 
 	c.batch = batch
 	c.bridgeId = bridgeId
-	c.namespace, err = sh.NewV0Namespace(c.NamespaceID())
+
+	namespaceID, err := c.NamespaceID()
+	if err != nil {
+		return err
+	}
+	c.namespace, err = sh.NewV0Namespace(namespaceID)
 	if err != nil {
 		return err
 	}
+	c.logger.Info("celestia namespace", zap.String("namespace_id", hex.EncodeToString(namespaceID)))
 	return nil
 }
 
-func (c *Celestia) RegisterDAHandlers() {
-	c.node.RegisterEventHandler("celestia.blob.v1.EventPayForBlobs", c.payForBlobsHandler)
-}
-
 func (c *Celestia) Start(ctx context.Context) {
 	c.logger.Info("celestia start")
 	c.node.Start(ctx)
 }
 
-func (c Celestia) BroadcastMsgs(msgs btypes.ProcessedMsgs) {
+func (c Celestia) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
 	if len(msgs.Msgs) == 0 {
-		return
+		return nil
 	}
 
-	c.node.MustGetBroadcaster().BroadcastMsgs(msgs)
+	return c.node.MustGetBroadcaster().BroadcastMsgs(msgs)
 }
 
 func (c Celestia) ProcessedMsgsToRawKV(msgs []btypes.ProcessedMsgs, delete bool) ([]types.RawKV, error) {
 	return c.node.MustGetBroadcaster().ProcessedMsgsToRawKV(msgs, delete)
 }
 
+func (c Celestia) UnconfirmedTraceIDs() (map[string]struct{}, error) {
+	return c.node.MustGetBroadcaster().UnconfirmedTraceIDs()
+}
+
+func (c Celestia) DeadLetterTraceIDs() (map[string]struct{}, error) {
+	return c.node.MustGetBroadcaster().DeadLetterTraceIDs()
+}
+
+func (c Celestia) RegisterTxConfirmedHandler(fn nodetypes.TxConfirmedHandlerFn) {
+	c.node.RegisterTxConfirmedHandler(fn)
+}
+
 func (c *Celestia) SetBridgeId(brigeId uint64) {
 	c.bridgeId = brigeId
 }
 
+func (c Celestia) Node() *node.Node {
+	return c.node
+}
+
 func (c Celestia) HasKey() bool {
 	return c.node.HasBroadcaster()
 }
@@ -127,12 +151,26 @@ func (c Celestia) GetHeight() int64 {
 	return c.node.GetHeight()
 }
 
-func (c Celestia) NamespaceID() []byte {
-	chainIDhash := sha256.Sum256([]byte(c.batch.ChainID()))
-	return chainIDhash[:10]
+// NamespaceID returns the namespace id of the batch blobs. The namespace is derived at the initialization,
+// so it is not changed by the bridge info migration and the readers keep following the same namespace.
+func (c Celestia) NamespaceID() ([]byte, error) {
+	switch c.namespaceConfig {
+	case "", executortypes.CelestiaNamespaceChainID:
+		chainIDhash := sha256.Sum256([]byte(c.batch.ChainID()))
+		return chainIDhash[:executortypes.CelestiaNamespaceIDSize], nil
+	case executortypes.CelestiaNamespaceBridgeID:
+		bridgeIdHash := sha256.Sum256(binary.BigEndian.AppendUint64(nil, c.bridgeId))
+		return bridgeIdHash[:executortypes.CelestiaNamespaceIDSize], nil
+	}
+
+	err := executortypes.ValidateCelestiaNamespace(c.namespaceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(c.namespaceConfig)
 }
 
-func (c Celestia) BaseAccountAddress() (string, error) {
+func (c Celestia) BaseAccountAddressString() (string, error) {
 	broadcaster, err := c.node.GetBroadcaster()
 	if err != nil {
 		if errors.Is(err, types.ErrKeyNotSet) {
@@ -0,0 +1,153 @@
+package celestia
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	celestiatypes "github.com/initia-labs/opinit-bots/types/celestia"
+)
+
+type mockBatchNode struct {
+	chainID string
+}
+
+func (m mockBatchNode) ChainID() string {
+	return m.chainID
+}
+
+func (m mockBatchNode) UpdateBatchInfo(string, string, uint64, int64) {}
+
+// newMockCelestiaRPCServer serves the status of the celestia node.
+func newMockCelestiaRPCServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Method != "status" {
+			http.Error(w, "unknown method", http.StatusNotFound)
+			return
+		}
+
+		res, err := cmtjson.Marshal(&rpccoretypes.ResultStatus{SyncInfo: rpccoretypes.SyncInfo{LatestBlockHeight: 100}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + string(res) + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestCelestia(t *testing.T, namespaceConfig string) *Celestia {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	server := newMockCelestiaRPCServer(t)
+	c, err := NewDACelestia(1, nodetypes.NodeConfig{
+		RPC:          server.URL,
+		ChainID:      "celestia-1",
+		ProcessType:  nodetypes.PROCESS_TYPE_ONLY_BROADCAST,
+		Bech32Prefix: "celestia",
+	}, namespaceConfig, db, zap.NewNop())
+	require.NoError(t, err)
+
+	err = c.Initialize(context.Background(), mockBatchNode{chainID: "l2-1"}, 7, nil)
+	require.NoError(t, err)
+	return c
+}
+
+func Test_NamespaceID(t *testing.T) {
+	chainIDHash := sha256.Sum256([]byte("l2-1"))
+	bridgeIdHash := sha256.Sum256(binary.BigEndian.AppendUint64(nil, 7))
+	customID := bytes.Repeat([]byte{1}, executortypes.CelestiaNamespaceIDSize)
+
+	cases := []struct {
+		config   string
+		expected []byte
+	}{
+		{"", chainIDHash[:10]},
+		{executortypes.CelestiaNamespaceChainID, chainIDHash[:10]},
+		{executortypes.CelestiaNamespaceBridgeID, bridgeIdHash[:10]},
+		{hex.EncodeToString(customID), customID},
+	}
+	for _, tc := range cases {
+		c := newTestCelestia(t, tc.config)
+		namespaceID, err := c.NamespaceID()
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, namespaceID, tc.config)
+		require.Equal(t, tc.expected, c.namespace.ID()[len(c.namespace.ID())-10:], tc.config)
+	}
+
+	c := &Celestia{namespaceConfig: "0102", batch: mockBatchNode{chainID: "l2-1"}}
+	_, err := c.NamespaceID()
+	require.Error(t, err)
+}
+
+func Test_CreateBatchMsg(t *testing.T) {
+	c := newTestCelestia(t, executortypes.CelestiaNamespaceBridgeID)
+
+	// no key to sign
+	require.False(t, c.HasKey())
+	msg, submitter, err := c.CreateBatchMsg([]byte("batch"))
+	require.NoError(t, err)
+	require.Nil(t, msg)
+	require.Empty(t, submitter)
+
+	signer := sdk.MustBech32ifyAddressBytes("celestia", bytes.Repeat([]byte{1}, 20))
+	pfb, err := c.newMsgPayForBlobsWithBlob(signer, []byte("batch"))
+	require.NoError(t, err)
+	require.Equal(t, signer, pfb.MsgPayForBlobs.Signer)
+	require.Equal(t, [][]byte{c.namespace.Bytes()}, pfb.MsgPayForBlobs.Namespaces)
+	require.Equal(t, []uint32{5}, pfb.MsgPayForBlobs.BlobSizes)
+	require.Len(t, pfb.MsgPayForBlobs.ShareCommitments, 1)
+	require.Equal(t, []byte("batch"), pfb.Blob.Data)
+	require.Equal(t, c.namespace.ID(), pfb.Blob.NamespaceId)
+
+	// the pending blob tx is converted back to the msg with the blob
+	txBuilder := c.node.GetTxConfig().NewTxBuilder()
+	require.NoError(t, txBuilder.SetMsgs(pfb.MsgPayForBlobs))
+	txBytes, err := c.node.GetTxConfig().TxEncoder()(txBuilder.GetTx())
+	require.NoError(t, err)
+	blobTx := celestiatypes.BlobTx{
+		Tx:     txBytes,
+		Blobs:  []*celestiatypes.Blob{pfb.Blob},
+		TypeId: "BLOB",
+	}
+	blobTxBytes, err := blobTx.Marshal()
+	require.NoError(t, err)
+
+	msgs, err := c.PendingTxToProcessedMsgs(blobTxBytes)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	restored, ok := msgs[0].(*celestiatypes.MsgPayForBlobsWithBlob)
+	require.True(t, ok)
+	require.Equal(t, pfb.MsgPayForBlobs.Signer, restored.MsgPayForBlobs.Signer)
+	require.Equal(t, pfb.MsgPayForBlobs.ShareCommitments, restored.MsgPayForBlobs.ShareCommitments)
+	require.Equal(t, pfb.Blob.Data, restored.Blob.Data)
+}
@@ -15,29 +15,41 @@ import (
 )
 
 func (c Celestia) CreateBatchMsg(rawBlob []byte) (sdk.Msg, string, error) {
-	submitter, err := c.BaseAccountAddress()
+	submitter, err := c.BaseAccountAddressString()
 	if err != nil {
 		if errors.Is(err, types.ErrKeyNotSet) {
 			return nil, "", nil
 		}
 		return nil, "", err
+	} else if submitter == "" {
+		return nil, "", nil
 	}
-	blob, err := sh.NewV0Blob(c.namespace, rawBlob)
+
+	msg, err := c.newMsgPayForBlobsWithBlob(submitter, rawBlob)
 	if err != nil {
 		return nil, "", err
 	}
+	return msg, submitter, nil
+}
+
+// newMsgPayForBlobsWithBlob creates the pay for blobs msg of the raw blob in the namespace of the batch.
+func (c Celestia) newMsgPayForBlobsWithBlob(submitter string, rawBlob []byte) (*celestiatypes.MsgPayForBlobsWithBlob, error) {
+	blob, err := sh.NewV0Blob(c.namespace, rawBlob)
+	if err != nil {
+		return nil, err
+	}
 	commitment, err := inclusion.CreateCommitment(blob,
 		merkle.HashFromByteSlices,
 		// https://github.com/celestiaorg/celestia-app/blob/4f4d0f7ff1a43b62b232726e52d1793616423df7/pkg/appconsts/v1/app_consts.go#L6
 		64,
 	)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	dataLength, err := types.SafeIntToUint32(len(blob.Data()))
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	return &celestiatypes.MsgPayForBlobsWithBlob{
@@ -54,5 +66,5 @@ func (c Celestia) CreateBatchMsg(rawBlob []byte) (sdk.Msg, string, error) {
 			ShareVersion:     uint32(blob.ShareVersion()),
 			NamespaceVersion: uint32(blob.Namespace().Version()),
 		},
-	}, submitter, nil
+	}, nil
 }
@@ -60,14 +60,14 @@ func (c *Celestia) BuildTxWithMessages(
 	return blobTxBytes, btypes.TxHash(txBytes), nil
 }
 
+// PendingTxToProcessedMsgs converts the pending tx to the msgs to be re-broadcasted. The pending txs are decoded
+// strictly regardless of the lenient tx decoding, because the msgs of the unknown type urls can't be re-broadcasted.
 func (c *Celestia) PendingTxToProcessedMsgs(
 	txBytes []byte,
 ) ([]sdk.Msg, error) {
-	txConfig := c.node.GetTxConfig()
-
 	blobTx := &celestiatypes.BlobTx{}
 	if err := blobTx.Unmarshal(txBytes); err == nil {
-		pfbTx, err := txutils.DecodeTx(txConfig, blobTx.Tx)
+		pfbTx, err := c.node.DecodeTx(blobTx.Tx)
 		if err != nil {
 			return nil, err
 		}
@@ -81,7 +81,7 @@ func (c *Celestia) PendingTxToProcessedMsgs(
 		}, nil
 	}
 
-	tx, err := txutils.DecodeTx(txConfig, txBytes)
+	tx, err := c.node.DecodeTx(txBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,65 @@
+package child
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	childprovider "github.com/initia-labs/opinit-bots/provider/child"
+)
+
+// RegisterBridgeInfoUpdateHandler registers the callback which is called with the new bridge info
+// whenever the bridge info of the opchild module is migrated to another bridge.
+func (ch *Child) RegisterBridgeInfoUpdateHandler(fn func(ophosttypes.QueryBridgeResponse)) {
+	ch.bridgeInfoUpdateHandlers = append(ch.bridgeInfoUpdateHandlers, fn)
+}
+
+// setBridgeInfoHandler handles the bridge info migration of the opchild module. The new bridge info is
+// queried from the host, and the msgs waiting to be broadcasted to the host with the old bridge id
+// are rewritten with the new bridge id.
+func (ch *Child) setBridgeInfoHandler(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+	bridgeId, err := childprovider.ParseSetBridgeInfo(args.EventAttributes)
+	if err != nil {
+		return err
+	}
+	oldBridgeId := ch.BridgeId()
+	if bridgeId == oldBridgeId {
+		return nil
+	}
+
+	bridgeInfo, err := ch.host.QueryBridgeConfig(ctx, bridgeId, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to query migrated bridge info")
+	}
+
+	ch.Logger().Warn("!!! BRIDGE INFO MIGRATED; the bridge id of the opchild module is changed !!!",
+		zap.Int64("height", args.BlockHeight),
+		zap.Uint64("old_bridge_id", oldBridgeId),
+		zap.Uint64("new_bridge_id", bridgeInfo.BridgeId),
+		zap.String("proposer", bridgeInfo.BridgeConfig.Proposer),
+		zap.String("batch_submitter", bridgeInfo.BridgeConfig.BatchInfo.Submitter),
+	)
+
+	rewritten, err := ch.host.RewriteQueuedBridgeMsgs(oldBridgeId, bridgeInfo.BridgeId)
+	if err != nil {
+		return errors.Wrap(err, "failed to rewrite queued msgs")
+	}
+	if rewritten > 0 {
+		ch.Logger().Warn("rewrite queued msgs with the migrated bridge id",
+			zap.Uint64("old_bridge_id", oldBridgeId),
+			zap.Uint64("new_bridge_id", bridgeInfo.BridgeId),
+			zap.Int("count", rewritten),
+		)
+	}
+
+	// the bridge info is applied only after the queued msgs are rewritten, so the failed rewrite is retried
+	ch.SetBridgeInfo(*bridgeInfo)
+	for _, fn := range ch.bridgeInfoUpdateHandlers {
+		fn(*bridgeInfo)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package child
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_SetBridgeInfoHandler(t *testing.T) {
+	ch, host := newTestChild(t)
+	host.bridgeInfos = map[uint64]ophosttypes.QueryBridgeResponse{
+		2: {BridgeId: 2, BridgeConfig: ophosttypes.BridgeConfig{Proposer: "new_proposer"}},
+	}
+
+	var updated []ophosttypes.QueryBridgeResponse
+	ch.RegisterBridgeInfoUpdateHandler(func(bridgeInfo ophosttypes.QueryBridgeResponse) {
+		updated = append(updated, bridgeInfo)
+	})
+
+	setBridgeInfo := func(bridgeId string) nodetypes.EventHandlerArgs {
+		return nodetypes.EventHandlerArgs{
+			BlockHeight: 10,
+			EventAttributes: []abcitypes.EventAttribute{
+				{Key: opchildtypes.AttributeKeyBridgeId, Value: bridgeId},
+			},
+		}
+	}
+
+	// the same bridge id is ignored
+	require.NoError(t, ch.setBridgeInfoHandler(context.Background(), setBridgeInfo("1")))
+	require.Empty(t, updated)
+	require.Empty(t, host.rewrittenBridgeIds)
+
+	// the bridge info is not applied if the queued msgs are not rewritten
+	host.rewriteErr = errors.New("failed to save rewritten msgs")
+	require.Error(t, ch.setBridgeInfoHandler(context.Background(), setBridgeInfo("2")))
+	require.Equal(t, uint64(1), ch.BridgeId())
+	require.Empty(t, updated)
+	host.rewriteErr = nil
+
+	// the migrated bridge info is propagated and the queued msgs are rewritten
+	require.NoError(t, ch.setBridgeInfoHandler(context.Background(), setBridgeInfo("2")))
+	require.Equal(t, uint64(2), ch.BridgeId())
+	require.Len(t, updated, 1)
+	require.Equal(t, "new_proposer", updated[0].BridgeConfig.Proposer)
+	require.Equal(t, [][2]uint64{{1, 2}}, host.rewrittenBridgeIds)
+
+	// the bridge which doesn't exist on the host fails
+	require.Error(t, ch.setBridgeInfoHandler(context.Background(), setBridgeInfo("3")))
+	require.Equal(t, uint64(2), ch.BridgeId())
+
+	require.Error(t, ch.setBridgeInfoHandler(context.Background(), nodetypes.EventHandlerArgs{}))
+}
@@ -2,8 +2,11 @@ package child
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -11,6 +14,8 @@ import (
 	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
 
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/node"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	"github.com/initia-labs/opinit-bots/types"
@@ -21,12 +26,17 @@ import (
 type hostNode interface {
 	HasKey() bool
 	BaseAccountAddressString() (string, error)
-	BroadcastMsgs(btypes.ProcessedMsgs)
+	BroadcastMsgs(btypes.ProcessedMsgs) error
 	ProcessedMsgsToRawKV([]btypes.ProcessedMsgs, bool) ([]types.RawKV, error)
-	QueryLastOutput(context.Context, uint64) (*ophosttypes.QueryOutputProposalResponse, error)
+	QueryLastOutput(context.Context, uint64, int64) (*ophosttypes.QueryOutputProposalResponse, error)
 	QueryOutput(context.Context, uint64, uint64, int64) (*ophosttypes.QueryOutputProposalResponse, error)
+	QueryOutputs(context.Context, uint64, uint64, uint64) ([]ophosttypes.QueryOutputProposalResponse, error)
+	ValidateOutputProposal(context.Context, uint64, uint64, int64, []byte) (bool, error)
+	QueryBridgeConfig(context.Context, uint64, int64) (*ophosttypes.QueryBridgeResponse, error)
+	RewriteQueuedBridgeMsgs(uint64, uint64) (int, error)
 
 	GetMsgProposeOutput(uint64, uint64, int64, []byte) (sdk.Msg, string, error)
+	CheckBalance(context.Context, string) error
 }
 
 type Child struct {
@@ -36,26 +46,66 @@ type Child struct {
 
 	nextOutputTime        time.Time
 	finalizingBlockHeight int64
+	outputSubmission      executortypes.OutputSubmissionConfig
+
+	// minimum withdrawal amounts by the base denom
+	minWithdrawalAmounts map[string]uint64
+
+	// set when the output submission is halted pending operator action
+	outputSubmissionHalted *atomic.Bool
+	// set in the emergency of the bridge, where only the withdrawals are tracked
+	readOnly *atomic.Bool
+	// outputs waiting to be proposed in order, e.g. while the proposer can't pay the fees
+	deferredOutputs        []executortypes.DeferredOutput
+	deferredOutputsChanged bool
 
 	// status info
-	lastUpdatedOracleL1Height         int64
+	lastUpdatedOracleL1Height         *atomic.Int64
 	lastFinalizedDepositL1BlockHeight int64
-	lastFinalizedDepositL1Sequence    uint64
+	lastFinalizedDepositL1Sequence    *atomic.Uint64
 	lastOutputTime                    time.Time
 
-	batchKVs        []types.RawKV
-	addressIndexMap map[string]uint64
+	batchKVs []types.RawKV
+	// height of the block whose state is not committed yet, 0 if there is none
+	blockInProgress int64
+
+	metrics        *childMetrics
+	outputProgress *outputProgress
+
+	// output roots of the outputs proposed by us, which are verified against the roots on chain
+	expectedOutputRootsMu *sync.Mutex
+	expectedOutputRoots   map[uint64][]byte
+
+	bridgeInfoUpdateHandlers []func(ophosttypes.QueryBridgeResponse)
 }
 
 func NewChildV1(
 	cfg nodetypes.NodeConfig,
 	db types.DB, logger *zap.Logger,
-) *Child {
-	return &Child{
-		BaseChild:       childprovider.NewBaseChildV1(cfg, db, logger),
-		batchKVs:        make([]types.RawKV, 0),
-		addressIndexMap: make(map[string]uint64),
+) (*Child, error) {
+	baseChild, err := childprovider.NewBaseChildV1(cfg, db, logger)
+	if err != nil {
+		return nil, err
 	}
+
+	ch := &Child{
+		BaseChild:             baseChild,
+		batchKVs:              make([]types.RawKV, 0),
+		metrics:               newChildMetrics(cfg.Bridge),
+		outputProgress:        newOutputProgress(),
+		expectedOutputRootsMu: &sync.Mutex{},
+		expectedOutputRoots:   make(map[uint64][]byte),
+
+		outputSubmissionHalted:    &atomic.Bool{},
+		readOnly:                  &atomic.Bool{},
+		lastUpdatedOracleL1Height: &atomic.Int64{},
+
+		lastFinalizedDepositL1Sequence: &atomic.Uint64{},
+	}
+	if ch.Node().HasBroadcaster() {
+		ch.Node().MustGetBroadcaster().RegisterEffectChecker(sdk.MsgTypeURL(&opchildtypes.MsgFinalizeTokenDeposit{}), finalizeDepositEffectChecker{child: ch})
+	}
+	return ch, nil
 }
 
 func (ch *Child) Initialize(
@@ -66,8 +116,20 @@ func (ch *Child) Initialize(
 	bridgeInfo ophosttypes.QueryBridgeResponse,
 	keyringConfig *btypes.KeyringConfig,
 	oracleKeyringConfig *btypes.KeyringConfig,
+	routedKeyringConfigs []btypes.KeyringConfig,
 	disableDeleteFutureWithdrawals bool,
-) error {
+	confirmDestructiveRewind bool,
+) (*executortypes.DeleteFutureWithdrawalsReport, error) {
+	hasSyncInfo, err := ch.Node().HasSyncInfo()
+	if err != nil {
+		return nil, err
+	} else if !hasSyncInfo {
+		processedHeight, startOutputIndex, err = ch.detectStartHeight(ctx, host, bridgeInfo.BridgeId, processedHeight, startOutputIndex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	l2Sequence, err := ch.BaseChild.Initialize(
 		ctx,
 		processedHeight,
@@ -75,27 +137,113 @@ func (ch *Child) Initialize(
 		bridgeInfo,
 		keyringConfig,
 		oracleKeyringConfig,
+		routedKeyringConfigs,
 		disableDeleteFutureWithdrawals,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
+
+	// the future withdrawals are deleted only if the operator confirms it
+	var report *executortypes.DeleteFutureWithdrawalsReport
 	if l2Sequence != 0 {
-		err = ch.DeleteFutureWithdrawals(l2Sequence)
+		report, err = ch.deleteFutureWithdrawals(l2Sequence, confirmDestructiveRewind)
 		if err != nil {
-			return err
+			return report, err
 		}
 	}
+	err = ch.DeleteFutureWorkingTrees(processedHeight)
+	if err != nil {
+		return report, err
+	}
+
+	err = ch.loadLastUpdatedOracleL1Height()
+	if err != nil {
+		return report, err
+	}
+	err = ch.loadLastFinalizedDepositL1Sequence(ctx)
+	if err != nil {
+		return report, err
+	}
+	err = ch.loadReadOnlyMode()
+	if err != nil {
+		return report, err
+	}
+	err = ch.loadDeferredOutputs()
+	if err != nil {
+		return report, err
+	}
 
 	ch.host = host
-	ch.registerHandlers()
-	return nil
+	if !ch.Node().HeightInitialized() {
+		err = ch.checkTreeDivergence(ctx)
+		if err != nil {
+			return report, err
+		}
+	}
+	err = ch.registerHandlers()
+	return report, err
 }
 
-func (ch *Child) registerHandlers() {
-	ch.Node().RegisterBeginBlockHandler(ch.beginBlockHandler)
-	ch.Node().RegisterEventHandler(opchildtypes.EventTypeFinalizeTokenDeposit, ch.finalizeDepositHandler)
-	ch.Node().RegisterEventHandler(opchildtypes.EventTypeUpdateOracle, ch.updateOracleHandler)
-	ch.Node().RegisterEventHandler(opchildtypes.EventTypeInitiateTokenWithdrawal, ch.initiateWithdrawalHandler)
-	ch.Node().RegisterEndBlockHandler(ch.endBlockHandler)
+// detectStartHeight detects the processed height and the start output index of the fresh child from the last output
+// submitted to the host, so the child starts right after the output. If the start height is configured, the configured
+// values are kept and the mismatches with the detected values are reported as warnings.
+func (ch *Child) detectStartHeight(ctx context.Context, host hostNode, bridgeId uint64, processedHeight int64, startOutputIndex uint64) (int64, uint64, error) {
+	output, err := host.QueryLastOutput(ctx, bridgeId, 0)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to query the last output to detect the start height")
+	}
+
+	// the fresh bridge without outputs starts from the genesis
+	detectedHeight, detectedOutputIndex := int64(0), uint64(1)
+	if output != nil {
+		detectedHeight = types.MustUint64ToInt64(output.OutputProposal.L2BlockNumber)
+		detectedOutputIndex = output.OutputIndex + 1
+	}
+
+	if processedHeight == 0 {
+		ch.Logger().Info("detect start height from the last output",
+			zap.Int64("start_height", detectedHeight+1),
+			zap.Uint64("start_output_index", detectedOutputIndex),
+		)
+		return detectedHeight, detectedOutputIndex, nil
+	}
+
+	if processedHeight != detectedHeight || startOutputIndex != detectedOutputIndex {
+		ch.Logger().Warn("configured start height differs from the last output",
+			zap.Int64("start_height", processedHeight+1),
+			zap.Int64("detected_start_height", detectedHeight+1),
+			zap.Uint64("start_output_index", startOutputIndex),
+			zap.Uint64("detected_start_output_index", detectedOutputIndex),
+		)
+	}
+	return processedHeight, startOutputIndex, nil
+}
+
+func (ch *Child) registerHandlers() error {
+	if err := ch.Node().RegisterBeginBlockHandler(ch.beginBlockHandler); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEventHandler(opchildtypes.EventTypeFinalizeTokenDeposit, ch.finalizeDepositHandler, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEventHandler(opchildtypes.EventTypeUpdateOracle, ch.updateOracleHandler, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEventHandler(opchildtypes.EventTypeSetBridgeInfo, ch.setBridgeInfoHandler, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEventHandlerWithDedup(opchildtypes.EventTypeInitiateTokenWithdrawal, ch.initiateWithdrawalHandler, childprovider.InitiateWithdrawalKey, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterEndBlockHandler(ch.endBlockHandler); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterRewindHandler(ch.rewindHandler); err != nil {
+		return err
+	}
+	if err := ch.Node().RegisterSkipBlockHandler(ch.skipBlockHandler); err != nil {
+		return err
+	}
+	return nil
 }
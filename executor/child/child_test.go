@@ -0,0 +1,37 @@
+package child
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+)
+
+func Test_DetectStartHeight(t *testing.T) {
+	ch, host := newTestChild(t)
+
+	// the fresh bridge without outputs starts from the genesis
+	host.outputs = map[uint64]ophosttypes.Output{}
+	processedHeight, startOutputIndex, err := ch.detectStartHeight(context.Background(), host, 1, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), processedHeight)
+	require.Equal(t, uint64(1), startOutputIndex)
+
+	// the established bridge starts right after the last output
+	host.outputs = map[uint64]ophosttypes.Output{
+		1: {L2BlockNumber: 10},
+		2: {L2BlockNumber: 25},
+	}
+	processedHeight, startOutputIndex, err = ch.detectStartHeight(context.Background(), host, 1, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(25), processedHeight)
+	require.Equal(t, uint64(3), startOutputIndex)
+
+	// the configured values take precedence over the detected values
+	processedHeight, startOutputIndex, err = ch.detectStartHeight(context.Background(), host, 1, 10, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), processedHeight)
+	require.Equal(t, uint64(2), startOutputIndex)
+}
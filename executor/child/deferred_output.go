@@ -0,0 +1,156 @@
+package child
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// proposeOutput queues the msg proposing the output. It returns false if the proposal must be deferred,
+// e.g. while the proposer can't pay the fees, not to burn the sequences with the failing txs.
+func (ch *Child) proposeOutput(ctx context.Context, output executortypes.DeferredOutput) (bool, error) {
+	if ch.outputSubmissionHalted.Load() {
+		ch.Logger().Warn("output submission is halted; skip proposing output",
+			zap.Uint64("output_index", output.OutputIndex),
+			zap.Int64("height", output.L2BlockNumber),
+		)
+		return true, nil
+	}
+
+	// validate the output against the outputs on chain to not burn the fees for the failing tx
+	exists, err := ch.host.ValidateOutputProposal(ctx, ch.BridgeId(), output.OutputIndex, output.L2BlockNumber, output.OutputRoot)
+	if errors.Is(err, types.ErrOutputConflict) {
+		ch.Logger().Error("conflicting output exists; halt output submission",
+			zap.Uint64("output_index", output.OutputIndex),
+			zap.Int64("height", output.L2BlockNumber),
+			zap.String("error", err.Error()),
+		)
+		ch.HaltOutputSubmission()
+		return true, nil
+	} else if err != nil {
+		return false, err
+	} else if exists {
+		ch.Logger().Info("output already proposed; skip proposing output",
+			zap.Uint64("output_index", output.OutputIndex),
+			zap.Int64("height", output.L2BlockNumber),
+		)
+		return true, nil
+	}
+
+	msg, sender, err := ch.host.GetMsgProposeOutput(
+		ch.BridgeId(),
+		output.OutputIndex,
+		output.L2BlockNumber,
+		output.OutputRoot,
+	)
+	if err != nil {
+		return false, err
+	} else if msg == nil {
+		return true, nil
+	}
+
+	err = ch.host.CheckBalance(ctx, sender)
+	if errors.Is(err, types.ErrInsufficientBalance) {
+		ch.Logger().Warn("proposer can't pay the fees; defer proposing output",
+			zap.Uint64("output_index", output.OutputIndex),
+			zap.Int64("height", output.L2BlockNumber),
+			zap.String("error", err.Error()),
+		)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	ch.expectOutputRoot(output.OutputIndex, output.OutputRoot)
+	ch.AppendMsgQueue(msg, sender)
+	ch.metrics.OutputsProposed.Inc()
+	return true, nil
+}
+
+// deferOutput appends the output to the deferred outputs, which are proposed in the order of the index.
+func (ch *Child) deferOutput(output executortypes.DeferredOutput) {
+	// the output of the same index is finalized again when the block is retried
+	deferredOutputs := slices.DeleteFunc(ch.deferredOutputs, func(deferred executortypes.DeferredOutput) bool {
+		return deferred.OutputIndex >= output.OutputIndex
+	})
+	ch.setDeferredOutputs(append(deferredOutputs, output))
+	ch.deferredOutputsChanged = true
+}
+
+// proposeDeferredOutputs proposes the deferred outputs in order, and stops at the first output
+// which is deferred again. The outputs are kept deferred while the child is in the read-only mode.
+func (ch *Child) proposeDeferredOutputs(ctx context.Context) error {
+	if ch.ReadOnly() {
+		return nil
+	}
+	for len(ch.deferredOutputs) > 0 {
+		proposed, err := ch.proposeOutput(ctx, ch.deferredOutputs[0])
+		if err != nil || !proposed {
+			return err
+		}
+		ch.setDeferredOutputs(slices.Delete(ch.deferredOutputs, 0, 1))
+		ch.deferredOutputsChanged = true
+	}
+	return nil
+}
+
+// setDeferredOutputs sets the deferred outputs and their number in the output progress.
+func (ch *Child) setDeferredOutputs(deferredOutputs []executortypes.DeferredOutput) {
+	ch.deferredOutputs = deferredOutputs
+	ch.outputProgress.update(func(progress *OutputProgress) {
+		progress.DeferredOutputs = len(deferredOutputs)
+	})
+}
+
+func (ch *Child) deferredOutputsToRawKV() (types.RawKV, error) {
+	data, err := json.Marshal(ch.deferredOutputs)
+	if err != nil {
+		return types.RawKV{}, err
+	}
+	return types.RawKV{
+		Key:   ch.DB().PrefixedKey(executortypes.DeferredOutputsKey),
+		Value: data,
+	}, nil
+}
+
+// loadDeferredOutputs restores the deferred outputs, so they are proposed after the restart.
+func (ch *Child) loadDeferredOutputs() error {
+	data, err := ch.DB().Get(executortypes.DeferredOutputsKey)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var deferredOutputs []executortypes.DeferredOutput
+	err = json.Unmarshal(data, &deferredOutputs)
+	if err != nil {
+		return err
+	}
+	ch.setDeferredOutputs(deferredOutputs)
+	return nil
+}
+
+// dropDeferredOutputs drops the deferred outputs from the output index, which are finalized again
+// after the rewind.
+func (ch *Child) dropDeferredOutputs(fromOutputIndex uint64) error {
+	deferredOutputs := slices.DeleteFunc(ch.deferredOutputs, func(deferred executortypes.DeferredOutput) bool {
+		return deferred.OutputIndex >= fromOutputIndex
+	})
+	if len(deferredOutputs) == len(ch.deferredOutputs) {
+		return nil
+	}
+	ch.setDeferredOutputs(deferredOutputs)
+
+	kv, err := ch.deferredOutputsToRawKV()
+	if err != nil {
+		return err
+	}
+	return ch.DB().RawBatchSet(kv)
+}
@@ -2,12 +2,17 @@ package child
 
 import (
 	"context"
+	"errors"
 
-	nodetypes "github.com/initia-labs/opinit-bots/node/types"
-	childprovider "github.com/initia-labs/opinit-bots/provider/child"
 	"go.uber.org/zap"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	childprovider "github.com/initia-labs/opinit-bots/provider/child"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 func (ch *Child) finalizeDepositHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
@@ -17,7 +22,13 @@ func (ch *Child) finalizeDepositHandler(_ context.Context, args nodetypes.EventH
 	}
 	ch.handleFinalizeDeposit(l1BlockHeight, l1Sequence, from, to, amount, baseDenom)
 	ch.lastFinalizedDepositL1BlockHeight = l1BlockHeight
-	ch.lastFinalizedDepositL1Sequence = l1Sequence
+	if l1Sequence > ch.lastFinalizedDepositL1Sequence.Load() {
+		ch.lastFinalizedDepositL1Sequence.Store(l1Sequence)
+		ch.batchKVs = append(ch.batchKVs, types.RawKV{
+			Key:   ch.DB().PrefixedKey(executortypes.LastFinalizedDepositSequenceKey),
+			Value: dbtypes.FromUint64(l1Sequence),
+		})
+	}
 	return nil
 }
 
@@ -31,3 +42,37 @@ func (ch *Child) handleFinalizeDeposit(l1BlockHeight int64, l1Sequence uint64, f
 		zap.String("base_denom", baseDenom),
 	)
 }
+
+// LastFinalizedDepositL1Sequence returns the l1 sequence of the last deposit finalized in l2,
+// so the host doesn't relay the deposits finalized already.
+func (ch *Child) LastFinalizedDepositL1Sequence() uint64 {
+	return ch.lastFinalizedDepositL1Sequence.Load()
+}
+
+// loadLastFinalizedDepositL1Sequence loads the last finalized deposit sequence from the db, and
+// heals it with the next l1 sequence of the opchild module when the db is lost or behind.
+func (ch *Child) loadLastFinalizedDepositL1Sequence(ctx context.Context) error {
+	value, err := ch.DB().Get(executortypes.LastFinalizedDepositSequenceKey)
+	if err == nil {
+		l1Sequence, err := dbtypes.ToUint64(value)
+		if err != nil {
+			return err
+		}
+		ch.lastFinalizedDepositL1Sequence.Store(l1Sequence)
+	} else if !errors.Is(err, dbtypes.ErrNotFound) {
+		return err
+	}
+
+	nextL1Sequence, err := ch.QueryNextL1Sequence(ctx, 0)
+	if err != nil {
+		return err
+	}
+	if nextL1Sequence > 0 && nextL1Sequence-1 > ch.lastFinalizedDepositL1Sequence.Load() {
+		ch.Logger().Info("heal last finalized deposit sequence",
+			zap.Uint64("stored", ch.lastFinalizedDepositL1Sequence.Load()),
+			zap.Uint64("next_l1_sequence", nextL1Sequence),
+		)
+		ch.lastFinalizedDepositL1Sequence.Store(nextL1Sequence - 1)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package child
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// divergenceOutputsWindow is the number of the outputs queried at once while walking back the finalized trees.
+const divergenceOutputsWindow = 100
+
+// checkTreeDivergence compares the finalized trees with the outputs on chain, which diverge when the db
+// is restored from an old backup. If they diverge, it rewinds to the l2 block of the last finalized tree
+// matching the output, so the withdrawals after it are inserted to the trees again.
+func (ch *Child) checkTreeDivergence(ctx context.Context) error {
+	lastOutput, err := ch.host.QueryLastOutput(ctx, ch.BridgeId(), 0)
+	if err != nil {
+		return err
+	} else if lastOutput == nil {
+		return nil
+	}
+
+	var matchedTree *merkletypes.FinalizedTreeInfo
+	var matchedOutput *ophosttypes.QueryOutputProposalResponse
+	divergedTreeIndices := make([]uint64, 0)
+	outputs := make(map[uint64]ophosttypes.QueryOutputProposalResponse)
+	err = ch.Merkle().ReverseIterateFinalizedTrees(func(tree merkletypes.FinalizedTreeInfo) (bool, error) {
+		// the trees after the last output are not submitted yet
+		if tree.TreeIndex > lastOutput.OutputIndex {
+			return false, nil
+		}
+
+		output, ok := outputs[tree.TreeIndex]
+		if !ok {
+			// query the outputs of the window ending at the tree at once
+			fromIndex := uint64(1)
+			if tree.TreeIndex > divergenceOutputsWindow {
+				fromIndex = tree.TreeIndex - divergenceOutputsWindow + 1
+			}
+			res, err := ch.host.QueryOutputs(ctx, ch.BridgeId(), fromIndex, tree.TreeIndex-fromIndex+1)
+			if err != nil {
+				return true, err
+			}
+			for _, output := range res {
+				outputs[output.OutputIndex] = output
+			}
+
+			output, ok = outputs[tree.TreeIndex]
+			if !ok {
+				return true, fmt.Errorf("output not found; output index: %d", tree.TreeIndex)
+			}
+		}
+		outputRoot, err := ch.treeOutputRoot(tree)
+		if err != nil {
+			return true, err
+		}
+
+		if bytes.Equal(outputRoot, output.OutputProposal.OutputRoot) {
+			matchedTree = &tree
+			matchedOutput = &output
+			return true, nil
+		}
+		divergedTreeIndices = append(divergedTreeIndices, tree.TreeIndex)
+		return false, nil
+	})
+	if err != nil {
+		return err
+	} else if len(divergedTreeIndices) == 0 {
+		return nil
+	} else if matchedTree == nil {
+		return fmt.Errorf("finalized trees diverge from the outputs on chain without a matching tree; restart with l2_start_height; tree indices: %v", divergedTreeIndices)
+	}
+
+	rewindHeight := types.MustUint64ToInt64(matchedOutput.OutputProposal.L2BlockNumber)
+	nextSequence := matchedTree.StartLeafIndex + matchedTree.LeafCount
+	fields := []zap.Field{
+		zap.Uint64s("diverged_tree_indices", divergedTreeIndices),
+		zap.Uint64("output_index", matchedOutput.OutputIndex),
+		zap.Int64("rewind_height", rewindHeight),
+		zap.Uint64("next_sequence", nextSequence),
+	}
+	if types.NoAutoRewind(ctx) {
+		ch.Logger().Error("finalized trees diverge from the outputs on chain; skip auto rewind", fields...)
+		return nil
+	}
+	ch.Logger().Warn("finalized trees diverge from the outputs on chain; rewind", fields...)
+
+	lastProcessedHeight := ch.Height() - 1
+	err = ch.rewindHandler(ctx, nodetypes.RewindArgs{Height: rewindHeight, LastProcessedHeight: lastProcessedHeight})
+	if err != nil {
+		return err
+	}
+	err = ch.DeleteFutureWithdrawals(nextSequence)
+	if err != nil {
+		return err
+	}
+	err = ch.Node().SaveSyncInfo(rewindHeight)
+	if err != nil {
+		return err
+	}
+	ch.Node().SetSyncInfo(rewindHeight)
+	return nil
+}
+
+// treeOutputRoot returns the output root of the finalized tree with the output root version of the tree.
+func (ch *Child) treeOutputRoot(tree merkletypes.FinalizedTreeInfo) ([]byte, error) {
+	var extraData executortypes.TreeExtraData
+	err := json.Unmarshal(tree.ExtraData, &extraData)
+	if err != nil {
+		return nil, err
+	} else if len(tree.Root) != 32 || len(extraData.BlockHash) != 32 {
+		return nil, fmt.Errorf("invalid finalized tree; tree index: %d", tree.TreeIndex)
+	}
+	outputRoot, err := executortypes.ComputeOutputRoot(extraData.OutputRootVersionOrDefault(), tree.Root, extraData.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	return outputRoot[:], nil
+}
@@ -0,0 +1,85 @@
+package child
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_TreeDivergenceRewind(t *testing.T) {
+	ch, host := newTestChild(t)
+
+	// the restored db has the trees 1, 2 and 3 finalized at the heights 10, 20 and 30
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	for height := uint64(1); height <= 30; height++ {
+		if height > 1 {
+			require.NoError(t, ch.Merkle().LoadWorkingTree(height-1))
+		}
+		ch.batchKVs = ch.batchKVs[:0]
+		require.NoError(t, ch.handleInitiateWithdrawal(height, "sender", "receiver", "uinit", 100))
+		if height%10 == 0 {
+			blockHash := [32]byte{byte(height)}
+			extraData, err := json.Marshal(executortypes.TreeExtraData{BlockNumber: int64(height), BlockHash: blockHash[:]})
+			require.NoError(t, err)
+			kvs, _, err := ch.Merkle().FinalizeWorkingTree(extraData)
+			require.NoError(t, err)
+			ch.batchKVs = append(ch.batchKVs, kvs...)
+		}
+		require.NoError(t, ch.DB().RawBatchSet(ch.batchKVs...))
+		require.NoError(t, ch.Merkle().SaveWorkingTree(height))
+	}
+	require.NoError(t, ch.Node().SaveSyncInfo(30))
+	ch.Node().SetSyncInfo(30)
+
+	// the output 1 matches the tree 1, while the output 2 is proposed with the other withdrawals
+	_, _, root, extraData, err := ch.Merkle().GetProofs(1)
+	require.NoError(t, err)
+	outputRoot, err := ch.treeOutputRoot(merkletypes.FinalizedTreeInfo{Root: root, ExtraData: extraData})
+	require.NoError(t, err)
+	host.outputs = map[uint64]ophosttypes.Output{
+		1: {OutputRoot: outputRoot, L2BlockNumber: 10},
+		2: {OutputRoot: []byte("diverged"), L2BlockNumber: 20},
+	}
+
+	// only reported without auto rewind
+	require.NoError(t, ch.checkTreeDivergence(types.WithNoAutoRewind(context.Background(), true)))
+	require.Equal(t, [][2]uint64{{1, 2}}, host.queriedOutputsRanges)
+	_, _, _, _, err = ch.Merkle().GetProofs(15)
+	require.NoError(t, err)
+	require.Equal(t, int64(31), ch.Height())
+
+	// rewind to the l2 block of the output 1
+	require.NoError(t, ch.checkTreeDivergence(context.Background()))
+	require.Equal(t, int64(11), ch.Height())
+	_, _, _, _, err = ch.Merkle().GetProofs(15)
+	require.ErrorIs(t, err, merkletypes.ErrUnfinalizedTree)
+	_, _, _, _, err = ch.Merkle().GetProofs(5)
+	require.NoError(t, err)
+	require.Error(t, ch.Merkle().LoadWorkingTree(11))
+
+	_, err = ch.GetWithdrawal(11)
+	require.Error(t, err)
+	_, err = ch.GetWithdrawal(10)
+	require.NoError(t, err)
+
+	// the next tree starts right after the tree 1
+	require.NoError(t, ch.prepareTree(11))
+	workingTreeIndex, err := ch.GetWorkingTreeIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), workingTreeIndex)
+	startLeafIndex, err := ch.GetStartLeafIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), startLeafIndex)
+
+	// nothing to rewind after the rewind
+	require.NoError(t, ch.checkTreeDivergence(context.Background()))
+	require.Equal(t, int64(11), ch.Height())
+}
@@ -0,0 +1,40 @@
+package child
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+var _ btypes.EffectChecker = finalizeDepositEffectChecker{}
+
+// finalizeDepositEffectChecker checks whether the deposit has already been finalized.
+type finalizeDepositEffectChecker struct {
+	child *Child
+}
+
+func (c finalizeDepositEffectChecker) EffectKey(msg sdk.Msg) (string, error) {
+	finalizeDeposit, ok := msg.(*opchildtypes.MsgFinalizeTokenDeposit)
+	if !ok {
+		return "", fmt.Errorf("unexpected msg type: %s", sdk.MsgTypeURL(msg))
+	}
+	return fmt.Sprintf("l1_sequence/%d", finalizeDeposit.Sequence), nil
+}
+
+func (c finalizeDepositEffectChecker) EffectExists(ctx context.Context, msg sdk.Msg) (bool, error) {
+	finalizeDeposit, ok := msg.(*opchildtypes.MsgFinalizeTokenDeposit)
+	if !ok {
+		return false, fmt.Errorf("unexpected msg type: %s", sdk.MsgTypeURL(msg))
+	}
+
+	nextL1Sequence, err := c.child.QueryNextL1Sequence(ctx, 0)
+	if err != nil {
+		return false, err
+	}
+	return nextL1Sequence > finalizeDeposit.Sequence, nil
+}
@@ -3,20 +3,19 @@ package child
 import (
 	"context"
 	"errors"
-	"slices"
-	"time"
+	"fmt"
 
-	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/db"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
-	"golang.org/x/exp/maps"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 func (ch *Child) beginBlockHandler(ctx context.Context, args nodetypes.BeginBlockArgs) (err error) {
 	blockHeight := args.Block.Header.Height
+	ch.blockInProgress = blockHeight
 	ch.EmptyMsgQueue()
 	ch.EmptyProcessedMsgs()
 	ch.batchKVs = ch.batchKVs[:0]
-	maps.Clear(ch.addressIndexMap)
 
 	if ch.Merkle() == nil {
 		return errors.New("merkle is not initialized")
@@ -34,63 +33,113 @@ func (ch *Child) beginBlockHandler(ctx context.Context, args nodetypes.BeginBloc
 	return nil
 }
 
-func (ch *Child) endBlockHandler(_ context.Context, args nodetypes.EndBlockArgs) error {
+func (ch *Child) endBlockHandler(ctx context.Context, args nodetypes.EndBlockArgs) error {
 	blockHeight := args.Block.Header.Height
-	treeKVs, storageRoot, err := ch.handleTree(blockHeight, args.LatestHeight, args.BlockID, args.Block.Header)
+	txn := db.NewTxn(ch.DB())
+	txn.Add(db.TxnEntryOther, ch.batchKVs...)
+
+	storageRoot, err := ch.handleTree(txn, blockHeight, args.LatestHeight, args.BlockID, args.Block.Header)
+	if err != nil {
+		return err
+	}
+
+	err = ch.proposeDeferredOutputs(ctx)
 	if err != nil {
 		return err
 	}
 
-	ch.batchKVs = append(ch.batchKVs, treeKVs...)
 	if storageRoot != nil {
 		workingTreeIndex, err := ch.GetWorkingTreeIndex()
 		if err != nil {
 			return err
 		}
-		err = ch.handleOutput(blockHeight, ch.Version(), args.BlockID, workingTreeIndex, storageRoot)
+		err = ch.handleOutput(ctx, blockHeight, ch.Version(), args.BlockID, workingTreeIndex, storageRoot)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ch.deferredOutputsChanged {
+		deferredOutputsKV, err := ch.deferredOutputsToRawKV()
 		if err != nil {
 			return err
 		}
+		txn.Add(db.TxnEntryOther, deferredOutputsKV)
 	}
 
 	// update the sync info
-	ch.batchKVs = append(ch.batchKVs, ch.Node().SyncInfoToRawKV(blockHeight))
+	txn.Add(db.TxnEntrySyncInfo, ch.Node().SyncInfoToRawKV(blockHeight))
 
 	// if has key, then process the messages
 	if ch.host.HasKey() {
-		msgQueues := ch.GetMsgQueue()
-
-		for sender := range msgQueues {
-			msgQueue := msgQueues[sender]
-			for i := 0; i < len(msgQueue); i += 5 {
-				end := i + 5
-				if end > len(msgQueue) {
-					end = len(msgQueue)
-				}
-
-				ch.AppendProcessedMsgs(btypes.ProcessedMsgs{
-					Sender:    sender,
-					Msgs:      slices.Clone(msgQueue[i:end]),
-					Timestamp: time.Now().UnixNano(),
-					Save:      true,
-				})
-			}
+		if err := ch.FlushMsgQueue(); err != nil {
+			return err
 		}
 
 		msgKVs, err := ch.host.ProcessedMsgsToRawKV(ch.GetProcessedMsgs(), false)
 		if err != nil {
 			return err
 		}
-		ch.batchKVs = append(ch.batchKVs, msgKVs...)
+		txn.Add(db.TxnEntryProcessedMsgs, msgKVs...)
 	}
 
-	err = ch.DB().RawBatchSet(ch.batchKVs...)
+	err = txn.Commit()
+	if errors.Is(err, db.ErrInvalidTxn) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("%w: %w", nodetypes.ErrFatalDB, err)
+	}
+	ch.blockInProgress = 0
+	ch.deferredOutputsChanged = false
+
+	err = ch.updateOutputProgress(blockHeight, args.LatestHeight)
 	if err != nil {
 		return err
 	}
 
 	for _, processedMsg := range ch.GetProcessedMsgs() {
-		ch.host.BroadcastMsgs(processedMsg)
+		err = ch.host.BroadcastMsgs(processedMsg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipBlockHandler discards the state of the skipped block and carries the working tree of the previous
+// height forward, so the next block prepares its tree as if the skipped block had no withdrawals.
+func (ch *Child) skipBlockHandler(_ context.Context, args nodetypes.SkipBlockArgs) error {
+	blockHeight := args.BlockHeight
+	// the output already submitted at the block must be finalized by it
+	if ch.finalizingBlockHeight == blockHeight {
+		return fmt.Errorf("the block finalizing the submitted output can not be skipped: height: %d", blockHeight)
+	}
+
+	ch.EmptyMsgQueue()
+	ch.EmptyProcessedMsgs()
+	ch.batchKVs = ch.batchKVs[:0]
+	ch.blockInProgress = 0
+
+	if ch.Merkle() == nil {
+		return errors.New("merkle is not initialized")
+	}
+	err := ch.prepareTree(blockHeight)
+	if err != nil {
+		return err
+	}
+	workingTreeKV, err := ch.Merkle().WorkingTreeToRawKV(types.MustInt64ToUint64(blockHeight))
+	if err != nil {
+		return err
+	}
+
+	txn := db.NewTxn(ch.DB())
+	txn.Add(db.TxnEntryWorkingTree, workingTreeKV)
+	txn.Add(db.TxnEntrySyncInfo, ch.Node().SyncInfoToRawKV(blockHeight))
+	err = txn.Commit()
+	if errors.Is(err, db.ErrInvalidTxn) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("%w: %w", nodetypes.ErrFatalDB, err)
 	}
 	return nil
 }
@@ -0,0 +1,65 @@
+package child
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_SkipBlockAfterHandlerPanic(t *testing.T) {
+	ch, _ := newTestChild(t)
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	require.NoError(t, ch.Merkle().SaveWorkingTree(0))
+
+	sequence := uint64(0)
+	beginBlock := func(height int64) cmtproto.Block {
+		block := cmtproto.Block{Header: cmtproto.Header{Height: height}}
+		require.NoError(t, ch.beginBlockHandler(context.Background(), nodetypes.BeginBlockArgs{BlockID: make([]byte, 32), Block: block, LatestHeight: 10}))
+		sequence++
+		require.NoError(t, ch.handleInitiateWithdrawal(sequence, "sender", "receiver", "uinit", 100))
+		return block
+	}
+	endBlock := func(block cmtproto.Block) {
+		require.NoError(t, ch.endBlockHandler(context.Background(), nodetypes.EndBlockArgs{BlockID: make([]byte, 32), Block: block, LatestHeight: 10}))
+	}
+	leafCount := func() uint64 {
+		count, err := ch.Merkle().GetWorkingTreeLeafCount()
+		require.NoError(t, err)
+		return count
+	}
+
+	endBlock(beginBlock(1))
+
+	// a handler of the block 2 panics after its withdrawal is inserted, and the node skips the block
+	beginBlock(2)
+	require.Equal(t, uint64(2), leafCount())
+	require.NoError(t, ch.skipBlockHandler(context.Background(), nodetypes.SkipBlockArgs{BlockHeight: 2}))
+	require.Zero(t, ch.blockInProgress)
+	require.Empty(t, ch.batchKVs)
+
+	// the working tree of the block 1 is carried forward to the skipped block
+	require.NoError(t, ch.Merkle().LoadWorkingTree(2))
+	require.Equal(t, uint64(1), leafCount())
+	data, err := ch.DB().Get(nodetypes.LastProcessedBlockHeightKey)
+	require.NoError(t, err)
+	syncedHeight, err := dbtypes.ToInt64(data)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), syncedHeight)
+
+	// the block 3 is processed normally without the withdrawal of the skipped block
+	sequence--
+	endBlock(beginBlock(3))
+	require.Equal(t, uint64(2), leafCount())
+	require.NoError(t, ch.Merkle().LoadWorkingTree(3))
+	require.Equal(t, uint64(2), leafCount())
+
+	// the block finalizing the submitted output is not skipped
+	ch.finalizingBlockHeight = 4
+	require.Error(t, ch.skipBlockHandler(context.Background(), nodetypes.SkipBlockArgs{BlockHeight: 4}))
+}
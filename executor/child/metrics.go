@@ -0,0 +1,22 @@
+package child
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/initia-labs/opinit-bots/node/metrics"
+)
+
+type childMetrics struct {
+	OutputsProposed prometheus.Counter
+}
+
+func newChildMetrics(bridge string) *childMetrics {
+	return &childMetrics{
+		OutputsProposed: metrics.Register(bridge, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "child",
+			Name:      "outputs_proposed_total",
+			Help:      "The number of the output proposals queued to be submitted.",
+		})),
+	}
+}
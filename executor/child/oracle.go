@@ -2,10 +2,15 @@ package child
 
 import (
 	"context"
+	"errors"
 
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	childprovider "github.com/initia-labs/opinit-bots/provider/child"
-	"go.uber.org/zap"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 func (ch *Child) updateOracleHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
@@ -15,7 +20,13 @@ func (ch *Child) updateOracleHandler(_ context.Context, args nodetypes.EventHand
 	}
 
 	ch.handleUpdateOracle(l1BlockHeight, from)
-	ch.lastUpdatedOracleL1Height = l1BlockHeight
+	if l1BlockHeight > ch.lastUpdatedOracleL1Height.Load() {
+		ch.lastUpdatedOracleL1Height.Store(l1BlockHeight)
+		ch.batchKVs = append(ch.batchKVs, types.RawKV{
+			Key:   ch.DB().PrefixedKey(executortypes.LastUpdatedOracleL1HeightKey),
+			Value: dbtypes.FromInt64(l1BlockHeight),
+		})
+	}
 	return nil
 }
 
@@ -25,3 +36,25 @@ func (ch *Child) handleUpdateOracle(l1BlockHeight int64, from string) {
 		zap.String("from", from),
 	)
 }
+
+// LastUpdatedOracleL1Height returns the l1 height of the last oracle update included in l2,
+// so the host doesn't relay the oracle data older than it.
+func (ch *Child) LastUpdatedOracleL1Height() int64 {
+	return ch.lastUpdatedOracleL1Height.Load()
+}
+
+func (ch *Child) loadLastUpdatedOracleL1Height() error {
+	value, err := ch.DB().Get(executortypes.LastUpdatedOracleL1HeightKey)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	l1BlockHeight, err := dbtypes.ToInt64(value)
+	if err != nil {
+		return err
+	}
+	ch.lastUpdatedOracleL1Height.Store(l1BlockHeight)
+	return nil
+}
@@ -0,0 +1,81 @@
+package child
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+
+	"go.uber.org/zap"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+)
+
+// expectOutputRoot records the output root computed locally for the output proposed by us.
+func (ch *Child) expectOutputRoot(outputIndex uint64, outputRoot []byte) {
+	ch.expectedOutputRootsMu.Lock()
+	defer ch.expectedOutputRootsMu.Unlock()
+
+	ch.expectedOutputRoots[outputIndex] = outputRoot
+}
+
+// expectedOutputRoot returns the output root computed locally for the output. The roots of the outputs
+// proposed before the restart are recomputed from the finalized trees.
+func (ch *Child) expectedOutputRoot(outputIndex uint64) ([]byte, error) {
+	ch.expectedOutputRootsMu.Lock()
+	outputRoot, ok := ch.expectedOutputRoots[outputIndex]
+	ch.expectedOutputRootsMu.Unlock()
+	if ok {
+		return outputRoot, nil
+	}
+
+	err := ch.Merkle().ReverseIterateFinalizedTrees(func(tree merkletypes.FinalizedTreeInfo) (bool, error) {
+		if tree.TreeIndex > outputIndex {
+			return false, nil
+		} else if tree.TreeIndex < outputIndex {
+			return true, nil
+		}
+
+		var err error
+		outputRoot, err = ch.treeOutputRoot(tree)
+		return true, err
+	})
+	return outputRoot, err
+}
+
+// VerifyProposedOutput cross-checks the output root reported by the host after our output proposal
+// lands against the output root computed locally. On a mismatch, the output submission is halted, as
+// the following outputs would be proposed with the wrong roots as well.
+func (ch *Child) VerifyProposedOutput(_ context.Context, output executortypes.ProposedOutputInfo) error {
+	expected, err := ch.expectedOutputRoot(output.OutputIndex)
+	if err != nil {
+		return err
+	} else if expected == nil {
+		// the empty tree is not stored, so its root can't be recomputed after the restart
+		ch.Logger().Debug("skip verifying output root; local output root not found",
+			zap.Uint64("output_index", output.OutputIndex),
+		)
+		return nil
+	}
+
+	ch.expectedOutputRootsMu.Lock()
+	for outputIndex := range ch.expectedOutputRoots {
+		if outputIndex <= output.OutputIndex {
+			delete(ch.expectedOutputRoots, outputIndex)
+		}
+	}
+	ch.expectedOutputRootsMu.Unlock()
+
+	if bytes.Equal(expected, output.Root) {
+		return nil
+	}
+	ch.Logger().Error("INVARIANT failed; proposed output root mismatches the local output root; halt output submission",
+		zap.Uint64("output_index", output.OutputIndex),
+		zap.Int64("l2_block_number", output.L2BlockNumber),
+		zap.String("output_root", base64.StdEncoding.EncodeToString(output.Root)),
+		zap.String("local_output_root", base64.StdEncoding.EncodeToString(expected)),
+		zap.String("l1_tx_hash", output.L1TxHash),
+	)
+	ch.HaltOutputSubmission()
+	return nil
+}
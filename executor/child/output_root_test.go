@@ -0,0 +1,72 @@
+package child
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+)
+
+func Test_VerifyProposedOutput(t *testing.T) {
+	ch, _ := newTestChild(t)
+	blockId, storageRoot := bytes.Repeat([]byte{0x02}, 32), bytes.Repeat([]byte{0x01}, 32)
+
+	// the output root of our proposal matches the root on chain
+	require.NoError(t, ch.handleOutput(context.Background(), 10, 1, blockId, 1, storageRoot))
+	outputRoot, err := executortypes.ComputeOutputRoot(1, storageRoot, blockId)
+	require.NoError(t, err)
+	require.NoError(t, ch.VerifyProposedOutput(context.Background(), executortypes.ProposedOutputInfo{OutputIndex: 1, Root: outputRoot[:]}))
+	require.False(t, ch.outputSubmissionHalted.Load())
+	require.Empty(t, ch.expectedOutputRoots)
+
+	// the output root of the finalized tree is stored with the tree, and used after the restart
+	ch.finalizingBlockHeight = 20
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(2, 1))
+	leaf := [32]byte{1}
+	require.NoError(t, ch.Merkle().InsertLeaf(leaf[:]))
+	txn := db.NewTxn(ch.DB())
+	_, err = ch.handleTree(txn, 20, 20, blockId, cmtproto.Header{})
+	require.NoError(t, err)
+	txn.Add(db.TxnEntrySyncInfo, ch.Node().SyncInfoToRawKV(20))
+	require.NoError(t, txn.Commit())
+
+	var treeOutputRoot []byte
+	require.NoError(t, ch.Merkle().ReverseIterateFinalizedTrees(func(tree merkletypes.FinalizedTreeInfo) (bool, error) {
+		var extraData executortypes.TreeExtraData
+		require.NoError(t, json.Unmarshal(tree.ExtraData, &extraData))
+		require.Equal(t, executortypes.OutputRootVersion1, extraData.OutputRootVersion)
+		expected, err := executortypes.ComputeOutputRoot(extraData.OutputRootVersion, tree.Root, blockId)
+		require.NoError(t, err)
+		require.Equal(t, expected[:], extraData.OutputRoot)
+
+		// the tree finalized before the version is recorded is of the version 1
+		extraData.OutputRootVersion = 0
+		tree.ExtraData, err = json.Marshal(extraData)
+		require.NoError(t, err)
+		legacy, err := ch.treeOutputRoot(tree)
+		require.NoError(t, err)
+		require.Equal(t, expected[:], legacy)
+		treeOutputRoot = extraData.OutputRoot
+		return true, nil
+	}))
+	require.NotNil(t, treeOutputRoot)
+	require.Equal(t, treeOutputRoot, ch.outputProgress.load().LastOutputRoot)
+	require.NoError(t, ch.VerifyProposedOutput(context.Background(), executortypes.ProposedOutputInfo{OutputIndex: 2, Root: treeOutputRoot}))
+	require.False(t, ch.outputSubmissionHalted.Load())
+
+	// the output without the local output root is not verified
+	require.NoError(t, ch.VerifyProposedOutput(context.Background(), executortypes.ProposedOutputInfo{OutputIndex: 3, Root: outputRoot[:]}))
+	require.False(t, ch.outputSubmissionHalted.Load())
+
+	// the mismatch halts the output submission
+	require.NoError(t, ch.VerifyProposedOutput(context.Background(), executortypes.ProposedOutputInfo{OutputIndex: 2, Root: outputRoot[:]}))
+	require.True(t, ch.outputSubmissionHalted.Load())
+}
@@ -27,12 +27,19 @@ func (ch Child) QueryWithdrawal(sequence uint64) (executortypes.QueryWithdrawalR
 		Sequence: sequence,
 		Amount:   amount,
 		Version:  []byte{ch.Version()},
+
+		BelowMinimum:          withdrawal.BelowMinimum,
+		WithdrawalHashVersion: withdrawal.HashVersionOrDefault(),
 	}
 
-	proofs, outputIndex, outputRoot, extraDataBytes, err := ch.Merkle().GetProofs(sequence)
+	proofs, outputIndex, storageRoot, extraDataBytes, err := ch.Merkle().GetProofs(sequence)
 	if errors.Is(err, merkletypes.ErrUnfinalizedTree) {
 		// if the tree is not finalized, we just return only withdrawal info
 		return res, nil
+	} else if errors.Is(err, merkletypes.ErrPrunedTree) {
+		// the tree is pruned after all its withdrawals are claimed
+		res.Finalized = true
+		return res, nil
 	} else if err != nil {
 		return executortypes.QueryWithdrawalResponse{}, err
 	}
@@ -42,10 +49,16 @@ func (ch Child) QueryWithdrawal(sequence uint64) (executortypes.QueryWithdrawalR
 	if err != nil {
 		return executortypes.QueryWithdrawalResponse{}, err
 	}
+	outputRoot, err := executortypes.ComputeOutputRoot(treeExtraData.OutputRootVersionOrDefault(), storageRoot, treeExtraData.BlockHash)
+	if err != nil {
+		return executortypes.QueryWithdrawalResponse{}, err
+	}
 	res.WithdrawalProofs = proofs
 	res.OutputIndex = outputIndex
-	res.StorageRoot = outputRoot
+	res.StorageRoot = storageRoot
 	res.LastBlockHash = treeExtraData.BlockHash
+	res.OutputRoot = outputRoot[:]
+	res.Finalized = true
 	return res, nil
 }
 
@@ -54,6 +67,19 @@ func (ch Child) QueryWithdrawals(address string, offset uint64, limit uint64, de
 	if err != nil {
 		return executortypes.QueryWithdrawalsResponse{}, err
 	}
+	return ch.queryWithdrawalsBySequences(sequences, next)
+}
+
+// QueryBelowMinimumWithdrawals returns the withdrawals below the minimum withdrawal amount.
+func (ch Child) QueryBelowMinimumWithdrawals(offset uint64, limit uint64, descOrder bool) (executortypes.QueryWithdrawalsResponse, error) {
+	sequences, next, err := ch.GetBelowMinimumSequences(offset, limit, descOrder)
+	if err != nil {
+		return executortypes.QueryWithdrawalsResponse{}, err
+	}
+	return ch.queryWithdrawalsBySequences(sequences, next)
+}
+
+func (ch Child) queryWithdrawalsBySequences(sequences []uint64, next uint64) (executortypes.QueryWithdrawalsResponse, error) {
 	withdrawals := make([]executortypes.QueryWithdrawalResponse, 0)
 	for _, sequence := range sequences {
 		withdrawal, err := ch.QueryWithdrawal(sequence)
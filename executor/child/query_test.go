@@ -0,0 +1,129 @@
+package child
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+func Test_QueryWithdrawals(t *testing.T) {
+	ch, _ := newTestChild(t)
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+
+	receivers := []string{"receiver0", "receiver1", "receiver0"}
+	for i, receiver := range receivers {
+		require.NoError(t, ch.handleInitiateWithdrawal(uint64(i+1), "sender", receiver, "uinit", uint64(100*(i+1))))
+	}
+	require.NoError(t, ch.DB().RawBatchSet(ch.batchKVs...))
+
+	// the withdrawal info is returned without the proofs before the tree is finalized
+	res, err := ch.QueryWithdrawal(2)
+	require.NoError(t, err)
+	require.Equal(t, "sender", res.From)
+	require.Equal(t, "receiver1", res.To)
+	require.Equal(t, "200uinit", res.Amount.String())
+	require.Equal(t, uint64(1), res.BridgeId)
+	require.Zero(t, res.OutputIndex)
+	require.Empty(t, res.WithdrawalProofs)
+
+	blockHash := [32]byte{10}
+	extraData, err := json.Marshal(executortypes.TreeExtraData{BlockNumber: 10, BlockHash: blockHash[:]})
+	require.NoError(t, err)
+	kvs, storageRoot, err := ch.Merkle().FinalizeWorkingTree(extraData)
+	require.NoError(t, err)
+	require.NoError(t, ch.DB().RawBatchSet(kvs...))
+
+	// claim-ready after the tree is finalized
+	res, err = ch.QueryWithdrawal(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.OutputIndex)
+	require.Equal(t, storageRoot, res.StorageRoot)
+	require.Equal(t, blockHash[:], res.LastBlockHash)
+	require.NotEmpty(t, res.WithdrawalProofs)
+
+	// paginated by the receiver
+	withdrawals, err := ch.QueryWithdrawals("receiver0", 0, 1, false)
+	require.NoError(t, err)
+	require.Len(t, withdrawals.Withdrawals, 1)
+	require.Equal(t, uint64(1), withdrawals.Withdrawals[0].Sequence)
+	require.NotNil(t, withdrawals.Next)
+
+	withdrawals, err = ch.QueryWithdrawals("receiver0", *withdrawals.Next, 1, false)
+	require.NoError(t, err)
+	require.Len(t, withdrawals.Withdrawals, 1)
+	require.Equal(t, uint64(3), withdrawals.Withdrawals[0].Sequence)
+	require.Nil(t, withdrawals.Next)
+
+	// the records from the sequence are deleted on rewind
+	require.NoError(t, ch.DeleteFutureWithdrawals(2))
+	_, err = ch.QueryWithdrawal(2)
+	require.Error(t, err)
+
+	withdrawals, err = ch.QueryWithdrawals("receiver0", 0, 10, false)
+	require.NoError(t, err)
+	require.Len(t, withdrawals.Withdrawals, 1)
+	require.Equal(t, uint64(1), withdrawals.Withdrawals[0].Sequence)
+
+	withdrawals, err = ch.QueryWithdrawals("receiver1", 0, 10, true)
+	require.NoError(t, err)
+	require.Empty(t, withdrawals.Withdrawals)
+}
+
+func Test_QueryBelowMinimumWithdrawals(t *testing.T) {
+	ch, _ := newTestChild(t)
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	ch.SetMinWithdrawalAmounts(map[string]uint64{"uinit": 100, "uusdc": 1000})
+
+	withdrawals := []struct {
+		denom        string
+		amount       uint64
+		belowMinimum bool
+	}{
+		{"uinit", 99, true},
+		{"uinit", 100, false},
+		{"uusdc", 999, true},
+		{"uusdc", 1000, false},
+		{"uother", 1, false},
+		{"uinit", 0, true},
+	}
+	for i, withdrawal := range withdrawals {
+		require.NoError(t, ch.handleInitiateWithdrawal(uint64(i+1), "sender", "receiver", withdrawal.denom, withdrawal.amount))
+	}
+	require.NoError(t, ch.DB().RawBatchSet(ch.batchKVs...))
+
+	for i, withdrawal := range withdrawals {
+		res, err := ch.QueryWithdrawal(uint64(i + 1))
+		require.NoError(t, err)
+		require.Equal(t, withdrawal.belowMinimum, res.BelowMinimum, "sequence %d", i+1)
+	}
+
+	// changing the minimum doesn't affect the stored records
+	ch.SetMinWithdrawalAmounts(map[string]uint64{"uinit": 1, "uother": 10})
+	res, err := ch.QueryBelowMinimumWithdrawals(0, 10, false)
+	require.NoError(t, err)
+	sequences := make([]uint64, 0)
+	for _, withdrawal := range res.Withdrawals {
+		require.True(t, withdrawal.BelowMinimum)
+		sequences = append(sequences, withdrawal.Sequence)
+	}
+	require.Equal(t, []uint64{1, 3, 6}, sequences)
+	require.Nil(t, res.Next)
+
+	// paginated in the desc order
+	res, err = ch.QueryBelowMinimumWithdrawals(0, 2, true)
+	require.NoError(t, err)
+	require.Len(t, res.Withdrawals, 2)
+	require.Equal(t, uint64(6), res.Withdrawals[0].Sequence)
+	require.Equal(t, uint64(3), res.Withdrawals[1].Sequence)
+	require.Equal(t, uint64(1), *res.Next)
+
+	// the records from the sequence are deleted on rewind
+	require.NoError(t, ch.DeleteFutureWithdrawals(3))
+	res, err = ch.QueryBelowMinimumWithdrawals(0, 10, false)
+	require.NoError(t, err)
+	require.Len(t, res.Withdrawals, 1)
+	require.Equal(t, uint64(1), res.Withdrawals[0].Sequence)
+}
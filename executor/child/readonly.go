@@ -0,0 +1,51 @@
+package child
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+// EnterReadOnlyMode switches the child into the read-only mode for the emergency of the bridge.
+// The withdrawals are still inserted and the trees are finalized to keep the proofs available,
+// but the outputs are deferred and the deposits are not finalized until the mode is exited.
+func (ch *Child) EnterReadOnlyMode(reason string) error {
+	if ch.readOnly.Swap(true) {
+		return nil
+	}
+	ch.Logger().Warn("!!! ENTER READ-ONLY MODE; stop proposing outputs and finalizing deposits !!!", zap.String("reason", reason))
+	return ch.DB().Set(executortypes.ReadOnlyModeKey, dbtypes.FromUint64(1))
+}
+
+// ExitReadOnlyMode resumes proposing the outputs and finalizing the deposits.
+// The outputs deferred in the read-only mode are proposed with the next block, and
+// the deposits skipped in the mode are backfilled with the next deposit.
+func (ch *Child) ExitReadOnlyMode(reason string) error {
+	if !ch.readOnly.Swap(false) {
+		return nil
+	}
+	ch.Logger().Warn("exit read-only mode", zap.String("reason", reason))
+	return ch.DB().Delete(executortypes.ReadOnlyModeKey)
+}
+
+// ReadOnly returns true if the child is in the read-only mode.
+func (ch *Child) ReadOnly() bool {
+	return ch.readOnly.Load()
+}
+
+// loadReadOnlyMode restores the read-only mode, so the mode survives the restart.
+func (ch *Child) loadReadOnlyMode() error {
+	_, err := ch.DB().Get(executortypes.ReadOnlyModeKey)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	ch.readOnly.Store(true)
+	ch.Logger().Warn("child is in read-only mode")
+	return nil
+}
@@ -0,0 +1,48 @@
+package child
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+func Test_ReadOnlyMode(t *testing.T) {
+	ch, host := newTestChild(t)
+	host.outputs = map[uint64]ophosttypes.Output{}
+	storageRoot := make([]byte, 32)
+	blockId := make([]byte, 32)
+
+	require.NoError(t, ch.EnterReadOnlyMode("test"))
+	require.True(t, ch.ReadOnly())
+	status, err := ch.Status()
+	require.NoError(t, err)
+	require.True(t, status.ReadOnly)
+
+	// the outputs are deferred in the read-only mode
+	require.NoError(t, ch.handleOutput(context.Background(), 10, 1, blockId, 1, storageRoot))
+	require.NoError(t, ch.proposeDeferredOutputs(context.Background()))
+	require.Empty(t, ch.GetMsgQueue())
+	require.Len(t, ch.deferredOutputs, 1)
+
+	// the mode survives the restart
+	ch.readOnly.Store(false)
+	require.NoError(t, ch.loadReadOnlyMode())
+	require.True(t, ch.ReadOnly())
+
+	// the deferred outputs are proposed in order after the mode is exited
+	require.NoError(t, ch.ExitReadOnlyMode("test"))
+	require.False(t, ch.ReadOnly())
+	_, err = ch.DB().Get(executortypes.ReadOnlyModeKey)
+	require.Error(t, err)
+
+	require.NoError(t, ch.proposeDeferredOutputs(context.Background()))
+	require.NoError(t, ch.handleOutput(context.Background(), 20, 1, blockId, 2, storageRoot))
+	require.Len(t, ch.GetMsgQueue()["proposer"], 2)
+	require.Equal(t, uint64(1), ch.GetMsgQueue()["proposer"][0].(*ophosttypes.MsgProposeOutput).OutputIndex)
+	require.Empty(t, ch.deferredOutputs)
+}
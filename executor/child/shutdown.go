@@ -0,0 +1,41 @@
+package child
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// DiscardIncompleteBlock discards the state of the block whose handlers failed before the end block, and
+// reloads the working tree of the last processed height. The state of a block is committed with the sync
+// info at once, so the discarded block is processed again after the restart without duplicate leaves.
+// It returns the height of the discarded block, or 0 if every block is committed. It must be called after
+// the block process looper is stopped.
+func (ch *Child) DiscardIncompleteBlock() (int64, error) {
+	height := ch.blockInProgress
+	if height == 0 {
+		return 0, nil
+	}
+
+	ch.Logger().Warn("discard the state of the incomplete block",
+		zap.Int64("height", height),
+		zap.Int("kvs", len(ch.batchKVs)),
+		zap.Int("processed_msgs", len(ch.GetProcessedMsgs())),
+	)
+	ch.EmptyMsgQueue()
+	ch.EmptyProcessedMsgs()
+	ch.batchKVs = ch.batchKVs[:0]
+	ch.blockInProgress = 0
+
+	if ch.Merkle() == nil {
+		return height, nil
+	}
+	err := ch.Merkle().LoadWorkingTree(types.MustInt64ToUint64(height - 1))
+	if err != nil && !errors.Is(err, dbtypes.ErrNotFound) {
+		return height, err
+	}
+	return height, nil
+}
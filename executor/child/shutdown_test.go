@@ -0,0 +1,56 @@
+package child
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_DiscardIncompleteBlock(t *testing.T) {
+	ch, _ := newTestChild(t)
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	require.NoError(t, ch.Merkle().SaveWorkingTree(0))
+
+	sequence := uint64(0)
+	beginBlock := func(height int64) cmtproto.Block {
+		block := cmtproto.Block{Header: cmtproto.Header{Height: height}}
+		require.NoError(t, ch.beginBlockHandler(context.Background(), nodetypes.BeginBlockArgs{BlockID: make([]byte, 32), Block: block, LatestHeight: 10}))
+		sequence++
+		require.NoError(t, ch.handleInitiateWithdrawal(sequence, "sender", "receiver", "uinit", 100))
+		return block
+	}
+	endBlock := func(block cmtproto.Block) {
+		require.NoError(t, ch.endBlockHandler(context.Background(), nodetypes.EndBlockArgs{BlockID: make([]byte, 32), Block: block, LatestHeight: 10}))
+	}
+	leafCount := func() uint64 {
+		count, err := ch.Merkle().GetWorkingTreeLeafCount()
+		require.NoError(t, err)
+		return count
+	}
+
+	// every committed block is kept
+	endBlock(beginBlock(1))
+	height, err := ch.DiscardIncompleteBlock()
+	require.NoError(t, err)
+	require.Zero(t, height)
+	require.Equal(t, uint64(1), leafCount())
+
+	// the block stopped before the end block is discarded with its leaf
+	beginBlock(2)
+	require.Equal(t, uint64(2), leafCount())
+	height, err = ch.DiscardIncompleteBlock()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), height)
+	require.Equal(t, uint64(1), leafCount())
+	require.Empty(t, ch.batchKVs)
+
+	// the discarded block is processed again without the duplicate leaf
+	sequence--
+	endBlock(beginBlock(2))
+	require.Equal(t, uint64(2), leafCount())
+}
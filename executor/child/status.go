@@ -2,6 +2,7 @@ package child
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
@@ -18,6 +19,8 @@ type Status struct {
 	FinalizingBlockHeight    int64     `json:"finalizing_block_height"`
 	LastOutputSubmissionTime time.Time `json:"last_output_submission_time"`
 	NextOutputSubmissionTime time.Time `json:"next_output_submission_time"`
+	OutputSubmissionHalted   bool      `json:"output_submission_halted"`
+	ReadOnly                 bool      `json:"read_only"`
 }
 
 func (ch Child) GetStatus() (Status, error) {
@@ -41,13 +44,114 @@ func (ch Child) GetStatus() (Status, error) {
 
 	return Status{
 		Node:                              node.GetStatus(),
-		LastUpdatedOracleL1Height:         ch.lastUpdatedOracleL1Height,
+		LastUpdatedOracleL1Height:         ch.lastUpdatedOracleL1Height.Load(),
 		LastFinalizedDepositL1BlockHeight: ch.lastFinalizedDepositL1BlockHeight,
-		LastFinalizedDepositL1Sequence:    ch.lastFinalizedDepositL1Sequence,
+		LastFinalizedDepositL1Sequence:    ch.lastFinalizedDepositL1Sequence.Load(),
 		LastWithdrawalL2Sequence:          workingTreeLeafCount + startLeafIndex - 1,
 		WorkingTreeIndex:                  workingTreeIndex,
 		FinalizingBlockHeight:             ch.finalizingBlockHeight,
 		LastOutputSubmissionTime:          ch.lastOutputTime,
 		NextOutputSubmissionTime:          ch.nextOutputTime,
+		OutputSubmissionHalted:            ch.outputSubmissionHalted.Load(),
+		ReadOnly:                          ch.ReadOnly(),
 	}, nil
 }
+
+// OutputProgress is the progress of the output finalization, which tells when the withdrawals are claimable.
+type OutputProgress struct {
+	LastOutputIndex uint64    `json:"last_output_index"`
+	LastOutputRoot  []byte    `json:"last_output_root"`
+	LastOutputTime  time.Time `json:"last_output_time"`
+	NextOutputTime  time.Time `json:"next_output_time"`
+
+	WorkingTreeIndex     uint64 `json:"working_tree_index"`
+	WorkingTreeLeafCount uint64 `json:"working_tree_leaf_count"`
+	// PendingWithdrawals is the number of the withdrawals which are not finalized yet.
+	PendingWithdrawals uint64 `json:"pending_withdrawals"`
+	// HeightLag is the number of the l2 blocks behind the chain tip.
+	HeightLag int64 `json:"height_lag"`
+	// DeferredOutputs is the number of the outputs waiting for the proposer to be funded.
+	DeferredOutputs int `json:"deferred_outputs"`
+}
+
+// ChildStatus is the snapshot of the node status and the output finalization progress.
+type ChildStatus struct {
+	nodetypes.NodeStatus
+	OutputProgress
+
+	// ReadOnly is true if the child only tracks the withdrawals in the emergency of the bridge.
+	ReadOnly bool `json:"read_only"`
+}
+
+// outputProgress keeps the output progress updated by the block loop,
+// so the status can be read without blocking the loop.
+type outputProgress struct {
+	mu       *sync.RWMutex
+	progress OutputProgress
+}
+
+func newOutputProgress() *outputProgress {
+	return &outputProgress{mu: &sync.RWMutex{}}
+}
+
+func (p *outputProgress) update(fn func(*OutputProgress)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn(&p.progress)
+}
+
+func (p *outputProgress) load() OutputProgress {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.progress
+}
+
+// Status returns the snapshot of the node status and the output finalization progress for the query server.
+// It does not block the block process loop.
+func (ch *Child) Status() (ChildStatus, error) {
+	node := ch.Node()
+	if node == nil {
+		return ChildStatus{}, errors.New("node is not initialized")
+	}
+
+	nodeStatus, err := node.Status()
+	if err != nil {
+		return ChildStatus{}, err
+	}
+	return ChildStatus{
+		NodeStatus:     nodeStatus,
+		OutputProgress: ch.outputProgress.load(),
+		ReadOnly:       ch.ReadOnly(),
+	}, nil
+}
+
+// updateOutputProgress updates the working tree progress at the end of the block.
+func (ch *Child) updateOutputProgress(blockHeight int64, latestHeight int64) error {
+	workingTreeIndex, err := ch.GetWorkingTreeIndex()
+	if err != nil {
+		return err
+	}
+	workingTreeLeafCount, err := ch.GetWorkingTreeLeafCount()
+	if err != nil {
+		return err
+	}
+	done, err := ch.Merkle().IsWorkingTreeDone()
+	if err != nil {
+		return err
+	}
+
+	ch.outputProgress.update(func(progress *OutputProgress) {
+		progress.LastOutputTime = ch.lastOutputTime
+		progress.NextOutputTime = ch.nextOutputTime
+		progress.WorkingTreeIndex = workingTreeIndex
+		progress.WorkingTreeLeafCount = workingTreeLeafCount
+		progress.PendingWithdrawals = workingTreeLeafCount
+		if done {
+			progress.PendingWithdrawals = 0
+		}
+		progress.HeightLag = max(latestHeight-blockHeight, 0)
+	})
+	return nil
+}
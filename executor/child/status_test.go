@@ -0,0 +1,72 @@
+package child
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_OutputProgress(t *testing.T) {
+	ch, host := newTestChild(t)
+	host.outputs = map[uint64]ophosttypes.Output{}
+	ch.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{SubmissionInterval: time.Hour},
+	})
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	require.NoError(t, ch.Merkle().SaveWorkingTree(0))
+
+	start := time.Unix(0, 0).UTC()
+	sequence := uint64(0)
+	processBlock := func(height int64, latestHeight int64, withdrawals int) OutputProgress {
+		block := cmtproto.Block{Header: cmtproto.Header{Height: height, Time: start.Add(time.Duration(height) * time.Minute)}}
+		blockID := make([]byte, 32)
+		require.NoError(t, ch.beginBlockHandler(context.Background(), nodetypes.BeginBlockArgs{BlockID: blockID, Block: block, LatestHeight: latestHeight}))
+		for i := 0; i < withdrawals; i++ {
+			sequence++
+			require.NoError(t, ch.handleInitiateWithdrawal(sequence, "sender", "receiver", "uinit", 100))
+		}
+		require.NoError(t, ch.endBlockHandler(context.Background(), nodetypes.EndBlockArgs{BlockID: blockID, Block: block, LatestHeight: latestHeight}))
+
+		status, err := ch.Status()
+		require.NoError(t, err)
+		return status.OutputProgress
+	}
+
+	// the withdrawals are pending while catching up
+	progress := processBlock(1, 3, 2)
+	require.Equal(t, uint64(1), progress.WorkingTreeIndex)
+	require.Equal(t, uint64(2), progress.WorkingTreeLeafCount)
+	require.Equal(t, uint64(2), progress.PendingWithdrawals)
+	require.Equal(t, int64(2), progress.HeightLag)
+	require.Zero(t, progress.LastOutputIndex)
+
+	progress = processBlock(2, 3, 1)
+	require.Equal(t, uint64(3), progress.PendingWithdrawals)
+	require.Equal(t, int64(1), progress.HeightLag)
+
+	// the tree is finalized at the chain tip
+	progress = processBlock(3, 3, 0)
+	require.Equal(t, uint64(1), progress.LastOutputIndex)
+	require.Len(t, progress.LastOutputRoot, 32)
+	require.Equal(t, start.Add(3*time.Minute), progress.LastOutputTime)
+	require.Equal(t, start.Add(43*time.Minute), progress.NextOutputTime)
+	require.Equal(t, uint64(3), progress.WorkingTreeLeafCount)
+	require.Zero(t, progress.PendingWithdrawals)
+	require.Zero(t, progress.HeightLag)
+
+	// the next tree starts
+	progress = processBlock(4, 4, 1)
+	require.Equal(t, uint64(2), progress.WorkingTreeIndex)
+	require.Equal(t, uint64(1), progress.WorkingTreeLeafCount)
+	require.Equal(t, uint64(1), progress.PendingWithdrawals)
+	require.Equal(t, uint64(1), progress.LastOutputIndex)
+}
@@ -4,30 +4,98 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+	"github.com/initia-labs/opinit-bots/db"
 	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	"github.com/initia-labs/opinit-bots/types"
 	"go.uber.org/zap"
 
 	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	childprovider "github.com/initia-labs/opinit-bots/provider/child"
 )
 
-func (ch *Child) initiateWithdrawalHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
+func (ch *Child) initiateWithdrawalHandler(ctx context.Context, args nodetypes.EventHandlerArgs) error {
 	l2Sequence, amount, from, to, baseDenom, err := childprovider.ParseInitiateWithdrawal(args.EventAttributes)
 	if err != nil {
 		return err
 	}
+	err = ch.checkWithdrawalSequence(ctx, args.BlockHeight, l2Sequence)
+	if err != nil {
+		return err
+	}
+	return ch.handleInitiateWithdrawal(l2Sequence, from, to, baseDenom, amount)
+}
+
+// checkWithdrawalSequence checks the l2 sequence of the withdrawal is the next sequence of the working tree.
+// Otherwise, the following leaves are mapped to the wrong sequences, so the processing halts with
+// WithdrawalSequenceGapError, unless the missing withdrawals are backfilled from the chain.
+func (ch *Child) checkWithdrawalSequence(ctx context.Context, blockHeight int64, l2Sequence uint64) error {
+	startLeafIndex, err := ch.GetStartLeafIndex()
+	if err != nil {
+		return err
+	}
+	workingTreeLeafCount, err := ch.GetWorkingTreeLeafCount()
+	if err != nil {
+		return err
+	}
+
+	expected := startLeafIndex + workingTreeLeafCount
+	if l2Sequence == expected {
+		return nil
+	}
+
+	gapErr := &types.WithdrawalSequenceGapError{
+		Expected: expected,
+		Received: l2Sequence,
+		Height:   blockHeight,
+	}
+	if l2Sequence < expected || !types.RepairWithdrawalGap(ctx) {
+		ch.Logger().Error("withdrawal sequence gap; halt processing",
+			zap.Int64("height", blockHeight),
+			zap.Uint64("expected", expected),
+			zap.Uint64("received", l2Sequence),
+		)
+		return gapErr
+	}
+
+	for sequence := expected; sequence < l2Sequence; sequence++ {
+		err = ch.backfillWithdrawal(ctx, sequence)
+		if err != nil {
+			return errors.Join(gapErr, err)
+		}
+	}
+	return nil
+}
+
+// backfillWithdrawal inserts the missing withdrawal of the given l2 sequence, which is searched from the chain.
+func (ch *Child) backfillWithdrawal(ctx context.Context, l2Sequence uint64) error {
+	eventAttrs, err := ch.QueryWithdrawalEvent(ctx, l2Sequence)
+	if err != nil {
+		return err
+	}
+	sequence, amount, from, to, baseDenom, err := childprovider.ParseInitiateWithdrawal(eventAttrs)
+	if err != nil {
+		return err
+	} else if sequence != l2Sequence {
+		return fmt.Errorf("unexpected withdrawal sequence; expected: %d, got: %d", l2Sequence, sequence)
+	}
+
+	ch.Logger().Warn("backfill missing withdrawal", zap.Uint64("l2_sequence", l2Sequence))
 	return ch.handleInitiateWithdrawal(l2Sequence, from, to, baseDenom, amount)
 }
 
 func (ch *Child) handleInitiateWithdrawal(l2Sequence uint64, from string, to string, baseDenom string, amount uint64) error {
-	withdrawalHash := ophosttypes.GenerateWithdrawalHash(ch.BridgeId(), l2Sequence, from, to, baseDenom, amount)
+	withdrawalHash, err := executortypes.WithdrawalHash(executortypes.WithdrawalHashVersion1, ch.BridgeId(), l2Sequence, from, to, baseDenom, amount)
+	if err != nil {
+		return err
+	}
 	data := executortypes.WithdrawalData{
 		Sequence:       l2Sequence,
 		From:           from,
@@ -35,6 +103,8 @@ func (ch *Child) handleInitiateWithdrawal(l2Sequence uint64, from string, to str
 		Amount:         amount,
 		BaseDenom:      baseDenom,
 		WithdrawalHash: withdrawalHash[:],
+		BelowMinimum:   amount < ch.minWithdrawalAmounts[baseDenom],
+		HashVersion:    executortypes.WithdrawalHashVersion1,
 	}
 
 	// store to database
@@ -57,11 +127,18 @@ func (ch *Child) handleInitiateWithdrawal(l2Sequence uint64, from string, to str
 		zap.Uint64("amount", amount),
 		zap.String("base_denom", baseDenom),
 		zap.String("withdrawal", base64.StdEncoding.EncodeToString(withdrawalHash[:])),
+		zap.Bool("below_minimum", data.BelowMinimum),
 	)
 
 	return nil
 }
 
+// SetMinWithdrawalAmounts sets the minimum withdrawal amounts by the base denom.
+// The withdrawals below the minimum are flagged when they are stored.
+func (ch *Child) SetMinWithdrawalAmounts(minWithdrawalAmounts map[string]uint64) {
+	ch.minWithdrawalAmounts = minWithdrawalAmounts
+}
+
 func (ch *Child) prepareTree(blockHeight int64) error {
 	err := ch.Merkle().LoadWorkingTree(types.MustInt64ToUint64(blockHeight) - 1)
 	if err == dbtypes.ErrNotFound {
@@ -91,7 +168,11 @@ func (ch *Child) prepareOutput(ctx context.Context) error {
 			return fmt.Errorf("output does not exist at index: %d", workingTreeIndex-1)
 		}
 		ch.lastOutputTime = output.OutputProposal.L1BlockTime
-		ch.nextOutputTime = output.OutputProposal.L1BlockTime.Add(ch.BridgeInfo().BridgeConfig.SubmissionInterval * 2 / 3)
+		ch.outputProgress.update(func(progress *OutputProgress) {
+			progress.LastOutputIndex = output.OutputIndex
+			progress.LastOutputRoot = output.OutputProposal.OutputRoot
+		})
+		ch.nextOutputTime = output.OutputProposal.L1BlockTime.Add(ch.outputSubmission.Interval(ch.BridgeInfo().BridgeConfig.SubmissionInterval))
 	}
 
 	output, err := ch.host.QueryOutput(ctx, ch.BridgeId(), workingTreeIndex, 0)
@@ -107,55 +188,107 @@ func (ch *Child) prepareOutput(ctx context.Context) error {
 	return nil
 }
 
-func (ch *Child) handleTree(blockHeight int64, latestHeight int64, blockId []byte, blockHeader cmtproto.Header) (kvs []types.RawKV, storageRoot []byte, err error) {
+// outputTriggerReason returns the reason to finalize the working tree at the block, or an empty string
+// if the tree is not finalized. The tree is finalized at the block of the output already submitted while
+// syncing, and when we are fully synced and the block time is over the next output time or the working
+// tree has too many withdrawals after the min interval.
+func (ch *Child) outputTriggerReason(blockHeight int64, latestHeight int64, blockTime time.Time) (string, error) {
+	if ch.finalizingBlockHeight == blockHeight {
+		return executortypes.OutputTriggerSync, nil
+	} else if ch.finalizingBlockHeight != 0 || blockHeight != latestHeight {
+		return "", nil
+	} else if blockTime.After(ch.nextOutputTime) {
+		return executortypes.OutputTriggerInterval, nil
+	}
+
+	maxLeaves := ch.outputSubmission.MaxLeavesPerOutput
+	minOutputTime := ch.lastOutputTime.Add(time.Duration(ch.outputSubmission.MinInterval) * time.Second)
+	if maxLeaves == 0 || blockTime.Before(minOutputTime) {
+		return "", nil
+	}
+
+	workingTreeLeafCount, err := ch.GetWorkingTreeLeafCount()
+	if err != nil {
+		return "", err
+	} else if workingTreeLeafCount >= maxLeaves {
+		return executortypes.OutputTriggerMaxLeaves, nil
+	}
+	return "", nil
+}
+
+// handleTree adds the finalized tree, if the working tree is finalized at the block, and the working tree to the txn.
+func (ch *Child) handleTree(txn *db.Txn, blockHeight int64, latestHeight int64, blockId []byte, blockHeader cmtproto.Header) (storageRoot []byte, err error) {
 	// panic if we are syncing and passed the finalizing block height
 	// this must not happened
 	if ch.finalizingBlockHeight != 0 && ch.finalizingBlockHeight < blockHeight {
 		panic(fmt.Errorf("INVARIANT failed; handleTree expect to finalize tree at block `%d` but we got block `%d`", blockHeight-1, blockHeight))
 	}
 
-	// finalize working tree if we are fully synced or block time is over next output time
-	if ch.finalizingBlockHeight == blockHeight ||
-		(ch.finalizingBlockHeight == 0 &&
-			blockHeight == latestHeight &&
-			blockHeader.Time.After(ch.nextOutputTime)) {
+	triggerReason, err := ch.outputTriggerReason(blockHeight, latestHeight, blockHeader.Time)
+	if err != nil {
+		return nil, err
+	}
 
-		data, err := json.Marshal(executortypes.TreeExtraData{
-			BlockNumber: blockHeight,
-			BlockHash:   blockId,
-		})
-		if err != nil {
-			return nil, nil, err
+	if triggerReason != "" {
+		extraData := executortypes.TreeExtraData{
+			BlockNumber:           blockHeight,
+			BlockHash:             blockId,
+			TriggerReason:         triggerReason,
+			OutputRootVersion:     ch.Version(),
+			WithdrawalHashVersion: executortypes.WithdrawalHashVersion1,
 		}
-
-		kvs, storageRoot, err = ch.Merkle().FinalizeWorkingTree(data)
+		kvs, root, err := ch.Merkle().FinalizeWorkingTreeFn(func(root []byte) ([]byte, error) {
+			outputRoot, err := executortypes.ComputeOutputRoot(extraData.OutputRootVersion, root, blockId)
+			if err != nil {
+				return nil, err
+			}
+			extraData.OutputRoot = outputRoot[:]
+			return json.Marshal(extraData)
+		})
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
+		txn.Add(db.TxnEntryFinalizedTree, kvs...)
+		storageRoot = root
 
 		workingTreeIndex, err := ch.GetWorkingTreeIndex()
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		workingTreeLeafCount, err := ch.GetWorkingTreeLeafCount()
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		startLeafIndex, err := ch.GetStartLeafIndex()
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
-		ch.Logger().Info("finalize working tree",
+		ch.Merkle().Logger().Info("finalize working tree",
 			zap.Uint64("tree_index", workingTreeIndex),
 			zap.Int64("height", blockHeight),
 			zap.Uint64("start_leaf_index", startLeafIndex),
 			zap.Uint64("num_leaves", workingTreeLeafCount),
 			zap.String("storage_root", base64.StdEncoding.EncodeToString(storageRoot)),
+			zap.String("trigger_reason", triggerReason),
 		)
 
+		// the empty tree is not stored, so the output root is not computed while finalizing
+		outputRoot := extraData.OutputRoot
+		if outputRoot == nil {
+			root, err := executortypes.ComputeOutputRoot(extraData.OutputRootVersion, storageRoot, blockId)
+			if err != nil {
+				return nil, err
+			}
+			outputRoot = root[:]
+		}
+		ch.outputProgress.update(func(progress *OutputProgress) {
+			progress.LastOutputIndex = workingTreeIndex
+			progress.LastOutputRoot = outputRoot
+		})
+
 		// skip output submission when it is already submitted
 		if ch.finalizingBlockHeight == blockHeight {
 			storageRoot = nil
@@ -163,31 +296,124 @@ func (ch *Child) handleTree(blockHeight int64, latestHeight int64, blockId []byt
 
 		ch.finalizingBlockHeight = 0
 		ch.lastOutputTime = blockHeader.Time
-		ch.nextOutputTime = blockHeader.Time.Add(ch.BridgeInfo().BridgeConfig.SubmissionInterval * 2 / 3)
+		ch.nextOutputTime = blockHeader.Time.Add(ch.outputSubmission.Interval(ch.BridgeInfo().BridgeConfig.SubmissionInterval))
+	}
+
+	// the working tree is saved atomically with the other kvs of the block
+	workingTreeKV, err := ch.Merkle().WorkingTreeToRawKV(types.MustInt64ToUint64(blockHeight))
+	if err != nil {
+		return nil, err
 	}
+	txn.Add(db.TxnEntryWorkingTree, workingTreeKV)
+
+	return storageRoot, nil
+}
 
-	version := types.MustInt64ToUint64(blockHeight)
-	err = ch.Merkle().SaveWorkingTree(version)
+func (ch *Child) handleOutput(ctx context.Context, blockHeight int64, version uint8, blockId []byte, outputIndex uint64, storageRoot []byte) error {
+	outputRoot, err := executortypes.ComputeOutputRoot(version, storageRoot, blockId)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	return kvs, storageRoot, nil
+	output := executortypes.DeferredOutput{
+		OutputIndex:   outputIndex,
+		L2BlockNumber: blockHeight,
+		OutputRoot:    outputRoot[:],
+	}
+	// the outputs are proposed after the read-only mode is exited
+	if ch.ReadOnly() {
+		ch.Logger().Warn("child is in read-only mode; defer proposing output",
+			zap.Uint64("output_index", outputIndex),
+			zap.Int64("height", blockHeight),
+		)
+		ch.deferOutput(output)
+		return nil
+	}
+	// the outputs are proposed in order, so the output waits for the deferred outputs
+	if len(ch.deferredOutputs) == 0 {
+		proposed, err := ch.proposeOutput(ctx, output)
+		if err != nil || proposed {
+			return err
+		}
+	}
+	ch.deferOutput(output)
+	return nil
+}
+
+// SetOutputSubmissionConfig sets the triggers of the output submission.
+func (ch *Child) SetOutputSubmissionConfig(cfg executortypes.OutputSubmissionConfig) {
+	ch.outputSubmission = cfg
+}
+
+// HaltOutputSubmission stops proposing the outputs until the bot is restarted by the operator.
+func (ch *Child) HaltOutputSubmission() {
+	ch.outputSubmissionHalted.Store(true)
 }
 
-func (ch *Child) handleOutput(blockHeight int64, version uint8, blockId []byte, outputIndex uint64, storageRoot []byte) error {
-	outputRoot := ophosttypes.GenerateOutputRoot(version, storageRoot, blockId)
-	msg, sender, err := ch.host.GetMsgProposeOutput(
-		ch.BridgeId(),
-		outputIndex,
-		blockHeight,
-		outputRoot[:],
+// RewindOutput rewinds the child to the l2 block of the output right before the deleted output,
+// so the outputs from the deleted index are proposed again.
+func (ch *Child) RewindOutput(ctx context.Context, deletedOutputIndex uint64) error {
+	if deletedOutputIndex <= 1 {
+		// there is no output to rewind to, the bot should be restarted with the l2 start height
+		ch.Logger().Error("first output deleted; halt output submission", zap.Uint64("output_index", deletedOutputIndex))
+		ch.HaltOutputSubmission()
+		return nil
+	}
+
+	output, err := ch.host.QueryOutput(ctx, ch.BridgeId(), deletedOutputIndex-1, 0)
+	if err != nil {
+		return err
+	}
+
+	l2BlockNumber := types.MustUint64ToInt64(output.OutputProposal.L2BlockNumber)
+	ch.Logger().Warn("rewind to re-propose the deleted output",
+		zap.Uint64("output_index", deletedOutputIndex),
+		zap.Int64("l2_block_number", l2BlockNumber),
 	)
+	return ch.Node().Rewind(l2BlockNumber)
+}
+
+// rewindHandler rolls back the trees processed after the rewind height.
+// The withdrawals are overwritten with the same data while processing the blocks again.
+func (ch *Child) rewindHandler(_ context.Context, args nodetypes.RewindArgs) error {
+	version := types.MustInt64ToUint64(args.Height)
+	err := ch.Merkle().LoadWorkingTree(version)
+	if err != nil {
+		return err
+	}
+
+	startLeafIndex, err := ch.GetStartLeafIndex()
+	if err != nil {
+		return err
+	}
+	workingTreeLeafCount, err := ch.GetWorkingTreeLeafCount()
+	if err != nil {
+		return err
+	}
+	nextSequence := startLeafIndex + workingTreeLeafCount
+
+	err = ch.Merkle().DeleteFutureFinalizedTrees(nextSequence)
+	if err != nil {
+		return err
+	}
+	err = ch.Merkle().DeleteFutureWorkingTrees(version + 1)
+	if err != nil {
+		return err
+	}
+
+	// the outputs from the working tree are finalized again
+	workingTreeIndex, err := ch.GetWorkingTreeIndex()
+	if err != nil {
+		return err
+	}
+	err = ch.dropDeferredOutputs(workingTreeIndex)
 	if err != nil {
 		return err
-	} else if msg != nil {
-		ch.AppendMsgQueue(msg, sender)
 	}
+
+	// reload the output submission time from the host
+	ch.finalizingBlockHeight = 0
+	ch.nextOutputTime = time.Time{}
 	return nil
 }
 
@@ -203,6 +429,19 @@ func (ch *Child) GetWithdrawal(sequence uint64) (executortypes.WithdrawalData, e
 }
 
 func (ch *Child) GetSequencesByAddress(address string, offset uint64, limit uint64, descOrder bool) (sequences []uint64, next uint64, err error) {
+	return ch.paginateSequences(executortypes.PrefixedWithdrawalKeyAddress(address), func(offset uint64) []byte {
+		return executortypes.PrefixedWithdrawalKeyAddressIndex(address, offset)
+	}, offset, limit, descOrder)
+}
+
+// GetBelowMinimumSequences returns the sequences of the withdrawals below the minimum amount.
+func (ch *Child) GetBelowMinimumSequences(offset uint64, limit uint64, descOrder bool) (sequences []uint64, next uint64, err error) {
+	return ch.paginateSequences(executortypes.BelowMinimumWithdrawalKey, executortypes.PrefixedBelowMinimumWithdrawalKey, offset, limit, descOrder)
+}
+
+// paginateSequences iterates the sequences stored as the values under the prefix from the offset,
+// and returns the next offset if there are more sequences than the limit.
+func (ch *Child) paginateSequences(prefix []byte, offsetKeyFn func(uint64) []byte, offset uint64, limit uint64, descOrder bool) (sequences []uint64, next uint64, err error) {
 	if limit == 0 {
 		return nil, 0, nil
 	}
@@ -225,15 +464,15 @@ func (ch *Child) GetSequencesByAddress(address string, offset uint64, limit uint
 	if descOrder {
 		var startKey []byte
 		if offset != 0 {
-			startKey = executortypes.PrefixedWithdrawalKeyAddressIndex(address, offset)
+			startKey = offsetKeyFn(offset)
 		}
-		err = ch.DB().PrefixedReverseIterate(executortypes.PrefixedWithdrawalKeyAddress(address), startKey, fetchFn)
+		err = ch.DB().PrefixedReverseIterate(prefix, startKey, fetchFn)
 		if err != nil {
 			return nil, 0, err
 		}
 	} else {
-		startKey := executortypes.PrefixedWithdrawalKeyAddressIndex(address, offset)
-		err := ch.DB().PrefixedIterate(executortypes.PrefixedWithdrawalKeyAddress(address), startKey, fetchFn)
+		startKey := offsetKeyFn(offset)
+		err := ch.DB().PrefixedIterate(prefix, startKey, fetchFn)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -258,21 +497,129 @@ func (ch *Child) WithdrawalToRawKVs(sequence uint64, data executortypes.Withdraw
 		Key:   ch.DB().PrefixedKey(executortypes.PrefixedWithdrawalKeyAddressIndex(data.To, sequence)),
 		Value: dbtypes.FromUint64(sequence),
 	})
+
+	if data.BelowMinimum {
+		kvs = append(kvs, types.RawKV{
+			Key:   ch.DB().PrefixedKey(executortypes.PrefixedBelowMinimumWithdrawalKey(sequence)),
+			Value: dbtypes.FromUint64(sequence),
+		})
+	}
 	return kvs, nil
 }
 
+// DeleteFutureWithdrawals deletes the withdrawal data, the address index records and the below minimum
+// records from the given sequence, which are stored again when the blocks are processed.
 func (ch *Child) DeleteFutureWithdrawals(fromSequence uint64) error {
-	return ch.DB().PrefixedIterate(executortypes.WithdrawalKey, nil, func(key, _ []byte) (bool, error) {
-		if len(key) != len(executortypes.WithdrawalKey)+1+8 {
+	// the address index records are interleaved with the withdrawal data by the address,
+	// so they are collected by the sequence suffix and deleted in a batch
+	prefix := executortypes.WithdrawalKey
+	kvs := make([]types.KV, 0)
+	err := ch.DB().PrefixedIterate(prefix, nil, func(key, _ []byte) (bool, error) {
+		// all the keys end with the sequence
+		if len(key) < len(prefix)+1+8 {
 			return false, nil
 		}
 		sequence := dbtypes.ToUint64Key(key[len(key)-8:])
 		if sequence >= fromSequence {
-			err := ch.DB().Delete(key)
-			if err != nil {
-				return true, err
-			}
+			kvs = append(kvs, types.KV{Key: key, Value: nil})
 		}
 		return false, nil
 	})
+	if err != nil {
+		return err
+	}
+	err = ch.DB().BatchSet(kvs...)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.DB().PrefixedDeleteRange(executortypes.BelowMinimumWithdrawalKey, executortypes.PrefixedBelowMinimumWithdrawalKey(fromSequence), nil)
+	return err
+}
+
+// reportFutureWithdrawals summarizes the withdrawals and the finalized trees from the given sequence,
+// which are deleted by deleteFutureWithdrawals.
+func (ch *Child) reportFutureWithdrawals(fromSequence uint64) (*executortypes.DeleteFutureWithdrawalsReport, error) {
+	report := &executortypes.DeleteFutureWithdrawalsReport{
+		FromSequence: fromSequence,
+		TreeIndices:  make([]uint64, 0),
+	}
+
+	prefix := executortypes.WithdrawalKey
+	err := ch.DB().PrefixedIterate(prefix, nil, func(key, _ []byte) (bool, error) {
+		// only the keys by the sequence are counted
+		if len(key) != len(prefix)+1+8 {
+			return false, nil
+		}
+		sequence := dbtypes.ToUint64Key(key[len(key)-8:])
+		if sequence < fromSequence {
+			return false, nil
+		}
+		if report.Count == 0 || sequence < report.StartSequence {
+			report.StartSequence = sequence
+		}
+		report.EndSequence = max(report.EndSequence, sequence)
+		report.Count++
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = ch.Merkle().ReverseIterateFinalizedTrees(func(tree merkletypes.FinalizedTreeInfo) (bool, error) {
+		if tree.StartLeafIndex < fromSequence {
+			return true, nil
+		}
+		report.TreeIndices = append([]uint64{tree.TreeIndex}, report.TreeIndices...)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// deleteFutureWithdrawals deletes the withdrawals and the finalized trees from the given sequence if it is confirmed.
+// The report of the records to be deleted is logged and persisted, and the deletion is refused without the confirmation.
+func (ch *Child) deleteFutureWithdrawals(fromSequence uint64, confirmed bool) (*executortypes.DeleteFutureWithdrawalsReport, error) {
+	report, err := ch.reportFutureWithdrawals(fromSequence)
+	if err != nil {
+		return nil, err
+	} else if report.IsEmpty() {
+		return report, nil
+	}
+	report.Confirmed = confirmed
+	report.Timestamp = time.Now().UnixNano()
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return report, err
+	}
+	err = ch.DB().Set(executortypes.PrefixedDeleteFutureWithdrawalsReportKey(types.MustInt64ToUint64(report.Timestamp)), data)
+	if err != nil {
+		return report, err
+	}
+
+	fields := []zap.Field{
+		zap.Uint64("from_sequence", report.FromSequence),
+		zap.Uint64("count", report.Count),
+		zap.Uint64("start_sequence", report.StartSequence),
+		zap.Uint64("end_sequence", report.EndSequence),
+		zap.Uint64s("tree_indices", report.TreeIndices),
+	}
+	if !confirmed {
+		ch.Logger().Error("refuse to delete future withdrawals; set `confirm_destructive_rewind` or `--confirm-destructive-rewind` to proceed", fields...)
+		return report, fmt.Errorf("%w: %d withdrawals from sequence %d", types.ErrDestructiveRewindNotConfirmed, report.Count, fromSequence)
+	}
+	ch.Logger().Warn("delete future withdrawals", fields...)
+
+	err = ch.Merkle().DeleteFutureFinalizedTrees(fromSequence)
+	if err != nil {
+		return report, err
+	}
+	err = ch.DeleteFutureWithdrawals(fromSequence)
+	if err != nil {
+		return report, err
+	}
+	return report, nil
 }
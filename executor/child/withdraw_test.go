@@ -0,0 +1,476 @@
+package child
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+type mockHostNode struct {
+	hostNode
+
+	queriedOutputIndexes []uint64
+	queriedOutputsRanges [][2]uint64
+	// outputs on chain, all the outputs exist if it is nil
+	outputs map[uint64]ophosttypes.Output
+
+	// results of the output proposal validation
+	outputExists      bool
+	outputValidateErr error
+
+	// bridge configs on chain and the queued msgs rewritten by the bridge id migration
+	bridgeInfos        map[uint64]ophosttypes.QueryBridgeResponse
+	rewrittenBridgeIds [][2]uint64
+	rewriteErr         error
+
+	// the proposer can't pay the fees if it is true
+	lowBalance bool
+}
+
+func (m *mockHostNode) QueryBridgeConfig(_ context.Context, bridgeId uint64, _ int64) (*ophosttypes.QueryBridgeResponse, error) {
+	bridgeInfo, ok := m.bridgeInfos[bridgeId]
+	if !ok {
+		return nil, errors.New("collections: not found")
+	}
+	return &bridgeInfo, nil
+}
+
+func (m *mockHostNode) RewriteQueuedBridgeMsgs(oldBridgeId, newBridgeId uint64) (int, error) {
+	if m.rewriteErr != nil {
+		return 0, m.rewriteErr
+	}
+	m.rewrittenBridgeIds = append(m.rewrittenBridgeIds, [2]uint64{oldBridgeId, newBridgeId})
+	return 1, nil
+}
+
+func (m *mockHostNode) QueryOutput(_ context.Context, bridgeId uint64, outputIndex uint64, _ int64) (*ophosttypes.QueryOutputProposalResponse, error) {
+	m.queriedOutputIndexes = append(m.queriedOutputIndexes, outputIndex)
+	if m.outputs != nil {
+		output, ok := m.outputs[outputIndex]
+		if !ok {
+			return nil, errors.New("collections: not found")
+		}
+		return &ophosttypes.QueryOutputProposalResponse{BridgeId: bridgeId, OutputIndex: outputIndex, OutputProposal: output}, nil
+	}
+	return &ophosttypes.QueryOutputProposalResponse{
+		BridgeId:       bridgeId,
+		OutputIndex:    outputIndex,
+		OutputProposal: ophosttypes.Output{L2BlockNumber: outputIndex * 10},
+	}, nil
+}
+
+func (m *mockHostNode) QueryOutputs(ctx context.Context, bridgeId uint64, fromIndex uint64, limit uint64) ([]ophosttypes.QueryOutputProposalResponse, error) {
+	m.queriedOutputsRanges = append(m.queriedOutputsRanges, [2]uint64{fromIndex, limit})
+	outputs := make([]ophosttypes.QueryOutputProposalResponse, 0)
+	for outputIndex := fromIndex; outputIndex < fromIndex+limit; outputIndex++ {
+		if _, ok := m.outputs[outputIndex]; m.outputs != nil && !ok {
+			continue
+		}
+		output, err := m.QueryOutput(ctx, bridgeId, outputIndex, 0)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, *output)
+	}
+	return outputs, nil
+}
+
+func (m *mockHostNode) QueryLastOutput(ctx context.Context, bridgeId uint64, _ int64) (*ophosttypes.QueryOutputProposalResponse, error) {
+	lastIndex := uint64(0)
+	for outputIndex := range m.outputs {
+		lastIndex = max(lastIndex, outputIndex)
+	}
+	if lastIndex == 0 {
+		return nil, nil
+	}
+	return m.QueryOutput(ctx, bridgeId, lastIndex, 0)
+}
+
+func (m *mockHostNode) HasKey() bool {
+	return false
+}
+
+func (m *mockHostNode) ValidateOutputProposal(context.Context, uint64, uint64, int64, []byte) (bool, error) {
+	return m.outputExists, m.outputValidateErr
+}
+
+func (m *mockHostNode) GetMsgProposeOutput(bridgeId uint64, outputIndex uint64, l2BlockNumber int64, outputRoot []byte) (sdk.Msg, string, error) {
+	return &ophosttypes.MsgProposeOutput{BridgeId: bridgeId, OutputIndex: outputIndex}, "proposer", nil
+}
+
+func (m *mockHostNode) CheckBalance(context.Context, string) error {
+	if m.lowBalance {
+		return types.ErrInsufficientBalance
+	}
+	return nil
+}
+
+func newTestChild(t *testing.T) (*Child, *mockHostNode) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ch, err := NewChildV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	host := &mockHostNode{}
+	ch.host = host
+	ch.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+	return ch, host
+}
+
+func Test_RewindOutput(t *testing.T) {
+	ch, host := newTestChild(t)
+
+	// finalize the tree 1 at height 10 and the tree 2 at height 20
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	for height := uint64(1); height <= 20; height++ {
+		if height > 1 {
+			require.NoError(t, ch.Merkle().LoadWorkingTree(height-1))
+		}
+		leaf := [32]byte{byte(height)}
+		require.NoError(t, ch.Merkle().InsertLeaf(leaf[:]))
+		if height%10 == 0 {
+			kvs, _, err := ch.Merkle().FinalizeWorkingTree(nil)
+			require.NoError(t, err)
+			require.NoError(t, ch.DB().RawBatchSet(kvs...))
+		}
+		require.NoError(t, ch.Merkle().SaveWorkingTree(height))
+	}
+	_, _, _, _, err := ch.Merkle().GetProofs(15)
+	require.NoError(t, err)
+
+	// the output 2 is deleted, rewind to the l2 block of the output 1
+	require.NoError(t, ch.RewindOutput(context.Background(), 2))
+	require.Equal(t, []uint64{1}, host.queriedOutputIndexes)
+
+	require.NoError(t, ch.rewindHandler(context.Background(), nodetypes.RewindArgs{Height: 10, LastProcessedHeight: 20}))
+
+	// the tree 2 is rolled back while the tree 1 is kept
+	_, _, _, _, err = ch.Merkle().GetProofs(15)
+	require.ErrorIs(t, err, merkletypes.ErrUnfinalizedTree)
+	_, treeIndex, _, _, err := ch.Merkle().GetProofs(5)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), treeIndex)
+	require.Error(t, ch.Merkle().LoadWorkingTree(11))
+
+	// the next tree starts right after the tree 1
+	require.NoError(t, ch.Merkle().LoadWorkingTree(10))
+	workingTreeIndex, err := ch.GetWorkingTreeIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), workingTreeIndex)
+	startLeafIndex, err := ch.GetStartLeafIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), startLeafIndex)
+}
+
+func Test_HaltOutputSubmission(t *testing.T) {
+	ch, host := newTestChild(t)
+	blockId, storageRoot := make([]byte, 32), make([]byte, 32)
+
+	require.NoError(t, ch.handleOutput(context.Background(), 10, 1, blockId, 1, storageRoot))
+	require.Len(t, ch.GetMsgQueue()["proposer"], 1)
+	ch.EmptyMsgQueue()
+
+	// the first output can't be rewound, so the submission is halted
+	require.NoError(t, ch.RewindOutput(context.Background(), 1))
+	require.Empty(t, host.queriedOutputIndexes)
+
+	require.NoError(t, ch.handleOutput(context.Background(), 20, 1, blockId, 2, storageRoot))
+	require.Empty(t, ch.GetMsgQueue()["proposer"])
+}
+
+func Test_HandleOutputValidation(t *testing.T) {
+	ch, host := newTestChild(t)
+	blockId, storageRoot := make([]byte, 32), make([]byte, 32)
+
+	// the same output is already proposed
+	host.outputExists = true
+	require.NoError(t, ch.handleOutput(context.Background(), 10, 1, blockId, 1, storageRoot))
+	require.Empty(t, ch.GetMsgQueue()["proposer"])
+	require.False(t, ch.outputSubmissionHalted.Load())
+
+	// the query failure is returned to retry the block
+	host.outputExists = false
+	host.outputValidateErr = errors.New("connection refused")
+	require.Error(t, ch.handleOutput(context.Background(), 10, 1, blockId, 1, storageRoot))
+	require.Empty(t, ch.GetMsgQueue()["proposer"])
+
+	// valid output
+	host.outputValidateErr = nil
+	require.NoError(t, ch.handleOutput(context.Background(), 10, 1, blockId, 1, storageRoot))
+	require.Len(t, ch.GetMsgQueue()["proposer"], 1)
+	ch.EmptyMsgQueue()
+
+	// conflicting output halts the output submission
+	host.outputValidateErr = fmt.Errorf("%w: output index: 2", types.ErrOutputConflict)
+	require.NoError(t, ch.handleOutput(context.Background(), 20, 1, blockId, 2, storageRoot))
+	require.Empty(t, ch.GetMsgQueue()["proposer"])
+	require.True(t, ch.outputSubmissionHalted.Load())
+}
+
+func Test_DeferOutputOnLowBalance(t *testing.T) {
+	ch, host := newTestChild(t)
+	blockId, storageRoot := make([]byte, 32), make([]byte, 32)
+	ctx := context.Background()
+	proposedIndexes := func() []uint64 {
+		indexes := make([]uint64, 0)
+		for _, msg := range ch.GetMsgQueue()["proposer"] {
+			indexes = append(indexes, msg.(*ophosttypes.MsgProposeOutput).OutputIndex)
+		}
+		return indexes
+	}
+
+	// the outputs are deferred while the proposer can't pay the fees
+	host.lowBalance = true
+	require.NoError(t, ch.handleOutput(ctx, 10, 1, blockId, 1, storageRoot))
+	require.NoError(t, ch.proposeDeferredOutputs(ctx))
+	require.NoError(t, ch.handleOutput(ctx, 20, 1, blockId, 2, storageRoot))
+	require.Empty(t, proposedIndexes())
+	require.Len(t, ch.deferredOutputs, 2)
+	require.Equal(t, 2, ch.outputProgress.load().DeferredOutputs)
+
+	// the deferred outputs survive the restart
+	kv, err := ch.deferredOutputsToRawKV()
+	require.NoError(t, err)
+	require.NoError(t, ch.DB().RawBatchSet(kv))
+	ch.deferredOutputs = nil
+	require.NoError(t, ch.loadDeferredOutputs())
+	require.Equal(t, int64(20), ch.deferredOutputs[1].L2BlockNumber)
+
+	// the output finalized again by the retried block replaces the deferred one
+	require.NoError(t, ch.handleOutput(ctx, 20, 1, blockId, 2, storageRoot))
+	require.Len(t, ch.deferredOutputs, 2)
+
+	// the deferred outputs are proposed in order after the proposer is funded
+	host.lowBalance = false
+	require.NoError(t, ch.proposeDeferredOutputs(ctx))
+	require.NoError(t, ch.handleOutput(ctx, 30, 1, blockId, 3, storageRoot))
+	require.Equal(t, []uint64{1, 2, 3}, proposedIndexes())
+	require.Empty(t, ch.deferredOutputs)
+	ch.EmptyMsgQueue()
+
+	// the deferred outputs finalized again after the rewind are dropped
+	host.lowBalance = true
+	require.NoError(t, ch.handleOutput(ctx, 40, 1, blockId, 4, storageRoot))
+	require.NoError(t, ch.handleOutput(ctx, 50, 1, blockId, 5, storageRoot))
+	require.NoError(t, ch.dropDeferredOutputs(5))
+	require.Len(t, ch.deferredOutputs, 1)
+	require.Equal(t, uint64(4), ch.deferredOutputs[0].OutputIndex)
+}
+
+func Test_OutputSubmissionTriggers(t *testing.T) {
+	ch, _ := newTestChild(t)
+	ch.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{SubmissionInterval: time.Hour},
+	})
+	ch.SetOutputSubmissionConfig(executortypes.OutputSubmissionConfig{MaxLeavesPerOutput: 3, MinInterval: 60})
+
+	start := time.Unix(0, 0)
+	ch.lastOutputTime = start
+	ch.nextOutputTime = start.Add(40 * time.Minute)
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+
+	handleBlock := func(height, latestHeight int64, blockTime time.Time) []byte {
+		txn := db.NewTxn(ch.DB())
+		storageRoot, err := ch.handleTree(txn, height, latestHeight, make([]byte, 32), cmtproto.Header{Time: blockTime})
+		require.NoError(t, err)
+		txn.Add(db.TxnEntrySyncInfo, ch.Node().SyncInfoToRawKV(height))
+		require.NoError(t, txn.Commit())
+		return storageRoot
+	}
+	triggerReason := func(sequence uint64) string {
+		_, _, _, extraDataBytes, err := ch.Merkle().GetProofs(sequence)
+		require.NoError(t, err)
+		extraData := executortypes.TreeExtraData{}
+		require.NoError(t, json.Unmarshal(extraDataBytes, &extraData))
+		return extraData.TriggerReason
+	}
+
+	// a burst of withdrawals right after the last output doesn't violate the min interval
+	for i := 0; i < 3; i++ {
+		leaf := [32]byte{byte(i)}
+		require.NoError(t, ch.Merkle().InsertLeaf(leaf[:]))
+	}
+	require.Nil(t, handleBlock(1, 1, start.Add(30*time.Second)))
+
+	// the tree is not finalized while catching up
+	require.Nil(t, handleBlock(2, 3, start.Add(2*time.Minute)))
+
+	// finalized early after the min interval
+	require.NotNil(t, handleBlock(3, 3, start.Add(2*time.Minute)))
+	require.Equal(t, executortypes.OutputTriggerMaxLeaves, triggerReason(1))
+	require.Equal(t, start.Add(42*time.Minute), ch.nextOutputTime)
+
+	// the tree with fewer withdrawals is finalized by the interval
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(2, 4))
+	leaf := [32]byte{4}
+	require.NoError(t, ch.Merkle().InsertLeaf(leaf[:]))
+	require.Nil(t, handleBlock(4, 4, start.Add(30*time.Minute)))
+	require.NotNil(t, handleBlock(5, 5, start.Add(43*time.Minute)))
+	require.Equal(t, executortypes.OutputTriggerInterval, triggerReason(4))
+}
+
+func Test_WithdrawalSequenceGap(t *testing.T) {
+	ch, _ := newTestChild(t)
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+
+	withdrawal := func(l2Sequence uint64) nodetypes.EventHandlerArgs {
+		return nodetypes.EventHandlerArgs{
+			BlockHeight: 10,
+			EventAttributes: []abcitypes.EventAttribute{
+				{Key: opchildtypes.AttributeKeyL2Sequence, Value: fmt.Sprintf("%d", l2Sequence)},
+				{Key: opchildtypes.AttributeKeyFrom, Value: "sender"},
+				{Key: opchildtypes.AttributeKeyTo, Value: "receiver"},
+				{Key: opchildtypes.AttributeKeyBaseDenom, Value: "uinit"},
+				{Key: opchildtypes.AttributeKeyAmount, Value: "100"},
+			},
+		}
+	}
+
+	require.NoError(t, ch.initiateWithdrawalHandler(context.Background(), withdrawal(1)))
+	require.NoError(t, ch.initiateWithdrawalHandler(context.Background(), withdrawal(2)))
+
+	// the withdrawal of the sequence 3 is missed
+	err := ch.initiateWithdrawalHandler(context.Background(), withdrawal(4))
+	require.ErrorIs(t, err, types.ErrWithdrawalSequenceGap)
+	var gapErr *types.WithdrawalSequenceGapError
+	require.ErrorAs(t, err, &gapErr)
+	require.Equal(t, uint64(3), gapErr.Expected)
+	require.Equal(t, uint64(4), gapErr.Received)
+	require.Equal(t, int64(10), gapErr.Height)
+
+	// the leaf is not inserted
+	leafCount, err := ch.GetWorkingTreeLeafCount()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), leafCount)
+
+	// the withdrawal handled twice halts as well, even with the repair
+	err = ch.initiateWithdrawalHandler(types.WithRepairWithdrawalGap(context.Background(), true), withdrawal(2))
+	require.ErrorIs(t, err, types.ErrWithdrawalSequenceGap)
+
+	require.NoError(t, ch.initiateWithdrawalHandler(context.Background(), withdrawal(3)))
+}
+
+func Test_DeleteFutureWithdrawals(t *testing.T) {
+	ch, _ := newTestChild(t)
+
+	// the trees 1, 2 and 3 are finalized with 5 withdrawals each
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	for sequence := uint64(1); sequence <= 15; sequence++ {
+		if sequence > 1 {
+			require.NoError(t, ch.Merkle().LoadWorkingTree(sequence-1))
+		}
+		ch.batchKVs = ch.batchKVs[:0]
+		require.NoError(t, ch.handleInitiateWithdrawal(sequence, "sender", "receiver", "uinit", 100))
+		if sequence%5 == 0 {
+			kvs, _, err := ch.Merkle().FinalizeWorkingTree(nil)
+			require.NoError(t, err)
+			ch.batchKVs = append(ch.batchKVs, kvs...)
+		}
+		require.NoError(t, ch.DB().RawBatchSet(ch.batchKVs...))
+		require.NoError(t, ch.Merkle().SaveWorkingTree(sequence))
+	}
+
+	// the report only
+	report, err := ch.reportFutureWithdrawals(8)
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), report.Count)
+	require.Equal(t, uint64(8), report.StartSequence)
+	require.Equal(t, uint64(15), report.EndSequence)
+	require.Equal(t, []uint64{3}, report.TreeIndices)
+
+	// refused without the confirmation, while the report is persisted
+	report, err = ch.deleteFutureWithdrawals(6, false)
+	require.ErrorIs(t, err, types.ErrDestructiveRewindNotConfirmed)
+	require.Equal(t, uint64(10), report.Count)
+	require.Equal(t, []uint64{2, 3}, report.TreeIndices)
+	require.False(t, report.Confirmed)
+	_, err = ch.GetWithdrawal(6)
+	require.NoError(t, err)
+	_, _, _, _, err = ch.Merkle().GetProofs(15)
+	require.NoError(t, err)
+
+	// deleted with the confirmation
+	report, err = ch.deleteFutureWithdrawals(6, true)
+	require.NoError(t, err)
+	require.True(t, report.Confirmed)
+	_, err = ch.GetWithdrawal(6)
+	require.Error(t, err)
+	_, err = ch.GetWithdrawal(5)
+	require.NoError(t, err)
+	_, _, _, _, err = ch.Merkle().GetProofs(15)
+	require.Error(t, err)
+
+	// nothing to delete anymore
+	report, err = ch.deleteFutureWithdrawals(6, false)
+	require.NoError(t, err)
+	require.True(t, report.IsEmpty())
+
+	reports := make([]executortypes.DeleteFutureWithdrawalsReport, 0)
+	err = ch.DB().PrefixedIterate(executortypes.DeleteFutureWithdrawalsReportKey, nil, func(_, value []byte) (bool, error) {
+		var report executortypes.DeleteFutureWithdrawalsReport
+		if err := json.Unmarshal(value, &report); err != nil {
+			return true, err
+		}
+		reports = append(reports, report)
+		return false, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	require.False(t, reports[0].Confirmed)
+	require.True(t, reports[1].Confirmed)
+}
+
+func Test_WithdrawalHashVersion(t *testing.T) {
+	ch, _ := newTestChild(t)
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+
+	ch.batchKVs = ch.batchKVs[:0]
+	require.NoError(t, ch.handleInitiateWithdrawal(1, "sender", "receiver", "uinit", 100))
+	require.NoError(t, ch.DB().RawBatchSet(ch.batchKVs...))
+	withdrawal, err := ch.GetWithdrawal(1)
+	require.NoError(t, err)
+	require.Equal(t, executortypes.WithdrawalHashVersion1, withdrawal.HashVersion)
+	expected, err := executortypes.WithdrawalHash(executortypes.WithdrawalHashVersion1, 1, 1, "sender", "receiver", "uinit", 100)
+	require.NoError(t, err)
+	require.Equal(t, expected[:], withdrawal.WithdrawalHash)
+
+	// the version of the leaves is recorded in the finalized tree
+	ch.finalizingBlockHeight = 1
+	txn := db.NewTxn(ch.DB())
+	_, err = ch.handleTree(txn, 1, 10, make([]byte, 32), cmtproto.Header{})
+	require.NoError(t, err)
+	txn.Add(db.TxnEntrySyncInfo, ch.Node().SyncInfoToRawKV(1))
+	require.NoError(t, txn.Commit())
+
+	_, _, _, extraDataBytes, err := ch.Merkle().GetProofs(1)
+	require.NoError(t, err)
+	extraData := executortypes.TreeExtraData{}
+	require.NoError(t, json.Unmarshal(extraDataBytes, &extraData))
+	require.Equal(t, executortypes.WithdrawalHashVersion1, extraData.WithdrawalHashVersion)
+}
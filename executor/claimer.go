@@ -0,0 +1,258 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// claimerNextSequenceKey is the key of the next withdrawal sequence to be claimed.
+var claimerNextSequenceKey = []byte("next_sequence")
+
+type claimerHost interface {
+	BridgeId() uint64
+	QueryClaimedBulk(ctx context.Context, bridgeId uint64, withdrawalHashes [][]byte) ([]bool, error)
+	GetMsgFinalizeTokenWithdrawal(uint64, uint64, uint64, string, string, sdk.Coin, [][]byte, []byte, []byte, []byte) (sdk.Msg, string, error)
+	ProcessedMsgsToRawKV([]btypes.ProcessedMsgs, bool) ([]types.RawKV, error)
+	BroadcastMsgs(btypes.ProcessedMsgs) error
+}
+
+type claimerChild interface {
+	QueryWithdrawal(sequence uint64) (executortypes.QueryWithdrawalResponse, error)
+}
+
+// claimer claims the withdrawals on l1 when their outputs are finalized, signed by the withdrawal claimer account
+// of the executor, or the host account without it.
+// Its watermark is the next withdrawal sequence to be claimed, which is saved with the broadcasted msgs atomically,
+// so a withdrawal is submitted at most once by the claimer. The withdrawals which are already claimed, below
+// the minimum amount or not in the allowlist are skipped.
+type claimer struct {
+	cfg    executortypes.AutoClaimConfig
+	db     types.DB
+	host   claimerHost
+	child  claimerChild
+	limits btypes.MsgQueueLimits
+	logger *zap.Logger
+
+	// lastFinalizedOutputIndex is the last finalized output index notified by the host
+	lastFinalizedOutputIndex *atomic.Uint64
+	// notified wakes up the claimer loop, coalescing the notifications while it claims
+	notified chan struct{}
+}
+
+func newClaimer(cfg executortypes.AutoClaimConfig, db types.DB, host claimerHost, child claimerChild, limits btypes.MsgQueueLimits, logger *zap.Logger) *claimer {
+	return &claimer{
+		cfg:    cfg,
+		db:     db,
+		host:   host,
+		child:  child,
+		limits: limits,
+		logger: logger,
+
+		lastFinalizedOutputIndex: &atomic.Uint64{},
+		notified:                 make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes up the claimer loop with the index of the last finalized output. It is called at the end
+// of every host block, and never blocks the block processing with the queries of the claims.
+func (c *claimer) Notify(_ context.Context, lastFinalizedOutputIndex uint64) error {
+	c.lastFinalizedOutputIndex.Store(lastFinalizedOutputIndex)
+	select {
+	case c.notified <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start runs the claimer loop until the context is done. The failures are logged, and the claims are retried
+// at the next notification.
+func (c *claimer) Start(ctx context.Context) {
+	types.ErrGrp(ctx).Go(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-c.notified:
+			}
+			if err := c.Claim(ctx, c.lastFinalizedOutputIndex.Load()); err != nil {
+				c.logger.Error("failed to auto claim withdrawals", zap.String("error", err.Error()))
+			}
+		}
+	})
+}
+
+// Claim broadcasts the msgs claiming the unclaimed withdrawals from the watermark to the last one
+// of the last finalized output, within the gas budget of a block. It is run by the claimer loop after every
+// host block, so the withdrawals left by the budget are claimed after the next blocks.
+func (c *claimer) Claim(ctx context.Context, lastFinalizedOutputIndex uint64) error {
+	nextSequence, err := c.nextSequence()
+	if err != nil {
+		return err
+	}
+
+	var gasUsed uint64
+	var sender string
+	msgs := make([]sdk.Msg, 0)
+	sequence := nextSequence
+	budgetExceeded := false
+	for page := 0; page < maxClaimedWithdrawalsPages && !budgetExceeded; page++ {
+		withdrawals, err := c.finalizedWithdrawals(sequence, lastFinalizedOutputIndex)
+		if err != nil {
+			return err
+		} else if len(withdrawals) == 0 {
+			break
+		}
+
+		withdrawalHashes := make([][]byte, len(withdrawals))
+		for i, withdrawal := range withdrawals {
+			withdrawalHashes[i], err = withdrawalHash(withdrawal)
+			if err != nil {
+				return err
+			}
+		}
+		claimed, err := c.host.QueryClaimedBulk(ctx, c.host.BridgeId(), withdrawalHashes)
+		if err != nil {
+			return err
+		}
+
+		for i, withdrawal := range withdrawals {
+			if claimed[i] || !c.claimable(withdrawal) {
+				sequence = withdrawal.Sequence + 1
+				continue
+			}
+
+			msg, msgSender, err := c.host.GetMsgFinalizeTokenWithdrawal(
+				withdrawal.BridgeId,
+				withdrawal.OutputIndex,
+				withdrawal.Sequence,
+				withdrawal.From,
+				withdrawal.To,
+				withdrawal.Amount,
+				withdrawal.WithdrawalProofs,
+				withdrawal.Version,
+				withdrawal.StorageRoot,
+				withdrawal.LastBlockHash,
+			)
+			if err != nil {
+				return err
+			} else if msg == nil {
+				c.logger.Warn("skip auto claim as the host key is not set")
+				return nil
+			}
+
+			msgBytes, err := btypes.EstimateMsgSize(msg)
+			if err != nil {
+				return err
+			}
+			// a claim exceeding the budget by itself is submitted alone
+			gas := btypes.EstimateTxGas(msgBytes, 1)
+			if c.cfg.MaxGasPerBlock != 0 && len(msgs) > 0 && gasUsed+gas > c.cfg.MaxGasPerBlock {
+				budgetExceeded = true
+				break
+			}
+			gasUsed += gas
+			sender = msgSender
+			msgs = append(msgs, msg)
+			sequence = withdrawal.Sequence + 1
+		}
+
+		if len(withdrawals) < claimedWithdrawalsPageSize {
+			break
+		}
+	}
+	if sequence == nextSequence {
+		return nil
+	}
+	return c.broadcast(sender, msgs, nextSequence, sequence, gasUsed)
+}
+
+// broadcast saves the msgs in chunks with the new watermark atomically, and broadcasts them.
+func (c *claimer) broadcast(sender string, msgs []sdk.Msg, fromSequence uint64, nextSequence uint64, gasUsed uint64) error {
+	chunks, err := c.limits.SplitMsgs(msgs)
+	if err != nil {
+		return err
+	}
+	processedMsgs := make([]btypes.ProcessedMsgs, 0, len(chunks))
+	for _, chunk := range chunks {
+		processedMsgs = append(processedMsgs, btypes.ProcessedMsgs{
+			Sender:    sender,
+			Msgs:      chunk,
+			Timestamp: time.Now().UnixNano(),
+			Save:      true,
+		}.WithTraceID())
+	}
+
+	kvs, err := c.host.ProcessedMsgsToRawKV(processedMsgs, false)
+	if err != nil {
+		return err
+	}
+	kvs = append(kvs, types.RawKV{
+		Key:   c.db.PrefixedKey(claimerNextSequenceKey),
+		Value: dbtypes.FromUint64(nextSequence),
+	})
+	if err := c.db.RawBatchSet(kvs...); err != nil {
+		return err
+	}
+
+	for _, processed := range processedMsgs {
+		if err := c.host.BroadcastMsgs(processed); err != nil {
+			return err
+		}
+	}
+	c.logger.Info("auto claim withdrawals",
+		zap.Uint64("from_sequence", fromSequence),
+		zap.Uint64("next_sequence", nextSequence),
+		zap.Int("claims", len(msgs)),
+		zap.Uint64("estimated_gas", gasUsed),
+	)
+	return nil
+}
+
+// finalizedWithdrawals returns a page of the withdrawals from the sequence whose outputs are finalized.
+func (c *claimer) finalizedWithdrawals(sequence uint64, lastFinalizedOutputIndex uint64) ([]executortypes.QueryWithdrawalResponse, error) {
+	withdrawals := make([]executortypes.QueryWithdrawalResponse, 0, claimedWithdrawalsPageSize)
+	for ; len(withdrawals) < claimedWithdrawalsPageSize; sequence++ {
+		withdrawal, err := c.child.QueryWithdrawal(sequence)
+		if errors.Is(err, dbtypes.ErrNotFound) {
+			break
+		} else if err != nil {
+			return nil, err
+		} else if !withdrawal.Finalized || withdrawal.OutputIndex > lastFinalizedOutputIndex {
+			break
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+	return withdrawals, nil
+}
+
+// claimable returns whether the withdrawal is claimed by the claimer. The withdrawals of the pruned trees
+// are all claimed already, so they have no proofs.
+func (c *claimer) claimable(withdrawal executortypes.QueryWithdrawalResponse) bool {
+	if withdrawal.OutputIndex == 0 || withdrawal.BelowMinimum {
+		return false
+	}
+	return len(c.cfg.Allowlist) == 0 || slices.Contains(c.cfg.Allowlist, withdrawal.To)
+}
+
+// nextSequence returns the watermark of the claimer, 1 if none.
+func (c *claimer) nextSequence() (uint64, error) {
+	value, err := c.db.Get(claimerNextSequenceKey)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return 1, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return dbtypes.ToUint64(value)
+}
@@ -0,0 +1,227 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+type mockClaimerHost struct {
+	db          types.DB
+	claimed     map[string]bool
+	broadcasted []btypes.ProcessedMsgs
+}
+
+func (m *mockClaimerHost) BridgeId() uint64 {
+	return 1
+}
+
+func (m *mockClaimerHost) QueryClaimedBulk(_ context.Context, _ uint64, withdrawalHashes [][]byte) ([]bool, error) {
+	claimed := make([]bool, len(withdrawalHashes))
+	for i, hash := range withdrawalHashes {
+		claimed[i] = m.claimed[string(hash)]
+	}
+	return claimed, nil
+}
+
+func (m *mockClaimerHost) GetMsgFinalizeTokenWithdrawal(bridgeId uint64, outputIndex uint64, sequence uint64, from string, to string, amount sdk.Coin, proofs [][]byte, version []byte, storageRoot []byte, lastBlockHash []byte) (sdk.Msg, string, error) {
+	return &ophosttypes.MsgFinalizeTokenWithdrawal{
+		Sender:           "executor",
+		BridgeId:         bridgeId,
+		OutputIndex:      outputIndex,
+		Sequence:         sequence,
+		From:             from,
+		To:               to,
+		Amount:           amount,
+		WithdrawalProofs: proofs,
+		Version:          version,
+		StorageRoot:      storageRoot,
+		LastBlockHash:    lastBlockHash,
+	}, "executor", nil
+}
+
+func (m *mockClaimerHost) ProcessedMsgsToRawKV(msgs []btypes.ProcessedMsgs, _ bool) ([]types.RawKV, error) {
+	kvs := make([]types.RawKV, 0, len(msgs))
+	for _, msg := range msgs {
+		kvs = append(kvs, types.RawKV{Key: m.db.PrefixedKey([]byte(msg.TraceID)), Value: []byte("processed_msgs")})
+	}
+	return kvs, nil
+}
+
+func (m *mockClaimerHost) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	m.broadcasted = append(m.broadcasted, msgs)
+	return nil
+}
+
+// claimedSequences returns the sequences of the broadcasted claims and resets them.
+func (m *mockClaimerHost) claimedSequences() []uint64 {
+	sequences := make([]uint64, 0)
+	for _, processedMsgs := range m.broadcasted {
+		for _, msg := range processedMsgs.Msgs {
+			sequences = append(sequences, msg.(*ophosttypes.MsgFinalizeTokenWithdrawal).Sequence)
+		}
+	}
+	m.broadcasted = nil
+	return sequences
+}
+
+type mockClaimerChild struct {
+	withdrawals map[uint64]executortypes.QueryWithdrawalResponse
+}
+
+func (m mockClaimerChild) QueryWithdrawal(sequence uint64) (executortypes.QueryWithdrawalResponse, error) {
+	withdrawal, ok := m.withdrawals[sequence]
+	if !ok {
+		return executortypes.QueryWithdrawalResponse{}, dbtypes.ErrNotFound
+	}
+	return withdrawal, nil
+}
+
+func testClaimWithdrawal(sequence uint64, outputIndex uint64, to string) executortypes.QueryWithdrawalResponse {
+	return executortypes.QueryWithdrawalResponse{
+		BridgeId:         1,
+		OutputIndex:      outputIndex,
+		WithdrawalProofs: [][]byte{make([]byte, 32)},
+		From:             "sender",
+		To:               to,
+		Sequence:         sequence,
+		Amount:           sdk.NewCoin("uinit", math.NewInt(100)),
+		Version:          []byte{0},
+		StorageRoot:      make([]byte, 32),
+		LastBlockHash:    make([]byte, 32),
+		Finalized:        outputIndex != 0,
+	}
+}
+
+func newClaimerTest(withdrawals ...executortypes.QueryWithdrawalResponse) (*claimer, *mockClaimerHost, types.DB) {
+	db := db.NewMemDB()
+	host := &mockClaimerHost{db: db.WithPrefix([]byte(types.HostName)), claimed: make(map[string]bool)}
+	child := mockClaimerChild{withdrawals: make(map[uint64]executortypes.QueryWithdrawalResponse)}
+	for _, withdrawal := range withdrawals {
+		child.withdrawals[withdrawal.Sequence] = withdrawal
+	}
+	c := newClaimer(executortypes.AutoClaimConfig{}, db.WithPrefix([]byte(types.ClaimerName)), host, child, btypes.MsgQueueLimits{}, zap.NewNop())
+	return c, host, db
+}
+
+func Test_Claimer(t *testing.T) {
+	belowMinimum := testClaimWithdrawal(5, 2, "receiver")
+	belowMinimum.BelowMinimum = true
+	c, host, db := newClaimerTest(
+		testClaimWithdrawal(1, 1, "receiver"),
+		testClaimWithdrawal(2, 1, "receiver"),
+		testClaimWithdrawal(3, 2, "receiver"),
+		testClaimWithdrawal(4, 2, "other"),
+		belowMinimum,
+		testClaimWithdrawal(6, 2, "receiver"),
+		// the tree is not finalized yet
+		testClaimWithdrawal(7, 0, "receiver"),
+	)
+	c.cfg.Allowlist = []string{"receiver"}
+	// claimed by the user
+	claimedHash, err := withdrawalHash(testClaimWithdrawal(2, 1, "receiver"))
+	require.NoError(t, err)
+	host.claimed[string(claimedHash)] = true
+
+	require.NoError(t, c.Claim(context.Background(), 1))
+	require.Equal(t, []uint64{1}, host.claimedSequences())
+	next, err := c.nextSequence()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), next)
+
+	// the claims are saved with the watermark
+	count := 0
+	require.NoError(t, db.WithPrefix([]byte(types.HostName)).PrefixedIterate(nil, nil, func(_, _ []byte) (bool, error) {
+		count++
+		return false, nil
+	}))
+	require.Equal(t, 1, count)
+
+	// the same output is not claimed again
+	require.NoError(t, c.Claim(context.Background(), 1))
+	require.Empty(t, host.claimedSequences())
+
+	// the withdrawals not in the allowlist and below the minimum are skipped
+	require.NoError(t, c.Claim(context.Background(), 2))
+	require.Equal(t, []uint64{3, 6}, host.claimedSequences())
+	next, err = c.nextSequence()
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), next)
+
+	require.NoError(t, c.Claim(context.Background(), 2))
+	require.Empty(t, host.claimedSequences())
+
+	// the restarted claimer resumes from the watermark
+	c = newClaimer(c.cfg, c.db, host, c.child, c.limits, zap.NewNop())
+	require.NoError(t, c.Claim(context.Background(), 2))
+	require.Empty(t, host.claimedSequences())
+}
+
+func Test_ClaimerGasBudget(t *testing.T) {
+	withdrawals := make([]executortypes.QueryWithdrawalResponse, 0)
+	for sequence := uint64(1); sequence <= 5; sequence++ {
+		withdrawals = append(withdrawals, testClaimWithdrawal(sequence, 1, "receiver"))
+	}
+	c, host, _ := newClaimerTest(withdrawals...)
+
+	msg, _, err := host.GetMsgFinalizeTokenWithdrawal(1, 1, 1, "sender", "receiver", withdrawals[0].Amount, withdrawals[0].WithdrawalProofs, []byte{0}, make([]byte, 32), make([]byte, 32))
+	require.NoError(t, err)
+	msgBytes, err := btypes.EstimateMsgSize(msg)
+	require.NoError(t, err)
+	// 2 claims per block
+	c.cfg.MaxGasPerBlock = btypes.EstimateTxGas(msgBytes, 1)*2 + 1
+	c.limits = btypes.MsgQueueLimits{MaxMsgs: 1}
+
+	require.NoError(t, c.Claim(context.Background(), 1))
+	require.Len(t, host.broadcasted, 2)
+	require.Equal(t, []uint64{1, 2}, host.claimedSequences())
+
+	require.NoError(t, c.Claim(context.Background(), 1))
+	require.Equal(t, []uint64{3, 4}, host.claimedSequences())
+
+	require.NoError(t, c.Claim(context.Background(), 1))
+	require.Equal(t, []uint64{5}, host.claimedSequences())
+
+	require.NoError(t, c.Claim(context.Background(), 1))
+	require.Empty(t, host.claimedSequences())
+}
+
+func Test_ClaimerLoop(t *testing.T) {
+	c, host, _ := newClaimerTest(
+		testClaimWithdrawal(1, 1, "receiver"),
+		testClaimWithdrawal(2, 2, "receiver"),
+	)
+
+	// the notifications are coalesced until the loop claims
+	require.NoError(t, c.Notify(context.Background(), 1))
+	require.NoError(t, c.Notify(context.Background(), 2))
+	require.Len(t, c.notified, 1)
+
+	errGrp, ctx := errgroup.WithContext(context.Background())
+	ctx, cancel := context.WithCancel(types.WithErrGrp(ctx, errGrp))
+	c.Start(ctx)
+	require.Eventually(t, func() bool {
+		next, err := c.nextSequence()
+		return err == nil && next == 3
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, errGrp.Wait())
+	require.Equal(t, []uint64{1, 2}, host.claimedSequences())
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+	"github.com/initia-labs/opinit-bots/db/migration"
 	dbtypes "github.com/initia-labs/opinit-bots/db/types"
 	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
@@ -80,9 +81,24 @@ func Migration015(db types.DB) error {
 	})
 }
 
+// checkJSONMerkleTrees returns an error if the merkle trees of the db are encoded in binary by the merkle
+// schema v1, as the v0.1.9 migrations read and write the JSON trees of the older bots.
+func checkJSONMerkleTrees(merkleDB types.DB) error {
+	version, err := migration.GetSchemaVersion(merkleDB, types.MerkleName)
+	if err != nil {
+		return err
+	} else if version >= 1 {
+		return fmt.Errorf("the merkle trees are encoded in binary by the merkle schema version %d; the v0.1.9 migrations must run before `opinitd migrate schema`", version)
+	}
+	return nil
+}
+
 func Migration0191(db types.DB) error {
 	nodeDB := db.WithPrefix([]byte(types.ChildName))
 	merkleDB := nodeDB.WithPrefix([]byte(types.MerkleName))
+	if err := checkJSONMerkleTrees(merkleDB); err != nil {
+		return err
+	}
 
 	err := merkleDB.PrefixedIterate(merkletypes.FinalizedTreeKey, nil, func(key, value []byte) (bool, error) {
 		var tree merkletypes.FinalizedTreeInfo
@@ -180,6 +196,9 @@ func Migration0191(db types.DB) error {
 func Migration0192(ctx context.Context, db types.DB, rpcClient *rpcclient.RPCClient) error {
 	nodeDB := db.WithPrefix([]byte(types.ChildName))
 	merkleDB := nodeDB.WithPrefix([]byte(types.MerkleName))
+	if err := checkJSONMerkleTrees(merkleDB); err != nil {
+		return err
+	}
 
 	timer := time.NewTicker(types.PollingInterval(ctx))
 	defer timer.Stop()
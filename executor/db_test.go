@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	"github.com/initia-labs/opinit-bots/db/migration"
+	"github.com/initia-labs/opinit-bots/merkle"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_Migration0191JSONMerkleTrees(t *testing.T) {
+	db := db.NewMemDB()
+	merkleDB := db.WithPrefix([]byte(types.ChildName)).WithPrefix([]byte(types.MerkleName))
+
+	workingTree := merkletypes.TreeInfo{Index: 1, StartLeafIndex: 1, LeafCount: 1, Done: true, LastSiblings: map[uint8][]byte{0: make([]byte, 32)}}
+	data, err := json.Marshal(workingTree)
+	require.NoError(t, err)
+	require.NoError(t, merkleDB.Set(merkletypes.PrefixedWorkingTreeKey(10), data))
+
+	// the JSON trees of the older bots are migrated
+	require.NoError(t, Migration0191(db))
+	_, err = merkleDB.Get(merkletypes.PrefixedFinalizedTreeKey(1))
+	require.NoError(t, err)
+
+	// the binary trees of the merkle schema v1 are not touched
+	require.NoError(t, migration.Run(zap.NewNop(), merkle.Schema(merkleDB)))
+	require.ErrorContains(t, Migration0191(db), "must run before `opinitd migrate schema`")
+	require.ErrorContains(t, Migration0192(context.Background(), db, nil), "must run before `opinitd migrate schema`")
+
+	data, err = merkleDB.Get(merkletypes.PrefixedWorkingTreeKey(10))
+	require.NoError(t, err)
+	var migrated merkletypes.TreeInfo
+	require.NoError(t, migrated.Unmarshal(data))
+	require.Equal(t, workingTree.LeafCount, migrated.LeafCount)
+}
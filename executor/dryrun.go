@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"github.com/pkg/errors"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/node/broadcaster"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// ErrDryRunDB is returned when the bot starts in the normal mode on the db used in the dry-run mode.
+var ErrDryRunDB = errors.New("db was used in the dry-run mode; restore the db backed up before the dry run")
+
+// broadcasterNodeNames are the names of the nodes whose broadcaster data is stored in the db.
+var broadcasterNodeNames = []string{
+	types.HostName,
+	types.ChildName,
+	types.DAHostName,
+	types.DACelestiaName,
+	types.SecondaryDAHostName,
+	types.SecondaryDACelestiaName,
+}
+
+// DryRunRecords returns the msgs recorded instead of being broadcasted in the dry-run mode by the node names.
+// The nodes without records are omitted.
+func DryRunRecords(db types.DB) (map[string][]btypes.DryRunRecord, error) {
+	records := make(map[string][]btypes.DryRunRecord)
+	for _, nodeName := range broadcasterNodeNames {
+		nodeRecords, err := broadcaster.LoadDryRunRecords(db.WithPrefix([]byte(nodeName)))
+		if err != nil {
+			return nil, err
+		}
+		if len(nodeRecords) != 0 {
+			records[nodeName] = nodeRecords
+		}
+	}
+	return records, nil
+}
+
+// checkDryRunMode marks the db used in the dry-run mode, and refuses to start the normal mode on the marked db.
+// The sync info, the trees and the batch state advance in the dry-run mode without the txs being broadcasted,
+// so the normal mode on the db would skip the msgs recorded in the dry run.
+func checkDryRunMode(db types.DB, dryRun bool) error {
+	if dryRun {
+		return db.Set(executortypes.DryRunModeKey, dbtypes.FromUint64(1))
+	}
+
+	_, err := db.Get(executortypes.DryRunModeKey)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return ErrDryRunDB
+}
@@ -0,0 +1,21 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/initia-labs/opinit-bots/db"
+)
+
+func Test_CheckDryRunMode(t *testing.T) {
+	database := db.NewMemDB()
+
+	// the db is not marked until the dry run
+	require.NoError(t, checkDryRunMode(database, false))
+	require.NoError(t, checkDryRunMode(database, true))
+	require.NoError(t, checkDryRunMode(database, true))
+
+	// the normal mode refuses the db used in the dry run
+	require.ErrorIs(t, checkDryRunMode(database, false), ErrDryRunDB)
+}
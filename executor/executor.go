@@ -3,24 +3,37 @@ package executor
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/initia-labs/opinit-bots/db/integrity"
 	"github.com/initia-labs/opinit-bots/executor/batch"
 	"github.com/initia-labs/opinit-bots/executor/celestia"
 	"github.com/initia-labs/opinit-bots/executor/child"
 	"github.com/initia-labs/opinit-bots/executor/host"
+	"github.com/initia-labs/opinit-bots/logging"
+	"github.com/initia-labs/opinit-bots/node"
 	"github.com/initia-labs/opinit-bots/server"
+	"github.com/initia-labs/opinit-bots/server/health"
 
 	bottypes "github.com/initia-labs/opinit-bots/bot/types"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
 	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
 
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
 	"github.com/initia-labs/opinit-bots/types"
 	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 var _ bottypes.Bot = &Executor{}
@@ -31,50 +44,107 @@ var _ bottypes.Bot = &Executor{}
 type Executor struct {
 	host  *host.Host
 	child *child.Child
+	// submits the batches to the DA with the batch submitter enabled, nil otherwise
 	batch *batch.BatchSubmitter
+	// claims the finalized withdrawals with the auto claim enabled, nil otherwise
+	claimer *claimer
 
 	cfg    *executortypes.Config
 	db     types.DB
 	server *server.Server
+	health *health.Checker
 	logger *zap.Logger
+	// logLevels changes the levels of the component loggers at runtime
+	logLevels *logging.Levels
 
 	homePath string
+
+	// mounted is true if the api routes are mounted on the server of the process running the bridges,
+	// which also owns the db.
+	mounted bool
+
+	// shutdown is closed to stop taking new blocks and msgs on the graceful shutdown,
+	// and closed is closed when the executor is closed.
+	shutdown     chan struct{}
+	shutdownOnce *sync.Once
+	closed       chan struct{}
+	closeOnce    *sync.Once
 }
 
-func NewExecutor(cfg *executortypes.Config, db types.DB, logger *zap.Logger, homePath string) *Executor {
+func NewExecutor(cfg *executortypes.Config, db types.DB, logger *zap.Logger, logLevels *logging.Levels, homePath string) (*Executor, error) {
+	return newExecutor(cfg, db, logger, logLevels, homePath, homePath)
+}
+
+// newExecutor creates the executor whose batch files are written to the batch directory.
+func newExecutor(cfg *executortypes.Config, db types.DB, logger *zap.Logger, logLevels *logging.Levels, homePath string, batchDir string) (*Executor, error) {
 	err := cfg.Validate()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return &Executor{
-		host: host.NewHostV1(
-			cfg.L1NodeConfig(homePath),
-			db.WithPrefix([]byte(types.HostName)),
-			logger.Named(types.HostName),
-		),
-		child: child.NewChildV1(
-			cfg.L2NodeConfig(homePath),
-			db.WithPrefix([]byte(types.ChildName)),
-			logger.Named(types.ChildName),
-		),
-		batch: batch.NewBatchSubmitterV1(
+	h, err := host.NewHostV1(
+		cfg.L1NodeConfig(homePath),
+		db.WithPrefix([]byte(types.HostName)),
+		logger.Named(types.HostName),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the host: %w", err)
+	}
+	ch, err := child.NewChildV1(
+		cfg.L2NodeConfig(homePath),
+		db.WithPrefix([]byte(types.ChildName)),
+		logger.Named(types.ChildName),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the child: %w", err)
+	}
+	var bs *batch.BatchSubmitter
+	if !cfg.DisableBatchSubmitter {
+		bs, err = batch.NewBatchSubmitterV1(
 			cfg.L2NodeConfig(homePath),
 			cfg.BatchConfig(), db.WithPrefix([]byte(types.BatchName)),
-			logger.Named(types.BatchName), cfg.L2Node.ChainID, homePath,
-		),
+			logger.Named(types.BatchName), cfg.L2Node.ChainID, batchDir,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the batch submitter: %w", err)
+		}
+	}
+
+	return &Executor{
+		host:  h,
+		child: ch,
+		batch: bs,
 
 		cfg:    cfg,
 		db:     db,
 		server: server.NewServer(cfg.Server),
+		health: health.NewChecker(cfg.Server.Health, db, logger.Named("health")),
 		logger: logger,
 
+		logLevels: logLevels,
+
 		homePath: homePath,
-	}
+
+		shutdown:     make(chan struct{}),
+		shutdownOnce: &sync.Once{},
+		closed:       make(chan struct{}),
+		closeOnce:    &sync.Once{},
+	}, nil
 }
 
 func (ex *Executor) Initialize(ctx context.Context) error {
-	childBridgeInfo, err := ex.child.QueryBridgeInfo(ctx)
+	if err := checkDryRunMode(ex.db, ex.cfg.DryRun); err != nil {
+		return err
+	}
+
+	if ex.cfg.CheckDBIntegrity {
+		_, err := integrity.Run(ex.db, ex.logger, types.RepairDB(ctx), IntegrityChecks(ex.db)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	childBridgeInfo, err := ex.child.QueryBridgeInfo(ctx, 0)
 	if err != nil {
 		return err
 	}
@@ -82,7 +152,7 @@ func (ex *Executor) Initialize(ctx context.Context) error {
 		return errors.New("bridge info is not set")
 	}
 
-	bridgeInfo, err := ex.host.QueryBridgeConfig(ctx, childBridgeInfo.BridgeId)
+	bridgeInfo, err := ex.host.QueryBridgeConfig(ctx, childBridgeInfo.BridgeId, 0)
 	if err != nil {
 		return err
 	}
@@ -98,13 +168,35 @@ func (ex *Executor) Initialize(ctx context.Context) error {
 		return err
 	}
 
-	hostKeyringConfig, childKeyringConfig, childOracleKeyringConfig, daKeyringConfig := ex.getKeyringConfigs(*bridgeInfo)
+	hostKeyringConfig, childKeyringConfig, childOracleKeyringConfig, childRoutedKeyringConfigs, daKeyringConfig := ex.getKeyringConfigs(*bridgeInfo)
+
+	var expectedChainInfo *hostprovider.ExpectedChainInfo
+	if ex.cfg.SkipChainVerification {
+		ex.logger.Warn("skip verifying the chain id and the bridge of the l1 node")
+	} else {
+		expectedChainInfo = &hostprovider.ExpectedChainInfo{
+			L2ChainID:    ex.cfg.L2Node.ChainID,
+			L2BridgeInfo: childBridgeInfo,
+		}
+	}
+
+	if disabled := ex.cfg.DisabledComponents(); len(disabled) != 0 {
+		ex.logger.Info("disabled components", zap.Strings("components", disabled))
+	}
 
-	err = ex.host.Initialize(ctx, hostProcessedHeight, ex.child, ex.batch, *bridgeInfo, hostKeyringConfig)
+	ex.host.SetDepositRelayer(ex.cfg.DepositRelayerEnabled())
+	if ex.cfg.WithdrawalClaimer != "" {
+		// the claims are not paid by the proposer
+		ex.host.SetRoutedKeyringConfigs([]btypes.KeyringConfig{{
+			Name:     ex.cfg.WithdrawalClaimer,
+			MsgTypes: []string{sdk.MsgTypeURL(&ophosttypes.MsgFinalizeTokenWithdrawal{})},
+		}})
+	}
+	err = ex.host.Initialize(ctx, hostProcessedHeight, ex.child, ex.batchNode(), *bridgeInfo, expectedChainInfo, hostKeyringConfig)
 	if err != nil {
 		return err
 	}
-	err = ex.child.Initialize(
+	_, err = ex.child.Initialize(
 		ctx,
 		childProcessedHeight,
 		processedOutputIndex+1,
@@ -112,54 +204,190 @@ func (ex *Executor) Initialize(ctx context.Context) error {
 		*bridgeInfo,
 		childKeyringConfig,
 		childOracleKeyringConfig,
+		childRoutedKeyringConfigs,
 		ex.cfg.DisableDeleteFutureWithdrawal,
+		ex.cfg.ConfirmDestructiveRewind || types.ConfirmDestructiveRewind(ctx),
 	)
 	if err != nil {
 		return err
 	}
-	err = ex.batch.Initialize(ctx, batchProcessedHeight, ex.host, *bridgeInfo)
+	if ex.batch != nil {
+		err = ex.initializeBatch(ctx, batchProcessedHeight, *bridgeInfo, daKeyringConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the host relays the msgs to l2, and the child submits the msgs to l1
+	ex.host.SetMsgQueueLimits(ex.cfg.L2Node.MsgQueueLimits())
+	ex.host.SetStandbyProposerKeys(ex.cfg.StandbyProposerKeys)
+	ex.host.SetOracleRelayInterval(time.Duration(ex.cfg.OracleRelayInterval) * time.Second)
+	ex.host.SetOracleMaxStaleness(ex.cfg.OracleMaxStaleness)
+	ex.host.SetOracleVerification(!ex.cfg.DisableOracleVerification)
+	ex.child.SetMsgQueueLimits(ex.cfg.L1Node.MsgQueueLimits())
+	ex.child.SetOutputSubmissionConfig(ex.cfg.OutputSubmission)
+	ex.child.SetMinWithdrawalAmounts(ex.cfg.MinWithdrawalAmounts)
+
+	// propagate the bridge config updates on the host chain to the child and the batch submitter
+	ex.host.RegisterBridgeInfoUpdateHandler(func(bridgeInfo ophosttypes.QueryBridgeResponse) {
+		ex.child.SetBridgeInfo(bridgeInfo)
+		if ex.batch != nil {
+			ex.batch.SetBridgeInfo(bridgeInfo)
+		}
+	})
+	// propagate the bridge info migrations of the opchild module to the host and the batch submitter
+	ex.child.RegisterBridgeInfoUpdateHandler(func(bridgeInfo ophosttypes.QueryBridgeResponse) {
+		ex.host.SetBridgeInfo(bridgeInfo)
+		if ex.batch != nil {
+			ex.batch.SetBridgeInfo(bridgeInfo)
+		}
+	})
+	// re-propose or halt the output submission when the outputs are deleted by the challenger
+	ex.host.RegisterOutputDeletedHandler(func(ctx context.Context, outputIndex uint64) error {
+		if ex.cfg.HaltOnOutputDeletion {
+			ex.child.HaltOutputSubmission()
+			return nil
+		}
+		return ex.child.RewindOutput(ctx, outputIndex)
+	})
+	// cross-check the output roots of our proposals with the roots reported by the host chain
+	ex.host.RegisterOutputProposedHandler(ex.child.VerifyProposedOutput)
+	if ex.cfg.AutoClaim.Enabled {
+		ex.claimer = newClaimer(
+			ex.cfg.AutoClaim,
+			ex.db.WithPrefix([]byte(types.ClaimerName)),
+			ex.host, ex.child,
+			ex.cfg.L1Node.MsgQueueLimits(),
+			ex.logger.Named(types.ClaimerName),
+		)
+		// the claims are queried and broadcasted by the claimer loop, not to block the host blocks
+		ex.host.RegisterOutputFinalizedHandler(ex.claimer.Notify)
+	}
+	ex.RegisterQuerier()
+	err = ex.registerRestartHandlers()
 	if err != nil {
 		return err
 	}
 
-	da, err := ex.makeDANode(ctx, *bridgeInfo, daKeyringConfig)
+	stats, err := ex.GetDBStats()
 	if err != nil {
 		return err
 	}
-	ex.batch.SetDANode(da)
-	ex.RegisterQuerier()
+	ex.logger.Info("db stats", zap.Any("stats", stats))
 	return nil
 }
 
+// initializeBatch initializes the batch submitter with the DA nodes of the batch info.
+func (ex *Executor) initializeBatch(ctx context.Context, batchProcessedHeight int64, bridgeInfo ophosttypes.QueryBridgeResponse, daKeyringConfig *btypes.KeyringConfig) error {
+	err := ex.batch.Initialize(ctx, batchProcessedHeight, ex.host, bridgeInfo)
+	if err != nil {
+		return err
+	}
+
+	batchInfo := ex.batch.BatchInfo()
+	if batchInfo == nil {
+		return errors.New("batch info is not set")
+	}
+	da, err := ex.makeDANode(ctx, bridgeInfo, *batchInfo, daKeyringConfig)
+	if err != nil {
+		return err
+	}
+	secondary, err := ex.makeSecondaryDANode(ctx, bridgeInfo, daKeyringConfig)
+	if err != nil {
+		return err
+	}
+	err = ex.batch.SetDANode(da, secondary)
+	if err != nil {
+		return err
+	}
+	// switch the DA node when the batch info is updated by the host events
+	ex.batch.SetDANodeFactory(func(ctx context.Context, batchInfo ophosttypes.BatchInfoWithOutput) (executortypes.DANode, error) {
+		da, err := ex.makeDANode(ctx, ex.batch.BridgeInfo(), batchInfo, daKeyringConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		// the batches must be signed by the submitter of the batch info
+		if account, ok := da.(interface{ BaseAccountAddressString() (string, error) }); ok {
+			address, err := account.BaseAccountAddressString()
+			if err != nil {
+				return nil, err
+			} else if address != batchInfo.BatchInfo.Submitter {
+				return nil, fmt.Errorf("DA key address %s does not match the batch submitter %s; update the DA key in the config", address, batchInfo.BatchInfo.Submitter)
+			}
+		}
+		return da, nil
+	})
+	return nil
+}
+
+// batchNode returns the batch submitter whose batch info is updated by the host events,
+// or nil without the batch submitter.
+func (ex *Executor) batchNode() interface {
+	UpdateBatchInfo(string, string, uint64, int64)
+} {
+	if ex.batch == nil {
+		return nil
+	}
+	return ex.batch
+}
+
 func (ex *Executor) Start(ctx context.Context) error {
 	defer ex.Close()
 
+	ctx = types.WithShutdown(ctx, ex.shutdown)
 	errGrp := types.ErrGrp(ctx)
-	errGrp.Go(func() (err error) {
-		<-ctx.Done()
-		return ex.server.Shutdown()
-	})
-
-	errGrp.Go(func() (err error) {
-		defer func() {
-			ex.logger.Info("api server stopped")
-		}()
-		return ex.server.Start()
-	})
+	if !ex.mounted {
+		errGrp.Go(func() (err error) {
+			<-ctx.Done()
+			return ex.server.Shutdown()
+		})
+
+		errGrp.Go(func() (err error) {
+			defer func() {
+				ex.logger.Info("api server stopped")
+			}()
+			return ex.server.Start()
+		})
+	}
 	ex.host.Start(ctx)
 	ex.child.Start(ctx)
-	ex.batch.Start(ctx)
-	ex.batch.DA().Start(ctx)
+	// the batch node doesn't process the blocks at all without the batch submitter
+	if ex.batch != nil {
+		ex.batch.Start(ctx)
+		ex.batch.DA().Start(ctx)
+		if ex.batch.SecondaryDA() != nil {
+			ex.batch.SecondaryDA().Start(ctx)
+		}
+	}
+	if ex.claimer != nil {
+		ex.claimer.Start(ctx)
+	}
+	if ex.cfg.Pruning.Interval > 0 {
+		ex.newPruner().Start(ctx)
+	}
+	ex.health.Start(ctx)
 	return errGrp.Wait()
 }
 
 func (ex *Executor) Close() {
-	ex.batch.Close()
-	ex.db.Close()
+	ex.closeOnce.Do(func() {
+		if ex.batch != nil {
+			ex.batch.Close()
+		}
+		_ = ex.host.Node().Close()
+		_ = ex.child.Node().Close()
+		if !ex.mounted {
+			ex.db.Close()
+		}
+		close(ex.closed)
+	})
 }
 
 func (ex *Executor) RegisterQuerier() {
-	ex.server.RegisterQuerier("/withdrawal/:sequence", func(c *fiber.Ctx) error {
+	ex.server.RegisterQuerier("/withdrawal/:sequence", withdrawalHandler(ex.QueryWithdrawal))
+
+	ex.server.RegisterAdminHandler(fiber.MethodPost, "/admin/withdrawal/:sequence/claim", func(c *fiber.Ctx) error {
 		sequenceStr := c.Params("sequence")
 		if sequenceStr == "" {
 			return errors.New("sequence is required")
@@ -168,65 +396,302 @@ func (ex *Executor) RegisterQuerier() {
 		if err != nil {
 			return err
 		}
-		res, err := ex.child.QueryWithdrawal(sequence)
+		res, err := ex.ClaimWithdrawal(c.UserContext(), sequence)
 		if err != nil {
 			return err
 		}
 		return c.JSON(res)
 	})
 
-	ex.server.RegisterQuerier("/withdrawals/:address", func(c *fiber.Ctx) error {
-		address := c.Params("address")
-		if address == "" {
-			return errors.New("address is required")
+	// operator override of the read-only mode of the child
+	ex.server.RegisterAdminHandler(fiber.MethodPost, "/admin/child/read_only/enter", func(c *fiber.Ctx) error {
+		err := ex.child.EnterReadOnlyMode(c.Query("reason", "operator override"))
+		if err != nil {
+			return err
 		}
+		return c.JSON(ex.child.ReadOnly())
+	})
+	ex.server.RegisterAdminHandler(fiber.MethodPost, "/admin/child/read_only/exit", func(c *fiber.Ctx) error {
+		err := ex.child.ExitReadOnlyMode(c.Query("reason", "operator override"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(ex.child.ReadOnly())
+	})
+
+	ex.server.RegisterQuerier("/withdrawals", withdrawalsHandler(ex.QueryWithdrawals))
+	ex.server.RegisterQuerier("/withdrawals/:address", withdrawalsHandler(ex.QueryWithdrawals))
 
-		offset := c.QueryInt("offset", 0)
-		uoffset, err := types.SafeInt64ToUint64(int64(offset))
+	ex.server.RegisterQuerier("/below_minimum_withdrawals", func(c *fiber.Ctx) error {
+		offset, limit, descOrder, err := paginationParams(c)
 		if err != nil {
 			return err
 		}
+		res, err := ex.QueryBelowMinimumWithdrawals(c.UserContext(), offset, limit, descOrder)
+		if err != nil {
+			return err
+		}
+		return c.JSON(res)
+	})
 
-		limit := c.QueryInt("limit", 10)
-		if limit > 100 {
-			limit = 100
+	ex.server.RegisterQuerier("/status", func(c *fiber.Ctx) error {
+		status, err := ex.GetStatus()
+		if err != nil {
+			return err
 		}
+		return c.JSON(status)
+	})
 
-		ulimit, err := types.SafeInt64ToUint64(int64(limit))
+	ex.server.RegisterQuerier("/status/db", func(c *fiber.Ctx) error {
+		stats, err := ex.GetDBStats()
 		if err != nil {
 			return err
 		}
+		return c.JSON(stats)
+	})
+
+	nodes := map[string]*node.Node{
+		types.HostName: ex.host.Node(),
+	}
+	for name, n := range nodes {
+		ex.server.RegisterQuerier("/status/"+name, func(c *fiber.Ctx) error {
+			status, err := n.Status()
+			if err != nil {
+				return err
+			}
+			return c.JSON(status)
+		})
+	}
 
-		descOrder := true
-		orderStr := c.Query("order", "desc")
-		if orderStr == "asc" {
-			descOrder = false
+	// the child status includes the output finalization progress
+	ex.server.RegisterQuerier("/status/"+types.ChildName, func(c *fiber.Ctx) error {
+		status, err := ex.child.Status()
+		if err != nil {
+			return err
 		}
-		res, err := ex.child.QueryWithdrawals(address, uoffset, ulimit, descOrder)
+		return c.JSON(status)
+	})
+
+	// the batch routes are not served without the batch submitter
+	if ex.batch != nil {
+		ex.registerBatchQuerier()
+	}
+
+	// the msgs parked by the broadcasters because they failed the simulation
+	broadcasterNodes := map[string]*node.Node{
+		types.HostName:  ex.host.Node(),
+		types.ChildName: ex.child.Node(),
+	}
+	ex.server.RegisterQuerier("/deadletter", func(c *fiber.Ctx) error {
+		deadLetters := make(map[string][]btypes.DeadLetter)
+		for name, n := range broadcasterNodes {
+			nodeDeadLetters, err := n.DeadLetters()
+			if errors.Is(err, types.ErrKeyNotSet) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			deadLetters[name] = nodeDeadLetters
+		}
+		return c.JSON(deadLetters)
+	})
+	for name, n := range broadcasterNodes {
+		ex.server.RegisterQuerier("/status/"+name+"/pending-txs", func(c *fiber.Ctx) error {
+			pendingTxs, err := n.PendingTxs()
+			if errors.Is(err, types.ErrKeyNotSet) {
+				return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("no broadcaster of node: %s", name))
+			} else if err != nil {
+				return err
+			}
+			return c.JSON(pendingTxs)
+		})
+	}
+	ex.server.RegisterQuerier("/dry_run", func(c *fiber.Ctx) error {
+		records, err := DryRunRecords(ex.db)
 		if err != nil {
 			return err
 		}
-		return c.JSON(res)
+		return c.JSON(records)
+	})
+	ex.server.RegisterAdminHandler(fiber.MethodPost, "/admin/deadletter/:node/:id/retry", func(c *fiber.Ctx) error {
+		n, ok := broadcasterNodes[c.Params("node")]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("unknown node: %s", c.Params("node")))
+		}
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid id: %s", c.Params("id")))
+		}
+		processedMsgs, err := n.RetryDeadLetter(id)
+		if errors.Is(err, dbtypes.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("dead letter not found: %d", id))
+		} else if err != nil {
+			return err
+		}
+		return c.JSON(executortypes.RetryDeadLetterResponse{
+			ID:      id,
+			Sender:  processedMsgs.Sender,
+			TraceID: processedMsgs.TraceID,
+		})
 	})
 
-	ex.server.RegisterQuerier("/status", func(c *fiber.Ctx) error {
-		status, err := ex.GetStatus()
+	ex.server.RegisterAdminHandler(fiber.MethodDelete, "/admin/pending-txs/:node/:sequence", func(c *fiber.Ctx) error {
+		n, ok := broadcasterNodes[c.Params("node")]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("unknown node: %s", c.Params("node")))
+		}
+		sequence, err := strconv.ParseUint(c.Params("sequence"), 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid sequence: %s", c.Params("sequence")))
+		}
+		requeue := c.QueryBool("requeue")
+		pendingTx, resubmittedTxs, err := n.DropPendingTx(c.Query("sender"), sequence, requeue)
+		if errors.Is(err, dbtypes.ErrNotFound) || errors.Is(err, types.ErrKeyNotSet) {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("pending tx not found: %d", sequence))
+		} else if err != nil {
+			return err
+		}
+		resubmittedSequences := make([]uint64, 0, len(resubmittedTxs))
+		for _, resubmittedTx := range resubmittedTxs {
+			resubmittedSequences = append(resubmittedSequences, resubmittedTx.Sequence)
+		}
+		return c.JSON(executortypes.DropPendingTxResponse{
+			Sender:   pendingTx.Sender,
+			Sequence: pendingTx.Sequence,
+			TxHash:   pendingTx.TxHash,
+			TraceID:  pendingTx.TraceID,
+			Requeued: requeue,
+
+			ResubmittedSequences: resubmittedSequences,
+		})
+	})
+
+	// the blocks are replayed by the block process looper, which is paused during the forced replay
+	ex.server.RegisterAdminHandler(fiber.MethodPost, "/admin/replay/:node", func(c *fiber.Ctx) error {
+		n, ok := broadcasterNodes[c.Params("node")]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("unknown node: %s", c.Params("node")))
+		}
+		from, err := strconv.ParseUint(c.Query("from"), 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid from: %s", c.Query("from")))
+		}
+		to, err := strconv.ParseUint(c.Query("to"), 10, 64)
+		if err != nil || to < from {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid to: %s", c.Query("to")))
+		}
+		err = n.ReplayRange(c.UserContext(), from, to, c.QueryBool("force"))
+		if errors.Is(err, nodetypes.ErrBlockSyncInactive) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		} else if errors.Is(err, nodetypes.ErrBlockProcessRunning) {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("%s; replay with force=true to pause the block process", err.Error()))
+		} else if err != nil {
+			return err
+		}
+		return c.JSON(executortypes.ReplayResponse{
+			Node: c.Params("node"),
+			From: from,
+			To:   to,
+		})
+	})
+
+	ex.server.RegisterBackupHandler(ex.db, bottypes.BotTypeExecutor.BackupDir(ex.homePath))
+
+	// the batch node is not checked, as it is behind the chain until the batch is submitted
+	ex.health.AddNode(types.HostName, ex.host.Node())
+	ex.health.AddNode(types.ChildName, ex.child.Node())
+	ex.server.RegisterHealthHandlers(ex.health)
+	ex.server.RegisterLogLevelHandlers(ex.logLevels)
+}
+
+// registerBatchQuerier registers the routes of the batch submitter.
+func (ex *Executor) registerBatchQuerier() {
+	// the batch status includes the batch in progress and the submission progress
+	ex.server.RegisterQuerier("/status/"+types.BatchName, func(c *fiber.Ctx) error {
+		status, err := ex.batch.Status()
 		if err != nil {
 			return err
 		}
 		return c.JSON(status)
 	})
+
+	ex.server.RegisterQuerier("/batches", func(c *fiber.Ctx) error {
+		return c.JSON(ex.batch.BatchHistory())
+	})
+
+	// the finalized batches copied to the archive directory, which can be re-submitted to the DA
+	ex.server.RegisterQuerier("/batches/archive", func(c *fiber.Ctx) error {
+		archived, err := ex.batch.ArchivedBatches()
+		if errors.Is(err, batch.ErrBatchArchiveDisabled) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		} else if err != nil {
+			return err
+		}
+		return c.JSON(archived)
+	})
+	ex.server.RegisterAdminHandler(fiber.MethodPost, "/admin/batches/archive/resubmit", func(c *fiber.Ctx) error {
+		start, err := strconv.ParseUint(c.Query("start"), 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid start: %s", c.Query("start")))
+		}
+		end, err := strconv.ParseUint(c.Query("end"), 10, 64)
+		if err != nil || end < start {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid end: %s", c.Query("end")))
+		}
+		resubmitted, err := ex.batch.ResubmitArchivedBatches(start, end)
+		if errors.Is(err, batch.ErrBatchArchiveDisabled) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		} else if err != nil {
+			return err
+		}
+		return c.JSON(resubmitted)
+	})
 }
 
-func (ex *Executor) makeDANode(ctx context.Context, bridgeInfo ophosttypes.QueryBridgeResponse, daKeyringConfig *btypes.KeyringConfig) (executortypes.DANode, error) {
-	if ex.cfg.DisableBatchSubmitter {
-		return batch.NewNoopDA(), nil
+// paginationParams parses the offset, the limit up to 100 and the order of the paginated queries.
+func paginationParams(c *fiber.Ctx) (uint64, uint64, bool, error) {
+	offset, err := types.SafeInt64ToUint64(int64(c.QueryInt("offset", 0)))
+	if err != nil {
+		return 0, 0, false, err
 	}
 
-	batchInfo := ex.batch.BatchInfo()
-	if batchInfo == nil {
-		return nil, errors.New("batch info is not set")
+	limit, err := types.SafeInt64ToUint64(int64(min(c.QueryInt("limit", 10), 100)))
+	if err != nil {
+		return 0, 0, false, err
 	}
+
+	descOrder := c.Query("order", "desc") != "asc"
+	return offset, limit, descOrder, nil
+}
+
+// registerRestartHandlers logs the restart attempts of the block process loopers
+// with the failing height, so the persistent bad blocks can be spotted.
+func (ex *Executor) registerRestartHandlers() error {
+	nodes := map[string]*node.Node{
+		types.HostName:  ex.host.Node(),
+		types.ChildName: ex.child.Node(),
+	}
+	if ex.batch != nil {
+		nodes[types.BatchName] = ex.batch.Node()
+	}
+	for name, n := range nodes {
+		err := n.RegisterRestartHandler(func(_ context.Context, args nodetypes.RestartArgs) {
+			ex.logger.Warn("restart block process looper",
+				zap.String("node", name),
+				zap.Int64("height", args.Height),
+				zap.Int("attempt", args.Attempt),
+				zap.Duration("backoff", args.Backoff),
+				zap.String("error", args.Err.Error()),
+			)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ex *Executor) makeDANode(ctx context.Context, bridgeInfo ophosttypes.QueryBridgeResponse, batchInfo ophosttypes.BatchInfoWithOutput, daKeyringConfig *btypes.KeyringConfig) (executortypes.DANode, error) {
 	switch batchInfo.BatchInfo.ChainType {
 	case ophosttypes.BatchInfo_CHAIN_TYPE_INITIA:
 		// might not exist
@@ -237,29 +702,71 @@ func (ex *Executor) makeDANode(ctx context.Context, bridgeInfo ophosttypes.Query
 			return ex.host, nil
 		}
 
-		hostda := host.NewHostV1(
+		hostda, err := host.NewHostV1(
 			ex.cfg.DANodeConfig(ex.homePath),
 			ex.db.WithPrefix([]byte(types.DAHostName)),
 			ex.logger.Named(types.DAHostName),
 		)
+		if err != nil {
+			return nil, err
+		}
 		err = hostda.InitializeDA(ctx, bridgeInfo, daKeyringConfig)
 		return hostda, err
 	case ophosttypes.BatchInfo_CHAIN_TYPE_CELESTIA:
-		celestiada := celestia.NewDACelestia(ex.cfg.Version, ex.cfg.DANodeConfig(ex.homePath),
+		celestiada, err := celestia.NewDACelestia(ex.cfg.Version, ex.cfg.DANodeConfig(ex.homePath), ex.cfg.CelestiaNamespace,
 			ex.db.WithPrefix([]byte(types.DACelestiaName)),
 			ex.logger.Named(types.DACelestiaName),
 		)
-		err := celestiada.Initialize(ctx, ex.batch, bridgeInfo.BridgeId, daKeyringConfig)
 		if err != nil {
 			return nil, err
 		}
-		celestiada.RegisterDAHandlers()
+		err = celestiada.Initialize(ctx, ex.batch, bridgeInfo.BridgeId, daKeyringConfig)
+		if err != nil {
+			return nil, err
+		}
 		return celestiada, nil
 	}
 
 	return nil, fmt.Errorf("unsupported chain id for DA: %s", ophosttypes.BatchInfo_ChainType_name[int32(batchInfo.BatchInfo.ChainType)])
 }
 
+// makeSecondaryDANode creates the secondary DA node of the dual submission, or returns nil if it is disabled.
+// Unlike the primary DA node, it never shares the host node, so that its confirmations are tracked apart.
+func (ex *Executor) makeSecondaryDANode(ctx context.Context, bridgeInfo ophosttypes.QueryBridgeResponse, daKeyringConfig *btypes.KeyringConfig) (executortypes.DANode, error) {
+	if ex.cfg.DisableBatchSubmitter || !ex.cfg.DualSubmit {
+		return nil, nil
+	}
+
+	switch ex.cfg.SecondaryDAChainType {
+	case "INITIA":
+		hostda, err := host.NewHostV1(
+			ex.cfg.SecondaryDANodeConfig(ex.homePath),
+			ex.db.WithPrefix([]byte(types.SecondaryDAHostName)),
+			ex.logger.Named(types.SecondaryDAHostName),
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = hostda.InitializeDA(ctx, bridgeInfo, daKeyringConfig)
+		return hostda, err
+	case "CELESTIA":
+		celestiada, err := celestia.NewDACelestia(ex.cfg.Version, ex.cfg.SecondaryDANodeConfig(ex.homePath), ex.cfg.CelestiaNamespace,
+			ex.db.WithPrefix([]byte(types.SecondaryDACelestiaName)),
+			ex.logger.Named(types.SecondaryDACelestiaName),
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = celestiada.Initialize(ctx, ex.batch, bridgeInfo.BridgeId, daKeyringConfig)
+		if err != nil {
+			return nil, err
+		}
+		return celestiada, nil
+	}
+
+	return nil, fmt.Errorf("unsupported chain type for the secondary DA: %s", ex.cfg.SecondaryDAChainType)
+}
+
 func (ex *Executor) getProcessedHeights(ctx context.Context, bridgeId uint64) (l1ProcessedHeight int64, l2ProcessedHeight int64, processedOutputIndex uint64, batchProcessedHeight int64, err error) {
 	var outputL1BlockNumber int64
 	// get the last submitted output height before the start height from the host
@@ -321,6 +828,7 @@ func (ex *Executor) getKeyringConfigs(bridgeInfo ophosttypes.QueryBridgeResponse
 	hostKeyringConfig *btypes.KeyringConfig,
 	childKeyringConfig *btypes.KeyringConfig,
 	childOracleKeyringConfig *btypes.KeyringConfig,
+	childRoutedKeyringConfigs []btypes.KeyringConfig,
 	daKeyringConfig *btypes.KeyringConfig,
 ) {
 	if !ex.cfg.DisableOutputSubmitter {
@@ -329,17 +837,35 @@ func (ex *Executor) getKeyringConfigs(bridgeInfo ophosttypes.QueryBridgeResponse
 		}
 	}
 
-	if ex.cfg.BridgeExecutor != "" {
+	// the bridge executor relays the deposits, and grants the fees of the oracle bridge executor
+	if ex.cfg.DepositRelayerEnabled() || ex.cfg.OracleRelayerEnabled() {
 		childKeyringConfig = &btypes.KeyringConfig{
 			Name: ex.cfg.BridgeExecutor,
 		}
 
-		if bridgeInfo.BridgeConfig.OracleEnabled && ex.cfg.OracleBridgeExecutor != "" {
+		if bridgeInfo.BridgeConfig.OracleEnabled && ex.cfg.OracleRelayerEnabled() {
 			childOracleKeyringConfig = &btypes.KeyringConfig{
 				Name:       ex.cfg.OracleBridgeExecutor,
 				FeeGranter: childKeyringConfig,
+				Lanes:      []string{btypes.OracleLane},
 			}
 		}
+
+		// group the routed msg types by the key name
+		routes := make(map[string][]string)
+		for msgType, keyName := range ex.cfg.BridgeExecutorRoutes {
+			routes[keyName] = append(routes[keyName], msgType)
+		}
+		for keyName, msgTypes := range routes {
+			slices.Sort(msgTypes)
+			childRoutedKeyringConfigs = append(childRoutedKeyringConfigs, btypes.KeyringConfig{
+				Name:     keyName,
+				MsgTypes: msgTypes,
+			})
+		}
+		slices.SortFunc(childRoutedKeyringConfigs, func(a, b btypes.KeyringConfig) int {
+			return strings.Compare(a.Name, b.Name)
+		})
 	}
 
 	if !ex.cfg.DisableBatchSubmitter {
@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/keys"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// handleQuery serves the response to the query path of the chain.
+func handleQuery(t *testing.T, chain *nodetest.FakeClient, path string, res interface{ Marshal() ([]byte, error) }) {
+	chain.HandleQuery(path, func(abci.RequestQuery) (abci.ResponseQuery, error) {
+		bz, err := res.Marshal()
+		require.NoError(t, err)
+		return abci.ResponseQuery{Value: bz}, nil
+	})
+}
+
+// newTestKey creates the key of the name in the keyring of the chain and returns its address.
+func newTestKey(t *testing.T, chainID string, homePath string, name string) string {
+	cdc, _, err := keys.CreateCodec(nil)
+	require.NoError(t, err)
+	keyBase, err := keys.GetKeyBase(chainID, homePath, cdc, nil)
+	require.NoError(t, err)
+
+	mnemonic, err := keys.CreateMnemonic()
+	require.NoError(t, err)
+	record, err := keyBase.NewAccount(name, mnemonic, "", sdk.FullFundraiserPath, hd.Secp256k1)
+	require.NoError(t, err)
+	address, err := record.GetAddress()
+	require.NoError(t, err)
+	return sdk.MustBech32ifyAddressBytes("init", address)
+}
+
+// newMockChains returns the mock l1 and l2 chains serving the bridge, the oracle grant of the bridge executor,
+// the accounts and no outputs to the executor.
+func newMockChains(t *testing.T, cfg *executortypes.Config, bridgeConfig ophosttypes.BridgeConfig, executor string, oracle string) (*nodetest.FakeClient, *nodetest.FakeClient) {
+	l1 := nodetest.NewFakeClient(cfg.L1Node.ChainID)
+	l1.AppendBlocks(5)
+	l2 := nodetest.NewFakeClient(cfg.L2Node.ChainID)
+	l2.AppendBlocks(5)
+
+	handleQuery(t, l1, "/opinit.ophost.v1.Query/Bridge", &ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeAddr:   bridgeConfig.Proposer,
+		BridgeConfig: bridgeConfig,
+	})
+	l1.HandleQuery("/opinit.ophost.v1.Query/LastFinalizedOutput", func(abci.RequestQuery) (abci.ResponseQuery, error) {
+		return abci.ResponseQuery{Code: 1, Log: "output not found"}, nil
+	})
+	handleQuery(t, l1, "/opinit.ophost.v1.Query/OutputProposals", &ophosttypes.QueryOutputProposalsResponse{})
+	handleQuery(t, l1, "/opinit.ophost.v1.Query/BatchInfos", &ophosttypes.QueryBatchInfosResponse{
+		BatchInfos: []ophosttypes.BatchInfoWithOutput{{BatchInfo: bridgeConfig.BatchInfo}},
+	})
+	handleQuery(t, l2, "/opinit.opchild.v1.Query/BridgeInfo", &opchildtypes.QueryBridgeInfoResponse{BridgeInfo: opchildtypes.BridgeInfo{
+		BridgeId:     1,
+		BridgeAddr:   bridgeConfig.Proposer,
+		L1ChainId:    cfg.L1Node.ChainID,
+		BridgeConfig: bridgeConfig,
+	}})
+	handleQuery(t, l2, "/opinit.opchild.v1.Query/NextL1Sequence", &opchildtypes.QueryNextL1SequenceResponse{NextL1Sequence: 1})
+	handleQuery(t, l2, "/opinit.opchild.v1.Query/Params", &opchildtypes.QueryParamsResponse{
+		Params: opchildtypes.Params{BridgeExecutors: []string{executor}},
+	})
+
+	authorization, err := codectypes.NewAnyWithValue(authz.NewGenericAuthorization(types.MsgUpdateOracleTypeUrl))
+	require.NoError(t, err)
+	handleQuery(t, l2, "/cosmos.authz.v1beta1.Query/GranteeGrants", &authz.QueryGranteeGrantsResponse{
+		Grants:     []*authz.GrantAuthorization{{Granter: executor, Grantee: oracle, Authorization: authorization}},
+		Pagination: &query.PageResponse{},
+	})
+
+	account, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{})
+	require.NoError(t, err)
+	balance := sdk.NewInt64Coin("uinit", 1_000_000_000)
+	for _, chain := range []*nodetest.FakeClient{l1, l2} {
+		handleQuery(t, chain, "/cosmos.auth.v1beta1.Query/Account", &authtypes.QueryAccountResponse{Account: account})
+		handleQuery(t, chain, "/cosmos.bank.v1beta1.Query/Balance", &banktypes.QueryBalanceResponse{Balance: &balance})
+	}
+	return l1, l2
+}
+
+func Test_SingleComponentConfigs(t *testing.T) {
+	disableAll := func(cfg *executortypes.Config) {
+		cfg.DisableOutputSubmitter = true
+		cfg.DisableBatchSubmitter = true
+		cfg.DisableDepositRelayer = true
+		cfg.DisableOracleRelayer = true
+	}
+
+	cases := []struct {
+		name   string
+		enable func(cfg *executortypes.Config)
+
+		hostKeyring   bool
+		childKeyring  *btypes.KeyringConfig
+		oracleKeyring *btypes.KeyringConfig
+		daKeyring     bool
+		disabled      []string
+	}{
+		{
+			name:        "output submitter",
+			enable:      func(cfg *executortypes.Config) { cfg.DisableOutputSubmitter = false },
+			hostKeyring: true,
+			disabled:    []string{executortypes.ComponentBatchSubmitter, executortypes.ComponentDepositRelayer, executortypes.ComponentOracleRelayer},
+		},
+		{
+			name:      "batch submitter",
+			enable:    func(cfg *executortypes.Config) { cfg.DisableBatchSubmitter = false },
+			daKeyring: true,
+			disabled:  []string{executortypes.ComponentOutputSubmitter, executortypes.ComponentDepositRelayer, executortypes.ComponentOracleRelayer},
+		},
+		{
+			name: "deposit relayer",
+			enable: func(cfg *executortypes.Config) {
+				cfg.DisableDepositRelayer = false
+				cfg.BridgeExecutor = "executor"
+				// the oracle bridge executor is not used with the oracle relayer disabled
+				cfg.OracleBridgeExecutor = "oracle"
+			},
+			childKeyring: &btypes.KeyringConfig{Name: "executor"},
+			disabled:     []string{executortypes.ComponentOutputSubmitter, executortypes.ComponentBatchSubmitter, executortypes.ComponentOracleRelayer},
+		},
+		{
+			name: "oracle relayer",
+			enable: func(cfg *executortypes.Config) {
+				cfg.DisableOracleRelayer = false
+				cfg.BridgeExecutor = "executor"
+				cfg.OracleBridgeExecutor = "oracle"
+			},
+			childKeyring:  &btypes.KeyringConfig{Name: "executor"},
+			oracleKeyring: &btypes.KeyringConfig{Name: "oracle", FeeGranter: &btypes.KeyringConfig{Name: "executor"}, Lanes: []string{btypes.OracleLane}},
+			disabled:      []string{executortypes.ComponentOutputSubmitter, executortypes.ComponentBatchSubmitter, executortypes.ComponentDepositRelayer},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := executortypes.DefaultConfig()
+			disableAll(cfg)
+			tc.enable(cfg)
+			cfg.Server.Address = "127.0.0.1:0"
+			cfg.DisableAutoSetL1Height = true
+			cfg.L1StartHeight = 1
+
+			homePath := t.TempDir()
+			proposer := newTestKey(t, cfg.L1Node.ChainID, homePath, "proposer")
+			submitter := newTestKey(t, cfg.DANode.ChainID, homePath, "submitter")
+			executor := newTestKey(t, cfg.L2Node.ChainID, homePath, "executor")
+			oracle := newTestKey(t, cfg.L2Node.ChainID, homePath, "oracle")
+
+			bridgeConfig := ophosttypes.BridgeConfig{
+				Proposer:           proposer,
+				Challenger:         proposer,
+				SubmissionInterval: time.Minute,
+				FinalizationPeriod: time.Minute,
+				OracleEnabled:      true,
+				BatchInfo:          ophosttypes.BatchInfo{Submitter: submitter, ChainType: ophosttypes.BatchInfo_CHAIN_TYPE_INITIA},
+			}
+			l1, l2 := newMockChains(t, cfg, bridgeConfig, executor, oracle)
+			cfg.L1Node.RPCAddress = nodetest.NewServer(t, l1).URL
+			cfg.L2Node.RPCAddress = nodetest.NewServer(t, l2).URL
+			cfg.DANode.RPCAddress = cfg.L1Node.RPCAddress
+
+			ex, err := NewExecutor(cfg, db.NewMemDB(), zap.NewNop(), nil, homePath)
+			require.NoError(t, err)
+
+			// only the nodes of the enabled components broadcast the txs
+			require.Equal(t, tc.hostKeyring, ex.host.Node().HasBroadcaster())
+			require.Equal(t, tc.childKeyring != nil, ex.child.Node().HasBroadcaster())
+			// the batch submitter is not created without being enabled
+			require.Equal(t, tc.daKeyring, ex.batch != nil)
+
+			bridgeInfo := ophosttypes.QueryBridgeResponse{BridgeId: 1, BridgeConfig: bridgeConfig}
+			hostKeyring, childKeyring, oracleKeyring, _, daKeyring := ex.getKeyringConfigs(bridgeInfo)
+			require.Equal(t, tc.hostKeyring, hostKeyring != nil)
+			require.Equal(t, tc.childKeyring, childKeyring)
+			require.Equal(t, tc.oracleKeyring, oracleKeyring)
+			require.Equal(t, tc.daKeyring, daKeyring != nil)
+
+			// the status lists the disabled components
+			require.Equal(t, tc.disabled, ex.cfg.DisabledComponents())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			errGrp, ctx := errgroup.WithContext(ctx)
+			ctx = types.WithErrGrp(ctx, errGrp)
+			require.NoError(t, ex.Initialize(ctx))
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- ex.Start(ctx)
+			}()
+			// the enabled component keeps running against the mock chains until the shutdown
+			select {
+			case err := <-errCh:
+				require.FailNow(t, "executor stopped before the shutdown", err)
+			case <-time.After(500 * time.Millisecond):
+			}
+			cancel()
+			require.NoError(t, <-errCh)
+		})
+	}
+
+	// at least one component is enabled
+	cfg := executortypes.DefaultConfig()
+	disableAll(cfg)
+	_, err := NewExecutor(cfg, db.NewMemDB(), zap.NewNop(), nil, t.TempDir())
+	require.ErrorContains(t, err, "components: at least one of")
+}
@@ -33,16 +33,11 @@ func (h *Host) recordBatchHandler(_ context.Context, args nodetypes.EventHandler
 	return nil
 }
 
-func (h *Host) updateBatchInfoHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
-	bridgeId, submitter, chain, outputIndex, l2BlockNumber, err := hostprovider.ParseMsgUpdateBatchInfo(args.EventAttributes)
+func (h *Host) updateBatchInfoHandler(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+	_, submitter, chain, outputIndex, l2BlockNumber, err := hostprovider.ParseMsgUpdateBatchInfo(args.EventAttributes)
 	if err != nil {
 		return err
 	}
-	if bridgeId != h.BridgeId() {
-		// pass other bridge deposit event
-		return nil
-	}
-
 	h.Logger().Info("update batch info",
 		zap.String("chain", chain),
 		zap.String("submitter", submitter),
@@ -50,6 +45,9 @@ func (h *Host) updateBatchInfoHandler(_ context.Context, args nodetypes.EventHan
 		zap.Int64("l2_block_number", l2BlockNumber),
 	)
 
-	h.batch.UpdateBatchInfo(chain, submitter, outputIndex, l2BlockNumber)
-	return nil
+	// the batch submitter is nil if it is disabled
+	if h.batch != nil {
+		h.batch.UpdateBatchInfo(chain, submitter, outputIndex, l2BlockNumber)
+	}
+	return h.refreshBridgeInfo(ctx, args.BlockHeight)
 }
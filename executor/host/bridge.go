@@ -0,0 +1,58 @@
+package host
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
+)
+
+// RegisterBridgeInfoUpdateHandler registers the callback which is called with the new bridge info
+// whenever the bridge config is updated on the host chain.
+func (h *Host) RegisterBridgeInfoUpdateHandler(fn func(ophosttypes.QueryBridgeResponse)) {
+	h.bridgeInfoUpdateHandlers = append(h.bridgeInfoUpdateHandlers, fn)
+}
+
+func (h *Host) updateBridgeHandler(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+	_, err := hostprovider.ParseBridgeUpdate(args.EventAttributes)
+	if err != nil {
+		return err
+	}
+	return h.refreshBridgeInfo(ctx, args.BlockHeight)
+}
+
+// refreshBridgeInfo re-queries the bridge info at the given height and propagates it to the registered handlers.
+func (h *Host) refreshBridgeInfo(ctx context.Context, height int64) error {
+	bridgeInfo, err := h.QueryBridgeConfig(ctx, h.BridgeId(), height)
+	if err != nil {
+		return errors.Wrap(err, "failed to query bridge info")
+	}
+	proposerChanged := h.BridgeInfo().BridgeConfig.Proposer != bridgeInfo.BridgeConfig.Proposer
+	h.SetBridgeInfo(*bridgeInfo)
+
+	h.Logger().Info("bridge info updated",
+		zap.Int64("height", height),
+		zap.String("proposer", bridgeInfo.BridgeConfig.Proposer),
+		zap.String("challenger", bridgeInfo.BridgeConfig.Challenger),
+		zap.String("batch_chain_type", bridgeInfo.BridgeConfig.BatchInfo.ChainType.StringWithoutPrefix()),
+		zap.String("batch_submitter", bridgeInfo.BridgeConfig.BatchInfo.Submitter),
+		zap.Bool("oracle_enabled", bridgeInfo.BridgeConfig.OracleEnabled),
+	)
+
+	if proposerChanged {
+		err = h.rotateProposerKey(ctx, bridgeInfo.BridgeConfig.Proposer)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fn := range h.bridgeInfoUpdateHandlers {
+		fn(*bridgeInfo)
+	}
+	return nil
+}
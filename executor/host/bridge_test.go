@@ -0,0 +1,162 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	childprovider "github.com/initia-labs/opinit-bots/provider/child"
+)
+
+type mockBatchNode struct {
+	chain string
+}
+
+func (m *mockBatchNode) UpdateBatchInfo(chain string, _ string, _ uint64, _ int64) {
+	m.chain = chain
+}
+
+// newMockBridgeServer serves the bridge query with the current bridge info.
+func newMockBridgeServer(t *testing.T, bridgeInfo *atomic.Pointer[ophosttypes.QueryBridgeResponse]) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "abci_query" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		bz, err := bridgeInfo.Load().Marshal()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result, err := cmtjson.Marshal(&rpccoretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: bz}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  json.RawMessage(result),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_UpdateBridgeInfo(t *testing.T) {
+	initialInfo := ophosttypes.QueryBridgeResponse{
+		BridgeId: 1,
+		BridgeConfig: ophosttypes.BridgeConfig{
+			Proposer:  "proposer1",
+			BatchInfo: ophosttypes.BatchInfo{Submitter: "submitter1", ChainType: ophosttypes.BatchInfo_CHAIN_TYPE_INITIA},
+		},
+	}
+	updatedInfo := ophosttypes.QueryBridgeResponse{
+		BridgeId: 1,
+		BridgeConfig: ophosttypes.BridgeConfig{
+			Proposer:      "proposer2",
+			BatchInfo:     ophosttypes.BatchInfo{Submitter: "submitter2", ChainType: ophosttypes.BatchInfo_CHAIN_TYPE_CELESTIA},
+			OracleEnabled: true,
+		},
+	}
+
+	var bridgeInfo atomic.Pointer[ophosttypes.QueryBridgeResponse]
+	bridgeInfo.Store(&initialInfo)
+	server := newMockBridgeServer(t, &bridgeInfo)
+
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	cfg := nodetypes.NodeConfig{
+		RPC:          server.URL,
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}
+	h, err := NewHostV1(cfg, db.WithPrefix([]byte("host")), zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(initialInfo)
+	batch := &mockBatchNode{}
+	h.batch = batch
+
+	child, err := childprovider.NewBaseChildV1(cfg, db.WithPrefix([]byte("child")), zap.NewNop())
+	require.NoError(t, err)
+	child.SetBridgeInfo(initialInfo)
+	h.RegisterBridgeInfoUpdateHandler(child.SetBridgeInfo)
+
+	// readers must not race with the update
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			_ = h.BridgeId()
+			_ = h.OracleEnabled()
+			_ = child.OracleEnabled()
+		}
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	bridgeInfo.Store(&updatedInfo)
+
+	// other bridge update events are dropped by the registered handler
+	require.NoError(t, h.registerHandlers())
+	err = h.Node().EventHandler(ophosttypes.EventTypeUpdateOracle)(ctx, nodetypes.EventHandlerArgs{
+		BlockHeight:     10,
+		EventAttributes: []abci.EventAttribute{{Key: ophosttypes.AttributeKeyBridgeId, Value: "2"}},
+	})
+	require.NoError(t, err)
+	require.False(t, h.OracleEnabled())
+	require.False(t, child.OracleEnabled())
+
+	err = h.updateBridgeHandler(ctx, nodetypes.EventHandlerArgs{
+		BlockHeight:     10,
+		EventAttributes: []abci.EventAttribute{{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"}},
+	})
+	require.NoError(t, err)
+	require.True(t, h.OracleEnabled())
+	require.Equal(t, "proposer2", h.BridgeInfo().BridgeConfig.Proposer)
+	require.True(t, child.OracleEnabled())
+	require.Equal(t, updatedInfo, child.BridgeInfo())
+
+	// the batch info update also refreshes the bridge info
+	bridgeInfo.Store(&initialInfo)
+	err = h.updateBatchInfoHandler(ctx, nodetypes.EventHandlerArgs{
+		BlockHeight: 11,
+		EventAttributes: []abci.EventAttribute{
+			{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"},
+			{Key: ophosttypes.AttributeKeyBatchChainType, Value: "INITIA"},
+			{Key: ophosttypes.AttributeKeyBatchSubmitter, Value: "submitter1"},
+			{Key: ophosttypes.AttributeKeyFinalizedOutputIndex, Value: "1"},
+			{Key: ophosttypes.AttributeKeyFinalizedL2BlockNumber, Value: "100"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "INITIA", batch.chain)
+	require.Equal(t, initialInfo, h.BridgeInfo())
+	require.Equal(t, initialInfo, child.BridgeInfo())
+}
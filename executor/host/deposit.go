@@ -2,32 +2,89 @@ package host
 
 import (
 	"context"
-	"errors"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
 
 	"cosmossdk.io/math"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
+	"github.com/initia-labs/opinit-bots/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-func (h *Host) initiateDepositHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
-	bridgeId, l1Sequence, from, to, l1Denom, l2Denom, amount, data, err := hostprovider.ParseMsgInitiateDeposit(args.EventAttributes)
+// SetDepositRelayer sets whether the deposits are relayed to l2. It must be set before the initialization,
+// which registers the deposit handler only if it is enabled.
+func (h *Host) SetDepositRelayer(enabled bool) {
+	h.depositRelayer = enabled
+}
+
+func (h *Host) initiateDepositHandler(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+	_, l1Sequence, from, to, l1Denom, l2Denom, amount, data, err := hostprovider.ParseMsgInitiateDeposit(args.EventAttributes)
 	if err != nil {
 		return err
 	}
-	if bridgeId != h.BridgeId() {
-		// pass other bridge deposit event
+	// the deposits finalized on l2 are regarded as relayed, even if the relayed sequence is lost
+	lastRelayedL1Sequence := max(h.lastRelayedL1Sequence, h.child.LastFinalizedDepositL1Sequence())
+	if l1Sequence < h.initialL1Sequence || l1Sequence <= lastRelayedL1Sequence {
+		// pass old or already relayed deposit event, which is replayed after rewind
+		h.Logger().Debug("skip duplicate deposit", zap.Uint64("l1_sequence", l1Sequence), zap.Int64("height", args.BlockHeight))
 		return nil
 	}
-	if l1Sequence < h.initialL1Sequence {
-		// pass old deposit event
+	if h.child.ReadOnly() {
+		// the relayed sequence is not advanced, so the skipped deposits are backfilled after the read-only mode
+		h.Logger().Warn("child is in read-only mode; skip deposit", zap.Uint64("l1_sequence", l1Sequence), zap.Int64("height", args.BlockHeight))
 		return nil
 	}
 
+	// backfill the missing deposits before relaying the current one
+	for sequence := lastRelayedL1Sequence + 1; sequence < l1Sequence; sequence++ {
+		err = h.backfillDeposit(ctx, sequence)
+		if err != nil {
+			return err
+		}
+	}
+	return h.relayDeposit(l1Sequence, args.BlockHeight, from, to, l1Denom, l2Denom, amount, data)
+}
+
+// backfillDeposit relays the deposit of the given sequence found in the historical blocks.
+func (h *Host) backfillDeposit(ctx context.Context, l1Sequence uint64) error {
+	height, eventAttrs, err := h.QueryDepositTxEvent(ctx, h.BridgeId(), l1Sequence)
+	if err != nil {
+		return errors.Wrapf(err, "failed to query missing deposit: l1_sequence: %d", l1Sequence)
+	} else if eventAttrs == nil {
+		return fmt.Errorf("missing deposit not found: l1_sequence: %d", l1Sequence)
+	}
+
+	_, _, from, to, l1Denom, l2Denom, amount, data, err := hostprovider.ParseMsgInitiateDeposit(eventAttrs)
+	if err != nil {
+		return err
+	}
+
+	h.Logger().Warn("backfill missing deposit",
+		zap.Uint64("l1_sequence", l1Sequence),
+		zap.Int64("height", height),
+	)
+	return h.relayDeposit(l1Sequence, height, from, to, l1Denom, l2Denom, amount, data)
+}
+
+func (h *Host) relayDeposit(
+	l1Sequence uint64,
+	blockHeight int64,
+	from string,
+	to string,
+	l1Denom string,
+	l2Denom string,
+	amount string,
+	data []byte,
+) error {
 	msg, sender, err := h.handleInitiateDeposit(
 		l1Sequence,
-		args.BlockHeight,
+		blockHeight,
 		from,
 		to,
 		l1Denom,
@@ -40,9 +97,41 @@ func (h *Host) initiateDepositHandler(_ context.Context, args nodetypes.EventHan
 	} else if msg != nil {
 		h.AppendMsgQueue(msg, sender)
 	}
+	h.lastRelayedL1Sequence = l1Sequence
+	return nil
+}
+
+// loadLastRelayedL1Sequence loads the last relayed deposit sequence of the bridge.
+// The deposits before the next l1 sequence of the child are regarded as relayed.
+func (h *Host) loadLastRelayedL1Sequence() error {
+	if h.initialL1Sequence > 0 {
+		h.lastRelayedL1Sequence = h.initialL1Sequence - 1
+	}
+
+	value, err := h.DB().Get(executortypes.PrefixedLastRelayedDepositSequenceKey(h.BridgeId()))
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	sequence, err := dbtypes.ToUint64(value)
+	if err != nil {
+		return err
+	}
+	if sequence > h.lastRelayedL1Sequence {
+		h.lastRelayedL1Sequence = sequence
+	}
 	return nil
 }
 
+func (h *Host) lastRelayedL1SequenceToRawKV() types.RawKV {
+	return types.RawKV{
+		Key:   h.DB().PrefixedKey(executortypes.PrefixedLastRelayedDepositSequenceKey(h.BridgeId())),
+		Value: dbtypes.FromUint64(h.lastRelayedL1Sequence),
+	}
+}
+
 func (h *Host) handleInitiateDeposit(
 	l1Sequence uint64,
 	blockHeight int64,
@@ -0,0 +1,296 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+type mockChildNode struct {
+	childNode
+
+	lastFinalizedDepositL1Sequence uint64
+	readOnly                       bool
+}
+
+func (m *mockChildNode) ReadOnly() bool {
+	return m.readOnly
+}
+
+func (m *mockChildNode) EnterReadOnlyMode(string) error {
+	m.readOnly = true
+	return nil
+}
+
+func (m *mockChildNode) ExitReadOnlyMode(string) error {
+	m.readOnly = false
+	return nil
+}
+
+func (m *mockChildNode) HasKey() bool {
+	return true
+}
+
+func (m *mockChildNode) LastFinalizedDepositL1Sequence() uint64 {
+	return m.lastFinalizedDepositL1Sequence
+}
+
+func (m *mockChildNode) GetMsgFinalizeTokenDeposit(from string, to string, amount sdk.Coin, l1Sequence uint64, blockHeight int64, l1Denom string, data []byte) (sdk.Msg, string, error) {
+	return &opchildtypes.MsgFinalizeTokenDeposit{
+		From:      from,
+		To:        to,
+		Amount:    amount,
+		Sequence:  l1Sequence,
+		Height:    types.MustInt64ToUint64(blockHeight),
+		BaseDenom: l1Denom,
+		Data:      data,
+	}, "executor", nil
+}
+
+func depositEventAttrs(bridgeId uint64, l1Sequence uint64) []abci.EventAttribute {
+	return []abci.EventAttribute{
+		{Key: ophosttypes.AttributeKeyBridgeId, Value: strconv.FormatUint(bridgeId, 10)},
+		{Key: ophosttypes.AttributeKeyL1Sequence, Value: strconv.FormatUint(l1Sequence, 10)},
+		{Key: ophosttypes.AttributeKeyFrom, Value: "from"},
+		{Key: ophosttypes.AttributeKeyTo, Value: "to"},
+		{Key: ophosttypes.AttributeKeyL1Denom, Value: "uinit"},
+		{Key: ophosttypes.AttributeKeyL2Denom, Value: "l2/uinit"},
+		{Key: ophosttypes.AttributeKeyAmount, Value: "100"},
+		{Key: ophosttypes.AttributeKeyData, Value: ""},
+	}
+}
+
+// newMockTxSearchServer serves the deposit tx of the searched l1 sequence at the height `sequence * 10`.
+func newMockTxSearchServer(t *testing.T, bridgeId uint64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Query string `json:"query"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "tx_search" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		sequence, err := strconv.ParseUint(strings.TrimSpace(req.Params.Query[strings.LastIndex(req.Params.Query, "=")+1:]), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		height := types.MustUint64ToInt64(sequence * 10)
+		result, err := cmtjson.Marshal(&rpccoretypes.ResultTxSearch{
+			Txs: []*rpccoretypes.ResultTx{{
+				Height: height,
+				TxResult: abci.ExecTxResult{Events: []abci.Event{{
+					Type:       ophosttypes.EventTypeInitiateTokenDeposit,
+					Attributes: depositEventAttrs(bridgeId, sequence),
+				}}},
+			}},
+			TotalCount: 1,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  json.RawMessage(result),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_DepositGapBackfill(t *testing.T) {
+	server := newMockTxSearchServer(t, 1)
+
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          server.URL,
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+	h.child = &mockChildNode{}
+	h.initialL1Sequence = 3
+	require.NoError(t, h.loadLastRelayedL1Sequence())
+	require.Equal(t, uint64(2), h.lastRelayedL1Sequence)
+
+	ctx := types.WithPollingInterval(context.Background(), time.Millisecond)
+	relayedSequences := func() []uint64 {
+		sequences := make([]uint64, 0)
+		for _, msg := range h.GetMsgQueue()["executor"] {
+			sequences = append(sequences, msg.(*opchildtypes.MsgFinalizeTokenDeposit).Sequence)
+		}
+		return sequences
+	}
+
+	// deposits before the next l1 sequence of the child are not relayed
+	require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 20, EventAttributes: depositEventAttrs(1, 2)}))
+	require.Empty(t, relayedSequences())
+
+	require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 30, EventAttributes: depositEventAttrs(1, 3)}))
+	require.Equal(t, []uint64{3}, relayedSequences())
+
+	// the missing deposits 4 and 5 are backfilled before the deposit 6
+	require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 60, EventAttributes: depositEventAttrs(1, 6)}))
+	require.Equal(t, []uint64{3, 4, 5, 6}, relayedSequences())
+	require.Equal(t, uint64(40), h.GetMsgQueue()["executor"][1].(*opchildtypes.MsgFinalizeTokenDeposit).Height)
+
+	// already relayed deposit is not relayed again
+	require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 50, EventAttributes: depositEventAttrs(1, 5)}))
+	require.Equal(t, []uint64{3, 4, 5, 6}, relayedSequences())
+
+	// other bridge deposit is dropped by the registered handler
+	require.NoError(t, h.registerHandlers())
+	handler := h.Node().EventHandler(ophosttypes.EventTypeInitiateTokenDeposit)
+	require.NoError(t, handler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 70, EventAttributes: depositEventAttrs(2, 9)}))
+	require.Equal(t, uint64(6), h.lastRelayedL1Sequence)
+
+	// the last relayed sequence is persisted
+	require.NoError(t, h.DB().RawBatchSet(h.lastRelayedL1SequenceToRawKV()))
+	h.lastRelayedL1Sequence = 0
+	require.NoError(t, h.loadLastRelayedL1Sequence())
+	require.Equal(t, uint64(6), h.lastRelayedL1Sequence)
+	_, err = h.DB().Get(executortypes.PrefixedLastRelayedDepositSequenceKey(1))
+	require.NoError(t, err)
+}
+
+func Test_DepositReplay(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+	child := &mockChildNode{lastFinalizedDepositL1Sequence: 4}
+	h.child = child
+	h.initialL1Sequence = 1
+	require.NoError(t, h.loadLastRelayedL1Sequence())
+
+	ctx := context.Background()
+	replay := func(l1Sequence uint64) {
+		require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{
+			BlockHeight:     int64(l1Sequence * 10),
+			EventAttributes: depositEventAttrs(1, l1Sequence),
+		}))
+	}
+
+	// the deposits finalized on l2 are not relayed, even without the relayed sequence
+	replay(4)
+	require.Empty(t, h.GetMsgQueue()["executor"])
+
+	// the same deposit event is relayed only once
+	replay(5)
+	replay(5)
+	require.Len(t, h.GetMsgQueue()["executor"], 1)
+	require.Equal(t, uint64(5), h.GetMsgQueue()["executor"][0].(*opchildtypes.MsgFinalizeTokenDeposit).Sequence)
+
+	// the deposit finalized while the host replays the blocks after the db loss
+	h.EmptyMsgQueue()
+	h.lastRelayedL1Sequence = 0
+	child.lastFinalizedDepositL1Sequence = 5
+	replay(5)
+	require.Empty(t, h.GetMsgQueue()["executor"])
+
+	// the deposit handler is not registered with the deposit relayer disabled
+	h.SetDepositRelayer(false)
+	require.NoError(t, h.registerHandlers())
+	require.Nil(t, h.Node().EventHandler(ophosttypes.EventTypeInitiateTokenDeposit))
+	require.NotNil(t, h.Node().EventHandler(ophosttypes.EventTypeProposeOutput))
+}
+
+func Test_DepositReadOnly(t *testing.T) {
+	server := newMockTxSearchServer(t, 1)
+
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          server.URL,
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+	child := &mockChildNode{readOnly: true}
+	h.child = child
+	h.initialL1Sequence = 1
+	require.NoError(t, h.loadLastRelayedL1Sequence())
+
+	ctx := types.WithPollingInterval(context.Background(), time.Millisecond)
+
+	// the deposits are not finalized in the read-only mode
+	require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 10, EventAttributes: depositEventAttrs(1, 1)}))
+	require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 20, EventAttributes: depositEventAttrs(1, 2)}))
+	require.Empty(t, h.GetMsgQueue()["executor"])
+	require.Equal(t, uint64(0), h.lastRelayedL1Sequence)
+
+	// the skipped deposits are backfilled after the read-only mode
+	child.readOnly = false
+	require.NoError(t, h.initiateDepositHandler(ctx, nodetypes.EventHandlerArgs{BlockHeight: 30, EventAttributes: depositEventAttrs(1, 3)}))
+	require.Len(t, h.GetMsgQueue()["executor"], 3)
+	require.Equal(t, uint64(3), h.lastRelayedL1Sequence)
+}
+
+func Test_FreezeBridgeHandlers(t *testing.T) {
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db.NewMemDB(), zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+	child := &mockChildNode{}
+	h.child = child
+	require.NoError(t, h.registerHandlers())
+
+	freezeAttrs := []abci.EventAttribute{{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"}}
+
+	// the child enters the read-only mode with the freeze event, and exits it with the freeze-lift event
+	require.NotNil(t, h.Node().EventHandler(hostprovider.EventTypeFreezeBridge))
+	require.NoError(t, h.Node().EventHandler(hostprovider.EventTypeFreezeBridge)(context.Background(), nodetypes.EventHandlerArgs{BlockHeight: 10, EventAttributes: freezeAttrs}))
+	require.True(t, child.readOnly)
+	require.NoError(t, h.Node().EventHandler(hostprovider.EventTypeUnfreezeBridge)(context.Background(), nodetypes.EventHandlerArgs{BlockHeight: 20, EventAttributes: freezeAttrs}))
+	require.False(t, child.readOnly)
+
+	require.Error(t, h.freezeBridgeHandler(context.Background(), nodetypes.EventHandlerArgs{}))
+	require.False(t, child.readOnly)
+}
@@ -0,0 +1,42 @@
+package host
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+var _ btypes.EffectChecker = proposeOutputEffectChecker{}
+
+// proposeOutputEffectChecker checks whether the output has already been proposed.
+type proposeOutputEffectChecker struct {
+	host *Host
+}
+
+func (c proposeOutputEffectChecker) EffectKey(msg sdk.Msg) (string, error) {
+	proposeOutput, ok := msg.(*ophosttypes.MsgProposeOutput)
+	if !ok {
+		return "", fmt.Errorf("unexpected msg type: %s", sdk.MsgTypeURL(msg))
+	}
+	return fmt.Sprintf("output/%d/%d", proposeOutput.BridgeId, proposeOutput.OutputIndex), nil
+}
+
+func (c proposeOutputEffectChecker) EffectExists(ctx context.Context, msg sdk.Msg) (bool, error) {
+	proposeOutput, ok := msg.(*ophosttypes.MsgProposeOutput)
+	if !ok {
+		return false, fmt.Errorf("unexpected msg type: %s", sdk.MsgTypeURL(msg))
+	}
+
+	output, err := c.host.QueryLastOutput(ctx, proposeOutput.BridgeId, 0)
+	if err != nil {
+		return false, err
+	} else if output == nil {
+		return false, nil
+	}
+	return output.OutputIndex >= proposeOutput.OutputIndex, nil
+}
@@ -2,7 +2,7 @@ package host
 
 import (
 	"context"
-	"slices"
+	"fmt"
 	"time"
 
 	"github.com/initia-labs/opinit-bots/types"
@@ -16,33 +16,24 @@ import (
 func (h *Host) beginBlockHandler(_ context.Context, args nodetypes.BeginBlockArgs) error {
 	h.EmptyMsgQueue()
 	h.EmptyProcessedMsgs()
+	clear(h.outputProposedTimes)
 	return nil
 }
 
-func (h *Host) endBlockHandler(_ context.Context, args nodetypes.EndBlockArgs) error {
+func (h *Host) endBlockHandler(ctx context.Context, args nodetypes.EndBlockArgs) error {
 	// collect more msgs if block height is not latest
 	blockHeight := args.Block.Header.Height
-	msgQueues := h.GetMsgQueue()
 
 	batchKVs := []types.RawKV{
 		h.Node().SyncInfoToRawKV(blockHeight),
+		h.lastRelayedL1SequenceToRawKV(),
+	}
+	for outputIndex, blockTime := range h.outputProposedTimes {
+		batchKVs = append(batchKVs, h.OutputProposedTimeToRawKV(outputIndex, blockTime))
 	}
 	if h.child.HasKey() {
-		for sender := range msgQueues {
-			msgQueue := msgQueues[sender]
-			for i := 0; i < len(msgQueue); i += 5 {
-				end := i + 5
-				if end > len(msgQueue) {
-					end = len(msgQueue)
-				}
-
-				h.AppendProcessedMsgs(btypes.ProcessedMsgs{
-					Sender:    sender,
-					Msgs:      slices.Clone(msgQueue[i:end]),
-					Timestamp: time.Now().UnixNano(),
-					Save:      true,
-				})
-			}
+		if err := h.FlushMsgQueue(); err != nil {
+			return err
 		}
 
 		msgkvs, err := h.child.ProcessedMsgsToRawKV(h.GetProcessedMsgs(), false)
@@ -54,18 +45,21 @@ func (h *Host) endBlockHandler(_ context.Context, args nodetypes.EndBlockArgs) e
 
 	err := h.DB().RawBatchSet(batchKVs...)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", nodetypes.ErrFatalDB, err)
 	}
 
 	for _, processedMsg := range h.GetProcessedMsgs() {
-		h.child.BroadcastMsgs(processedMsg)
+		err = h.child.BroadcastMsgs(processedMsg)
+		if err != nil {
+			return err
+		}
 	}
-	return nil
+	return h.finalizeOutputs(ctx, args.Block.Header.Time)
 }
 
-func (h *Host) txHandler(_ context.Context, args nodetypes.TxHandlerArgs) error {
+func (h *Host) txHandler(ctx context.Context, args nodetypes.TxHandlerArgs) error {
 	if args.BlockHeight == args.LatestHeight && args.TxIndex == 0 {
-		msg, sender, err := h.oracleTxHandler(args.BlockHeight, args.Tx)
+		msg, sender, err := h.oracleTxHandler(ctx, args.BlockHeight, args.BlockTime, args.Tx)
 		if err != nil {
 			return err
 		} else if msg != nil {
@@ -73,6 +67,7 @@ func (h *Host) txHandler(_ context.Context, args nodetypes.TxHandlerArgs) error
 				Sender:    sender,
 				Msgs:      []sdk.Msg{msg},
 				Timestamp: time.Now().UnixNano(),
+				Lane:      btypes.OracleLane,
 				Save:      false,
 			})
 		}
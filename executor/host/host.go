@@ -2,6 +2,8 @@ package host
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -10,6 +12,7 @@ import (
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
 
 	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/node"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	"github.com/initia-labs/opinit-bots/types"
@@ -19,7 +22,7 @@ import (
 
 type childNode interface {
 	HasKey() bool
-	BroadcastMsgs(btypes.ProcessedMsgs)
+	BroadcastMsgs(btypes.ProcessedMsgs) error
 	ProcessedMsgsToRawKV([]btypes.ProcessedMsgs, bool) ([]types.RawKV, error)
 	QueryNextL1Sequence(context.Context, int64) (uint64, error)
 	BaseAccountAddressString() (string, error)
@@ -27,6 +30,12 @@ type childNode interface {
 
 	GetMsgFinalizeTokenDeposit(string, string, sdk.Coin, uint64, int64, string, []byte) (sdk.Msg, string, error)
 	GetMsgUpdateOracle(int64, []byte) (sdk.Msg, string, error)
+	DropQueuedOracleMsgs() (int, error)
+	LastUpdatedOracleL1Height() int64
+	LastFinalizedDepositL1Sequence() uint64
+	ReadOnly() bool
+	EnterReadOnlyMode(string) error
+	ExitReadOnlyMode(string) error
 }
 
 type batchNode interface {
@@ -41,20 +50,71 @@ type Host struct {
 	child childNode
 	batch batchNode
 
-	initialL1Sequence uint64
+	initialL1Sequence     uint64
+	lastRelayedL1Sequence uint64
+
+	// called with the new bridge info when the bridge config is updated
+	bridgeInfoUpdateHandlers []func(ophosttypes.QueryBridgeResponse)
+	// called with the deleted output index when the outputs are deleted by the challenger
+	outputDeletedHandlers []func(context.Context, uint64) error
+	// called at the end of the host blocks with the index of the last finalized output
+	outputFinalizedHandlers []func(context.Context, uint64) error
+	// called with the outputs proposed by our confirmed txs
+	outputProposedHandlers []func(context.Context, executortypes.ProposedOutputInfo) error
+
+	// the outputs proposed on the host chain which are not finalized yet, in the order of the output index
+	unfinalizedOutputs []unfinalizedOutput
+	// the l1 block times of the outputs proposed in the block, saved at the end of the block
+	outputProposedTimes map[uint64]time.Time
+
+	// key names which can be rotated to the proposer
+	standbyProposerKeys []string
+
+	// relay the deposits to l2; the deposit handler is not registered if it is disabled
+	depositRelayer bool
+
+	// minimum time between the relayed oracle updates
+	oracleRelayInterval time.Duration
+	lastOracleRelayTime time.Time
+	// maximum number of l1 blocks the relayed oracle data can fall behind
+	oracleMaxStaleness    int64
+	lastOracleRelayHeight int64
+	// verify the oracle updates against the l1 validator set before they are relayed
+	oracleVerification bool
+	validators         validatorQuerier
 
 	// status info
 	lastProposedOutputIndex         uint64
 	lastProposedOutputL2BlockNumber int64
+	lastDeletedOutputIndex          uint64
+	lastFinalizedOutputIndex        uint64
+
+	metrics *hostMetrics
 }
 
 func NewHostV1(
 	cfg nodetypes.NodeConfig,
 	db types.DB, logger *zap.Logger,
-) *Host {
-	return &Host{
-		BaseHost: hostprovider.NewBaseHostV1(cfg, db, logger),
+) (*Host, error) {
+	baseHost, err := hostprovider.NewBaseHostV1(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Host{
+		BaseHost:            baseHost,
+		outputProposedTimes: make(map[uint64]time.Time),
+		depositRelayer:      true,
+		metrics:             newHostMetrics(cfg.Bridge),
+	}
+	h.validators = h.Node()
+	if h.Node().HasBroadcaster() {
+		broadcaster := h.Node().MustGetBroadcaster()
+		broadcaster.RegisterEffectChecker(sdk.MsgTypeURL(&ophosttypes.MsgProposeOutput{}), proposeOutputEffectChecker{host: h})
+		broadcaster.RegisterMsgResigner(sdk.MsgTypeURL(&ophosttypes.MsgProposeOutput{}), resignProposeOutput)
+		broadcaster.RegisterMsgResigner(sdk.MsgTypeURL(&ophosttypes.MsgFinalizeTokenWithdrawal{}), resignFinalizeTokenWithdrawal)
 	}
+	return h, nil
 }
 
 func (h *Host) Initialize(
@@ -63,9 +123,10 @@ func (h *Host) Initialize(
 	child childNode,
 	batch batchNode,
 	bridgeInfo ophosttypes.QueryBridgeResponse,
+	expectedChainInfo *hostprovider.ExpectedChainInfo,
 	keyringConfig *btypes.KeyringConfig,
 ) error {
-	err := h.BaseHost.Initialize(ctx, processedHeight, bridgeInfo, keyringConfig)
+	err := h.BaseHost.Initialize(ctx, processedHeight, bridgeInfo, expectedChainInfo, keyringConfig)
 	if err != nil {
 		return err
 	}
@@ -75,8 +136,21 @@ func (h *Host) Initialize(
 	if err != nil {
 		return err
 	}
-	h.registerHandlers()
-	return nil
+	err = h.loadLastRelayedL1Sequence()
+	if err != nil {
+		return err
+	}
+	err = h.loadUnfinalizedOutputs(ctx, processedHeight)
+	if err != nil {
+		return err
+	}
+	if h.HasKey() {
+		err = h.backfillProposedOutputs(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return h.registerHandlers()
 }
 
 func (h *Host) InitializeDA(
@@ -84,25 +158,57 @@ func (h *Host) InitializeDA(
 	bridgeInfo ophosttypes.QueryBridgeResponse,
 	keyringConfig *btypes.KeyringConfig,
 ) error {
-	err := h.BaseHost.Initialize(ctx, 0, bridgeInfo, keyringConfig)
-	if err != nil {
+	err := h.BaseHost.Initialize(ctx, 0, bridgeInfo, nil, keyringConfig)
+	return err
+}
+
+func (h *Host) registerHandlers() error {
+	if err := h.Node().RegisterBeginBlockHandler(h.beginBlockHandler); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterTxHandler(h.txHandler); err != nil {
+		return err
+	}
+	// the events of the other bridges are dropped before the handlers
+	bridgeFilter := node.WithAttributeFilter(ophosttypes.AttributeKeyBridgeId, strconv.FormatUint(h.BridgeId(), 10))
+	if h.depositRelayer {
+		if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeInitiateTokenDeposit, h.initiateDepositHandler, bridgeFilter, node.WithMetrics()); err != nil {
+			return err
+		}
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeProposeOutput, h.proposeOutputHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeDeleteOutput, h.deleteOutputHandler, bridgeFilter, node.WithMetrics()); err != nil {
 		return err
 	}
-	h.registerDAHandlers()
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeFinalizeTokenWithdrawal, h.finalizeWithdrawalHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeRecordBatch, h.recordBatchHandler, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeUpdateBatchInfo, h.updateBatchInfoHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeUpdateProposer, h.updateBridgeHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeUpdateChallenger, h.updateBridgeHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(ophosttypes.EventTypeUpdateOracle, h.updateBridgeHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(hostprovider.EventTypeFreezeBridge, h.freezeBridgeHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEventHandler(hostprovider.EventTypeUnfreezeBridge, h.unfreezeBridgeHandler, bridgeFilter, node.WithMetrics()); err != nil {
+		return err
+	}
+	if err := h.Node().RegisterEndBlockHandler(h.endBlockHandler); err != nil {
+		return err
+	}
+	h.Node().RegisterTxConfirmedHandler(h.txConfirmedHandler)
 	return nil
 }
-
-func (h *Host) registerHandlers() {
-	h.Node().RegisterBeginBlockHandler(h.beginBlockHandler)
-	h.Node().RegisterTxHandler(h.txHandler)
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeInitiateTokenDeposit, h.initiateDepositHandler)
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeProposeOutput, h.proposeOutputHandler)
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeFinalizeTokenWithdrawal, h.finalizeWithdrawalHandler)
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeRecordBatch, h.recordBatchHandler)
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeUpdateBatchInfo, h.updateBatchInfoHandler)
-	h.Node().RegisterEndBlockHandler(h.endBlockHandler)
-}
-
-func (h *Host) registerDAHandlers() {
-	h.Node().RegisterEventHandler(ophosttypes.EventTypeRecordBatch, h.recordBatchHandler)
-}
@@ -0,0 +1,43 @@
+package host
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/initia-labs/opinit-bots/node/metrics"
+)
+
+type hostMetrics struct {
+	OracleUpdatesRelayed prometheus.Counter
+	OracleUpdatesDropped prometheus.Counter
+	OracleUpdatesSkipped prometheus.Counter
+	OracleUpdatesInvalid prometheus.Counter
+}
+
+func newHostMetrics(bridge string) *hostMetrics {
+	return &hostMetrics{
+		OracleUpdatesRelayed: metrics.Register(bridge, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "host",
+			Name:      "oracle_updates_relayed_total",
+			Help:      "The number of the oracle updates queued to be relayed to l2.",
+		})),
+		OracleUpdatesDropped: metrics.Register(bridge, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "host",
+			Name:      "oracle_updates_dropped_total",
+			Help:      "The number of the oracle updates dropped because they are stale or superseded by a fresher one.",
+		})),
+		OracleUpdatesSkipped: metrics.Register(bridge, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "host",
+			Name:      "oracle_updates_skipped_total",
+			Help:      "The number of the oracle updates skipped by the relay interval.",
+		})),
+		OracleUpdatesInvalid: metrics.Register(bridge, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace(),
+			Subsystem: "host",
+			Name:      "oracle_updates_invalid_total",
+			Help:      "The number of the oracle updates dropped because they are not signed by the l1 validators.",
+		})),
+	}
+}
@@ -1,19 +1,163 @@
 package host
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	comettypes "github.com/cometbft/cometbft/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+type validatorQuerier interface {
+	QueryValidators(context.Context, int64) ([]*comettypes.Validator, error)
+}
+
+// SetOracleRelayInterval sets the minimum time between the relayed oracle updates.
+func (h *Host) SetOracleRelayInterval(interval time.Duration) {
+	h.oracleRelayInterval = interval
+}
+
+// SetOracleMaxStaleness sets the maximum number of l1 blocks the relayed oracle data can fall behind.
+// If the last relayed update is older than it, the update is relayed even in the relay interval.
+func (h *Host) SetOracleMaxStaleness(blocks int64) {
+	h.oracleMaxStaleness = blocks
+}
+
+// SetOracleVerification sets whether the oracle updates are verified against the l1 validator set before they are relayed.
+func (h *Host) SetOracleVerification(enabled bool) {
+	h.oracleVerification = enabled
+}
+
 // If the relay oracle is enabled and the extended commit info contains votes, create a new MsgUpdateOracle message.
 // Else return nil.
-func (h *Host) oracleTxHandler(blockHeight int64, extCommitBz comettypes.Tx) (sdk.Msg, string, error) {
+//
+// The first update after the relay interval passes is relayed, and the later updates in the interval are
+// skipped, unless the last relayed update is older than the max staleness. The older updates still waiting
+// in the queue are superseded by the relayed one, and the updates already included in l2 are never relayed again.
+// If the verification is enabled, the updates which are not signed by the l1 validators are dropped.
+func (h *Host) oracleTxHandler(ctx context.Context, blockHeight int64, blockTime time.Time, extCommitBz comettypes.Tx) (sdk.Msg, string, error) {
 	if !h.OracleEnabled() {
 		return nil, "", nil
 	}
-	return h.child.GetMsgUpdateOracle(
+
+	if blockHeight <= h.child.LastUpdatedOracleL1Height() {
+		h.metrics.OracleUpdatesDropped.Inc()
+		return nil, "", nil
+	} else if !h.isOracleStale(blockHeight) && !h.lastOracleRelayTime.IsZero() && blockTime.Before(h.lastOracleRelayTime.Add(h.oracleRelayInterval)) {
+		// the updates after the relayed one are skipped until the interval passes
+		h.metrics.OracleUpdatesSkipped.Inc()
+		return nil, "", nil
+	}
+
+	if h.oracleVerification {
+		// the vote extensions in the block are signed by the validators of the previous block
+		validators, err := h.validators.QueryValidators(ctx, blockHeight-1)
+		if err != nil {
+			return nil, "", err
+		}
+		err = verifyOracleData(h.ChainId(), blockHeight, extCommitBz, validators)
+		if err != nil {
+			h.Logger().Warn("drop invalid oracle update", zap.Int64("height", blockHeight), zap.String("error", err.Error()))
+			h.metrics.OracleUpdatesInvalid.Inc()
+			return nil, "", nil
+		}
+	}
+
+	msg, sender, err := h.child.GetMsgUpdateOracle(
 		blockHeight,
 		extCommitBz,
 	)
+	if err != nil || msg == nil {
+		return nil, "", err
+	}
+
+	dropped, err := h.child.DropQueuedOracleMsgs()
+	if err != nil {
+		return nil, "", err
+	} else if dropped > 0 {
+		h.Logger().Debug("drop superseded oracle updates", zap.Int64("height", blockHeight), zap.Int("dropped", dropped))
+		h.metrics.OracleUpdatesDropped.Add(float64(dropped))
+	}
+
+	h.lastOracleRelayTime = blockTime
+	h.lastOracleRelayHeight = blockHeight
+	h.metrics.OracleUpdatesRelayed.Inc()
+	return msg, sender, nil
+}
+
+// isOracleStale returns true if the last relayed oracle update is older than the max staleness at the given l1 height.
+// The last update included in l2 is used until an update is relayed after the restart.
+func (h *Host) isOracleStale(blockHeight int64) bool {
+	if h.oracleMaxStaleness == 0 {
+		return false
+	}
+	return blockHeight-max(h.lastOracleRelayHeight, h.child.LastUpdatedOracleL1Height()) >= h.oracleMaxStaleness
+}
+
+// verifyOracleData verifies the extended commit info in the 0th tx of the l1 block at the given height.
+// The vote extensions are signed at the previous height, and the validators with valid signatures
+// must have more than 2/3 of the voting power of the validator set.
+func verifyOracleData(chainId string, blockHeight int64, extCommitBz []byte, validators []*comettypes.Validator) error {
+	var extCommit abci.ExtendedCommitInfo
+	if err := extCommit.Unmarshal(extCommitBz); err != nil {
+		return fmt.Errorf("failed to decode extended commit info: %w", err)
+	} else if len(extCommit.Votes) == 0 {
+		return errors.New("extended commit info has no votes")
+	}
+
+	totalPower := int64(0)
+	for _, validator := range validators {
+		totalPower += validator.VotingPower
+	}
+
+	signedPower := int64(0)
+	signed := make(map[string]bool)
+	for _, vote := range extCommit.Votes {
+		if vote.BlockIdFlag != cmtproto.BlockIDFlagCommit {
+			continue
+		}
+
+		address := vote.Validator.Address
+		if signed[string(address)] {
+			return fmt.Errorf("duplicated vote of validator %X", address)
+		}
+		validator := findValidator(validators, address)
+		if validator == nil {
+			return fmt.Errorf("validator %X is not in the validator set", address)
+		}
+
+		signBytes := comettypes.VoteExtensionSignBytes(chainId, &cmtproto.Vote{
+			Type:      cmtproto.PrecommitType,
+			Height:    blockHeight - 1,
+			Round:     extCommit.Round,
+			Extension: vote.VoteExtension,
+		})
+		if !validator.PubKey.VerifySignature(signBytes, vote.ExtensionSignature) {
+			return fmt.Errorf("invalid vote extension signature of validator %X", address)
+		}
+		signed[string(address)] = true
+		signedPower += validator.VotingPower
+	}
+
+	if signedPower*3 <= totalPower*2 {
+		return fmt.Errorf("insufficient voting power: signed %d, total %d", signedPower, totalPower)
+	}
+	return nil
+}
+
+func findValidator(validators []*comettypes.Validator, address []byte) *comettypes.Validator {
+	for _, validator := range validators {
+		if bytes.Equal(validator.Address, address) {
+			return validator
+		}
+	}
+	return nil
 }
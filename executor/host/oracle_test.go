@@ -0,0 +1,269 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	comettypes "github.com/cometbft/cometbft/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+type mockOracleChildNode struct {
+	childNode
+
+	lastUpdatedOracleL1Height int64
+	// the number of the oracle msgs waiting in the queue
+	queuedOracleMsgs int
+}
+
+func (m *mockOracleChildNode) GetMsgUpdateOracle(height int64, data []byte) (sdk.Msg, string, error) {
+	return &opchildtypes.MsgUpdateOracle{Height: types.MustInt64ToUint64(height), Data: data}, "oracle", nil
+}
+
+func (m *mockOracleChildNode) DropQueuedOracleMsgs() (int, error) {
+	dropped := m.queuedOracleMsgs
+	m.queuedOracleMsgs = 0
+	return dropped, nil
+}
+
+func (m *mockOracleChildNode) LastUpdatedOracleL1Height() int64 {
+	return m.lastUpdatedOracleL1Height
+}
+
+func Test_OracleRelay(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{OracleEnabled: true},
+	})
+	h.SetOracleRelayInterval(10 * time.Second)
+	child := &mockOracleChildNode{lastUpdatedOracleL1Height: 100}
+	h.child = child
+
+	relay := func(height int64, blockTime time.Time) sdk.Msg {
+		msg, _, err := h.oracleTxHandler(context.Background(), height, blockTime, []byte("oracle"))
+		require.NoError(t, err)
+		return msg
+	}
+
+	// the updates already included in l2 are not relayed after the restart
+	start := time.Unix(0, 0)
+	require.Nil(t, relay(100, start))
+
+	// the fresh update supersedes the update queued while the previous tx is pending
+	child.queuedOracleMsgs = 1
+	msg := relay(101, start)
+	require.NotNil(t, msg)
+	require.Equal(t, uint64(101), msg.(*opchildtypes.MsgUpdateOracle).Height)
+	require.Zero(t, child.queuedOracleMsgs)
+
+	// the first update after the interval passes is relayed, and the later updates in the interval are skipped
+	require.Nil(t, relay(102, start.Add(5*time.Second)))
+	msg = relay(103, start.Add(10*time.Second))
+	require.NotNil(t, msg)
+	require.Equal(t, uint64(103), msg.(*opchildtypes.MsgUpdateOracle).Height)
+}
+
+func Test_OracleRelayPolicy(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// the l1 blocks with the irregular block times
+	blockTimes := make([]time.Time, 0, 200)
+	blockTime := time.Unix(0, 0)
+	for i := 0; i < 200; i++ {
+		blockTime = blockTime.Add(time.Duration(500+(i*37)%2000) * time.Millisecond)
+		blockTimes = append(blockTimes, blockTime)
+	}
+
+	for _, interval := range []time.Duration{0, 3 * time.Second, 10 * time.Second, time.Minute} {
+		for _, maxStaleness := range []int64{0, 1, 5, 20} {
+			t.Run(fmt.Sprintf("interval=%s,max_staleness=%d", interval, maxStaleness), func(t *testing.T) {
+				h, err := NewHostV1(nodetypes.NodeConfig{
+					RPC:          "http://localhost:26657",
+					ChainID:      "test-1",
+					Bech32Prefix: "init",
+				}, db, zap.NewNop())
+				require.NoError(t, err)
+				h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+					BridgeId:     1,
+					BridgeConfig: ophosttypes.BridgeConfig{OracleEnabled: true},
+				})
+				h.SetOracleRelayInterval(interval)
+				h.SetOracleMaxStaleness(maxStaleness)
+				child := &mockOracleChildNode{lastUpdatedOracleL1Height: 1}
+				h.child = child
+
+				relayed := make([]int64, 0)
+				var lastRelayTime time.Time
+				for i, blockTime := range blockTimes {
+					height := int64(i + 2)
+					msg, _, err := h.oracleTxHandler(context.Background(), height, blockTime, []byte("oracle"))
+					require.NoError(t, err)
+					if msg == nil {
+						// the update is skipped only in the interval
+						require.True(t, blockTime.Before(lastRelayTime.Add(interval)))
+					} else {
+						relayed = append(relayed, height)
+						lastRelayTime = blockTime
+						// the relayed update is included in l2 right away
+						child.lastUpdatedOracleL1Height = height
+					}
+
+					// the oracle data on l2 never falls behind the staleness bound
+					if maxStaleness > 0 {
+						require.Less(t, height-child.lastUpdatedOracleL1Height, maxStaleness)
+					}
+				}
+
+				if interval == 0 {
+					require.Len(t, relayed, len(blockTimes))
+				} else if maxStaleness != 1 {
+					require.Less(t, len(relayed), len(blockTimes))
+				}
+			})
+		}
+	}
+}
+
+type mockValidatorQuerier struct {
+	validators []*comettypes.Validator
+}
+
+func (m mockValidatorQuerier) QueryValidators(_ context.Context, _ int64) ([]*comettypes.Validator, error) {
+	return m.validators, nil
+}
+
+type testValidator struct {
+	privKey   ed25519.PrivKey
+	validator *comettypes.Validator
+}
+
+func newTestValidators(powers ...int64) []testValidator {
+	validators := make([]testValidator, 0, len(powers))
+	for _, power := range powers {
+		privKey := ed25519.GenPrivKey()
+		validators = append(validators, testValidator{
+			privKey:   privKey,
+			validator: comettypes.NewValidator(privKey.PubKey(), power),
+		})
+	}
+	return validators
+}
+
+// extendedCommit returns the extended commit info of the block at the given height, signed by the validators.
+func extendedCommit(t *testing.T, chainId string, height int64, signers []testValidator) []byte {
+	extCommit := abci.ExtendedCommitInfo{Round: 1}
+	for i, signer := range signers {
+		extension := []byte(fmt.Sprintf("prices-%d", i))
+		signature, err := signer.privKey.Sign(comettypes.VoteExtensionSignBytes(chainId, &cmtproto.Vote{
+			Type:      cmtproto.PrecommitType,
+			Height:    height - 1,
+			Round:     extCommit.Round,
+			Extension: extension,
+		}))
+		require.NoError(t, err)
+
+		extCommit.Votes = append(extCommit.Votes, abci.ExtendedVoteInfo{
+			Validator:          abci.Validator{Address: signer.validator.Address, Power: signer.validator.VotingPower},
+			VoteExtension:      extension,
+			ExtensionSignature: signature,
+			BlockIdFlag:        cmtproto.BlockIDFlagCommit,
+		})
+	}
+	bz, err := extCommit.Marshal()
+	require.NoError(t, err)
+	return bz
+}
+
+func Test_VerifyOracleData(t *testing.T) {
+	signers := newTestValidators(10, 10, 10)
+	validators := []*comettypes.Validator{signers[0].validator, signers[1].validator, signers[2].validator}
+
+	// valid
+	require.NoError(t, verifyOracleData("test-1", 100, extendedCommit(t, "test-1", 100, signers), validators))
+	// 3 of 4 validators with the same power have more than 2/3 of the power
+	signers = append(signers, newTestValidators(10)...)
+	require.NoError(t, verifyOracleData("test-1", 100, extendedCommit(t, "test-1", 100, signers[:3]), append(validators, signers[3].validator)))
+
+	// under-powered
+	require.Error(t, verifyOracleData("test-1", 100, extendedCommit(t, "test-1", 100, signers[:2]), validators))
+	// signed for the other height or chain
+	require.Error(t, verifyOracleData("test-1", 100, extendedCommit(t, "test-1", 101, signers[:3]), validators))
+	require.Error(t, verifyOracleData("test-1", 100, extendedCommit(t, "test-2", 100, signers[:3]), validators))
+	// signed by the validator not in the validator set
+	require.Error(t, verifyOracleData("test-1", 100, extendedCommit(t, "test-1", 100, signers[1:]), validators))
+	// not an extended commit info
+	require.Error(t, verifyOracleData("test-1", 100, []byte("oracle"), validators))
+	require.Error(t, verifyOracleData("test-1", 100, extendedCommit(t, "test-1", 100, nil), validators))
+}
+
+func Test_OracleRelayVerification(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{OracleEnabled: true},
+	})
+	h.SetOracleVerification(true)
+	signers := newTestValidators(10, 10, 10)
+	h.validators = mockValidatorQuerier{validators: []*comettypes.Validator{signers[0].validator, signers[1].validator, signers[2].validator}}
+	child := &mockOracleChildNode{lastUpdatedOracleL1Height: 100}
+	h.child = child
+
+	relay := func(height int64, data []byte) sdk.Msg {
+		msg, _, err := h.oracleTxHandler(context.Background(), height, time.Unix(0, 0), data)
+		require.NoError(t, err)
+		return msg
+	}
+
+	// stale
+	require.Nil(t, relay(100, extendedCommit(t, "test-1", 100, signers)))
+	// under-powered
+	require.Nil(t, relay(101, extendedCommit(t, "test-1", 101, signers[:2])))
+	// nonstandard payload
+	require.Nil(t, relay(101, []byte("oracle")))
+
+	// valid
+	msg := relay(101, extendedCommit(t, "test-1", 101, signers))
+	require.NotNil(t, msg)
+	require.Equal(t, uint64(101), msg.(*opchildtypes.MsgUpdateOracle).Height)
+
+	// the verification is disabled for the nonstandard payloads
+	h.SetOracleVerification(false)
+	require.NotNil(t, relay(102, []byte("oracle")))
+}
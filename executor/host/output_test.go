@@ -0,0 +1,224 @@
+package host
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
+)
+
+func Test_DeleteOutput(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+
+	var deleted []uint64
+	h.RegisterOutputDeletedHandler(func(_ context.Context, outputIndex uint64) error {
+		deleted = append(deleted, outputIndex)
+		return nil
+	})
+
+	deleteEvent := func(bridgeId string, outputIndex string) nodetypes.EventHandlerArgs {
+		return nodetypes.EventHandlerArgs{
+			BlockHeight: 10,
+			EventAttributes: []abci.EventAttribute{
+				{Key: ophosttypes.AttributeKeyChallenger, Value: "challenger"},
+				{Key: ophosttypes.AttributeKeyBridgeId, Value: bridgeId},
+				{Key: ophosttypes.AttributeKeyOutputIndex, Value: outputIndex},
+			},
+		}
+	}
+
+	// other bridge output deletion is dropped by the registered handler
+	require.NoError(t, h.registerHandlers())
+	handler := h.Node().EventHandler(ophosttypes.EventTypeDeleteOutput)
+	require.NoError(t, handler(context.Background(), deleteEvent("2", "3")))
+	require.Empty(t, deleted)
+
+	require.NoError(t, handler(context.Background(), deleteEvent("1", "3")))
+	require.Equal(t, []uint64{3}, deleted)
+	require.Equal(t, uint64(3), h.lastDeletedOutputIndex)
+
+	// missing attributes
+	require.Error(t, h.deleteOutputHandler(context.Background(), nodetypes.EventHandlerArgs{
+		EventAttributes: []abci.EventAttribute{{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"}},
+	}))
+}
+
+func Test_FinalizeOutputs(t *testing.T) {
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db.NewMemDB(), zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{FinalizationPeriod: 10 * time.Second},
+	})
+
+	var finalized []uint64
+	h.RegisterOutputFinalizedHandler(func(_ context.Context, outputIndex uint64) error {
+		finalized = append(finalized, outputIndex)
+		return nil
+	})
+
+	now := time.Unix(1000, 0)
+	proposeEvent := func(outputIndex uint64, blockTime time.Time) nodetypes.EventHandlerArgs {
+		return nodetypes.EventHandlerArgs{
+			BlockTime: blockTime,
+			EventAttributes: []abci.EventAttribute{
+				{Key: ophosttypes.AttributeKeyProposer, Value: "proposer"},
+				{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"},
+				{Key: ophosttypes.AttributeKeyOutputIndex, Value: strconv.FormatUint(outputIndex, 10)},
+				{Key: ophosttypes.AttributeKeyL2BlockNumber, Value: strconv.FormatUint(outputIndex*10, 10)},
+				{Key: ophosttypes.AttributeKeyOutputRoot, Value: "00"},
+			},
+		}
+	}
+	for outputIndex := uint64(1); outputIndex <= 3; outputIndex++ {
+		require.NoError(t, h.proposeOutputHandler(context.Background(), proposeEvent(outputIndex, now.Add(time.Duration(outputIndex)*time.Second))))
+	}
+
+	// no output is finalized yet
+	require.NoError(t, h.finalizeOutputs(context.Background(), now.Add(10*time.Second)))
+	require.Empty(t, finalized)
+
+	require.NoError(t, h.finalizeOutputs(context.Background(), now.Add(12*time.Second)))
+	require.Equal(t, []uint64{2}, finalized)
+	require.Equal(t, uint64(2), h.LastFinalizedOutputIndex())
+
+	// the handlers are called at every block once an output is finalized
+	require.NoError(t, h.finalizeOutputs(context.Background(), now.Add(12*time.Second)))
+	require.Equal(t, []uint64{2, 2}, finalized)
+
+	// the deleted output is never finalized
+	require.NoError(t, h.deleteOutputHandler(context.Background(), nodetypes.EventHandlerArgs{
+		EventAttributes: []abci.EventAttribute{
+			{Key: ophosttypes.AttributeKeyChallenger, Value: "challenger"},
+			{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"},
+			{Key: ophosttypes.AttributeKeyOutputIndex, Value: "3"},
+		},
+	}))
+	require.NoError(t, h.finalizeOutputs(context.Background(), now.Add(20*time.Second)))
+	require.Equal(t, []uint64{2, 2, 2}, finalized)
+}
+
+func Test_LoadUnfinalizedOutputs(t *testing.T) {
+	server := newMockOutputServer(t, 4)
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          server.URL,
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db.NewMemDB(), zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{FinalizationPeriod: 10 * time.Second},
+	})
+
+	var finalized []uint64
+	h.RegisterOutputFinalizedHandler(func(_ context.Context, outputIndex uint64) error {
+		finalized = append(finalized, outputIndex)
+		return nil
+	})
+
+	// the output 4 is proposed after the processed height, and seen by the handler
+	require.NoError(t, h.loadUnfinalizedOutputs(context.Background(), 300))
+	require.Equal(t, uint64(1), h.LastFinalizedOutputIndex())
+	require.Equal(t, []unfinalizedOutput{
+		{outputIndex: 2, proposedAt: time.Unix(2, 0).UTC()},
+		{outputIndex: 3, proposedAt: time.Unix(3, 0).UTC()},
+	}, h.unfinalizedOutputs)
+
+	// the restored outputs are finalized after the restart
+	require.NoError(t, h.finalizeOutputs(context.Background(), time.Unix(13, 0)))
+	require.Equal(t, []uint64{3}, finalized)
+}
+
+type mockNoKeyChildNode struct {
+	childNode
+}
+
+func (m *mockNoKeyChildNode) HasKey() bool {
+	return false
+}
+
+func Test_OutputProposedTimes(t *testing.T) {
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db.NewMemDB(), zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{FinalizationPeriod: time.Hour},
+	})
+	h.child = &mockNoKeyChildNode{}
+
+	proposeEvent := func(outputIndex uint64, blockTime time.Time) nodetypes.EventHandlerArgs {
+		return nodetypes.EventHandlerArgs{
+			BlockTime: blockTime,
+			EventAttributes: []abci.EventAttribute{
+				{Key: ophosttypes.AttributeKeyProposer, Value: "proposer"},
+				{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"},
+				{Key: ophosttypes.AttributeKeyOutputIndex, Value: strconv.FormatUint(outputIndex, 10)},
+				{Key: ophosttypes.AttributeKeyL2BlockNumber, Value: strconv.FormatUint(outputIndex*10, 10)},
+				{Key: ophosttypes.AttributeKeyOutputRoot, Value: "00"},
+			},
+		}
+	}
+	handleBlock := func(height int64, blockTime time.Time, events ...nodetypes.EventHandlerArgs) {
+		require.NoError(t, h.beginBlockHandler(context.Background(), nodetypes.BeginBlockArgs{}))
+		for _, event := range events {
+			require.NoError(t, h.proposeOutputHandler(context.Background(), event))
+		}
+		require.NoError(t, h.endBlockHandler(context.Background(), nodetypes.EndBlockArgs{
+			Block: cmtproto.Block{Header: cmtproto.Header{Height: height, Time: blockTime}},
+		}))
+	}
+
+	// the l1 block times of the outputs observed live are saved at the end of the block
+	blockTime := time.Unix(100, 0)
+	handleBlock(1, blockTime, proposeEvent(1, blockTime), proposeEvent(2, blockTime))
+	handleBlock(2, blockTime.Add(time.Second), proposeEvent(3, blockTime.Add(time.Second)))
+	for outputIndex, proposedAt := range map[uint64]time.Time{1: blockTime, 2: blockTime, 3: blockTime.Add(time.Second)} {
+		claimableAt, err := h.OutputClaimableAt(context.Background(), outputIndex)
+		require.NoError(t, err)
+		require.Equal(t, proposedAt.Add(time.Hour).UTC(), claimableAt)
+	}
+
+	// the times of the deleted outputs are removed
+	require.NoError(t, h.deleteOutputHandler(context.Background(), nodetypes.EventHandlerArgs{
+		EventAttributes: []abci.EventAttribute{
+			{Key: ophosttypes.AttributeKeyChallenger, Value: "challenger"},
+			{Key: ophosttypes.AttributeKeyBridgeId, Value: "1"},
+			{Key: ophosttypes.AttributeKeyOutputIndex, Value: "2"},
+		},
+	}))
+	_, err = h.DB().Get(hostprovider.PrefixedOutputProposedTimeKey(2))
+	require.Error(t, err)
+	_, err = h.OutputProposedTime(context.Background(), 1)
+	require.NoError(t, err)
+}
@@ -0,0 +1,159 @@
+package host
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// txConfirmedHandler records the outputs proposed by the confirmed tx of the host.
+func (h *Host) txConfirmedHandler(ctx context.Context, args nodetypes.TxConfirmedArgs) error {
+	if !slices.Contains(args.MsgTypes, sdk.MsgTypeURL(&ophosttypes.MsgProposeOutput{})) {
+		return nil
+	}
+
+	for _, event := range args.Events {
+		if event.Type != ophosttypes.EventTypeProposeOutput {
+			continue
+		}
+
+		bridgeId, l2BlockNumber, outputIndex, _, outputRoot, err := hostprovider.ParseMsgProposeOutput(event.Attributes)
+		if err != nil {
+			return err
+		} else if bridgeId != h.BridgeId() {
+			continue
+		}
+
+		info := executortypes.ProposedOutputInfo{
+			OutputIndex:   outputIndex,
+			L2BlockNumber: l2BlockNumber,
+			Root:          outputRoot,
+			L1Height:      args.BlockHeight,
+			L1TxHash:      args.TxHash,
+			Timestamp:     args.BlockTime.UnixNano(),
+		}
+		err = h.saveProposedOutput(info)
+		if err != nil {
+			return err
+		}
+
+		for _, fn := range h.outputProposedHandlers {
+			if err := fn(ctx, info); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RegisterOutputProposedHandler registers the callback which is called with the output proposed by the
+// confirmed tx of the host, e.g. to cross-check the output root reported by the chain.
+func (h *Host) RegisterOutputProposedHandler(fn func(context.Context, executortypes.ProposedOutputInfo) error) {
+	h.outputProposedHandlers = append(h.outputProposedHandlers, fn)
+}
+
+// backfillProposedOutputs records the outputs proposed on the chain after the last record,
+// e.g. the outputs confirmed while the bot was down. It does nothing if there is no record yet.
+func (h *Host) backfillProposedOutputs(ctx context.Context) error {
+	last, err := h.lastProposedOutput()
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// the outputs deleted by the challenger are not returned
+	lastIndex := last.OutputIndex
+	err = h.IterateOutputs(ctx, h.BridgeId(), last.OutputIndex+1, 0, func(output ophosttypes.QueryOutputProposalResponse) (bool, error) {
+		lastIndex = output.OutputIndex
+		return false, h.saveProposedOutput(executortypes.ProposedOutputInfo{
+			OutputIndex:   output.OutputIndex,
+			L2BlockNumber: types.MustUint64ToInt64(output.OutputProposal.L2BlockNumber),
+			Root:          output.OutputProposal.OutputRoot,
+			L1Height:      types.MustUint64ToInt64(output.OutputProposal.L1BlockNumber),
+			Timestamp:     output.OutputProposal.L1BlockTime.UnixNano(),
+		})
+	})
+	if err != nil {
+		return err
+	} else if lastIndex == last.OutputIndex {
+		return nil
+	}
+
+	h.Logger().Info("backfill proposed outputs",
+		zap.Uint64("from", last.OutputIndex+1),
+		zap.Uint64("to", lastIndex),
+	)
+	return nil
+}
+
+// saveProposedOutput saves the record of the proposed output. The same output is not written again,
+// and the record confirmed with the tx hash is not overwritten by the backfilled one.
+func (h *Host) saveProposedOutput(info executortypes.ProposedOutputInfo) error {
+	existing, err := h.GetProposedOutput(info.OutputIndex)
+	if err != nil && !errors.Is(err, dbtypes.ErrNotFound) {
+		return err
+	} else if err == nil &&
+		existing.L2BlockNumber == info.L2BlockNumber &&
+		bytes.Equal(existing.Root, info.Root) &&
+		(info.L1TxHash == "" || info.L1TxHash == existing.L1TxHash) {
+		return nil
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return h.DB().Set(executortypes.PrefixedProposedOutputKey(info.OutputIndex), data)
+}
+
+// GetProposedOutput returns the record of the proposed output of the index.
+func (h Host) GetProposedOutput(outputIndex uint64) (executortypes.ProposedOutputInfo, error) {
+	data, err := h.DB().Get(executortypes.PrefixedProposedOutputKey(outputIndex))
+	if err != nil {
+		return executortypes.ProposedOutputInfo{}, err
+	}
+
+	var info executortypes.ProposedOutputInfo
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+// IterateProposedOutputs iterates the records of the proposed outputs in the order of the output index.
+func (h Host) IterateProposedOutputs(fn func(executortypes.ProposedOutputInfo) (stop bool, err error)) error {
+	return h.DB().PrefixedIterate(executortypes.ProposedOutputKey, nil, func(_, value []byte) (bool, error) {
+		var info executortypes.ProposedOutputInfo
+		if err := json.Unmarshal(value, &info); err != nil {
+			return true, err
+		}
+		return fn(info)
+	})
+}
+
+func (h Host) lastProposedOutput() (executortypes.ProposedOutputInfo, error) {
+	var last *executortypes.ProposedOutputInfo
+	err := h.DB().PrefixedReverseIterate(executortypes.ProposedOutputKey, nil, func(_, value []byte) (bool, error) {
+		last = &executortypes.ProposedOutputInfo{}
+		return true, json.Unmarshal(value, last)
+	})
+	if err != nil {
+		return executortypes.ProposedOutputInfo{}, err
+	} else if last == nil {
+		return executortypes.ProposedOutputInfo{}, dbtypes.ErrNotFound
+	}
+	return *last, nil
+}
@@ -0,0 +1,211 @@
+package host
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	query "github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/gogoproto/proto"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func testOutputProposal(outputIndex uint64) ophosttypes.QueryOutputProposalResponse {
+	return ophosttypes.QueryOutputProposalResponse{
+		BridgeId:    1,
+		OutputIndex: outputIndex,
+		OutputProposal: ophosttypes.Output{
+			OutputRoot:    []byte{byte(outputIndex)},
+			L1BlockNumber: outputIndex * 100,
+			L1BlockTime:   time.Unix(int64(outputIndex), 0).UTC(),
+			L2BlockNumber: outputIndex * 10,
+		},
+	}
+}
+
+// newMockOutputServer serves the output proposals from 1 to `lastIndex`, and the first output as the last finalized output.
+func newMockOutputServer(t *testing.T, lastIndex uint64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Path string `json:"path"`
+				Data string `json:"data"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "abci_query" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		data, err := hex.DecodeString(req.Params.Data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var res proto.Message
+		switch req.Params.Path {
+		case "/opinit.ophost.v1.Query/OutputProposals":
+			var outputsReq ophosttypes.QueryOutputProposalsRequest
+			if err := proto.Unmarshal(data, &outputsReq); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			outputsRes := &ophosttypes.QueryOutputProposalsResponse{Pagination: &query.PageResponse{}}
+			if outputsReq.Pagination.Reverse {
+				outputsRes.OutputProposals = append(outputsRes.OutputProposals, testOutputProposal(lastIndex))
+			} else {
+				outputIndex := binary.BigEndian.Uint64(outputsReq.Pagination.Key)
+				for ; outputIndex <= lastIndex && uint64(len(outputsRes.OutputProposals)) < outputsReq.Pagination.Limit; outputIndex++ {
+					outputsRes.OutputProposals = append(outputsRes.OutputProposals, testOutputProposal(outputIndex))
+				}
+				if outputIndex <= lastIndex {
+					outputsRes.Pagination.NextKey = binary.BigEndian.AppendUint64(nil, outputIndex)
+				}
+			}
+			res = outputsRes
+		case "/opinit.ophost.v1.Query/OutputProposal":
+			var outputReq ophosttypes.QueryOutputProposalRequest
+			if err := proto.Unmarshal(data, &outputReq); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			output := testOutputProposal(outputReq.OutputIndex)
+			res = &output
+		case "/opinit.ophost.v1.Query/LastFinalizedOutput":
+			output := testOutputProposal(1)
+			res = &ophosttypes.QueryLastFinalizedOutputResponse{
+				OutputIndex:    output.OutputIndex,
+				OutputProposal: output.OutputProposal,
+			}
+		default:
+			http.Error(w, "unknown path", http.StatusBadRequest)
+			return
+		}
+
+		bz, err := proto.Marshal(res)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result, err := cmtjson.Marshal(&rpccoretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: bz}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  json.RawMessage(result),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_ProposedOutputs(t *testing.T) {
+	server := newMockOutputServer(t, 4)
+
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHostV1(nodetypes.NodeConfig{
+		RPC:          server.URL,
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+
+	// nothing to backfill without records
+	require.NoError(t, h.backfillProposedOutputs(context.Background()))
+	_, err = h.lastProposedOutput()
+	require.Error(t, err)
+
+	proposeOutputEvent := func(bridgeId string) abci.Event {
+		return abci.Event{
+			Type: ophosttypes.EventTypeProposeOutput,
+			Attributes: []abci.EventAttribute{
+				{Key: ophosttypes.AttributeKeyProposer, Value: "proposer"},
+				{Key: ophosttypes.AttributeKeyBridgeId, Value: bridgeId},
+				{Key: ophosttypes.AttributeKeyOutputIndex, Value: "1"},
+				{Key: ophosttypes.AttributeKeyL2BlockNumber, Value: "10"},
+				{Key: ophosttypes.AttributeKeyOutputRoot, Value: hex.EncodeToString([]byte{1})},
+			},
+		}
+	}
+	confirmed := nodetypes.TxConfirmedArgs{
+		BlockHeight: 100,
+		BlockTime:   time.Unix(1, 0),
+		TxHash:      "TXHASH",
+		MsgTypes:    []string{"/opinit.ophost.v1.MsgProposeOutput"},
+		Events:      []abci.Event{proposeOutputEvent("2"), proposeOutputEvent("1")},
+	}
+	require.NoError(t, h.txConfirmedHandler(context.Background(), confirmed))
+
+	expected := executortypes.ProposedOutputInfo{
+		OutputIndex:   1,
+		L2BlockNumber: 10,
+		Root:          []byte{1},
+		L1Height:      100,
+		L1TxHash:      "TXHASH",
+		Timestamp:     time.Unix(1, 0).UnixNano(),
+	}
+	output, err := h.GetProposedOutput(1)
+	require.NoError(t, err)
+	require.Equal(t, expected, output)
+
+	// the outputs after the last record are backfilled from the chain
+	require.NoError(t, h.backfillProposedOutputs(context.Background()))
+	outputs := make([]executortypes.ProposedOutputInfo, 0)
+	require.NoError(t, h.IterateProposedOutputs(func(output executortypes.ProposedOutputInfo) (bool, error) {
+		outputs = append(outputs, output)
+		return false, nil
+	}))
+	require.Len(t, outputs, 4)
+	require.Equal(t, expected, outputs[0])
+	for i, output := range outputs[1:] {
+		outputIndex := uint64(i + 2)
+		require.Equal(t, outputIndex, output.OutputIndex)
+		require.Equal(t, int64(outputIndex*10), output.L2BlockNumber)
+		require.Equal(t, []byte{byte(outputIndex)}, output.Root)
+		require.Equal(t, int64(outputIndex*100), output.L1Height)
+		require.Empty(t, output.L1TxHash)
+	}
+
+	// writes are idempotent, and the confirmed record is not overwritten by the backfilled one
+	require.NoError(t, h.txConfirmedHandler(context.Background(), confirmed))
+	require.NoError(t, h.saveProposedOutput(executortypes.ProposedOutputInfo{OutputIndex: 1, L2BlockNumber: 10, Root: []byte{1}, L1Height: 100}))
+	output, err = h.GetProposedOutput(1)
+	require.NoError(t, err)
+	require.Equal(t, expected, output)
+
+	last, err := h.lastProposedOutput()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), last.OutputIndex)
+
+	// the other txs are ignored
+	require.NoError(t, h.txConfirmedHandler(context.Background(), nodetypes.TxConfirmedArgs{
+		MsgTypes: []string{"/opinit.ophost.v1.MsgFinalizeTokenWithdrawal"},
+		Events:   []abci.Event{proposeOutputEvent("1")},
+	}))
+}
@@ -0,0 +1,31 @@
+package host
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
+)
+
+// freezeBridgeHandler switches the child into the read-only mode when the bridge is frozen on the host.
+func (h *Host) freezeBridgeHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
+	bridgeId, err := hostprovider.ParseFreezeBridge(args.EventAttributes)
+	if err != nil {
+		return err
+	}
+	h.Logger().Warn("bridge frozen", zap.Uint64("bridge_id", bridgeId), zap.Int64("height", args.BlockHeight))
+	return h.child.EnterReadOnlyMode(fmt.Sprintf("bridge frozen at host height %d", args.BlockHeight))
+}
+
+// unfreezeBridgeHandler exits the read-only mode of the child when the freeze of the bridge is lifted.
+func (h *Host) unfreezeBridgeHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
+	bridgeId, err := hostprovider.ParseFreezeBridge(args.EventAttributes)
+	if err != nil {
+		return err
+	}
+	h.Logger().Warn("bridge unfrozen", zap.Uint64("bridge_id", bridgeId), zap.Int64("height", args.BlockHeight))
+	return h.child.ExitReadOnlyMode(fmt.Sprintf("bridge unfrozen at host height %d", args.BlockHeight))
+}
@@ -0,0 +1,82 @@
+package host
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+var (
+	_ btypes.MsgResignerFn = resignProposeOutput
+	_ btypes.MsgResignerFn = resignFinalizeTokenWithdrawal
+)
+
+func resignProposeOutput(msg sdk.Msg, signer string) (sdk.Msg, error) {
+	proposeOutput, ok := msg.(*ophosttypes.MsgProposeOutput)
+	if !ok {
+		return nil, fmt.Errorf("unexpected msg type: %s", sdk.MsgTypeURL(msg))
+	}
+	resigned := *proposeOutput
+	resigned.Proposer = signer
+	return &resigned, nil
+}
+
+func resignFinalizeTokenWithdrawal(msg sdk.Msg, signer string) (sdk.Msg, error) {
+	finalizeWithdrawal, ok := msg.(*ophosttypes.MsgFinalizeTokenWithdrawal)
+	if !ok {
+		return nil, fmt.Errorf("unexpected msg type: %s", sdk.MsgTypeURL(msg))
+	}
+	resigned := *finalizeWithdrawal
+	resigned.Sender = signer
+	return &resigned, nil
+}
+
+// SetStandbyProposerKeys sets the key names which can be rotated to the proposer.
+func (h *Host) SetStandbyProposerKeys(keyNames []string) {
+	h.standbyProposerKeys = keyNames
+}
+
+// rotateProposerKey rotates the key of the output submitter to the standby key of the new proposer.
+func (h *Host) rotateProposerKey(ctx context.Context, proposer string) error {
+	if !h.HasKey() || len(h.standbyProposerKeys) == 0 {
+		return nil
+	}
+
+	broadcaster := h.Node().MustGetBroadcaster()
+	account, err := broadcaster.AccountByIndex(0)
+	if err != nil {
+		return err
+	} else if account.GetAddressString() == proposer {
+		return nil
+	}
+
+	for _, keyName := range h.standbyProposerKeys {
+		keyringConfig := btypes.KeyringConfig{Name: keyName}
+		address, err := broadcaster.KeyAddress(keyringConfig)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the address of the standby proposer key %s", keyName)
+		} else if address != proposer {
+			continue
+		}
+
+		err = broadcaster.RotateKey(ctx, account.KeyName(), keyringConfig)
+		if err != nil {
+			return errors.Wrap(err, "failed to rotate the proposer key")
+		}
+		return nil
+	}
+
+	h.Logger().Warn("no standby key matches the new proposer; outputs can't be proposed until restarted with the proposer key",
+		zap.String("proposer", proposer),
+		zap.String("current", account.GetAddressString()),
+	)
+	return nil
+}
@@ -3,6 +3,8 @@ package host
 import (
 	"errors"
 
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 )
 
@@ -10,6 +12,13 @@ type Status struct {
 	Node                            nodetypes.Status `json:"node"`
 	LastProposedOutputIndex         uint64           `json:"last_proposed_output_index"`
 	LastProposedOutputL2BlockNumber int64            `json:"last_proposed_output_l2_block_number"`
+	// the index of the last output deleted by the challenger, 0 if none
+	LastDeletedOutputIndex uint64 `json:"last_deleted_output_index"`
+	// the index of the last output whose finalization period is elapsed, 0 if none
+	LastFinalizedOutputIndex uint64 `json:"last_finalized_output_index"`
+	LastRelayedL1Sequence    uint64 `json:"last_relayed_l1_sequence"`
+	// the record of the last output proposed by the host, nil if none
+	LastProposedOutput *executortypes.ProposedOutputInfo `json:"last_proposed_output,omitempty"`
 }
 
 func (h Host) GetStatus() (Status, error) {
@@ -18,10 +27,21 @@ func (h Host) GetStatus() (Status, error) {
 		return Status{}, err
 	}
 
+	var lastProposedOutput *executortypes.ProposedOutputInfo
+	if output, err := h.lastProposedOutput(); err == nil {
+		lastProposedOutput = &output
+	} else if !errors.Is(err, dbtypes.ErrNotFound) {
+		return Status{}, err
+	}
+
 	return Status{
 		Node:                            nodeStatus,
 		LastProposedOutputIndex:         h.lastProposedOutputIndex,
 		LastProposedOutputL2BlockNumber: h.lastProposedOutputL2BlockNumber,
+		LastDeletedOutputIndex:          h.lastDeletedOutputIndex,
+		LastFinalizedOutputIndex:        h.lastFinalizedOutputIndex,
+		LastRelayedL1Sequence:           h.lastRelayedL1Sequence,
+		LastProposedOutput:              lastProposedOutput,
 	}, nil
 }
 
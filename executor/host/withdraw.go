@@ -3,10 +3,17 @@ package host
 import (
 	"context"
 	"encoding/base64"
+	"maps"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
 
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	hostprovider "github.com/initia-labs/opinit-bots/provider/host"
-	"go.uber.org/zap"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 func (h *Host) proposeOutputHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
@@ -14,12 +21,12 @@ func (h *Host) proposeOutputHandler(_ context.Context, args nodetypes.EventHandl
 	if err != nil {
 		return err
 	}
-	if bridgeId != h.BridgeId() {
-		// pass other bridge output proposal event
-		return nil
-	}
-
 	h.handleProposeOutput(bridgeId, proposer, outputIndex, l2BlockNumber, outputRoot)
+	h.unfinalizedOutputs = append(h.unfinalizedOutputs, unfinalizedOutput{
+		outputIndex: outputIndex,
+		proposedAt:  args.BlockTime,
+	})
+	h.outputProposedTimes[outputIndex] = args.BlockTime
 	h.lastProposedOutputIndex = outputIndex
 	h.lastProposedOutputL2BlockNumber = l2BlockNumber
 	return nil
@@ -35,16 +42,132 @@ func (h *Host) handleProposeOutput(bridgeId uint64, proposer string, outputIndex
 	)
 }
 
-func (h *Host) finalizeWithdrawalHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
-	bridgeId, outputIndex, l2Sequence, from, to, l1Denom, l2Denom, amount, err := hostprovider.ParseMsgFinalizeWithdrawal(args.EventAttributes)
+// RegisterOutputDeletedHandler registers the callback which is called with the index of the output
+// deleted by the challenger. All the outputs from the index are deleted.
+func (h *Host) RegisterOutputDeletedHandler(fn func(context.Context, uint64) error) {
+	h.outputDeletedHandlers = append(h.outputDeletedHandlers, fn)
+}
+
+func (h *Host) deleteOutputHandler(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+	bridgeId, outputIndex, challenger, err := hostprovider.ParseMsgDeleteOutput(args.EventAttributes)
 	if err != nil {
 		return err
 	}
-	if bridgeId != h.BridgeId() {
-		// pass other bridge withdrawal event
+	h.Logger().Warn("output deleted",
+		zap.Uint64("bridge_id", bridgeId),
+		zap.String("challenger", challenger),
+		zap.Uint64("output_index", outputIndex),
+		zap.Int64("height", args.BlockHeight),
+	)
+	h.lastDeletedOutputIndex = outputIndex
+	h.unfinalizedOutputs = slices.DeleteFunc(h.unfinalizedOutputs, func(output unfinalizedOutput) bool {
+		return output.outputIndex >= outputIndex
+	})
+	maps.DeleteFunc(h.outputProposedTimes, func(index uint64, _ time.Time) bool {
+		return index >= outputIndex
+	})
+	if err := h.DeleteOutputProposedTimes(outputIndex); err != nil {
+		return err
+	}
+
+	for _, fn := range h.outputDeletedHandlers {
+		if err := fn(ctx, outputIndex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type unfinalizedOutput struct {
+	outputIndex uint64
+	proposedAt  time.Time
+}
+
+// RegisterOutputFinalizedHandler registers the callback which is called at the end of every host block
+// with the index of the last output whose finalization period is elapsed, once any output is finalized.
+// It is called even without a new finalized output, so the callback can resume the work limited by a block.
+func (h *Host) RegisterOutputFinalizedHandler(fn func(context.Context, uint64) error) {
+	h.outputFinalizedHandlers = append(h.outputFinalizedHandlers, fn)
+}
+
+// loadUnfinalizedOutputs restores the last finalized output and the unfinalized outputs at the processed height
+// from the chain, as the outputs proposed before the processed height are not seen by the handlers.
+func (h *Host) loadUnfinalizedOutputs(ctx context.Context, processedHeight int64) error {
+	lastFinalizedOutput, err := h.QueryLastFinalizedOutput(ctx, h.BridgeId(), processedHeight)
+	if err != nil {
+		return err
+	}
+	fromIndex := uint64(1)
+	if lastFinalizedOutput != nil {
+		h.lastFinalizedOutputIndex = lastFinalizedOutput.OutputIndex
+		fromIndex = lastFinalizedOutput.OutputIndex + 1
+	}
+
+	unfinalizedOutputs := make([]unfinalizedOutput, 0)
+	err = h.IterateOutputs(ctx, h.BridgeId(), fromIndex, 0, func(output ophosttypes.QueryOutputProposalResponse) (bool, error) {
+		// the outputs proposed after the processed height are seen by the handlers
+		if types.MustUint64ToInt64(output.OutputProposal.L1BlockNumber) > processedHeight {
+			return true, nil
+		}
+		proposedAt := output.OutputProposal.L1BlockTime
+		if proposedAt.IsZero() {
+			var err error
+			proposedAt, err = h.OutputProposedTime(ctx, output.OutputIndex)
+			if err != nil {
+				return false, err
+			}
+		}
+		unfinalizedOutputs = append(unfinalizedOutputs, unfinalizedOutput{
+			outputIndex: output.OutputIndex,
+			proposedAt:  proposedAt,
+		})
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	h.unfinalizedOutputs = unfinalizedOutputs
+	return nil
+}
+
+// LastFinalizedOutputIndex returns the index of the last finalized output known by the host, 0 if none.
+func (h Host) LastFinalizedOutputIndex() uint64 {
+	return h.lastFinalizedOutputIndex
+}
+
+// finalizeOutputs marks the outputs whose finalization period is elapsed at the block time as finalized,
+// and calls the output finalized handlers.
+func (h *Host) finalizeOutputs(ctx context.Context, blockTime time.Time) error {
+	finalizationPeriod := h.BridgeInfo().BridgeConfig.FinalizationPeriod
+	finalized := 0
+	for _, output := range h.unfinalizedOutputs {
+		if output.proposedAt.Add(finalizationPeriod).After(blockTime) {
+			break
+		}
+		h.lastFinalizedOutputIndex = max(h.lastFinalizedOutputIndex, output.outputIndex)
+		finalized++
+	}
+	if finalized > 0 {
+		h.unfinalizedOutputs = h.unfinalizedOutputs[finalized:]
+		h.Logger().Info("output finalized", zap.Uint64("output_index", h.lastFinalizedOutputIndex))
+	}
+
+	if h.lastFinalizedOutputIndex == 0 {
 		return nil
 	}
+	for _, fn := range h.outputFinalizedHandlers {
+		if err := fn(ctx, h.lastFinalizedOutputIndex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+func (h *Host) finalizeWithdrawalHandler(_ context.Context, args nodetypes.EventHandlerArgs) error {
+	bridgeId, outputIndex, l2Sequence, from, to, l1Denom, l2Denom, amount, err := hostprovider.ParseMsgFinalizeWithdrawal(args.EventAttributes)
+	if err != nil {
+		return err
+	}
 	h.handleFinalizeWithdrawal(bridgeId, outputIndex, l2Sequence, from, to, l1Denom, l2Denom, amount)
 	return nil
 }
@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"github.com/initia-labs/opinit-bots/db/integrity"
+	"github.com/initia-labs/opinit-bots/merkle"
+	"github.com/initia-labs/opinit-bots/node"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// IntegrityChecks returns the integrity checks of the executor db, which are run at startup if enabled.
+func IntegrityChecks(db types.DB) []integrity.Check {
+	checks := make([]integrity.Check, 0)
+	for _, nodeName := range []string{
+		types.HostName,
+		types.ChildName,
+		types.BatchName,
+		types.DAHostName,
+		types.DACelestiaName,
+		types.SecondaryDAHostName,
+		types.SecondaryDACelestiaName,
+	} {
+		checks = append(checks, node.IntegrityChecks(nodeName, db.WithPrefix([]byte(nodeName)))...)
+	}
+	return append(checks, merkle.IntegrityChecks(db.WithPrefix([]byte(types.ChildName)).WithPrefix([]byte(types.MerkleName)))...)
+}
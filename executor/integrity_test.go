@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	"github.com/initia-labs/opinit-bots/db/integrity"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_IntegrityChecks(t *testing.T) {
+	root := db.NewMemDB()
+	hostDB := root.WithPrefix([]byte(types.HostName))
+	childDB := root.WithPrefix([]byte(types.ChildName))
+	merkleDB := childDB.WithPrefix([]byte(types.MerkleName))
+
+	// the valid records
+	require.NoError(t, hostDB.Set(nodetypes.LastProcessedBlockHeightKey, dbtypes.FromUint64(10)))
+	pendingTx, err := btypes.PendingTxInfo{Sequence: 1, Tx: []byte("tx"), Save: true}.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, hostDB.Set(btypes.PrefixedPendingTx(1), pendingTx))
+	workingTree, err := merkletypes.TreeInfo{Index: 1, StartLeafIndex: 1, LastSiblings: map[uint8][]byte{}}.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, merkleDB.Set(merkletypes.PrefixedWorkingTreeKey(10), workingTree))
+	for i := uint64(0); i < 20_000; i++ {
+		require.NoError(t, merkleDB.Set(merkletypes.PrefixedNodeKey(1, 0, i), make([]byte, 32)))
+	}
+
+	// the records corrupted by a power loss
+	corrupted := [][]byte{
+		childDB.PrefixedKey(nodetypes.LastProcessedBlockHeightKey),
+		hostDB.PrefixedKey(btypes.PrefixedPendingTx(2)),
+		merkleDB.PrefixedKey(merkletypes.PrefixedWorkingTreeKey(11)),
+		merkleDB.PrefixedKey(merkletypes.PrefixedFinalizedTreeKey(1)),
+		merkleDB.PrefixedKey(merkletypes.PrefixedNodeKey(2, 0, 0)),
+	}
+	for _, key := range corrupted {
+		require.NoError(t, root.RawBatchSet(types.RawKV{Key: key, Value: []byte{0xff, 0x00, 0x01}}))
+	}
+	// the corrupted node out of the sample is not detected
+	require.NoError(t, merkleDB.Set(merkletypes.PrefixedNodeKey(1, 0, 0), []byte{0xff}))
+
+	report, err := integrity.Run(root, zap.NewNop(), false, IntegrityChecks(root)...)
+	require.ErrorIs(t, err, integrity.ErrCorruptedDB)
+	require.Len(t, report.BadRecords, len(corrupted))
+	// the nodes are sampled
+	require.Less(t, report.Checked, 20_000)
+
+	checks := make([]string, 0)
+	for _, record := range report.BadRecords {
+		checks = append(checks, record.Check)
+	}
+	require.ElementsMatch(t, []string{
+		"host/pending_txs",
+		"child/sync_info",
+		"merkle/working_trees",
+		"merkle/finalized_trees",
+		"merkle/tree_nodes",
+	}, checks)
+
+	report, err = integrity.Run(root, zap.NewNop(), true, IntegrityChecks(root)...)
+	require.NoError(t, err)
+	require.Len(t, report.BadRecords, len(corrupted))
+	for _, key := range corrupted {
+		_, err := root.Get(integrity.PrefixedQuarantineKey(key))
+		require.NoError(t, err)
+	}
+
+	// the handlers read the valid records only
+	_, err = childDB.Get(nodetypes.LastProcessedBlockHeightKey)
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+	value, err := merkleDB.Get(merkletypes.PrefixedWorkingTreeKey(10))
+	require.NoError(t, err)
+	require.Equal(t, workingTree, value)
+
+	_, err = integrity.Run(root, zap.NewNop(), false, IntegrityChecks(root)...)
+	require.NoError(t, err)
+}
@@ -0,0 +1,29 @@
+package executor
+
+import (
+	"github.com/initia-labs/opinit-bots/db/migration"
+	"github.com/initia-labs/opinit-bots/merkle"
+	"github.com/initia-labs/opinit-bots/node"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// SchemaName is the name of the schema of the executor data apart from the node and the merkle dbs,
+// e.g. the withdrawals and the batch chunk states.
+const SchemaName = "executor"
+
+// Schemas returns the schemas of the executor db, whose pending migrations are applied by `opinitd migrate schema`.
+func Schemas(db types.DB) []migration.Schema {
+	schemas := []migration.Schema{{Name: SchemaName, DB: db}}
+	for _, nodeName := range []string{
+		types.HostName,
+		types.ChildName,
+		types.BatchName,
+		types.DAHostName,
+		types.DACelestiaName,
+		types.SecondaryDAHostName,
+		types.SecondaryDACelestiaName,
+	} {
+		schemas = append(schemas, node.Schema(db.WithPrefix([]byte(nodeName))))
+	}
+	return append(schemas, merkle.Schema(db.WithPrefix([]byte(types.ChildName)).WithPrefix([]byte(types.MerkleName))))
+}
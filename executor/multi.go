@@ -0,0 +1,267 @@
+package executor
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	bottypes "github.com/initia-labs/opinit-bots/bot/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/logging"
+	"github.com/initia-labs/opinit-bots/server"
+	servertypes "github.com/initia-labs/opinit-bots/server/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+var _ bottypes.Bot = &MultiExecutor{}
+
+// bridgeInstance is a bridge run by the MultiExecutor.
+type bridgeInstance struct {
+	id  string
+	bot bottypes.Bot
+
+	// the components of the bridge are run in their own error group, so a failure
+	// stops only the bridge
+	ctx context.Context
+
+	mu    *sync.Mutex
+	state executortypes.BridgeInstanceState
+	err   error
+}
+
+func (b *bridgeInstance) setState(state executortypes.BridgeInstanceState, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = state
+	b.err = err
+}
+
+func (b *bridgeInstance) Status() executortypes.BridgeInstanceStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := executortypes.BridgeInstanceStatus{ID: b.id, State: b.state}
+	if b.err != nil {
+		status.Error = b.err.Error()
+	}
+	return status
+}
+
+// MultiExecutor runs the executors of the bridges in a process. The bridges share the api server, where the
+// routes of a bridge are served under `/bridges/{id}`, and the db, where the records of a bridge are stored
+// under its own top-level prefix. A failed bridge is stopped without stopping the other bridges, unless
+// the process is started with --fail-fast.
+type MultiExecutor struct {
+	bridges []*bridgeInstance
+
+	db     types.DB
+	server *server.Server
+	logger *zap.Logger
+
+	// closed is closed when the db is closed
+	closed    chan struct{}
+	closeOnce *sync.Once
+}
+
+// NewMultiExecutor creates the executors of the bridges of the config. The batch files of a bridge are
+// written to `{home}/bridges/{id}`, while the keyring of the home is shared by the bridges.
+func NewMultiExecutor(cfg *executortypes.Config, db types.DB, logger *zap.Logger, logLevels *logging.Levels, homePath string) (*MultiExecutor, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	} else if len(cfg.Bridges) == 0 {
+		return nil, errors.New("no bridges in the config")
+	}
+
+	m := newMultiExecutor(cfg.Server, db, logger)
+	for _, bridgeCfg := range cfg.BridgeConfigs() {
+		batchDir := filepath.Join(homePath, types.BridgesName, bridgeCfg.ID)
+		if err := os.MkdirAll(batchDir, 0o755); err != nil {
+			return nil, err
+		}
+
+		ex, err := newExecutor(&bridgeCfg.Config, BridgeDB(db, bridgeCfg.ID), logger.With(zap.String("bridge", bridgeCfg.ID)), logLevels, homePath, batchDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create the bridge %s", bridgeCfg.ID)
+		}
+		ex.mounted = true
+		m.addBridge(bridgeCfg.ID, ex, ex.server.App)
+	}
+	m.RegisterQuerier()
+	return m, nil
+}
+
+func newMultiExecutor(cfg servertypes.ServerConfig, db types.DB, logger *zap.Logger) *MultiExecutor {
+	return &MultiExecutor{
+		db:     db,
+		server: server.NewServer(cfg),
+		logger: logger,
+
+		closed:    make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+}
+
+// BridgeDB returns the db of the bridge instance, whose top-level prefix is distinct from the other bridges
+// and from the prefixes of the single bridge executor.
+func BridgeDB(db types.DB, id string) types.DB {
+	return db.WithPrefix([]byte(types.BridgesName)).WithPrefix([]byte(id))
+}
+
+// addBridge adds the bridge and mounts its api routes under `/bridges/{id}`.
+func (m *MultiExecutor) addBridge(id string, bot bottypes.Bot, app *fiber.App) {
+	m.bridges = append(m.bridges, &bridgeInstance{
+		id:    id,
+		bot:   bot,
+		mu:    &sync.Mutex{},
+		state: executortypes.BridgeInstanceStateInitializing,
+	})
+	m.server.Mount(fmt.Sprintf("/%s/%s", types.BridgesName, id), app)
+}
+
+// Initialize initializes the bridges concurrently. The failed bridges are not started,
+// unless the process is started with --fail-fast, where any failure fails the initialization.
+func (m *MultiExecutor) Initialize(ctx context.Context) error {
+	errs := make([]error, len(m.bridges))
+	wg := sync.WaitGroup{}
+	for i, bridge := range m.bridges {
+		errGrp, bridgeCtx := errgroup.WithContext(ctx)
+		bridge.ctx = types.WithErrGrp(bridgeCtx, errGrp)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = bridge.bot.Initialize(bridge.ctx)
+		}()
+	}
+	wg.Wait()
+
+	initialized := 0
+	for i, bridge := range m.bridges {
+		if errs[i] == nil {
+			initialized++
+			continue
+		} else if types.FailFast(ctx) {
+			return errors.Wrapf(errs[i], "failed to initialize the bridge %s", bridge.id)
+		}
+
+		m.logger.Error("failed to initialize the bridge", zap.String("bridge", bridge.id), zap.String("error", errs[i].Error()))
+		bridge.setState(executortypes.BridgeInstanceStateFailed, errs[i])
+		bridge.bot.Close()
+	}
+	if initialized == 0 {
+		return errors.New("all bridges failed to initialize")
+	}
+	return nil
+}
+
+// Start starts the api server and the initialized bridges, and waits until they are stopped.
+func (m *MultiExecutor) Start(ctx context.Context) error {
+	defer m.Close()
+
+	errGrp := types.ErrGrp(ctx)
+	errGrp.Go(func() (err error) {
+		<-ctx.Done()
+		return m.server.Shutdown()
+	})
+
+	errGrp.Go(func() (err error) {
+		defer func() {
+			m.logger.Info("api server stopped")
+		}()
+		return m.server.Start()
+	})
+
+	for _, bridge := range m.bridges {
+		if bridge.Status().State == executortypes.BridgeInstanceStateFailed {
+			continue
+		}
+
+		bridge.setState(executortypes.BridgeInstanceStateRunning, nil)
+		errGrp.Go(func() error {
+			return m.runBridge(ctx, bridge)
+		})
+	}
+	return errGrp.Wait()
+}
+
+// runBridge runs the bridge until it is stopped. The failure of the bridge is returned
+// to stop the other bridges only if the process is started with --fail-fast.
+func (m *MultiExecutor) runBridge(ctx context.Context, bridge *bridgeInstance) error {
+	err := bridge.bot.Start(bridge.ctx)
+	if err == nil || ctx.Err() != nil {
+		bridge.setState(executortypes.BridgeInstanceStateStopped, nil)
+		return nil
+	} else if types.FailFast(ctx) {
+		bridge.setState(executortypes.BridgeInstanceStateFailed, err)
+		return errors.Wrapf(err, "bridge %s failed", bridge.id)
+	}
+
+	m.logger.Error("bridge failed; the other bridges keep running", zap.String("bridge", bridge.id), zap.String("error", err.Error()))
+	bridge.setState(executortypes.BridgeInstanceStateFailed, err)
+	return nil
+}
+
+func (m *MultiExecutor) Close() {
+	m.closeOnce.Do(func() {
+		m.db.Close()
+		close(m.closed)
+	})
+}
+
+// Shutdown flushes the state of the running bridges concurrently, and then stops the bridges and the api
+// server by the stop function before the db is closed. Each stage is bounded by the stage timeout.
+func (m *MultiExecutor) Shutdown(ctx context.Context, stageTimeout time.Duration, stop context.CancelFunc) error {
+	errs := make([]error, len(m.bridges))
+	wg := sync.WaitGroup{}
+	for i, bridge := range m.bridges {
+		ex, ok := bridge.bot.(*Executor)
+		if !ok || bridge.Status().State != executortypes.BridgeInstanceStateRunning {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := runShutdownStages(ctx, ex.logger, stageTimeout, ex.flushStages()...)
+			ex.logger.Info("shutdown summary", zap.Any("stages", results), zap.Bool("clean", err == nil))
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "failed to flush the bridge %s", bridge.id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	results, err := runShutdownStages(ctx, m.logger, stageTimeout, shutdownStage{
+		name: ShutdownStageCloseDB,
+		run: func(ctx context.Context) error {
+			stop()
+			return waitStopped(ctx, m.closed, "multi executor")
+		},
+	})
+	m.logger.Info("shutdown summary", zap.Any("stages", results), zap.Bool("clean", err == nil))
+	return stderrors.Join(append(errs, err)...)
+}
+
+// BridgeStatuses returns the states of the bridges in the order of the config.
+func (m *MultiExecutor) BridgeStatuses() []executortypes.BridgeInstanceStatus {
+	statuses := make([]executortypes.BridgeInstanceStatus, 0, len(m.bridges))
+	for _, bridge := range m.bridges {
+		statuses = append(statuses, bridge.Status())
+	}
+	return statuses
+}
+
+func (m *MultiExecutor) RegisterQuerier() {
+	m.server.RegisterQuerier("/"+types.BridgesName, func(c *fiber.Ctx) error {
+		return c.JSON(m.BridgeStatuses())
+	})
+}
@@ -0,0 +1,218 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/initia-labs/opinit-bots/db"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	servertypes "github.com/initia-labs/opinit-bots/server/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// mockBridge runs until the context is done or it fails with the error sent to the channel.
+type mockBridge struct {
+	id      string
+	initErr error
+	fail    chan error
+	db      types.DB
+
+	started atomic.Bool
+	closed  atomic.Bool
+}
+
+func newMockBridge(database types.DB, id string) *mockBridge {
+	return &mockBridge{id: id, fail: make(chan error, 1), db: BridgeDB(database, id)}
+}
+
+func (m *mockBridge) Initialize(_ context.Context) error {
+	if m.initErr != nil {
+		return m.initErr
+	}
+	return m.db.Set([]byte("status"), []byte(m.id))
+}
+
+func (m *mockBridge) Start(ctx context.Context) error {
+	defer m.Close()
+	m.started.Store(true)
+
+	// the components of the bridge run in the error group of the bridge
+	errGrp := types.ErrGrp(ctx)
+	errGrp.Go(func() error {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-m.fail:
+			return err
+		}
+	})
+	return errGrp.Wait()
+}
+
+func (m *mockBridge) Close() {
+	m.closed.Store(true)
+}
+
+func (m *mockBridge) app() *fiber.App {
+	app := fiber.New()
+	app.Get("/status", func(c *fiber.Ctx) error {
+		status, err := m.db.Get([]byte("status"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(map[string]string{"id": string(status)})
+	})
+	app.Get("/withdrawal/:sequence", func(c *fiber.Ctx) error {
+		return c.JSON(map[string]string{"id": m.id, "sequence": c.Params("sequence")})
+	})
+	return app
+}
+
+func newMultiExecutorTest(t *testing.T, ids ...string) (*MultiExecutor, []*mockBridge) {
+	database := db.NewMemDB()
+	m := newMultiExecutor(servertypes.ServerConfig{Address: "127.0.0.1:0"}, database, zap.NewNop())
+	bridges := make([]*mockBridge, 0, len(ids))
+	for _, id := range ids {
+		bridge := newMockBridge(database, id)
+		m.addBridge(id, bridge, bridge.app())
+		bridges = append(bridges, bridge)
+	}
+	m.RegisterQuerier()
+	return m, bridges
+}
+
+// startMultiExecutor starts the executor in the background, and returns the channel of the result.
+func startMultiExecutor(t *testing.T, ctx context.Context, m *MultiExecutor) <-chan error {
+	errGrp, ctx := errgroup.WithContext(ctx)
+	ctx = types.WithErrGrp(ctx, errGrp)
+	require.NoError(t, m.Initialize(ctx))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Start(ctx)
+	}()
+	return done
+}
+
+func bridgeStates(m *MultiExecutor) map[string]executortypes.BridgeInstanceState {
+	states := make(map[string]executortypes.BridgeInstanceState)
+	for _, status := range m.BridgeStatuses() {
+		states[status.ID] = status.State
+	}
+	return states
+}
+
+func Test_MultiExecutor(t *testing.T) {
+	m, bridges := newMultiExecutorTest(t, "minimove", "miniwasm")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := startMultiExecutor(t, ctx, m)
+	require.Eventually(t, func() bool { return bridges[0].started.Load() && bridges[1].started.Load() }, 5*time.Second, time.Millisecond)
+
+	// the routes of the bridges are served side by side
+	for _, id := range []string{"minimove", "miniwasm"} {
+		var status map[string]string
+		require.Equal(t, http.StatusOK, get(t, m.server.App, "/bridges/"+id+"/status", &status))
+		require.Equal(t, id, status["id"])
+
+		var withdrawal map[string]string
+		require.Equal(t, http.StatusOK, get(t, m.server.App, "/bridges/"+id+"/withdrawal/3", &withdrawal))
+		require.Equal(t, map[string]string{"id": id, "sequence": "3"}, withdrawal)
+	}
+	require.Equal(t, http.StatusNotFound, get(t, m.server.App, "/bridges/unknown/status", nil))
+
+	// the failed bridge doesn't stop the other bridge
+	bridges[0].fail <- errors.New("l1 node is down")
+	require.Eventually(t, func() bool {
+		return bridgeStates(m)["minimove"] == executortypes.BridgeInstanceStateFailed
+	}, 5*time.Second, time.Millisecond)
+	require.True(t, bridges[0].closed.Load())
+	require.False(t, bridges[1].closed.Load())
+
+	var statuses []executortypes.BridgeInstanceStatus
+	require.Equal(t, http.StatusOK, get(t, m.server.App, "/bridges", &statuses))
+	require.Equal(t, []executortypes.BridgeInstanceStatus{
+		{ID: "minimove", State: executortypes.BridgeInstanceStateFailed, Error: "l1 node is down"},
+		{ID: "miniwasm", State: executortypes.BridgeInstanceStateRunning},
+	}, statuses)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.True(t, bridges[1].closed.Load())
+	require.Equal(t, executortypes.BridgeInstanceStateStopped, bridgeStates(m)["miniwasm"])
+}
+
+func Test_MultiExecutorFailFast(t *testing.T) {
+	m, bridges := newMultiExecutorTest(t, "minimove", "miniwasm")
+	ctx := types.WithFailFast(context.Background(), true)
+	done := startMultiExecutor(t, ctx, m)
+	require.Eventually(t, func() bool { return bridges[0].started.Load() && bridges[1].started.Load() }, 5*time.Second, time.Millisecond)
+
+	// the failed bridge stops the other bridge
+	bridges[0].fail <- errors.New("l1 node is down")
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, "bridge minimove failed: l1 node is down")
+	case <-time.After(5 * time.Second):
+		t.Fatal("multi executor is not stopped")
+	}
+	require.True(t, bridges[1].closed.Load())
+}
+
+func Test_MultiExecutorInitializeFailure(t *testing.T) {
+	m, bridges := newMultiExecutorTest(t, "minimove", "miniwasm")
+	bridges[0].initErr = errors.New("bridge info is not set")
+
+	// the bridge failed to initialize is not started
+	ctx, cancel := context.WithCancel(context.Background())
+	done := startMultiExecutor(t, ctx, m)
+	require.Eventually(t, func() bool { return bridges[1].started.Load() }, 5*time.Second, time.Millisecond)
+	require.False(t, bridges[0].started.Load())
+	require.True(t, bridges[0].closed.Load())
+	require.Equal(t, executortypes.BridgeInstanceStateFailed, bridgeStates(m)["minimove"])
+	cancel()
+	require.NoError(t, <-done)
+
+	// any failure fails the initialization with --fail-fast
+	m, bridges = newMultiExecutorTest(t, "minimove", "miniwasm")
+	bridges[1].initErr = errors.New("bridge info is not set")
+	errGrp, ctx := errgroup.WithContext(types.WithFailFast(context.Background(), true))
+	ctx = types.WithErrGrp(ctx, errGrp)
+	require.ErrorContains(t, m.Initialize(ctx), "failed to initialize the bridge miniwasm")
+}
+
+func Test_BridgeDB(t *testing.T) {
+	database := db.NewMemDB()
+
+	// the same keys of the bridges never collide, even with the prefixes of the single bridge executor
+	require.NoError(t, BridgeDB(database, "minimove").WithPrefix([]byte(types.HostName)).Set([]byte("key"), []byte("minimove")))
+	require.NoError(t, BridgeDB(database, "miniwasm").WithPrefix([]byte(types.HostName)).Set([]byte("key"), []byte("miniwasm")))
+	require.NoError(t, database.WithPrefix([]byte(types.HostName)).Set([]byte("key"), []byte("single")))
+
+	value, err := BridgeDB(database, "minimove").WithPrefix([]byte(types.HostName)).Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("minimove"), value)
+	value, err = BridgeDB(database, "miniwasm").WithPrefix([]byte(types.HostName)).Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("miniwasm"), value)
+	value, err = database.WithPrefix([]byte(types.HostName)).Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("single"), value)
+
+	// the bridge whose id is the prefix of another bridge id doesn't see the records of the other bridge
+	count := 0
+	require.NoError(t, BridgeDB(database, "mini").PrefixedIterate(nil, nil, func(_, _ []byte) (bool, error) {
+		count++
+		return false, nil
+	}))
+	require.Zero(t, count)
+}
@@ -0,0 +1,169 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/initia-labs/opinit-bots/db/pruning"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// claimedWithdrawalsPageSize is the number of the withdrawals whose claimed status is queried at once.
+const claimedWithdrawalsPageSize = 100
+
+// maxClaimedWithdrawalsPages is the maximum number of the pages queried in a prune,
+// which bounds the queries of the first prune of a large db.
+const maxClaimedWithdrawalsPages = 100
+
+// newPruner creates the pruner of the policies enabled by the config.
+func (ex *Executor) newPruner() *pruning.Pruner {
+	cfg := ex.cfg.Pruning
+	policies := make([]pruning.Policy, 0)
+
+	// the records of the txs already broadcasted by the nodes; the processed msgs waiting to be broadcasted
+	// are never pruned, not to drop the deposits and the outputs before they are broadcasted
+	for _, nodeName := range broadcasterNodeNames {
+		nodeDB := ex.db.WithPrefix([]byte(nodeName))
+		if cfg.PendingTxsRetention > 0 {
+			policies = append(policies, pruning.RangePolicy{
+				PolicyName: nodeName + "/pending_txs",
+				DB:         nodeDB,
+				Prefix:     btypes.PendingTxsKey,
+				KeyFn:      btypes.PrefixedPendingTx,
+				CutoffFn:   pruning.RetentionCutoff(time.Now, time.Duration(cfg.PendingTxsRetention)*time.Second),
+			})
+		}
+	}
+
+	merkleDB := ex.db.WithPrefix([]byte(types.ChildName)).WithPrefix([]byte(types.MerkleName))
+	if cfg.KeepWorkingTrees > 0 {
+		policies = append(policies, pruning.RangePolicy{
+			PolicyName: "working_trees",
+			DB:         merkleDB,
+			Prefix:     merkletypes.WorkingTreeKey,
+			KeyFn:      merkletypes.PrefixedWorkingTreeKey,
+			CutoffFn: func(context.Context) (uint64, error) {
+				// the working tree versions are the l2 heights, and the node height is the next height to process
+				height, err := types.SafeInt64ToUint64(ex.child.Node().GetHeight())
+				if err != nil || height <= cfg.KeepWorkingTrees {
+					return 0, err
+				}
+				return height - cfg.KeepWorkingTrees, nil
+			},
+		})
+	}
+
+	if cfg.PruneClaimedTrees {
+		policies = append(policies, claimedTreesPolicy{
+			childDB: ex.db.WithPrefix([]byte(types.ChildName)),
+			merkle:  ex.child.Merkle(),
+			host:    ex.host,
+		})
+	}
+
+	return pruning.NewPruner(ex.db, ex.logger.Named("pruner"), time.Duration(cfg.Interval)*time.Second, policies...)
+}
+
+type claimedQuerier interface {
+	BridgeId() uint64
+	QueryClaimedBulk(ctx context.Context, bridgeId uint64, withdrawalHashes [][]byte) ([]bool, error)
+}
+
+type treeNodesPruner interface {
+	TreeIndexOfLeaf(leafIndex uint64) (uint64, error)
+	DeleteTreeNodes(fromTreeIndex uint64, toTreeIndex uint64) (int, error)
+}
+
+// claimedTreesPolicy prunes the nodes of the finalized trees whose withdrawals are all claimed on l1.
+// Its watermark is the oldest unclaimed withdrawal sequence, and the trees from the one of the oldest
+// unclaimed withdrawal are kept, so the proofs of the unclaimed withdrawals are always available.
+type claimedTreesPolicy struct {
+	childDB types.DB
+	merkle  treeNodesPruner
+	host    claimedQuerier
+}
+
+var _ pruning.Policy = claimedTreesPolicy{}
+
+func (p claimedTreesPolicy) Name() string {
+	return "claimed_trees"
+}
+
+func (p claimedTreesPolicy) Prune(ctx context.Context, watermark uint64) (uint64, int, error) {
+	oldestUnclaimed, err := p.oldestUnclaimedSequence(ctx, max(watermark, 1))
+	if err != nil || oldestUnclaimed <= watermark {
+		return watermark, 0, err
+	}
+
+	var fromTreeIndex uint64
+	if watermark > 0 {
+		fromTreeIndex, err = p.merkle.TreeIndexOfLeaf(watermark)
+		if err != nil {
+			return watermark, 0, err
+		}
+	}
+	toTreeIndex, err := p.merkle.TreeIndexOfLeaf(oldestUnclaimed)
+	if err != nil {
+		return watermark, 0, err
+	} else if toTreeIndex <= fromTreeIndex {
+		return oldestUnclaimed, 0, nil
+	}
+
+	count, err := p.merkle.DeleteTreeNodes(fromTreeIndex, toTreeIndex)
+	if err != nil {
+		return watermark, count, err
+	}
+	return oldestUnclaimed, count, nil
+}
+
+// oldestUnclaimedSequence queries the claimed status of the withdrawals from the given sequence in pages,
+// and returns the sequence of the first unclaimed withdrawal. If the withdrawals of all the pages are claimed,
+// it returns the sequence after the last one queried.
+func (p claimedTreesPolicy) oldestUnclaimedSequence(ctx context.Context, sequence uint64) (uint64, error) {
+	prefix := executortypes.WithdrawalKey
+	for page := 0; page < maxClaimedWithdrawalsPages; page++ {
+		withdrawals := make([]executortypes.WithdrawalData, 0, claimedWithdrawalsPageSize)
+		err := p.childDB.PrefixedRangeIterate(
+			prefix,
+			executortypes.PrefixedWithdrawalKey(sequence),
+			executortypes.PrefixedWithdrawalKey(sequence+claimedWithdrawalsPageSize),
+			func(key, value []byte) (bool, error) {
+				// skip the address index records
+				if len(key) != len(prefix)+1+8 {
+					return false, nil
+				}
+				var data executortypes.WithdrawalData
+				if err := json.Unmarshal(value, &data); err != nil {
+					return true, err
+				}
+				withdrawals = append(withdrawals, data)
+				return false, nil
+			},
+		)
+		if err != nil {
+			return 0, err
+		} else if len(withdrawals) == 0 {
+			return sequence, nil
+		}
+
+		withdrawalHashes := make([][]byte, len(withdrawals))
+		for i, withdrawal := range withdrawals {
+			withdrawalHashes[i] = withdrawal.WithdrawalHash
+		}
+		claimed, err := p.host.QueryClaimedBulk(ctx, p.host.BridgeId(), withdrawalHashes)
+		if err != nil {
+			return 0, err
+		}
+		for i, withdrawal := range withdrawals {
+			if !claimed[i] {
+				return withdrawal.Sequence, nil
+			}
+		}
+		sequence = withdrawals[len(withdrawals)-1].Sequence + 1
+	}
+	return sequence, nil
+}
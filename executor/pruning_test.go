@@ -0,0 +1,136 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	"github.com/initia-labs/opinit-bots/merkle"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+type mockClaimedQuerier struct {
+	claimed map[string]bool
+	queried int
+}
+
+func (m *mockClaimedQuerier) BridgeId() uint64 {
+	return 1
+}
+
+func (m *mockClaimedQuerier) QueryClaimedBulk(_ context.Context, _ uint64, withdrawalHashes [][]byte) ([]bool, error) {
+	m.queried += len(withdrawalHashes)
+	claimed := make([]bool, len(withdrawalHashes))
+	for i, hash := range withdrawalHashes {
+		claimed[i] = m.claimed[string(hash)]
+	}
+	return claimed, nil
+}
+
+// newClaimedTreesTest creates the trees of 2 withdrawals from the sequence 1 and their withdrawal records.
+func newClaimedTreesTest(t *testing.T, numTrees uint64) (types.DB, *merkle.Merkle) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	childDB := db.WithPrefix([]byte(types.ChildName))
+	m, err := merkle.NewMerkle(childDB.WithPrefix([]byte(types.MerkleName)), zap.NewNop(), ophosttypes.GenerateNodeHash)
+	require.NoError(t, err)
+
+	sequence := uint64(1)
+	for treeIndex := uint64(1); treeIndex <= numTrees; treeIndex++ {
+		require.NoError(t, m.InitializeWorkingTree(treeIndex, sequence))
+		for range 2 {
+			hash := []byte{byte(sequence)}
+			data, err := json.Marshal(executortypes.WithdrawalData{
+				Sequence:       sequence,
+				From:           "from",
+				To:             "to",
+				WithdrawalHash: hash,
+			})
+			require.NoError(t, err)
+			require.NoError(t, childDB.Set(executortypes.PrefixedWithdrawalKey(sequence), data))
+			require.NoError(t, childDB.Set(executortypes.PrefixedWithdrawalKeyAddressIndex("to", sequence), dbtypes.FromUint64(sequence)))
+			require.NoError(t, m.InsertLeaf(hash))
+			sequence++
+		}
+		kvs, _, err := m.FinalizeWorkingTree(nil)
+		require.NoError(t, err)
+		require.NoError(t, db.RawBatchSet(kvs...))
+	}
+	return childDB, m
+}
+
+func Test_ClaimedTreesPolicy(t *testing.T) {
+	childDB, m := newClaimedTreesTest(t, 3)
+	host := &mockClaimedQuerier{claimed: map[string]bool{
+		string([]byte{1}): true,
+		string([]byte{2}): true,
+		// the withdrawal 3 is still unclaimed
+		string([]byte{4}): true,
+		string([]byte{5}): true,
+		string([]byte{6}): true,
+	}}
+	policy := claimedTreesPolicy{childDB: childDB, merkle: m, host: host}
+
+	watermark, count, err := policy.Prune(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), watermark)
+	require.Equal(t, 3, count)
+
+	_, _, _, _, err = m.GetProofs(1)
+	require.ErrorIs(t, err, merkletypes.ErrPrunedTree)
+	// the tree of the unclaimed withdrawal is kept, including its claimed withdrawals
+	for _, sequence := range []uint64{3, 4, 5, 6} {
+		_, _, _, _, err = m.GetProofs(sequence)
+		require.NoError(t, err)
+	}
+
+	// nothing is pruned until the withdrawal 3 is claimed
+	host.queried = 0
+	watermark, count, err = policy.Prune(context.Background(), watermark)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), watermark)
+	require.Zero(t, count)
+	require.Equal(t, 4, host.queried)
+
+	host.claimed[string([]byte{3})] = true
+	watermark, count, err = policy.Prune(context.Background(), watermark)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), watermark)
+	require.Equal(t, 6, count)
+
+	for _, sequence := range []uint64{3, 6} {
+		_, _, _, _, err = m.GetProofs(sequence)
+		require.ErrorIs(t, err, merkletypes.ErrPrunedTree)
+	}
+}
+
+func Test_ClaimedTreesPolicyAllClaimed(t *testing.T) {
+	childDB, m := newClaimedTreesTest(t, 2)
+	host := &mockClaimedQuerier{claimed: map[string]bool{
+		string([]byte{1}): true,
+		string([]byte{2}): true,
+		string([]byte{3}): true,
+		string([]byte{4}): true,
+	}}
+	policy := claimedTreesPolicy{childDB: childDB, merkle: m, host: host}
+
+	watermark, count, err := policy.Prune(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), watermark)
+	require.Equal(t, 6, count)
+
+	for _, sequence := range []uint64{1, 4} {
+		_, _, _, _, err = m.GetProofs(sequence)
+		require.ErrorIs(t, err, merkletypes.ErrPrunedTree)
+	}
+}
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+)
+
+type withdrawalQuerier func(ctx context.Context, sequence uint64) (executortypes.QueryWithdrawalResponse, error)
+
+type withdrawalsQuerier func(ctx context.Context, address string, offset uint64, limit uint64, descOrder bool) (executortypes.QueryWithdrawalsResponse, error)
+
+// withdrawalHandler serves the claim data of the withdrawal. It responds 404 for the unknown sequence,
+// and 425 with the withdrawal info without the proofs if the tree of the withdrawal is not finalized yet.
+func withdrawalHandler(query withdrawalQuerier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sequence, err := strconv.ParseUint(c.Params("sequence"), 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid sequence: %s", c.Params("sequence")))
+		}
+
+		res, err := query(c.UserContext(), sequence)
+		if errors.Is(err, dbtypes.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("withdrawal not found: %d", sequence))
+		} else if err != nil {
+			return err
+		}
+
+		if !res.Finalized {
+			c.Status(fiber.StatusTooEarly)
+		}
+		return c.JSON(res)
+	}
+}
+
+// withdrawalsHandler serves the withdrawals of the address, which is given by the path or the query.
+func withdrawalsHandler(query withdrawalsQuerier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		address := c.Params("address", c.Query("address"))
+		if address == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "address is required")
+		}
+
+		offset, limit, descOrder, err := paginationParams(c)
+		if err != nil {
+			return err
+		}
+		res, err := query(c.UserContext(), address, offset, limit, descOrder)
+		if err != nil {
+			return err
+		}
+		return c.JSON(res)
+	}
+}
@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/executor/child"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// newQueryTestApp serves the withdrawal queries of a child db with the withdrawals 1-3 of the finalized tree
+// and the withdrawal 4 of the working tree.
+func newQueryTestApp(t *testing.T) *fiber.App {
+	ch, err := child.NewChildV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db.NewMemDB().WithPrefix([]byte(types.ChildName)), zap.NewNop())
+	require.NoError(t, err)
+	ch.SetBridgeInfo(ophosttypes.QueryBridgeResponse{BridgeId: 1})
+
+	require.NoError(t, ch.Merkle().InitializeWorkingTree(1, 1))
+	for sequence := uint64(1); sequence <= 4; sequence++ {
+		to := "receiver0"
+		if sequence%2 == 0 {
+			to = "receiver1"
+		}
+		hash := ophosttypes.GenerateWithdrawalHash(1, sequence, "sender", to, "uinit", 100)
+		kvs, err := ch.WithdrawalToRawKVs(sequence, executortypes.WithdrawalData{
+			Sequence:       sequence,
+			From:           "sender",
+			To:             to,
+			Amount:         100,
+			BaseDenom:      "uinit",
+			WithdrawalHash: hash[:],
+		})
+		require.NoError(t, err)
+		require.NoError(t, ch.DB().RawBatchSet(kvs...))
+		require.NoError(t, ch.Merkle().InsertLeaf(hash[:]))
+
+		if sequence == 3 {
+			blockHash := [32]byte{10}
+			extraData, err := json.Marshal(executortypes.TreeExtraData{BlockNumber: 10, BlockHash: blockHash[:]})
+			require.NoError(t, err)
+			kvs, _, err := ch.Merkle().FinalizeWorkingTree(extraData)
+			require.NoError(t, err)
+			require.NoError(t, ch.DB().RawBatchSet(kvs...))
+			require.NoError(t, ch.Merkle().InitializeWorkingTree(2, 4))
+		}
+	}
+
+	app := fiber.New()
+	app.Get("/withdrawal/:sequence", withdrawalHandler(func(_ context.Context, sequence uint64) (executortypes.QueryWithdrawalResponse, error) {
+		return ch.QueryWithdrawal(sequence)
+	}))
+	querier := func(_ context.Context, address string, offset uint64, limit uint64, descOrder bool) (executortypes.QueryWithdrawalsResponse, error) {
+		return ch.QueryWithdrawals(address, offset, limit, descOrder)
+	}
+	app.Get("/withdrawals", withdrawalsHandler(querier))
+	app.Get("/withdrawals/:address", withdrawalsHandler(querier))
+	return app
+}
+
+func get(t *testing.T, app *fiber.App, path string, res any) int {
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	if res != nil {
+		require.NoError(t, json.Unmarshal(body, res))
+	}
+	return resp.StatusCode
+}
+
+func Test_WithdrawalHandler(t *testing.T) {
+	app := newQueryTestApp(t)
+
+	var res executortypes.QueryWithdrawalResponse
+	require.Equal(t, http.StatusOK, get(t, app, "/withdrawal/2", &res))
+	require.True(t, res.Finalized)
+	require.Equal(t, uint64(2), res.Sequence)
+	require.Equal(t, "receiver1", res.To)
+	require.Equal(t, uint64(1), res.OutputIndex)
+	require.Len(t, res.WithdrawalProofs, 2)
+	blockHash := [32]byte{10}
+	require.Equal(t, blockHash[:], res.LastBlockHash)
+	outputRoot := ophosttypes.GenerateOutputRoot(res.Version[0], res.StorageRoot, res.LastBlockHash)
+	require.Equal(t, outputRoot[:], res.OutputRoot)
+
+	// not finalized yet
+	res = executortypes.QueryWithdrawalResponse{}
+	require.Equal(t, fiber.StatusTooEarly, get(t, app, "/withdrawal/4", &res))
+	require.False(t, res.Finalized)
+	require.Equal(t, uint64(4), res.Sequence)
+	require.Empty(t, res.WithdrawalProofs)
+
+	require.Equal(t, http.StatusNotFound, get(t, app, "/withdrawal/5", nil))
+	require.Equal(t, http.StatusBadRequest, get(t, app, "/withdrawal/abc", nil))
+}
+
+func Test_WithdrawalsHandler(t *testing.T) {
+	app := newQueryTestApp(t)
+
+	var res executortypes.QueryWithdrawalsResponse
+	require.Equal(t, http.StatusOK, get(t, app, "/withdrawals?address=receiver0&limit=1&order=asc", &res))
+	require.Len(t, res.Withdrawals, 1)
+	require.Equal(t, uint64(1), res.Withdrawals[0].Sequence)
+	require.NotNil(t, res.Next)
+
+	res = executortypes.QueryWithdrawalsResponse{}
+	require.Equal(t, http.StatusOK, get(t, app, "/withdrawals?address=receiver0&limit=1&order=asc&offset=3", &res))
+	require.Len(t, res.Withdrawals, 1)
+	require.Equal(t, uint64(3), res.Withdrawals[0].Sequence)
+	require.Nil(t, res.Next)
+
+	// the listing includes the withdrawals not finalized yet
+	res = executortypes.QueryWithdrawalsResponse{}
+	require.Equal(t, http.StatusOK, get(t, app, "/withdrawals/receiver1", &res))
+	require.Len(t, res.Withdrawals, 2)
+	require.Equal(t, uint64(4), res.Withdrawals[0].Sequence)
+	require.False(t, res.Withdrawals[0].Finalized)
+	require.True(t, res.Withdrawals[1].Finalized)
+
+	require.Equal(t, http.StatusBadRequest, get(t, app, "/withdrawals", nil))
+}
+
+func Test_WithClaimableAt(t *testing.T) {
+	proposedAt := time.Unix(100, 0)
+	outputClaimableAt := func(_ context.Context, outputIndex uint64) (time.Time, error) {
+		switch outputIndex {
+		case 1:
+			return proposedAt.Add(time.Hour), nil
+		case 2:
+			return time.Time{}, status.Error(codes.NotFound, "output proposal not found")
+		case 3:
+			return time.Time{}, dbtypes.ErrNotFound
+		case 4:
+			// the message alone doesn't tell the output is missing
+			return time.Time{}, errors.New("account not found")
+		}
+		return time.Time{}, errors.New("connection refused")
+	}
+	withdrawal := func(outputIndex uint64, claimed bool) executortypes.QueryWithdrawalResponse {
+		return executortypes.QueryWithdrawalResponse{Sequence: 1, OutputIndex: outputIndex, Finalized: true, Claimed: claimed}
+	}
+
+	// claimable after the finalization period
+	res, err := withClaimableAt(context.Background(), withdrawal(1, false), outputClaimableAt, proposedAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, proposedAt.Add(time.Hour), *res.ClaimableAt)
+	require.False(t, res.IsClaimable)
+	res, err = withClaimableAt(context.Background(), withdrawal(1, false), outputClaimableAt, proposedAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, res.IsClaimable)
+
+	// already claimed
+	res, err = withClaimableAt(context.Background(), withdrawal(1, true), outputClaimableAt, proposedAt.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, res.ClaimableAt)
+	require.False(t, res.IsClaimable)
+
+	// the output is not proposed yet, or the tree is not finalized
+	res, err = withClaimableAt(context.Background(), withdrawal(2, false), outputClaimableAt, proposedAt)
+	require.NoError(t, err)
+	require.Nil(t, res.ClaimableAt)
+	res, err = withClaimableAt(context.Background(), withdrawal(3, false), outputClaimableAt, proposedAt)
+	require.NoError(t, err)
+	require.Nil(t, res.ClaimableAt)
+	res, err = withClaimableAt(context.Background(), executortypes.QueryWithdrawalResponse{Sequence: 1}, outputClaimableAt, proposedAt)
+	require.NoError(t, err)
+	require.Nil(t, res.ClaimableAt)
+
+	_, err = withClaimableAt(context.Background(), withdrawal(4, false), outputClaimableAt, proposedAt)
+	require.Error(t, err)
+	_, err = withClaimableAt(context.Background(), withdrawal(5, false), outputClaimableAt, proposedAt)
+	require.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/node"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+const (
+	ShutdownStageStopBlockIngestion = "stop_block_ingestion"
+	ShutdownStageFlushChildState    = "flush_child_state"
+	ShutdownStageDrainBroadcasters  = "drain_broadcasters"
+	ShutdownStageCloseDB            = "close_db"
+)
+
+// shutdownStage is a stage of the graceful shutdown, which is bounded by the stage timeout.
+type shutdownStage struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// ShutdownStageResult is the result of a stage of the graceful shutdown, reported in the summary log.
+type ShutdownStageResult struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	TimedOut bool          `json:"timed_out,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// runShutdownStages runs the stages in order. The stage failed or timed out is reported, and the next
+// stage proceeds, so the later stages still flush what they can.
+func runShutdownStages(ctx context.Context, logger *zap.Logger, stageTimeout time.Duration, stages ...shutdownStage) ([]ShutdownStageResult, error) {
+	results := make([]ShutdownStageResult, 0, len(stages))
+	var errs []error
+	for _, stage := range stages {
+		stageCtx, cancel := context.WithTimeout(ctx, stageTimeout)
+		start := time.Now()
+		err := stage.run(stageCtx)
+		cancel()
+
+		result := ShutdownStageResult{Name: stage.name, Duration: time.Since(start)}
+		if err != nil {
+			result.TimedOut = errors.Is(err, context.DeadlineExceeded)
+			result.Error = err.Error()
+			errs = append(errs, fmt.Errorf("shutdown stage %s failed: %w", stage.name, err))
+			logger.Error("shutdown stage failed", zap.String("stage", stage.name), zap.Duration("duration", result.Duration), zap.String("error", err.Error()))
+		} else {
+			logger.Info("shutdown stage completed", zap.String("stage", stage.name), zap.Duration("duration", result.Duration))
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(errs...)
+}
+
+// waitStopped waits for the channel to be closed within the context.
+func waitStopped(ctx context.Context, stopped <-chan struct{}, name string) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%s is not stopped: %w", name, ctx.Err())
+	case <-stopped:
+		return nil
+	}
+}
+
+// Shutdown stops the executor in order, so the state is flushed to the db before it is closed.
+//  1. stop the block ingestion of the nodes, while the handlers of the blocks in progress complete.
+//  2. discard the state of the child block whose handlers failed, which is processed again after the restart.
+//  3. drain the msgs queued to the broadcasters to the db.
+//  4. stop the remaining components by the stop function, and wait for the db to be closed.
+//
+// Each stage is bounded by the stage timeout, and the summary of the stages is logged at the end.
+func (ex *Executor) Shutdown(ctx context.Context, stageTimeout time.Duration, stop context.CancelFunc) error {
+	stages := append(ex.flushStages(), shutdownStage{
+		name: ShutdownStageCloseDB,
+		run: func(ctx context.Context) error {
+			stop()
+			return waitStopped(ctx, ex.closed, "executor")
+		},
+	})
+	results, err := runShutdownStages(ctx, ex.logger, stageTimeout, stages...)
+	ex.logger.Info("shutdown summary", zap.Any("stages", results), zap.Bool("clean", err == nil))
+	return err
+}
+
+// flushStages returns the stages of the shutdown flushing the state of the executor before it is stopped.
+func (ex *Executor) flushStages() []shutdownStage {
+	return []shutdownStage{
+		{
+			name: ShutdownStageStopBlockIngestion,
+			run: func(ctx context.Context) error {
+				ex.shutdownOnce.Do(func() { close(ex.shutdown) })
+				nodes := map[string]*node.Node{
+					types.HostName:  ex.host.Node(),
+					types.ChildName: ex.child.Node(),
+				}
+				if ex.batch != nil {
+					nodes[types.BatchName] = ex.batch.Node()
+				}
+				for name, n := range nodes {
+					if err := waitStopped(ctx, n.BlockProcessStopped(), name+" block process looper"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name: ShutdownStageFlushChildState,
+			run: func(_ context.Context) error {
+				_, err := ex.child.DiscardIncompleteBlock()
+				return err
+			},
+		},
+		{
+			name: ShutdownStageDrainBroadcasters,
+			run: func(ctx context.Context) error {
+				for name, n := range map[string]*node.Node{
+					types.HostName:  ex.host.Node(),
+					types.ChildName: ex.child.Node(),
+				} {
+					if !n.HasBroadcaster() {
+						continue
+					}
+					persisted, discarded, err := n.DrainBroadcaster(ctx)
+					if err != nil {
+						return fmt.Errorf("failed to drain the %s broadcaster: %w", name, err)
+					}
+					ex.logger.Info("drain broadcaster", zap.String("node", name), zap.Int("persisted", persisted), zap.Int("discarded", discarded))
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_RunShutdownStages(t *testing.T) {
+	var order []string
+	stage := func(name string, run func(ctx context.Context) error) shutdownStage {
+		return shutdownStage{name: name, run: func(ctx context.Context) error {
+			order = append(order, name)
+			return run(ctx)
+		}}
+	}
+
+	results, err := runShutdownStages(context.Background(), zap.NewNop(), 10*time.Millisecond,
+		stage(ShutdownStageStopBlockIngestion, func(context.Context) error { return nil }),
+		// the stage not completed in the timeout doesn't block the later stages
+		stage(ShutdownStageFlushChildState, func(ctx context.Context) error {
+			return waitStopped(ctx, make(chan struct{}), "child")
+		}),
+		stage(ShutdownStageDrainBroadcasters, func(context.Context) error { return errors.New("db is closed") }),
+		stage(ShutdownStageCloseDB, func(context.Context) error { return nil }),
+	)
+	require.Equal(t, []string{ShutdownStageStopBlockIngestion, ShutdownStageFlushChildState, ShutdownStageDrainBroadcasters, ShutdownStageCloseDB}, order)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "shutdown stage drain_broadcasters failed: db is closed")
+
+	require.Len(t, results, 4)
+	require.Empty(t, results[0].Error)
+	require.True(t, results[1].TimedOut)
+	require.GreaterOrEqual(t, results[1].Duration, 10*time.Millisecond)
+	require.False(t, results[2].TimedOut)
+	require.Equal(t, "db is closed", results[2].Error)
+	require.Empty(t, results[3].Error)
+}
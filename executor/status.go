@@ -1,24 +1,30 @@
 package executor
 
 import (
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
 	"github.com/initia-labs/opinit-bots/executor/batch"
 	"github.com/initia-labs/opinit-bots/executor/child"
 	"github.com/initia-labs/opinit-bots/executor/host"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 type Status struct {
-	BridgeId uint64           `json:"bridge_id"`
-	Host     host.Status      `json:"host,omitempty"`
-	Child    child.Status     `json:"child,omitempty"`
-	Batch    batch.Status     `json:"batch,omitempty"`
-	DA       nodetypes.Status `json:"da,omitempty"`
+	BridgeId uint64 `json:"bridge_id"`
+	// DryRun is true if the msgs are recorded instead of being broadcasted.
+	DryRun bool `json:"dry_run"`
+	// DisabledComponents are the components disabled by the config, e.g. "batch_submitter".
+	DisabledComponents []string         `json:"disabled_components"`
+	Host               host.Status      `json:"host,omitempty"`
+	Child              child.Status     `json:"child,omitempty"`
+	Batch              batch.Status     `json:"batch,omitempty"`
+	DA                 nodetypes.Status `json:"da,omitempty"`
 }
 
 func (ex Executor) GetStatus() (Status, error) {
 	var err error
 
-	s := Status{}
+	s := Status{DryRun: ex.cfg.DryRun, DisabledComponents: ex.cfg.DisabledComponents()}
 	if ex.host != nil {
 		s.BridgeId = ex.host.BridgeId()
 		s.Host, err = ex.host.GetStatus()
@@ -46,3 +52,17 @@ func (ex Executor) GetStatus() (Status, error) {
 	}
 	return s, nil
 }
+
+// GetDBStats returns the approximate usage of the db by the components, where the child includes its merkle.
+func (ex Executor) GetDBStats() ([]types.PrefixStats, error) {
+	return ex.db.Stats(
+		dbtypes.PrefixOf(types.HostName),
+		dbtypes.PrefixOf(types.ChildName),
+		dbtypes.PrefixOf(types.ChildName, types.MerkleName),
+		dbtypes.PrefixOf(types.BatchName),
+		dbtypes.PrefixOf(types.DAHostName),
+		dbtypes.PrefixOf(types.DACelestiaName),
+		dbtypes.PrefixOf(types.SecondaryDAHostName),
+		dbtypes.PrefixOf(types.SecondaryDACelestiaName),
+	)
+}
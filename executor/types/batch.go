@@ -1,15 +1,21 @@
 package types
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	"github.com/initia-labs/opinit-bots/types"
+	batchtypes "github.com/initia-labs/opinit-bots/types/batch"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -18,9 +24,18 @@ type DANode interface {
 	Start(context.Context)
 	HasKey() bool
 	CreateBatchMsg([]byte) (sdk.Msg, string, error)
-	BroadcastMsgs(btypes.ProcessedMsgs)
+	BroadcastMsgs(btypes.ProcessedMsgs) error
 	ProcessedMsgsToRawKV(processedMsgs []btypes.ProcessedMsgs, delete bool) ([]types.RawKV, error)
 	GetNodeStatus() (nodetypes.Status, error)
+	UnconfirmedTraceIDs() (map[string]struct{}, error)
+	DeadLetterTraceIDs() (map[string]struct{}, error)
+	RegisterTxConfirmedHandler(nodetypes.TxConfirmedHandlerFn)
+}
+
+// BatchMsgSimulator is implemented by the DA nodes which can simulate the batch msgs before the submission.
+type BatchMsgSimulator interface {
+	// SimulateBatchMsg returns the adjusted gas of the batch msg signed by the sender.
+	SimulateBatchMsg(ctx context.Context, msg sdk.Msg, sender string) (uint64, error)
 }
 
 type LocalBatchInfo struct {
@@ -31,6 +46,70 @@ type LocalBatchInfo struct {
 
 	LastSubmissionTime time.Time `json:"last_submission_time"`
 	BatchFileSize      int64     `json:"batch_size"`
+
+	// Compression is the compression algorithm of the batch file, which is changed only at the batch boundary.
+	// Empty means gzip, which is the only algorithm before the compression is configurable.
+	Compression string `json:"compression,omitempty"`
+	// Version is the payload format of the batch file, which is changed only at the batch boundary.
+	Version BatchVersion `json:"version,omitempty"`
+
+	// Checksum is the sha256 checksum of the compressed batch file, which is validated
+	// against the finalized file before the submission.
+	Checksum []byte `json:"checksum,omitempty"`
+
+	// BlockOffsets are the raw offsets of the batch payload at the end of each block from the start height.
+	// They are kept while the batch in progress is rebuilt, so the rebuilt blocks are verified against them.
+	BlockOffsets []int64 `json:"block_offsets,omitempty"`
+}
+
+// BatchChunkState is the submission state of a finalized batch. The batch is submitted
+// when the header and all the chunks are confirmed on the DA.
+type BatchChunkState struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+	// TraceIDs are the trace ids of the header and the chunk msgs in order, the header first.
+	TraceIDs  []string `json:"trace_ids"`
+	Confirmed []bool   `json:"confirmed"`
+	// TxHashes are the hashes of the DA txs which include the header and the chunks, empty if unknown.
+	TxHashes []string `json:"tx_hashes,omitempty"`
+	// GasUsed is the gas used by the confirmed DA txs of the batch.
+	GasUsed uint64 `json:"gas_used,omitempty"`
+	// Size is the compressed size of the batch. It is 0 for the states saved before the size is recorded.
+	Size int64 `json:"size,omitempty"`
+	// ChunkSizes are the sizes of the header and the chunks in the order of the trace ids,
+	// empty for the states saved before the sizes are recorded.
+	ChunkSizes []int64 `json:"chunk_sizes,omitempty"`
+}
+
+// PendingSize returns the size of the batch excluding the unconfirmed chunks parked in the dead letters,
+// which are not submitted until they are retried.
+func (s BatchChunkState) PendingSize(parkedTraceIDs map[string]struct{}) int64 {
+	if len(s.ChunkSizes) != len(s.TraceIDs) {
+		return s.Size
+	}
+	size := s.Size
+	for i, traceID := range s.TraceIDs {
+		if _, ok := parkedTraceIDs[traceID]; ok && !s.Confirmed[i] {
+			size -= s.ChunkSizes[i]
+		}
+	}
+	return max(size, 0)
+}
+
+// ConfirmedChunks returns the number of the confirmed header and chunks.
+func (s BatchChunkState) ConfirmedChunks() int {
+	count := 0
+	for _, confirmed := range s.Confirmed {
+		if confirmed {
+			count++
+		}
+	}
+	return count
+}
+
+// IsSubmitted returns true if the header and all the chunks are confirmed.
+func (s BatchChunkState) IsSubmitted() bool {
+	return s.ConfirmedChunks() == len(s.TraceIDs)
 }
 
 type BatchDataType uint8
@@ -38,12 +117,106 @@ type BatchDataType uint8
 const (
 	BatchDataTypeHeader BatchDataType = iota
 	BatchDataTypeChunk
+	// BatchDataTypeCompressedHeader is the header of the batch compressed with the algorithm other than gzip,
+	// which has the compression byte after the type byte. The gzip batches keep the legacy header.
+	BatchDataTypeCompressedHeader
+	// BatchDataTypeVersionedHeader is the header of the batch whose payload format is other than the blocks only,
+	// which has the version and the compression bytes after the type byte.
+	BatchDataTypeVersionedHeader
+	// BatchDataTypeCommittedHeader is the header which commits to the content of the batch, the raw and the
+	// compressed lengths and the checksum of the compressed payload, after the version and the compression bytes.
+	BatchDataTypeCommittedHeader
+)
+
+// BatchVersion is the format of the decompressed batch payload, which is recorded in the batch header.
+type BatchVersion uint8
+
+const (
+	// BatchVersionBlocks is the payload of the length prefixed blocks followed by the raw commit of the last block.
+	BatchVersionBlocks BatchVersion = iota
+	// BatchVersionBlockEvents is the payload which has the length prefixed events of each block after its bytes.
+	BatchVersionBlockEvents
+)
+
+// BatchCompression is the compression algorithm of the batch data, which is recorded in the batch header.
+type BatchCompression uint8
+
+const (
+	BatchCompressionGzip BatchCompression = iota
+	BatchCompressionZstd
 )
 
+var batchCompressionNames = map[BatchCompression]string{
+	BatchCompressionGzip: "gzip",
+	BatchCompressionZstd: "zstd",
+}
+
+func (c BatchCompression) String() string {
+	if name, ok := batchCompressionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(c))
+}
+
+// BatchCompressionFromString returns the compression algorithm of the name. Empty name means gzip.
+func BatchCompressionFromString(name string) (BatchCompression, error) {
+	if name == "" {
+		return BatchCompressionGzip, nil
+	}
+	for compression, compressionName := range batchCompressionNames {
+		if compressionName == name {
+			return compression, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown batch compression: %s", name)
+}
+
+const (
+	// CelestiaNamespaceChainID derives the celestia namespace id from the l2 chain id.
+	CelestiaNamespaceChainID = "chain_id"
+	// CelestiaNamespaceBridgeID derives the celestia namespace id from the bridge id.
+	CelestiaNamespaceBridgeID = "bridge_id"
+
+	// CelestiaNamespaceIDSize is the size of the version 0 namespace id of celestia.
+	CelestiaNamespaceIDSize = 10
+)
+
+// ValidateCelestiaNamespace validates the celestia namespace config, which is empty,
+// one of the derivation sources or the hex encoded namespace id.
+func ValidateCelestiaNamespace(namespace string) error {
+	switch namespace {
+	case "", CelestiaNamespaceChainID, CelestiaNamespaceBridgeID:
+		return nil
+	}
+
+	id, err := hex.DecodeString(namespace)
+	if err != nil {
+		return fmt.Errorf("invalid celestia namespace: %s", namespace)
+	} else if len(id) != CelestiaNamespaceIDSize {
+		return fmt.Errorf("invalid celestia namespace id length: %d, expected: %d", len(id), CelestiaNamespaceIDSize)
+	}
+	return nil
+}
+
 type BatchDataHeader struct {
-	Start     uint64
-	End       uint64
-	Checksums [][]byte
+	Start       uint64
+	End         uint64
+	Compression BatchCompression
+	Version     BatchVersion
+	Checksums   [][]byte
+
+	// RawLength is the length of the batch payload before the compression.
+	RawLength uint64
+	// CompressedLength is the length of the compressed payload, the sum of the chunk lengths.
+	CompressedLength uint64
+	// PayloadChecksum is the sha256 checksum of the compressed payload.
+	// The commitments are empty for the headers before the committed header.
+	PayloadChecksum []byte
+}
+
+// Committed returns true if the header commits to the lengths and the checksum of the payload.
+func (h BatchDataHeader) Committed() bool {
+	return len(h.PayloadChecksum) != 0
 }
 
 type BatchDataChunk struct {
@@ -61,10 +234,19 @@ func GetChecksumFromChunk(chunk []byte) [32]byte {
 func MarshalBatchDataHeader(
 	start uint64,
 	end uint64,
+	compression BatchCompression,
+	version BatchVersion,
 	checksums [][]byte,
 ) []byte {
 	data := make([]byte, 1)
 	data[0] = byte(BatchDataTypeHeader)
+	if version != BatchVersionBlocks {
+		data[0] = byte(BatchDataTypeVersionedHeader)
+		data = append(data, byte(version), byte(compression))
+	} else if compression != BatchCompressionGzip {
+		data[0] = byte(BatchDataTypeCompressedHeader)
+		data = append(data, byte(compression))
+	}
 	data = binary.BigEndian.AppendUint64(data, start)
 	data = binary.BigEndian.AppendUint64(data, end)
 	data = binary.BigEndian.AppendUint64(data, uint64(len(checksums)))
@@ -74,7 +256,95 @@ func MarshalBatchDataHeader(
 	return data
 }
 
+// MarshalCommittedBatchDataHeader marshals the header with the commitments of the payload
+// to the committed header, the type byte followed by the proto encoded CommittedBatchDataHeader.
+func MarshalCommittedBatchDataHeader(header BatchDataHeader) ([]byte, error) {
+	body, err := (&batchtypes.CommittedBatchDataHeader{
+		Start:            header.Start,
+		End:              header.End,
+		Version:          uint32(header.Version),
+		Compression:      uint32(header.Compression),
+		RawLength:        header.RawLength,
+		CompressedLength: header.CompressedLength,
+		PayloadChecksum:  header.PayloadChecksum,
+		Checksums:        header.Checksums,
+	}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal committed batch header: %w", err)
+	}
+	return append([]byte{byte(BatchDataTypeCommittedHeader)}, body...), nil
+}
+
+// unmarshalCommittedBatchDataHeader unmarshals the committed header.
+func unmarshalCommittedBatchDataHeader(data []byte) (BatchDataHeader, error) {
+	var committed batchtypes.CommittedBatchDataHeader
+	if err := committed.Unmarshal(data[1:]); err != nil {
+		return BatchDataHeader{}, fmt.Errorf("failed to unmarshal committed batch header: %w", err)
+	}
+
+	if committed.Version > uint32(BatchVersionBlockEvents) {
+		return BatchDataHeader{}, fmt.Errorf("invalid batch version: %d", committed.Version)
+	}
+	compression := BatchCompression(committed.Compression)
+	if _, ok := batchCompressionNames[compression]; !ok || committed.Compression > 0xff {
+		return BatchDataHeader{}, fmt.Errorf("unknown batch compression: %d", committed.Compression)
+	}
+	if committed.Start > committed.End {
+		return BatchDataHeader{}, fmt.Errorf("invalid start: %d, end: %d", committed.Start, committed.End)
+	}
+	if committed.CompressedLength == 0 || committed.RawLength == 0 {
+		return BatchDataHeader{}, fmt.Errorf("invalid raw length: %d, compressed length: %d", committed.RawLength, committed.CompressedLength)
+	}
+	if len(committed.PayloadChecksum) != sha256.Size {
+		return BatchDataHeader{}, fmt.Errorf("invalid payload checksum length: %d", len(committed.PayloadChecksum))
+	}
+	if len(committed.Checksums) == 0 {
+		return BatchDataHeader{}, errors.New("empty chunk checksums")
+	}
+	for i, checksum := range committed.Checksums {
+		if len(checksum) != sha256.Size {
+			return BatchDataHeader{}, fmt.Errorf("invalid checksum length: %d, index: %d", len(checksum), i)
+		}
+	}
+
+	return BatchDataHeader{
+		Start:            committed.Start,
+		End:              committed.End,
+		Compression:      compression,
+		Version:          BatchVersion(committed.Version),
+		Checksums:        committed.Checksums,
+		RawLength:        committed.RawLength,
+		CompressedLength: committed.CompressedLength,
+		PayloadChecksum:  committed.PayloadChecksum,
+	}, nil
+}
+
+// UnmarshalBatchDataHeader unmarshals the header of any type, so the batches submitted
+// before the committed header still parse.
 func UnmarshalBatchDataHeader(data []byte) (BatchDataHeader, error) {
+	if len(data) > 0 && BatchDataType(data[0]) == BatchDataTypeCommittedHeader {
+		return unmarshalCommittedBatchDataHeader(data)
+	}
+
+	compression := BatchCompressionGzip
+	version := BatchVersionBlocks
+	if len(data) > 2 && BatchDataType(data[0]) == BatchDataTypeVersionedHeader {
+		version = BatchVersion(data[1])
+		if version == BatchVersionBlocks || version > BatchVersionBlockEvents {
+			return BatchDataHeader{}, fmt.Errorf("invalid batch version: %d", data[1])
+		}
+		// skip the version byte to parse the rest as the compressed header
+		data = data[1:]
+	}
+	if len(data) > 1 && (BatchDataType(data[0]) == BatchDataTypeCompressedHeader || version != BatchVersionBlocks) {
+		compression = BatchCompression(data[1])
+		if _, ok := batchCompressionNames[compression]; !ok {
+			return BatchDataHeader{}, fmt.Errorf("unknown batch compression: %d", data[1])
+		}
+		// skip the compression byte to parse the rest as the legacy header
+		data = data[1:]
+	}
+
 	if len(data) < 25 {
 		err := fmt.Errorf("invalid data length: %d, expected > 25", len(data))
 		return BatchDataHeader{}, err
@@ -102,9 +372,11 @@ func UnmarshalBatchDataHeader(data []byte) (BatchDataHeader, error) {
 	}
 
 	return BatchDataHeader{
-		Start:     start,
-		End:       end,
-		Checksums: checksums,
+		Start:       start,
+		End:         end,
+		Compression: compression,
+		Version:     version,
+		Checksums:   checksums,
 	}, nil
 }
 
@@ -147,3 +419,44 @@ func UnmarshalBatchDataChunk(data []byte) (BatchDataChunk, error) {
 		ChunkData: chunkData,
 	}, nil
 }
+
+// ReassembleBatchChunks verifies the chunks against the header and concatenates them
+// in the order of the chunk index into the compressed batch data.
+func ReassembleBatchChunks(header BatchDataHeader, chunks []BatchDataChunk) ([]byte, error) {
+	if len(chunks) != len(header.Checksums) {
+		return nil, fmt.Errorf("invalid number of chunks: %d, expected: %d", len(chunks), len(header.Checksums))
+	}
+
+	chunks = slices.Clone(chunks)
+	slices.SortFunc(chunks, func(a, b BatchDataChunk) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+
+	data := make([]byte, 0)
+	for i, chunk := range chunks {
+		if chunk.Start != header.Start || chunk.End != header.End {
+			return nil, fmt.Errorf("chunk of another batch: start: %d, end: %d, expected start: %d, end: %d", chunk.Start, chunk.End, header.Start, header.End)
+		} else if chunk.Index != uint64(i) {
+			return nil, fmt.Errorf("missing chunk: %d", i)
+		} else if chunk.Length != uint64(len(header.Checksums)) {
+			return nil, fmt.Errorf("invalid chunk length: %d, expected: %d", chunk.Length, len(header.Checksums))
+		}
+
+		checksum := GetChecksumFromChunk(chunk.ChunkData)
+		if !bytes.Equal(checksum[:], header.Checksums[i]) {
+			return nil, fmt.Errorf("checksum mismatch of chunk: %d", i)
+		}
+		data = append(data, chunk.ChunkData...)
+	}
+
+	if header.Committed() {
+		if uint64(len(data)) != header.CompressedLength {
+			return nil, fmt.Errorf("compressed length mismatch: %d, expected: %d", len(data), header.CompressedLength)
+		}
+		checksum := sha256.Sum256(data)
+		if !bytes.Equal(checksum[:], header.PayloadChecksum) {
+			return nil, errors.New("payload checksum mismatch")
+		}
+	}
+	return data, nil
+}
@@ -1,9 +1,13 @@
 package types
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	batchtypes "github.com/initia-labs/opinit-bots/types/batch"
 )
 
 func TestBatchDataHeader(t *testing.T) {
@@ -25,6 +29,8 @@ func TestBatchDataHeader(t *testing.T) {
 	headerData := MarshalBatchDataHeader(
 		start,
 		end,
+		BatchCompressionGzip,
+		BatchVersionBlocks,
 		checksums)
 	require.Equal(t, 1+8+8+8+3*32, len(headerData))
 
@@ -35,4 +41,126 @@ func TestBatchDataHeader(t *testing.T) {
 	require.Equal(t, end, header.End)
 	require.Equal(t, checksums, header.Checksums)
 	require.Equal(t, len(chunks), len(header.Checksums))
+	require.Equal(t, BatchCompressionGzip, header.Compression)
+
+	// the compression other than gzip is recorded after the type byte
+	headerData = MarshalBatchDataHeader(start, end, BatchCompressionZstd, BatchVersionBlocks, checksums)
+	require.Equal(t, 1+1+8+8+8+3*32, len(headerData))
+	require.Equal(t, byte(BatchDataTypeCompressedHeader), headerData[0])
+
+	header, err = UnmarshalBatchDataHeader(headerData)
+	require.NoError(t, err)
+	require.Equal(t, start, header.Start)
+	require.Equal(t, end, header.End)
+	require.Equal(t, checksums, header.Checksums)
+	require.Equal(t, BatchCompressionZstd, header.Compression)
+	require.Equal(t, BatchVersionBlocks, header.Version)
+
+	headerData[1] = 100
+	_, err = UnmarshalBatchDataHeader(headerData)
+	require.Error(t, err)
+
+	// the version other than the blocks only is recorded with the compression after the type byte
+	for _, compression := range []BatchCompression{BatchCompressionGzip, BatchCompressionZstd} {
+		headerData = MarshalBatchDataHeader(start, end, compression, BatchVersionBlockEvents, checksums)
+		require.Equal(t, 1+1+1+8+8+8+3*32, len(headerData))
+		require.Equal(t, byte(BatchDataTypeVersionedHeader), headerData[0])
+
+		header, err = UnmarshalBatchDataHeader(headerData)
+		require.NoError(t, err)
+		require.Equal(t, start, header.Start)
+		require.Equal(t, end, header.End)
+		require.Equal(t, checksums, header.Checksums)
+		require.Equal(t, compression, header.Compression)
+		require.Equal(t, BatchVersionBlockEvents, header.Version)
+	}
+
+	for _, version := range []byte{byte(BatchVersionBlocks), 100} {
+		headerData[1] = version
+		_, err = UnmarshalBatchDataHeader(headerData)
+		require.Error(t, err)
+	}
+}
+
+func TestCommittedBatchDataHeader(t *testing.T) {
+	payload := []byte("chunk1chunk2chunk3")
+	chunks := []BatchDataChunk{
+		{Start: 1, End: 100, Index: 0, Length: 3, ChunkData: payload[:6]},
+		{Start: 1, End: 100, Index: 1, Length: 3, ChunkData: payload[6:12]},
+		{Start: 1, End: 100, Index: 2, Length: 3, ChunkData: payload[12:]},
+	}
+	checksums := make([][]byte, 0, len(chunks))
+	for _, chunk := range chunks {
+		checksum := GetChecksumFromChunk(chunk.ChunkData)
+		checksums = append(checksums, checksum[:])
+	}
+	payloadChecksum := sha256.Sum256(payload)
+	expected := BatchDataHeader{
+		Start:            1,
+		End:              100,
+		Compression:      BatchCompressionZstd,
+		Version:          BatchVersionBlockEvents,
+		Checksums:        checksums,
+		RawLength:        1000,
+		CompressedLength: uint64(len(payload)),
+		PayloadChecksum:  payloadChecksum[:],
+	}
+
+	headerData, err := MarshalCommittedBatchDataHeader(expected)
+	require.NoError(t, err)
+	require.Equal(t, byte(BatchDataTypeCommittedHeader), headerData[0])
+
+	header, err := UnmarshalBatchDataHeader(headerData)
+	require.NoError(t, err)
+	require.Equal(t, expected, header)
+	require.True(t, header.Committed())
+
+	data, err := ReassembleBatchChunks(header, chunks)
+	require.NoError(t, err)
+	require.Equal(t, payload, data)
+
+	// the legacy headers have no commitments
+	legacy, err := UnmarshalBatchDataHeader(MarshalBatchDataHeader(1, 100, BatchCompressionGzip, BatchVersionBlocks, checksums))
+	require.NoError(t, err)
+	require.False(t, legacy.Committed())
+
+	// the payload substituted with the chunks of the same checksums in another header fails the verification
+	tampered := header
+	tampered.PayloadChecksum = bytes.Repeat([]byte{1}, 32)
+	_, err = ReassembleBatchChunks(tampered, chunks)
+	require.ErrorContains(t, err, "payload checksum mismatch")
+	tampered = header
+	tampered.CompressedLength++
+	_, err = ReassembleBatchChunks(tampered, chunks)
+	require.ErrorContains(t, err, "compressed length mismatch")
+
+	// the invalid fields fail the parsing
+	for i, modify := range []func(*batchtypes.CommittedBatchDataHeader){
+		func(h *batchtypes.CommittedBatchDataHeader) { h.Version = 100 },
+		func(h *batchtypes.CommittedBatchDataHeader) { h.Compression = 100 },
+		func(h *batchtypes.CommittedBatchDataHeader) { h.Start = 200 },
+		func(h *batchtypes.CommittedBatchDataHeader) { h.RawLength = 0 },
+		func(h *batchtypes.CommittedBatchDataHeader) { h.PayloadChecksum = h.PayloadChecksum[:31] },
+		func(h *batchtypes.CommittedBatchDataHeader) { h.Checksums = nil },
+		func(h *batchtypes.CommittedBatchDataHeader) { h.Checksums[1] = h.Checksums[1][:31] },
+	} {
+		var committed batchtypes.CommittedBatchDataHeader
+		require.NoError(t, committed.Unmarshal(headerData[1:]))
+		modify(&committed)
+		data, err := committed.Marshal()
+		require.NoError(t, err)
+		_, err = UnmarshalBatchDataHeader(append([]byte{byte(BatchDataTypeCommittedHeader)}, data...))
+		require.Error(t, err, "case %d", i)
+	}
+	_, err = UnmarshalBatchDataHeader(headerData[:len(headerData)-1])
+	require.Error(t, err)
+}
+
+func TestValidateCelestiaNamespace(t *testing.T) {
+	require.NoError(t, ValidateCelestiaNamespace(""))
+	require.NoError(t, ValidateCelestiaNamespace(CelestiaNamespaceChainID))
+	require.NoError(t, ValidateCelestiaNamespace(CelestiaNamespaceBridgeID))
+	require.NoError(t, ValidateCelestiaNamespace("0102030405060708090a"))
+	require.Error(t, ValidateCelestiaNamespace("0102"))
+	require.Error(t, ValidateCelestiaNamespace("bridge"))
 }
@@ -2,49 +2,236 @@ package types
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+
+	"github.com/initia-labs/opinit-bots/config"
+	"github.com/initia-labs/opinit-bots/keys"
+	"github.com/initia-labs/opinit-bots/logging"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/metrics"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 
 	servertypes "github.com/initia-labs/opinit-bots/server/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 type NodeConfig struct {
-	ChainID       string  `json:"chain_id"`
-	Bech32Prefix  string  `json:"bech32_prefix"`
-	RPCAddress    string  `json:"rpc_address"`
+	ChainID      string `json:"chain_id"`
+	Bech32Prefix string `json:"bech32_prefix"`
+	RPCAddress   string `json:"rpc_address"`
+	// GRPCAddress is the grpc address of the node for the queries. If it is empty, the queries use the rpc.
+	GRPCAddress string `json:"grpc_address"`
+	// GRPCTLS enables the TLS for the grpc connection.
+	GRPCTLS       bool    `json:"grpc_tls"`
 	GasPrice      string  `json:"gas_price"`
 	GasAdjustment float64 `json:"gas_adjustment"`
 	TxTimeout     int64   `json:"tx_timeout"` // seconds
+	// TxTimeoutHeight is the number of blocks after which a broadcasted tx expires.
+	// If it is zero, the txs have no timeout height.
+	TxTimeoutHeight int64 `json:"tx_timeout_height"`
+	// GasPriceEscalation is the multiplier of the gas price for each resubmission of the expired tx.
+	// If it is zero or one, the gas price is not escalated.
+	GasPriceEscalation float64 `json:"gas_price_escalation"`
+	// MaxGasPriceMultiplier caps the escalated gas price multiplier. If it is zero, there is no cap.
+	MaxGasPriceMultiplier float64 `json:"max_gas_price_multiplier"`
+	// GasPriceSource is the source of the gas price, "static" or "feemarket". With "feemarket", the gas price
+	// follows the base fee of the feemarket module, and gas_price is the fallback and decides the fee denom.
+	GasPriceSource string `json:"gas_price_source"`
+	// FeemarketTipMultiplier is the multiplier of the base fee paid as the gas price.
+	FeemarketTipMultiplier float64 `json:"feemarket_tip_multiplier"`
+	// GasPriceRefreshInterval is the minimum interval between the queries of the base fee.
+	GasPriceRefreshInterval int64 `json:"gas_price_refresh_interval"` // seconds
+	// GasPriceHysteresis is the fraction by which the new gas price must deviate to replace the current gas price.
+	// If it is null, the default value is used, and zero disables the hysteresis.
+	GasPriceHysteresis *float64 `json:"gas_price_hysteresis"`
+	// IsolateFailingMsgs bisects the msgs of a tx failing the simulation to park the failing msgs
+	// to the dead letters, and broadcasts the rest of the msgs.
+	IsolateFailingMsgs bool `json:"isolate_failing_msgs"`
+	// SequenceReconcileInterval is the number of the txs after which the cached account sequence is reconciled
+	// with the chain. If it is zero, the default value is used.
+	SequenceReconcileInterval uint64 `json:"sequence_reconcile_interval"`
+
+	// LowBalanceGas is the estimated gas of a tx to compute the low balance threshold.
+	// If it is zero, only the zero balance is reported as low balance.
+	LowBalanceGas uint64 `json:"low_balance_gas"`
+	// BalanceCheckInterval is the interval to re-check the balance of the broadcaster accounts.
+	BalanceCheckInterval int64 `json:"balance_check_interval"` // seconds
+
+	// FeeGranter is the bech32 address of the fee granter, which pays the fees of the txs.
+	FeeGranter string `json:"fee_granter"`
+	// Memo is the memo attached to the txs.
+	Memo string `json:"memo"`
+
+	// SkipHeights are the heights to skip without running the handlers.
+	// It can be used to get out of a crash loop on a block which cannot be handled.
+	// The skipped l2 blocks carry the working tree forward, and they are not supported with the batch submitter.
+	SkipHeights []int64 `json:"skip_heights"`
+
+	// PollingInterval is the interval to poll new blocks while the node is behind the chain.
+	// If it is zero, the polling interval flag is used.
+	PollingInterval int64 `json:"polling_interval"` // milliseconds
+	// MaxPollingInterval is the max interval to poll new blocks while the chain has no new block.
+	// If it is zero, the default max polling interval is used.
+	MaxPollingInterval int64 `json:"max_polling_interval"` // milliseconds
+	// MaxCatchUpWait is the maximum time to wait for the node to catch up the chain at the start.
+	// The txs are not broadcasted and the blocks are not processed until the node catches up.
+	// If it is zero, the node is waited without limit.
+	MaxCatchUpWait int64 `json:"max_catch_up_wait"` // seconds
+	// RPCTimeout is the timeout of each rpc call and query to the node.
+	// If it is zero, the default timeout 10s is used.
+	RPCTimeout int64 `json:"rpc_timeout"` // seconds
+
+	// MaxMsgsPerTx is the maximum number of msgs in a tx submitted to the chain.
+	// If it is zero, the default value 5 is used.
+	MaxMsgsPerTx int `json:"max_msgs_per_tx"`
+	// MaxTxBytes is the maximum estimated bytes of a tx submitted to the chain.
+	// If it is zero, the bytes are not limited.
+	MaxTxBytes int64 `json:"max_tx_bytes"`
+	// MaxTxGas is the maximum estimated gas of a tx submitted to the chain.
+	// If it is zero, the gas is not limited.
+	MaxTxGas uint64 `json:"max_tx_gas"`
+
+	// CodecModules are the app modules whose interfaces are registered to the codec of the node
+	// in addition to the default ones, so that their msgs can be decoded. Supported modules are
+	// "bank", "ibc", "wasm" and "move". The "move" set has the msgs of the users only.
+	CodecModules []string `json:"codec_modules"`
+	// LenientTxDecoding decodes the txs with the msgs of the unknown type urls with a warning,
+	// instead of failing to decode them.
+	LenientTxDecoding bool `json:"lenient_tx_decoding"`
 }
 
 func (nc NodeConfig) Validate() error {
-	if nc.ChainID == "" {
-		return errors.New("chain ID is required")
+	var problems config.Problems
+	problems.Add("chain_id", config.ValidateChainID(nc.ChainID))
+	problems.Add("bech32_prefix", config.ValidateBech32Prefix(nc.Bech32Prefix))
+	problems.Add("rpc_address", config.ValidateURL(nc.RPCAddress, config.RPCSchemes...))
+	if nc.GRPCAddress != "" {
+		problems.Add("grpc_address", config.ValidateGRPCAddress(nc.GRPCAddress))
+	} else if nc.GRPCTLS {
+		problems.Addf("grpc_tls", "grpc address is required to enable the tls")
 	}
-	if nc.Bech32Prefix == "" {
-		return errors.New("bech32 prefix is required")
+	if nc.GasPrice != "" {
+		problems.Add("gas_price", config.ValidateGasPrice(nc.GasPrice))
 	}
-	if nc.RPCAddress == "" {
-		return errors.New("RPC address is required")
+	if nc.GasAdjustment < 0 {
+		problems.Addf("gas_adjustment", "must be greater than or equal to 0")
 	}
-	return nil
+	if nc.TxTimeout < 0 {
+		problems.Addf("tx_timeout", "must be greater than or equal to 0")
+	}
+	if nc.TxTimeoutHeight < 0 {
+		problems.Addf("tx_timeout_height", "must be greater than or equal to 0")
+	}
+	if nc.BalanceCheckInterval < 0 {
+		problems.Addf("balance_check_interval", "must be greater than or equal to 0")
+	}
+	if nc.GasPriceEscalation < 0 {
+		problems.Addf("gas_price_escalation", "must be greater than or equal to 0")
+	}
+	if nc.MaxGasPriceMultiplier < 0 {
+		problems.Addf("max_gas_price_multiplier", "must be greater than or equal to 0")
+	}
+	switch nc.GasPriceSource {
+	case "", btypes.GasPriceSourceStatic, btypes.GasPriceSourceFeemarket:
+	default:
+		problems.Addf("gas_price_source", "must be one of %q or %q", btypes.GasPriceSourceStatic, btypes.GasPriceSourceFeemarket)
+	}
+	if nc.FeemarketTipMultiplier != 0 && nc.FeemarketTipMultiplier < 1 {
+		problems.Addf("feemarket_tip_multiplier", "must be greater than or equal to 1")
+	}
+	if nc.GasPriceRefreshInterval < 0 {
+		problems.Addf("gas_price_refresh_interval", "must be greater than or equal to 0")
+	}
+	if nc.GasPriceHysteresis != nil && (*nc.GasPriceHysteresis < 0 || *nc.GasPriceHysteresis >= 1) {
+		problems.Addf("gas_price_hysteresis", "must be greater than or equal to 0 and less than 1")
+	}
+	if nc.FeeGranter != "" {
+		if _, err := sdk.GetFromBech32(nc.FeeGranter, nc.Bech32Prefix); err != nil {
+			problems.Addf("fee_granter", "must be a valid bech32 address")
+		}
+	}
+	for _, height := range nc.SkipHeights {
+		if height <= 0 {
+			problems.Addf("skip_heights", "skip height must be greater than 0")
+		}
+	}
+	if nc.PollingInterval < 0 {
+		problems.Addf("polling_interval", "must be greater than or equal to 0")
+	}
+	if nc.MaxPollingInterval < 0 {
+		problems.Addf("max_polling_interval", "must be greater than or equal to 0")
+	} else if nc.MaxPollingInterval != 0 && nc.PollingInterval > nc.MaxPollingInterval {
+		problems.Addf("max_polling_interval", "must be greater than or equal to the polling interval")
+	}
+	if nc.MaxCatchUpWait < 0 {
+		problems.Addf("max_catch_up_wait", "must be greater than or equal to 0")
+	}
+	problems.Add("codec_modules", keys.ValidateCodecModules(nc.CodecModules))
+	if nc.RPCTimeout < 0 {
+		problems.Addf("rpc_timeout", "must be greater than or equal to 0")
+	}
+	if nc.MaxMsgsPerTx < 0 {
+		problems.Addf("max_msgs_per_tx", "must be greater than or equal to 0")
+	}
+	if nc.MaxTxBytes < 0 {
+		problems.Addf("max_tx_bytes", "must be greater than or equal to 0")
+	}
+	return problems.Err()
+}
+
+func (nc NodeConfig) MsgQueueLimits() btypes.MsgQueueLimits {
+	return btypes.MsgQueueLimits{
+		MaxMsgs:    nc.MaxMsgsPerTx,
+		MaxTxBytes: nc.MaxTxBytes,
+		MaxTxGas:   nc.MaxTxGas,
+	}
+}
+
+// The components of the executor, which can be disabled for the partial deployments.
+const (
+	ComponentOutputSubmitter = "output_submitter"
+	ComponentBatchSubmitter  = "batch_submitter"
+	ComponentDepositRelayer  = "deposit_relayer"
+	ComponentOracleRelayer   = "oracle_relayer"
+)
+
+// routableMsgTypes are the msg types signed by the bridge executor, which can be routed to another key.
+// The oracle updates are signed by the oracle bridge executor.
+var routableMsgTypes = map[string]bool{
+	sdk.MsgTypeURL(&opchildtypes.MsgFinalizeTokenDeposit{}): true,
 }
 
 type Config struct {
 	// Version is the version used to build output root.
 	Version uint8 `json:"version"`
 
+	// DryRun runs the full pipeline without signing any tx. The msgs which would be broadcasted
+	// are logged and recorded in the db instead, which can be inspected with `opinitd db dry-run`.
+	DryRun bool `json:"dry_run"`
+
 	// Server is the configuration for the server.
 	Server servertypes.ServerConfig `json:"server"`
 
+	// Metrics is the configuration for the prometheus metrics.
+	Metrics metrics.Config `json:"metrics"`
+
+	// Log is the configuration for the logs.
+	Log logging.Config `json:"log"`
+
 	// L1Node is the configuration for the l1 node.
 	L1Node NodeConfig `json:"l1_node"`
 	// L2Node is the configuration for the l2 node.
 	L2Node NodeConfig `json:"l2_node"`
 	// DANode is the configuration for the data availability node.
 	DANode NodeConfig `json:"da_node"`
+	// SecondaryDANode is the configuration for the secondary data availability node,
+	// which is used only if the dual submission is enabled.
+	SecondaryDANode NodeConfig `json:"secondary_da_node"`
 
 	// BridgeExecutor is the key name in the keyring for the bridge executor,
 	// which is used to relay initiate token bridge transaction from l1 to l2.
@@ -57,6 +244,34 @@ type Config struct {
 	//
 	// If L2 is using oracle, you need to set this field.
 	OracleBridgeExecutor string `json:"oracle_bridge_executor"`
+	// OracleRelayInterval is the minimum time between the relayed oracle updates. The first update after the
+	// interval passes is relayed, and the later updates in the interval are skipped. If it is 0, every l1 block is relayed.
+	OracleRelayInterval int64 `json:"oracle_relay_interval"` // seconds
+	// OracleMaxStaleness is the maximum number of l1 blocks the relayed oracle data can fall behind. If the last
+	// relayed update is older than it, the update is relayed even in the relay interval. If it is 0, there is no bound.
+	OracleMaxStaleness int64 `json:"oracle_max_staleness"`
+	// DisableOracleVerification disables the verification of the oracle updates against the l1 validator set.
+	// Disable it only if the l1 uses the nonstandard oracle data in the 0th tx.
+	DisableOracleVerification bool `json:"disable_oracle_verification"`
+
+	// BridgeExecutorRoutes maps the msg type url to the key name in the keyring,
+	// which signs the msgs of the type instead of the bridge executor.
+	// The routed accounts have their own sequences and pending txs, so the msgs
+	// of an account are not blocked by the msgs of the other accounts.
+	//
+	// The routed keys must be registered as bridge executors on L2.
+	// Only MsgFinalizeTokenDeposit is signed by the bridge executor, and can be routed.
+	BridgeExecutorRoutes map[string]string `json:"bridge_executor_routes"`
+
+	// StandbyProposerKeys is the list of key names in the keyring which can be rotated to the proposer.
+	// When the proposer of the bridge is updated to the address of a standby key, the output submitter
+	// re-signs the queued msgs and continues with the standby key without restart.
+	StandbyProposerKeys []string `json:"standby_proposer_keys"`
+
+	// WithdrawalClaimer is the key name in the keyring which signs the claims of the withdrawals on l1
+	// instead of the proposer. It is required to claim the withdrawals with the admin api, and the auto
+	// claim uses it if it is set. It requires the output submitter.
+	WithdrawalClaimer string `json:"withdrawal_claimer"`
 
 	// DisableOutputSubmitter is the flag to disable the output submitter.
 	// If it is true, the output submitter will not be started.
@@ -66,12 +281,76 @@ type Config struct {
 	// If it is true, the batch submitter will not be started.
 	DisableBatchSubmitter bool `json:"disable_batch_submitter"`
 
+	// DisableDepositRelayer is the flag to disable the deposit relayer.
+	// If it is true, the deposits on l1 are not relayed to l2 even with the bridge executor key.
+	DisableDepositRelayer bool `json:"disable_deposit_relayer"`
+
+	// DisableOracleRelayer is the flag to disable the oracle relayer.
+	// If it is true, the oracle updates on l1 are not relayed to l2 even with the oracle bridge executor key.
+	DisableOracleRelayer bool `json:"disable_oracle_relayer"`
+
 	// MaxChunks is the maximum number of chunks in a batch.
 	MaxChunks int64 `json:"max_chunks"`
 	// MaxChunkSize is the maximum size of a chunk in a batch.
 	MaxChunkSize int64 `json:"max_chunk_size"`
 	// MaxSubmissionTime is the maximum time to submit a batch.
 	MaxSubmissionTime int64 `json:"max_submission_time"` // seconds
+	// MaxBatchBytes is the compressed size of the batch which triggers the submission before the interval.
+	// If it is 0, the trigger is disabled.
+	MaxBatchBytes int64 `json:"max_batch_bytes"`
+	// MaxBatchBlocks is the number of blocks in the batch which triggers the submission before the interval.
+	// If it is 0, the trigger is disabled.
+	MaxBatchBlocks int64 `json:"max_batch_blocks"`
+	// MaxBatchGas is the max gas of a batch tx. If the simulated gas of a chunk exceeds it, the batch is split
+	// into the smaller chunks until every chunk fits. If it is 0, the batch txs are not simulated before the submission.
+	MaxBatchGas uint64 `json:"max_batch_gas"`
+	// MaxPendingBatchBytes is the compressed size of the finalized batches which are not confirmed on the DA yet,
+	// above which the batch submitter pauses processing the blocks until the backlog drains. If it is 0, it is disabled.
+	MaxPendingBatchBytes int64 `json:"max_pending_batch_bytes"`
+	// BatchCompression is the compression algorithm of the batch, "gzip" or "zstd".
+	// The change takes effect from the next batch.
+	BatchCompression string `json:"batch_compression"`
+	// BatchCompressionLevel is the compression level of the algorithm. If it is 0, the default level is used.
+	BatchCompressionLevel int `json:"batch_compression_level"`
+	// BatchBlockEvents is the allowlist of the event types, e.g. the oracle votes, written after each block in the batch
+	// for the verifiers replaying the state. If it is empty, only the blocks are written. The batch header records
+	// the format, and the change takes effect from the next batch. The block results are queried for every block,
+	// and the size impact depends on the allowed events; see Benchmark_BlockEvents of the batch package.
+	BatchBlockEvents []string `json:"batch_block_events"`
+	// DisableCommittedBatchHeader is the flag to submit the batch header in the legacy layout without the lengths
+	// and the checksum of the payload, for the readers which don't parse the committed header yet.
+	// The change takes effect from the next batch.
+	DisableCommittedBatchHeader bool `json:"disable_committed_batch_header"`
+	// CelestiaNamespace is the namespace of the batch blobs when the batch is submitted to Celestia.
+	// "chain_id" derives it from the l2 chain id, "bridge_id" derives it from the bridge id,
+	// and otherwise it is the hex encoded 10 bytes namespace id.
+	CelestiaNamespace string `json:"celestia_namespace"`
+
+	// DualSubmit is the flag to submit the batches to the secondary DA node as well as the DA node
+	// of the batch info, e.g. during the migration of the DA. The failures of the secondary DA node
+	// are logged and counted, and only the confirmations of the primary DA node advance the submission.
+	DualSubmit bool `json:"dual_submit"`
+	// SecondaryDAChainType is the chain type of the secondary DA node, "INITIA" or "CELESTIA".
+	SecondaryDAChainType string `json:"secondary_da_chain_type"`
+
+	// BatchArchive is the configuration of the local archive of the finalized batches,
+	// which can be re-submitted to the DA if the submitted data becomes unavailable.
+	BatchArchive BatchArchiveConfig `json:"batch_archive"`
+
+	// OutputSubmission is the configuration of the output submission triggers.
+	OutputSubmission OutputSubmissionConfig `json:"output_submission"`
+
+	// Pruning is the configuration of the pruning of the old data in the db.
+	Pruning PruningConfig `json:"pruning"`
+
+	// MinWithdrawalAmounts maps the base denom to the minimum withdrawal amount. The withdrawals below
+	// the minimum are still inserted to the tree, but they are flagged and excluded from the auto claim.
+	// Changing the minimum doesn't affect the withdrawals already stored.
+	MinWithdrawalAmounts map[string]uint64 `json:"min_withdrawal_amounts"`
+
+	// AutoClaim is the configuration of the automatic claim of the finalized withdrawals on l1,
+	// which is signed by the bridge executor key on l1 and paid by it.
+	AutoClaim AutoClaimConfig `json:"auto_claim"`
 
 	// DisableAutoSetL1Height is the flag to disable the automatic setting of the l1 height.
 	// If it is false, it will finds the optimal height and sets l1_start_height automatically
@@ -80,11 +359,17 @@ type Config struct {
 	DisableAutoSetL1Height bool `json:"disable_auto_set_l1_height"`
 	// L1StartHeight is the height to start the l1 node.
 	L1StartHeight int64 `json:"l1_start_height"`
-	// L2StartHeight is the height to start the l2 node. If it is 0, it will start from the latest height.
+	// L2StartHeight is the height to start the l2 node. If it is 0, the child detects it from the last output
+	// submitted to the host, and starts from the genesis if no output is submitted yet.
 	// If the latest height stored in the db is not 0, this config is ignored.
 	// L2 starts from the last submitted output l2 block number + 1 before L2StartHeight.
 	// L1 starts from the block number of the output tx + 1
 	L2StartHeight int64 `json:"l2_start_height"`
+
+	// SkipChainVerification is the flag to skip verifying the chain id of the l1 node and the bridge
+	// registered on the l2 chain at startup. It can be useful for the test networks where they mismatch.
+	SkipChainVerification bool `json:"skip_chain_verification"`
+
 	// BatchStartHeight is the height to start the batch. If it is 0, it will start from the latest height.
 	// If the latest height stored in the db is not 0, this config is ignored.
 	BatchStartHeight int64 `json:"batch_start_height"`
@@ -93,19 +378,115 @@ type Config struct {
 	// when the bot is rolled back, it will delete the future withdrawals from DB.
 	// If it is true, it will not delete the future withdrawals.
 	DisableDeleteFutureWithdrawal bool `json:"disable_delete_future_withdrawal"`
+
+	// ConfirmDestructiveRewind is the flag to confirm the deletion of the future withdrawals.
+	// If it is false, the bot only reports the withdrawals to be deleted and refuses to start.
+	ConfirmDestructiveRewind bool `json:"confirm_destructive_rewind"`
+
+	// HaltOnOutputDeletion is the flag to halt the output submission when the challenger deletes an output.
+	// If it is false, the bot rewinds to the output right before the deleted one and proposes the outputs again.
+	HaltOnOutputDeletion bool `json:"halt_on_output_deletion"`
+
+	// SkipBlockOnHandlerPanic is the flag to skip the block when a handler panics.
+	// The withdrawals of the skipped l2 block are dropped, as the child carries its working tree forward.
+	// If it is false, the node halts on the block with the handler panic.
+	SkipBlockOnHandlerPanic bool `json:"skip_block_on_handler_panic"`
+
+	// CheckDBIntegrity is the flag to decode the critical records of the db at startup, such as the sync info
+	// and the trees, to report the records corrupted by a power loss. The bot refuses to start on the corrupted
+	// records unless it is started with --repair-db, which moves them to the quarantine prefix.
+	CheckDBIntegrity bool `json:"check_db_integrity"`
+
+	// Bridges is the list of the bridge instances run in a process. If it is not empty, the executor runs
+	// an instance per bridge with the config of the bridge, sharing the server, the metrics and the log config
+	// of this config, and the other fields of this config are ignored.
+	Bridges []BridgeConfig `json:"bridges,omitempty"`
+
+	// bridgeID is the id of the bridge instance, which labels the metrics of the nodes of the bridge.
+	// It is set by BridgeConfigs and empty for the single bridge.
+	bridgeID string
+}
+
+// BridgeConfig is the config of a bridge instance run with the other bridges in a process. The version,
+// the server, the metrics and the log config are inherited from the process config.
+type BridgeConfig struct {
+	// ID identifies the instance in the db prefix, the batch directory and the api routes `/bridges/{id}`.
+	ID string `json:"id"`
+
+	Config
+}
+
+// bridgeIDRegex is the pattern of the bridge instance id, which is used in the paths and the urls.
+var bridgeIDRegex = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+// BridgeConfigs returns the configs of the bridge instances with the fields inherited from the process config.
+func (cfg Config) BridgeConfigs() []BridgeConfig {
+	bridges := make([]BridgeConfig, 0, len(cfg.Bridges))
+	for _, bridge := range cfg.Bridges {
+		if bridge.Version == 0 {
+			bridge.Version = cfg.Version
+		}
+		bridge.Server = cfg.Server
+		bridge.Metrics = cfg.Metrics
+		bridge.Log = cfg.Log
+		bridge.bridgeID = bridge.ID
+		bridges = append(bridges, bridge)
+	}
+	return bridges
+}
+
+// validateBridges validates the process config and the configs of the bridge instances.
+func (cfg Config) validateBridges() error {
+	var problems config.Problems
+	problems.Add("server", cfg.Server.Validate())
+	problems.Add("metrics", cfg.Metrics.Validate())
+	problems.Add("log", cfg.Log.Validate())
+
+	ids := make(map[string]bool)
+	l2ChainIDs := make(map[string]string)
+	for i, bridge := range cfg.BridgeConfigs() {
+		field := fmt.Sprintf("bridges[%d]", i)
+		if !bridgeIDRegex.MatchString(bridge.ID) {
+			problems.Addf(field+".id", "bridge id must consist of alphanumeric characters, '-' and '_': %q", bridge.ID)
+		} else if ids[bridge.ID] {
+			problems.Addf(field+".id", "duplicate bridge id: %s", bridge.ID)
+		} else {
+			ids[bridge.ID] = true
+			field = fmt.Sprintf("bridges[%s]", bridge.ID)
+		}
+
+		if len(bridge.Bridges) > 0 {
+			problems.Addf(field+".bridges", "bridge instance can't have the bridges")
+			continue
+		}
+		// the batch files and the keys are separated by the bridge, but the l2 must be unique
+		if other, ok := l2ChainIDs[bridge.L2Node.ChainID]; ok {
+			problems.Addf(field+".l2_node", "l2 chain id %s is already used by the bridge %s", bridge.L2Node.ChainID, other)
+		} else {
+			l2ChainIDs[bridge.L2Node.ChainID] = bridge.ID
+		}
+		problems.Add(field, bridge.Config.Validate())
+	}
+	return problems.Err()
 }
 
 func DefaultConfig() *Config {
 	return &Config{
 		Version: 1,
+		DryRun:  false,
 
 		Server: servertypes.ServerConfig{
 			Address:      "localhost:3000",
 			AllowOrigins: "*",
 			AllowHeaders: "Origin, Content-Type, Accept",
 			AllowMethods: "GET",
+			Health:       servertypes.DefaultHealthConfig(),
 		},
 
+		Metrics: metrics.DefaultConfig(),
+
+		Log: logging.DefaultConfig(),
+
 		L1Node: NodeConfig{
 			ChainID:       "testnet-l1-1",
 			Bech32Prefix:  "init",
@@ -113,6 +494,9 @@ func DefaultConfig() *Config {
 			GasPrice:      "0.15uinit",
 			GasAdjustment: 1.5,
 			TxTimeout:     60,
+
+			LowBalanceGas:        200000,
+			BalanceCheckInterval: 60,
 		},
 
 		L2Node: NodeConfig{
@@ -122,6 +506,9 @@ func DefaultConfig() *Config {
 			GasPrice:      "",
 			GasAdjustment: 1.5,
 			TxTimeout:     60,
+
+			LowBalanceGas:        0,
+			BalanceCheckInterval: 60,
 		},
 
 		DANode: NodeConfig{
@@ -131,91 +518,315 @@ func DefaultConfig() *Config {
 			GasPrice:      "0.15uinit",
 			GasAdjustment: 1.5,
 			TxTimeout:     60,
+
+			LowBalanceGas:        200000,
+			BalanceCheckInterval: 60,
 		},
 
-		BridgeExecutor:         "",
-		OracleBridgeExecutor:   "",
-		DisableOutputSubmitter: false,
-		DisableBatchSubmitter:  false,
+		BridgeExecutor:            "",
+		OracleBridgeExecutor:      "",
+		OracleRelayInterval:       0,
+		OracleMaxStaleness:        0,
+		DisableOracleVerification: false,
+		BridgeExecutorRoutes:      map[string]string{},
+		StandbyProposerKeys:       []string{},
+		WithdrawalClaimer:         "",
+		DisableOutputSubmitter:    false,
+		DisableBatchSubmitter:     false,
+		DisableDepositRelayer:     false,
+		DisableOracleRelayer:      false,
 
 		MaxChunks:         5000,
 		MaxChunkSize:      300000,  // 300KB
 		MaxSubmissionTime: 60 * 60, // 1 hour
+		MaxBatchBytes:     0,
+		MaxBatchBlocks:    0,
+		MaxBatchGas:       0,
+
+		MaxPendingBatchBytes: 0,
+
+		BatchCompression:      "gzip",
+		BatchCompressionLevel: 0,
+		BatchBlockEvents:      []string{},
+		CelestiaNamespace:     CelestiaNamespaceChainID,
+
+		DisableCommittedBatchHeader: false,
+
+		DualSubmit:           false,
+		SecondaryDAChainType: "",
+
+		BatchArchive: BatchArchiveConfig{
+			Dir:      "",
+			MaxBytes: 0,
+			MaxAge:   0,
+		},
+
+		Pruning: PruningConfig{
+			Interval:            60 * 60, // 1 hour
+			KeepWorkingTrees:    0,
+			PendingTxsRetention: 0,
+			PruneClaimedTrees:   true,
+		},
+
+		MinWithdrawalAmounts: map[string]uint64{},
+
+		AutoClaim: AutoClaimConfig{
+			Enabled:        false,
+			Allowlist:      []string{},
+			MaxGasPerBlock: 0,
+		},
 
 		DisableAutoSetL1Height:        false,
 		L1StartHeight:                 0,
 		L2StartHeight:                 0,
 		BatchStartHeight:              0,
 		DisableDeleteFutureWithdrawal: false,
+		HaltOnOutputDeletion:          false,
+		SkipBlockOnHandlerPanic:       false,
 	}
 }
 
 func (cfg Config) Validate() error {
+	if len(cfg.Bridges) > 0 {
+		return cfg.validateBridges()
+	}
+
+	var problems config.Problems
 	if cfg.Version == 0 {
-		return errors.New("version is required")
+		problems.Addf("version", "version is required")
+	} else if cfg.Version != 1 {
+		problems.Addf("version", "only version 1 is supported")
+	}
+
+	problems.Add("server", cfg.Server.Validate())
+	problems.Add("metrics", cfg.Metrics.Validate())
+	problems.Add("log", cfg.Log.Validate())
+	problems.Add("l1_node", cfg.L1Node.Validate())
+	problems.Add("l2_node", cfg.L2Node.Validate())
+	problems.Add("da_node", cfg.DANode.Validate())
+
+	// the broadcasters are validated only if the nodes are valid, since they share the fields
+	if problems.Err() == nil {
+		problems.Add("l1_node", validateBroadcaster(cfg.L1NodeConfig("")))
+		problems.Add("l2_node", validateBroadcaster(cfg.L2NodeConfig("")))
+		problems.Add("da_node", validateBroadcaster(cfg.DANodeConfig("")))
+	}
+
+	for msgType, keyName := range cfg.BridgeExecutorRoutes {
+		if msgType == "" || keyName == "" {
+			problems.Addf("bridge_executor_routes", "bridge executor route must have msg type and key name")
+		} else if !routableMsgTypes[msgType] {
+			problems.Addf("bridge_executor_routes", "msg type %s is not signed by the bridge executor", msgType)
+		} else if keyName == cfg.BridgeExecutor || keyName == cfg.OracleBridgeExecutor {
+			problems.Addf("bridge_executor_routes", "bridge executor route must use a different key from the bridge executor and the oracle bridge executor")
+		}
+	}
+
+	if cfg.DisableOutputSubmitter && cfg.DisableBatchSubmitter && cfg.DisableDepositRelayer && cfg.DisableOracleRelayer {
+		problems.Addf("components", "at least one of the output submitter, the batch submitter, the deposit relayer and the oracle relayer must be enabled")
 	}
 
-	if cfg.Version != 1 {
-		return errors.New("only version 1 is supported")
+	if !cfg.DisableBatchSubmitter && cfg.L1Node.RPCAddress == "" {
+		problems.Addf("l1_node", "batch submitter requires the host query access through the rpc address of the l1 node")
 	}
 
-	if err := cfg.Server.Validate(); err != nil {
-		return err
+	// the skipped l2 blocks would be left out of the batches
+	if !cfg.DisableBatchSubmitter && len(cfg.L2Node.SkipHeights) != 0 {
+		problems.Addf("l2_node", "skip_heights: not supported with the batch submitter, which submits every l2 block")
 	}
 
-	if err := cfg.L1Node.Validate(); err != nil {
-		return err
+	if !cfg.DisableOutputSubmitter && cfg.L1Node.GasPrice == "" {
+		problems.Addf("l1_node", "output submitter requires the host key, which proposes the outputs with the gas price of the l1 node")
 	}
 
-	if err := cfg.L2Node.Validate(); err != nil {
-		return err
+	if !cfg.DisableOracleRelayer && cfg.OracleBridgeExecutor != "" && cfg.BridgeExecutor == "" {
+		problems.Addf("oracle_bridge_executor", "oracle relayer requires the bridge executor, which grants the fees of the oracle bridge executor")
+	}
+
+	for _, keyName := range cfg.StandbyProposerKeys {
+		if keyName == "" {
+			problems.Addf("standby_proposer_keys", "standby proposer key name must not be empty")
+		}
 	}
 
-	if err := cfg.DANode.Validate(); err != nil {
-		return err
+	if cfg.WithdrawalClaimer != "" && cfg.DisableOutputSubmitter {
+		problems.Addf("withdrawal_claimer", "withdrawal claimer requires the output submitter")
 	}
 
 	if cfg.MaxChunks <= 0 {
-		return errors.New("max chunks must be greater than 0")
+		problems.Addf("max_chunks", "max chunks must be greater than 0")
 	}
 
 	if cfg.MaxChunkSize <= 0 {
-		return errors.New("max chunk size must be greater than 0")
+		problems.Addf("max_chunk_size", "max chunk size must be greater than 0")
+	}
+
+	if cfg.OracleRelayInterval < 0 {
+		problems.Addf("oracle_relay_interval", "oracle relay interval must be greater than or equal to 0")
+	}
+	if cfg.OracleMaxStaleness < 0 {
+		problems.Addf("oracle_max_staleness", "oracle max staleness must be greater than or equal to 0")
 	}
 
 	if cfg.MaxSubmissionTime <= 0 {
-		return errors.New("max submission time must be greater than 0")
+		problems.Addf("max_submission_time", "max submission time must be greater than 0")
+	}
+
+	if cfg.MaxBatchBytes < 0 {
+		problems.Addf("max_batch_bytes", "max batch bytes must be greater than or equal to 0")
+	}
+
+	if cfg.MaxBatchBlocks < 0 {
+		problems.Addf("max_batch_blocks", "max batch blocks must be greater than or equal to 0")
+	}
+
+	if cfg.MaxPendingBatchBytes < 0 {
+		problems.Addf("max_pending_batch_bytes", "max pending batch bytes must be greater than or equal to 0")
+	}
+
+	if _, err := BatchCompressionFromString(cfg.BatchCompression); err != nil {
+		problems.Add("batch_compression", err)
+	}
+
+	for _, eventType := range cfg.BatchBlockEvents {
+		if eventType == "" {
+			problems.Addf("batch_block_events", "batch block event type must not be empty")
+		}
+	}
+
+	problems.Add("celestia_namespace", ValidateCelestiaNamespace(cfg.CelestiaNamespace))
+
+	if cfg.DualSubmit {
+		if cfg.DisableBatchSubmitter {
+			problems.Addf("dual_submit", "dual submission requires the batch submitter")
+		}
+		problems.Add("secondary_da_node", cfg.SecondaryDANode.Validate())
+
+		switch cfg.SecondaryDAChainType {
+		case "INITIA", "CELESTIA":
+		default:
+			problems.Addf("secondary_da_chain_type", "invalid secondary DA chain type: %s", cfg.SecondaryDAChainType)
+		}
 	}
 
+	problems.Add("batch_archive", cfg.BatchArchive.Validate())
+	problems.Add("output_submission", cfg.OutputSubmission.Validate())
+	problems.Add("pruning", cfg.Pruning.Validate())
+
+	for denom := range cfg.MinWithdrawalAmounts {
+		if denom == "" {
+			problems.Addf("min_withdrawal_amounts", "min withdrawal amount must have a denom")
+		}
+	}
+
+	problems.Add("auto_claim", cfg.AutoClaim.Validate())
+
 	if cfg.L1StartHeight < 0 {
-		return errors.New("l1 start height must be greater than or equal to 0")
+		problems.Addf("l1_start_height", "l1 start height must be greater than or equal to 0")
 	}
 
 	if cfg.L2StartHeight < 0 {
-		return errors.New("l2 start height must be greater than or equal to 0")
+		problems.Addf("l2_start_height", "l2 start height must be greater than or equal to 0")
 	}
 
 	if cfg.BatchStartHeight < 0 {
-		return errors.New("batch start height must be greater than or equal to 0")
+		problems.Addf("batch_start_height", "batch start height must be greater than or equal to 0")
 	}
-	return nil
+	return problems.Err()
+}
+
+// DepositRelayerEnabled returns true if the deposits are relayed to l2 by the bridge executor.
+func (cfg Config) DepositRelayerEnabled() bool {
+	return !cfg.DisableDepositRelayer && cfg.BridgeExecutor != ""
+}
+
+// OracleRelayerEnabled returns true if the oracle updates are relayed to l2 by the oracle bridge executor,
+// whose fees are granted by the bridge executor.
+func (cfg Config) OracleRelayerEnabled() bool {
+	return !cfg.DisableOracleRelayer && cfg.OracleBridgeExecutor != "" && cfg.BridgeExecutor != ""
+}
+
+// DisabledComponents returns the components disabled by the flags or by the missing keys.
+func (cfg Config) DisabledComponents() []string {
+	disabled := make([]string, 0)
+	if cfg.DisableOutputSubmitter {
+		disabled = append(disabled, ComponentOutputSubmitter)
+	}
+	if cfg.DisableBatchSubmitter {
+		disabled = append(disabled, ComponentBatchSubmitter)
+	}
+	if !cfg.DepositRelayerEnabled() {
+		disabled = append(disabled, ComponentDepositRelayer)
+	}
+	if !cfg.OracleRelayerEnabled() {
+		disabled = append(disabled, ComponentOracleRelayer)
+	}
+	return disabled
+}
+
+// validateBroadcaster validates the broadcaster config of the node, if the node broadcasts the txs.
+func validateBroadcaster(nc nodetypes.NodeConfig) error {
+	if nc.BroadcasterConfig == nil {
+		return nil
+	}
+	return nc.BroadcasterConfig.Validate()
+}
+
+func (cfg Config) handlerPanicPolicy() nodetypes.HandlerPanicPolicy {
+	if cfg.SkipBlockOnHandlerPanic {
+		return nodetypes.HANDLER_PANIC_POLICY_SKIP
+	}
+	return nodetypes.HANDLER_PANIC_POLICY_HALT
 }
 
 func (cfg Config) L1NodeConfig(homePath string) nodetypes.NodeConfig {
 	nc := nodetypes.NodeConfig{
 		RPC:          cfg.L1Node.RPCAddress,
+		Bridge:       cfg.bridgeID,
+		GRPCAddress:  cfg.L1Node.GRPCAddress,
+		GRPCTLS:      cfg.L1Node.GRPCTLS,
+		ChainID:      cfg.L1Node.ChainID,
 		ProcessType:  nodetypes.PROCESS_TYPE_DEFAULT,
 		Bech32Prefix: cfg.L1Node.Bech32Prefix,
+
+		HandlerPanicPolicy: cfg.handlerPanicPolicy(),
+		SkipHeights:        cfg.L1Node.SkipHeights,
+		PollingInterval:    time.Duration(cfg.L1Node.PollingInterval) * time.Millisecond,
+		MaxPollingInterval: time.Duration(cfg.L1Node.MaxPollingInterval) * time.Millisecond,
+		MaxCatchUpWait:     time.Duration(cfg.L1Node.MaxCatchUpWait) * time.Second,
+		RPCTimeout:         time.Duration(cfg.L1Node.RPCTimeout) * time.Second,
+
+		CodecModules:      cfg.L1Node.CodecModules,
+		LenientTxDecoding: cfg.L1Node.LenientTxDecoding,
 	}
 
 	if !cfg.DisableOutputSubmitter {
 		nc.BroadcasterConfig = &btypes.BroadcasterConfig{
-			ChainID:       cfg.L1Node.ChainID,
-			GasPrice:      cfg.L1Node.GasPrice,
-			GasAdjustment: cfg.L1Node.GasAdjustment,
-			TxTimeout:     time.Duration(cfg.L1Node.TxTimeout) * time.Second,
-			Bech32Prefix:  cfg.L1Node.Bech32Prefix,
-			HomePath:      homePath,
+			ChainID:         cfg.L1Node.ChainID,
+			Bridge:          cfg.bridgeID,
+			GasPrice:        cfg.L1Node.GasPrice,
+			GasAdjustment:   cfg.L1Node.GasAdjustment,
+			TxTimeout:       time.Duration(cfg.L1Node.TxTimeout) * time.Second,
+			TxTimeoutHeight: cfg.L1Node.TxTimeoutHeight,
+			Bech32Prefix:    cfg.L1Node.Bech32Prefix,
+			HomePath:        homePath,
+
+			BalanceCheckInterval: time.Duration(cfg.L1Node.BalanceCheckInterval) * time.Second,
+			LowBalanceGas:        cfg.L1Node.LowBalanceGas,
+			FeeGranter:           cfg.L1Node.FeeGranter,
+			Memo:                 cfg.L1Node.Memo,
+
+			GasPriceEscalation:    cfg.L1Node.GasPriceEscalation,
+			MaxGasPriceMultiplier: cfg.L1Node.MaxGasPriceMultiplier,
+			IsolateFailingMsgs:    cfg.L1Node.IsolateFailingMsgs,
+			DryRun:                cfg.DryRun,
+
+			GasPriceSource:          cfg.L1Node.GasPriceSource,
+			FeemarketTipMultiplier:  cfg.L1Node.FeemarketTipMultiplier,
+			GasPriceRefreshInterval: time.Duration(cfg.L1Node.GasPriceRefreshInterval) * time.Second,
+			GasPriceHysteresis:      cfg.L1Node.GasPriceHysteresis,
+
+			SequenceReconcileInterval: cfg.L1Node.SequenceReconcileInterval,
 		}
 	}
 
@@ -225,18 +836,51 @@ func (cfg Config) L1NodeConfig(homePath string) nodetypes.NodeConfig {
 func (cfg Config) L2NodeConfig(homePath string) nodetypes.NodeConfig {
 	nc := nodetypes.NodeConfig{
 		RPC:          cfg.L2Node.RPCAddress,
+		Bridge:       cfg.bridgeID,
+		GRPCAddress:  cfg.L2Node.GRPCAddress,
+		GRPCTLS:      cfg.L2Node.GRPCTLS,
+		ChainID:      cfg.L2Node.ChainID,
 		ProcessType:  nodetypes.PROCESS_TYPE_DEFAULT,
 		Bech32Prefix: cfg.L2Node.Bech32Prefix,
+
+		HandlerPanicPolicy: cfg.handlerPanicPolicy(),
+		SkipHeights:        cfg.L2Node.SkipHeights,
+		PollingInterval:    time.Duration(cfg.L2Node.PollingInterval) * time.Millisecond,
+		MaxPollingInterval: time.Duration(cfg.L2Node.MaxPollingInterval) * time.Millisecond,
+		MaxCatchUpWait:     time.Duration(cfg.L2Node.MaxCatchUpWait) * time.Second,
+		RPCTimeout:         time.Duration(cfg.L2Node.RPCTimeout) * time.Second,
+
+		CodecModules:      cfg.L2Node.CodecModules,
+		LenientTxDecoding: cfg.L2Node.LenientTxDecoding,
 	}
 
-	if cfg.BridgeExecutor != "" || cfg.OracleBridgeExecutor != "" {
+	if cfg.DepositRelayerEnabled() || cfg.OracleRelayerEnabled() {
 		nc.BroadcasterConfig = &btypes.BroadcasterConfig{
-			ChainID:       cfg.L2Node.ChainID,
-			GasPrice:      cfg.L2Node.GasPrice,
-			GasAdjustment: cfg.L2Node.GasAdjustment,
-			TxTimeout:     time.Duration(cfg.L2Node.TxTimeout) * time.Second,
-			Bech32Prefix:  cfg.L2Node.Bech32Prefix,
-			HomePath:      homePath,
+			ChainID:         cfg.L2Node.ChainID,
+			Bridge:          cfg.bridgeID,
+			GasPrice:        cfg.L2Node.GasPrice,
+			GasAdjustment:   cfg.L2Node.GasAdjustment,
+			TxTimeout:       time.Duration(cfg.L2Node.TxTimeout) * time.Second,
+			TxTimeoutHeight: cfg.L2Node.TxTimeoutHeight,
+			Bech32Prefix:    cfg.L2Node.Bech32Prefix,
+			HomePath:        homePath,
+
+			BalanceCheckInterval: time.Duration(cfg.L2Node.BalanceCheckInterval) * time.Second,
+			LowBalanceGas:        cfg.L2Node.LowBalanceGas,
+			FeeGranter:           cfg.L2Node.FeeGranter,
+			Memo:                 cfg.L2Node.Memo,
+
+			GasPriceEscalation:    cfg.L2Node.GasPriceEscalation,
+			MaxGasPriceMultiplier: cfg.L2Node.MaxGasPriceMultiplier,
+			IsolateFailingMsgs:    cfg.L2Node.IsolateFailingMsgs,
+			DryRun:                cfg.DryRun,
+
+			GasPriceSource:          cfg.L2Node.GasPriceSource,
+			FeemarketTipMultiplier:  cfg.L2Node.FeemarketTipMultiplier,
+			GasPriceRefreshInterval: time.Duration(cfg.L2Node.GasPriceRefreshInterval) * time.Second,
+			GasPriceHysteresis:      cfg.L2Node.GasPriceHysteresis,
+
+			SequenceReconcileInterval: cfg.L2Node.SequenceReconcileInterval,
 		}
 	}
 
@@ -244,20 +888,61 @@ func (cfg Config) L2NodeConfig(homePath string) nodetypes.NodeConfig {
 }
 
 func (cfg Config) DANodeConfig(homePath string) nodetypes.NodeConfig {
+	return cfg.daNodeConfig(cfg.DANode, homePath)
+}
+
+// SecondaryDANodeConfig returns the node config of the secondary DA node of the dual submission.
+func (cfg Config) SecondaryDANodeConfig(homePath string) nodetypes.NodeConfig {
+	return cfg.daNodeConfig(cfg.SecondaryDANode, homePath)
+}
+
+func (cfg Config) daNodeConfig(daNode NodeConfig, homePath string) nodetypes.NodeConfig {
 	nc := nodetypes.NodeConfig{
-		RPC:          cfg.DANode.RPCAddress,
+		RPC:          daNode.RPCAddress,
+		Bridge:       cfg.bridgeID,
+		GRPCAddress:  daNode.GRPCAddress,
+		GRPCTLS:      daNode.GRPCTLS,
+		ChainID:      daNode.ChainID,
 		ProcessType:  nodetypes.PROCESS_TYPE_ONLY_BROADCAST,
-		Bech32Prefix: cfg.DANode.Bech32Prefix,
+		Bech32Prefix: daNode.Bech32Prefix,
+
+		HandlerPanicPolicy: cfg.handlerPanicPolicy(),
+		SkipHeights:        daNode.SkipHeights,
+		PollingInterval:    time.Duration(daNode.PollingInterval) * time.Millisecond,
+		MaxPollingInterval: time.Duration(daNode.MaxPollingInterval) * time.Millisecond,
+		RPCTimeout:         time.Duration(daNode.RPCTimeout) * time.Second,
+
+		CodecModules:      daNode.CodecModules,
+		LenientTxDecoding: daNode.LenientTxDecoding,
 	}
 
 	if !cfg.DisableBatchSubmitter {
 		nc.BroadcasterConfig = &btypes.BroadcasterConfig{
-			ChainID:       cfg.DANode.ChainID,
-			GasPrice:      cfg.DANode.GasPrice,
-			GasAdjustment: cfg.DANode.GasAdjustment,
-			TxTimeout:     time.Duration(cfg.DANode.TxTimeout) * time.Second,
-			Bech32Prefix:  cfg.DANode.Bech32Prefix,
-			HomePath:      homePath,
+			ChainID:         daNode.ChainID,
+			Bridge:          cfg.bridgeID,
+			GasPrice:        daNode.GasPrice,
+			GasAdjustment:   daNode.GasAdjustment,
+			TxTimeout:       time.Duration(daNode.TxTimeout) * time.Second,
+			TxTimeoutHeight: daNode.TxTimeoutHeight,
+			Bech32Prefix:    daNode.Bech32Prefix,
+			HomePath:        homePath,
+
+			BalanceCheckInterval: time.Duration(daNode.BalanceCheckInterval) * time.Second,
+			LowBalanceGas:        daNode.LowBalanceGas,
+			FeeGranter:           daNode.FeeGranter,
+			Memo:                 daNode.Memo,
+
+			GasPriceEscalation:    daNode.GasPriceEscalation,
+			MaxGasPriceMultiplier: daNode.MaxGasPriceMultiplier,
+			IsolateFailingMsgs:    daNode.IsolateFailingMsgs,
+			DryRun:                cfg.DryRun,
+
+			GasPriceSource:          daNode.GasPriceSource,
+			FeemarketTipMultiplier:  daNode.FeemarketTipMultiplier,
+			GasPriceRefreshInterval: time.Duration(daNode.GasPriceRefreshInterval) * time.Second,
+			GasPriceHysteresis:      daNode.GasPriceHysteresis,
+
+			SequenceReconcileInterval: daNode.SequenceReconcileInterval,
 		}
 	}
 	return nc
@@ -268,11 +953,139 @@ func (cfg Config) BatchConfig() BatchConfig {
 		MaxChunks:         cfg.MaxChunks,
 		MaxChunkSize:      cfg.MaxChunkSize,
 		MaxSubmissionTime: cfg.MaxSubmissionTime,
+		MaxBatchBytes:     cfg.MaxBatchBytes,
+		MaxBatchBlocks:    cfg.MaxBatchBlocks,
+		MaxBatchGas:       cfg.MaxBatchGas,
+		Compression:       cfg.BatchCompression,
+		CompressionLevel:  cfg.BatchCompressionLevel,
+		BlockEvents:       cfg.BatchBlockEvents,
+		DualSubmit:        cfg.DualSubmit,
+		Archive:           cfg.BatchArchive,
+
+		DisableCommittedHeader: cfg.DisableCommittedBatchHeader,
+
+		MaxPendingBatchBytes: cfg.MaxPendingBatchBytes,
+		DryRun:               cfg.DryRun,
 	}
 }
 
 type BatchConfig struct {
-	MaxChunks         int64 `json:"max_chunks"`
-	MaxChunkSize      int64 `json:"max_chunk_size"`
-	MaxSubmissionTime int64 `json:"max_submission_time"` // seconds
+	MaxChunks         int64    `json:"max_chunks"`
+	MaxChunkSize      int64    `json:"max_chunk_size"`
+	MaxSubmissionTime int64    `json:"max_submission_time"` // seconds
+	MaxBatchBytes     int64    `json:"max_batch_bytes"`
+	MaxBatchBlocks    int64    `json:"max_batch_blocks"`
+	MaxBatchGas       uint64   `json:"max_batch_gas"`
+	Compression       string   `json:"compression"`
+	CompressionLevel  int      `json:"compression_level"`
+	BlockEvents       []string `json:"block_events"`
+	DualSubmit        bool     `json:"dual_submit"`
+
+	Archive BatchArchiveConfig `json:"archive"`
+
+	DisableCommittedHeader bool `json:"disable_committed_header"`
+
+	MaxPendingBatchBytes int64 `json:"max_pending_batch_bytes"`
+	// DryRun disables the back pressure, because the recorded batches are never confirmed.
+	DryRun bool `json:"dry_run"`
+}
+
+type BatchArchiveConfig struct {
+	// Dir is the directory where the finalized batches are archived. A relative path is resolved from the home
+	// directory of the bot. If it is empty, the batches are not archived.
+	Dir string `json:"dir"`
+	// MaxBytes is the total size of the archived batches, above which the oldest batches are deleted.
+	// If it is 0, the size is not limited.
+	MaxBytes int64 `json:"max_bytes"`
+	// MaxAge is the time to keep the archived batches. If it is 0, the batches are kept regardless of the age.
+	MaxAge int64 `json:"max_age"` // seconds
+}
+
+func (c BatchArchiveConfig) Validate() error {
+	if c.MaxBytes < 0 {
+		return errors.New("batch archive max bytes must be greater than or equal to 0")
+	}
+	if c.MaxAge < 0 {
+		return errors.New("batch archive max age must be greater than or equal to 0")
+	}
+	return nil
+}
+
+// OutputSubmissionConfig is the configuration of the output submission triggers. By default, the output is
+// submitted after 2/3 of the submission interval of the bridge.
+type OutputSubmissionConfig struct {
+	// MaxLeavesPerOutput is the number of withdrawals in the working tree which triggers the output submission
+	// before the interval, to bound the claim latency. If it is 0, the output is submitted only by the interval.
+	MaxLeavesPerOutput uint64 `json:"max_leaves_per_output"`
+	// MinInterval is the minimum time between the outputs, which is never violated by the triggers.
+	MinInterval int64 `json:"min_interval"` // seconds
+	// MaxInterval is the maximum time between the outputs. If it is 0 or longer than 2/3 of
+	// the submission interval of the bridge, 2/3 of the submission interval is used.
+	MaxInterval int64 `json:"max_interval"` // seconds
+}
+
+func (c OutputSubmissionConfig) Validate() error {
+	if c.MinInterval < 0 {
+		return errors.New("output submission min interval must be greater than or equal to 0")
+	}
+	if c.MaxInterval < 0 {
+		return errors.New("output submission max interval must be greater than or equal to 0")
+	}
+	if c.MaxInterval != 0 && c.MaxInterval < c.MinInterval {
+		return errors.New("output submission max interval must be greater than or equal to the min interval")
+	}
+	return nil
+}
+
+// Interval returns the time to the next output from the submission interval of the bridge.
+func (c OutputSubmissionConfig) Interval(submissionInterval time.Duration) time.Duration {
+	interval := submissionInterval * 2 / 3
+	if maxInterval := time.Duration(c.MaxInterval) * time.Second; maxInterval > 0 && maxInterval < interval {
+		interval = maxInterval
+	}
+	return max(interval, time.Duration(c.MinInterval)*time.Second)
+}
+
+type PruningConfig struct {
+	// Interval is the interval of the pruning. If it is 0, the pruning is disabled.
+	Interval int64 `json:"interval"` // seconds
+	// KeepWorkingTrees is the number of the latest working tree versions, which are the l2 heights, to keep.
+	// The rewinds load the working trees at the l2 heights of the outputs, so it must cover the blocks
+	// of the outputs which can be rewound. If it is 0, the working trees are not pruned.
+	KeepWorkingTrees uint64 `json:"keep_working_trees"`
+	// PendingTxsRetention is the time to keep the records of the pending txs, which are already broadcasted.
+	// The processed msgs waiting to be broadcasted are never pruned. If it is 0, the pending txs are not pruned.
+	PendingTxsRetention int64 `json:"pending_txs_retention"` // seconds
+	// PruneClaimedTrees is the flag to prune the nodes of the finalized trees whose withdrawals are all claimed on l1.
+	// The claimed status is queried from l1, and the trees of the unclaimed withdrawals are always kept.
+	PruneClaimedTrees bool `json:"prune_claimed_trees"`
+}
+
+func (c PruningConfig) Validate() error {
+	if c.Interval < 0 {
+		return errors.New("pruning interval must be greater than or equal to 0")
+	}
+	if c.PendingTxsRetention < 0 {
+		return errors.New("pruning retention must be greater than or equal to 0")
+	}
+	return nil
+}
+
+type AutoClaimConfig struct {
+	// Enabled is the flag to claim the withdrawals on l1 after the finalization period of their outputs.
+	Enabled bool `json:"enabled"`
+	// Allowlist is the l1 receivers whose withdrawals are claimed. If it is empty, the withdrawals of all the receivers are claimed.
+	Allowlist []string `json:"allowlist"`
+	// MaxGasPerBlock is the estimated gas of the claims submitted in a host block, and the rest are claimed
+	// in the next blocks. If it is 0, the gas is not limited.
+	MaxGasPerBlock uint64 `json:"max_gas_per_block"`
+}
+
+func (c AutoClaimConfig) Validate() error {
+	for _, address := range c.Allowlist {
+		if address == "" {
+			return errors.New("auto claim allowlist must not have an empty address")
+		}
+	}
+	return nil
 }
@@ -0,0 +1,242 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/initia-labs/opinit-bots/config"
+)
+
+func Test_DefaultConfigValidate(t *testing.T) {
+	require.NoError(t, DefaultConfig().Validate())
+}
+
+func Test_ConfigValidateBadConfigs(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(cfg *Config)
+		problems []string
+	}{
+		{
+			name:     "unsupported version",
+			modify:   func(cfg *Config) { cfg.Version = 2 },
+			problems: []string{"version: only version 1 is supported"},
+		},
+		{
+			name:     "empty chain id",
+			modify:   func(cfg *Config) { cfg.L2Node.ChainID = "" },
+			problems: []string{"l2_node: chain_id: chain id is required"},
+		},
+		{
+			name:     "rpc address without scheme",
+			modify:   func(cfg *Config) { cfg.L1Node.RPCAddress = "localhost:26657" },
+			problems: []string{`l1_node: rpc_address: invalid scheme of address "localhost:26657", must be one of tcp, http, https, unix`},
+		},
+		{
+			name:     "grpc address without port",
+			modify:   func(cfg *Config) { cfg.DANode.GRPCAddress = "localhost" },
+			problems: []string{"da_node: grpc_address: "},
+		},
+		{
+			name:     "grpc tls without grpc address",
+			modify:   func(cfg *Config) { cfg.L2Node.GRPCTLS = true },
+			problems: []string{"l2_node: grpc_tls: grpc address is required to enable the tls"},
+		},
+		{
+			name:     "gas price without denom",
+			modify:   func(cfg *Config) { cfg.L1Node.GasPrice = "0.15" },
+			problems: []string{`l1_node: gas_price: invalid gas price "0.15"`},
+		},
+		{
+			name:     "uppercase bech32 prefix",
+			modify:   func(cfg *Config) { cfg.DANode.Bech32Prefix = "INIT" },
+			problems: []string{`da_node: bech32_prefix: invalid bech32 prefix "INIT"`},
+		},
+		{
+			name:     "unsupported codec module",
+			modify:   func(cfg *Config) { cfg.L2Node.CodecModules = []string{"bank", "evm"} },
+			problems: []string{"l2_node: codec_modules: unsupported codec module: evm"},
+		},
+		{
+			name: "polling interval above the max",
+			modify: func(cfg *Config) {
+				cfg.L1Node.PollingInterval = 1000
+				cfg.L1Node.MaxPollingInterval = 100
+			},
+			problems: []string{"l1_node: max_polling_interval: must be greater than or equal to the polling interval"},
+		},
+		{
+			name:     "zero tx timeout of the broadcaster",
+			modify:   func(cfg *Config) { cfg.L1Node.TxTimeout = 0 },
+			problems: []string{"l1_node: tx timeout is zero"},
+		},
+		{
+			name: "zero tx timeout without the broadcaster",
+			modify: func(cfg *Config) {
+				cfg.DisableOutputSubmitter = true
+				cfg.L1Node.TxTimeout = 0
+			},
+		},
+		{
+			name:     "negative oracle relay interval",
+			modify:   func(cfg *Config) { cfg.OracleRelayInterval = -1 },
+			problems: []string{"oracle_relay_interval: "},
+		},
+		{
+			name:     "negative oracle max staleness",
+			modify:   func(cfg *Config) { cfg.OracleMaxStaleness = -1 },
+			problems: []string{"oracle_max_staleness: "},
+		},
+		{
+			name: "route of the msg type not signed by the bridge executor",
+			modify: func(cfg *Config) {
+				cfg.BridgeExecutorRoutes = map[string]string{"/opinit.opchild.v1.MsgUpdateOracle": "oracle"}
+			},
+			problems: []string{"bridge_executor_routes: msg type /opinit.opchild.v1.MsgUpdateOracle is not signed by the bridge executor"},
+		},
+		{
+			name: "all the components disabled",
+			modify: func(cfg *Config) {
+				cfg.DisableOutputSubmitter = true
+				cfg.DisableBatchSubmitter = true
+				cfg.DisableDepositRelayer = true
+				cfg.DisableOracleRelayer = true
+			},
+			problems: []string{"components: "},
+		},
+		{
+			name: "withdrawal claimer without the output submitter",
+			modify: func(cfg *Config) {
+				cfg.WithdrawalClaimer = "claimer"
+				cfg.DisableOutputSubmitter = true
+			},
+			problems: []string{"withdrawal_claimer: "},
+		},
+		{
+			name:     "oracle bridge executor without the bridge executor",
+			modify:   func(cfg *Config) { cfg.OracleBridgeExecutor = "oracle" },
+			problems: []string{"oracle_bridge_executor: "},
+		},
+		{
+			name:     "batch submitter without the host query access",
+			modify:   func(cfg *Config) { cfg.L1Node.RPCAddress = "" },
+			problems: []string{"l1_node: rpc_address: ", "l1_node: batch submitter requires the host query access"},
+		},
+		{
+			name:     "skip heights with the batch submitter",
+			modify:   func(cfg *Config) { cfg.L2Node.SkipHeights = []int64{10} },
+			problems: []string{"l2_node: skip_heights: not supported with the batch submitter"},
+		},
+		{
+			name: "skip heights without the batch submitter",
+			modify: func(cfg *Config) {
+				cfg.L2Node.SkipHeights = []int64{10}
+				cfg.DisableBatchSubmitter = true
+			},
+		},
+		{
+			name:     "output submitter without the host key",
+			modify:   func(cfg *Config) { cfg.L1Node.GasPrice = "" },
+			problems: []string{"l1_node: output submitter requires the host key"},
+		},
+		{
+			name: "no host key without the output submitter",
+			modify: func(cfg *Config) {
+				cfg.L1Node.GasPrice = ""
+				cfg.DisableOutputSubmitter = true
+			},
+		},
+		{
+			name: "dual submission without the batch submitter",
+			modify: func(cfg *Config) {
+				cfg.DualSubmit = true
+				cfg.SecondaryDANode = cfg.DANode
+				cfg.SecondaryDAChainType = "INITIA"
+				cfg.DisableBatchSubmitter = true
+			},
+			problems: []string{"dual_submit: "},
+		},
+		{
+			name: "invalid secondary DA",
+			modify: func(cfg *Config) {
+				cfg.DualSubmit = true
+				cfg.SecondaryDAChainType = "ETHEREUM"
+			},
+			problems: []string{"secondary_da_node: chain_id: ", "secondary_da_node: bech32_prefix: ", "secondary_da_node: rpc_address: ", "secondary_da_chain_type: "},
+		},
+		{
+			name: "duplicate bridges",
+			modify: func(cfg *Config) {
+				bridge := DefaultConfig()
+				cfg.Bridges = []BridgeConfig{{ID: "minimove", Config: *bridge}, {ID: "minimove", Config: *bridge}, {ID: "mini move", Config: *bridge}}
+			},
+			problems: []string{
+				"bridges[1].id: duplicate bridge id: minimove",
+				"bridges[1].l2_node: l2 chain id testnet-l2-1 is already used by the bridge minimove",
+				`bridges[2].id: bridge id must consist of alphanumeric characters, '-' and '_': "mini move"`,
+				"bridges[2].l2_node: l2 chain id testnet-l2-1 is already used by the bridge minimove",
+			},
+		},
+		{
+			name: "all the problems at once",
+			modify: func(cfg *Config) {
+				cfg.L1Node.ChainID = " "
+				cfg.L1Node.Bech32Prefix = "in-it"
+				cfg.L2Node.RPCAddress = "ws://localhost:27657"
+				cfg.DANode.GasPrice = "utia"
+				cfg.MaxChunks = 0
+				cfg.MaxSubmissionTime = -1
+				cfg.Pruning.Interval = -1
+			},
+			problems: []string{
+				"l1_node: chain_id: ",
+				"l1_node: bech32_prefix: ",
+				"l2_node: rpc_address: ",
+				"da_node: gas_price: ",
+				"max_chunks: ",
+				"max_submission_time: ",
+				"pruning: ",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tc.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tc.problems) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var problems config.Errors
+			require.ErrorAs(t, err, &problems)
+			require.Len(t, problems, len(tc.problems))
+			for i, problem := range problems {
+				require.Contains(t, problem.Error(), tc.problems[i])
+			}
+		})
+	}
+}
+
+func Test_DisabledComponents(t *testing.T) {
+	cfg := DefaultConfig()
+	require.Equal(t, []string{ComponentDepositRelayer, ComponentOracleRelayer}, cfg.DisabledComponents())
+
+	// the oracle relayer requires the oracle bridge executor as well as the bridge executor
+	cfg.BridgeExecutor = "executor"
+	require.Equal(t, []string{ComponentOracleRelayer}, cfg.DisabledComponents())
+	cfg.OracleBridgeExecutor = "oracle"
+	require.Empty(t, cfg.DisabledComponents())
+
+	cfg.DisableOutputSubmitter = true
+	cfg.DisableBatchSubmitter = true
+	cfg.DisableDepositRelayer = true
+	require.Equal(t, []string{ComponentOutputSubmitter, ComponentBatchSubmitter, ComponentDepositRelayer}, cfg.DisabledComponents())
+	require.NotNil(t, cfg.L2NodeConfig("").BroadcasterConfig)
+	cfg.DisableOracleRelayer = true
+	require.Nil(t, cfg.L2NodeConfig("").BroadcasterConfig)
+}
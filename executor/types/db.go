@@ -7,9 +7,76 @@ type WithdrawalData struct {
 	Amount         uint64 `json:"amount"`
 	BaseDenom      string `json:"base_denom"`
 	WithdrawalHash []byte `json:"withdrawal_hash"`
+
+	// BelowMinimum is true if the amount is below the minimum withdrawal amount at the time of the withdrawal.
+	BelowMinimum bool `json:"below_minimum,omitempty"`
+	// HashVersion is the version of the withdrawal hash. It is empty for the withdrawals of the version 1
+	// stored before it is introduced.
+	HashVersion uint8 `json:"hash_version,omitempty"`
 }
 
 type TreeExtraData struct {
 	BlockNumber int64  `json:"block_number"`
 	BlockHash   []byte `json:"block_hash"`
+
+	// TriggerReason is the reason why the tree is finalized.
+	TriggerReason string `json:"trigger_reason,omitempty"`
+
+	// OutputRoot is the output root computed locally when the tree is finalized.
+	// It is empty for the trees finalized before it is introduced.
+	OutputRoot []byte `json:"output_root,omitempty"`
+	// OutputRootVersion is the version of the output root of the tree, so the output root is recomputed
+	// with the same version after the version of the config changes. It is empty for the trees finalized
+	// before it is introduced, which are of the version 1.
+	OutputRootVersion uint8 `json:"output_root_version,omitempty"`
+	// WithdrawalHashVersion is the version of the withdrawal hashes of the leaves, which the proofs of the tree
+	// are verified with. It is empty for the trees of the version 1 finalized before it is introduced.
+	WithdrawalHashVersion uint8 `json:"withdrawal_hash_version,omitempty"`
+}
+
+// OutputRootVersionOrDefault returns the output root version of the tree, which is the version 1 for the
+// trees finalized before the version is recorded.
+func (d TreeExtraData) OutputRootVersionOrDefault() uint8 {
+	if d.OutputRootVersion == 0 {
+		return OutputRootVersion1
+	}
+	return d.OutputRootVersion
+}
+
+const (
+	// OutputTriggerSync finalizes the tree of the output already submitted while syncing.
+	OutputTriggerSync = "sync"
+	// OutputTriggerInterval finalizes the tree after the output interval.
+	OutputTriggerInterval = "interval"
+	// OutputTriggerMaxLeaves finalizes the tree early when it has too many withdrawals.
+	OutputTriggerMaxLeaves = "max_leaves"
+)
+
+// ProposedOutputInfo is the record of an output proposed by the host, kept for the audit and the recovery.
+type ProposedOutputInfo struct {
+	OutputIndex   uint64 `json:"output_index"`
+	L2BlockNumber int64  `json:"l2_block_number"`
+	Root          []byte `json:"root"`
+	L1Height      int64  `json:"l1_height"`
+	// L1TxHash is empty if the record is backfilled from the chain.
+	L1TxHash  string `json:"l1_tx_hash,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// DeleteFutureWithdrawalsReport is the summary of the withdrawals and the finalized trees deleted when the child
+// restarts from a height before them. It is persisted for the audit whether the deletion is confirmed or not.
+type DeleteFutureWithdrawalsReport struct {
+	FromSequence uint64 `json:"from_sequence"`
+	Count        uint64 `json:"count"`
+	// StartSequence and EndSequence are the range of the sequences of the deleted withdrawals.
+	StartSequence uint64   `json:"start_sequence"`
+	EndSequence   uint64   `json:"end_sequence"`
+	TreeIndices   []uint64 `json:"tree_indices"`
+	Confirmed     bool     `json:"confirmed"`
+	Timestamp     int64    `json:"timestamp"`
+}
+
+// IsEmpty returns true if there is nothing to delete.
+func (r DeleteFutureWithdrawalsReport) IsEmpty() bool {
+	return r.Count == 0 && len(r.TreeIndices) == 0
 }
@@ -6,6 +6,24 @@ import (
 
 var (
 	WithdrawalKey = []byte("withdrawal")
+
+	LastRelayedDepositSequenceKey = []byte("last_relayed_deposit_sequence")
+
+	ProposedOutputKey = []byte("proposed_output")
+
+	LastUpdatedOracleL1HeightKey = []byte("last_updated_oracle_l1_height")
+
+	LastFinalizedDepositSequenceKey = []byte("last_finalized_deposit_sequence")
+
+	BelowMinimumWithdrawalKey = []byte("below_minimum_withdrawal")
+
+	DeleteFutureWithdrawalsReportKey = []byte("delete_future_withdrawals_report")
+
+	ReadOnlyModeKey = []byte("read_only_mode")
+
+	DryRunModeKey = []byte("dry_run_mode")
+
+	DeferredOutputsKey = []byte("deferred_outputs")
 )
 
 func PrefixedWithdrawalKey(sequence uint64) []byte {
@@ -19,3 +37,19 @@ func PrefixedWithdrawalKeyAddress(address string) []byte {
 func PrefixedWithdrawalKeyAddressIndex(address string, index uint64) []byte {
 	return append(PrefixedWithdrawalKeyAddress(address), dbtypes.FromUint64Key(index)...)
 }
+
+func PrefixedLastRelayedDepositSequenceKey(bridgeId uint64) []byte {
+	return append(append(LastRelayedDepositSequenceKey, dbtypes.Splitter), dbtypes.FromUint64Key(bridgeId)...)
+}
+
+func PrefixedProposedOutputKey(outputIndex uint64) []byte {
+	return append(append(ProposedOutputKey, dbtypes.Splitter), dbtypes.FromUint64Key(outputIndex)...)
+}
+
+func PrefixedBelowMinimumWithdrawalKey(sequence uint64) []byte {
+	return append(append(BelowMinimumWithdrawalKey, dbtypes.Splitter), dbtypes.FromUint64Key(sequence)...)
+}
+
+func PrefixedDeleteFutureWithdrawalsReportKey(timestamp uint64) []byte {
+	return append(append(DeleteFutureWithdrawalsReportKey, dbtypes.Splitter), dbtypes.FromUint64Key(timestamp)...)
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"fmt"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+)
+
+// OutputRootVersion1 is the version of the output root, sha3(version || storage_root || last_block_hash),
+// which is the only version of the ophost module.
+const OutputRootVersion1 byte = 1
+
+// ComputeOutputRoot computes the output root of the version as the ophost module does.
+func ComputeOutputRoot(version byte, storageRoot []byte, blockHash []byte) ([32]byte, error) {
+	if len(storageRoot) != 32 {
+		return [32]byte{}, fmt.Errorf("invalid storage root length: %d", len(storageRoot))
+	} else if len(blockHash) != 32 {
+		return [32]byte{}, fmt.Errorf("invalid block hash length: %d", len(blockHash))
+	} else if version != OutputRootVersion1 {
+		return [32]byte{}, fmt.Errorf("unsupported output root version: %d", version)
+	}
+	return ophosttypes.GenerateOutputRoot(version, storageRoot, blockHash), nil
+}
+
+// DeferredOutput is the output whose proposal is deferred, e.g. while the proposer can't pay the fees.
+type DeferredOutput struct {
+	OutputIndex   uint64 `json:"output_index"`
+	L2BlockNumber int64  `json:"l2_block_number"`
+	OutputRoot    []byte `json:"output_root"`
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+)
+
+func TestComputeOutputRoot(t *testing.T) {
+	// the storage root and the block hash of Test_FinalizeTokenWithdrawal of the ophost module
+	sender := "osmo174knscjg688ddtxj8smyjz073r3w5mms8ugvx6"
+	receiver := "cosmos174knscjg688ddtxj8smyjz073r3w5mms08musg"
+	withdrawal1 := ophosttypes.GenerateWithdrawalHash(1, 1, sender, receiver, "uinit", 1_000_000)
+	withdrawal2 := ophosttypes.GenerateWithdrawalHash(1, 2, sender, receiver, "uinit", 1_000_000)
+	withdrawal3 := ophosttypes.GenerateWithdrawalHash(1, 3, sender, receiver, "uinit", 1_000_000)
+	node12 := ophosttypes.GenerateNodeHash(withdrawal1[:], withdrawal2[:])
+	node33 := ophosttypes.GenerateNodeHash(withdrawal3[:], withdrawal3[:])
+	storageRoot := ophosttypes.GenerateNodeHash(node12[:], node33[:])
+	blockHash, err := base64.StdEncoding.DecodeString("tgmfQJT4uipVToW631xz0RXdrfzu7n5XxGNoPpX6isI=")
+	require.NoError(t, err)
+	require.Equal(t, "91629b5cc4a5b77c4397485c5ca4365df2250d47af4d115869bd2d8f60dd64ff", hex.EncodeToString(storageRoot[:]))
+
+	cases := []struct {
+		name        string
+		version     byte
+		storageRoot []byte
+		blockHash   []byte
+		expected    string
+		err         string
+	}{
+		{
+			name:        "version 1",
+			version:     OutputRootVersion1,
+			storageRoot: storageRoot[:],
+			blockHash:   blockHash,
+			expected:    "b3528842a1c42142c6640ad41220ec04ace5558c35534981b804f5d956dfe7f3",
+		},
+		{
+			name:        "version 1 of zero hashes",
+			version:     OutputRootVersion1,
+			storageRoot: make([]byte, 32),
+			blockHash:   make([]byte, 32),
+			expected:    "b86805a796ed09229c23b327537e450bec57b43e9946455fc5e859345adf1abd",
+		},
+		{
+			name:        "short storage root",
+			version:     OutputRootVersion1,
+			storageRoot: storageRoot[:31],
+			blockHash:   blockHash,
+			err:         "invalid storage root length: 31",
+		},
+		{
+			name:        "short block hash",
+			version:     OutputRootVersion1,
+			storageRoot: storageRoot[:],
+			blockHash:   blockHash[:31],
+			err:         "invalid block hash length: 31",
+		},
+		{
+			name:        "unsupported version",
+			version:     2,
+			storageRoot: storageRoot[:],
+			blockHash:   blockHash,
+			err:         "unsupported output root version: 2",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			outputRoot, err := ComputeOutputRoot(tc.version, tc.storageRoot, tc.blockHash)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hex.EncodeToString(outputRoot[:]))
+			require.Equal(t, ophosttypes.GenerateOutputRoot(tc.version, tc.storageRoot, tc.blockHash), outputRoot)
+		})
+	}
+
+	// the trees finalized before the version is recorded are of the version 1
+	require.Equal(t, OutputRootVersion1, TreeExtraData{}.OutputRootVersionOrDefault())
+	require.Equal(t, uint8(2), TreeExtraData{OutputRootVersion: 2}.OutputRootVersionOrDefault())
+}
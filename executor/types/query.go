@@ -1,6 +1,10 @@
 package types
 
-import "github.com/cosmos/cosmos-sdk/types"
+import (
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types"
+)
 
 type QueryWithdrawalResponse struct {
 	// fields required to withdraw funds
@@ -16,11 +20,79 @@ type QueryWithdrawalResponse struct {
 	LastBlockHash    []byte     `json:"last_block_hash"`
 
 	// extra info
+	// OutputRoot is the root of the output proposed for the tree of the withdrawal.
+	OutputRoot []byte `json:"output_root,omitempty"`
+	// Finalized is true if the tree of the withdrawal is finalized, so the withdrawal can be claimed.
+	Finalized bool `json:"finalized"`
+	// Claimed is true if the withdrawal is already claimed on l1.
+	Claimed bool `json:"claimed"`
+	// BelowMinimum is true if the amount is below the minimum withdrawal amount.
+	BelowMinimum bool `json:"below_minimum,omitempty"`
+	// WithdrawalHashVersion is the version of the withdrawal hash, the leaf of the tree verified by the proofs.
+	WithdrawalHashVersion uint8 `json:"withdrawal_hash_version"`
+	// ClaimableAt is the time when the withdrawal becomes claimable on l1, the l1 block time of the output
+	// proposal plus the finalization period. It is empty until the output of the tree is proposed.
+	ClaimableAt *time.Time `json:"claimable_at,omitempty"`
+	// IsClaimable is true if the finalization period of the output is over and the withdrawal is not claimed yet.
+	IsClaimable bool `json:"is_claimable"`
 	// BlockNumber    int64  `json:"block_number"`
 	// WithdrawalHash []byte `json:"withdrawal_hash"`
 }
 
+type ClaimWithdrawalResponse struct {
+	Sequence uint64 `json:"sequence"`
+	// the host account signing the claim tx
+	Sender string `json:"sender"`
+	// the trace id to follow the claim tx
+	TraceID string `json:"trace_id"`
+}
+
 type QueryWithdrawalsResponse struct {
 	Withdrawals []QueryWithdrawalResponse `json:"withdrawals"`
 	Next        *uint64                   `json:"next,omitempty"`
 }
+
+type RetryDeadLetterResponse struct {
+	ID uint64 `json:"id"`
+	// the account broadcasting the retried msg
+	Sender string `json:"sender"`
+	// the trace id to follow the retried msg
+	TraceID string `json:"trace_id"`
+}
+
+type ReplayResponse struct {
+	Node string `json:"node"`
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+type DropPendingTxResponse struct {
+	Sender   string `json:"sender"`
+	Sequence uint64 `json:"sequence"`
+	TxHash   string `json:"tx_hash"`
+	// the trace id of the msgs of the dropped tx
+	TraceID string `json:"trace_id"`
+	// Requeued is true if the msgs of the dropped tx are broadcasted again.
+	Requeued bool `json:"requeued"`
+
+	// ResubmittedSequences are the sequences of the later pending txs of the sender,
+	// whose msgs are broadcasted again with the reconciled sequences.
+	ResubmittedSequences []uint64 `json:"resubmitted_sequences"`
+}
+
+// BridgeInstanceState is the state of a bridge instance run with the other bridges in a process.
+type BridgeInstanceState string
+
+const (
+	BridgeInstanceStateInitializing BridgeInstanceState = "initializing"
+	BridgeInstanceStateRunning      BridgeInstanceState = "running"
+	BridgeInstanceStateFailed       BridgeInstanceState = "failed"
+	BridgeInstanceStateStopped      BridgeInstanceState = "stopped"
+)
+
+type BridgeInstanceStatus struct {
+	ID    string              `json:"id"`
+	State BridgeInstanceState `json:"state"`
+	// the error which failed the bridge instance
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"fmt"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+)
+
+// WithdrawalHashVersion1 is the version of the withdrawal hash, the double sha3 of bridge_id || l2_sequence ||
+// sha3(sender) || sha3(receiver) || sha3(denom) || amount, which is the only version of the ophost module.
+const WithdrawalHashVersion1 uint8 = 1
+
+// WithdrawalHash computes the withdrawal hash of the version as the ophost module does, which is the leaf of the merkle tree.
+func WithdrawalHash(version uint8, bridgeId uint64, l2Sequence uint64, sender string, receiver string, denom string, amount uint64) ([32]byte, error) {
+	if version != WithdrawalHashVersion1 {
+		return [32]byte{}, fmt.Errorf("unsupported withdrawal hash version: %d", version)
+	}
+	return ophosttypes.GenerateWithdrawalHash(bridgeId, l2Sequence, sender, receiver, denom, amount), nil
+}
+
+// HashVersionOrDefault returns the withdrawal hash version of the withdrawal, which is the version 1 for the
+// withdrawals stored before the version is recorded.
+func (w WithdrawalData) HashVersionOrDefault() uint8 {
+	if w.HashVersion == 0 {
+		return WithdrawalHashVersion1
+	}
+	return w.HashVersion
+}
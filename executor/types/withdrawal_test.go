@@ -0,0 +1,74 @@
+package types
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+)
+
+func TestWithdrawalHash(t *testing.T) {
+	cases := []struct {
+		name     string
+		version  uint8
+		bridgeId uint64
+		sequence uint64
+		sender   string
+		receiver string
+		denom    string
+		amount   uint64
+		expected string
+		err      string
+	}{
+		{
+			name:     "version 1",
+			version:  WithdrawalHashVersion1,
+			bridgeId: 1,
+			sequence: 1,
+			sender:   "init1sender",
+			receiver: "init1receiver",
+			denom:    "uinit",
+			amount:   100,
+			expected: "50766ba5db8a4093f0da9b7e06986c879d395e54dc8b0d8bd00979373c7b5712",
+		},
+		{
+			name:     "version 1 of empty fields",
+			version:  WithdrawalHashVersion1,
+			expected: "cc5481fd5d34c34d34ab96e5144367658a99b178f2f7a0e6470038374de1c865",
+		},
+		{
+			name:    "unknown version",
+			version: 2,
+			err:     "unsupported withdrawal hash version: 2",
+		},
+		{
+			name:    "zero version",
+			version: 0,
+			err:     "unsupported withdrawal hash version: 0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			hash, err := WithdrawalHash(tc.version, tc.bridgeId, tc.sequence, tc.sender, tc.receiver, tc.denom, tc.amount)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hex.EncodeToString(hash[:]))
+		})
+	}
+
+	// the version 1 is the withdrawal hash of the ophost module
+	hash, err := WithdrawalHash(WithdrawalHashVersion1, 1, 1, "init1sender", "init1receiver", "uinit", 100)
+	require.NoError(t, err)
+	require.Equal(t, ophosttypes.GenerateWithdrawalHash(1, 1, "init1sender", "init1receiver", "uinit", 100), hash)
+}
+
+func TestHashVersionOrDefault(t *testing.T) {
+	require.Equal(t, WithdrawalHashVersion1, WithdrawalData{}.HashVersionOrDefault())
+	require.Equal(t, WithdrawalHashVersion1, WithdrawalData{HashVersion: WithdrawalHashVersion1}.HashVersionOrDefault())
+}
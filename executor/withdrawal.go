@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	executortypes "github.com/initia-labs/opinit-bots/executor/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// QueryWithdrawal returns the withdrawal info of the given sequence with its claimed status on l1.
+func (ex *Executor) QueryWithdrawal(ctx context.Context, sequence uint64) (executortypes.QueryWithdrawalResponse, error) {
+	withdrawal, err := ex.child.QueryWithdrawal(sequence)
+	if err != nil {
+		return executortypes.QueryWithdrawalResponse{}, err
+	}
+
+	hash, err := withdrawalHash(withdrawal)
+	if err != nil {
+		return executortypes.QueryWithdrawalResponse{}, err
+	}
+	withdrawal.Claimed, err = ex.host.QueryClaimed(ctx, withdrawal.BridgeId, hash)
+	if err != nil {
+		return executortypes.QueryWithdrawalResponse{}, err
+	}
+	return withClaimableAt(ctx, withdrawal, ex.host.OutputClaimableAt, time.Now())
+}
+
+// withClaimableAt fills the time when the withdrawal becomes claimable on l1 and whether it is claimable now.
+// It is not filled if the output of the tree is not proposed yet, or the tree is pruned after the claims.
+func withClaimableAt(
+	ctx context.Context,
+	withdrawal executortypes.QueryWithdrawalResponse,
+	outputClaimableAt func(context.Context, uint64) (time.Time, error),
+	now time.Time,
+) (executortypes.QueryWithdrawalResponse, error) {
+	if !withdrawal.Finalized || withdrawal.OutputIndex == 0 {
+		return withdrawal, nil
+	}
+
+	claimableAt, err := outputClaimableAt(ctx, withdrawal.OutputIndex)
+	if status.Code(err) == codes.NotFound || errors.Is(err, dbtypes.ErrNotFound) {
+		return withdrawal, nil
+	} else if err != nil {
+		return executortypes.QueryWithdrawalResponse{}, err
+	}
+	withdrawal.ClaimableAt = &claimableAt
+	withdrawal.IsClaimable = !withdrawal.Claimed && !now.Before(claimableAt)
+	return withdrawal, nil
+}
+
+// QueryWithdrawals returns the withdrawals of the given address with their claimed status on l1.
+func (ex *Executor) QueryWithdrawals(ctx context.Context, address string, offset uint64, limit uint64, descOrder bool) (executortypes.QueryWithdrawalsResponse, error) {
+	res, err := ex.child.QueryWithdrawals(address, offset, limit, descOrder)
+	if err != nil {
+		return executortypes.QueryWithdrawalsResponse{}, err
+	}
+	return ex.withClaimed(ctx, res)
+}
+
+// QueryBelowMinimumWithdrawals returns the withdrawals below the minimum withdrawal amount with their claimed status on l1.
+func (ex *Executor) QueryBelowMinimumWithdrawals(ctx context.Context, offset uint64, limit uint64, descOrder bool) (executortypes.QueryWithdrawalsResponse, error) {
+	res, err := ex.child.QueryBelowMinimumWithdrawals(offset, limit, descOrder)
+	if err != nil {
+		return executortypes.QueryWithdrawalsResponse{}, err
+	}
+	return ex.withClaimed(ctx, res)
+}
+
+// withClaimed fills the claimed status of the withdrawals from l1.
+func (ex *Executor) withClaimed(ctx context.Context, res executortypes.QueryWithdrawalsResponse) (executortypes.QueryWithdrawalsResponse, error) {
+	withdrawalHashes := make([][]byte, len(res.Withdrawals))
+	for i, withdrawal := range res.Withdrawals {
+		hash, err := withdrawalHash(withdrawal)
+		if err != nil {
+			return executortypes.QueryWithdrawalsResponse{}, err
+		}
+		withdrawalHashes[i] = hash
+	}
+	claimed, err := ex.host.QueryClaimedBulk(ctx, ex.host.BridgeId(), withdrawalHashes)
+	if err != nil {
+		return executortypes.QueryWithdrawalsResponse{}, err
+	}
+	for i := range res.Withdrawals {
+		res.Withdrawals[i].Claimed = claimed[i]
+	}
+	return res, nil
+}
+
+// withdrawalHash computes the withdrawal hash of the withdrawal with its hash version, which is
+// the version 1 for the responses without it.
+func withdrawalHash(withdrawal executortypes.QueryWithdrawalResponse) ([]byte, error) {
+	version := withdrawal.WithdrawalHashVersion
+	if version == 0 {
+		version = executortypes.WithdrawalHashVersion1
+	}
+	hash, err := executortypes.WithdrawalHash(
+		version,
+		withdrawal.BridgeId,
+		withdrawal.Sequence,
+		withdrawal.From,
+		withdrawal.To,
+		withdrawal.Amount.Denom,
+		withdrawal.Amount.Amount.Uint64(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return hash[:], nil
+}
+
+// ClaimWithdrawal broadcasts the msg finalizing the withdrawal of the given sequence on l1,
+// signed by the withdrawal claimer account of the executor.
+func (ex *Executor) ClaimWithdrawal(ctx context.Context, sequence uint64) (executortypes.ClaimWithdrawalResponse, error) {
+	if ex.cfg.WithdrawalClaimer == "" {
+		return executortypes.ClaimWithdrawalResponse{}, errors.New("withdrawal claimer key is not set")
+	}
+
+	withdrawal, err := ex.child.QueryWithdrawal(sequence)
+	if err != nil {
+		return executortypes.ClaimWithdrawalResponse{}, err
+	} else if withdrawal.OutputIndex == 0 {
+		return executortypes.ClaimWithdrawalResponse{}, errors.New("withdrawal is not finalized yet")
+	}
+
+	// not to pay the fees for the failing tx
+	hash, err := withdrawalHash(withdrawal)
+	if err != nil {
+		return executortypes.ClaimWithdrawalResponse{}, err
+	}
+	claimed, err := ex.host.QueryClaimed(ctx, withdrawal.BridgeId, hash)
+	if err != nil {
+		return executortypes.ClaimWithdrawalResponse{}, err
+	} else if claimed {
+		return executortypes.ClaimWithdrawalResponse{}, errors.New("withdrawal is already claimed")
+	}
+
+	msg, sender, err := ex.host.GetMsgFinalizeTokenWithdrawal(
+		withdrawal.BridgeId,
+		withdrawal.OutputIndex,
+		withdrawal.Sequence,
+		withdrawal.From,
+		withdrawal.To,
+		withdrawal.Amount,
+		withdrawal.WithdrawalProofs,
+		withdrawal.Version,
+		withdrawal.StorageRoot,
+		withdrawal.LastBlockHash,
+	)
+	if err != nil {
+		return executortypes.ClaimWithdrawalResponse{}, err
+	} else if msg == nil {
+		return executortypes.ClaimWithdrawalResponse{}, errors.New("host key is not set")
+	}
+
+	processedMsgs := btypes.ProcessedMsgs{
+		Sender:    sender,
+		Msgs:      []sdk.Msg{msg},
+		Timestamp: time.Now().UnixNano(),
+		Save:      false,
+	}.WithTraceID()
+	err = ex.host.BroadcastMsgs(processedMsgs)
+	if err != nil {
+		return executortypes.ClaimWithdrawalResponse{}, err
+	}
+
+	return executortypes.ClaimWithdrawalResponse{
+		Sequence: sequence,
+		Sender:   sender,
+		TraceID:  processedMsgs.TraceID,
+	}, nil
+}
@@ -1,6 +1,11 @@
 package keys
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
 	"cosmossdk.io/x/tx/signing"
 	"github.com/cosmos/gogoproto/proto"
 
@@ -11,6 +16,16 @@ import (
 	"github.com/cosmos/cosmos-sdk/std"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibctm "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+
+	movetypes "github.com/initia-labs/opinit-bots/types/move"
+	wasmtypes "github.com/initia-labs/opinit-bots/types/wasm"
 )
 
 type RegisterInterfaces func(registry codectypes.InterfaceRegistry)
@@ -35,3 +50,83 @@ func CreateCodec(registerFns []RegisterInterfaces) (codec.Codec, client.TxConfig
 	txConfig := tx.NewTxConfig(appCodec, tx.DefaultSignModes)
 	return appCodec, txConfig, nil
 }
+
+const (
+	CodecModuleBank = "bank"
+	CodecModuleIBC  = "ibc"
+	CodecModuleWasm = "wasm"
+	CodecModuleMove = "move"
+)
+
+// codecModules are the module sets whose interfaces can be registered to the codec in addition to
+// the interfaces of the bot, so the txs of the other modules can be decoded.
+var codecModules = map[string][]RegisterInterfaces{
+	CodecModuleBank: {banktypes.RegisterInterfaces},
+	CodecModuleIBC: {
+		transfertypes.RegisterInterfaces,
+		clienttypes.RegisterInterfaces,
+		connectiontypes.RegisterInterfaces,
+		channeltypes.RegisterInterfaces,
+		ibctm.RegisterInterfaces,
+	},
+	CodecModuleWasm: {wasmtypes.RegisterInterfaces},
+	CodecModuleMove: {movetypes.RegisterInterfaces},
+}
+
+// ValidateCodecModules validates the names of the module sets registered to the codec.
+func ValidateCodecModules(modules []string) error {
+	for _, module := range modules {
+		if _, ok := codecModules[module]; !ok {
+			supported := make([]string, 0, len(codecModules))
+			for name := range codecModules {
+				supported = append(supported, name)
+			}
+			slices.Sort(supported)
+			return fmt.Errorf("unsupported codec module: %s, must be one of %s", module, strings.Join(supported, ", "))
+		}
+	}
+	return nil
+}
+
+type cachedCodec struct {
+	cdc      codec.Codec
+	txConfig client.TxConfig
+}
+
+var (
+	codecCacheMu sync.Mutex
+	codecCache   = make(map[string]cachedCodec)
+)
+
+// GetCachedCodec returns the codec of the bech32 prefix with the given interfaces and the interfaces of
+// the module sets. The codec is created once per name, bech32 prefix and module sets, and shared by the
+// components, as it is not changed after the creation.
+func GetCachedCodec(name string, bech32Prefix string, registerFns []RegisterInterfaces, modules []string) (codec.Codec, client.TxConfig, error) {
+	if err := ValidateCodecModules(modules); err != nil {
+		return nil, nil, err
+	}
+	modules = slices.Clone(modules)
+	slices.Sort(modules)
+	modules = slices.Compact(modules)
+	key := fmt.Sprintf("%s/%s/%s", name, bech32Prefix, strings.Join(modules, ","))
+
+	codecCacheMu.Lock()
+	defer codecCacheMu.Unlock()
+	if cached, ok := codecCache[key]; ok {
+		return cached.cdc, cached.txConfig, nil
+	}
+
+	registerFns = slices.Clone(registerFns)
+	for _, module := range modules {
+		registerFns = append(registerFns, codecModules[module]...)
+	}
+
+	unlock := SetSDKConfigContext(bech32Prefix)
+	defer unlock()
+	cdc, txConfig, err := CreateCodec(registerFns)
+	if err != nil {
+		return nil, nil, err
+	}
+	codecCache[key] = cachedCodec{cdc: cdc, txConfig: txConfig}
+	return cdc, txConfig, nil
+}
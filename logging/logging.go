@@ -0,0 +1,227 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+type Config struct {
+	// Format is the format of the logs, "console" or "json". If it is empty, "console" is used.
+	Format string `json:"format"`
+	// Level is the default level of the logs. If it is empty, "info" is used.
+	// The --log-level flag overrides it.
+	Level string `json:"level"`
+	// Levels overrides the levels of the components, e.g. "host", "child", "batch", "node.broadcaster" or "merkle".
+	// A component matches the loggers whose names contain it, and the longest matching component is used.
+	Levels map[string]string `json:"levels"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Format: FormatConsole,
+		Level:  "info",
+		Levels: map[string]string{},
+	}
+}
+
+func (c Config) Validate() error {
+	switch c.Format {
+	case "", FormatConsole, FormatJSON:
+	default:
+		return fmt.Errorf("invalid log format: %s", c.Format)
+	}
+	if _, err := ParseLevel(c.Level); err != nil {
+		return err
+	}
+	for component, level := range c.Levels {
+		if component == "" {
+			return errors.New("log level must have a component")
+		}
+		if _, err := ParseLevel(level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseLevel parses the level of the logs, where the empty level is "info".
+func ParseLevel(level string) (zapcore.Level, error) {
+	if level == "" {
+		return zapcore.InfoLevel, nil
+	}
+	return zapcore.ParseLevel(level)
+}
+
+// NewLogger builds the root logger of the config, and the levels to change the levels of the components at runtime.
+func NewLogger(cfg Config) (*zap.Logger, *Levels, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	levels, err := NewLevels(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var config zap.Config
+	if cfg.Format == FormatJSON {
+		config = zap.NewProductionConfig()
+		config.Sampling = nil
+	} else {
+		config = zap.NewDevelopmentConfig()
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	// the levels are filtered by the components
+	config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+
+	logger, err := config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, levels: levels}
+	}))
+	if err != nil {
+		return nil, nil, err
+	}
+	return logger, levels, nil
+}
+
+// Levels is the levels of the components, each controlled by an atomic level.
+type Levels struct {
+	mu         sync.RWMutex
+	defaultLvl zap.AtomicLevel
+	components map[string]zap.AtomicLevel
+	// cache of the logger name to the level of the longest matching component
+	cache map[string]zap.AtomicLevel
+}
+
+func NewLevels(cfg Config) (*Levels, error) {
+	defaultLevel, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Levels{
+		defaultLvl: zap.NewAtomicLevelAt(defaultLevel),
+		components: make(map[string]zap.AtomicLevel),
+		cache:      make(map[string]zap.AtomicLevel),
+	}
+	for component, level := range cfg.Levels {
+		if err := l.SetLevel(component, level); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// SetLevel sets the level of the component. The empty component is the default level.
+func (l *Levels) SetLevel(component string, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if component == "" {
+		l.defaultLvl.SetLevel(lvl)
+		return nil
+	}
+	if atomicLevel, ok := l.components[component]; ok {
+		atomicLevel.SetLevel(lvl)
+		return nil
+	}
+	l.components[component] = zap.NewAtomicLevelAt(lvl)
+	// the new component may match the cached loggers
+	l.cache = make(map[string]zap.AtomicLevel)
+	return nil
+}
+
+// All returns the levels of the components, with the default level at the empty component.
+func (l *Levels) All() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	levels := make(map[string]string, len(l.components)+1)
+	levels[""] = l.defaultLvl.String()
+	for component, atomicLevel := range l.components {
+		levels[component] = atomicLevel.String()
+	}
+	return levels
+}
+
+// Level returns the atomic level of the logger name, from the longest component matching the name.
+func (l *Levels) Level(loggerName string) zap.AtomicLevel {
+	l.mu.RLock()
+	level, ok := l.cache[loggerName]
+	l.mu.RUnlock()
+	if ok {
+		return level
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	level = l.defaultLvl
+	matched := ""
+	for component, atomicLevel := range l.components {
+		if len(component) > len(matched) && matchComponent(loggerName, component) {
+			matched = component
+			level = atomicLevel
+		}
+	}
+	l.cache[loggerName] = level
+	return level
+}
+
+// enabled returns whether the level is enabled by any component.
+func (l *Levels) enabled(lvl zapcore.Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.defaultLvl.Enabled(lvl) {
+		return true
+	}
+	for _, atomicLevel := range l.components {
+		if atomicLevel.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchComponent returns whether the dot separated segments of the component are in the logger name,
+// e.g. "node.broadcaster" matches "executor.host.node.broadcaster", but "host" doesn't match "da_host".
+func matchComponent(loggerName string, component string) bool {
+	name := "." + loggerName + "."
+	return strings.Contains(name, "."+component+".")
+}
+
+// levelCore filters the entries by the level of their logger names.
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.enabled(lvl)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.Level(ent.LoggerName).Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestLogger(t *testing.T, cfg Config) (*zap.Logger, *Levels, *observer.ObservedLogs) {
+	levels, err := NewLevels(cfg)
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(&levelCore{Core: core, levels: levels}), levels, logs
+}
+
+// messages returns the messages of the observed logs and resets them.
+func messages(logs *observer.ObservedLogs) []string {
+	msgs := make([]string, 0)
+	for _, entry := range logs.TakeAll() {
+		msgs = append(msgs, entry.LoggerName+": "+entry.Message)
+	}
+	return msgs
+}
+
+func Test_ComponentLevels(t *testing.T) {
+	logger, _, logs := newTestLogger(t, Config{
+		Level:  "info",
+		Levels: map[string]string{"batch": "debug", "node.broadcaster": "error"},
+	})
+	executor := logger.Named("executor")
+	host := executor.Named("host")
+	batch := executor.Named("batch")
+	broadcaster := host.Named("node.broadcaster")
+
+	host.Debug("host debug")
+	host.Info("host info")
+	batch.Debug("batch debug")
+	broadcaster.Warn("broadcaster warn")
+	broadcaster.Error("broadcaster error")
+	require.Equal(t, []string{
+		"executor.host: host info",
+		"executor.batch: batch debug",
+		"executor.host.node.broadcaster: broadcaster error",
+	}, messages(logs))
+}
+
+func Test_SetLevelAtRuntime(t *testing.T) {
+	logger, levels, logs := newTestLogger(t, Config{})
+	host := logger.Named("executor").Named("host")
+	child := logger.Named("executor").Named("child").With(zap.String("chain_id", "l2"))
+
+	host.Debug("host debug")
+	child.Debug("child debug")
+	require.Empty(t, messages(logs))
+
+	// the existing loggers follow the new component level
+	require.NoError(t, levels.SetLevel("child", "debug"))
+	host.Debug("host debug")
+	child.Debug("child debug")
+	require.Equal(t, []string{"executor.child: child debug"}, messages(logs))
+
+	require.NoError(t, levels.SetLevel("child", "warn"))
+	child.Info("child info")
+	require.Empty(t, messages(logs))
+
+	// the default level
+	require.NoError(t, levels.SetLevel("", "debug"))
+	host.Debug("host debug")
+	child.Info("child info")
+	require.Equal(t, []string{"executor.host: host debug"}, messages(logs))
+
+	require.Error(t, levels.SetLevel("host", "verbose"))
+	require.Equal(t, map[string]string{"": "debug", "child": "warn"}, levels.All())
+}
+
+func Test_LongestComponentMatch(t *testing.T) {
+	_, levels, _ := newTestLogger(t, Config{
+		Level:  "warn",
+		Levels: map[string]string{"host": "debug", "host.node.broadcaster": "error"},
+	})
+
+	require.Equal(t, zapcore.DebugLevel, levels.Level("executor.host").Level())
+	require.Equal(t, zapcore.ErrorLevel, levels.Level("executor.host.node.broadcaster").Level())
+	require.Equal(t, zapcore.WarnLevel, levels.Level("executor.child.node.broadcaster").Level())
+	// the component matches the whole segments of the logger name
+	require.Equal(t, zapcore.WarnLevel, levels.Level("executor.batch.da_host").Level())
+}
+
+func Test_ConfigValidate(t *testing.T) {
+	require.NoError(t, Config{}.Validate())
+	require.NoError(t, DefaultConfig().Validate())
+	require.NoError(t, Config{Format: FormatJSON, Level: "debug", Levels: map[string]string{"merkle": "warn"}}.Validate())
+	require.Error(t, Config{Format: "text"}.Validate())
+	require.Error(t, Config{Level: "verbose"}.Validate())
+	require.Error(t, Config{Levels: map[string]string{"": "info"}}.Validate())
+	require.Error(t, Config{Levels: map[string]string{"host": "verbose"}}.Validate())
+}
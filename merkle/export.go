@@ -0,0 +1,60 @@
+package merkle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+)
+
+// IterateNodes iterates all the stored nodes of the tree, sorted by the height and then by the local index.
+func (m *Merkle) IterateNodes(treeIndex uint64, cb func(height uint8, localIndex uint64, hash []byte) (stop bool, err error)) error {
+	return m.db.PrefixedIterate(merkletypes.PrefixedTreeNodesKey(treeIndex), nil, func(key, value []byte) (bool, error) {
+		nodeTreeIndex, height, localIndex, err := merkletypes.ParsePrefixedNodeKey(key)
+		if err != nil {
+			return true, err
+		} else if nodeTreeIndex != treeIndex {
+			return true, fmt.Errorf("unexpected tree index of the node; expected: %d, got: %d", treeIndex, nodeTreeIndex)
+		}
+		return cb(height, localIndex, value)
+	})
+}
+
+// ExportTree writes the finalized tree with all of its nodes to the writer in json.
+func (m *Merkle) ExportTree(treeIndex uint64, w io.Writer) error {
+	var treeInfo *merkletypes.FinalizedTreeInfo
+	err := m.ReverseIterateFinalizedTrees(func(tree merkletypes.FinalizedTreeInfo) (bool, error) {
+		if tree.TreeIndex == treeIndex {
+			treeInfo = &tree
+		}
+		return tree.TreeIndex <= treeIndex, nil
+	})
+	if err != nil {
+		return err
+	} else if treeInfo == nil {
+		return fmt.Errorf("%w: %d", merkletypes.ErrUnfinalizedTree, treeIndex)
+	}
+
+	export := merkletypes.TreeExport{
+		TreeIndex:      treeInfo.TreeIndex,
+		TreeHeight:     treeInfo.TreeHeight,
+		Root:           treeInfo.Root,
+		StartLeafIndex: treeInfo.StartLeafIndex,
+		LeafCount:      treeInfo.LeafCount,
+		Nodes:          make([]merkletypes.NodeExport, 0),
+	}
+	err = m.IterateNodes(treeIndex, func(height uint8, localIndex uint64, hash []byte) (bool, error) {
+		export.Nodes = append(export.Nodes, merkletypes.NodeExport{Height: height, LocalIndex: localIndex, Hash: hash})
+		return false, nil
+	})
+	if err != nil {
+		return err
+	} else if len(export.Nodes) == 0 && treeInfo.LeafCount != 0 {
+		return fmt.Errorf("%w: %d", merkletypes.ErrPrunedTree, treeIndex)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
@@ -0,0 +1,50 @@
+package merkle
+
+import (
+	"fmt"
+
+	"github.com/initia-labs/opinit-bots/db/integrity"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// sampleSize is the number of the last working trees and tree nodes decoded by the integrity check,
+// as a merkle db can have millions of them, e.g. a working tree for every l2 height.
+const sampleSize = 10_000
+
+// IntegrityChecks returns the integrity checks of the sampled working trees, the finalized trees and the sampled nodes of the merkle db.
+func IntegrityChecks(db types.DB) []integrity.Check {
+	return []integrity.Check{
+		{
+			Name:   "merkle/working_trees",
+			DB:     db,
+			Prefix: merkletypes.WorkingTreeKey,
+			Decode: func(_, value []byte) error {
+				var treeInfo merkletypes.TreeInfo
+				return treeInfo.Unmarshal(value)
+			},
+			SampleSize: sampleSize,
+		},
+		{
+			Name:   "merkle/finalized_trees",
+			DB:     db,
+			Prefix: merkletypes.FinalizedTreeKey,
+			Decode: func(_, value []byte) error {
+				var treeInfo merkletypes.FinalizedTreeInfo
+				return treeInfo.Unmarshal(value)
+			},
+		},
+		{
+			Name:   "merkle/tree_nodes",
+			DB:     db,
+			Prefix: merkletypes.NodeKey,
+			Decode: func(_, value []byte) error {
+				if len(value) != len(merkletypes.EmptyRootHash) {
+					return fmt.Errorf("invalid node length: %d", len(value))
+				}
+				return nil
+			},
+			SampleSize: sampleSize,
+		},
+	}
+}
@@ -2,11 +2,12 @@ package merkle
 
 import (
 	"crypto/rand"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math/bits"
 
+	"go.uber.org/zap"
+
 	dbtypes "github.com/initia-labs/opinit-bots/db/types"
 	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
 	types "github.com/initia-labs/opinit-bots/types"
@@ -21,8 +22,13 @@ type NodeGeneratorFn func([]byte, []byte) [32]byte
 // of each level(height) to minimize the memory usage.
 type Merkle struct {
 	db              types.DB
+	logger          *zap.Logger
 	workingTree     *merkletypes.TreeInfo
 	nodeGeneratorFn NodeGeneratorFn
+
+	// minimum indices accepted by InitializeWorkingTree
+	minTreeIndex      uint64
+	minStartLeafIndex uint64
 }
 
 // Check if the node generator function is commutative
@@ -48,7 +54,7 @@ func validateNodeGeneratorFn(fn NodeGeneratorFn) error {
 	return nil
 }
 
-func NewMerkle(db types.DB, nodeGeneratorFn NodeGeneratorFn) (*Merkle, error) {
+func NewMerkle(db types.DB, logger *zap.Logger, nodeGeneratorFn NodeGeneratorFn) (*Merkle, error) {
 	err := validateNodeGeneratorFn(nodeGeneratorFn)
 	if err != nil {
 		return nil, err
@@ -56,13 +62,33 @@ func NewMerkle(db types.DB, nodeGeneratorFn NodeGeneratorFn) (*Merkle, error) {
 
 	return &Merkle{
 		db:              db,
+		logger:          logger,
 		nodeGeneratorFn: nodeGeneratorFn,
+
+		minTreeIndex:      merkletypes.DefaultMinTreeIndex,
+		minStartLeafIndex: merkletypes.DefaultMinStartLeafIndex,
 	}, nil
 }
 
+// Logger returns the logger of the merkle tree.
+func (m *Merkle) Logger() *zap.Logger {
+	return m.logger
+}
+
+// SetMinIndices sets the minimum tree index and start leaf index of the working trees, which are 1 by default.
+// It allows the integrations whose first output or withdrawal has the index 0 to use the merkle tree.
+//
+// CONTRACT: The tree indices and the start leaf indices must still increase monotonically, as the finalized
+// trees are looked up by the start leaf index and the nodes are stored by the tree index.
+func (m *Merkle) SetMinIndices(minTreeIndex uint64, minStartLeafIndex uint64) {
+	m.minTreeIndex = minTreeIndex
+	m.minStartLeafIndex = minStartLeafIndex
+}
+
 // InitializeWorkingTree resets the working tree with the given tree index and start leaf index.
+// The indices must not be less than the minimum indices set by SetMinIndices.
 func (m *Merkle) InitializeWorkingTree(treeIndex uint64, startLeafIndex uint64) error {
-	if treeIndex < 1 || startLeafIndex < 1 {
+	if treeIndex < m.minTreeIndex || startLeafIndex < m.minStartLeafIndex {
 		return fmt.Errorf("failed to initialize working tree index: %d, leaf: %d; invalid index", treeIndex, startLeafIndex)
 	}
 
@@ -79,6 +105,15 @@ func (m *Merkle) InitializeWorkingTree(treeIndex uint64, startLeafIndex uint64)
 
 // FinalizeWorkingTree finalizes the working tree and returns the finalized tree info.
 func (m *Merkle) FinalizeWorkingTree(extraData []byte) ([]types.RawKV, []byte /* root */, error) {
+	return m.FinalizeWorkingTreeFn(func([]byte) ([]byte, error) {
+		return extraData, nil
+	})
+}
+
+// FinalizeWorkingTreeFn finalizes the working tree with the extra data built from the root of the tree,
+// such as the output root committing to the root. The extra data is not built for the empty tree,
+// which is not stored.
+func (m *Merkle) FinalizeWorkingTreeFn(extraDataFn func(root []byte) ([]byte, error)) ([]types.RawKV, []byte /* root */, error) {
 	if m.workingTree == nil {
 		return nil, nil, errors.New("working tree is not initialized")
 	}
@@ -98,6 +133,11 @@ func (m *Merkle) FinalizeWorkingTree(extraData []byte) ([]types.RawKV, []byte /*
 	}
 
 	treeRootHash := m.workingTree.LastSiblings[height]
+	extraData, err := extraDataFn(treeRootHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	finalizedTreeInfo := merkletypes.FinalizedTreeInfo{
 		TreeIndex:      m.workingTree.Index,
 		TreeHeight:     height,
@@ -107,7 +147,7 @@ func (m *Merkle) FinalizeWorkingTree(extraData []byte) ([]types.RawKV, []byte /*
 		ExtraData:      extraData,
 	}
 
-	data, err := json.Marshal(finalizedTreeInfo)
+	data, err := finalizedTreeInfo.Marshal()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -122,30 +162,58 @@ func (m *Merkle) FinalizeWorkingTree(extraData []byte) ([]types.RawKV, []byte /*
 	return kvs, treeRootHash, err
 }
 
+// DeleteFutureFinalizedTrees deletes the finalized trees which start from the given sequence or later.
 func (m *Merkle) DeleteFutureFinalizedTrees(fromSequence uint64) error {
-	return m.db.PrefixedIterate(merkletypes.FinalizedTreeKey, nil, func(key, _ []byte) (bool, error) {
-		sequence := dbtypes.ToUint64Key(key[len(key)-8:])
-		if sequence >= fromSequence {
-			err := m.db.Delete(key)
-			if err != nil {
-				return true, err
-			}
+	_, err := m.db.PrefixedDeleteRange(merkletypes.FinalizedTreeKey, merkletypes.PrefixedFinalizedTreeKey(fromSequence), nil)
+	return err
+}
+
+// TreeIndexOfLeaf returns the index of the finalized tree which has the leaf. If the leaf is not finalized yet,
+// it returns the index of the tree after the last finalized tree. It returns 0 if no tree is finalized before the leaf,
+// which is also the index of the first tree if the minimum tree index is 0.
+func (m *Merkle) TreeIndexOfLeaf(leafIndex uint64) (uint64, error) {
+	_, value, err := m.db.SeekPrevInclusiveKey(merkletypes.FinalizedTreeKey, merkletypes.PrefixedFinalizedTreeKey(leafIndex))
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	var treeInfo merkletypes.FinalizedTreeInfo
+	if err := treeInfo.Unmarshal(value); err != nil {
+		return 0, err
+	}
+	if leafIndex-treeInfo.StartLeafIndex < treeInfo.LeafCount {
+		return treeInfo.TreeIndex, nil
+	}
+	return treeInfo.TreeIndex + 1, nil
+}
+
+// DeleteTreeNodes deletes the nodes of the trees in [fromTreeIndex, toTreeIndex) and returns the number of
+// the deleted nodes. The finalized tree infos are kept, so the proofs of the trees fail with ErrPrunedTree.
+func (m *Merkle) DeleteTreeNodes(fromTreeIndex uint64, toTreeIndex uint64) (int, error) {
+	return m.db.PrefixedDeleteRange(
+		merkletypes.NodeKey,
+		merkletypes.PrefixedNodeKey(fromTreeIndex, 0, 0),
+		merkletypes.PrefixedNodeKey(toTreeIndex, 0, 0),
+	)
+}
+
+// ReverseIterateFinalizedTrees iterates the finalized trees from the last one.
+func (m *Merkle) ReverseIterateFinalizedTrees(fn func(merkletypes.FinalizedTreeInfo) (bool, error)) error {
+	return m.db.PrefixedReverseIterate(merkletypes.FinalizedTreeKey, nil, func(_, value []byte) (bool, error) {
+		var treeInfo merkletypes.FinalizedTreeInfo
+		if err := treeInfo.Unmarshal(value); err != nil {
+			return true, err
 		}
-		return false, nil
+		return fn(treeInfo)
 	})
 }
 
+// DeleteFutureWorkingTrees deletes the working trees of the given version or later.
 func (m *Merkle) DeleteFutureWorkingTrees(fromVersion uint64) error {
-	return m.db.PrefixedIterate(merkletypes.WorkingTreeKey, nil, func(key, _ []byte) (bool, error) {
-		version := dbtypes.ToUint64Key(key[len(key)-8:])
-		if version >= fromVersion {
-			err := m.db.Delete(key)
-			if err != nil {
-				return true, err
-			}
-		}
-		return false, nil
-	})
+	_, err := m.db.PrefixedDeleteRange(merkletypes.WorkingTreeKey, merkletypes.PrefixedWorkingTreeKey(fromVersion), nil)
+	return err
 }
 
 // LoadWorkingTree loads the working tree from the database.
@@ -158,7 +226,7 @@ func (m *Merkle) LoadWorkingTree(version uint64) error {
 	}
 
 	var workingTree merkletypes.TreeInfo
-	err = json.Unmarshal(data, &workingTree)
+	err = workingTree.Unmarshal(data)
 	m.workingTree = &workingTree
 	if err != nil {
 		return err
@@ -174,15 +242,27 @@ func (m *Merkle) LoadWorkingTree(version uint64) error {
 //
 // It is used to save the working tree to handle the case where the bot is stopped.
 func (m *Merkle) SaveWorkingTree(version uint64) error {
+	kv, err := m.WorkingTreeToRawKV(version)
+	if err != nil {
+		return err
+	}
+	return m.db.RawBatchSet(kv)
+}
+
+// WorkingTreeToRawKV returns the raw kv of the working tree, to save it atomically with the other kvs.
+func (m *Merkle) WorkingTreeToRawKV(version uint64) (types.RawKV, error) {
 	if m.workingTree == nil {
-		return errors.New("working tree is not initialized")
+		return types.RawKV{}, errors.New("working tree is not initialized")
 	}
 
-	data, err := json.Marshal(&m.workingTree)
+	data, err := m.workingTree.Marshal()
 	if err != nil {
-		return err
+		return types.RawKV{}, err
 	}
-	return m.db.Set(merkletypes.PrefixedWorkingTreeKey(version), data)
+	return types.RawKV{
+		Key:   m.db.PrefixedKey(merkletypes.PrefixedWorkingTreeKey(version)),
+		Value: data,
+	}, nil
 }
 
 // Height returns the height of the working tree.
@@ -214,6 +294,14 @@ func (m *Merkle) GetWorkingTreeLeafCount() (uint64, error) {
 	return m.workingTree.LeafCount, nil
 }
 
+// IsWorkingTreeDone returns true if the working tree is finalized.
+func (m *Merkle) IsWorkingTreeDone() (bool, error) {
+	if m.workingTree == nil {
+		return false, errors.New("working tree is not initialized")
+	}
+	return m.workingTree.Done, nil
+}
+
 // GetStartLeafIndex returns the start leaf index of the working tree.
 func (m *Merkle) GetStartLeafIndex() (uint64, error) {
 	if m.workingTree == nil {
@@ -250,7 +338,7 @@ func (m *Merkle) fillLeaves() error {
 
 	lastLeaf := m.workingTree.LastSiblings[0]
 	for range numRestLeaves {
-		if err := m.InsertLeaf(lastLeaf); err != nil {
+		if err := m.insertLeaf(lastLeaf); err != nil {
 			return err
 		}
 	}
@@ -264,8 +352,20 @@ func (m *Merkle) fillLeaves() error {
 
 // InsertLeaf inserts a leaf to the working tree.
 //
-// It updates the last sibling of each level until the root.
+// It updates the last sibling of each level until the root. It fails with ErrTreeFinalized
+// if the working tree is finalized, as the root of the tree is already committed.
 func (m *Merkle) InsertLeaf(data []byte) error {
+	if m.workingTree == nil {
+		return errors.New("working tree is not initialized")
+	} else if m.workingTree.Done {
+		return fmt.Errorf("%w: %d", merkletypes.ErrTreeFinalized, m.workingTree.Index)
+	}
+	return m.insertLeaf(data)
+}
+
+// insertLeaf inserts a leaf to the working tree without checking whether the tree is finalized,
+// so the finalization can fill the rest of the leaves.
+func (m *Merkle) insertLeaf(data []byte) error {
 	if m.workingTree == nil {
 		return errors.New("working tree is not initialized")
 	}
@@ -307,7 +407,7 @@ func (m *Merkle) GetProofs(leafIndex uint64) (proofs [][]byte, treeIndex uint64,
 	}
 
 	var treeInfo merkletypes.FinalizedTreeInfo
-	if err := json.Unmarshal(value, &treeInfo); err != nil {
+	if err := treeInfo.Unmarshal(value); err != nil {
 		return nil, 0, nil, nil, err
 	}
 
@@ -323,7 +423,9 @@ func (m *Merkle) GetProofs(leafIndex uint64) (proofs [][]byte, treeIndex uint64,
 	for height < treeInfo.TreeHeight {
 		siblingIndex := localNodeIndex ^ 1 // flip the last bit to find the sibling
 		sibling, err := m.getNode(treeInfo.TreeIndex, height, siblingIndex)
-		if err != nil {
+		if errors.Is(err, dbtypes.ErrNotFound) {
+			return nil, 0, nil, nil, fmt.Errorf("%w: %d", merkletypes.ErrPrunedTree, treeInfo.TreeIndex)
+		} else if err != nil {
 			return nil, 0, nil, nil, err
 		}
 
@@ -1,9 +1,12 @@
 package merkle
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"testing"
 
+	"go.uber.org/zap"
 	"golang.org/x/crypto/sha3"
 
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
@@ -28,7 +31,7 @@ func Test_MerkleTree_LastSibling(t *testing.T) {
 	require.NoError(t, err)
 
 	hashFn := ophosttypes.GenerateNodeHash
-	m, err := NewMerkle(db, hashFn)
+	m, err := NewMerkle(db, zap.NewNop(), hashFn)
 	require.NoError(t, err)
 
 	require.NoError(t, m.InitializeWorkingTree(1, 1))
@@ -85,7 +88,7 @@ func Test_FinalizeWorkingTree(t *testing.T) {
 	require.NoError(t, err)
 
 	hashFn := ophosttypes.GenerateNodeHash
-	m, err := NewMerkle(db, hashFn)
+	m, err := NewMerkle(db, zap.NewNop(), hashFn)
 	require.NoError(t, err)
 
 	require.NoError(t, m.InitializeWorkingTree(1, 1))
@@ -96,6 +99,10 @@ func Test_FinalizeWorkingTree(t *testing.T) {
 	require.Len(t, kvs, 0)
 	require.Equal(t, merkletypes.EmptyRootHash[:], root)
 
+	// the finalized tree doesn't accept the leaves
+	require.ErrorIs(t, m.InsertLeaf([]byte("node1")), merkletypes.ErrTreeFinalized)
+	require.NoError(t, m.InitializeWorkingTree(1, 1))
+
 	// insert 6 nodes
 	require.NoError(t, m.InsertLeaf([]byte("node1")))
 	require.NoError(t, m.InsertLeaf([]byte("node2")))
@@ -119,7 +126,7 @@ func Test_FinalizeWorkingTree(t *testing.T) {
 	require.Len(t, kvs, 1)
 
 	var info merkletypes.FinalizedTreeInfo
-	require.NoError(t, json.Unmarshal(kvs[0].Value, &info))
+	require.NoError(t, info.Unmarshal(kvs[0].Value))
 	require.Equal(t, merkletypes.FinalizedTreeInfo{
 		TreeIndex:      1,
 		TreeHeight:     3,
@@ -130,13 +137,49 @@ func Test_FinalizeWorkingTree(t *testing.T) {
 	}, info)
 }
 
+func Test_InsertLeafToFinalizedTree(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+
+	m, err := NewMerkle(db, zap.NewNop(), ophosttypes.GenerateNodeHash)
+	require.NoError(t, err)
+
+	require.NoError(t, m.InitializeWorkingTree(1, 1))
+	require.NoError(t, m.InsertLeaf([]byte("node1")))
+	require.NoError(t, m.InsertLeaf([]byte("node2")))
+	require.NoError(t, m.InsertLeaf([]byte("node3")))
+	_, root, err := m.FinalizeWorkingTree(nil)
+	require.NoError(t, err)
+	require.NoError(t, m.SaveWorkingTree(1))
+
+	// the finalized tree is left untouched
+	lastSiblings := make(map[uint8][]byte)
+	for height, sibling := range m.workingTree.LastSiblings {
+		lastSiblings[height] = sibling
+	}
+	require.ErrorIs(t, m.InsertLeaf([]byte("node4")), merkletypes.ErrTreeFinalized)
+	require.Equal(t, lastSiblings, m.workingTree.LastSiblings)
+	require.Equal(t, uint64(3), m.workingTree.LeafCount)
+	require.Equal(t, root, m.workingTree.LastSiblings[2])
+
+	// the loaded finalized tree is replaced with the next tree
+	require.NoError(t, m.LoadWorkingTree(1))
+	require.NoError(t, m.InsertLeaf([]byte("node4")))
+	index, err := m.GetWorkingTreeIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), index)
+	startLeafIndex, err := m.GetStartLeafIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), startLeafIndex)
+}
+
 func Test_GetProofs(t *testing.T) {
 	tempDir := t.TempDir()
 	db, err := db.NewDB(tempDir)
 	require.NoError(t, err)
 
 	hashFn := ophosttypes.GenerateNodeHash
-	m, err := NewMerkle(db, hashFn)
+	m, err := NewMerkle(db, zap.NewNop(), hashFn)
 	require.NoError(t, err)
 
 	require.NoError(t, m.InitializeWorkingTree(1, 1))
@@ -175,3 +218,193 @@ func Test_GetProofs(t *testing.T) {
 	require.Equal(t, hash34[:], proofs[1])
 	require.Equal(t, hash5666[:], proofs[2])
 }
+
+func Test_MinIndices(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+
+	hashFn := ophosttypes.GenerateNodeHash
+	m, err := NewMerkle(db, zap.NewNop(), hashFn)
+	require.NoError(t, err)
+
+	// the indices start from 1 by default
+	require.Error(t, m.InitializeWorkingTree(0, 1))
+	require.Error(t, m.InitializeWorkingTree(1, 0))
+	require.NoError(t, m.InitializeWorkingTree(1, 1))
+
+	// the tree 0 starts from the leaf 0
+	m.SetMinIndices(0, 0)
+	require.NoError(t, m.InitializeWorkingTree(0, 0))
+	require.NoError(t, m.InsertLeaf([]byte("node0")))
+	require.NoError(t, m.InsertLeaf([]byte("node1")))
+	require.NoError(t, m.InsertLeaf([]byte("node2")))
+	kvs, root, err := m.FinalizeWorkingTree([]byte("extra data"))
+	require.NoError(t, err)
+	require.NoError(t, db.RawBatchSet(kvs...))
+	require.NoError(t, m.SaveWorkingTree(1))
+
+	hash01 := hashFn([]byte("node0"), []byte("node1"))
+	hash22 := hashFn([]byte("node2"), []byte("node2"))
+	hashRoot := hashFn(hash01[:], hash22[:])
+	require.Equal(t, hashRoot[:], root)
+
+	// the proofs of the leaf 0 in the tree 0
+	proofs, treeIndex, root_, extraData, err := m.GetProofs(0)
+	require.NoError(t, err)
+	require.Zero(t, treeIndex)
+	require.Equal(t, root, root_)
+	require.Equal(t, []byte("extra data"), extraData)
+	require.Equal(t, [][]byte{[]byte("node1"), hash22[:]}, proofs)
+
+	// the tree 1 follows the tree 0 from the leaf 3
+	require.NoError(t, m.LoadWorkingTree(1))
+	workingTreeIndex, err := m.GetWorkingTreeIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), workingTreeIndex)
+	startLeafIndex, err := m.GetStartLeafIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), startLeafIndex)
+	require.NoError(t, m.InsertLeaf([]byte("node3")))
+
+	for leafIndex, treeIndex := range map[uint64]uint64{0: 0, 2: 0, 3: 1} {
+		index, err := m.TreeIndexOfLeaf(leafIndex)
+		require.NoError(t, err)
+		require.Equal(t, treeIndex, index, "leaf %d", leafIndex)
+	}
+	_, _, _, _, err = m.GetProofs(3)
+	require.ErrorIs(t, err, merkletypes.ErrUnfinalizedTree)
+
+	// the nodes of the tree 0 are kept apart from the tree 1
+	treeIndex, height, nodeIndex, err := merkletypes.ParsePrefixedNodeKey(merkletypes.PrefixedNodeKey(0, 0, 0))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{0, 0, 0}, []uint64{treeIndex, uint64(height), nodeIndex})
+	var buf bytes.Buffer
+	require.NoError(t, m.ExportTree(0, &buf))
+	var export merkletypes.TreeExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &export))
+	require.Zero(t, export.TreeIndex)
+	require.Equal(t, uint64(3), export.LeafCount)
+
+	// the nodes of the tree 0 are deleted from the beginning of the range
+	count, err := m.DeleteTreeNodes(0, 1)
+	require.NoError(t, err)
+	require.Equal(t, 7, count)
+	_, _, _, _, err = m.GetProofs(0)
+	require.ErrorIs(t, err, merkletypes.ErrPrunedTree)
+}
+
+func Test_DeleteTreeNodes(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := db.NewDB(tempDir)
+	require.NoError(t, err)
+
+	hashFn := ophosttypes.GenerateNodeHash
+	m, err := NewMerkle(db, zap.NewNop(), hashFn)
+	require.NoError(t, err)
+
+	// tree 1: leaves 1-2, tree 2: leaves 3-4, tree 3: leaf 5 in working
+	for treeIndex := uint64(1); treeIndex <= 2; treeIndex++ {
+		require.NoError(t, m.InitializeWorkingTree(treeIndex, treeIndex*2-1))
+		require.NoError(t, m.InsertLeaf([]byte("node1")))
+		require.NoError(t, m.InsertLeaf([]byte("node2")))
+		kvs, _, err := m.FinalizeWorkingTree(nil)
+		require.NoError(t, err)
+		require.NoError(t, db.RawBatchSet(kvs...))
+	}
+	require.NoError(t, m.InitializeWorkingTree(3, 5))
+	require.NoError(t, m.InsertLeaf([]byte("node1")))
+
+	for leafIndex, treeIndex := range map[uint64]uint64{1: 1, 2: 1, 3: 2, 4: 2, 5: 3, 6: 3} {
+		index, err := m.TreeIndexOfLeaf(leafIndex)
+		require.NoError(t, err)
+		require.Equal(t, treeIndex, index, "leaf %d", leafIndex)
+	}
+
+	count, err := m.DeleteTreeNodes(1, 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	_, _, _, _, err = m.GetProofs(1)
+	require.ErrorIs(t, err, merkletypes.ErrPrunedTree)
+	proofs, treeIndex, _, _, err := m.GetProofs(3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), treeIndex)
+	require.Equal(t, [][]byte{[]byte("node2")}, proofs)
+
+	// the working tree is kept
+	_, _, _, _, err = m.GetProofs(5)
+	require.ErrorIs(t, err, merkletypes.ErrUnfinalizedTree)
+}
+
+func Test_TreeIndexOfLeafEmpty(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+
+	m, err := NewMerkle(db, zap.NewNop(), ophosttypes.GenerateNodeHash)
+	require.NoError(t, err)
+
+	index, err := m.TreeIndexOfLeaf(1)
+	require.NoError(t, err)
+	require.Zero(t, index)
+}
+
+func Test_ExportTree(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+
+	hashFn := ophosttypes.GenerateNodeHash
+	m, err := NewMerkle(db, zap.NewNop(), hashFn)
+	require.NoError(t, err)
+
+	// tree 1: leaves 1-5, tree 2: leaves 6-8
+	for treeIndex, leafCount := range map[uint64]int{1: 5, 2: 3} {
+		require.NoError(t, m.InitializeWorkingTree(treeIndex, treeIndex*5-4))
+		for i := 0; i < leafCount; i++ {
+			require.NoError(t, m.InsertLeaf([]byte(fmt.Sprintf("tree%d-node%d", treeIndex, i))))
+		}
+		kvs, _, err := m.FinalizeWorkingTree(nil)
+		require.NoError(t, err)
+		require.NoError(t, db.RawBatchSet(kvs...))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, m.ExportTree(1, &buf))
+	var export merkletypes.TreeExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &export))
+	require.Equal(t, uint64(1), export.TreeIndex)
+	require.Equal(t, uint64(5), export.LeafCount)
+	require.Equal(t, uint8(3), export.TreeHeight)
+
+	// the nodes of each height are sorted and only of the tree
+	nodes := make(map[uint8][][]byte)
+	for _, node := range export.Nodes {
+		require.Equal(t, uint64(len(nodes[node.Height])), node.LocalIndex)
+		nodes[node.Height] = append(nodes[node.Height], node.Hash)
+	}
+	require.Len(t, nodes[0], 8)
+	require.Equal(t, []byte("tree1-node4"), nodes[0][7])
+
+	// recompute the root from the exported leaves
+	level := nodes[0]
+	for height := uint8(1); height <= export.TreeHeight; height++ {
+		next := make([][]byte, 0, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			hash := hashFn(level[i], level[i+1])
+			next = append(next, hash[:])
+		}
+		require.Equal(t, nodes[height], next)
+		level = next
+	}
+	require.Equal(t, export.Root, level[0])
+
+	// the nodes can be stopped iterating
+	visited := 0
+	require.NoError(t, m.IterateNodes(2, func(height uint8, localIndex uint64, hash []byte) (bool, error) {
+		visited++
+		return height == 1, nil
+	}))
+	require.Equal(t, 5, visited)
+
+	// the unfinalized tree is not exported
+	require.ErrorIs(t, m.ExportTree(3, &buf), merkletypes.ErrUnfinalizedTree)
+}
@@ -0,0 +1,59 @@
+package merkle
+
+import (
+	"encoding/json"
+
+	"github.com/initia-labs/opinit-bots/db/migration"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// Schema returns the schema of the merkle db with its migrations in order.
+func Schema(db types.DB) migration.Schema {
+	return migration.Schema{
+		Name: types.MerkleName,
+		DB:   db,
+		Migrations: []migration.Migration{
+			// v1: encode the working trees and the finalized trees in binary instead of JSON
+			migration.MigrationFn(migrateTreeInfosToBinary),
+		},
+	}
+}
+
+// migrateTreeInfosToBinary re-encodes the JSON working trees and finalized trees in binary.
+func migrateTreeInfosToBinary(db types.DB) error {
+	err := db.PrefixedIterate(merkletypes.WorkingTreeKey, nil, func(key, value []byte) (bool, error) {
+		var workingTree merkletypes.TreeInfo
+		if err := json.Unmarshal(value, &workingTree); err != nil {
+			return true, err
+		}
+		data, err := workingTree.Marshal()
+		if err != nil {
+			return true, err
+		}
+		err = db.Set(key, data)
+		if err != nil {
+			return true, err
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.PrefixedIterate(merkletypes.FinalizedTreeKey, nil, func(key, value []byte) (bool, error) {
+		var tree merkletypes.FinalizedTreeInfo
+		if err := json.Unmarshal(value, &tree); err != nil {
+			return true, err
+		}
+		data, err := tree.Marshal()
+		if err != nil {
+			return true, err
+		}
+		err = db.Set(key, data)
+		if err != nil {
+			return true, err
+		}
+		return false, nil
+	})
+}
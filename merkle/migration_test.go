@@ -0,0 +1,129 @@
+package merkle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	"github.com/initia-labs/opinit-bots/db/migration"
+	merkletypes "github.com/initia-labs/opinit-bots/merkle/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// newJSONFixtureDB builds the merkle db in the JSON encoding of the tree infos before the schema versioning,
+// which has a finalized tree of 3 leaves and the working tree of the next tree.
+func newJSONFixtureDB(t *testing.T) (types.DB, merkletypes.FinalizedTreeInfo) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	merkleDB := db.WithPrefix([]byte(types.ChildName)).WithPrefix([]byte(types.MerkleName))
+
+	m, err := NewMerkle(merkleDB, zap.NewNop(), ophosttypes.GenerateNodeHash)
+	require.NoError(t, err)
+	require.NoError(t, m.InitializeWorkingTree(1, 1))
+	for _, leaf := range []string{"leaf1", "leaf2", "leaf3"} {
+		require.NoError(t, m.InsertLeaf([]byte(leaf)))
+	}
+	_, root, err := m.FinalizeWorkingTree([]byte("extra data"))
+	require.NoError(t, err)
+
+	tree := merkletypes.FinalizedTreeInfo{
+		TreeIndex:      1,
+		TreeHeight:     2,
+		Root:           root,
+		StartLeafIndex: 1,
+		LeafCount:      3,
+		ExtraData:      []byte("extra data"),
+	}
+	data, err := json.Marshal(tree)
+	require.NoError(t, err)
+	require.NoError(t, merkleDB.Set(tree.Key(), data))
+
+	workingTrees := map[uint64]merkletypes.TreeInfo{
+		10: {Index: 1, LeafCount: 3, StartLeafIndex: 1, LastSiblings: m.workingTree.LastSiblings, Done: true},
+		11: {Index: 2, LeafCount: 1, StartLeafIndex: 4, LastSiblings: map[uint8][]byte{0: []byte("leaf4")}},
+	}
+	for version, workingTree := range workingTrees {
+		data, err := json.Marshal(workingTree)
+		require.NoError(t, err)
+		require.NoError(t, merkleDB.Set(merkletypes.PrefixedWorkingTreeKey(version), data))
+	}
+	return merkleDB, tree
+}
+
+func Test_MigrateTreeInfosToBinary(t *testing.T) {
+	merkleDB, tree := newJSONFixtureDB(t)
+
+	require.NoError(t, migration.Run(zap.NewNop(), Schema(merkleDB)))
+	version, err := migration.GetSchemaVersion(merkleDB, types.MerkleName)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), version)
+
+	m, err := NewMerkle(merkleDB, zap.NewNop(), ophosttypes.GenerateNodeHash)
+	require.NoError(t, err)
+
+	// the working tree of the next tree is loaded as it is
+	require.NoError(t, m.LoadWorkingTree(11))
+	require.Equal(t, uint64(2), m.workingTree.Index)
+	require.Equal(t, uint64(4), m.workingTree.StartLeafIndex)
+	require.Equal(t, []byte("leaf4"), m.workingTree.LastSiblings[0])
+
+	// the done working tree starts the next tree
+	require.NoError(t, m.LoadWorkingTree(10))
+	require.Equal(t, uint64(2), m.workingTree.Index)
+	require.Equal(t, uint64(4), m.workingTree.StartLeafIndex)
+
+	// the proofs are served from the migrated finalized tree
+	proofs, treeIndex, root, extraData, err := m.GetProofs(2)
+	require.NoError(t, err)
+	require.Len(t, proofs, 2)
+	require.Equal(t, uint64(1), treeIndex)
+	require.Equal(t, tree.Root, root)
+	require.Equal(t, tree.ExtraData, extraData)
+
+	var trees []merkletypes.FinalizedTreeInfo
+	require.NoError(t, m.ReverseIterateFinalizedTrees(func(tree merkletypes.FinalizedTreeInfo) (bool, error) {
+		trees = append(trees, tree)
+		return false, nil
+	}))
+	require.Equal(t, []merkletypes.FinalizedTreeInfo{tree}, trees)
+
+	// the migration is applied once
+	require.NoError(t, migration.Run(zap.NewNop(), Schema(merkleDB)))
+	require.NoError(t, m.LoadWorkingTree(11))
+}
+
+func Test_TreeInfoEncoding(t *testing.T) {
+	workingTree := merkletypes.TreeInfo{
+		Index:          3,
+		LeafCount:      5,
+		StartLeafIndex: 10,
+		LastSiblings:   map[uint8][]byte{0: []byte("sibling0"), 2: []byte("sibling2")},
+		Done:           true,
+	}
+	data, err := workingTree.Marshal()
+	require.NoError(t, err)
+	var decodedTree merkletypes.TreeInfo
+	require.NoError(t, decodedTree.Unmarshal(data))
+	require.Equal(t, workingTree, decodedTree)
+	require.Error(t, decodedTree.Unmarshal(data[:len(data)-1]))
+
+	tree := merkletypes.FinalizedTreeInfo{
+		TreeIndex:      3,
+		TreeHeight:     3,
+		Root:           []byte("root"),
+		StartLeafIndex: 10,
+		LeafCount:      5,
+	}
+	data, err = tree.Marshal()
+	require.NoError(t, err)
+	var decoded merkletypes.FinalizedTreeInfo
+	require.NoError(t, decoded.Unmarshal(data))
+	require.Equal(t, tree, decoded)
+	require.Error(t, decoded.Unmarshal(append(data, 0)))
+}
@@ -1,5 +1,12 @@
 package types
 
+const (
+	// DefaultMinTreeIndex is the minimum tree index of the working trees by default, as the output indices of OPinit start from 1.
+	DefaultMinTreeIndex uint64 = 1
+	// DefaultMinStartLeafIndex is the minimum start leaf index of the working trees by default, as the withdrawal sequences of OPinit start from 1.
+	DefaultMinStartLeafIndex uint64 = 1
+)
+
 var (
 	EmptyRootHash [32]byte
 )
@@ -1,5 +1,11 @@
 package types
 
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+)
+
 type TreeInfo struct {
 	// Index of the tree used as prefix for the keys
 	Index uint64 `json:"index"`
@@ -17,6 +23,60 @@ type TreeInfo struct {
 	Done bool `json:"done"`
 }
 
+// Marshal encodes the tree info in binary, with the last siblings in the order of the height.
+func (t TreeInfo) Marshal() ([]byte, error) {
+	data := make([]byte, 0, 8*3+1+2)
+	data = binary.BigEndian.AppendUint64(data, t.Index)
+	data = binary.BigEndian.AppendUint64(data, t.LeafCount)
+	data = binary.BigEndian.AppendUint64(data, t.StartLeafIndex)
+	data = append(data, boolToByte(t.Done))
+
+	heights := make([]uint8, 0, len(t.LastSiblings))
+	for height := range t.LastSiblings {
+		heights = append(heights, height)
+	}
+	slices.Sort(heights)
+
+	data = binary.BigEndian.AppendUint16(data, uint16(len(heights)))
+	for _, height := range heights {
+		data = append(data, height)
+		data = appendBytes(data, t.LastSiblings[height])
+	}
+	return data, nil
+}
+
+// Unmarshal decodes the tree info encoded by Marshal.
+func (t *TreeInfo) Unmarshal(data []byte) error {
+	if len(data) < 8*3+1+2 {
+		return fmt.Errorf("invalid tree info length: %d", len(data))
+	}
+	t.Index = binary.BigEndian.Uint64(data[0:8])
+	t.LeafCount = binary.BigEndian.Uint64(data[8:16])
+	t.StartLeafIndex = binary.BigEndian.Uint64(data[16:24])
+	t.Done = data[24] == 1
+
+	count := int(binary.BigEndian.Uint16(data[25:27]))
+	data = data[27:]
+	t.LastSiblings = make(map[uint8][]byte, count)
+	for i := 0; i < count; i++ {
+		if len(data) < 1 {
+			return fmt.Errorf("invalid tree info; missing last sibling: %d", i)
+		}
+		height := data[0]
+
+		sibling, rest, err := readBytes(data[1:])
+		if err != nil {
+			return err
+		}
+		t.LastSiblings[height] = sibling
+		data = rest
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("invalid tree info; %d trailing bytes", len(data))
+	}
+	return nil
+}
+
 type FinalizedTreeInfo struct {
 	// TreeIndex is the index of the tree used as prefix for the keys,
 	// which is incremented by 1 for each new tree.
@@ -33,3 +93,85 @@ type FinalizedTreeInfo struct {
 func (f FinalizedTreeInfo) Key() []byte {
 	return PrefixedFinalizedTreeKey(f.StartLeafIndex)
 }
+
+// Marshal encodes the finalized tree info in binary.
+func (f FinalizedTreeInfo) Marshal() ([]byte, error) {
+	data := make([]byte, 0, 8*3+1+8+len(f.Root)+8+len(f.ExtraData))
+	data = binary.BigEndian.AppendUint64(data, f.TreeIndex)
+	data = append(data, f.TreeHeight)
+	data = binary.BigEndian.AppendUint64(data, f.StartLeafIndex)
+	data = binary.BigEndian.AppendUint64(data, f.LeafCount)
+	data = appendBytes(data, f.Root)
+	data = appendBytes(data, f.ExtraData)
+	return data, nil
+}
+
+// Unmarshal decodes the finalized tree info encoded by Marshal.
+func (f *FinalizedTreeInfo) Unmarshal(data []byte) error {
+	if len(data) < 8*3+1 {
+		return fmt.Errorf("invalid finalized tree info length: %d", len(data))
+	}
+	f.TreeIndex = binary.BigEndian.Uint64(data[0:8])
+	f.TreeHeight = data[8]
+	f.StartLeafIndex = binary.BigEndian.Uint64(data[9:17])
+	f.LeafCount = binary.BigEndian.Uint64(data[17:25])
+
+	root, rest, err := readBytes(data[25:])
+	if err != nil {
+		return err
+	}
+	extraData, rest, err := readBytes(rest)
+	if err != nil {
+		return err
+	} else if len(rest) != 0 {
+		return fmt.Errorf("invalid finalized tree info; %d trailing bytes", len(rest))
+	}
+	f.Root = root
+	f.ExtraData = extraData
+	return nil
+}
+
+// TreeExport is the dump of all the nodes of a finalized tree, which can be used to recompute the root.
+type TreeExport struct {
+	TreeIndex      uint64 `json:"tree_index"`
+	TreeHeight     uint8  `json:"tree_height"`
+	Root           []byte `json:"root"`
+	StartLeafIndex uint64 `json:"start_leaf_index"`
+	LeafCount      uint64 `json:"leaf_count"`
+	// Nodes are sorted by the height and then by the local index, and the leaves include the filled ones.
+	Nodes []NodeExport `json:"nodes"`
+}
+
+// NodeExport is a node of the exported tree.
+type NodeExport struct {
+	Height     uint8  `json:"height"`
+	LocalIndex uint64 `json:"local_index"`
+	Hash       []byte `json:"hash"`
+}
+
+func boolToByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// appendBytes appends the length prefixed bytes, where nil is encoded as empty.
+func appendBytes(data []byte, bz []byte) []byte {
+	data = binary.BigEndian.AppendUint64(data, uint64(len(bz)))
+	return append(data, bz...)
+}
+
+// readBytes reads the length prefixed bytes, and returns nil for empty.
+func readBytes(data []byte) ([]byte, []byte, error) {
+	if len(data) < 8 {
+		return nil, nil, fmt.Errorf("invalid length prefix: %d bytes", len(data))
+	}
+	length := binary.BigEndian.Uint64(data[:8])
+	if uint64(len(data)-8) < length {
+		return nil, nil, fmt.Errorf("invalid length: %d, remaining: %d", length, len(data)-8)
+	} else if length == 0 {
+		return nil, data[8:], nil
+	}
+	return data[8 : 8+length], data[8+length:], nil
+}
@@ -3,3 +3,9 @@ package types
 import "errors"
 
 var ErrUnfinalizedTree = errors.New("unfinalized tree")
+
+// ErrPrunedTree is returned when the nodes of the finalized tree are pruned.
+var ErrPrunedTree = errors.New("pruned tree")
+
+// ErrTreeFinalized is returned when a leaf is inserted to the finalized working tree.
+var ErrTreeFinalized = errors.New("tree finalized")
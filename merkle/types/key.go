@@ -1,7 +1,9 @@
 package types
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
 
 	dbtypes "github.com/initia-labs/opinit-bots/db/types"
 )
@@ -24,6 +26,28 @@ func PrefixedNodeKey(treeIndex uint64, height uint8, nodeIndex uint64) []byte {
 	return append(append(NodeKey, dbtypes.Splitter), GetNodeKey(treeIndex, height, nodeIndex)...)
 }
 
+// PrefixedTreeNodesKey returns the prefix of the node keys of the tree.
+func PrefixedTreeNodesKey(treeIndex uint64) []byte {
+	return append(append(NodeKey, dbtypes.Splitter), dbtypes.FromUint64Key(treeIndex)...)
+}
+
+// ParseNodeKey decodes the key encoded by GetNodeKey.
+func ParseNodeKey(key []byte) (treeIndex uint64, height uint8, nodeIndex uint64, err error) {
+	if len(key) != 17 {
+		return 0, 0, 0, fmt.Errorf("invalid node key length: %d", len(key))
+	}
+	return binary.BigEndian.Uint64(key[:8]), key[8], binary.BigEndian.Uint64(key[9:]), nil
+}
+
+// ParsePrefixedNodeKey decodes the key encoded by PrefixedNodeKey.
+func ParsePrefixedNodeKey(key []byte) (treeIndex uint64, height uint8, nodeIndex uint64, err error) {
+	prefix := append(NodeKey, dbtypes.Splitter)
+	if !bytes.HasPrefix(key, prefix) {
+		return 0, 0, 0, fmt.Errorf("invalid node key prefix: %X", key)
+	}
+	return ParseNodeKey(key[len(prefix):])
+}
+
 func PrefixedFinalizedTreeKey(startLeafIndex uint64) []byte {
 	return append(append(FinalizedTreeKey, dbtypes.Splitter), dbtypes.FromUint64Key(startLeafIndex)...)
 }
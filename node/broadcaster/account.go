@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync/atomic"
+
+	sdkmath "cosmossdk.io/math"
 
 	abci "github.com/cometbft/cometbft/abci/types"
 	"github.com/initia-labs/opinit-bots/keys"
@@ -13,6 +16,8 @@ import (
 
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 
+	gogogrpc "github.com/cosmos/gogoproto/grpc"
+
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -21,6 +26,7 @@ import (
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 )
 
 type BroadcasterAccount struct {
@@ -28,19 +34,27 @@ type BroadcasterAccount struct {
 	txf       tx.Factory
 	cdc       codec.Codec
 	txConfig  client.TxConfig
-	rpcClient *rpcclient.RPCClient
+	rpcClient rpcclient.Client
+	queryConn gogogrpc.ClientConn
 
 	keyName       string
 	keyBase       keyring.Keyring
 	keyringRecord *keyring.Record
 	address       sdk.AccAddress
 	addressString string
+	feeGranter    sdk.AccAddress
+
+	// number of the txs broadcasted since the cached sequence was reconciled with the chain
+	txsSinceReconcile uint64
+	// reconcile the cached sequence before the next tx, e.g. after a broadcast error.
+	// It is set from the other goroutines when the pending txs are dropped.
+	reconcileRequired *atomic.Bool
 
 	BuildTxWithMessages      btypes.BuildTxWithMessagesFn
 	PendingTxToProcessedMsgs btypes.PendingTxToProcessedMsgsFn
 }
 
-func NewBroadcasterAccount(cfg btypes.BroadcasterConfig, cdc codec.Codec, txConfig client.TxConfig, rpcClient *rpcclient.RPCClient, keyringConfig btypes.KeyringConfig) (*BroadcasterAccount, error) {
+func NewBroadcasterAccount(cfg btypes.BroadcasterConfig, cdc codec.Codec, txConfig client.TxConfig, rpcClient rpcclient.Client, keyringConfig btypes.KeyringConfig) (*BroadcasterAccount, error) {
 	err := keyringConfig.Validate()
 	if err != nil {
 		return nil, err
@@ -74,6 +88,8 @@ func NewBroadcasterAccount(cfg btypes.BroadcasterConfig, cdc codec.Codec, txConf
 		address:       addr,
 		addressString: addrStr,
 
+		reconcileRequired: &atomic.Bool{},
+
 		BuildTxWithMessages:      keyringConfig.BuildTxWithMessages,
 		PendingTxToProcessedMsgs: keyringConfig.PendingTxToProcessedMsgs,
 	}
@@ -93,7 +109,17 @@ func NewBroadcasterAccount(cfg btypes.BroadcasterConfig, cdc codec.Codec, txConf
 		WithGasAdjustment(cfg.GasAdjustment).
 		WithGasPrices(cfg.GasPrice).
 		WithKeybase(keyBase).
-		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT)
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
+		WithMemo(cfg.Memo)
+
+	if cfg.FeeGranter != "" {
+		feeGranter, err := keys.DecodeBech32AccAddr(cfg.FeeGranter, cfg.Bech32Prefix)
+		if err != nil {
+			return nil, err
+		}
+		b.txf = b.txf.WithFeeGranter(feeGranter)
+		b.feeGranter = feeGranter
+	}
 
 	if keyringConfig.FeeGranter != nil {
 		// setup keyring
@@ -107,6 +133,7 @@ func NewBroadcasterAccount(cfg btypes.BroadcasterConfig, cdc codec.Codec, txConf
 			return nil, err
 		}
 		b.txf = b.txf.WithFeeGranter(feeGranter)
+		b.feeGranter = feeGranter
 	}
 	return b, nil
 }
@@ -115,10 +142,22 @@ func (b BroadcasterAccount) GetAddress() sdk.AccAddress {
 	return b.address
 }
 
+func (b BroadcasterAccount) KeyName() string {
+	return b.keyName
+}
+
 func (b BroadcasterAccount) GetAddressString() string {
 	return b.addressString
 }
 
+func (b BroadcasterAccount) HasFeeGranter() bool {
+	return b.feeGranter != nil
+}
+
+func (b BroadcasterAccount) Memo() string {
+	return b.txf.Memo()
+}
+
 func (b BroadcasterAccount) Bech32Prefix() string {
 	return b.cfg.Bech32Prefix
 }
@@ -129,6 +168,8 @@ func (b *BroadcasterAccount) Load(ctx context.Context) error {
 		return err
 	}
 	b.txf = b.txf.WithAccountNumber(account.GetAccountNumber()).WithSequence(account.GetSequence())
+	b.txsSinceReconcile = 0
+	b.reconcileRequired.Store(false)
 	return nil
 }
 
@@ -137,12 +178,34 @@ func (b BroadcasterAccount) GetLatestSequence(ctx context.Context) (uint64, erro
 	if err != nil {
 		return 0, err
 	}
-	return account.GetAccountNumber(), nil
+	return account.GetSequence(), nil
+}
+
+// GetBalance queries the balance of the account for the given denom.
+func (b BroadcasterAccount) GetBalance(ctx context.Context, denom string) (sdk.Coin, error) {
+	queryClient := banktypes.NewQueryClient(b.getQueryConn())
+	res, err := queryClient.Balance(ctx, &banktypes.QueryBalanceRequest{
+		Address: b.addressString,
+		Denom:   denom,
+	})
+	if err != nil {
+		return sdk.Coin{}, err
+	} else if res.Balance == nil {
+		return sdk.NewCoin(denom, sdkmath.ZeroInt()), nil
+	}
+	return *res.Balance, nil
+}
+
+// getQueryConn returns the connection for the queries, which falls back to the rpc client.
+func (b BroadcasterAccount) getQueryConn() gogogrpc.ClientConn {
+	if b.queryConn == nil {
+		return b.rpcClient
+	}
+	return b.queryConn
 }
 
 func (b BroadcasterAccount) getClientCtx(ctx context.Context) client.Context {
-	return client.Context{}.WithClient(b.rpcClient).
-		WithInterfaceRegistry(b.cdc.InterfaceRegistry()).
+	return client.Context{}.WithInterfaceRegistry(b.cdc.InterfaceRegistry()).
 		WithChainID(b.cfg.ChainID).
 		WithCodec(b.cdc).
 		WithFromAddress(b.address).
@@ -153,14 +216,50 @@ func (b BroadcasterAccount) Sequence() uint64 {
 	return b.txf.Sequence()
 }
 
+func (b BroadcasterAccount) AccountNumber() uint64 {
+	return b.txf.AccountNumber()
+}
+
 func (b *BroadcasterAccount) IncreaseSequence() {
 	b.txf = b.txf.WithSequence(b.txf.Sequence() + 1)
+	b.txsSinceReconcile++
+}
+
+// RequireReconcile makes the cached sequence reconciled with the chain before the next tx.
+func (b *BroadcasterAccount) RequireReconcile() {
+	b.reconcileRequired.Store(true)
 }
 
 func (b *BroadcasterAccount) UpdateSequence(sequence uint64) {
 	b.txf = b.txf.WithSequence(sequence)
 }
 
+// SetTimeoutHeight sets the timeout height of the txs built by the account.
+// Zero means no timeout height.
+func (b *BroadcasterAccount) SetTimeoutHeight(height uint64) {
+	b.txf = b.txf.WithTimeoutHeight(height)
+}
+
+// SetGasPrices sets the gas prices of the txs built by the account to the gas prices of the gas price
+// source multiplied by the multiplier, which escalates the fees of the resubmitted msgs.
+func (b *BroadcasterAccount) SetGasPrices(gasPrices sdk.DecCoins, multiplier float64) {
+	if multiplier <= 1 {
+		b.txf = b.txf.WithGasPrices(gasPrices.String())
+		return
+	}
+
+	dec, err := floatToDec(multiplier)
+	if err != nil {
+		b.txf = b.txf.WithGasPrices(gasPrices.String())
+		return
+	}
+	b.txf = b.txf.WithGasPrices(gasPrices.MulDec(dec).String())
+}
+
+func (b BroadcasterAccount) TimeoutHeight() uint64 {
+	return b.txf.TimeoutHeight()
+}
+
 func (b BroadcasterAccount) BroadcastTxSync(ctx context.Context, txBytes []byte) (*ctypes.ResultBroadcastTx, error) {
 	return b.rpcClient.BroadcastTxSync(ctx, txBytes)
 }
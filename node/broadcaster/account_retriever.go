@@ -27,7 +27,7 @@ func (b *BroadcasterAccount) GetAccount(clientCtx client.Context, addr sdk.AccAd
 // or decoding fails.
 func (b *BroadcasterAccount) GetAccountWithHeight(clienCtx client.Context, addr sdk.AccAddress) (client.Account, int64, error) {
 	var header metadata.MD
-	queryClient := authtypes.NewQueryClient(b.rpcClient)
+	queryClient := authtypes.NewQueryClient(b.getQueryConn())
 	res, err := queryClient.Account(clienCtx.CmdContext, &authtypes.QueryAccountRequest{Address: b.addressString}, grpc.Header(&header))
 	if err != nil {
 		return nil, 0, err
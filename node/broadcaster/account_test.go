@@ -0,0 +1,76 @@
+package broadcaster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/initia-labs/opinit-bots/keys"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	"github.com/initia-labs/opinit-bots/txutils"
+)
+
+func Test_FeeGranterAndMemo(t *testing.T) {
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{banktypes.RegisterInterfaces})
+	require.NoError(t, err)
+
+	rpcClient := nodetest.NewFakeClient("test-1")
+
+	cfg := btypes.BroadcasterConfig{
+		ChainID:       "test-1",
+		GasPrice:      "0.15uinit",
+		GasAdjustment: 1.5,
+		TxTimeout:     time.Minute,
+		Bech32Prefix:  "init",
+		HomePath:      t.TempDir(),
+		Memo:          "accounting-tag",
+	}
+
+	keyBase, err := keys.GetKeyBase(cfg.ChainID, cfg.HomePath, cdc, nil)
+	require.NoError(t, err)
+
+	newKey := func(name string) string {
+		mnemonic, err := keys.CreateMnemonic()
+		require.NoError(t, err)
+		record, err := keyBase.NewAccount(name, mnemonic, "", sdk.FullFundraiserPath, hd.Secp256k1)
+		require.NoError(t, err)
+		addr, err := record.GetAddress()
+		require.NoError(t, err)
+		addrStr, err := keys.EncodeBech32AccAddr(addr, cfg.Bech32Prefix)
+		require.NoError(t, err)
+		return addrStr
+	}
+	sender := newKey("sender")
+	cfg.FeeGranter = newKey("granter")
+	require.NoError(t, cfg.Validate())
+
+	account, err := NewBroadcasterAccount(cfg, cdc, txConfig, rpcClient, btypes.KeyringConfig{Name: "sender"})
+	require.NoError(t, err)
+	require.True(t, account.HasFeeGranter())
+	require.Equal(t, cfg.Memo, account.Memo())
+
+	txBytes, err := account.buildSimTx(&banktypes.MsgSend{
+		FromAddress: sender,
+		ToAddress:   cfg.FeeGranter,
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uinit", 100)),
+	})
+	require.NoError(t, err)
+
+	tx, err := txutils.DecodeTx(txConfig, txBytes)
+	require.NoError(t, err)
+	require.Equal(t, cfg.Memo, tx.GetMemo())
+
+	feeGranter, err := keys.EncodeBech32AccAddr(tx.FeeGranter(), cfg.Bech32Prefix)
+	require.NoError(t, err)
+	require.Equal(t, cfg.FeeGranter, feeGranter)
+
+	// invalid fee granter
+	cfg.FeeGranter = "cosmos1invalid"
+	require.Error(t, cfg.Validate())
+}
@@ -0,0 +1,105 @@
+package broadcaster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// feeGasPrice returns the gas price of the fee denom from the gas price source.
+// If the gas price is empty, it returns false.
+func (b Broadcaster) feeGasPrice(ctx context.Context) (sdk.DecCoin, bool) {
+	gasPrices, err := b.gasPriceSource.GasPrices(ctx)
+	if err != nil || gasPrices.Len() == 0 {
+		return sdk.DecCoin{}, false
+	}
+	return gasPrices[0], true
+}
+
+// checkBalance checks whether the account can pay the estimated fees of the queued txs,
+// and updates the low balance flag of the account.
+func (b *Broadcaster) checkBalance(ctx context.Context, account *BroadcasterAccount) error {
+	// the fee is paid by the fee granter
+	if account.HasFeeGranter() {
+		return nil
+	}
+
+	gasPrice, ok := b.feeGasPrice(ctx)
+	if !ok {
+		return nil
+	}
+
+	balance, err := account.GetBalance(ctx, gasPrice.Denom)
+	if err != nil {
+		return errors.Wrap(err, "failed to query balance")
+	}
+
+	queuedTxs := int64(b.LenLocalPendingTxByAddress(account.GetAddressString())+b.LenQueuedMsgsByAddress(account.GetAddressString())) + 1
+	threshold := gasPrice.Amount.
+		MulInt64(types.MustUint64ToInt64(b.cfg.LowBalanceGas)).
+		MulInt64(queuedTxs).
+		Ceil().
+		TruncateInt()
+
+	lowBalance := balance.Amount.LT(threshold)
+	b.setLowBalance(account.GetAddressString(), lowBalance)
+	if lowBalance {
+		return errors.Wrapf(types.ErrInsufficientBalance, "address: %s, balance: %s, required: %s%s", account.GetAddressString(), balance.String(), threshold.String(), gasPrice.Denom)
+	}
+	return nil
+}
+
+// CheckBalance checks whether the account of the address can pay the estimated fees of the queued txs.
+// It returns ErrInsufficientBalance if the balance is low, so the callers can pause queueing the msgs.
+func (b *Broadcaster) CheckBalance(ctx context.Context, address string) error {
+	account, err := b.AccountByAddress(address)
+	if err != nil {
+		return err
+	}
+	return b.checkBalance(ctx, account)
+}
+
+// checkBalances re-checks the balances of all accounts and logs a warning if the balance is low.
+func (b *Broadcaster) checkBalances(ctx context.Context) {
+	for _, account := range b.activeAccounts() {
+		err := b.checkBalance(ctx, account)
+		if errors.Is(err, types.ErrInsufficientBalance) {
+			b.logger.Warn("low balance", zap.String("address", account.GetAddressString()), zap.String("error", err.Error()))
+		} else if err != nil {
+			b.logger.Warn("failed to check balance", zap.String("address", account.GetAddressString()), zap.String("error", err.Error()))
+		}
+	}
+}
+
+func (b *Broadcaster) setLowBalance(address string, lowBalance bool) {
+	b.balanceMu.Lock()
+	defer b.balanceMu.Unlock()
+
+	b.lowBalances[address] = lowBalance
+}
+
+// IsLowBalance returns true if the balance of the account is lower than the estimated fees.
+func (b Broadcaster) IsLowBalance(address string) bool {
+	b.balanceMu.Lock()
+	defer b.balanceMu.Unlock()
+
+	return b.lowBalances[address]
+}
+
+// LowBalance returns true if any of the accounts has low balance.
+func (b Broadcaster) LowBalance() bool {
+	b.balanceMu.Lock()
+	defer b.balanceMu.Unlock()
+
+	for _, lowBalance := range b.lowBalances {
+		if lowBalance {
+			return true
+		}
+	}
+	return false
+}
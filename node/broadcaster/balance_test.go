@@ -0,0 +1,98 @@
+package broadcaster
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// handleBalanceQuery serves the balance of the accounts from the pointer, so the tests can fund the accounts.
+func handleBalanceQuery(t *testing.T, chain *mockChain, balance *atomic.Int64) {
+	chain.HandleQuery("/cosmos.bank.v1beta1.Query/Balance", func(req abcitypes.RequestQuery) (abcitypes.ResponseQuery, error) {
+		var balanceReq banktypes.QueryBalanceRequest
+		require.NoError(t, balanceReq.Unmarshal(req.Data))
+		coin := sdk.NewCoin(balanceReq.Denom, sdkmath.NewInt(balance.Load()))
+		bz, err := (&banktypes.QueryBalanceResponse{Balance: &coin}).Marshal()
+		require.NoError(t, err)
+		return abcitypes.ResponseQuery{Value: bz}, nil
+	})
+}
+
+func Test_CheckBalance(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+	chain := newMockChain(t, b, 10)
+
+	// 0.15uinit * 1000 gas for a tx
+	b.cfg.LowBalanceGas = 1000
+	balance := &atomic.Int64{}
+	balance.Store(150)
+	handleBalanceQuery(t, chain, balance)
+
+	ctx := context.Background()
+	require.NoError(t, b.CheckBalance(ctx, sender))
+	require.False(t, b.IsLowBalance(sender))
+
+	// the threshold grows with the queued txs
+	b.BroadcastMsgs(btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 1)})
+	err := b.CheckBalance(ctx, sender)
+	require.ErrorIs(t, err, types.ErrInsufficientBalance)
+	require.ErrorContains(t, err, "required: 300uinit")
+	require.True(t, b.IsLowBalance(sender))
+	require.True(t, b.LowBalance())
+
+	balance.Store(300)
+	require.NoError(t, b.CheckBalance(ctx, sender))
+	require.False(t, b.LowBalance())
+
+	// the fees paid by the fee granter are not checked
+	balance.Store(0)
+	b.accounts[0].feeGranter = sdk.AccAddress("granter")
+	require.NoError(t, b.CheckBalance(ctx, sender))
+
+	_, err = b.AccountByAddress("unknown")
+	require.Error(t, err)
+	require.Error(t, b.CheckBalance(ctx, "unknown"))
+}
+
+func Test_WaitForBalance(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	account, err := b.AccountByAddress(addresses[0])
+	require.NoError(t, err)
+	chain := newMockChain(t, b, 10)
+
+	b.cfg.LowBalanceGas = 1000
+	b.cfg.BalanceCheckInterval = time.Millisecond
+	balance := &atomic.Int64{}
+	handleBalanceQuery(t, chain, balance)
+
+	// the account is funded while waiting
+	funded := make(chan struct{})
+	go func() {
+		defer close(funded)
+		time.Sleep(20 * time.Millisecond)
+		balance.Store(150)
+	}()
+	require.False(t, b.waitForBalance(context.Background(), account))
+	<-funded
+	require.False(t, b.IsLowBalance(account.GetAddressString()))
+
+	// the wait is stopped by the context
+	balance.Store(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.True(t, b.waitForBalance(ctx, account))
+	require.True(t, b.IsLowBalance(account.GetAddressString()))
+}
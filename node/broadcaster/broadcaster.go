@@ -5,18 +5,20 @@ import (
 	"fmt"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
-	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
-
 	"github.com/pkg/errors"
 
+	gogogrpc "github.com/cosmos/gogoproto/grpc"
+
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
 
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/metrics"
 	"github.com/initia-labs/opinit-bots/node/rpcclient"
 	"github.com/initia-labs/opinit-bots/types"
 )
@@ -24,53 +26,112 @@ import (
 type Broadcaster struct {
 	cfg btypes.BroadcasterConfig
 
-	db        types.DB
-	cdc       codec.Codec
-	logger    *zap.Logger
-	rpcClient *rpcclient.RPCClient
+	db     types.DB
+	cdc    codec.Codec
+	logger *zap.Logger
+	// name is the name of the node logger, used as the label of the metrics
+	name      string
+	rpcClient rpcclient.Client
+	queryConn gogogrpc.ClientConn
+	metrics   *metrics.NodeMetrics
+
+	// gasPriceSource provides the gas prices of the txs at the signing time
+	gasPriceSource GasPriceSource
 
 	txConfig          client.TxConfig
 	accounts          []*BroadcasterAccount
 	addressAccountMap map[string]int
 	accountMu         *sync.Mutex
 
-	txChannel        chan btypes.ProcessedMsgs
+	// msg type url to the account index
+	msgTypeRoutes map[string]int
+
+	// lane name to the account index
+	laneRoutes map[string]int
+
+	// msg type url to the checker of the msg effect
+	effectCheckers map[string]btypes.EffectChecker
+
+	// msg type url to the function replacing the signer of the msg
+	msgResigners map[string]btypes.MsgResignerFn
+
+	// rotated address to the account, kept to resolve the pending txs signed before the rotation
+	retiredAccounts map[string]*BroadcasterAccount
+	// rotated address to the address of the new account
+	rotatedSenders map[string]string
+
+	// broadcast lanes of the accounts
+	lanes            map[string]*broadcastLane
 	txChannelStopped chan struct{}
 
-	// local pending txs, which is following Queue data structure
-	pendingTxMu *sync.Mutex
-	pendingTxs  []btypes.PendingTxInfo
+	// local pending txs of the accounts, which are following Queue data structure
+	pendingTxMu     *sync.Mutex
+	pendingTxs      map[string][]btypes.PendingTxInfo
+	pendingTxCursor int
 
 	pendingProcessedMsgs []btypes.ProcessedMsgs
 
+	// the msgs broadcasted before the preparation are buffered, and merged with the msgs restored from the db
+	// by the preparation. The later broadcasts of the restored msgs by their producers are dropped.
+	prepareMu        *sync.Mutex
+	prepared         bool
+	unpreparedMsgs   []btypes.ProcessedMsgs
+	restoredTraceIDs map[string]struct{}
+
+	// last counter assigned to the saved processed msgs
+	processedMsgsCounter *atomic.Uint64
+
+	// low balance flags of the accounts
+	balanceMu   *sync.Mutex
+	lowBalances map[string]bool
+
 	lastProcessedBlockHeight int64
 }
 
+// LoggerName is the name of the broadcaster logger under the node logger, whose level can be set
+// separately by the logging config.
+const LoggerName = "node.broadcaster"
+
 func NewBroadcaster(
 	cfg btypes.BroadcasterConfig,
 	db types.DB,
 	logger *zap.Logger,
 	cdc codec.Codec,
 	txConfig client.TxConfig,
-	rpcClient *rpcclient.RPCClient,
+	rpcClient rpcclient.Client,
 ) (*Broadcaster, error) {
 	b := &Broadcaster{
 		cdc:       cdc,
-		logger:    logger,
+		logger:    logger.Named(LoggerName),
+		name:      logger.Name(),
 		db:        db,
 		rpcClient: rpcClient,
+		metrics:   metrics.NewNodeMetrics(cfg.Bridge),
 
 		txConfig:          txConfig,
 		accounts:          make([]*BroadcasterAccount, 0),
 		addressAccountMap: make(map[string]int),
 		accountMu:         &sync.Mutex{},
 
-		txChannel:        make(chan btypes.ProcessedMsgs),
+		msgTypeRoutes:  make(map[string]int),
+		laneRoutes:     make(map[string]int),
+		effectCheckers: make(map[string]btypes.EffectChecker),
+		msgResigners:   make(map[string]btypes.MsgResignerFn),
+
+		retiredAccounts: make(map[string]*BroadcasterAccount),
+		rotatedSenders:  make(map[string]string),
+
+		lanes:            make(map[string]*broadcastLane),
 		txChannelStopped: make(chan struct{}),
 
 		pendingTxMu:          &sync.Mutex{},
-		pendingTxs:           make([]btypes.PendingTxInfo, 0),
+		pendingTxs:           make(map[string][]btypes.PendingTxInfo),
 		pendingProcessedMsgs: make([]btypes.ProcessedMsgs, 0),
+		prepareMu:            &sync.Mutex{},
+		processedMsgsCounter: &atomic.Uint64{},
+
+		balanceMu:   &sync.Mutex{},
+		lowBalances: make(map[string]bool),
 	}
 
 	// validate broadcaster config
@@ -85,25 +146,84 @@ func NewBroadcaster(
 	if rpcClient == nil {
 		return nil, errors.New("rpc client is nil")
 	}
+
+	gasPriceSource, err := newGasPriceSource(cfg, rpcClient, b.logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gas price source")
+	}
+	b.gasPriceSource = gasPriceSource
+
+	counter, err := b.loadProcessedMsgsCounter()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load processed msgs counter")
+	}
+	b.processedMsgsCounter.Store(counter)
 	return b, nil
 }
 
-func (b *Broadcaster) Initialize(ctx context.Context, status *rpccoretypes.ResultStatus, keyringConfigs []btypes.KeyringConfig) error {
+// SetQueryConn sets the connection for the account queries. If it is not set, the rpc client is used.
+func (b *Broadcaster) SetQueryConn(conn gogogrpc.ClientConn) {
+	b.queryConn = conn
+}
+
+// Initialize adds the accounts of the keyring configs and prepares the broadcaster.
+func (b *Broadcaster) Initialize(ctx context.Context, keyringConfigs []btypes.KeyringConfig) error {
+	err := b.AddAccounts(keyringConfigs)
+	if err != nil {
+		return err
+	}
+	return b.Prepare(ctx)
+}
+
+// AddAccounts adds the accounts of the keyring configs and their routes. It doesn't query the chain,
+// so the addresses of the accounts are available before the node catches up the chain.
+func (b *Broadcaster) AddAccounts(keyringConfigs []btypes.KeyringConfig) error {
 	for _, keyringConfig := range keyringConfigs {
 		account, err := NewBroadcasterAccount(b.cfg, b.cdc, b.txConfig, b.rpcClient, keyringConfig)
 		if err != nil {
 			return err
 		}
-		err = account.Load(ctx)
+		account.queryConn = b.queryConn
+		b.accounts = append(b.accounts, account)
+		b.addressAccountMap[account.GetAddressString()] = len(b.accounts) - 1
+		b.lanes[account.GetAddressString()] = newBroadcastLane(b.cfg.GetMaxQueuedMsgs())
+
+		for _, msgType := range keyringConfig.MsgTypes {
+			if _, ok := b.msgTypeRoutes[msgType]; ok {
+				return fmt.Errorf("duplicated msg type route: %s", msgType)
+			}
+			b.msgTypeRoutes[msgType] = len(b.accounts) - 1
+		}
+
+		for _, lane := range keyringConfig.Lanes {
+			if _, ok := b.laneRoutes[lane]; ok {
+				return fmt.Errorf("duplicated lane route: %s", lane)
+			}
+			b.laneRoutes[lane] = len(b.accounts) - 1
+		}
+	}
+	return nil
+}
+
+// Prepare loads the sequences of the accounts from the chain and restores the pending txs and msgs.
+// It must be called after the node catches up the chain, before the broadcaster is started.
+func (b *Broadcaster) Prepare(ctx context.Context) error {
+	// the dry-run signs nothing, and leaves the pending txs and msgs of the previous run untouched
+	if b.cfg.DryRun {
+		b.logger.Warn("dry-run mode: the msgs are recorded instead of being broadcasted")
+		b.markPrepared(nil)
+		return nil
+	}
+
+	for _, account := range b.accounts {
+		err := account.Load(ctx)
 		if err != nil {
 			return err
 		}
-		b.accounts = append(b.accounts, account)
-		b.addressAccountMap[account.GetAddressString()] = len(b.accounts) - 1
 	}
 
 	// prepare broadcaster
-	err := b.prepareBroadcaster(ctx, status.SyncInfo.LatestBlockTime)
+	err := b.prepareBroadcaster(ctx)
 	return errors.Wrap(err, "failed to prepare broadcaster")
 }
 
@@ -115,67 +235,28 @@ func (b *Broadcaster) SetSyncInfo(height int64) {
 	b.lastProcessedBlockHeight = height
 }
 
-func (b *Broadcaster) prepareBroadcaster(ctx context.Context, lastBlockTime time.Time) error {
+func (b *Broadcaster) prepareBroadcaster(ctx context.Context) error {
+	// fail fast if the accounts can't pay the fees
+	for _, account := range b.accounts {
+		err := b.checkBalance(ctx, account)
+		if err != nil {
+			return err
+		}
+	}
+
 	dbBatchKVs := make([]types.RawKV, 0)
 
 	loadedPendingTxs, err := b.loadPendingTxs()
 	if err != nil {
 		return err
 	}
-	if len(loadedPendingTxs) > 0 {
-		pendingTxTime := time.Unix(0, loadedPendingTxs[0].Timestamp)
-
-		// if we have pending txs, wait until timeout
-		if timeoutTime := pendingTxTime.Add(b.cfg.TxTimeout); lastBlockTime.Before(timeoutTime) {
-			waitingTime := timeoutTime.Sub(lastBlockTime)
-			timer := time.NewTimer(waitingTime)
-			b.logger.Info("waiting for pending txs to be processed", zap.Duration("waiting_time", waitingTime))
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-timer.C:
-			}
-		}
-
-		// convert pending txs to raw kv pairs for deletion
-		pendingKVs, err := b.PendingTxsToRawKV(loadedPendingTxs, true)
-		if err != nil {
-			return err
-		}
-
-		// add pending txs delegation to db batch
-		dbBatchKVs = append(dbBatchKVs, pendingKVs...)
-
-		// convert pending txs to pending msgs
-		for i, txInfo := range loadedPendingTxs {
-			account, err := b.AccountByAddress(txInfo.Sender)
-			if err != nil {
-				return err
-			}
-			msgs, err := account.PendingTxToProcessedMsgs(txInfo.Tx)
-			if err != nil {
-				return err
-			}
-
-			if txInfo.Save {
-				for i := 0; i < len(msgs); i += 5 {
-					end := i + 5
-					if end > len(msgs) {
-						end = len(msgs)
-					}
-
-					b.pendingProcessedMsgs = append(b.pendingProcessedMsgs, btypes.ProcessedMsgs{
-						Sender:    txInfo.Sender,
-						Msgs:      slices.Clone(msgs[i:end]),
-						Timestamp: time.Now().UnixNano(),
-						Save:      true,
-					})
-				}
-			}
-
-			b.logger.Debug("pending tx", zap.Int("index", i), zap.String("tx", txInfo.String()))
-		}
+	// drop the pending txs which already landed, and leave the others to the lanes
+	// not to block the startup until they are resolved
+	pendingKVs, err := b.restorePendingTxs(ctx, loadedPendingTxs)
+	if err != nil {
+		return err
 	}
+	dbBatchKVs = append(dbBatchKVs, pendingKVs...)
 
 	loadedProcessedMsgs, err := b.loadProcessedMsgs()
 	if err != nil {
@@ -183,7 +264,7 @@ func (b *Broadcaster) prepareBroadcaster(ctx context.Context, lastBlockTime time
 	}
 
 	// need to remove processed msgs from db before updating the timestamp
-	// because the timestamp is used as a key.
+	// because the timestamp and the counter are used as a key.
 	kvProcessedMsgs, err := b.ProcessedMsgsToRawKV(loadedProcessedMsgs, true)
 	if err != nil {
 		return err
@@ -193,11 +274,19 @@ func (b *Broadcaster) prepareBroadcaster(ctx context.Context, lastBlockTime time
 	// update timestamp of loaded processed msgs
 	for i, pendingMsgs := range loadedProcessedMsgs {
 		loadedProcessedMsgs[i].Timestamp = time.Now().UnixNano()
+		// the new counters are assigned on save not to collide with the same timestamp
+		loadedProcessedMsgs[i].Counter = 0
 		b.logger.Debug("pending msgs", zap.Int("index", i), zap.String("msgs", pendingMsgs.String()))
 	}
 
 	// save all pending msgs with updated timestamp to db
 	b.pendingProcessedMsgs = append(b.pendingProcessedMsgs, loadedProcessedMsgs...)
+
+	// skip the msgs which already landed before the restart
+	b.pendingProcessedMsgs, err = b.filterLandedProcessedMsgs(ctx, b.pendingProcessedMsgs)
+	if err != nil {
+		return err
+	}
 	kvProcessedMsgs, err = b.ProcessedMsgsToRawKV(b.pendingProcessedMsgs, false)
 	if err != nil {
 		return err
@@ -209,9 +298,76 @@ func (b *Broadcaster) prepareBroadcaster(ctx context.Context, lastBlockTime time
 	if err != nil {
 		return err
 	}
+	b.markPrepared(loadedProcessedMsgs)
+	return nil
+}
+
+// markPrepared merges the msgs broadcasted before the preparation into the pending msgs, deduplicated
+// by the trace id, as the saved msgs are broadcasted by their producers and restored from the db as well.
+func (b *Broadcaster) markPrepared(restoredProcessedMsgs []btypes.ProcessedMsgs) {
+	b.prepareMu.Lock()
+	defer b.prepareMu.Unlock()
+
+	b.restoredTraceIDs = make(map[string]struct{}, len(restoredProcessedMsgs))
+	for _, restored := range restoredProcessedMsgs {
+		if restored.TraceID != "" {
+			b.restoredTraceIDs[restored.TraceID] = struct{}{}
+		}
+	}
+	for _, msgs := range b.unpreparedMsgs {
+		if _, ok := b.restoredTraceIDs[msgs.TraceID]; ok {
+			delete(b.restoredTraceIDs, msgs.TraceID)
+			continue
+		}
+		b.pendingProcessedMsgs = append(b.pendingProcessedMsgs, msgs)
+	}
+	b.unpreparedMsgs = nil
+	b.prepared = true
+}
+
+// maxTimeoutHeight returns the max timeout height of the pending txs.
+// It returns 0 if any of the pending txs has no timeout height.
+func maxTimeoutHeight(pendingTxs []btypes.PendingTxInfo) uint64 {
+	maxHeight := uint64(0)
+	for _, pendingTx := range pendingTxs {
+		if pendingTx.TimeoutHeight == 0 {
+			return 0
+		} else if pendingTx.TimeoutHeight > maxHeight {
+			maxHeight = pendingTx.TimeoutHeight
+		}
+	}
+	return maxHeight
+}
+
+// waitForTimeoutHeight waits until the chain height passes the timeout height.
+func (b *Broadcaster) waitForTimeoutHeight(ctx context.Context, latestHeight int64, timeoutHeight uint64) error {
+	ticker := time.NewTicker(types.PollingInterval(ctx))
+	defer ticker.Stop()
+
+	for latestHeight <= 0 || types.MustInt64ToUint64(latestHeight) <= timeoutHeight {
+		b.logger.Info("waiting for pending txs to be processed", zap.Int64("latest_height", latestHeight), zap.Uint64("timeout_height", timeoutHeight))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		header, err := b.rpcClient.Header(ctx, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to query latest header")
+		}
+		latestHeight = header.Header.Height
+	}
 	return nil
 }
 
+// activeAccounts returns the snapshot of the accounts, which can be replaced by the key rotation.
+func (b Broadcaster) activeAccounts() []*BroadcasterAccount {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+	return slices.Clone(b.accounts)
+}
+
 func (b Broadcaster) AccountByIndex(index int) (*BroadcasterAccount, error) {
 	b.accountMu.Lock()
 	defer b.accountMu.Unlock()
@@ -221,11 +377,38 @@ func (b Broadcaster) AccountByIndex(index int) (*BroadcasterAccount, error) {
 	return b.accounts[index], nil
 }
 
+// AccountByLane returns the account serving the given lane.
+// If there is no route for the lane, it returns ErrKeyNotSet.
+func (b Broadcaster) AccountByLane(lane string) (*BroadcasterAccount, error) {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+	index, ok := b.laneRoutes[lane]
+	if !ok {
+		return nil, types.ErrKeyNotSet
+	}
+	return b.accounts[index], nil
+}
+
+// AccountByAddress returns the account of the address, including the accounts retired by the key rotation.
 func (b Broadcaster) AccountByAddress(address string) (*BroadcasterAccount, error) {
 	b.accountMu.Lock()
 	defer b.accountMu.Unlock()
-	if _, ok := b.addressAccountMap[address]; !ok {
-		return nil, fmt.Errorf("broadcaster account not found; address: %s", address)
+	if index, ok := b.addressAccountMap[address]; ok {
+		return b.accounts[index], nil
+	} else if account, ok := b.retiredAccounts[address]; ok {
+		return account, nil
 	}
-	return b.accounts[b.addressAccountMap[address]], nil
+	return nil, fmt.Errorf("broadcaster account not found; address: %s", address)
+}
+
+// AccountByMsgType returns the account routed to the given msg type url.
+// If there is no route for the msg type, it returns ErrKeyNotSet.
+func (b Broadcaster) AccountByMsgType(msgType string) (*BroadcasterAccount, error) {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+	index, ok := b.msgTypeRoutes[msgType]
+	if !ok {
+		return nil, types.ErrKeyNotSet
+	}
+	return b.accounts[index], nil
 }
@@ -1,8 +1,10 @@
 package broadcaster
 
 import (
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 	"github.com/initia-labs/opinit-bots/types"
 )
@@ -72,9 +74,17 @@ func (b Broadcaster) PendingTxsToRawKV(txInfos []btypes.PendingTxInfo, delete bo
 
 // ProcessedMsgsToRawKV converts processed data to raw kv pairs.
 // If delete is true, it will return kv pairs for deletion (empty value).
+// The processed msgs to be saved without a counter are assigned the next counters in place, so the caller
+// must broadcast the same processed msgs to delete them by the same key later.
 func (b Broadcaster) ProcessedMsgsToRawKV(ProcessedMsgs []btypes.ProcessedMsgs, delete bool) ([]types.RawKV, error) {
+	// the msgs are recorded on the broadcast instead in the dry-run mode
+	if b.cfg.DryRun && !delete {
+		return nil, nil
+	}
+
 	kvs := make([]types.RawKV, 0, len(ProcessedMsgs))
-	for _, processedMsgs := range ProcessedMsgs {
+	lastCounter := uint64(0)
+	for i, processedMsgs := range ProcessedMsgs {
 		var data []byte
 		var err error
 
@@ -83,27 +93,60 @@ func (b Broadcaster) ProcessedMsgsToRawKV(ProcessedMsgs []btypes.ProcessedMsgs,
 				continue
 			}
 
+			if processedMsgs.Counter == 0 {
+				lastCounter = b.processedMsgsCounter.Add(1)
+				ProcessedMsgs[i].Counter = lastCounter
+				processedMsgs.Counter = lastCounter
+			}
+
+			processedMsgs = processedMsgs.WithTraceID()
+			processedMsgs.EffectKeys, err = b.effectKeys(processedMsgs.Msgs)
+			if err != nil {
+				return nil, err
+			}
+
 			data, err = processedMsgs.MarshalInterfaceJSON(b.cdc)
 			if err != nil {
 				return nil, err
 			}
 		}
 		kvs = append(kvs, types.RawKV{
-			Key:   b.db.PrefixedKey(btypes.PrefixedProcessedMsgs(types.MustInt64ToUint64(processedMsgs.Timestamp))),
+			Key:   b.db.PrefixedKey(processedMsgs.Key()),
 			Value: data,
 		})
 	}
+
+	// persist the last counter with the msgs, so the counter doesn't go back after the restart
+	if lastCounter != 0 {
+		kvs = append(kvs, types.RawKV{
+			Key:   b.db.PrefixedKey(btypes.LastProcessedMsgsCounterKey),
+			Value: dbtypes.FromUint64(lastCounter),
+		})
+	}
 	return kvs, nil
 }
 
-// currently no use case, but keep it for future use
-// func (n *Broadcaster) saveProcessedMsgs(processedMsgs btypes.ProcessedMsgs) error {
-// 	data, err := processedMsgs.Marshal()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	return b.db.Set(btypes.PrefixedProcessedMsgs(uint64(processedMsgs.Timestamp)), data)
-// }
+// saveProcessedMsgs saves the processed msgs, and returns them with the assigned counter.
+func (b Broadcaster) saveProcessedMsgs(processedMsgs btypes.ProcessedMsgs) (btypes.ProcessedMsgs, error) {
+	processedMsgs.Save = true
+	processedMsgsList := []btypes.ProcessedMsgs{processedMsgs}
+	kvs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+	if err != nil {
+		return processedMsgs, err
+	}
+	return processedMsgsList[0], b.db.RawBatchSet(kvs...)
+}
+
+func (b Broadcaster) loadProcessedMsgsByKey(key []byte) (btypes.ProcessedMsgs, error) {
+	data, err := b.db.Get(key)
+	if err != nil {
+		return btypes.ProcessedMsgs{}, err
+	}
+
+	var processedMsgs btypes.ProcessedMsgs
+	err = processedMsgs.UnmarshalInterfaceJSON(b.cdc, data)
+	return processedMsgs, err
+}
 
 func (b Broadcaster) loadProcessedMsgs() (ProcessedMsgs []btypes.ProcessedMsgs, err error) {
 	iterErr := b.db.PrefixedIterate(btypes.ProcessedMsgsKey, nil, func(_, value []byte) (stop bool, err error) {
@@ -123,6 +166,40 @@ func (b Broadcaster) loadProcessedMsgs() (ProcessedMsgs []btypes.ProcessedMsgs,
 	return ProcessedMsgs, nil
 }
 
-func (b Broadcaster) deleteProcessedMsgs(timestamp int64) error {
-	return b.db.Delete(btypes.PrefixedProcessedMsgs(types.MustInt64ToUint64(timestamp)))
+func (b Broadcaster) deleteProcessedMsgs(processedMsgs btypes.ProcessedMsgs) error {
+	return b.db.Delete(processedMsgs.Key())
+}
+
+// loadProcessedMsgsCounter returns the last counter assigned to the processed msgs.
+func (b Broadcaster) loadProcessedMsgsCounter() (uint64, error) {
+	data, err := b.db.Get(btypes.LastProcessedMsgsCounterKey)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return dbtypes.ToUint64(data)
+}
+
+// UnconfirmedTraceIDs returns the trace ids of the saved msgs which are not included in a block yet,
+// either waiting to be broadcasted or pending in the mempool.
+func (b Broadcaster) UnconfirmedTraceIDs() (map[string]struct{}, error) {
+	traceIDs := make(map[string]struct{})
+
+	pendingTxs, err := b.loadPendingTxs()
+	if err != nil {
+		return nil, err
+	}
+	for _, pendingTx := range pendingTxs {
+		traceIDs[pendingTx.TraceID] = struct{}{}
+	}
+
+	processedMsgsList, err := b.loadProcessedMsgs()
+	if err != nil {
+		return nil, err
+	}
+	for _, processedMsgs := range processedMsgsList {
+		traceIDs[processedMsgs.TraceID] = struct{}{}
+	}
+	return traceIDs, nil
 }
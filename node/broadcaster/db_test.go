@@ -0,0 +1,84 @@
+package broadcaster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+func Test_ProcessedMsgsSameTimestamp(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 1, "sender")
+	sender := addresses[0]
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+
+	// the bundles created at the same nanosecond are all overflowed to the db
+	count := 100
+	b.BroadcastMsgs(btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 1), Timestamp: 1})
+	for i := 1; i <= count; i++ {
+		b.BroadcastMsgs(btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, i), Timestamp: 1, Save: true})
+	}
+	require.Equal(t, count, b.LenOverflowedMsgs())
+
+	saved, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, saved, count)
+
+	// every bundle is refilled in order
+	<-lane.txChannel
+	for i := 1; i <= count; i++ {
+		require.NoError(t, b.refillTxChannel(lane))
+		msgs := <-lane.txChannel
+		require.Len(t, msgs.Msgs, i)
+		require.Equal(t, uint64(i), msgs.Counter)
+		require.NoError(t, b.deleteProcessedMsgs(msgs))
+	}
+	saved, err = b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Empty(t, saved)
+
+	// the counter is restored after the restart
+	restarted, err := NewBroadcaster(b.cfg, b.db, zap.NewNop(), b.cdc, b.txConfig, b.rpcClient)
+	require.NoError(t, err)
+	processedMsgsList := []btypes.ProcessedMsgs{{Sender: sender, Msgs: testSendMsgs(sender, 1), Timestamp: 1, Save: true}}
+	kvs, err := restarted.ProcessedMsgsToRawKV(processedMsgsList, false)
+	require.NoError(t, err)
+	require.NoError(t, restarted.db.RawBatchSet(kvs...))
+	require.Equal(t, uint64(count+1), processedMsgsList[0].Counter)
+	_, err = restarted.loadProcessedMsgsByKey(processedMsgsList[0].Key())
+	require.NoError(t, err)
+}
+
+func Test_LegacyProcessedMsgs(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 1, "sender")
+	sender := addresses[0]
+
+	// the processed msgs saved before the counter was introduced
+	legacy := btypes.ProcessedMsgs{
+		Sender:    sender,
+		Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: sender, ToAddress: sender}},
+		Timestamp: 1,
+		Save:      true,
+	}.WithTraceID()
+	data, err := legacy.MarshalInterfaceJSON(b.cdc)
+	require.NoError(t, err)
+	require.NoError(t, b.db.Set(btypes.PrefixedProcessedMsgs(1), data))
+
+	saved, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, uint64(0), saved[0].Counter)
+	require.Equal(t, legacy.TraceID, saved[0].TraceID)
+
+	// the legacy msgs are deleted by the legacy key
+	require.NoError(t, b.deleteProcessedMsgs(saved[0]))
+	_, err = b.loadProcessedMsgsByKey(btypes.PrefixedProcessedMsgs(1))
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+}
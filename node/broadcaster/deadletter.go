@@ -0,0 +1,229 @@
+package broadcaster
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pkg/errors"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/rpcclient"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// errSimulationFailed is the error of the processed msgs failing the simulation before they are signed.
+var errSimulationFailed = errors.New("simulation failed")
+
+// parkFailingMsgs bisects the processed msgs by the simulation to find the msgs failing on their own,
+// and parks them to the dead letters, so the rest of the msgs are not blocked by them. The msgs which
+// don't need to be saved are dropped instead of being parked.
+// It returns false if the failing msgs can't be isolated, e.g. all msgs fail or the node is unreachable.
+func (b *Broadcaster) parkFailingMsgs(ctx context.Context, data btypes.ProcessedMsgs, broadcasterAccount *BroadcasterAccount, err error) (btypes.ProcessedMsgs, bool, error) {
+	if len(data.Msgs) < 2 {
+		return data, false, nil
+	}
+
+	// the msgs are parked only if the chain rejects them, not on the transient errors of the node
+	if !isSimulationFailure(err) {
+		return data, false, nil
+	}
+
+	failed := make(map[int]error)
+	if !b.bisectFailingMsgs(ctx, broadcasterAccount, data.Msgs, 0, err, failed) || len(failed) == 0 || len(failed) == len(data.Msgs) {
+		return data, false, nil
+	}
+
+	parkedAt := time.Now()
+	remaining := data
+	remaining.Msgs = make([]sdk.Msg, 0, len(data.Msgs)-len(failed))
+	deadLetters := make([]btypes.DeadLetter, 0, len(failed))
+	for i, msg := range data.Msgs {
+		simErr, ok := failed[i]
+		if !ok {
+			remaining.Msgs = append(remaining.Msgs, msg)
+			continue
+		}
+
+		b.logger.Warn("isolate msg failing the simulation",
+			zap.String("trace_id", data.TraceID),
+			zap.String("msg_type", sdk.MsgTypeURL(msg)),
+			zap.Bool("parked", data.Save),
+			zap.String("error", simErr.Error()),
+		)
+		if !data.Save {
+			continue
+		}
+
+		msgBz, err := b.cdc.MarshalInterfaceJSON(msg)
+		if err != nil {
+			return data, false, err
+		}
+		deadLetters = append(deadLetters, btypes.DeadLetter{
+			ID:       types.MustInt64ToUint64(parkedAt.UnixNano()) + uint64(len(deadLetters)),
+			Sender:   data.Sender,
+			TraceID:  data.TraceID,
+			Lane:     data.Lane,
+			MsgType:  sdk.MsgTypeURL(msg),
+			Msg:      msgBz,
+			Error:    simErr.Error(),
+			ParkedAt: parkedAt,
+		})
+	}
+
+	// replace the processed msgs with the remaining msgs atomically not to broadcast the parked msgs after the restart
+	kvs, err := b.DeadLettersToRawKV(deadLetters, false)
+	if err != nil {
+		return data, false, err
+	}
+	remainingList := []btypes.ProcessedMsgs{remaining}
+	processedMsgsKVs, err := b.ProcessedMsgsToRawKV(remainingList, false)
+	if err != nil {
+		return data, false, err
+	}
+	err = b.db.RawBatchSet(append(kvs, processedMsgsKVs...)...)
+	if err != nil {
+		return data, false, err
+	}
+	return remainingList[0], true, nil
+}
+
+// bisectFailingMsgs collects the errors of the msgs failing the simulation on their own by their indexes.
+// The given msgs are known to fail together with the given error. It returns false if the bisection is
+// interrupted, e.g. by a transient error of the node, which doesn't tell whether the msgs fail.
+func (b *Broadcaster) bisectFailingMsgs(ctx context.Context, broadcasterAccount *BroadcasterAccount, msgs []sdk.Msg, offset int, err error, failed map[int]error) bool {
+	if len(msgs) == 1 {
+		failed[offset] = err
+		return true
+	}
+
+	mid := len(msgs) / 2
+	for _, half := range []struct {
+		msgs   []sdk.Msg
+		offset int
+	}{{msgs[:mid], offset}, {msgs[mid:], offset + mid}} {
+		if ctx.Err() != nil {
+			return false
+		}
+		_, _, simErr := broadcasterAccount.BuildTxWithMessages(ctx, half.msgs)
+		if simErr == nil {
+			continue
+		} else if !isSimulationFailure(simErr) {
+			b.logger.Warn("failed to bisect the msgs failing the simulation", zap.String("error", simErr.Error()))
+			return false
+		} else if !b.bisectFailingMsgs(ctx, broadcasterAccount, half.msgs, half.offset, simErr, failed) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSimulationFailure returns true if the simulation is rejected by the chain, which fails the same msgs
+// deterministically, unlike the rpc errors of the node.
+func isSimulationFailure(err error) bool {
+	var queryErr rpcclient.ABCIQueryError
+	return errors.As(err, &queryErr)
+}
+
+// DeadLettersToRawKV converts the dead letters to raw kv pairs.
+// If delete is true, it will return kv pairs for deletion (empty value).
+func (b Broadcaster) DeadLettersToRawKV(deadLetters []btypes.DeadLetter, delete bool) ([]types.RawKV, error) {
+	kvs := make([]types.RawKV, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		var data []byte
+		var err error
+
+		if !delete {
+			data, err = deadLetter.Marshal()
+			if err != nil {
+				return nil, err
+			}
+		}
+		kvs = append(kvs, types.RawKV{
+			Key:   b.db.PrefixedKey(btypes.PrefixedDeadLetter(deadLetter.ID)),
+			Value: data,
+		})
+	}
+	return kvs, nil
+}
+
+// DeadLetters returns the parked msgs in the order they were parked.
+func (b Broadcaster) DeadLetters() ([]btypes.DeadLetter, error) {
+	deadLetters := make([]btypes.DeadLetter, 0)
+	err := b.db.PrefixedIterate(btypes.DeadLettersKey, nil, func(_, value []byte) (stop bool, err error) {
+		var deadLetter btypes.DeadLetter
+		if err := deadLetter.Unmarshal(value); err != nil {
+			return true, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
+
+// DeadLetterTraceIDs returns the trace ids of the processed msgs whose msgs are parked.
+func (b Broadcaster) DeadLetterTraceIDs() (map[string]struct{}, error) {
+	deadLetters, err := b.DeadLetters()
+	if err != nil {
+		return nil, err
+	}
+	traceIDs := make(map[string]struct{}, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		traceIDs[deadLetter.TraceID] = struct{}{}
+	}
+	return traceIDs, nil
+}
+
+// RetryDeadLetter moves the parked msg back to the processed msgs and broadcasts it again.
+func (b *Broadcaster) RetryDeadLetter(id uint64) (btypes.ProcessedMsgs, error) {
+	data, err := b.db.Get(btypes.PrefixedDeadLetter(id))
+	if err != nil {
+		return btypes.ProcessedMsgs{}, err
+	}
+	var deadLetter btypes.DeadLetter
+	if err := deadLetter.Unmarshal(data); err != nil {
+		return btypes.ProcessedMsgs{}, err
+	}
+
+	var msg sdk.Msg
+	if err := b.cdc.UnmarshalInterfaceJSON(deadLetter.Msg, &msg); err != nil {
+		return btypes.ProcessedMsgs{}, errors.Wrap(err, "failed to unmarshal the parked msg")
+	}
+	processedMsgs := btypes.ProcessedMsgs{
+		Sender:    deadLetter.Sender,
+		Msgs:      []sdk.Msg{msg},
+		Timestamp: time.Now().UnixNano(),
+		TraceID:   deadLetter.TraceID,
+		Lane:      deadLetter.Lane,
+		Save:      true,
+	}
+
+	// replace the dead letter with the processed msgs atomically
+	kvs, err := b.DeadLettersToRawKV([]btypes.DeadLetter{deadLetter}, true)
+	if err != nil {
+		return btypes.ProcessedMsgs{}, err
+	}
+	processedMsgsList := []btypes.ProcessedMsgs{processedMsgs}
+	processedMsgsKVs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+	if err != nil {
+		return btypes.ProcessedMsgs{}, err
+	}
+	err = b.db.RawBatchSet(append(kvs, processedMsgsKVs...)...)
+	if err != nil {
+		return btypes.ProcessedMsgs{}, err
+	}
+	processedMsgs = processedMsgsList[0]
+
+	b.logger.Info("retry parked msg", zap.Uint64("id", id), zap.String("trace_id", deadLetter.TraceID), zap.String("msg_type", deadLetter.MsgType))
+	err = b.broadcastMsgs(processedMsgs)
+	if err != nil {
+		return btypes.ProcessedMsgs{}, err
+	}
+	return processedMsgs, nil
+}
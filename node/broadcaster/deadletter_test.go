@@ -0,0 +1,167 @@
+package broadcaster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	"github.com/initia-labs/opinit-bots/node/rpcclient"
+)
+
+// newDeadLetterTest returns the broadcaster isolating the failing msgs with the mock chain,
+// where the msg sending 3uinit fails the simulation.
+func newDeadLetterTest(t *testing.T) (*Broadcaster, *BroadcasterAccount, *mockChain) {
+	b, _ := newTestBroadcaster(t, 10, "sender")
+	b.cfg.IsolateFailingMsgs = true
+
+	chain := newMockChain(t, b, 0)
+	chain.simulate = func(tx sdk.Tx) error {
+		for _, msg := range tx.GetMsgs() {
+			if msg.(*banktypes.MsgSend).Amount.AmountOf("uinit").Int64() == 3 {
+				return errors.New("failed to execute message; message index: 0: deposit already finalized")
+			}
+		}
+		return nil
+	}
+	return b, b.accounts[0], chain
+}
+
+func testSendMsgs(sender string, count int) []sdk.Msg {
+	msgs := make([]sdk.Msg, 0, count)
+	for i := 1; i <= count; i++ {
+		msgs = append(msgs, &banktypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uinit", int64(i)))})
+	}
+	return msgs
+}
+
+func sentAmounts(msgs []sdk.Msg) []int64 {
+	amounts := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		amounts = append(amounts, msg.(*banktypes.MsgSend).Amount.AmountOf("uinit").Int64())
+	}
+	return amounts
+}
+
+func Test_IsolateFailingMsgs(t *testing.T) {
+	b, account, chain := newDeadLetterTest(t)
+	sender := account.GetAddressString()
+
+	data := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 10), Timestamp: 1, Save: true}.WithTraceID()
+	data, err := b.saveProcessedMsgs(data)
+	require.NoError(t, err)
+
+	stop, err := b.handleProcessedMsgsWithRetry(context.Background(), data, account)
+	require.NoError(t, err)
+	require.False(t, stop)
+
+	// the remaining 9 msgs are broadcasted in a tx
+	require.Len(t, chain.broadcasted, 1)
+	require.Equal(t, []int64{1, 2, 4, 5, 6, 7, 8, 9, 10}, sentAmounts(chain.broadcasted[0].GetMsgs()))
+	trace, err := b.TraceTx(data.TraceID)
+	require.NoError(t, err)
+	require.Empty(t, trace.ProcessedMsgs)
+	require.Len(t, trace.PendingTxs, 1)
+
+	// the 3rd msg is parked
+	deadLetters, err := b.DeadLetters()
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, sender, deadLetters[0].Sender)
+	require.Equal(t, data.TraceID, deadLetters[0].TraceID)
+	require.Equal(t, sdk.MsgTypeURL(&banktypes.MsgSend{}), deadLetters[0].MsgType)
+	require.Contains(t, deadLetters[0].Error, "deposit already finalized")
+
+	// the retried msg is moved back to the processed msgs
+	id := deadLetters[0].ID
+	retried, err := b.RetryDeadLetter(id)
+	require.NoError(t, err)
+	require.Equal(t, []int64{3}, sentAmounts(retried.Msgs))
+	deadLetters, err = b.DeadLetters()
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	queued := <-lane.txChannel
+	require.Equal(t, data.TraceID, queued.TraceID)
+	require.Equal(t, []int64{3}, sentAmounts(queued.Msgs))
+	_, err = b.loadProcessedMsgsByKey(retried.Key())
+	require.NoError(t, err)
+
+	_, err = b.RetryDeadLetter(id)
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+}
+
+func Test_IsolateFailingMsgsNotSaved(t *testing.T) {
+	b, account, chain := newDeadLetterTest(t)
+	sender := account.GetAddressString()
+
+	// the msgs which don't need to be saved are dropped instead of being parked
+	data := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 4), Timestamp: 1}.WithTraceID()
+	_, err := b.handleProcessedMsgsWithRetry(context.Background(), data, account)
+	require.NoError(t, err)
+
+	require.Len(t, chain.broadcasted, 1)
+	require.Equal(t, []int64{1, 2, 4}, sentAmounts(chain.broadcasted[0].GetMsgs()))
+	deadLetters, err := b.DeadLetters()
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+}
+
+func Test_IsolateFailingMsgsAllFailed(t *testing.T) {
+	b, account, _ := newDeadLetterTest(t)
+	sender := account.GetAddressString()
+
+	// nothing is isolated if all msgs fail
+	failing := &banktypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uinit", 3))}
+	data := btypes.ProcessedMsgs{Sender: sender, Msgs: []sdk.Msg{failing, failing}, Timestamp: 1, Save: true}.WithTraceID()
+	simErr := rpcclient.ABCIQueryError{Code: 1, Log: "deposit already finalized"}
+	remaining, isolated, err := b.parkFailingMsgs(context.Background(), data, account, simErr)
+	require.NoError(t, err)
+	require.False(t, isolated)
+	require.Equal(t, data, remaining)
+
+	// a single msg can't be isolated from others
+	data.Msgs = data.Msgs[:1]
+	_, isolated, err = b.parkFailingMsgs(context.Background(), data, account, simErr)
+	require.NoError(t, err)
+	require.False(t, isolated)
+
+	deadLetters, err := b.DeadLetters()
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+}
+
+func Test_IsolateFailingMsgsTransientErr(t *testing.T) {
+	b, account, chain := newDeadLetterTest(t)
+	sender := account.GetAddressString()
+	data := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 4), Timestamp: 1, Save: true}.WithTraceID()
+
+	// the rpc error of the node is not a failure of the msgs
+	_, isolated, err := b.parkFailingMsgs(context.Background(), data, account, errors.New("connection refused"))
+	require.NoError(t, err)
+	require.False(t, isolated)
+
+	// the bisection interrupted by the rpc error parks nothing
+	chain.SetCallHook(func(_ context.Context, method string) error {
+		if method == nodetest.MethodABCIQuery {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	_, isolated, err = b.parkFailingMsgs(context.Background(), data, account, rpcclient.ABCIQueryError{Code: 1, Log: "deposit already finalized"})
+	require.NoError(t, err)
+	require.False(t, isolated)
+
+	deadLetters, err := b.DeadLetters()
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+}
@@ -0,0 +1,69 @@
+package broadcaster
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// recordDryRunMsgs logs and records the msgs which would be broadcasted in the dry-run mode.
+func (b Broadcaster) recordDryRunMsgs(msgs btypes.ProcessedMsgs) {
+	record := btypes.DryRunRecord{
+		Sender:     msgs.Sender,
+		TraceID:    msgs.TraceID,
+		Lane:       msgs.Lane,
+		MsgTypes:   msgs.GetMsgTypes(),
+		Msgs:       make([]json.RawMessage, 0, len(msgs.Msgs)),
+		RecordedAt: time.Now(),
+	}
+	for _, msg := range msgs.Msgs {
+		msgBz, err := b.cdc.MarshalInterfaceJSON(msg)
+		if err != nil {
+			b.logger.Error("failed to record dry-run msgs", zap.String("trace_id", msgs.TraceID), zap.String("error", err.Error()))
+			return
+		}
+		record.Msgs = append(record.Msgs, msgBz)
+	}
+
+	data, err := record.Marshal()
+	if err == nil {
+		key := btypes.PrefixedDryRunRecord(types.MustInt64ToUint64(record.RecordedAt.UnixNano()), b.processedMsgsCounter.Add(1))
+		err = b.db.Set(key, data)
+	}
+	if err != nil {
+		b.logger.Error("failed to record dry-run msgs", zap.String("trace_id", msgs.TraceID), zap.String("error", err.Error()))
+		return
+	}
+	b.logger.Info("dry-run: record msgs instead of broadcasting",
+		zap.String("trace_id", msgs.TraceID),
+		zap.String("sender", msgs.Sender),
+		zap.Strings("msg_types", record.MsgTypes),
+	)
+}
+
+// DryRunRecords returns the msgs recorded in the dry-run mode in the order they were recorded.
+func (b Broadcaster) DryRunRecords() ([]btypes.DryRunRecord, error) {
+	return LoadDryRunRecords(b.db)
+}
+
+// LoadDryRunRecords returns the msgs recorded in the dry-run mode from the db of the node,
+// so they can be inspected after the bot is stopped.
+func LoadDryRunRecords(db types.DB) ([]btypes.DryRunRecord, error) {
+	records := make([]btypes.DryRunRecord, 0)
+	err := db.PrefixedIterate(btypes.DryRunRecordsKey, nil, func(_, value []byte) (stop bool, err error) {
+		var record btypes.DryRunRecord
+		if err := record.Unmarshal(value); err != nil {
+			return true, err
+		}
+		records = append(records, record)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,47 @@
+package broadcaster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+func Test_DryRun(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 1, "sender")
+	sender := addresses[0]
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	b.cfg.DryRun = true
+
+	// the handlers of the blocks save and broadcast their msgs
+	blocks := 5
+	for i := 1; i <= blocks; i++ {
+		processedMsgsList := []btypes.ProcessedMsgs{{Sender: sender, Msgs: testSendMsgs(sender, i), Timestamp: int64(i), Save: true}}
+		kvs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+		require.NoError(t, err)
+		require.Empty(t, kvs)
+		b.BroadcastMsgs(processedMsgsList[0])
+	}
+
+	// nothing is queued or saved to be broadcasted after the dry-run
+	require.Empty(t, lane.txChannel)
+	require.Equal(t, 0, b.LenOverflowedMsgs())
+	saved, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Empty(t, saved)
+	pendingTxs, err := b.loadPendingTxs()
+	require.NoError(t, err)
+	require.Empty(t, pendingTxs)
+
+	records, err := b.DryRunRecords()
+	require.NoError(t, err)
+	require.Len(t, records, blocks)
+	for i, record := range records {
+		require.Equal(t, sender, record.Sender)
+		require.NotEmpty(t, record.TraceID)
+		require.Len(t, record.Msgs, i+1)
+		require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", record.MsgTypes[0])
+	}
+}
@@ -0,0 +1,92 @@
+package broadcaster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// RegisterEffectChecker registers the checker of the msg type.
+// It should be called before the broadcaster is initialized.
+func (b *Broadcaster) RegisterEffectChecker(msgType string, checker btypes.EffectChecker) {
+	b.effectCheckers[msgType] = checker
+}
+
+// effectKeys returns the effect keys of the msgs.
+// If no msg has an effect checker, it returns nil.
+func (b Broadcaster) effectKeys(msgs []sdk.Msg) ([]string, error) {
+	var keys []string
+	for i, msg := range msgs {
+		checker, ok := b.effectCheckers[sdk.MsgTypeURL(msg)]
+		if !ok {
+			continue
+		}
+
+		key, err := checker.EffectKey(msg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get effect key")
+		}
+		if keys == nil {
+			keys = make([]string, len(msgs))
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// filterLandedProcessedMsgs removes the msgs whose effects already exist on the chain,
+// and drops the processed msgs which have no msgs left.
+func (b Broadcaster) filterLandedProcessedMsgs(ctx context.Context, processedMsgsList []btypes.ProcessedMsgs) ([]btypes.ProcessedMsgs, error) {
+	if len(b.effectCheckers) == 0 {
+		return processedMsgsList, nil
+	}
+
+	filtered := make([]btypes.ProcessedMsgs, 0, len(processedMsgsList))
+	for _, processedMsgs := range processedMsgsList {
+		effectKeys, err := b.effectKeys(processedMsgs.Msgs)
+		if err != nil {
+			return nil, err
+		}
+
+		msgs := make([]sdk.Msg, 0, len(processedMsgs.Msgs))
+		for i, msg := range processedMsgs.Msgs {
+			checker, ok := b.effectCheckers[sdk.MsgTypeURL(msg)]
+			if !ok {
+				msgs = append(msgs, msg)
+				continue
+			}
+
+			exists, err := checker.EffectExists(ctx, msg)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to check msg effect")
+			} else if !exists {
+				msgs = append(msgs, msg)
+				continue
+			}
+
+			b.logger.Info("skip msg already landed",
+				zap.String("trace_id", processedMsgs.TraceID),
+				zap.String("sender", processedMsgs.Sender),
+				zap.String("msg_type", sdk.MsgTypeURL(msg)),
+				zap.String("effect_key", effectKeys[i]),
+			)
+		}
+
+		if len(msgs) == 0 {
+			continue
+		} else if len(msgs) != len(processedMsgs.Msgs) {
+			processedMsgs.Msgs = msgs
+			processedMsgs.EffectKeys, err = b.effectKeys(msgs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		filtered = append(filtered, processedMsgs)
+	}
+	return filtered, nil
+}
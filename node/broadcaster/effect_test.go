@@ -0,0 +1,74 @@
+package broadcaster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// mockEffectChecker treats the amount of MsgSend as the effect key.
+type mockEffectChecker struct {
+	landed map[int64]bool
+}
+
+func (c mockEffectChecker) EffectKey(msg sdk.Msg) (string, error) {
+	return fmt.Sprintf("amount/%d", msg.(*banktypes.MsgSend).Amount.AmountOf("uinit").Int64()), nil
+}
+
+func (c mockEffectChecker) EffectExists(_ context.Context, msg sdk.Msg) (bool, error) {
+	return c.landed[msg.(*banktypes.MsgSend).Amount.AmountOf("uinit").Int64()], nil
+}
+
+func Test_EffectChecker(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	b.RegisterEffectChecker(sdk.MsgTypeURL(&banktypes.MsgSend{}), mockEffectChecker{
+		landed: map[int64]bool{1: true, 2: true, 3: true},
+	})
+
+	newMsg := func(amount int64) sdk.Msg {
+		return &banktypes.MsgSend{
+			FromAddress: sender,
+			ToAddress:   sender,
+			Amount:      sdk.NewCoins(sdk.NewInt64Coin("uinit", amount)),
+		}
+	}
+	processedMsgsList := []btypes.ProcessedMsgs{
+		// already landed
+		{Sender: sender, Msgs: []sdk.Msg{newMsg(1), newMsg(2)}, Timestamp: 1, Save: true},
+		// partially landed
+		{Sender: sender, Msgs: []sdk.Msg{newMsg(3), newMsg(4)}, Timestamp: 2, Save: true},
+		// not landed
+		{Sender: sender, Msgs: []sdk.Msg{newMsg(5)}, Timestamp: 3, Save: true},
+	}
+
+	// effect keys are recorded with the processed msgs
+	kvs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+	require.NoError(t, err)
+	require.NoError(t, b.db.RawBatchSet(kvs...))
+
+	loaded, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, loaded, 3)
+	require.Equal(t, []string{"amount/1", "amount/2"}, loaded[0].EffectKeys)
+
+	filtered, err := b.filterLandedProcessedMsgs(context.Background(), loaded)
+	require.NoError(t, err)
+	require.Len(t, filtered, 2)
+
+	require.Equal(t, int64(2), filtered[0].Timestamp)
+	require.Len(t, filtered[0].Msgs, 1)
+	require.Equal(t, []string{"amount/4"}, filtered[0].EffectKeys)
+
+	require.Equal(t, int64(3), filtered[1].Timestamp)
+	require.Len(t, filtered[1].Msgs, 1)
+	require.Equal(t, []string{"amount/5"}, filtered[1].EffectKeys)
+}
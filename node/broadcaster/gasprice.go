@@ -0,0 +1,187 @@
+package broadcaster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/cosmos/gogoproto/proto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// feemarketGasPriceQueryPath is the abci query path of the gas price of the feemarket module.
+const feemarketGasPriceQueryPath = "/feemarket.feemarket.v1.Query/GasPrice"
+
+// GasPriceSource provides the gas prices of the txs at the signing time.
+type GasPriceSource interface {
+	GasPrices(ctx context.Context) (sdk.DecCoins, error)
+}
+
+// newGasPriceSource returns the gas price source of the config.
+func newGasPriceSource(cfg btypes.BroadcasterConfig, querier btypes.Querier, logger *zap.Logger) (GasPriceSource, error) {
+	gasPrices, err := sdk.ParseDecCoins(cfg.GasPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.GasPriceSource {
+	case btypes.GasPriceSourceFeemarket:
+		if len(gasPrices) == 0 {
+			return nil, errors.New("gas price is required to decide the fee denom of the feemarket")
+		}
+		tipMultiplier, err := floatToDec(cfg.GetFeemarketTipMultiplier())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse feemarket tip multiplier")
+		}
+		hysteresis, err := floatToDec(cfg.GetGasPriceHysteresis())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse gas price hysteresis")
+		}
+		return &feemarketGasPriceSource{
+			querier:         querier,
+			logger:          logger,
+			fallback:        gasPrices,
+			tipMultiplier:   tipMultiplier,
+			hysteresis:      hysteresis,
+			refreshInterval: cfg.GetGasPriceRefreshInterval(),
+			mu:              &sync.Mutex{},
+		}, nil
+	default:
+		return staticGasPriceSource{gasPrices: gasPrices}, nil
+	}
+}
+
+// staticGasPriceSource always provides the configured gas prices.
+type staticGasPriceSource struct {
+	gasPrices sdk.DecCoins
+}
+
+func (s staticGasPriceSource) GasPrices(_ context.Context) (sdk.DecCoins, error) {
+	return s.gasPrices, nil
+}
+
+// feemarketGasPriceSource follows the base fee of the feemarket module of the chain. The base fee is
+// queried at most once per refresh interval and multiplied by the tip multiplier. To avoid changing
+// the fees of every tx, the gas price is only updated when the new price deviates from the current
+// price by more than the hysteresis, or when the base fee exceeds the current price.
+type feemarketGasPriceSource struct {
+	querier         btypes.Querier
+	logger          *zap.Logger
+	fallback        sdk.DecCoins
+	tipMultiplier   sdkmath.LegacyDec
+	hysteresis      sdkmath.LegacyDec
+	refreshInterval time.Duration
+
+	mu        *sync.Mutex
+	gasPrice  *sdk.DecCoin
+	fetchedAt time.Time
+}
+
+// GasPrices returns the gas price following the base fee. The configured gas price is returned until
+// the base fee is queried successfully, and the last gas price is kept while the query fails.
+func (s *feemarketGasPriceSource) GasPrices(ctx context.Context) (sdk.DecCoins, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.gasPrice != nil && time.Since(s.fetchedAt) < s.refreshInterval {
+		return sdk.NewDecCoins(*s.gasPrice), nil
+	}
+
+	baseFee, err := s.queryBaseFee(ctx)
+	if err != nil {
+		s.logger.Warn("failed to query feemarket gas price", zap.String("error", err.Error()))
+		if s.gasPrice == nil {
+			return s.fallback, nil
+		}
+		return sdk.NewDecCoins(*s.gasPrice), nil
+	}
+	s.fetchedAt = time.Now()
+
+	target := sdk.NewDecCoinFromDec(baseFee.Denom, baseFee.Amount.Mul(s.tipMultiplier))
+	if s.gasPrice == nil || s.gasPrice.Denom != target.Denom ||
+		baseFee.Amount.GT(s.gasPrice.Amount) ||
+		target.Amount.GT(s.gasPrice.Amount.Mul(sdkmath.LegacyOneDec().Add(s.hysteresis))) ||
+		target.Amount.LT(s.gasPrice.Amount.Mul(sdkmath.LegacyOneDec().Sub(s.hysteresis))) {
+		s.logger.Info("gas price updated",
+			zap.String("base_fee", baseFee.String()),
+			zap.String("gas_price", target.String()),
+		)
+		s.gasPrice = &target
+	}
+	return sdk.NewDecCoins(*s.gasPrice), nil
+}
+
+// queryBaseFee queries the gas price of the fee denom from the feemarket module.
+func (s *feemarketGasPriceSource) queryBaseFee(ctx context.Context) (sdk.DecCoin, error) {
+	res, err := s.querier.QueryABCI(ctx, abci.RequestQuery{
+		Path: feemarketGasPriceQueryPath,
+		Data: marshalFeemarketGasPriceRequest(s.fallback[0].Denom),
+	})
+	if err != nil {
+		return sdk.DecCoin{}, err
+	} else if res.Code != 0 {
+		return sdk.DecCoin{}, fmt.Errorf("query failed with code %d: %s", res.Code, res.Log)
+	}
+
+	baseFee, err := unmarshalFeemarketGasPriceResponse(res.Value)
+	if err != nil {
+		return sdk.DecCoin{}, err
+	} else if baseFee.Amount.IsNil() || baseFee.Amount.IsNegative() {
+		return sdk.DecCoin{}, fmt.Errorf("invalid feemarket gas price: %s", baseFee.String())
+	}
+	return baseFee, nil
+}
+
+// marshalFeemarketGasPriceRequest encodes the GasPriceRequest of the feemarket module, whose only field is the denom.
+func marshalFeemarketGasPriceRequest(denom string) []byte {
+	// field 1, length-delimited
+	data := []byte{0x0a}
+	data = append(data, proto.EncodeVarint(uint64(len(denom)))...)
+	return append(data, denom...)
+}
+
+// unmarshalFeemarketGasPriceResponse decodes the GasPriceResponse of the feemarket module, whose only field is the price.
+func unmarshalFeemarketGasPriceResponse(data []byte) (sdk.DecCoin, error) {
+	for len(data) > 0 {
+		tag, n := proto.DecodeVarint(data)
+		if n == 0 {
+			return sdk.DecCoin{}, errors.New("invalid feemarket gas price response")
+		}
+		data = data[n:]
+
+		// the response only has the length-delimited fields
+		if tag&0x7 != 2 {
+			return sdk.DecCoin{}, fmt.Errorf("unexpected wire type of feemarket gas price response: %d", tag&0x7)
+		}
+		length, n := proto.DecodeVarint(data)
+		if n == 0 || length > uint64(len(data)-n) {
+			return sdk.DecCoin{}, errors.New("invalid feemarket gas price response")
+		}
+		value := data[n : n+int(length)]
+		data = data[n+int(length):]
+
+		if tag>>3 == 1 {
+			var price sdk.DecCoin
+			if err := price.Unmarshal(value); err != nil {
+				return sdk.DecCoin{}, errors.Wrap(err, "failed to unmarshal feemarket gas price")
+			}
+			return price, nil
+		}
+	}
+	return sdk.DecCoin{}, errors.New("feemarket gas price not found in the response")
+}
+
+func floatToDec(f float64) (sdkmath.LegacyDec, error) {
+	return sdkmath.LegacyNewDecFromStr(strconv.FormatFloat(f, 'f', 6, 64))
+}
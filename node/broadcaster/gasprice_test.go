@@ -0,0 +1,133 @@
+package broadcaster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/cosmos/gogoproto/proto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// mockFeemarket serves the base fee of the feemarket module.
+type mockFeemarket struct {
+	baseFee string
+	err     error
+	queries int
+}
+
+func (m *mockFeemarket) QueryABCI(_ context.Context, req abci.RequestQuery) (abci.ResponseQuery, error) {
+	m.queries++
+	if m.err != nil {
+		return abci.ResponseQuery{}, m.err
+	} else if req.Path != feemarketGasPriceQueryPath {
+		return abci.ResponseQuery{Code: 1, Log: "unknown query path"}, nil
+	} else if string(req.Data) != string(marshalFeemarketGasPriceRequest("uinit")) {
+		return abci.ResponseQuery{Code: 1, Log: "unknown denom"}, nil
+	}
+
+	price := sdk.NewDecCoinFromDec("uinit", sdkmath.LegacyMustNewDecFromStr(m.baseFee))
+	bz, err := price.Marshal()
+	if err != nil {
+		return abci.ResponseQuery{}, err
+	}
+	value := append([]byte{0x0a}, proto.EncodeVarint(uint64(len(bz)))...)
+	return abci.ResponseQuery{Value: append(value, bz...)}, nil
+}
+
+func Test_FeemarketGasPriceSource(t *testing.T) {
+	feemarket := &mockFeemarket{err: errors.New("connection refused")}
+	hysteresis := 0.2
+	cfg := btypes.BroadcasterConfig{
+		GasPrice:                "0.15uinit",
+		GasPriceSource:          btypes.GasPriceSourceFeemarket,
+		FeemarketTipMultiplier:  2,
+		GasPriceRefreshInterval: time.Nanosecond,
+		GasPriceHysteresis:      &hysteresis,
+	}
+	source, err := newGasPriceSource(cfg, feemarket, zap.NewNop())
+	require.NoError(t, err)
+
+	gasPrice := func() string {
+		gasPrices, err := source.GasPrices(context.Background())
+		require.NoError(t, err)
+		return gasPrices.String()
+	}
+
+	// the configured gas price is used until the base fee is queried
+	require.Equal(t, "0.150000000000000000uinit", gasPrice())
+
+	// the base fee is multiplied by the tip multiplier
+	feemarket.err = nil
+	feemarket.baseFee = "0.1"
+	require.Equal(t, "0.200000000000000000uinit", gasPrice())
+
+	// the small changes of the base fee are ignored
+	feemarket.baseFee = "0.11"
+	require.Equal(t, "0.200000000000000000uinit", gasPrice())
+	feemarket.baseFee = "0.09"
+	require.Equal(t, "0.200000000000000000uinit", gasPrice())
+
+	// the rising base fee raises the gas price
+	feemarket.baseFee = "0.15"
+	require.Equal(t, "0.300000000000000000uinit", gasPrice())
+
+	// the gas price is raised as soon as it can't cover the base fee, even within the hysteresis
+	source.(*feemarketGasPriceSource).tipMultiplier = sdkmath.LegacyOneDec()
+	feemarket.baseFee = "0.31"
+	require.Equal(t, "0.310000000000000000uinit", gasPrice())
+	source.(*feemarketGasPriceSource).tipMultiplier = sdkmath.LegacyNewDec(2)
+
+	// the falling base fee lowers the gas price
+	feemarket.baseFee = "0.05"
+	require.Equal(t, "0.100000000000000000uinit", gasPrice())
+
+	// the last gas price is kept while the query fails
+	feemarket.err = errors.New("connection refused")
+	require.Equal(t, "0.100000000000000000uinit", gasPrice())
+
+	// every change of the base fee is followed without the hysteresis
+	hysteresis = 0
+	feemarket = &mockFeemarket{baseFee: "0.1"}
+	source, err = newGasPriceSource(cfg, feemarket, zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, "0.200000000000000000uinit", gasPrice())
+	feemarket.baseFee = "0.101"
+	require.Equal(t, "0.202000000000000000uinit", gasPrice())
+}
+
+func Test_FeemarketGasPriceRefreshInterval(t *testing.T) {
+	feemarket := &mockFeemarket{baseFee: "0.1"}
+	cfg := btypes.BroadcasterConfig{
+		GasPrice:       "0.15uinit",
+		GasPriceSource: btypes.GasPriceSourceFeemarket,
+	}
+	source, err := newGasPriceSource(cfg, feemarket, zap.NewNop())
+	require.NoError(t, err)
+
+	// the base fee is queried once per refresh interval
+	for i := 0; i < 3; i++ {
+		gasPrices, err := source.GasPrices(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "0.110000000000000000uinit", gasPrices.String())
+	}
+	require.Equal(t, 1, feemarket.queries)
+}
+
+func Test_StaticGasPriceSource(t *testing.T) {
+	source, err := newGasPriceSource(btypes.BroadcasterConfig{GasPrice: "0.15uinit"}, &mockFeemarket{}, zap.NewNop())
+	require.NoError(t, err)
+	gasPrices, err := source.GasPrices(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "0.150000000000000000uinit", gasPrices.String())
+}
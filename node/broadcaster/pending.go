@@ -0,0 +1,182 @@
+package broadcaster
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// PendingTxs returns the decoded summaries of the local pending txs, ordered by the sender
+// and the order in which they are checked.
+func (b *Broadcaster) PendingTxs() []btypes.PendingTxSummary {
+	b.pendingTxMu.Lock()
+	senders := make([]string, 0, len(b.pendingTxs))
+	pendingTxs := make(map[string][]btypes.PendingTxInfo, len(b.pendingTxs))
+	for sender, txs := range b.pendingTxs {
+		senders = append(senders, sender)
+		pendingTxs[sender] = append([]btypes.PendingTxInfo(nil), txs...)
+	}
+	b.pendingTxMu.Unlock()
+	sort.Strings(senders)
+
+	now := time.Now()
+	summaries := make([]btypes.PendingTxSummary, 0)
+	for _, sender := range senders {
+		for _, pendingTx := range pendingTxs[sender] {
+			summary := btypes.PendingTxSummary{
+				Sender:        pendingTx.Sender,
+				Sequence:      pendingTx.Sequence,
+				TxHash:        pendingTx.TxHash,
+				TraceID:       pendingTx.TraceID,
+				MsgTypes:      pendingTx.MsgTypes,
+				Timestamp:     pendingTx.Timestamp,
+				AgeSeconds:    int64(now.Sub(time.Unix(0, pendingTx.Timestamp)).Seconds()),
+				TimeoutHeight: pendingTx.TimeoutHeight,
+				Resubmissions: pendingTx.Resubmissions,
+				Save:          pendingTx.Save,
+			}
+			if msgTypes, err := b.decodePendingTxMsgTypes(pendingTx); err != nil {
+				summary.DecodeError = err.Error()
+			} else {
+				summary.MsgTypes = msgTypes
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+// decodePendingTxMsgTypes decodes the tx bytes of the pending tx with the account of the sender,
+// as the tx format depends on the chain, and returns the type urls of the msgs.
+func (b *Broadcaster) decodePendingTxMsgTypes(pendingTx btypes.PendingTxInfo) ([]string, error) {
+	account, err := b.AccountByAddress(pendingTx.Sender)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := account.PendingTxToProcessedMsgs(pendingTx.Tx)
+	if err != nil {
+		return nil, err
+	}
+	msgTypes := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		msgTypes = append(msgTypes, sdk.MsgTypeURL(msg))
+	}
+	return msgTypes, nil
+}
+
+// DropPendingTx drops the pending tx of the given sequence from the db and the local pending txs,
+// so a stuck pending tx doesn't block the pending txs checker. The sender can be empty if only one
+// account has a pending tx of the sequence. The later pending txs of the sender can't be included
+// after the gap of the dropped sequence, so they are converted back to the processed msgs and
+// broadcasted again with the sequences reconciled with the chain. If requeue is true, the msgs of
+// the dropped tx are broadcasted again in the same way, otherwise they are discarded.
+func (b *Broadcaster) DropPendingTx(sender string, sequence uint64, requeue bool) (btypes.PendingTxInfo, []btypes.PendingTxInfo, error) {
+	pendingTx, resubmittedTxs, processedMsgsList, err := b.removeLocalPendingTx(sender, sequence, requeue)
+	if err != nil {
+		return btypes.PendingTxInfo{}, nil, err
+	}
+
+	if account, err := b.AccountByAddress(pendingTx.Sender); err == nil {
+		account.RequireReconcile()
+	}
+	b.logger.Warn("pending tx dropped",
+		zap.String("sender", pendingTx.Sender),
+		zap.Uint64("sequence", pendingTx.Sequence),
+		zap.String("trace_id", pendingTx.TraceID),
+		zap.String("tx_hash", pendingTx.TxHash),
+		zap.Strings("msg_types", pendingTx.MsgTypes),
+		zap.Bool("requeue", requeue),
+		zap.Int("resubmitted_txs", len(resubmittedTxs)),
+	)
+
+	for _, processedMsgs := range processedMsgsList {
+		if err := b.broadcastMsgs(processedMsgs); err != nil {
+			return btypes.PendingTxInfo{}, nil, err
+		}
+	}
+	return pendingTx, resubmittedTxs, nil
+}
+
+// removeLocalPendingTx removes the pending tx of the given sequence and the later pending txs of
+// the sender from the db and the local pending txs under pendingTxMu, so both are updated consistently.
+// The later pending txs, and the dropped tx if requeue is true, are replaced with the processed msgs
+// in the db atomically, which are returned to be broadcasted.
+func (b *Broadcaster) removeLocalPendingTx(sender string, sequence uint64, requeue bool) (btypes.PendingTxInfo, []btypes.PendingTxInfo, []btypes.ProcessedMsgs, error) {
+	b.pendingTxMu.Lock()
+	defer b.pendingTxMu.Unlock()
+
+	found := make([]btypes.PendingTxInfo, 0, 1)
+	for address, pendingTxs := range b.pendingTxs {
+		if sender != "" && address != sender {
+			continue
+		}
+		for _, pendingTx := range pendingTxs {
+			if pendingTx.Sequence == sequence {
+				found = append(found, pendingTx)
+			}
+		}
+	}
+	if len(found) == 0 {
+		return btypes.PendingTxInfo{}, nil, nil, errors.Wrap(dbtypes.ErrNotFound, fmt.Sprintf("pending tx of sequence %d", sequence))
+	} else if len(found) > 1 {
+		return btypes.PendingTxInfo{}, nil, nil, fmt.Errorf("%d pending txs of sequence %d, sender is required", len(found), sequence)
+	}
+
+	pendingTx := found[0]
+	resubmittedTxs := make([]btypes.PendingTxInfo, 0)
+	for _, localPendingTx := range b.pendingTxs[pendingTx.Sender] {
+		if localPendingTx.Sequence > pendingTx.Sequence {
+			resubmittedTxs = append(resubmittedTxs, localPendingTx)
+		}
+	}
+
+	requeuedTxs := resubmittedTxs
+	if requeue {
+		requeuedTxs = append([]btypes.PendingTxInfo{pendingTx}, resubmittedTxs...)
+	}
+	processedMsgsList := make([]btypes.ProcessedMsgs, 0, len(requeuedTxs))
+	for _, requeuedTx := range requeuedTxs {
+		account, err := b.AccountByAddress(requeuedTx.Sender)
+		if err != nil {
+			return btypes.PendingTxInfo{}, nil, nil, err
+		}
+		msgs, err := account.PendingTxToProcessedMsgs(requeuedTx.Tx)
+		if err != nil {
+			return btypes.PendingTxInfo{}, nil, nil, err
+		}
+		processedMsgsList = append(processedMsgsList, btypes.ProcessedMsgs{
+			Sender:    requeuedTx.Sender,
+			Msgs:      msgs,
+			Timestamp: time.Now().UnixNano(),
+			TraceID:   requeuedTx.TraceID,
+			Lane:      requeuedTx.Lane,
+			Save:      requeuedTx.Save,
+		})
+	}
+
+	// replace the pending txs with the processed msgs atomically
+	removedTxs := append([]btypes.PendingTxInfo{pendingTx}, resubmittedTxs...)
+	kvs, err := b.PendingTxsToRawKV(removedTxs, true)
+	if err != nil {
+		return btypes.PendingTxInfo{}, nil, nil, err
+	}
+	processedMsgsKVs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+	if err != nil {
+		return btypes.PendingTxInfo{}, nil, nil, err
+	}
+	if err := b.db.RawBatchSet(append(kvs, processedMsgsKVs...)...); err != nil {
+		return btypes.PendingTxInfo{}, nil, nil, err
+	}
+	for _, removedTx := range removedTxs {
+		b.removeLocalPendingTxLocked(removedTx)
+	}
+	return pendingTx, resubmittedTxs, processedMsgsList, nil
+}
@@ -0,0 +1,85 @@
+package broadcaster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+func Test_DropPendingTx(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	chain := newMockChain(t, b, 0)
+	account := b.accounts[0]
+	require.NoError(t, account.Load(context.Background()))
+
+	broadcast := func(amount int) uint64 {
+		data := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, amount), Timestamp: int64(amount), Save: true}.WithTraceID()
+		require.NoError(t, b.handleProcessedMsgs(context.Background(), data, account))
+		return txSequence(t, chain.broadcasted[len(chain.broadcasted)-1])
+	}
+	require.Equal(t, uint64(0), broadcast(1))
+	require.Equal(t, uint64(1), broadcast(2))
+	require.Equal(t, uint64(2), broadcast(3))
+
+	// the pending txs are listed with the msgs decoded from the tx bytes
+	summaries := b.PendingTxs()
+	require.Len(t, summaries, 3)
+	for i, summary := range summaries {
+		require.Equal(t, sender, summary.Sender)
+		require.Equal(t, uint64(i), summary.Sequence)
+		require.Len(t, summary.MsgTypes, i+1)
+		require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", summary.MsgTypes[0])
+		require.True(t, summary.Save)
+		require.Empty(t, summary.DecodeError)
+	}
+
+	// the pending tx of sequence 1 is dropped from the mempool, so the later tx can't be included after the gap
+	head, err := b.PeekLocalPendingTx()
+	require.NoError(t, err)
+	require.NoError(t, b.RemovePendingTx(head))
+	chain.sequence++
+	dropped, resubmitted, err := b.DropPendingTx("", 1, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), dropped.Sequence)
+	require.Len(t, resubmitted, 1)
+	require.Equal(t, uint64(2), resubmitted[0].Sequence)
+	require.Zero(t, b.LenLocalPendingTx())
+	pendingTxs, err := b.loadPendingTxs()
+	require.NoError(t, err)
+	require.Empty(t, pendingTxs)
+
+	// the msgs of the later tx are saved and queued again, while the msgs of the dropped tx are discarded
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	require.Len(t, lane.txChannel, 1)
+	saved, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, resubmitted[0].TraceID, saved[0].TraceID)
+
+	// the msgs are re-signed with the sequence of the dropped tx
+	requeued := <-lane.txChannel
+	require.Equal(t, []int64{1, 2, 3}, sentAmounts(requeued.Msgs))
+	require.NoError(t, b.handleProcessedMsgs(context.Background(), requeued, account))
+	require.Equal(t, uint64(1), txSequence(t, chain.broadcasted[len(chain.broadcasted)-1]))
+	require.Equal(t, uint64(2), broadcast(4))
+
+	// the msgs of the dropped tx are queued again on the request
+	dropped, resubmitted, err = b.DropPendingTx(sender, 2, true)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), dropped.Sequence)
+	require.Empty(t, resubmitted)
+	require.Len(t, lane.txChannel, 1)
+	requeued = <-lane.txChannel
+	require.Equal(t, dropped.TraceID, requeued.TraceID)
+	require.Equal(t, []int64{1, 2, 3, 4}, sentAmounts(requeued.Msgs))
+
+	_, _, err = b.DropPendingTx(sender, 10, false)
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
 
@@ -38,9 +39,10 @@ func (b *Broadcaster) CheckPendingTx(ctx context.Context, pendingTx btypes.Pendi
 		}
 		pendingTxTime := time.Unix(0, pendingTx.Timestamp)
 
-		// before timeout
-		if lastHeader.Header.Time.Before(pendingTxTime.Add(b.cfg.TxTimeout)) {
-			b.logger.Debug("failed to query tx", zap.String("tx_hash", pendingTx.TxHash), zap.String("error", txerr.Error()))
+		// before timeout; the tx with a timeout height can be included until the timeout height
+		if (pendingTx.TimeoutHeight > 0 && !pendingTx.Expired(lastHeader.Header.Height)) ||
+			(pendingTx.TimeoutHeight == 0 && lastHeader.Header.Time.Before(pendingTxTime.Add(b.cfg.TxTimeout))) {
+			b.logger.Debug("failed to query tx", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash), zap.String("error", txerr.Error()))
 			return nil, time.Time{}, types.ErrTxNotFound
 		} else {
 			// timeout case
@@ -56,14 +58,18 @@ func (b *Broadcaster) CheckPendingTx(ctx context.Context, pendingTx btypes.Pendi
 			// if sequence is larger than the sequence of the pending tx,
 			// handle it as the tx has already been processed
 			if pendingTx.Sequence < accountSequence {
+				b.logger.Debug("pending tx processed without tx result", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash))
 				return nil, time.Time{}, nil
+			} else if pendingTx.TimeoutHeight > 0 {
+				// the expired tx can never be included, so it is safe to resubmit the msgs
+				return nil, time.Time{}, errors.Wrapf(types.ErrTxExpired, "tx hash: %s, timeout height: %d, latest height: %d", pendingTx.TxHash, pendingTx.TimeoutHeight, lastHeader.Header.Height)
 			}
-			panic(fmt.Errorf("something wrong, pending txs are not processed for a long time; current block time: %s, pending tx processing time: %s", time.Now().UTC().String(), pendingTxTime.UTC().String()))
+			panic(fmt.Errorf("something wrong, pending txs are not processed for a long time; trace id: %s, current block time: %s, pending tx processing time: %s", pendingTx.TraceID, time.Now().UTC().String(), pendingTxTime.UTC().String()))
 		}
 	} else if txerr != nil {
 		return nil, time.Time{}, txerr
 	} else if res.TxResult.Code != 0 {
-		panic(fmt.Errorf("tx failed, tx hash: %s, code: %d, log: %s; you might need to check gas adjustment config or balance", pendingTx.TxHash, res.TxResult.Code, res.TxResult.Log))
+		panic(fmt.Errorf("tx failed, trace id: %s, tx hash: %s, code: %d, log: %s; you might need to check gas adjustment config or balance", pendingTx.TraceID, pendingTx.TxHash, res.TxResult.Code, res.TxResult.Log))
 	}
 
 	header, err := b.rpcClient.Header(ctx, &res.Height)
@@ -81,68 +87,281 @@ func (b *Broadcaster) RemovePendingTx(pendingTx btypes.PendingTxInfo) error {
 		return err
 	}
 
-	b.dequeueLocalPendingTx()
+	b.dequeueLocalPendingTx(pendingTx)
+	b.logger.Debug("pending tx removed", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash))
 	return nil
 }
 
+// ResubmitPendingTx converts the expired pending tx back to the processed msgs
+// and broadcasts them again with a new sequence and timeout height.
+func (b *Broadcaster) ResubmitPendingTx(pendingTx btypes.PendingTxInfo) error {
+	account, err := b.AccountByAddress(pendingTx.Sender)
+	if err != nil {
+		return err
+	}
+	msgs, err := account.PendingTxToProcessedMsgs(pendingTx.Tx)
+	if err != nil {
+		return err
+	}
+
+	processedMsgs := btypes.ProcessedMsgs{
+		Sender:    pendingTx.Sender,
+		Msgs:      msgs,
+		Timestamp: time.Now().UnixNano(),
+		TraceID:   pendingTx.TraceID,
+		Lane:      pendingTx.Lane,
+		Save:      pendingTx.Save,
+
+		Resubmissions: pendingTx.Resubmissions + 1,
+	}
+
+	// replace the pending tx with the processed msgs atomically
+	kvs, err := b.PendingTxsToRawKV([]btypes.PendingTxInfo{pendingTx}, true)
+	if err != nil {
+		return err
+	}
+	processedMsgsList := []btypes.ProcessedMsgs{processedMsgs}
+	processedMsgsKVs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+	if err != nil {
+		return err
+	}
+	err = b.db.RawBatchSet(append(kvs, processedMsgsKVs...)...)
+	if err != nil {
+		return err
+	}
+	processedMsgs = processedMsgsList[0]
+	b.dequeueLocalPendingTx(pendingTx)
+
+	b.logger.Warn("resubmit expired pending tx",
+		zap.String("trace_id", pendingTx.TraceID),
+		zap.String("tx_hash", pendingTx.TxHash),
+		zap.Uint64("sequence", pendingTx.Sequence),
+		zap.Uint64("timeout_height", pendingTx.TimeoutHeight),
+		zap.Uint32("resubmissions", processedMsgs.Resubmissions),
+		zap.Float64("gas_price_multiplier", b.cfg.GasPriceMultiplier(processedMsgs.Resubmissions)),
+	)
+	return b.broadcastMsgs(processedMsgs)
+}
+
 // Start broadcaster loop
 func (b *Broadcaster) Start(ctx context.Context) error {
 	defer close(b.txChannelStopped)
 
+	errGrp, ctx := errgroup.WithContext(ctx)
+	errGrp.Go(func() error {
+		balanceTicker := time.NewTicker(b.cfg.GetBalanceCheckInterval())
+		defer balanceTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-types.Shutdown(ctx):
+				return nil
+			case <-balanceTicker.C:
+				b.checkBalances(ctx)
+			}
+		}
+	})
+
+	// each account has its own lane, so the sequences of the accounts are handled independently
+	for _, account := range b.accounts {
+		lane, err := b.laneByAddress(account.GetAddressString())
+		if err != nil {
+			return err
+		}
+		errGrp.Go(func() error {
+			return b.startLane(ctx, account, lane)
+		})
+	}
+	return errGrp.Wait()
+}
+
+// startLane broadcasts the processed msgs of the account one by one.
+func (b *Broadcaster) startLane(ctx context.Context, broadcasterAccount *BroadcasterAccount, lane *broadcastLane) error {
+	// resolve the pending txs restored at the startup before broadcasting new msgs
+	restoredMsgs, err := b.resolveRestoredTxs(ctx, broadcasterAccount, lane)
+	if ctx.Err() != nil {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to resolve restored pending txs")
+	}
+	for _, data := range restoredMsgs {
+		if stop, err := b.handleProcessedMsgsWithRetry(ctx, data, broadcasterAccount); stop || err != nil {
+			return err
+		}
+	}
+
 	for {
+		// refill the queue with the overflowed msgs
+		err := b.refillTxChannel(lane)
+		if err != nil {
+			return err
+		}
+
 		select {
 		case <-ctx.Done():
 			return nil
-		case data := <-b.txChannel:
-			var err error
+		case <-types.Shutdown(ctx):
+			// the msgs left in the lane are persisted by the drain after the broadcaster is stopped
+			return nil
+		case data := <-lane.txChannel:
+			// the key of the lane can be rotated while the msgs are queued
+			data = b.redirectRotatedMsgs(data)
+			data = b.rewriteLaneMsgs(lane, data)
 			broadcasterAccount, err := b.AccountByAddress(data.Sender)
 			if err != nil {
 				return err
 			}
-			for retry := 1; retry <= types.MaxRetryCount; retry++ {
+			if stop, err := b.handleProcessedMsgsWithRetry(ctx, data, broadcasterAccount); stop || err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handleProcessedMsgsWithRetry broadcasts the processed msgs with retries.
+// It returns true if the context is done.
+func (b *Broadcaster) handleProcessedMsgsWithRetry(ctx context.Context, data btypes.ProcessedMsgs, broadcasterAccount *BroadcasterAccount) (bool, error) {
+	var err error
+	for retry := 1; retry <= types.MaxRetryCount; retry++ {
+		// do not broadcast txs which will fail until the account is funded
+		if b.IsLowBalance(broadcasterAccount.GetAddressString()) && b.waitForBalance(ctx, broadcasterAccount) {
+			return true, nil
+		}
+
+		err = b.handleProcessedMsgs(ctx, data, broadcasterAccount)
+		if err != nil && b.cfg.IsolateFailingMsgs && errors.Is(err, errSimulationFailed) {
+			var isolated bool
+			var isolateErr error
+			data, isolated, isolateErr = b.parkFailingMsgs(ctx, data, broadcasterAccount, err)
+			if isolateErr != nil {
+				return false, errors.Wrapf(isolateErr, "failed to park failing msgs; trace id: %s", data.TraceID)
+			} else if isolated {
 				err = b.handleProcessedMsgs(ctx, data, broadcasterAccount)
-				if err == nil {
-					break
-				} else if err = b.handleMsgError(err, broadcasterAccount); err == nil {
-					// if the error is handled, we can delete the processed msgs
-					err = b.deleteProcessedMsgs(data.Timestamp)
-					if err != nil {
-						return err
-					}
-					break
-				} else if !data.Save {
-					b.logger.Warn("discard msgs: failed to handle processed msgs", zap.String("error", err.Error()))
-					// if the message does not need to be saved, we can skip retry
-					err = nil
-					break
-				}
-				b.logger.Warn(fmt.Sprintf("retry to handle processed msgs after %d seconds", int(2*math.Exp2(float64(retry)))), zap.Int("count", retry), zap.String("error", err.Error()))
-				if types.SleepWithRetry(ctx, retry) {
-					return nil
-				}
 			}
+		}
+		if err == nil {
+			break
+		} else if err = b.handleMsgError(err, broadcasterAccount); err == nil {
+			// if the error is handled, we can delete the processed msgs
+			err = b.deleteProcessedMsgs(data)
 			if err != nil {
-				return errors.Wrap(err, "failed to handle processed msgs")
+				return false, err
 			}
+			break
+		} else if !data.Save {
+			b.logger.Warn("discard msgs: failed to handle processed msgs", zap.String("trace_id", data.TraceID), zap.String("error", err.Error()))
+			// if the message does not need to be saved, we can skip retry
+			err = nil
+			break
+		} else if isPermanentErr(err) {
+			// retrying can't make the tx valid, so halt with the error instead of losing the msgs
+			return false, errors.Wrapf(fmt.Errorf("%w: %w", types.ErrPermanentTxFailure, err), "failed to handle processed msgs; trace id: %s", data.TraceID)
+		}
+		b.logger.Warn(fmt.Sprintf("retry to handle processed msgs after %d seconds", int(2*math.Exp2(float64(retry)))), zap.Int("count", retry), zap.String("trace_id", data.TraceID), zap.String("error", err.Error()))
+		if types.SleepWithRetry(ctx, retry) {
+			return true, nil
+		}
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to handle processed msgs; trace id: %s", data.TraceID)
+	}
+	return false, nil
+}
+
+// waitForBalance waits until the account can pay the estimated fees.
+// It returns true if the context is done.
+func (b *Broadcaster) waitForBalance(ctx context.Context, account *BroadcasterAccount) bool {
+	ticker := time.NewTicker(b.cfg.GetBalanceCheckInterval())
+	defer ticker.Stop()
+
+	for {
+		err := b.checkBalance(ctx, account)
+		if err == nil {
+			return false
+		}
+		b.logger.Warn("waiting for the account to be funded", zap.String("address", account.GetAddressString()), zap.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return true
+		case <-ticker.C:
 		}
 	}
 }
 
+// Stopped returns the channel closed when the broadcaster loop is stopped.
+func (b *Broadcaster) Stopped() <-chan struct{} {
+	return b.txChannelStopped
+}
+
 // @dev: these pending processed data is filled at initialization(`NewBroadcaster`).
-func (b Broadcaster) BroadcastPendingProcessedMsgs() {
+func (b *Broadcaster) BroadcastPendingProcessedMsgs() error {
 	for _, processedMsg := range b.pendingProcessedMsgs {
-		b.BroadcastMsgs(processedMsg)
+		err := b.broadcastMsgs(processedMsg)
+		if err != nil {
+			return err
+		}
 	}
+	b.pendingProcessedMsgs = nil
+	return nil
 }
 
 // BroadcastTxSync broadcasts transaction bytes to txBroadcastLooper.
-func (b Broadcaster) BroadcastMsgs(msgs btypes.ProcessedMsgs) {
-	if b.txChannel == nil {
-		return
+// The msgs are queued to their lane, or to the default lane of the sender account.
+// If the queue is full, the msgs are persisted to the db and refilled later.
+// It returns an error if the msgs have no lane to be broadcasted. The msgs broadcasted before the preparation
+// are buffered until Prepare merges them with the msgs restored from the db.
+func (b *Broadcaster) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	msgs = msgs.WithTraceID()
+
+	b.prepareMu.Lock()
+	if !b.prepared {
+		// the saved msgs are restored from the db by the preparation as well
+		b.unpreparedMsgs = append(b.unpreparedMsgs, msgs)
+		b.prepareMu.Unlock()
+		return nil
+	} else if _, ok := b.restoredTraceIDs[msgs.TraceID]; ok {
+		delete(b.restoredTraceIDs, msgs.TraceID)
+		b.prepareMu.Unlock()
+		b.logger.Debug("drop the msgs restored from the db", zap.String("trace_id", msgs.TraceID))
+		return nil
+	}
+	b.prepareMu.Unlock()
+	return b.broadcastMsgs(msgs)
+}
+
+// broadcastMsgs queues the msgs to their lane, without the deduplication against the restored msgs.
+func (b *Broadcaster) broadcastMsgs(msgs btypes.ProcessedMsgs) error {
+	select {
+	case <-b.txChannelStopped:
+		return nil
+	default:
+	}
+
+	if b.cfg.DryRun {
+		b.recordDryRunMsgs(msgs.WithTraceID())
+		return nil
+	}
+
+	msgs = b.redirectRotatedMsgs(msgs.WithTraceID())
+	lane, err := b.laneOf(msgs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to broadcast msgs; trace id: %s", msgs.TraceID)
 	}
 
+	err = b.enqueueProcessedMsgs(lane, msgs)
+	if err == nil {
+		b.logger.Debug("enqueue processed msgs", zap.String("trace_id", msgs.TraceID), zap.String("lane", msgs.Lane), zap.Strings("msg_types", msgs.GetMsgTypes()))
+		return nil
+	}
+
+	// fallback to the blocking queue not to drop the msgs
+	b.logger.Error("failed to persist overflowed msgs", zap.String("trace_id", msgs.TraceID), zap.String("error", err.Error()))
 	select {
 	case <-b.txChannelStopped:
-	case b.txChannel <- msgs:
+	case lane.txChannel <- msgs:
 	}
+	return nil
 }
@@ -0,0 +1,398 @@
+package broadcaster
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/pkg/errors"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// broadcastLane is the queue of processed msgs of an account.
+// Each account has its own lane, so the msgs of an account
+// never block the msgs of another account.
+type broadcastLane struct {
+	mu        *sync.Mutex
+	txChannel chan btypes.ProcessedMsgs
+
+	// db keys of the overflowed processed msgs, which are persisted in the db
+	overflowKeys [][]byte
+
+	// pending txs restored from the db, which are not resolved at the startup
+	restoredTxs []btypes.PendingTxInfo
+
+	// rewriters of the msgs queued before they are registered
+	rewriters []laneRewriter
+}
+
+// laneRewriter is the rewriter of the msgs which were in the queue of the lane when it was registered.
+type laneRewriter struct {
+	rewrite btypes.MsgRewriteFn
+
+	// number of the msgs left to be taken from the queue
+	remaining int
+}
+
+func newBroadcastLane(maxQueuedMsgs int) *broadcastLane {
+	return &broadcastLane{
+		mu:        &sync.Mutex{},
+		txChannel: make(chan btypes.ProcessedMsgs, maxQueuedMsgs),
+	}
+}
+
+// laneOf returns the broadcast lane of the processed msgs. The msgs without a lane,
+// with an unknown lane or with a lane served by another account fall back to
+// the default lane of the sender.
+func (b Broadcaster) laneOf(msgs btypes.ProcessedMsgs) (*broadcastLane, error) {
+	if msgs.Lane != "" {
+		account, err := b.AccountByLane(msgs.Lane)
+		if err == nil && account.GetAddressString() == msgs.Sender {
+			return b.laneByAddress(account.GetAddressString())
+		}
+
+		b.logger.Warn("fallback to the default lane",
+			zap.String("lane", msgs.Lane),
+			zap.String("sender", msgs.Sender),
+			zap.String("trace_id", msgs.TraceID),
+		)
+	}
+	return b.laneByAddress(msgs.Sender)
+}
+
+func (b Broadcaster) laneByAddress(address string) (*broadcastLane, error) {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+
+	lane, ok := b.lanes[address]
+	if !ok {
+		// the lane of the rotated account is served by the new account
+		if sender, rotated := b.rotatedSenders[address]; rotated {
+			if lane, ok := b.lanes[sender]; ok {
+				return lane, nil
+			}
+		}
+		return nil, errors.Errorf("broadcaster lane not found; address: %s", address)
+	}
+	return lane, nil
+}
+
+// enqueueProcessedMsgs pushes the processed msgs to the tx channel if there is a room
+// and no overflowed msgs are waiting. Otherwise, it persists the msgs to the db and
+// keeps only the key in memory, so they can be refilled in order.
+func (b *Broadcaster) enqueueProcessedMsgs(lane *broadcastLane, msgs btypes.ProcessedMsgs) error {
+	lane.mu.Lock()
+	defer lane.mu.Unlock()
+
+	if len(lane.overflowKeys) == 0 && len(lane.txChannel) < cap(lane.txChannel) {
+		lane.txChannel <- msgs
+		return nil
+	}
+
+	// overflowed msgs must be saved to be refilled from the db
+	msgs, err := b.saveProcessedMsgs(msgs)
+	if err != nil {
+		return err
+	}
+	lane.overflowKeys = append(lane.overflowKeys, msgs.Key())
+	return nil
+}
+
+// refillTxChannel moves the overflowed msgs from the db to the tx channel
+// until the channel is full.
+func (b *Broadcaster) refillTxChannel(lane *broadcastLane) error {
+	lane.mu.Lock()
+	defer lane.mu.Unlock()
+
+	for len(lane.overflowKeys) > 0 && len(lane.txChannel) < cap(lane.txChannel) {
+		key := lane.overflowKeys[0]
+		msgs, err := b.loadProcessedMsgsByKey(key)
+		if errors.Is(err, dbtypes.ErrNotFound) {
+			b.logger.Warn("overflowed msgs not found", zap.String("key", fmt.Sprintf("%X", key)))
+		} else if err != nil {
+			return errors.Wrap(err, "failed to load overflowed msgs")
+		} else {
+			lane.txChannel <- msgs
+		}
+		lane.overflowKeys = lane.overflowKeys[1:]
+	}
+
+	if len(lane.overflowKeys) == 0 {
+		// release the underlying array
+		lane.overflowKeys = nil
+	}
+	return nil
+}
+
+// DropQueuedMsgs removes the processed msgs of the account which are waiting to be broadcasted and match
+// the filter, and returns the number of the removed msgs. The msgs already broadcasted are not affected.
+func (b *Broadcaster) DropQueuedMsgs(address string, filter func(btypes.ProcessedMsgs) bool) (int, error) {
+	lane, err := b.laneByAddress(address)
+	if err != nil {
+		return 0, err
+	}
+
+	lane.mu.Lock()
+	defer lane.mu.Unlock()
+
+	queued := make([]btypes.ProcessedMsgs, 0, len(lane.txChannel))
+DRAIN:
+	for {
+		select {
+		case msgs := <-lane.txChannel:
+			queued = append(queued, msgs)
+		default:
+			break DRAIN
+		}
+	}
+
+	dropped := 0
+	for i, msgs := range queued {
+		if !filter(msgs) {
+			lane.txChannel <- msgs
+			continue
+		}
+		// the dropped msgs are no longer taken by the rewriters registered after they were queued
+		lane.dropQueuedRewrite(i - dropped)
+		if msgs.Save {
+			if err := b.deleteProcessedMsgs(msgs); err != nil {
+				return dropped, errors.Wrap(err, "failed to delete dropped msgs")
+			}
+		}
+		dropped++
+	}
+
+	overflowKeys := lane.overflowKeys[:0]
+	for _, key := range lane.overflowKeys {
+		msgs, err := b.loadProcessedMsgsByKey(key)
+		if errors.Is(err, dbtypes.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return dropped, errors.Wrap(err, "failed to load overflowed msgs")
+		}
+
+		if !filter(msgs) {
+			overflowKeys = append(overflowKeys, key)
+			continue
+		}
+		if err := b.deleteProcessedMsgs(msgs); err != nil {
+			return dropped, errors.Wrap(err, "failed to delete dropped msgs")
+		}
+		dropped++
+	}
+	lane.overflowKeys = overflowKeys
+	return dropped, nil
+}
+
+// RewriteQueuedMsgs rewrites the msgs of the account which are waiting to be broadcasted, and returns the number
+// of the rewritten saved msgs. The rewrite function returns the new msg and whether the msg is changed.
+// The saved msgs, including the overflowed msgs, are rewritten in the db, while the msgs in the queue of the lane
+// are left to the lane loop, which rewrites them when it takes them, so the queue is not drained concurrently.
+// The msgs already broadcasted are not affected.
+func (b *Broadcaster) RewriteQueuedMsgs(address string, rewrite btypes.MsgRewriteFn) (int, error) {
+	lane, err := b.laneByAddress(address)
+	if err != nil {
+		return 0, err
+	}
+
+	// the overflowed msgs are not refilled while they are rewritten
+	lane.mu.Lock()
+	defer lane.mu.Unlock()
+
+	// the overflowed msgs are rewritten in the db, so only the msgs in the queue are left to the lane loop
+	if queued := len(lane.txChannel); queued > 0 {
+		lane.rewriters = append(lane.rewriters, laneRewriter{rewrite: rewrite, remaining: queued})
+	}
+
+	processedMsgsList, err := b.loadProcessedMsgs()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to load queued msgs")
+	}
+	count := 0
+	for _, msgs := range processedMsgsList {
+		if msgs.Sender != address {
+			continue
+		}
+		msgs, changed := rewriteMsgs(msgs, rewrite)
+		if !changed {
+			continue
+		}
+		// rewritten msgs are saved with the same key
+		if _, err := b.saveProcessedMsgs(msgs); err != nil {
+			return count, errors.Wrap(err, "failed to save rewritten msgs")
+		}
+		count++
+	}
+	return count, nil
+}
+
+// rewriteLaneMsgs rewrites the msgs taken from the queue of the lane with the rewriters registered
+// while they were queued, and saves them if they need to be saved. The rewriters are dropped
+// once all the msgs queued before their registration are taken.
+func (b Broadcaster) rewriteLaneMsgs(lane *broadcastLane, msgs btypes.ProcessedMsgs) btypes.ProcessedMsgs {
+	lane.mu.Lock()
+	defer lane.mu.Unlock()
+
+	changed := false
+	rewriters := lane.rewriters[:0]
+	for _, rewriter := range lane.rewriters {
+		var ok bool
+		msgs, ok = rewriteMsgs(msgs, rewriter.rewrite)
+		changed = changed || ok
+
+		rewriter.remaining--
+		if rewriter.remaining > 0 {
+			rewriters = append(rewriters, rewriter)
+		}
+	}
+	lane.rewriters = rewriters
+	if len(lane.rewriters) == 0 {
+		// release the underlying array
+		lane.rewriters = nil
+	}
+	if !changed || !msgs.Save {
+		return msgs
+	}
+
+	saved, err := b.saveProcessedMsgs(msgs)
+	if err != nil {
+		b.logger.Error("failed to save rewritten msgs", zap.String("trace_id", msgs.TraceID), zap.String("error", err.Error()))
+		return msgs
+	}
+	return saved
+}
+
+// rewriteMsgs applies the rewrite function to the msgs, and returns whether any msg is changed.
+func rewriteMsgs(msgs btypes.ProcessedMsgs, rewrite btypes.MsgRewriteFn) (btypes.ProcessedMsgs, bool) {
+	changed := false
+	rewritten := make([]sdk.Msg, 0, len(msgs.Msgs))
+	for _, msg := range msgs.Msgs {
+		newMsg, ok := rewrite(msg)
+		changed = changed || ok
+		rewritten = append(rewritten, newMsg)
+	}
+	msgs.Msgs = rewritten
+	return msgs, changed
+}
+
+// dropQueuedRewrite removes the msg at the position of the queue from the msgs left to the rewriters.
+func (l *broadcastLane) dropQueuedRewrite(position int) {
+	rewriters := l.rewriters[:0]
+	for _, rewriter := range l.rewriters {
+		if position < rewriter.remaining {
+			rewriter.remaining--
+		}
+		if rewriter.remaining > 0 {
+			rewriters = append(rewriters, rewriter)
+		}
+	}
+	l.rewriters = rewriters
+}
+
+func (l *broadcastLane) addRestoredTx(pendingTx btypes.PendingTxInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.restoredTxs = append(l.restoredTxs, pendingTx)
+}
+
+func (l *broadcastLane) takeRestoredTxs() []btypes.PendingTxInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	restoredTxs := l.restoredTxs
+	l.restoredTxs = nil
+	return restoredTxs
+}
+
+func (l *broadcastLane) lenQueuedMsgs() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.txChannel) + len(l.overflowKeys)
+}
+
+func (l *broadcastLane) lenOverflowedMsgs() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.overflowKeys)
+}
+
+// LenQueuedMsgs returns the number of processed msgs waiting to be broadcasted,
+// including the overflowed msgs persisted in the db.
+func (b Broadcaster) LenQueuedMsgs() int {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+
+	count := 0
+	for _, lane := range b.lanes {
+		count += lane.lenQueuedMsgs()
+	}
+	return count
+}
+
+// LenOverflowedMsgs returns the number of processed msgs persisted in the db
+// because the queue was full.
+func (b Broadcaster) LenOverflowedMsgs() int {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+
+	count := 0
+	for _, lane := range b.lanes {
+		count += lane.lenOverflowedMsgs()
+	}
+	return count
+}
+
+// LenQueuedMsgsByAddress returns the number of processed msgs waiting to be broadcasted by the account.
+func (b Broadcaster) LenQueuedMsgsByAddress(address string) int {
+	lane, err := b.laneByAddress(address)
+	if err != nil {
+		return 0
+	}
+	return lane.lenQueuedMsgs()
+}
+
+// PersistQueuedMsgs takes the processed msgs waiting in the lanes and persists the ones to be saved, so they
+// are broadcasted after the restart. The msgs not to be saved are discarded. It returns the number of the
+// persisted and the discarded processed msgs. It must be called after the broadcaster is stopped.
+func (b *Broadcaster) PersistQueuedMsgs() (persisted int, discarded int, err error) {
+	b.accountMu.Lock()
+	lanes := make([]*broadcastLane, 0, len(b.lanes))
+	for _, lane := range b.lanes {
+		lanes = append(lanes, lane)
+	}
+	b.accountMu.Unlock()
+
+	for _, lane := range lanes {
+		lane.mu.Lock()
+	DRAIN:
+		for {
+			select {
+			case msgs := <-lane.txChannel:
+				if !msgs.Save {
+					discarded++
+					continue
+				}
+				if _, err := b.saveProcessedMsgs(msgs); err != nil {
+					lane.mu.Unlock()
+					return persisted, discarded, errors.Wrapf(err, "failed to persist queued msgs; trace id: %s", msgs.TraceID)
+				}
+				persisted++
+			default:
+				break DRAIN
+			}
+		}
+		// the overflowed msgs are already persisted
+		persisted += len(lane.overflowKeys)
+		lane.overflowKeys = nil
+		lane.mu.Unlock()
+	}
+	return persisted, discarded, nil
+}
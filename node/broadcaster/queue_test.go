@@ -0,0 +1,433 @@
+package broadcaster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	"github.com/initia-labs/opinit-bots/keys"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// newTestBroadcaster creates a broadcaster with the given keys without querying the chain.
+func newTestBroadcaster(t *testing.T, maxQueuedMsgs int, keyNames ...string) (*Broadcaster, []string) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces, banktypes.RegisterInterfaces})
+	require.NoError(t, err)
+
+	rpcClient := nodetest.NewFakeClient("test-1")
+
+	cfg := btypes.BroadcasterConfig{
+		ChainID:       "test-1",
+		GasPrice:      "0.15uinit",
+		GasAdjustment: 1.5,
+		TxTimeout:     time.Minute,
+		Bech32Prefix:  "init",
+		HomePath:      t.TempDir(),
+		MaxQueuedMsgs: maxQueuedMsgs,
+	}
+	b, err := NewBroadcaster(cfg, db, zap.NewNop(), cdc, txConfig, rpcClient)
+	require.NoError(t, err)
+	// the msgs are queued without the preparation restoring the msgs from the db
+	b.prepared = true
+
+	keyBase, err := keys.GetKeyBase(cfg.ChainID, cfg.HomePath, cdc, nil)
+	require.NoError(t, err)
+
+	addresses := make([]string, 0, len(keyNames))
+	for _, keyName := range keyNames {
+		mnemonic, err := keys.CreateMnemonic()
+		require.NoError(t, err)
+		_, err = keyBase.NewAccount(keyName, mnemonic, "", sdk.FullFundraiserPath, hd.Secp256k1)
+		require.NoError(t, err)
+
+		account, err := NewBroadcasterAccount(cfg, cdc, txConfig, rpcClient, btypes.KeyringConfig{Name: keyName})
+		require.NoError(t, err)
+
+		b.accounts = append(b.accounts, account)
+		b.addressAccountMap[account.GetAddressString()] = len(b.accounts) - 1
+		b.lanes[account.GetAddressString()] = newBroadcastLane(cfg.GetMaxQueuedMsgs())
+		addresses = append(addresses, account.GetAddressString())
+	}
+	return b, addresses
+}
+
+func Test_BoundedQueue(t *testing.T) {
+	maxQueuedMsgs := 100
+	b, addresses := newTestBroadcaster(t, maxQueuedMsgs, "sender")
+	sender := addresses[0]
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+
+	// enqueue msgs while the broadcaster is stalled
+	count := 100_000
+	for i := 0; i < count; i++ {
+		b.BroadcastMsgs(btypes.ProcessedMsgs{
+			Sender: sender,
+			Msgs: []sdk.Msg{
+				&banktypes.MsgSend{
+					FromAddress: sender,
+					ToAddress:   sender,
+					Amount:      sdk.NewCoins(sdk.NewInt64Coin("uinit", int64(i+1))),
+				},
+			},
+			Timestamp: int64(i + 1),
+			Save:      i%2 == 0,
+		})
+		require.LessOrEqual(t, len(lane.txChannel), maxQueuedMsgs)
+	}
+	require.Equal(t, maxQueuedMsgs, cap(lane.txChannel))
+	require.Equal(t, count, b.LenQueuedMsgs())
+	require.Equal(t, count-maxQueuedMsgs, b.LenOverflowedMsgs())
+
+	// resume the broadcaster and drain the queue
+	for i := 0; i < count; i++ {
+		require.NoError(t, b.refillTxChannel(lane))
+		msgs := <-lane.txChannel
+		require.Equal(t, int64(i+1), msgs.Timestamp)
+		require.Len(t, msgs.Msgs, 1)
+		require.Equal(t, int64(i+1), msgs.Msgs[0].(*banktypes.MsgSend).Amount.AmountOf("uinit").Int64())
+		if i >= maxQueuedMsgs {
+			// overflowed msgs are always saved
+			require.True(t, msgs.Save)
+		}
+		require.NoError(t, b.deleteProcessedMsgs(msgs))
+	}
+	require.Equal(t, 0, b.LenQueuedMsgs())
+
+	processedMsgs, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, processedMsgs, 0)
+}
+
+func Test_AccountLanes(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "executor", "oracle")
+
+	// interleave the msgs of two accounts
+	count := 30
+	for i := 0; i < count; i++ {
+		sender := addresses[i%2]
+		b.BroadcastMsgs(btypes.ProcessedMsgs{
+			Sender:    sender,
+			Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: sender, ToAddress: sender}},
+			Timestamp: int64(i + 1),
+			Save:      true,
+		})
+	}
+
+	for _, address := range addresses {
+		lane, err := b.laneByAddress(address)
+		require.NoError(t, err)
+		require.Equal(t, count/2, lane.lenQueuedMsgs())
+
+		account, err := b.AccountByAddress(address)
+		require.NoError(t, err)
+
+		// each lane only has the msgs of its account in order
+		lastTimestamp := int64(0)
+		for lane.lenQueuedMsgs() > 0 {
+			require.NoError(t, b.refillTxChannel(lane))
+			msgs := <-lane.txChannel
+			require.Equal(t, address, msgs.Sender)
+			require.Greater(t, msgs.Timestamp, lastTimestamp)
+			lastTimestamp = msgs.Timestamp
+
+			// simulate the broadcast of the msgs
+			b.enqueueLocalPendingTx(btypes.PendingTxInfo{
+				Sender:    msgs.Sender,
+				Sequence:  account.Sequence(),
+				Timestamp: msgs.Timestamp,
+			})
+			account.IncreaseSequence()
+		}
+		require.Equal(t, uint64(count/2), account.Sequence())
+	}
+
+	// pending txs are checked in round-robin order and the sequences never cross
+	lastSequences := make(map[string]int64)
+	for i := 0; b.LenLocalPendingTx() > 0; i++ {
+		pendingTx, err := b.PeekLocalPendingTx()
+		require.NoError(t, err)
+		require.Equal(t, addresses[i%2], pendingTx.Sender)
+
+		lastSequence, ok := lastSequences[pendingTx.Sender]
+		if ok {
+			require.Equal(t, lastSequence+1, int64(pendingTx.Sequence))
+		}
+		lastSequences[pendingTx.Sender] = int64(pendingTx.Sequence)
+
+		require.NoError(t, b.RemovePendingTx(pendingTx))
+	}
+	require.Len(t, lastSequences, 2)
+}
+
+func Test_NamedLanes(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "executor", "oracle")
+	executor, oracle := addresses[0], addresses[1]
+	b.laneRoutes[btypes.OracleLane] = 1
+
+	account, err := b.AccountByLane(btypes.OracleLane)
+	require.NoError(t, err)
+	require.Equal(t, oracle, account.GetAddressString())
+	_, err = b.AccountByLane("unknown")
+	require.ErrorIs(t, err, types.ErrKeyNotSet)
+
+	newMsgs := func(sender string, lane string, timestamp int64) btypes.ProcessedMsgs {
+		return btypes.ProcessedMsgs{
+			Sender:    sender,
+			Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: sender, ToAddress: sender}},
+			Timestamp: timestamp,
+			Lane:      lane,
+			Save:      true,
+		}
+	}
+
+	// a large batch of msgs of the default lane
+	for i := 0; i < 30; i++ {
+		b.BroadcastMsgs(newMsgs(executor, "", int64(i+1)))
+	}
+	// oracle msgs are not blocked by them
+	b.BroadcastMsgs(newMsgs(oracle, btypes.OracleLane, 100))
+
+	oracleLane, err := b.laneByAddress(oracle)
+	require.NoError(t, err)
+	require.Equal(t, 1, oracleLane.lenQueuedMsgs())
+	msgs := <-oracleLane.txChannel
+	require.Equal(t, btypes.OracleLane, msgs.Lane)
+
+	// unknown lanes and lanes served by another account fall back to the default lane of the sender
+	executorLane, err := b.laneByAddress(executor)
+	require.NoError(t, err)
+	b.BroadcastMsgs(newMsgs(executor, "unknown", 200))
+	b.BroadcastMsgs(newMsgs(executor, btypes.OracleLane, 201))
+	require.Equal(t, 32, executorLane.lenQueuedMsgs())
+	require.Equal(t, 0, oracleLane.lenQueuedMsgs())
+
+	// the msgs of the sender without an account are not dropped silently
+	require.ErrorContains(t, b.BroadcastMsgs(newMsgs("unknown", "", 202)), "broadcaster lane not found")
+
+	// the lane is persisted with the overflowed msgs
+	processedMsgsList, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, processedMsgsList, 22)
+	require.Equal(t, "unknown", processedMsgsList[20].Lane)
+	require.Equal(t, btypes.OracleLane, processedMsgsList[21].Lane)
+
+	// the lane is kept in the pending tx
+	require.NoError(t, b.addPendingTx(msgs, 0, []byte("tx"), btypes.TxHash([]byte("tx")), "", 0))
+	pendingTxs, err := b.loadPendingTxs()
+	require.NoError(t, err)
+	require.Len(t, pendingTxs, 1)
+	require.Equal(t, btypes.OracleLane, pendingTxs[0].Lane)
+}
+
+func Test_DropQueuedMsgs(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 2, "oracle")
+	oracle := addresses[0]
+	b.laneRoutes[btypes.OracleLane] = 0
+
+	newMsgs := func(lane string, timestamp int64) btypes.ProcessedMsgs {
+		return btypes.ProcessedMsgs{
+			Sender:    oracle,
+			Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: oracle, ToAddress: oracle}},
+			Timestamp: timestamp,
+			Lane:      lane,
+		}
+	}
+	isOracleMsgs := func(msgs btypes.ProcessedMsgs) bool {
+		return msgs.Lane == btypes.OracleLane
+	}
+
+	// the first update is broadcasted and pending
+	lane, err := b.laneByAddress(oracle)
+	require.NoError(t, err)
+	b.BroadcastMsgs(newMsgs(btypes.OracleLane, 1))
+	pending := <-lane.txChannel
+	require.NoError(t, b.addPendingTx(pending, 0, []byte("tx"), btypes.TxHash([]byte("tx")), "", 0))
+
+	// the following updates are queued, and some of them are overflowed
+	b.BroadcastMsgs(newMsgs(btypes.OracleLane, 2))
+	b.BroadcastMsgs(newMsgs("", 3))
+	b.BroadcastMsgs(newMsgs(btypes.OracleLane, 4))
+	b.BroadcastMsgs(newMsgs(btypes.OracleLane, 5))
+	require.Equal(t, 4, b.LenQueuedMsgs())
+	require.Equal(t, 2, b.LenOverflowedMsgs())
+
+	// the queued updates are superseded, while the pending tx and the other msgs are kept
+	dropped, err := b.DropQueuedMsgs(oracle, isOracleMsgs)
+	require.NoError(t, err)
+	require.Equal(t, 3, dropped)
+	require.Equal(t, 1, b.LenLocalPendingTx())
+	require.Equal(t, 1, b.LenQueuedMsgs())
+	require.Equal(t, 0, b.LenOverflowedMsgs())
+	msgs := <-lane.txChannel
+	require.Equal(t, int64(3), msgs.Timestamp)
+
+	// the dropped overflowed msgs are deleted from the db
+	processedMsgs, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Empty(t, processedMsgs)
+
+	_, err = b.DropQueuedMsgs("unknown", isOracleMsgs)
+	require.Error(t, err)
+}
+
+func Test_RewriteQueuedMsgs(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 1, "proposer", "other")
+	proposer, other := addresses[0], addresses[1]
+
+	newMsgs := func(to string, timestamp int64) btypes.ProcessedMsgs {
+		return btypes.ProcessedMsgs{
+			Sender:    proposer,
+			Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: proposer, ToAddress: to}},
+			Timestamp: timestamp,
+			Save:      true,
+		}
+	}
+	redirect := func(msg sdk.Msg) (sdk.Msg, bool) {
+		send, ok := msg.(*banktypes.MsgSend)
+		if !ok || send.ToAddress != "old" {
+			return msg, false
+		}
+		rewritten := *send
+		rewritten.ToAddress = other
+		return &rewritten, true
+	}
+
+	// the first msgs are queued in the channel, and the others are overflowed
+	b.BroadcastMsgs(newMsgs("old", 1))
+	b.BroadcastMsgs(newMsgs(proposer, 2))
+	b.BroadcastMsgs(newMsgs("old", 3))
+	require.Equal(t, 3, b.LenQueuedMsgs())
+	require.Equal(t, 2, b.LenOverflowedMsgs())
+
+	// only the overflowed msgs are saved in the db
+	rewritten, err := b.RewriteQueuedMsgs(proposer, redirect)
+	require.NoError(t, err)
+	require.Equal(t, 1, rewritten)
+	require.Equal(t, 3, b.LenQueuedMsgs())
+
+	// the order is kept and the saved msgs are rewritten in the db
+	lane, err := b.laneByAddress(proposer)
+	require.NoError(t, err)
+	// the queued msgs are rewritten by the lane loop
+	msgs := b.rewriteLaneMsgs(lane, <-lane.txChannel)
+	require.Equal(t, int64(1), msgs.Timestamp)
+	require.Equal(t, other, msgs.Msgs[0].(*banktypes.MsgSend).ToAddress)
+
+	saved, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, saved, 3)
+	for i, to := range []string{other, proposer, other} {
+		require.Equal(t, int64(i+1), saved[i].Timestamp)
+		require.Equal(t, to, saved[i].Msgs[0].(*banktypes.MsgSend).ToAddress)
+	}
+
+	_, err = b.RewriteQueuedMsgs("unknown", redirect)
+	require.Error(t, err)
+}
+
+func Test_RewriteQueuedMsgsAfterRegistration(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 3, "proposer", "other")
+	proposer, other := addresses[0], addresses[1]
+
+	newMsgs := func(timestamp int64) btypes.ProcessedMsgs {
+		return btypes.ProcessedMsgs{
+			Sender:    proposer,
+			Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: proposer, ToAddress: "old"}},
+			Timestamp: timestamp,
+		}
+	}
+	redirect := func(msg sdk.Msg) (sdk.Msg, bool) {
+		send, ok := msg.(*banktypes.MsgSend)
+		if !ok || send.ToAddress != "old" {
+			return msg, false
+		}
+		rewritten := *send
+		rewritten.ToAddress = other
+		return &rewritten, true
+	}
+
+	lane, err := b.laneByAddress(proposer)
+	require.NoError(t, err)
+
+	// no msgs are queued, so the rewriter is not kept
+	_, err = b.RewriteQueuedMsgs(proposer, redirect)
+	require.NoError(t, err)
+	require.Empty(t, lane.rewriters)
+
+	b.BroadcastMsgs(newMsgs(1))
+	b.BroadcastMsgs(newMsgs(2))
+	_, err = b.RewriteQueuedMsgs(proposer, redirect)
+	require.NoError(t, err)
+	b.BroadcastMsgs(newMsgs(3))
+
+	// the dropped msg is not counted by the rewriter
+	dropped, err := b.DropQueuedMsgs(proposer, func(msgs btypes.ProcessedMsgs) bool {
+		return msgs.Timestamp == 1
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+
+	// only the msg queued before the registration is rewritten
+	msgs := b.rewriteLaneMsgs(lane, <-lane.txChannel)
+	require.Equal(t, int64(2), msgs.Timestamp)
+	require.Equal(t, other, msgs.Msgs[0].(*banktypes.MsgSend).ToAddress)
+	require.Empty(t, lane.rewriters)
+
+	// the msg queued after the registration is left unchanged
+	msgs = b.rewriteLaneMsgs(lane, <-lane.txChannel)
+	require.Equal(t, int64(3), msgs.Timestamp)
+	require.Equal(t, "old", msgs.Msgs[0].(*banktypes.MsgSend).ToAddress)
+}
+
+func Test_PersistQueuedMsgs(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 2, "sender")
+	sender := addresses[0]
+
+	newMsgs := func(timestamp int64, save bool) btypes.ProcessedMsgs {
+		return btypes.ProcessedMsgs{
+			Sender:    sender,
+			Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: sender, ToAddress: sender}},
+			Timestamp: timestamp,
+			Save:      save,
+		}
+	}
+
+	// the msgs left in the lane, some of which are overflowed to the db
+	b.BroadcastMsgs(newMsgs(1, true))
+	b.BroadcastMsgs(newMsgs(2, false))
+	b.BroadcastMsgs(newMsgs(3, false))
+	b.BroadcastMsgs(newMsgs(4, true))
+	require.Equal(t, 4, b.LenQueuedMsgs())
+	require.Equal(t, 2, b.LenOverflowedMsgs())
+
+	// the msgs to be saved are persisted to be broadcasted after the restart
+	persisted, discarded, err := b.PersistQueuedMsgs()
+	require.NoError(t, err)
+	require.Equal(t, 3, persisted)
+	require.Equal(t, 1, discarded)
+	require.Equal(t, 0, b.LenQueuedMsgs())
+	require.Equal(t, 0, b.LenOverflowedMsgs())
+
+	saved, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	timestamps := make([]int64, 0, len(saved))
+	for _, msgs := range saved {
+		timestamps = append(timestamps, msgs.Timestamp)
+	}
+	require.ElementsMatch(t, []int64{1, 3, 4}, timestamps)
+}
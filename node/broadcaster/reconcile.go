@@ -0,0 +1,48 @@
+package broadcaster
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// reconcileSequence compares the cached account number and sequence of the account with the chain every
+// SequenceReconcileInterval txs or after a failed tx, and replaces the cached values that the chain contradicts.
+//
+// The chain sequence lags behind the cached sequence while the txs of the account are pending, so a lower
+// chain sequence is only taken when the account has no pending txs. A higher chain sequence means the txs
+// signed outside of the bot have been included, and it is always taken.
+func (b *Broadcaster) reconcileSequence(ctx context.Context, account *BroadcasterAccount) error {
+	if !account.reconcileRequired.Load() && account.txsSinceReconcile < b.cfg.GetSequenceReconcileInterval() {
+		return nil
+	}
+
+	chainAccount, err := account.GetAccount(account.getClientCtx(ctx), account.GetAddress())
+	if err != nil {
+		return err
+	}
+
+	if chainAccount.GetAccountNumber() != account.AccountNumber() {
+		b.logger.Warn("cached account number differs from the chain",
+			zap.String("address", account.GetAddressString()),
+			zap.Uint64("cached", account.AccountNumber()),
+			zap.Uint64("chain", chainAccount.GetAccountNumber()),
+		)
+		account.txf = account.txf.WithAccountNumber(chainAccount.GetAccountNumber())
+	}
+
+	cachedSequence, chainSequence := account.Sequence(), chainAccount.GetSequence()
+	pendingTxs := b.LenLocalPendingTxByAddress(account.GetAddressString())
+	if chainSequence > cachedSequence || (chainSequence < cachedSequence && pendingTxs == 0) {
+		b.logger.Warn("cached account sequence differs from the chain",
+			zap.String("address", account.GetAddressString()),
+			zap.Uint64("cached", cachedSequence),
+			zap.Uint64("chain", chainSequence),
+		)
+		account.UpdateSequence(chainSequence)
+	}
+
+	account.txsSinceReconcile = 0
+	account.reconcileRequired.Store(false)
+	return nil
+}
@@ -0,0 +1,82 @@
+package broadcaster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// txSequence returns the sequence of the signer of the broadcasted tx.
+func txSequence(t *testing.T, tx sdk.Tx) uint64 {
+	sigs, err := tx.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 1)
+	return sigs[0].Sequence
+}
+
+func Test_ReconcileSequence(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+	b.cfg.SequenceReconcileInterval = 2
+
+	chain := newMockChain(t, b, 0)
+	account := b.accounts[0]
+	require.NoError(t, account.Load(context.Background()))
+
+	broadcast := func(amount int) uint64 {
+		data := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, amount), Timestamp: 1}.WithTraceID()
+		require.NoError(t, b.handleProcessedMsgs(context.Background(), data, account))
+		return txSequence(t, chain.broadcasted[len(chain.broadcasted)-1])
+	}
+	// removePendingTxs removes the pending txs, which are included in blocks or dropped from the mempool
+	removePendingTxs := func(included bool) {
+		for b.LenLocalPendingTx() > 0 {
+			pendingTx, err := b.PeekLocalPendingTx()
+			require.NoError(t, err)
+			require.NoError(t, b.RemovePendingTx(pendingTx))
+			if included {
+				chain.sequence++
+			}
+		}
+	}
+
+	// the sequence is tracked locally after the first fetch
+	require.Equal(t, uint64(0), broadcast(1))
+	require.Equal(t, uint64(1), broadcast(2))
+
+	// an external tx bumps the sequence on the chain, which is taken after the reconcile interval
+	removePendingTxs(true)
+	chain.sequence++
+	require.Equal(t, uint64(3), broadcast(3))
+
+	// the pending txs explain the lower chain sequence, so the cached sequence is kept
+	require.Equal(t, uint64(4), broadcast(4))
+	require.Equal(t, uint64(5), broadcast(5))
+	require.Equal(t, uint64(6), account.Sequence())
+
+	// the failed tx reconciles the sequence before the next tx
+	removePendingTxs(true)
+	chain.sequence++
+	chain.simulate = func(sdk.Tx) error { return errors.New("out of gas") }
+	data := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 1), Timestamp: 1}.WithTraceID()
+	require.Error(t, b.handleProcessedMsgs(context.Background(), data, account))
+	chain.simulate = nil
+	require.Equal(t, uint64(7), broadcast(1))
+
+	// the txs dropped from the mempool leave the cached sequence ahead of the chain
+	removePendingTxs(false)
+	account.RequireReconcile()
+	require.Equal(t, uint64(7), broadcast(1))
+
+	// the cache is invalidated on the restart
+	chain.sequence = 20
+	require.NoError(t, account.Load(context.Background()))
+	require.Equal(t, uint64(20), broadcast(1))
+}
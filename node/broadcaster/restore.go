@@ -0,0 +1,160 @@
+package broadcaster
+
+import (
+	"context"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// restorePendingTxs drops the pending txs restored from the db which already landed on the chain,
+// and leaves the unresolved ones to the lanes of their senders, which resolve them asynchronously.
+// It returns the kv pairs to delete the landed pending txs.
+func (b *Broadcaster) restorePendingTxs(ctx context.Context, pendingTxs []btypes.PendingTxInfo) ([]types.RawKV, error) {
+	landedTxs := make([]btypes.PendingTxInfo, 0)
+	for _, pendingTx := range pendingTxs {
+		landed, err := b.isPendingTxLanded(ctx, pendingTx)
+		if err != nil {
+			return nil, err
+		} else if landed {
+			b.logger.Info("pending tx already processed", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash))
+			landedTxs = append(landedTxs, pendingTx)
+			continue
+		}
+
+		lane, err := b.laneByAddress(pendingTx.Sender)
+		if err != nil {
+			return nil, err
+		}
+		lane.addRestoredTx(pendingTx)
+		b.logger.Debug("unresolved pending tx", zap.String("tx", pendingTx.String()))
+	}
+	return b.PendingTxsToRawKV(landedTxs, true)
+}
+
+// isPendingTxLanded returns true if the pending tx is successfully included in a block.
+func (b *Broadcaster) isPendingTxLanded(ctx context.Context, pendingTx btypes.PendingTxInfo) (bool, error) {
+	txHash, err := hex.DecodeString(pendingTx.TxHash)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := b.rpcClient.QueryTx(ctx, txHash)
+	if err != nil && IsTxNotFoundErr(err, pendingTx.TxHash) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to query pending tx")
+	}
+	return res.TxResult.Code == 0, nil
+}
+
+// resolveRestoredTxs waits until the unresolved pending txs of the lane time out, and converts
+// the txs which are still not landed to the processed msgs to be broadcasted again.
+// It is called by the lane before broadcasting new msgs, so the msgs of the lane keep their order.
+func (b *Broadcaster) resolveRestoredTxs(ctx context.Context, account *BroadcasterAccount, lane *broadcastLane) ([]btypes.ProcessedMsgs, error) {
+	restoredTxs := lane.takeRestoredTxs()
+	if len(restoredTxs) == 0 {
+		return nil, nil
+	}
+
+	err := b.waitForPendingTxsTimeout(ctx, restoredTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	processedMsgsList := make([]btypes.ProcessedMsgs, 0)
+	for _, pendingTx := range restoredTxs {
+		landed, err := b.isPendingTxLanded(ctx, pendingTx)
+		if err != nil {
+			return nil, err
+		} else if landed {
+			b.logger.Info("pending tx processed", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash))
+			continue
+		} else if !pendingTx.Save {
+			continue
+		}
+
+		msgs, err := account.PendingTxToProcessedMsgs(pendingTx.Tx)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(msgs); i += 5 {
+			end := i + 5
+			if end > len(msgs) {
+				end = len(msgs)
+			}
+
+			processedMsgsList = append(processedMsgsList, btypes.ProcessedMsgs{
+				Sender:    pendingTx.Sender,
+				Msgs:      slices.Clone(msgs[i:end]),
+				Timestamp: time.Now().UnixNano(),
+				TraceID:   pendingTx.TraceID,
+				Lane:      pendingTx.Lane,
+				Save:      true,
+			})
+		}
+	}
+
+	// skip the msgs which already landed by the other txs
+	processedMsgsList, err = b.filterLandedProcessedMsgs(ctx, processedMsgsList)
+	if err != nil {
+		return nil, err
+	}
+
+	// replace the pending txs with the processed msgs atomically
+	kvs, err := b.PendingTxsToRawKV(restoredTxs, true)
+	if err != nil {
+		return nil, err
+	}
+	processedMsgsKVs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+	if err != nil {
+		return nil, err
+	}
+	err = b.db.RawBatchSet(append(kvs, processedMsgsKVs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the landed txs increased the sequence of the account
+	sequence, err := account.GetLatestSequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account.UpdateSequence(sequence)
+	return processedMsgsList, nil
+}
+
+// waitForPendingTxsTimeout waits until the pending txs can't be included in a block anymore.
+func (b *Broadcaster) waitForPendingTxsTimeout(ctx context.Context, pendingTxs []btypes.PendingTxInfo) error {
+	// the expiry is computed from the chain height if all pending txs have the timeout height
+	if timeoutHeight := maxTimeoutHeight(pendingTxs); timeoutHeight > 0 {
+		return b.waitForTimeoutHeight(ctx, 0, timeoutHeight)
+	}
+
+	header, err := b.rpcClient.Header(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to query latest header")
+	}
+
+	lastBlockTime := header.Header.Time
+	pendingTxTime := time.Unix(0, pendingTxs[len(pendingTxs)-1].Timestamp)
+	if timeoutTime := pendingTxTime.Add(b.cfg.TxTimeout); lastBlockTime.Before(timeoutTime) {
+		waitingTime := timeoutTime.Sub(lastBlockTime)
+		timer := time.NewTimer(waitingTime)
+		defer timer.Stop()
+
+		b.logger.Info("waiting for pending txs to be processed", zap.Duration("waiting_time", waitingTime))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
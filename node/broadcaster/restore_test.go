@@ -0,0 +1,157 @@
+package broadcaster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_RestorePendingTxs(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	chain := newMockChain(t, b, 10)
+	chain.sequence = 2
+
+	newPendingTx := func(amount int64, timeoutHeight uint64) btypes.PendingTxInfo {
+		msg := &banktypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uinit", amount))}
+		txBuilder := b.txConfig.NewTxBuilder()
+		require.NoError(t, txBuilder.SetMsgs(msg))
+		txBytes, err := b.txConfig.TxEncoder()(txBuilder.GetTx())
+		require.NoError(t, err)
+
+		return btypes.PendingTxInfo{
+			Sender:        sender,
+			Sequence:      uint64(amount),
+			Tx:            txBytes,
+			TxHash:        btypes.TxHash(txBytes),
+			Timestamp:     time.Now().UnixNano() + amount,
+			TimeoutHeight: timeoutHeight,
+			Save:          true,
+		}
+	}
+
+	// the first tx already landed, but the second one is not resolved yet
+	resolvedTx := newPendingTx(1, 20)
+	unresolvedTx := newPendingTx(2, 20)
+	chain.LandTx(resolvedTx.TxHash)
+
+	kvs, err := b.PendingTxsToRawKV([]btypes.PendingTxInfo{resolvedTx, unresolvedTx}, false)
+	require.NoError(t, err)
+	require.NoError(t, b.db.RawBatchSet(kvs...))
+
+	pendingTxs, err := b.loadPendingTxs()
+	require.NoError(t, err)
+	require.Len(t, pendingTxs, 2)
+
+	// the startup is not blocked by the pending txs; the tx timeout is a minute
+	start := time.Now()
+	kvs, err = b.restorePendingTxs(context.Background(), pendingTxs)
+	require.NoError(t, err)
+	require.NoError(t, b.db.RawBatchSet(kvs...))
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// the landed tx is dropped
+	pendingTxs, err = b.loadPendingTxs()
+	require.NoError(t, err)
+	require.Len(t, pendingTxs, 1)
+	require.Equal(t, unresolvedTx.TxHash, pendingTxs[0].TxHash)
+	traceID := pendingTxs[0].TraceID
+
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	require.Len(t, lane.restoredTxs, 1)
+
+	// the lane waits until the unresolved tx expires, then broadcasts its msgs again
+	account, err := b.AccountByAddress(sender)
+	require.NoError(t, err)
+	chain.SetAutoIncreaseHeight(true)
+	ctx := types.WithPollingInterval(context.Background(), time.Millisecond)
+	restoredMsgs, err := b.resolveRestoredTxs(ctx, account, lane)
+	require.NoError(t, err)
+	require.Greater(t, chain.LatestHeight(), int64(20))
+	require.Len(t, restoredMsgs, 1)
+	require.Equal(t, traceID, restoredMsgs[0].TraceID)
+	require.Equal(t, int64(2), restoredMsgs[0].Msgs[0].(*banktypes.MsgSend).Amount.AmountOf("uinit").Int64())
+	require.Equal(t, uint64(2), account.Sequence())
+	require.Len(t, lane.restoredTxs, 0)
+
+	// the pending tx is replaced with the processed msgs
+	pendingTxs, err = b.loadPendingTxs()
+	require.NoError(t, err)
+	require.Len(t, pendingTxs, 0)
+	processedMsgs, err := b.loadProcessedMsgs()
+	require.NoError(t, err)
+	require.Len(t, processedMsgs, 1)
+}
+
+func Test_ResolveRestoredTxsLanded(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	chain := newMockChain(t, b, 10)
+	chain.sequence = 1
+	chain.SetAutoIncreaseHeight(true)
+
+	// the tx lands while the lane is waiting
+	pendingTx := btypes.PendingTxInfo{
+		Sender:        sender,
+		Tx:            []byte("tx"),
+		TxHash:        btypes.TxHash([]byte("tx")),
+		Timestamp:     1,
+		TimeoutHeight: 12,
+		Save:          true,
+	}
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	lane.addRestoredTx(pendingTx)
+	chain.LandTx(pendingTx.TxHash)
+
+	account, err := b.AccountByAddress(sender)
+	require.NoError(t, err)
+	ctx := types.WithPollingInterval(context.Background(), time.Millisecond)
+	restoredMsgs, err := b.resolveRestoredTxs(ctx, account, lane)
+	require.NoError(t, err)
+	require.Len(t, restoredMsgs, 0)
+	require.Equal(t, uint64(1), account.Sequence())
+}
+
+func Test_BroadcastMsgsBeforePrepare(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	b.prepared = false
+
+	saved := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 1), Timestamp: 1, Save: true}
+	savedLater := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 2), Timestamp: 2, Save: true}
+	oracle := btypes.ProcessedMsgs{Sender: sender, Msgs: testSendMsgs(sender, 3), Timestamp: 3, Save: false}
+
+	// the msgs broadcasted while the node is catching up are buffered
+	require.NoError(t, b.BroadcastMsgs(saved))
+	require.NoError(t, b.BroadcastMsgs(oracle))
+	require.Equal(t, 0, lane.lenQueuedMsgs())
+
+	// the saved msgs are restored from the db by the preparation, including the msgs saved before
+	// their producer broadcasts them
+	restored := []btypes.ProcessedMsgs{saved.WithTraceID(), savedLater.WithTraceID()}
+	b.pendingProcessedMsgs = append(b.pendingProcessedMsgs, restored...)
+	b.markPrepared(restored)
+	require.NoError(t, b.BroadcastPendingProcessedMsgs())
+	require.NoError(t, b.BroadcastMsgs(savedLater))
+
+	// every msgs are queued once
+	require.Equal(t, 3, lane.lenQueuedMsgs())
+	for _, count := range []int{1, 2, 3} {
+		msgs := <-lane.txChannel
+		require.Len(t, msgs.Msgs, count)
+	}
+}
@@ -0,0 +1,196 @@
+package broadcaster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/initia-labs/opinit-bots/keys"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// RegisterMsgResigner registers the function replacing the signer of the msg type,
+// which is used to re-sign the queued msgs when the key is rotated.
+// It should be called before the broadcaster is initialized.
+func (b *Broadcaster) RegisterMsgResigner(msgType string, fn btypes.MsgResignerFn) {
+	b.msgResigners[msgType] = fn
+}
+
+// KeyAddress returns the bech32 address of the key of the keyring config.
+func (b Broadcaster) KeyAddress(keyringConfig btypes.KeyringConfig) (string, error) {
+	_, keyringRecord, err := b.cfg.GetKeyringRecord(b.cdc, &keyringConfig)
+	if err != nil {
+		return "", err
+	}
+	addr, err := keyringRecord.GetAddress()
+	if err != nil {
+		return "", err
+	}
+	return keys.EncodeBech32AccAddr(addr, b.cfg.Bech32Prefix)
+}
+
+// RotateKey replaces the account of the key `name` with the account of the keyring config without restart.
+// The queued msgs of the account are re-signed by the new account and broadcasted in the same order,
+// while the pending txs signed before the rotation are still resolved with the retired account.
+func (b *Broadcaster) RotateKey(ctx context.Context, name string, keyringConfig btypes.KeyringConfig) error {
+	account, err := NewBroadcasterAccount(b.cfg, b.cdc, b.txConfig, b.rpcClient, keyringConfig)
+	if err != nil {
+		return err
+	}
+	account.queryConn = b.queryConn
+	err = account.Load(ctx)
+	if err != nil {
+		return err
+	}
+	return b.rotateAccount(name, account)
+}
+
+// rotateAccount swaps the account of the key `name` with the new account, and re-signs the queued msgs of its lane.
+func (b *Broadcaster) rotateAccount(name string, account *BroadcasterAccount) error {
+	oldAccount, lane, err := b.swapAccount(name, account)
+	if err != nil {
+		return err
+	}
+
+	b.logger.Info("broadcaster key rotated",
+		zap.String("old_key", oldAccount.KeyName()),
+		zap.String("old_address", oldAccount.GetAddressString()),
+		zap.String("new_key", account.KeyName()),
+		zap.String("new_address", account.GetAddressString()),
+	)
+	return b.resignQueuedMsgs(lane, oldAccount.GetAddressString())
+}
+
+// swapAccount replaces the account of the key `name` in all routes atomically, and moves its lane to the new address.
+func (b *Broadcaster) swapAccount(name string, account *BroadcasterAccount) (*BroadcasterAccount, *broadcastLane, error) {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+	// the pending txs are peeked by the accounts
+	b.pendingTxMu.Lock()
+	defer b.pendingTxMu.Unlock()
+
+	index := -1
+	for i, a := range b.accounts {
+		if a.KeyName() == name {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, nil, errors.Errorf("broadcaster account not found; key name: %s", name)
+	}
+
+	oldAccount := b.accounts[index]
+	oldAddress, newAddress := oldAccount.GetAddressString(), account.GetAddressString()
+	if _, ok := b.addressAccountMap[newAddress]; ok {
+		return nil, nil, errors.Errorf("broadcaster account already exists; address: %s", newAddress)
+	}
+
+	lane, ok := b.lanes[oldAddress]
+	if !ok {
+		return nil, nil, errors.Errorf("broadcaster lane not found; address: %s", oldAddress)
+	}
+
+	b.accounts[index] = account
+	delete(b.addressAccountMap, oldAddress)
+	b.addressAccountMap[newAddress] = index
+	delete(b.lanes, oldAddress)
+	b.lanes[newAddress] = lane
+
+	b.retiredAccounts[oldAddress] = oldAccount
+	delete(b.retiredAccounts, newAddress)
+	delete(b.rotatedSenders, newAddress)
+	for retired, sender := range b.rotatedSenders {
+		if sender == oldAddress {
+			b.rotatedSenders[retired] = newAddress
+		}
+	}
+	b.rotatedSenders[oldAddress] = newAddress
+	return oldAccount, lane, nil
+}
+
+// resignQueuedMsgs re-signs the saved msgs of the old sender with the new sender, including the overflowed msgs,
+// so they are restored with the new sender after a restart. The msgs in the queue of the lane are left to the lane
+// loop, which re-signs the msgs of the rotated senders when it takes them, so the queue is not drained concurrently.
+func (b *Broadcaster) resignQueuedMsgs(lane *broadcastLane, oldAddress string) error {
+	// the overflowed msgs are not refilled while they are re-signed
+	lane.mu.Lock()
+	defer lane.mu.Unlock()
+
+	processedMsgsList, err := b.loadProcessedMsgs()
+	if err != nil {
+		return errors.Wrap(err, "failed to load queued msgs")
+	}
+	for _, msgs := range processedMsgsList {
+		if msgs.Sender != oldAddress {
+			continue
+		}
+		// redirected msgs are saved with the same key
+		b.redirectRotatedMsgs(msgs)
+	}
+	return nil
+}
+
+// redirectRotatedMsgs re-signs the msgs of a rotated sender with the new sender, and saves them
+// if they need to be saved. If the msgs can't be re-signed, they are left to be signed by the retired account.
+func (b Broadcaster) redirectRotatedMsgs(msgs btypes.ProcessedMsgs) btypes.ProcessedMsgs {
+	sender, ok := b.rotatedSender(msgs.Sender)
+	if !ok {
+		return msgs
+	}
+
+	resigned := make([]sdk.Msg, 0, len(msgs.Msgs))
+	for _, msg := range msgs.Msgs {
+		resigner, ok := b.msgResigners[sdk.MsgTypeURL(msg)]
+		if !ok {
+			b.logger.Warn("no msg resigner; broadcast with the retired account",
+				zap.String("trace_id", msgs.TraceID),
+				zap.String("sender", msgs.Sender),
+				zap.String("msg_type", sdk.MsgTypeURL(msg)),
+			)
+			return msgs
+		}
+
+		resignedMsg, err := resigner(msg, sender)
+		if err != nil {
+			b.logger.Warn("failed to re-sign msg; broadcast with the retired account",
+				zap.String("trace_id", msgs.TraceID),
+				zap.String("sender", msgs.Sender),
+				zap.String("msg_type", sdk.MsgTypeURL(msg)),
+				zap.String("error", err.Error()),
+			)
+			return msgs
+		}
+		resigned = append(resigned, resignedMsg)
+	}
+
+	b.logger.Debug("re-sign msgs of the rotated sender",
+		zap.String("trace_id", msgs.TraceID),
+		zap.String("old_sender", msgs.Sender),
+		zap.String("new_sender", sender),
+	)
+	msgs.Sender = sender
+	msgs.Msgs = resigned
+
+	if msgs.Save {
+		saved, err := b.saveProcessedMsgs(msgs)
+		if err != nil {
+			b.logger.Error("failed to save re-signed msgs", zap.String("trace_id", msgs.TraceID), zap.String("error", err.Error()))
+		} else {
+			msgs = saved
+		}
+	}
+	return msgs
+}
+
+// rotatedSender returns the address of the new account if the sender is rotated.
+func (b Broadcaster) rotatedSender(sender string) (string, bool) {
+	b.accountMu.Lock()
+	defer b.accountMu.Unlock()
+
+	newSender, ok := b.rotatedSenders[sender]
+	return newSender, ok
+}
@@ -0,0 +1,102 @@
+package broadcaster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/initia-labs/opinit-bots/keys"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+func resignMsgSend(msg sdk.Msg, signer string) (sdk.Msg, error) {
+	resigned := *msg.(*banktypes.MsgSend)
+	resigned.FromAddress = signer
+	return &resigned, nil
+}
+
+func Test_RotateKey(t *testing.T) {
+	maxQueuedMsgs := 3
+	b, addresses := newTestBroadcaster(t, maxQueuedMsgs, "proposer")
+	oldAddress := addresses[0]
+	b.RegisterMsgResigner(sdk.MsgTypeURL(&banktypes.MsgSend{}), resignMsgSend)
+
+	keyBase, err := keys.GetKeyBase(b.cfg.ChainID, b.cfg.HomePath, b.cdc, nil)
+	require.NoError(t, err)
+	mnemonic, err := keys.CreateMnemonic()
+	require.NoError(t, err)
+	_, err = keyBase.NewAccount("standby", mnemonic, "", sdk.FullFundraiserPath, hd.Secp256k1)
+	require.NoError(t, err)
+
+	keyringConfig := btypes.KeyringConfig{Name: "standby"}
+	newAccount, err := NewBroadcasterAccount(b.cfg, b.cdc, b.txConfig, b.rpcClient, keyringConfig)
+	require.NoError(t, err)
+	newAddress := newAccount.GetAddressString()
+
+	keyAddress, err := b.KeyAddress(keyringConfig)
+	require.NoError(t, err)
+	require.Equal(t, newAddress, keyAddress)
+
+	newMsgs := func(sender string, amount int64) btypes.ProcessedMsgs {
+		return btypes.ProcessedMsgs{
+			Sender:    sender,
+			Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uinit", amount))}},
+			Timestamp: amount,
+			Save:      true,
+		}
+	}
+
+	// queue msgs more than the queue size, so some of them are overflowed
+	count := 5
+	for i := 1; i <= count; i++ {
+		msgs := newMsgs(oldAddress, int64(i))
+		kvs, err := b.ProcessedMsgsToRawKV([]btypes.ProcessedMsgs{msgs}, false)
+		require.NoError(t, err)
+		require.NoError(t, b.db.RawBatchSet(kvs...))
+		b.BroadcastMsgs(msgs)
+	}
+	require.Equal(t, count-maxQueuedMsgs, b.LenOverflowedMsgs())
+
+	// rotate in the middle of the queue
+	require.NoError(t, b.rotateAccount("proposer", newAccount))
+
+	base, err := b.AccountByIndex(0)
+	require.NoError(t, err)
+	require.Equal(t, newAddress, base.GetAddressString())
+
+	// the retired account is kept to resolve the pending txs signed before the rotation
+	retired, err := b.AccountByAddress(oldAddress)
+	require.NoError(t, err)
+	require.Equal(t, "proposer", retired.KeyName())
+
+	// msgs of the old sender broadcasted after the rotation are redirected
+	b.BroadcastMsgs(newMsgs(oldAddress, int64(count+1)))
+	count++
+
+	require.Equal(t, count, b.LenQueuedMsgsByAddress(newAddress))
+
+	lane, err := b.laneByAddress(newAddress)
+	require.NoError(t, err)
+	for i := 1; i <= count; i++ {
+		require.NoError(t, b.refillTxChannel(lane))
+		// the lane loop re-signs the queued msgs when it takes them
+		msgs := b.redirectRotatedMsgs(<-lane.txChannel)
+		require.Equal(t, int64(i), msgs.Timestamp)
+		require.Equal(t, newAddress, msgs.Sender)
+		require.Equal(t, newAddress, msgs.Msgs[0].(*banktypes.MsgSend).FromAddress)
+
+		// the saved msgs are re-signed too, so they are restored with the new sender
+		saved, err := b.loadProcessedMsgsByKey(msgs.Key())
+		require.NoError(t, err)
+		require.Equal(t, newAddress, saved.Sender)
+		require.Equal(t, newAddress, saved.Msgs[0].(*banktypes.MsgSend).FromAddress)
+	}
+	require.Equal(t, 0, b.LenQueuedMsgs())
+
+	// the retired key can't be rotated again
+	require.Error(t, b.rotateAccount("proposer", newAccount))
+}
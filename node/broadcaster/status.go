@@ -7,16 +7,23 @@ import (
 func (b Broadcaster) GetStatus() btypes.BroadcasterStatus {
 	return btypes.BroadcasterStatus{
 		PendingTxs:     b.LenLocalPendingTx(),
+		QueuedMsgs:     b.LenQueuedMsgs(),
+		OverflowedMsgs: b.LenOverflowedMsgs(),
+		LowBalance:     b.LowBalance(),
 		AccountsStatus: b.getAccountsStatus(),
 	}
 }
 
 func (b Broadcaster) getAccountsStatus() []btypes.BroadcasterAccountStatus {
-	accountsStatus := make([]btypes.BroadcasterAccountStatus, 0, len(b.accounts))
-	for _, account := range b.accounts {
+	accounts := b.activeAccounts()
+	accountsStatus := make([]btypes.BroadcasterAccountStatus, 0, len(accounts))
+	for _, account := range accounts {
 		accountsStatus = append(accountsStatus, btypes.BroadcasterAccountStatus{
-			Address:  account.addressString,
-			Sequence: account.Sequence(),
+			Address:    account.addressString,
+			Sequence:   account.Sequence(),
+			PendingTxs: b.LenLocalPendingTxByAddress(account.addressString),
+			QueuedMsgs: b.LenQueuedMsgsByAddress(account.addressString),
+			LowBalance: b.IsLowBalance(account.addressString),
 		})
 	}
 	return accountsStatus
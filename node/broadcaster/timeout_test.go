@@ -0,0 +1,186 @@
+package broadcaster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+	comettypes "github.com/cometbft/cometbft/types"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// mockChain is the fake chain serving the header, tx and account queries to the broadcaster.
+type mockChain struct {
+	*nodetest.FakeClient
+
+	// sequence is the sequence of all accounts.
+	sequence uint64
+
+	// simulate returns the error of the simulated tx. If it is nil, all txs pass the simulation.
+	simulate func(tx sdk.Tx) error
+	// broadcasted is the list of the decoded broadcasted txs.
+	broadcasted []sdk.Tx
+}
+
+// newMockChain returns the mock chain at the given height, which is set to the rpc client of the broadcaster
+// and its accounts.
+func newMockChain(t *testing.T, b *Broadcaster, height int64) *mockChain {
+	chain := &mockChain{FakeClient: nodetest.NewFakeClient(b.cfg.ChainID)}
+	chain.SetLatestHeight(height)
+
+	txDecoder := b.txConfig.TxDecoder()
+	chain.HandleQuery("/cosmos.tx.v1beta1.Service/Simulate", func(req abcitypes.RequestQuery) (abcitypes.ResponseQuery, error) {
+		var simReq txtypes.SimulateRequest
+		require.NoError(t, simReq.Unmarshal(req.Data))
+		tx, err := txDecoder(simReq.TxBytes)
+		require.NoError(t, err)
+
+		if chain.simulate != nil {
+			if err := chain.simulate(tx); err != nil {
+				return abcitypes.ResponseQuery{Code: 1, Log: err.Error()}, nil
+			}
+		}
+		bz, err := (&txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 100_000}, Result: &sdk.Result{}}).Marshal()
+		require.NoError(t, err)
+		return abcitypes.ResponseQuery{Value: bz}, nil
+	})
+	chain.HandleQuery("/cosmos.auth.v1beta1.Query/Account", func(abcitypes.RequestQuery) (abcitypes.ResponseQuery, error) {
+		account, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{Sequence: chain.sequence})
+		require.NoError(t, err)
+		bz, err := (&authtypes.QueryAccountResponse{Account: account}).Marshal()
+		require.NoError(t, err)
+		return abcitypes.ResponseQuery{Value: bz}, nil
+	})
+	chain.SetBroadcastFn(func(tx comettypes.Tx) (*rpccoretypes.ResultBroadcastTx, error) {
+		decoded, err := txDecoder(tx)
+		if err != nil {
+			return nil, err
+		}
+		chain.broadcasted = append(chain.broadcasted, decoded)
+		return &rpccoretypes.ResultBroadcastTx{Hash: tx.Hash()}, nil
+	})
+
+	b.rpcClient = chain
+	for _, account := range b.accounts {
+		account.rpcClient = chain
+	}
+	return chain
+}
+
+func Test_TxTimeoutHeight(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	newMockChain(t, b, 20)
+
+	// the timeout height is computed from the latest height
+	b.cfg.TxTimeoutHeight = 10
+	timeoutHeight, err := b.timeoutHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), timeoutHeight)
+
+	b.cfg.TxTimeoutHeight = 0
+	timeoutHeight, err = b.timeoutHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), timeoutHeight)
+
+	// the wall clock timeout has passed long ago, but the tx can be included until the timeout height
+	pendingTx := btypes.PendingTxInfo{
+		Sender:        sender,
+		Tx:            []byte("tx"),
+		TxHash:        btypes.TxHash([]byte("tx")),
+		Timestamp:     1,
+		TimeoutHeight: 20,
+	}
+	require.False(t, pendingTx.Expired(20))
+	require.True(t, pendingTx.Expired(21))
+	require.False(t, btypes.PendingTxInfo{}.Expired(100))
+
+	_, _, err = b.CheckPendingTx(context.Background(), pendingTx)
+	require.ErrorIs(t, err, types.ErrTxNotFound)
+}
+
+func Test_WaitForTimeoutHeight(t *testing.T) {
+	b, _ := newTestBroadcaster(t, 10, "sender")
+
+	chain := newMockChain(t, b, 10)
+	chain.SetAutoIncreaseHeight(true)
+
+	// the expiry of the restored pending txs is computed from the chain height
+	require.Equal(t, uint64(0), maxTimeoutHeight([]btypes.PendingTxInfo{{TimeoutHeight: 15}, {}}))
+	timeoutHeight := maxTimeoutHeight([]btypes.PendingTxInfo{{TimeoutHeight: 15}, {TimeoutHeight: 13}})
+	require.Equal(t, uint64(15), timeoutHeight)
+
+	ctx := types.WithPollingInterval(context.Background(), time.Millisecond)
+	require.NoError(t, b.waitForTimeoutHeight(ctx, 10, timeoutHeight))
+	require.Equal(t, int64(16), chain.LatestHeight())
+
+	// already expired
+	require.NoError(t, b.waitForTimeoutHeight(ctx, 20, timeoutHeight))
+	require.Equal(t, int64(16), chain.LatestHeight())
+}
+
+func Test_ResubmitPendingTx(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	msg := &banktypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uinit", 1))}
+	txBuilder := b.txConfig.NewTxBuilder()
+	require.NoError(t, txBuilder.SetMsgs(msg))
+	txBytes, err := b.txConfig.TxEncoder()(txBuilder.GetTx())
+	require.NoError(t, err)
+
+	data := btypes.ProcessedMsgs{Sender: sender, Msgs: []sdk.Msg{msg}, Timestamp: 1, Save: true}.WithTraceID()
+	require.NoError(t, b.addPendingTx(data, 0, txBytes, btypes.TxHash(txBytes), "", 30))
+
+	pendingTx, err := b.PeekLocalPendingTx()
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), pendingTx.TimeoutHeight)
+	require.NoError(t, b.ResubmitPendingTx(pendingTx))
+
+	// the pending tx is replaced with the processed msgs
+	require.Equal(t, 0, b.LenLocalPendingTx())
+	trace, err := b.TraceTx(data.TraceID)
+	require.NoError(t, err)
+	require.Len(t, trace.PendingTxs, 0)
+	require.Len(t, trace.ProcessedMsgs, 1)
+
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	resubmitted := <-lane.txChannel
+	require.Equal(t, data.TraceID, resubmitted.TraceID)
+	require.Equal(t, uint32(1), resubmitted.Resubmissions)
+	require.Len(t, resubmitted.Msgs, 1)
+	require.Equal(t, msg.Amount, resubmitted.Msgs[0].(*banktypes.MsgSend).Amount)
+}
+
+func Test_GasPriceEscalation(t *testing.T) {
+	cfg := btypes.BroadcasterConfig{GasPriceEscalation: 1.5, MaxGasPriceMultiplier: 3}
+	require.Equal(t, 1.0, cfg.GasPriceMultiplier(0))
+	require.Equal(t, 1.5, cfg.GasPriceMultiplier(1))
+	require.Equal(t, 2.25, cfg.GasPriceMultiplier(2))
+	require.Equal(t, 3.0, cfg.GasPriceMultiplier(3))
+
+	// no escalation
+	cfg.GasPriceEscalation = 0
+	require.Equal(t, 1.0, cfg.GasPriceMultiplier(5))
+
+	require.True(t, isPermanentErr(errors.Wrap(txResponseError{codespace: sdkerrors.ErrTxTooLarge.Codespace(), code: sdkerrors.ErrTxTooLarge.ABCICode(), log: "tx too large"}, "failed")))
+	require.False(t, isPermanentErr(txResponseError{codespace: sdkerrors.ErrInsufficientFee.Codespace(), code: sdkerrors.ErrInsufficientFee.ABCICode(), log: "too large fees"}))
+	require.False(t, isPermanentErr(errors.New("tx too large")))
+}
@@ -0,0 +1,42 @@
+package broadcaster
+
+import (
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+// TraceTx returns the stored processed msgs and pending txs of the trace id.
+// The pending txs which are not saved to the db are found from the local pending txs.
+func (b Broadcaster) TraceTx(traceID string) (btypes.TxTrace, error) {
+	trace := btypes.TxTrace{TraceID: traceID}
+
+	processedMsgsList, err := b.loadProcessedMsgs()
+	if err != nil {
+		return btypes.TxTrace{}, err
+	}
+	for _, processedMsgs := range processedMsgsList {
+		if processedMsgs.TraceID == traceID {
+			trace.ProcessedMsgs = append(trace.ProcessedMsgs, processedMsgs)
+		}
+	}
+
+	pendingTxs, err := b.loadPendingTxs()
+	if err != nil {
+		return btypes.TxTrace{}, err
+	}
+	for _, pendingTx := range pendingTxs {
+		if pendingTx.TraceID == traceID {
+			trace.PendingTxs = append(trace.PendingTxs, pendingTx)
+		}
+	}
+
+	b.pendingTxMu.Lock()
+	defer b.pendingTxMu.Unlock()
+	for _, localPendingTxs := range b.pendingTxs {
+		for _, pendingTx := range localPendingTxs {
+			if pendingTx.TraceID == traceID && !pendingTx.Save {
+				trace.PendingTxs = append(trace.PendingTxs, pendingTx)
+			}
+		}
+	}
+	return trace, nil
+}
@@ -0,0 +1,115 @@
+package broadcaster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+)
+
+func Test_TraceTx(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	b.logger = zap.New(core)
+
+	// enqueue
+	b.BroadcastMsgs(btypes.ProcessedMsgs{
+		Sender:    sender,
+		Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: sender, ToAddress: sender}},
+		Timestamp: 1,
+		Save:      true,
+	})
+	lane, err := b.laneByAddress(sender)
+	require.NoError(t, err)
+	data := <-lane.txChannel
+
+	traceID := data.TraceID
+	require.NotEmpty(t, traceID)
+	// the trace id is kept once it is assigned
+	require.Equal(t, traceID, data.WithTraceID().TraceID)
+
+	processedMsgsList := []btypes.ProcessedMsgs{data}
+	kvs, err := b.ProcessedMsgsToRawKV(processedMsgsList, false)
+	require.NoError(t, err)
+	require.NoError(t, b.db.RawBatchSet(kvs...))
+	data = processedMsgsList[0]
+
+	trace, err := b.TraceTx(traceID)
+	require.NoError(t, err)
+	require.Len(t, trace.ProcessedMsgs, 1)
+	require.Len(t, trace.PendingTxs, 0)
+
+	// broadcast
+	require.NoError(t, b.deleteProcessedMsgs(data))
+	require.NoError(t, b.addPendingTx(data, 0, []byte("tx"), btypes.TxHash([]byte("tx")), "", 0))
+
+	trace, err = b.TraceTx(traceID)
+	require.NoError(t, err)
+	require.Len(t, trace.ProcessedMsgs, 0)
+	require.Len(t, trace.PendingTxs, 1)
+	require.Equal(t, traceID, trace.PendingTxs[0].TraceID)
+
+	// confirm
+	pendingTx, err := b.PeekLocalPendingTx()
+	require.NoError(t, err)
+	require.Equal(t, traceID, pendingTx.TraceID)
+	require.NoError(t, b.RemovePendingTx(pendingTx))
+
+	trace, err = b.TraceTx(traceID)
+	require.NoError(t, err)
+	require.Len(t, trace.ProcessedMsgs, 0)
+	require.Len(t, trace.PendingTxs, 0)
+
+	// every log along the lifecycle has the same trace id
+	messages := make([]string, 0)
+	for _, entry := range logs.All() {
+		if id, ok := entry.ContextMap()["trace_id"]; ok {
+			require.Equal(t, traceID, id)
+			messages = append(messages, entry.Message)
+		}
+	}
+	require.Equal(t, []string{"enqueue processed msgs", "pending tx added", "pending tx removed"}, messages)
+}
+
+func Test_TraceIDOfOldRecords(t *testing.T) {
+	b, addresses := newTestBroadcaster(t, 10, "sender")
+	sender := addresses[0]
+
+	// the records saved before the trace id was introduced
+	processedMsgs := btypes.ProcessedMsgs{
+		Sender:    sender,
+		Msgs:      []sdk.Msg{&banktypes.MsgSend{FromAddress: sender, ToAddress: sender}},
+		Timestamp: 1,
+		Save:      true,
+	}
+	data, err := processedMsgs.MarshalInterfaceJSON(b.cdc)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "trace_id")
+
+	var loaded btypes.ProcessedMsgs
+	require.NoError(t, loaded.UnmarshalInterfaceJSON(b.cdc, data))
+	require.Equal(t, processedMsgs.WithTraceID().TraceID, loaded.TraceID)
+
+	pendingTx := btypes.PendingTxInfo{Sender: sender, Tx: []byte("tx"), Timestamp: 1, Save: true}
+	data, err = pendingTx.Marshal()
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "trace_id")
+
+	var loadedPendingTx btypes.PendingTxInfo
+	require.NoError(t, loadedPendingTx.Unmarshal(data))
+	require.NotEmpty(t, loadedPendingTx.TraceID)
+
+	// the trace id is deterministic
+	var reloadedPendingTx btypes.PendingTxInfo
+	require.NoError(t, reloadedPendingTx.Unmarshal(data))
+	require.Equal(t, loadedPendingTx.TraceID, reloadedPendingTx.TraceID)
+}
@@ -6,12 +6,17 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/pkg/errors"
 
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
+
+	errorsmod "cosmossdk.io/errors"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 
 	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
 )
@@ -36,7 +41,8 @@ func (b *Broadcaster) handleMsgError(err error, broadcasterAccount *BroadcasterA
 			return parseErr
 		}
 
-		if expected > got {
+		// the local sequence can be ahead of the chain after the pending txs expired
+		if expected != got {
 			broadcasterAccount.UpdateSequence(expected)
 		}
 		return err
@@ -71,33 +77,121 @@ func (b *Broadcaster) handleMsgError(err error, broadcasterAccount *BroadcasterA
 	return err
 }
 
+// permanentErrs are the errors of the tx responses which can never be accepted with the same msgs.
+var permanentErrs = []*errorsmod.Error{
+	sdkerrors.ErrTxTooLarge,
+}
+
+// txResponseError is the error of the tx rejected with the non-zero code.
+type txResponseError struct {
+	codespace string
+	code      uint32
+	log       string
+}
+
+func (e txResponseError) Error() string {
+	return fmt.Sprintf("broadcast txs: %s", e.log)
+}
+
+// isPermanentErr returns true if the tx is rejected with the codespace and the code of the permanent errors,
+// e.g. the tx exceeds the max size of the chain.
+func isPermanentErr(err error) bool {
+	var resErr txResponseError
+	if !errors.As(err, &resErr) {
+		return false
+	}
+	for _, permanentErr := range permanentErrs {
+		if resErr.codespace == permanentErr.Codespace() && resErr.code == permanentErr.ABCICode() {
+			return true
+		}
+	}
+	return false
+}
+
+func isInsufficientFundsErr(log string) bool {
+	return strings.Contains(log, sdkerrors.ErrInsufficientFunds.Error()) || strings.Contains(log, sdkerrors.ErrInsufficientFee.Error())
+}
+
 // HandleProcessedMsgs handles processed messages by broadcasting them to the network.
 // It stores the transaction in the database and local memory and keep track of the successful broadcast.
-func (b *Broadcaster) handleProcessedMsgs(ctx context.Context, data btypes.ProcessedMsgs, broadcasterAccount *BroadcasterAccount) error {
+func (b *Broadcaster) handleProcessedMsgs(ctx context.Context, data btypes.ProcessedMsgs, broadcasterAccount *BroadcasterAccount) (err error) {
+	defer func() {
+		// the failed tx may leave the cached sequence out of sync with the chain
+		if err != nil {
+			broadcasterAccount.RequireReconcile()
+		}
+	}()
+
+	if err := b.reconcileSequence(ctx, broadcasterAccount); err != nil {
+		return err
+	}
 	sequence := broadcasterAccount.Sequence()
 
+	timeoutHeight, err := b.timeoutHeight(ctx)
+	if err != nil {
+		return err
+	}
+	broadcasterAccount.SetTimeoutHeight(timeoutHeight)
+
+	gasPrices, err := b.gasPriceSource.GasPrices(ctx)
+	if err != nil {
+		return err
+	}
+	broadcasterAccount.SetGasPrices(gasPrices, b.cfg.GasPriceMultiplier(data.Resubmissions))
+
 	txBytes, txHash, err := broadcasterAccount.BuildTxWithMessages(ctx, data.Msgs)
 	if err != nil {
-		return errors.Wrapf(err, "simulation failed")
+		if isInsufficientFundsErr(err.Error()) {
+			b.setLowBalance(broadcasterAccount.GetAddressString(), true)
+			return errors.Wrapf(types.ErrInsufficientBalance, "simulation failed: %s", err.Error())
+		}
+		return fmt.Errorf("%w: %w", errSimulationFailed, err)
 	}
 
+	start := time.Now()
 	res, err := b.rpcClient.BroadcastTxSync(ctx, txBytes)
+	b.metrics.RPCLatency.WithLabelValues(b.name, "broadcast_tx_sync").Observe(time.Since(start).Seconds())
 	if err != nil {
+		b.metrics.Broadcasts.WithLabelValues(b.name, "error").Inc()
 		// TODO: handle error, may repeat sending tx
 		return fmt.Errorf("broadcast txs: %w", err)
 	}
+	b.metrics.Broadcasts.WithLabelValues(b.name, strconv.FormatUint(uint64(res.Code), 10)).Inc()
 	if res.Code != 0 {
-		return fmt.Errorf("broadcast txs: %s", res.Log)
+		if isInsufficientFundsErr(res.Log) {
+			b.setLowBalance(broadcasterAccount.GetAddressString(), true)
+			return errors.Wrapf(types.ErrInsufficientBalance, "broadcast txs: %s", res.Log)
+		}
+		return txResponseError{codespace: res.Codespace, code: res.Code, log: res.Log}
 	}
 
-	b.logger.Debug("broadcast tx", zap.String("tx_hash", txHash), zap.Uint64("sequence", sequence))
+	b.logger.Debug("broadcast tx", zap.String("trace_id", data.TraceID), zap.String("tx_hash", txHash), zap.Uint64("sequence", sequence))
 
-	err = b.deleteProcessedMsgs(data.Timestamp)
+	err = b.deleteProcessedMsgs(data)
 	if err != nil {
 		return err
 	}
 
 	broadcasterAccount.IncreaseSequence()
+	return b.addPendingTx(data, sequence, txBytes, txHash, broadcasterAccount.Memo(), timeoutHeight)
+}
+
+// timeoutHeight returns the timeout height of the tx to be built from the latest height.
+// It returns 0 if the tx timeout height is not configured.
+func (b *Broadcaster) timeoutHeight(ctx context.Context) (uint64, error) {
+	if b.cfg.TxTimeoutHeight == 0 {
+		return 0, nil
+	}
+
+	header, err := b.rpcClient.Header(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query latest header")
+	}
+	return types.MustInt64ToUint64(header.Header.Height + b.cfg.TxTimeoutHeight), nil
+}
+
+// addPendingTx tracks the broadcasted tx of the processed msgs until it is included in a block.
+func (b *Broadcaster) addPendingTx(data btypes.ProcessedMsgs, sequence uint64, txBytes []byte, txHash string, memo string, timeoutHeight uint64) error {
 	pendingTx := btypes.PendingTxInfo{
 		Sender:          data.Sender,
 		ProcessedHeight: b.GetHeight(),
@@ -106,12 +200,17 @@ func (b *Broadcaster) handleProcessedMsgs(ctx context.Context, data btypes.Proce
 		TxHash:          txHash,
 		Timestamp:       data.Timestamp,
 		MsgTypes:        data.GetMsgTypes(),
+		Memo:            memo,
+		TraceID:         data.TraceID,
+		Lane:            data.Lane,
+		TimeoutHeight:   timeoutHeight,
+		Resubmissions:   data.Resubmissions,
 		Save:            data.Save,
 	}
 
 	if pendingTx.Save {
 		// save pending transaction to the database for handling after restart
-		err = b.savePendingTx(pendingTx)
+		err := b.savePendingTx(pendingTx)
 		if err != nil {
 			return err
 		}
@@ -119,6 +218,7 @@ func (b *Broadcaster) handleProcessedMsgs(ctx context.Context, data btypes.Proce
 
 	// save pending tx to local memory to handle this tx in this session
 	b.enqueueLocalPendingTx(pendingTx)
+	b.logger.Debug("pending tx added", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash), zap.Bool("save", pendingTx.Save))
 	return nil
 }
 
@@ -126,29 +226,78 @@ func (b *Broadcaster) enqueueLocalPendingTx(tx btypes.PendingTxInfo) {
 	b.pendingTxMu.Lock()
 	defer b.pendingTxMu.Unlock()
 
-	b.pendingTxs = append(b.pendingTxs, tx)
+	b.pendingTxs[tx.Sender] = append(b.pendingTxs[tx.Sender], tx)
+	b.updatePendingTxsMetric()
 }
 
+// PeekLocalPendingTx returns the oldest pending tx of the next account in round-robin order,
+// so a stuck pending tx of an account does not block the pending txs of the other accounts.
 func (b *Broadcaster) PeekLocalPendingTx() (btypes.PendingTxInfo, error) {
 	b.pendingTxMu.Lock()
 	defer b.pendingTxMu.Unlock()
 
-	if len(b.pendingTxs) == 0 {
-		return btypes.PendingTxInfo{}, errors.New("no pending txs")
+	// the pending txs signed before the key rotation are older than the others
+	for address := range b.retiredAccounts {
+		if pendingTxs := b.pendingTxs[address]; len(pendingTxs) > 0 {
+			return pendingTxs[0], nil
+		}
+	}
+
+	for i := 0; i < len(b.accounts); i++ {
+		index := (b.pendingTxCursor + i) % len(b.accounts)
+		if pendingTxs := b.pendingTxs[b.accounts[index].GetAddressString()]; len(pendingTxs) > 0 {
+			b.pendingTxCursor = (index + 1) % len(b.accounts)
+			return pendingTxs[0], nil
+		}
 	}
-	return b.pendingTxs[0], nil
+	return btypes.PendingTxInfo{}, errors.New("no pending txs")
 }
 
 func (b Broadcaster) LenLocalPendingTx() int {
 	b.pendingTxMu.Lock()
 	defer b.pendingTxMu.Unlock()
 
-	return len(b.pendingTxs)
+	count := 0
+	for _, pendingTxs := range b.pendingTxs {
+		count += len(pendingTxs)
+	}
+	return count
 }
 
-func (b *Broadcaster) dequeueLocalPendingTx() {
+func (b Broadcaster) LenLocalPendingTxByAddress(address string) int {
 	b.pendingTxMu.Lock()
 	defer b.pendingTxMu.Unlock()
 
-	b.pendingTxs = b.pendingTxs[1:]
+	return len(b.pendingTxs[address])
+}
+
+func (b *Broadcaster) dequeueLocalPendingTx(pendingTx btypes.PendingTxInfo) {
+	b.pendingTxMu.Lock()
+	defer b.pendingTxMu.Unlock()
+
+	b.removeLocalPendingTxLocked(pendingTx)
+}
+
+// removeLocalPendingTxLocked removes the given pending tx from the local pending txs of the sender.
+// The pending tx dropped by the operator while it is checked is not found, so the other pending txs are kept.
+// It should be called with pendingTxMu held.
+func (b *Broadcaster) removeLocalPendingTxLocked(pendingTx btypes.PendingTxInfo) {
+	pendingTxs := b.pendingTxs[pendingTx.Sender]
+	for i, localPendingTx := range pendingTxs {
+		if localPendingTx.TxHash == pendingTx.TxHash {
+			b.pendingTxs[pendingTx.Sender] = append(pendingTxs[:i:i], pendingTxs[i+1:]...)
+			b.updatePendingTxsMetric()
+			return
+		}
+	}
+}
+
+// updatePendingTxsMetric updates the pending txs gauge.
+// It should be called with pendingTxMu held.
+func (b *Broadcaster) updatePendingTxsMetric() {
+	count := 0
+	for _, pendingTxs := range b.pendingTxs {
+		count += len(pendingTxs)
+	}
+	b.metrics.PendingTxs.WithLabelValues(b.name).Set(float64(count))
 }
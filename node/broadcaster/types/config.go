@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/initia-labs/opinit-bots/keys"
@@ -15,10 +16,19 @@ import (
 type BuildTxWithMessagesFn func(context.Context, []sdk.Msg) ([]byte, string, error)
 type PendingTxToProcessedMsgsFn func([]byte) ([]sdk.Msg, error)
 
+// MsgResignerFn returns the copy of the msg whose signer is replaced with the given signer.
+type MsgResignerFn func(msg sdk.Msg, signer string) (sdk.Msg, error)
+
+// MsgRewriteFn returns the rewritten msg and whether the msg is changed.
+type MsgRewriteFn func(msg sdk.Msg) (sdk.Msg, bool)
+
 type BroadcasterConfig struct {
 	// ChainID is the chain ID.
 	ChainID string
 
+	// Bridge is the id of the bridge instance run by the multi executor, which labels the metrics of the broadcaster.
+	Bridge string
+
 	// GasPrice is the gas price.
 	GasPrice string
 
@@ -28,11 +38,140 @@ type BroadcasterConfig struct {
 	// TxTimeout is the transaction timeout.
 	TxTimeout time.Duration
 
+	// TxTimeoutHeight is the number of blocks after which a broadcasted tx expires.
+	// If it is zero, the txs have no timeout height and TxTimeout is used to detect stale txs.
+	TxTimeoutHeight int64
+
 	// Bech32Prefix is the Bech32 prefix.
 	Bech32Prefix string
 
 	// HomePath is the path to the keyring.
 	HomePath string
+
+	// BalanceCheckInterval is the interval to re-check the balance of the accounts.
+	// If it is zero, DefaultBalanceCheckInterval is used.
+	BalanceCheckInterval time.Duration
+
+	// LowBalanceGas is the estimated gas of a tx, which is used to compute
+	// the low balance threshold with the gas price and the number of queued txs.
+	LowBalanceGas uint64
+
+	// FeeGranter is the bech32 address of the fee granter for all accounts of the node.
+	// The fee granter of the keyring config takes precedence over it.
+	FeeGranter string
+
+	// Memo is the memo attached to all txs of the node.
+	Memo string
+
+	// GasPriceEscalation is the multiplier of the gas price applied for each resubmission of the msgs
+	// whose tx expired without being included. If it is zero or one, the gas price is not escalated.
+	GasPriceEscalation float64
+
+	// MaxGasPriceMultiplier caps the escalated gas price multiplier. If it is zero, there is no cap.
+	MaxGasPriceMultiplier float64
+
+	// GasPriceSource is the source of the gas price. If it is GasPriceSourceFeemarket, the gas price follows
+	// the base fee of the feemarket module, and GasPrice is used as the fallback until the base fee is queried.
+	// If it is empty, GasPriceSourceStatic is used.
+	GasPriceSource string
+
+	// FeemarketTipMultiplier is the multiplier of the base fee paid as the gas price.
+	// If it is zero, DefaultFeemarketTipMultiplier is used.
+	FeemarketTipMultiplier float64
+
+	// GasPriceRefreshInterval is the minimum interval between the queries of the base fee.
+	// If it is zero, DefaultGasPriceRefreshInterval is used.
+	GasPriceRefreshInterval time.Duration
+
+	// GasPriceHysteresis is the fraction by which the new gas price must deviate from the current gas price
+	// to replace it. If it is nil, DefaultGasPriceHysteresis is used, and zero disables the hysteresis.
+	GasPriceHysteresis *float64
+
+	// MaxQueuedMsgs is the maximum number of processed msgs kept in memory.
+	// The overflow is persisted to the db and refilled when the queue drains.
+	// If it is zero, DefaultMaxQueuedMsgs is used.
+	MaxQueuedMsgs int
+
+	// IsolateFailingMsgs enables the isolation of the msgs failing the simulation. When the simulation
+	// of the processed msgs fails, the msgs are bisected to find the failing msgs, which are parked
+	// to the dead letters, and the rest of the msgs are broadcasted.
+	IsolateFailingMsgs bool
+
+	// SequenceReconcileInterval is the number of the txs after which the cached account sequence is reconciled
+	// with the chain. The sequence is also reconciled after a failed tx. If it is zero, DefaultSequenceReconcileInterval is used.
+	SequenceReconcileInterval uint64
+
+	// DryRun replaces the broadcast with the recorder, which logs and records the msgs in the db
+	// without signing them. The msgs are not saved to be broadcasted, so they are not replayed after the dry-run.
+	DryRun bool
+}
+
+const DefaultBalanceCheckInterval = time.Minute
+const DefaultMaxQueuedMsgs = 100
+const DefaultSequenceReconcileInterval = 100
+
+func (bc BroadcasterConfig) GetSequenceReconcileInterval() uint64 {
+	if bc.SequenceReconcileInterval == 0 {
+		return DefaultSequenceReconcileInterval
+	}
+	return bc.SequenceReconcileInterval
+}
+
+const (
+	GasPriceSourceStatic    = "static"
+	GasPriceSourceFeemarket = "feemarket"
+)
+
+const DefaultFeemarketTipMultiplier = 1.1
+const DefaultGasPriceRefreshInterval = 5 * time.Second
+const DefaultGasPriceHysteresis = 0.1
+
+func (bc BroadcasterConfig) GetFeemarketTipMultiplier() float64 {
+	if bc.FeemarketTipMultiplier == 0 {
+		return DefaultFeemarketTipMultiplier
+	}
+	return bc.FeemarketTipMultiplier
+}
+
+func (bc BroadcasterConfig) GetGasPriceRefreshInterval() time.Duration {
+	if bc.GasPriceRefreshInterval == 0 {
+		return DefaultGasPriceRefreshInterval
+	}
+	return bc.GasPriceRefreshInterval
+}
+
+func (bc BroadcasterConfig) GetGasPriceHysteresis() float64 {
+	if bc.GasPriceHysteresis == nil {
+		return DefaultGasPriceHysteresis
+	}
+	return *bc.GasPriceHysteresis
+}
+
+func (bc BroadcasterConfig) GetMaxQueuedMsgs() int {
+	if bc.MaxQueuedMsgs == 0 {
+		return DefaultMaxQueuedMsgs
+	}
+	return bc.MaxQueuedMsgs
+}
+
+func (bc BroadcasterConfig) GetBalanceCheckInterval() time.Duration {
+	if bc.BalanceCheckInterval == 0 {
+		return DefaultBalanceCheckInterval
+	}
+	return bc.BalanceCheckInterval
+}
+
+// GasPriceMultiplier returns the multiplier of the gas price for the msgs resubmitted the given times.
+func (bc BroadcasterConfig) GasPriceMultiplier(resubmissions uint32) float64 {
+	if bc.GasPriceEscalation <= 1 {
+		return 1
+	}
+
+	multiplier := math.Pow(bc.GasPriceEscalation, float64(resubmissions))
+	if bc.MaxGasPriceMultiplier > 0 && multiplier > bc.MaxGasPriceMultiplier {
+		return bc.MaxGasPriceMultiplier
+	}
+	return multiplier
 }
 
 func (bc BroadcasterConfig) Validate() error {
@@ -57,6 +196,54 @@ func (bc BroadcasterConfig) Validate() error {
 		return fmt.Errorf("tx timeout is zero")
 	}
 
+	if bc.TxTimeoutHeight < 0 {
+		return fmt.Errorf("tx timeout height is negative")
+	}
+
+	if bc.BalanceCheckInterval < 0 {
+		return fmt.Errorf("balance check interval is negative")
+	}
+
+	if bc.FeeGranter != "" {
+		if _, err := sdk.GetFromBech32(bc.FeeGranter, bc.Bech32Prefix); err != nil {
+			return fmt.Errorf("failed to parse fee granter address: %s", bc.FeeGranter)
+		}
+	}
+
+	if bc.MaxQueuedMsgs < 0 {
+		return fmt.Errorf("max queued msgs is negative")
+	}
+
+	if bc.GasPriceEscalation < 0 {
+		return fmt.Errorf("gas price escalation is negative")
+	}
+
+	if bc.MaxGasPriceMultiplier < 0 {
+		return fmt.Errorf("max gas price multiplier is negative")
+	}
+
+	switch bc.GasPriceSource {
+	case "", GasPriceSourceStatic:
+	case GasPriceSourceFeemarket:
+		if bc.GasPrice == "" {
+			return fmt.Errorf("gas price is empty; it is required to decide the fee denom of the feemarket")
+		}
+	default:
+		return fmt.Errorf("unknown gas price source: %s", bc.GasPriceSource)
+	}
+
+	if bc.FeemarketTipMultiplier != 0 && bc.FeemarketTipMultiplier < 1 {
+		return fmt.Errorf("feemarket tip multiplier must be greater than or equal to 1")
+	}
+
+	if bc.GasPriceRefreshInterval < 0 {
+		return fmt.Errorf("gas price refresh interval is negative")
+	}
+
+	if hysteresis := bc.GetGasPriceHysteresis(); hysteresis < 0 || hysteresis >= 1 {
+		return fmt.Errorf("gas price hysteresis must be in [0, 1)")
+	}
+
 	return nil
 }
 
@@ -82,6 +269,9 @@ func (bc BroadcasterConfig) GetKeyringRecord(cdc codec.Codec, keyringConfig *Key
 	return keyBase, keyringRecord, nil
 }
 
+// OracleLane is the lane of the oracle msgs, which must not be blocked by the other msgs.
+const OracleLane = "oracle"
+
 type KeyringConfig struct {
 	// Name of key in keyring
 	Name string `json:"name"`
@@ -92,6 +282,13 @@ type KeyringConfig struct {
 	// FeeGranter is the fee granter.
 	FeeGranter *KeyringConfig
 
+	// MsgTypes is the list of msg type urls routed to this account.
+	MsgTypes []string `json:"msg_types"`
+
+	// Lanes is the list of lane names served by this account.
+	// The msgs of a lane are broadcasted in order, independently of the other lanes.
+	Lanes []string `json:"lanes"`
+
 	// BuildTxWithMessages is the function to build a transaction with messages.
 	BuildTxWithMessages BuildTxWithMessagesFn
 
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -8,8 +11,19 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
 )
 
+// traceID returns the correlation id computed from the given parts and the timestamp.
+func traceID(timestamp int64, parts ...[]byte) string {
+	hasher := sha256.New()
+	for _, part := range parts {
+		hasher.Write(part)
+	}
+	hasher.Write(binary.BigEndian.AppendUint64(nil, uint64(timestamp)))
+	return hex.EncodeToString(hasher.Sum(nil)[:8])
+}
+
 type PendingTxInfo struct {
 	Sender          string   `json:"sender"`
 	ProcessedHeight int64    `json:"height"`
@@ -18,6 +32,20 @@ type PendingTxInfo struct {
 	TxHash          string   `json:"tx_hash"`
 	Timestamp       int64    `json:"timestamp"`
 	MsgTypes        []string `json:"msg_types"`
+	Memo            string   `json:"memo,omitempty"`
+
+	// TraceID is the correlation id of the processed msgs included in the tx.
+	TraceID string `json:"trace_id,omitempty"`
+
+	// Lane is the lane of the processed msgs included in the tx.
+	Lane string `json:"lane,omitempty"`
+
+	// TimeoutHeight is the height after which the tx can't be included in a block.
+	// It is zero if the tx has no timeout height.
+	TimeoutHeight uint64 `json:"timeout_height,omitempty"`
+
+	// Resubmissions is the number of times the msgs of the tx are resubmitted after the tx expired.
+	Resubmissions uint32 `json:"resubmissions,omitempty"`
 
 	// Save is true if the pending tx should be saved until processed.
 	// Save is false if the pending tx can be discarded even if it is not processed
@@ -30,12 +58,25 @@ func (p PendingTxInfo) Marshal() ([]byte, error) {
 }
 
 func (p *PendingTxInfo) Unmarshal(data []byte) error {
-	return json.Unmarshal(data, p)
+	if err := json.Unmarshal(data, p); err != nil {
+		return err
+	}
+
+	// the pending txs saved before the trace id was introduced
+	if p.TraceID == "" {
+		p.TraceID = traceID(p.Timestamp, p.Tx)
+	}
+	return nil
+}
+
+// Expired returns true if the tx can't be included in a block after the given height.
+func (p PendingTxInfo) Expired(height int64) bool {
+	return p.TimeoutHeight > 0 && height > 0 && uint64(height) > p.TimeoutHeight
 }
 
 func (p PendingTxInfo) String() string {
 	tsStr := time.Unix(0, p.Timestamp).UTC().String()
-	return fmt.Sprintf("Pending tx: %s, sender: %s, msgs: %s, sequence: %d at height: %d, %s", p.TxHash, p.Sender, strings.Join(p.MsgTypes, ","), p.Sequence, p.ProcessedHeight, tsStr)
+	return fmt.Sprintf("Pending tx: %s, trace id: %s, sender: %s, msgs: %s, sequence: %d at height: %d, %s", p.TxHash, p.TraceID, p.Sender, strings.Join(p.MsgTypes, ","), p.Sequence, p.ProcessedHeight, tsStr)
 }
 
 type ProcessedMsgs struct {
@@ -43,6 +84,25 @@ type ProcessedMsgs struct {
 	Msgs      []sdk.Msg `json:"msgs"`
 	Timestamp int64     `json:"timestamp"`
 
+	// Counter is the monotonic counter assigned when the msgs are saved, which makes the key unique
+	// among the msgs with the same timestamp. It is zero for the msgs saved before the counter was introduced.
+	Counter uint64 `json:"counter,omitempty"`
+
+	// EffectKeys are the keys identifying the effects of the msgs on the chain.
+	// The key is empty if the msg type has no effect checker.
+	EffectKeys []string `json:"effect_keys,omitempty"`
+
+	// TraceID is the correlation id of the msgs, which follows them through the broadcast lifecycle.
+	TraceID string `json:"trace_id,omitempty"`
+
+	// Lane is the name of the broadcast lane. The msgs are only ordered within the same lane.
+	// If it is empty or unknown, the default lane of the sender is used.
+	Lane string `json:"lane,omitempty"`
+
+	// Resubmissions is the number of times the msgs are resubmitted after their tx expired,
+	// which escalates the gas price of the tx.
+	Resubmissions uint32 `json:"resubmissions,omitempty"`
+
 	// Save is true if the processed msgs should be saved until processed.
 	// Save is false if the processed msgs can be discarded even if they are not processed
 	// like oracle msgs.
@@ -51,18 +111,56 @@ type ProcessedMsgs struct {
 
 // processedMsgsJSON is a helper struct to JSON encode ProcessedMsgs
 type processedMsgsJSON struct {
-	Sender    string   `json:"sender"`
-	Msgs      []string `json:"msgs"`
-	Timestamp int64    `json:"timestamp"`
-	Save      bool     `json:"save"`
+	Sender        string   `json:"sender"`
+	Msgs          []string `json:"msgs"`
+	Timestamp     int64    `json:"timestamp"`
+	Counter       uint64   `json:"counter,omitempty"`
+	EffectKeys    []string `json:"effect_keys,omitempty"`
+	TraceID       string   `json:"trace_id,omitempty"`
+	Lane          string   `json:"lane,omitempty"`
+	Resubmissions uint32   `json:"resubmissions,omitempty"`
+	Save          bool     `json:"save"`
+}
+
+// WithTraceID returns the processed msgs with the trace id computed from the msgs and the timestamp.
+// The trace id is kept if it is already assigned.
+func (p ProcessedMsgs) WithTraceID() ProcessedMsgs {
+	if p.TraceID != "" {
+		return p
+	}
+
+	parts := make([][]byte, 0, len(p.Msgs))
+	for _, msg := range p.Msgs {
+		bz, err := proto.Marshal(msg)
+		if err != nil {
+			// fallback to the text representation
+			bz = []byte(msg.String())
+		}
+		parts = append(parts, bz)
+	}
+	p.TraceID = traceID(p.Timestamp, parts...)
+	return p
+}
+
+// Key returns the db key of the processed msgs.
+func (p ProcessedMsgs) Key() []byte {
+	if p.Counter == 0 {
+		return PrefixedProcessedMsgs(uint64(p.Timestamp))
+	}
+	return PrefixedProcessedMsgsWithCounter(uint64(p.Timestamp), p.Counter)
 }
 
 func (p ProcessedMsgs) MarshalInterfaceJSON(cdc codec.Codec) ([]byte, error) {
 	pms := processedMsgsJSON{
-		Sender:    p.Sender,
-		Msgs:      make([]string, len(p.Msgs)),
-		Timestamp: p.Timestamp,
-		Save:      p.Save,
+		Sender:        p.Sender,
+		Msgs:          make([]string, len(p.Msgs)),
+		Timestamp:     p.Timestamp,
+		Counter:       p.Counter,
+		EffectKeys:    p.EffectKeys,
+		TraceID:       p.TraceID,
+		Lane:          p.Lane,
+		Resubmissions: p.Resubmissions,
+		Save:          p.Save,
 	}
 
 	for i, msg := range p.Msgs {
@@ -85,6 +183,11 @@ func (p *ProcessedMsgs) UnmarshalInterfaceJSON(cdc codec.Codec, data []byte) err
 
 	p.Sender = pms.Sender
 	p.Timestamp = pms.Timestamp
+	p.Counter = pms.Counter
+	p.EffectKeys = pms.EffectKeys
+	p.TraceID = pms.TraceID
+	p.Lane = pms.Lane
+	p.Resubmissions = pms.Resubmissions
 	p.Save = pms.Save
 
 	p.Msgs = make([]sdk.Msg, len(pms.Msgs))
@@ -94,12 +197,14 @@ func (p *ProcessedMsgs) UnmarshalInterfaceJSON(cdc codec.Codec, data []byte) err
 		}
 	}
 
+	// the processed msgs saved before the trace id was introduced
+	*p = p.WithTraceID()
 	return nil
 }
 
 func (p ProcessedMsgs) String() string {
 	tsStr := time.Unix(0, p.Timestamp).UTC().String()
-	return fmt.Sprintf("Pending msgs: sender: %s, %s at %s", p.Sender, strings.Join(p.GetMsgTypes(), ","), tsStr)
+	return fmt.Sprintf("Pending msgs: trace id: %s, sender: %s, %s at %s", p.TraceID, p.Sender, strings.Join(p.GetMsgTypes(), ","), tsStr)
 }
 
 func (p ProcessedMsgs) GetMsgStrings() []string {
@@ -117,3 +222,51 @@ func (p ProcessedMsgs) GetMsgTypes() []string {
 	}
 	return msgTypes
 }
+
+// TxTrace is the stored records of the msgs with the same trace id.
+type TxTrace struct {
+	TraceID       string          `json:"trace_id"`
+	ProcessedMsgs []ProcessedMsgs `json:"processed_msgs"`
+	PendingTxs    []PendingTxInfo `json:"pending_txs"`
+}
+
+// DeadLetter is a msg parked out of its processed msgs because it failed the simulation on its own,
+// so it doesn't block the other msgs. It is broadcasted again only when it is retried.
+type DeadLetter struct {
+	ID      uint64 `json:"id"`
+	Sender  string `json:"sender"`
+	TraceID string `json:"trace_id"`
+	Lane    string `json:"lane,omitempty"`
+	MsgType string `json:"msg_type"`
+	// Msg is the interface json of the msg.
+	Msg      json.RawMessage `json:"msg"`
+	Error    string          `json:"error"`
+	ParkedAt time.Time       `json:"parked_at"`
+}
+
+func (d DeadLetter) Marshal() ([]byte, error) {
+	return json.Marshal(&d)
+}
+
+func (d *DeadLetter) Unmarshal(data []byte) error {
+	return json.Unmarshal(data, d)
+}
+
+// DryRunRecord is the processed msgs recorded instead of being broadcasted in the dry-run mode.
+type DryRunRecord struct {
+	Sender   string   `json:"sender"`
+	TraceID  string   `json:"trace_id"`
+	Lane     string   `json:"lane,omitempty"`
+	MsgTypes []string `json:"msg_types"`
+	// Msgs are the interface json of the msgs.
+	Msgs       []json.RawMessage `json:"msgs"`
+	RecordedAt time.Time         `json:"recorded_at"`
+}
+
+func (r DryRunRecord) Marshal() ([]byte, error) {
+	return json.Marshal(&r)
+}
+
+func (r *DryRunRecord) Unmarshal(data []byte) error {
+	return json.Unmarshal(data, r)
+}
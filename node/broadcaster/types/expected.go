@@ -4,8 +4,19 @@ import (
 	"context"
 
 	abci "github.com/cometbft/cometbft/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 type Querier interface {
 	QueryABCI(ctx context.Context, req abci.RequestQuery) (abci.ResponseQuery, error)
 }
+
+// EffectChecker checks whether the effect of a msg has already landed on the chain,
+// so the msgs executed before the restart are not broadcasted again.
+type EffectChecker interface {
+	// EffectKey returns the key identifying the effect of the msg. e.g. output index
+	EffectKey(msg sdk.Msg) (string, error)
+	// EffectExists returns true if the effect of the msg already exists on the chain.
+	EffectExists(ctx context.Context, msg sdk.Msg) (bool, error)
+}
@@ -8,12 +8,33 @@ var (
 	// Keys
 	PendingTxsKey    = []byte("pending_txs")
 	ProcessedMsgsKey = []byte("processed_msgs")
+	DeadLettersKey   = []byte("dead_letters")
+
+	DryRunRecordsKey = []byte("dry_run_records")
+
+	LastProcessedMsgsCounterKey = []byte("last_processed_msgs_counter")
 )
 
 func PrefixedPendingTx(timestamp uint64) []byte {
 	return append(append(PendingTxsKey, dbtypes.Splitter), dbtypes.FromUint64Key(timestamp)...)
 }
 
+// PrefixedProcessedMsgs returns the key of the processed msgs saved before the counter was introduced.
+// It precedes the keys of the processed msgs with the same timestamp, so it also bounds the key ranges.
 func PrefixedProcessedMsgs(timestamp uint64) []byte {
 	return append(append(ProcessedMsgsKey, dbtypes.Splitter), dbtypes.FromUint64Key(timestamp)...)
 }
+
+// PrefixedProcessedMsgsWithCounter returns the key of the processed msgs with the timestamp and the counter,
+// so the processed msgs created at the same nanosecond don't overwrite each other.
+func PrefixedProcessedMsgsWithCounter(timestamp uint64, counter uint64) []byte {
+	return append(append(PrefixedProcessedMsgs(timestamp), dbtypes.Splitter), dbtypes.FromUint64Key(counter)...)
+}
+
+func PrefixedDeadLetter(id uint64) []byte {
+	return append(append(DeadLettersKey, dbtypes.Splitter), dbtypes.FromUint64Key(id)...)
+}
+
+func PrefixedDryRunRecord(timestamp uint64, counter uint64) []byte {
+	return append(append(append(append(DryRunRecordsKey, dbtypes.Splitter), dbtypes.FromUint64Key(timestamp)...), dbtypes.Splitter), dbtypes.FromUint64Key(counter)...)
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// DefaultMaxMsgsPerTx is the default maximum number of msgs in a tx.
+	DefaultMaxMsgsPerTx = 5
+
+	// TxOverheadBytes is the estimated bytes of the signature, auth info and fee of a tx.
+	TxOverheadBytes = 256
+
+	// TxSizeCostPerByte is the default gas cost per tx byte of the auth module.
+	TxSizeCostPerByte = 10
+
+	// EstimatedMsgGas is the estimated gas to execute a msg, excluding the tx size cost.
+	EstimatedMsgGas = 100_000
+)
+
+// MsgQueueLimits are the limits of a tx when the msg queue is flushed into multiple txs.
+type MsgQueueLimits struct {
+	// MaxMsgs is the maximum number of msgs in a tx. If it is zero, DefaultMaxMsgsPerTx is used.
+	MaxMsgs int
+
+	// MaxTxBytes is the maximum estimated bytes of a tx. If it is zero, the bytes are not limited.
+	MaxTxBytes int64
+
+	// MaxTxGas is the maximum estimated gas of a tx. If it is zero, the gas is not limited.
+	MaxTxGas uint64
+}
+
+func (l MsgQueueLimits) GetMaxMsgs() int {
+	if l.MaxMsgs == 0 {
+		return DefaultMaxMsgsPerTx
+	}
+	return l.MaxMsgs
+}
+
+// EstimateMsgSize returns the size of the msg encoded as an Any in the tx body.
+func EstimateMsgSize(msg sdk.Msg) (int64, error) {
+	anyMsg, err := codectypes.NewAnyWithValue(msg)
+	if err != nil {
+		return 0, err
+	}
+	return int64(anyMsg.Size()), nil
+}
+
+// EstimateTxGas returns the estimated gas of a tx with the given bytes and number of msgs.
+func EstimateTxGas(txBytes int64, numMsgs int) uint64 {
+	return uint64(txBytes)*TxSizeCostPerByte + uint64(numMsgs)*EstimatedMsgGas
+}
+
+// SplitMsgs splits the msgs into chunks which fit the limits, preserving the order of the msgs.
+// A msg exceeding the limits by itself is put into its own chunk.
+func (l MsgQueueLimits) SplitMsgs(msgs []sdk.Msg) ([][]sdk.Msg, error) {
+	maxMsgs := l.GetMaxMsgs()
+
+	chunks := make([][]sdk.Msg, 0)
+	var chunk []sdk.Msg
+	chunkBytes := int64(TxOverheadBytes)
+	for _, msg := range msgs {
+		msgBytes, err := EstimateMsgSize(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(chunk) > 0 && !l.fits(chunkBytes+msgBytes, len(chunk)+1, maxMsgs) {
+			chunks = append(chunks, chunk)
+			chunk = nil
+			chunkBytes = TxOverheadBytes
+		}
+		chunk = append(chunk, msg)
+		chunkBytes += msgBytes
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func (l MsgQueueLimits) fits(txBytes int64, numMsgs int, maxMsgs int) bool {
+	if numMsgs > maxMsgs {
+		return false
+	} else if l.MaxTxBytes != 0 && txBytes > l.MaxTxBytes {
+		return false
+	} else if l.MaxTxGas != 0 && EstimateTxGas(txBytes, numMsgs) > l.MaxTxGas {
+		return false
+	}
+	return true
+}
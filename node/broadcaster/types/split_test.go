@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+func newTestMsgs(n int) []sdk.Msg {
+	msgs := make([]sdk.Msg, n)
+	for i := range msgs {
+		msgs[i] = &banktypes.MsgSend{
+			FromAddress: "init1from",
+			ToAddress:   fmt.Sprintf("init1to%04d", i),
+			Amount:      sdk.NewCoins(sdk.NewInt64Coin("uinit", 1)),
+		}
+	}
+	return msgs
+}
+
+func Test_SplitMsgs(t *testing.T) {
+	msgs := newTestMsgs(3000)
+	msgSize, err := EstimateMsgSize(msgs[0])
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		limits    MsgQueueLimits
+		chunkSize int
+	}{
+		{"default", MsgQueueLimits{}, DefaultMaxMsgsPerTx},
+		{"max msgs", MsgQueueLimits{MaxMsgs: 100}, 100},
+		{"max tx bytes", MsgQueueLimits{MaxMsgs: 3000, MaxTxBytes: TxOverheadBytes + 40*msgSize}, 40},
+		{"max tx gas", MsgQueueLimits{MaxMsgs: 3000, MaxTxGas: 10 * EstimatedMsgGas}, 9},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chunks, err := tc.limits.SplitMsgs(msgs)
+			require.NoError(t, err)
+
+			// split boundaries
+			require.Len(t, chunks, (len(msgs)+tc.chunkSize-1)/tc.chunkSize)
+			for i, chunk := range chunks[:len(chunks)-1] {
+				require.Len(t, chunk, tc.chunkSize, "chunk %d", i)
+			}
+
+			// ordering
+			flattened := make([]sdk.Msg, 0, len(msgs))
+			for _, chunk := range chunks {
+				flattened = append(flattened, chunk...)
+			}
+			require.Equal(t, msgs, flattened)
+		})
+	}
+}
+
+func Test_SplitMsgs_OversizedMsg(t *testing.T) {
+	msgs := newTestMsgs(3)
+	chunks, err := MsgQueueLimits{MaxTxBytes: 1}.SplitMsgs(msgs)
+	require.NoError(t, err)
+	require.Equal(t, [][]sdk.Msg{msgs[:1], msgs[1:2], msgs[2:]}, chunks)
+
+	chunks, err = MsgQueueLimits{}.SplitMsgs(nil)
+	require.NoError(t, err)
+	require.Empty(t, chunks)
+}
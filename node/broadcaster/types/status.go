@@ -2,10 +2,33 @@ package types
 
 type BroadcasterStatus struct {
 	PendingTxs     int                        `json:"pending_txs"`
+	QueuedMsgs     int                        `json:"queued_msgs"`
+	OverflowedMsgs int                        `json:"overflowed_msgs"`
+	LowBalance     bool                       `json:"low_balance"`
 	AccountsStatus []BroadcasterAccountStatus `json:"accounts_status"`
 }
 
 type BroadcasterAccountStatus struct {
-	Address  string `json:"address"`
-	Sequence uint64 `json:"sequence"`
+	Address    string `json:"address"`
+	Sequence   uint64 `json:"sequence"`
+	PendingTxs int    `json:"pending_txs"`
+	QueuedMsgs int    `json:"queued_msgs"`
+	LowBalance bool   `json:"low_balance"`
+}
+
+// PendingTxSummary is the decoded summary of the pending tx to debug the stuck pending txs.
+type PendingTxSummary struct {
+	Sender        string   `json:"sender"`
+	Sequence      uint64   `json:"sequence"`
+	TxHash        string   `json:"tx_hash"`
+	TraceID       string   `json:"trace_id"`
+	MsgTypes      []string `json:"msg_types"`
+	Timestamp     int64    `json:"timestamp"`
+	AgeSeconds    int64    `json:"age_seconds"`
+	TimeoutHeight uint64   `json:"timeout_height,omitempty"`
+	Resubmissions uint32   `json:"resubmissions,omitempty"`
+	Save          bool     `json:"save"`
+
+	// DecodeError is set if the tx bytes can't be decoded, and the msg types are the ones recorded at the broadcast.
+	DecodeError string `json:"decode_error,omitempty"`
 }
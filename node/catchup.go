@@ -0,0 +1,71 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// checkCaughtUp returns whether the node has caught up the chain.
+func (n *Node) checkCaughtUp(ctx context.Context) (bool, error) {
+	rpcStart := time.Now()
+	status, err := n.rpcClient.Status(ctx)
+	n.observeRPC("status", rpcStart)
+	if err != nil {
+		return false, err
+	}
+	n.status.setLatestChainHeight(status.SyncInfo.LatestBlockHeight)
+	return !status.SyncInfo.CatchingUp, nil
+}
+
+// waitCatchUp polls the node until it catches up the chain, and then prepares the broadcaster.
+// It returns an error if the node doesn't catch up in the max catch up wait.
+func (n *Node) waitCatchUp(ctx context.Context) error {
+	interval := n.cfg.CatchUpPollingInterval
+	if interval == 0 {
+		interval = nodetypes.DefaultCatchUpPollingInterval
+	}
+
+	start := time.Now()
+	for {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-types.Shutdown(ctx):
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		caughtUp, err := n.checkCaughtUp(ctx)
+		if err != nil {
+			n.logger.Warn("failed to check the node is caught up", zap.String("error", err.Error()))
+			n.status.setError(err)
+		} else if caughtUp {
+			break
+		}
+
+		if n.cfg.MaxCatchUpWait > 0 && time.Since(start) >= n.cfg.MaxCatchUpWait {
+			return fmt.Errorf("node %s has not caught up the chain in %s; latest height of the node: %d, check the sync of the node or increase the max catch up wait",
+				n.cfg.RPC, n.cfg.MaxCatchUpWait, n.status.load().LatestChainHeight)
+		}
+		n.logger.Info("waiting for the node to catch up", zap.Int64("latest_height", n.status.load().LatestChainHeight))
+	}
+
+	n.logger.Info("node caught up", zap.Duration("waited", time.Since(start)))
+	if n.broadcaster != nil {
+		err := n.broadcaster.Prepare(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	n.status.setCatchingUp(false)
+	return nil
+}
@@ -0,0 +1,98 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+// newCatchingUpChain returns the fake chain of the node which is catching up until the given number of
+// status calls. The chain produces a new block on every status call.
+func newCatchingUpChain(catchUpAfter int) *nodetest.FakeClient {
+	chain := nodetest.NewFakeClient("test-1")
+	chain.SetLatestHeight(100)
+	chain.SetCallHook(func(_ context.Context, method string) error {
+		if method == nodetest.MethodStatus {
+			calls := chain.Calls(nodetest.MethodStatus)
+			chain.SetLatestHeight(100 + int64(calls))
+			chain.SetCatchingUp(calls <= catchUpAfter)
+		}
+		return nil
+	})
+	return chain
+}
+
+func Test_WaitCatchUp(t *testing.T) {
+	chain := newCatchingUpChain(3)
+	n := newTestNode(t, chain)
+	n.cfg.CatchUpPollingInterval = 10 * time.Millisecond
+
+	// the catching up node doesn't fail the initialization
+	require.NoError(t, n.Initialize(context.Background(), 0, nil))
+	status, err := n.Status()
+	require.NoError(t, err)
+	require.True(t, status.CatchingUp)
+	require.NotNil(t, status.CatchingUpSince)
+	require.Equal(t, int64(101), status.LatestChainHeight)
+
+	require.NoError(t, n.waitCatchUp(context.Background()))
+	require.Equal(t, 4, chain.Calls(nodetest.MethodStatus))
+	status, err = n.Status()
+	require.NoError(t, err)
+	require.False(t, status.CatchingUp)
+	require.Nil(t, status.CatchingUpSince)
+	require.Equal(t, int64(104), status.LatestChainHeight)
+}
+
+func Test_WaitCatchUpTimeout(t *testing.T) {
+	n := newTestNode(t, newCatchingUpChain(1000))
+	n.cfg.CatchUpPollingInterval = 10 * time.Millisecond
+	n.cfg.MaxCatchUpWait = 50 * time.Millisecond
+
+	require.NoError(t, n.Initialize(context.Background(), 0, nil))
+	err := n.waitCatchUp(context.Background())
+	require.ErrorContains(t, err, "has not caught up the chain in 50ms")
+
+	status, err := n.Status()
+	require.NoError(t, err)
+	require.True(t, status.CatchingUp)
+}
+
+func Test_WaitCatchUpCanceled(t *testing.T) {
+	n := newTestNode(t, newCatchingUpChain(1000))
+	n.cfg.CatchUpPollingInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.NoError(t, n.Initialize(ctx, 0, nil))
+	require.NoError(t, n.waitCatchUp(ctx))
+}
+
+func Test_InitializeCaughtUp(t *testing.T) {
+	chain := newCatchingUpChain(0)
+	n := newTestNode(t, chain)
+
+	require.NoError(t, n.Initialize(context.Background(), 0, nil))
+	require.Equal(t, 1, chain.Calls(nodetest.MethodStatus))
+	status, err := n.Status()
+	require.NoError(t, err)
+	require.False(t, status.CatchingUp)
+}
+
+func Test_InitializeOnlyBroadcast(t *testing.T) {
+	chain := newCatchingUpChain(1000)
+	n := newTestNode(t, chain)
+	n.cfg.ProcessType = nodetypes.PROCESS_TYPE_ONLY_BROADCAST
+
+	// the node only broadcasting the txs doesn't check the sync of the node
+	require.NoError(t, n.Initialize(context.Background(), 0, nil))
+	require.Zero(t, chain.Calls(nodetest.MethodStatus))
+	status, err := n.Status()
+	require.NoError(t, err)
+	require.False(t, status.CatchingUp)
+}
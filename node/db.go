@@ -1,6 +1,8 @@
 package node
 
 import (
+	"errors"
+
 	dbtypes "github.com/initia-labs/opinit-bots/db/types"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
@@ -9,12 +11,18 @@ import (
 )
 
 func (n *Node) SetSyncInfo(height int64) {
-	n.lastProcessedBlockHeight = height
+	n.setLastProcessedBlockHeight(height)
 	if n.broadcaster != nil {
 		n.broadcaster.SetSyncInfo(n.lastProcessedBlockHeight)
 	}
 }
 
+// setLastProcessedBlockHeight marks the height as processed and updates the status snapshot.
+func (n *Node) setLastProcessedBlockHeight(height int64) {
+	n.lastProcessedBlockHeight = height
+	n.status.setLastProcessedHeight(height)
+}
+
 func (n *Node) loadSyncInfo(processedHeight int64) error {
 	data, err := n.db.Get(nodetypes.LastProcessedBlockHeightKey)
 	if err == dbtypes.ErrNotFound {
@@ -37,11 +45,30 @@ func (n *Node) loadSyncInfo(processedHeight int64) error {
 	return nil
 }
 
+// HasSyncInfo returns true if the last processed block height is stored, so the given start height is ignored.
+func (n Node) HasSyncInfo() (bool, error) {
+	_, err := n.db.Get(nodetypes.LastProcessedBlockHeightKey)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (n Node) SaveSyncInfo(height int64) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
 	return n.db.Set(nodetypes.LastProcessedBlockHeightKey, dbtypes.FromUint64(types.MustInt64ToUint64(height)))
 }
 
+// SyncInfoToRawKV returns the raw kv of the last processed block height.
+// While replaying, it keeps the current last processed block height.
 func (n Node) SyncInfoToRawKV(height int64) types.RawKV {
+	if n.replaying.Load() {
+		height = n.lastProcessedBlockHeight
+	}
 	return types.RawKV{
 		Key:   n.db.PrefixedKey(nodetypes.LastProcessedBlockHeightKey),
 		Value: dbtypes.FromUint64(types.MustInt64ToUint64(height)),
@@ -57,21 +84,11 @@ func DeleteSyncInfo(db types.DB) error {
 }
 
 func DeleteProcessedMsgs(db types.DB) error {
-	return db.PrefixedIterate(btypes.ProcessedMsgsKey, nil, func(key, _ []byte) (stop bool, err error) {
-		err = db.Delete(key)
-		if err != nil {
-			return stop, err
-		}
-		return false, nil
-	})
+	_, err := db.PrefixedDeleteRange(btypes.ProcessedMsgsKey, nil, nil)
+	return err
 }
 
 func DeletePendingTxs(db types.DB) error {
-	return db.PrefixedIterate(btypes.PendingTxsKey, nil, func(key, _ []byte) (stop bool, err error) {
-		err = db.Delete(key)
-		if err != nil {
-			return stop, err
-		}
-		return false, nil
-	})
+	_, err := db.PrefixedDeleteRange(btypes.PendingTxsKey, nil, nil)
+	return err
 }
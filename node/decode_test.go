@@ -0,0 +1,119 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/initia-labs/opinit-bots/keys"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	movetypes "github.com/initia-labs/opinit-bots/types/move"
+	wasmtypes "github.com/initia-labs/opinit-bots/types/wasm"
+)
+
+func Test_DecodeTxMsgs(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+
+	// the tx of the bank module, which is not registered to the codec of the node
+	bankCdc, bankTxConfig, err := keys.GetCachedCodec("test", "init", []keys.RegisterInterfaces{authtypes.RegisterInterfaces}, []string{keys.CodecModuleBank})
+	require.NoError(t, err)
+	msgSend := &banktypes.MsgSend{
+		FromAddress: "init1from",
+		ToAddress:   "init1to",
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uinit", 100)),
+	}
+	txBuilder := bankTxConfig.NewTxBuilder()
+	require.NoError(t, txBuilder.SetMsgs(msgSend))
+	txBytes, err := bankTxConfig.TxEncoder()(txBuilder.GetTx())
+	require.NoError(t, err)
+
+	// the unknown type url fails the decoding by default
+	_, err = n.DecodeTxMsgs(txBytes)
+	require.ErrorContains(t, err, "unable to resolve type URL")
+
+	// the lenient decoding keeps the msg of the unknown type url undecoded
+	n.cfg.LenientTxDecoding = true
+	msgs, err := n.DecodeTxMsgs(txBytes)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	anyMsg, ok := msgs[0].(*codectypes.Any)
+	require.True(t, ok)
+	require.Equal(t, sdk.MsgTypeURL(msgSend), anyMsg.TypeUrl)
+
+	// the codec with the bank module decodes the msg
+	n.cdc, n.txConfig = bankCdc, bankTxConfig
+	msgs, err = n.DecodeTxMsgs(txBytes)
+	require.NoError(t, err)
+	require.Equal(t, []sdk.Msg{msgSend}, msgs)
+
+	// the malformed tx is not decoded even with the lenient decoding
+	_, err = n.DecodeTxMsgs([]byte("malformed"))
+	require.Error(t, err)
+}
+
+func Test_GetCachedCodec(t *testing.T) {
+	registerFns := []keys.RegisterInterfaces{authtypes.RegisterInterfaces}
+
+	// the codec is shared regardless of the order and the duplicates of the modules
+	cdc, _, err := keys.GetCachedCodec("test", "init", registerFns, []string{keys.CodecModuleIBC, keys.CodecModuleBank})
+	require.NoError(t, err)
+	cached, _, err := keys.GetCachedCodec("test", "init", registerFns, []string{keys.CodecModuleBank, keys.CodecModuleIBC, keys.CodecModuleBank})
+	require.NoError(t, err)
+	require.Same(t, cdc, cached)
+
+	// the different module sets have their own codecs
+	other, _, err := keys.GetCachedCodec("test", "init", registerFns, nil)
+	require.NoError(t, err)
+	require.NotSame(t, cdc, other)
+
+	_, _, err = keys.GetCachedCodec("test", "init", registerFns, []string{"evm"})
+	require.ErrorContains(t, err, "unsupported codec module: evm")
+}
+
+func Test_DecodeTxMsgsCodecModules(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+
+	for _, tc := range []struct {
+		module string
+		msg    sdk.Msg
+	}{
+		{
+			module: keys.CodecModuleWasm,
+			msg: &wasmtypes.MsgExecuteContract{
+				Sender:   "init1sender",
+				Contract: "init1contract",
+				Msg:      wasmtypes.RawContractMessage(`{"transfer":{}}`),
+				Funds:    sdk.NewCoins(sdk.NewInt64Coin("uinit", 100)),
+			},
+		},
+		{
+			module: keys.CodecModuleMove,
+			msg: &movetypes.MsgExecute{
+				Sender:        "init1sender",
+				ModuleAddress: "0x1",
+				ModuleName:    "coin",
+				FunctionName:  "transfer",
+				TypeArgs:      []string{},
+				Args:          [][]byte{{1}},
+			},
+		},
+	} {
+		cdc, txConfig, err := keys.GetCachedCodec("test", "init", []keys.RegisterInterfaces{authtypes.RegisterInterfaces}, []string{tc.module})
+		require.NoError(t, err)
+		txBuilder := txConfig.NewTxBuilder()
+		require.NoError(t, txBuilder.SetMsgs(tc.msg))
+		txBytes, err := txConfig.TxEncoder()(txBuilder.GetTx())
+		require.NoError(t, err)
+
+		n.cdc, n.txConfig = cdc, txConfig
+		msgs, err := n.DecodeTxMsgs(txBytes)
+		require.NoError(t, err, tc.module)
+		require.Len(t, msgs, 1)
+		require.Equal(t, sdk.MsgTypeURL(tc.msg), sdk.MsgTypeURL(msgs[0]))
+	}
+}
@@ -0,0 +1,136 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/keys"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_DryRunBlockSequence(t *testing.T) {
+	blocks := int64(5)
+	chain := nodetest.NewFakeClient("test-1")
+	chain.AppendBlocks(int(blocks))
+
+	database, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces, banktypes.RegisterInterfaces})
+	require.NoError(t, err)
+
+	homePath := t.TempDir()
+	n, err := NewNodeWithRPCClient(nodetypes.NodeConfig{
+		RPC:          "tcp://localhost:26657",
+		ChainID:      "test-1",
+		ProcessType:  nodetypes.PROCESS_TYPE_DEFAULT,
+		Bech32Prefix: "init",
+		BroadcasterConfig: &btypes.BroadcasterConfig{
+			ChainID:       "test-1",
+			GasPrice:      "0.15uinit",
+			GasAdjustment: 1.5,
+			TxTimeout:     time.Minute,
+			Bech32Prefix:  "init",
+			HomePath:      homePath,
+			DryRun:        true,
+		},
+	}, database, zap.NewNop(), cdc, txConfig, chain)
+	require.NoError(t, err)
+
+	keyBase, err := keys.GetKeyBase("test-1", homePath, cdc, nil)
+	require.NoError(t, err)
+	mnemonic, err := keys.CreateMnemonic()
+	require.NoError(t, err)
+	record, err := keyBase.NewAccount("sender", mnemonic, "", sdk.FullFundraiserPath, hd.Secp256k1)
+	require.NoError(t, err)
+	address, err := record.GetAddress()
+	require.NoError(t, err)
+	sender, err := keys.EncodeBech32AccAddr(address, "init")
+	require.NoError(t, err)
+
+	// the dry-run doesn't load the accounts from the chain
+	require.NoError(t, n.Initialize(context.Background(), 0, []btypes.KeyringConfig{{Name: "sender"}}))
+	b, err := n.GetBroadcaster()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(types.WithPollingInterval(context.Background(), time.Millisecond))
+	defer cancel()
+	errGrp, ctx := errgroup.WithContext(ctx)
+	ctx = types.WithErrGrp(ctx, errGrp)
+
+	// every block saves and broadcasts its msgs with the sync info, as the handlers of the bridge do
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, args nodetypes.EndBlockArgs) error {
+		height := args.Block.Header.Height
+		processedMsgs := btypes.ProcessedMsgs{
+			Sender:    sender,
+			Msgs:      []sdk.Msg{banktypes.NewMsgSend(address, address, sdk.NewCoins(sdk.NewInt64Coin("uinit", height)))},
+			Timestamp: height,
+			Save:      true,
+		}
+		kvs, err := b.ProcessedMsgsToRawKV([]btypes.ProcessedMsgs{processedMsgs}, false)
+		if err != nil {
+			return err
+		}
+		if err := n.db.RawBatchSet(append(kvs, n.SyncInfoToRawKV(height))...); err != nil {
+			return err
+		}
+		if err := b.BroadcastMsgs(processedMsgs); err != nil {
+			return err
+		}
+		if height == blocks {
+			cancel()
+		}
+		return nil
+	}))
+
+	n.Start(ctx)
+	select {
+	case <-n.BlockProcessStopped():
+	case <-time.After(5 * time.Second):
+		t.Fatal("block process looper is not stopped")
+	}
+	require.NoError(t, errGrp.Wait())
+
+	// the blocks are processed and synced as usual
+	data, err := n.db.Get(nodetypes.LastProcessedBlockHeightKey)
+	require.NoError(t, err)
+	syncedHeight, err := dbtypes.ToInt64(data)
+	require.NoError(t, err)
+	require.Equal(t, blocks, syncedHeight)
+
+	// but nothing is broadcasted or left to be broadcasted after the dry-run
+	require.Zero(t, chain.Calls(nodetest.MethodBroadcastTxSync))
+	require.Empty(t, chain.Broadcasted())
+	for _, prefix := range [][]byte{btypes.ProcessedMsgsKey, btypes.PendingTxsKey} {
+		require.NoError(t, n.db.PrefixedIterate(prefix, nil, func(key, _ []byte) (bool, error) {
+			t.Fatalf("unexpected key: %s", key)
+			return true, nil
+		}))
+	}
+
+	// the msgs of every block are recorded in order
+	records, err := b.DryRunRecords()
+	require.NoError(t, err)
+	require.Len(t, records, int(blocks))
+	for i, record := range records {
+		require.Equal(t, sender, record.Sender)
+		require.Equal(t, []string{"/cosmos.bank.v1beta1.MsgSend"}, record.MsgTypes)
+		require.Contains(t, string(record.Msgs[0]), fmt.Sprintf(`"amount":"%d"`, i+1))
+	}
+}
@@ -0,0 +1,33 @@
+package node
+
+import (
+	"github.com/initia-labs/opinit-bots/db/integrity"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// IntegrityChecks returns the integrity checks of the sync info and the pending txs of the node db.
+func IntegrityChecks(name string, db types.DB) []integrity.Check {
+	return []integrity.Check{
+		{
+			Name:   name + "/sync_info",
+			DB:     db,
+			Prefix: nodetypes.LastProcessedBlockHeightKey,
+			Decode: func(_, value []byte) error {
+				_, err := dbtypes.ToUint64(value)
+				return err
+			},
+		},
+		{
+			Name:   name + "/pending_txs",
+			DB:     db,
+			Prefix: btypes.PendingTxsKey,
+			Decode: func(_, value []byte) error {
+				var pendingTx btypes.PendingTxInfo
+				return pendingTx.Unmarshal(value)
+			},
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const DefaultNamespace = "opinit"
+
+type Config struct {
+	// Address is the address of the metrics listener. If it is empty, the listener is not started.
+	Address string `json:"address"`
+	// Namespace is the namespace of the metrics.
+	Namespace string `json:"namespace"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Address:   "",
+		Namespace: DefaultNamespace,
+	}
+}
+
+func (cfg Config) Validate() error {
+	if cfg.Namespace == "" {
+		return errors.New("metrics namespace is required")
+	}
+	return nil
+}
+
+var (
+	mu       sync.RWMutex
+	cfg      = DefaultConfig()
+	registry = prometheus.NewRegistry()
+)
+
+// Init sets the config and resets the registry.
+// It should be called before creating any collectors.
+func Init(config Config) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if config.Namespace == "" {
+		config.Namespace = DefaultNamespace
+	}
+	cfg = config
+	registry = prometheus.NewRegistry()
+}
+
+func Namespace() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return cfg.Namespace
+}
+
+func Registry() *prometheus.Registry {
+	mu.RLock()
+	defer mu.RUnlock()
+	return registry
+}
+
+// BridgeLabel is the const label of the collectors of the bridge instances run by the multi executor.
+const BridgeLabel = "bridge"
+
+// Registerer returns the registerer of the collectors of the bridge instance, which labels the collectors
+// with the bridge id, so the bridges run in a process don't share the collectors. The collectors of
+// the single bridge are registered without the label, as a process runs either the single bridge
+// or the bridge instances.
+func Registerer(bridge string) prometheus.Registerer {
+	if bridge == "" {
+		return Registry()
+	}
+	return prometheus.WrapRegistererWith(prometheus.Labels{BridgeLabel: bridge}, Registry())
+}
+
+// Register registers the collector of the bridge instance to the registry.
+// If an equal collector is already registered, the registered one is returned.
+func Register[T prometheus.Collector](bridge string, c T) T {
+	err := Registerer(bridge).Register(c)
+	if err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// StartServer serves the metrics at `/metrics` until the context is done.
+// If the address is not configured, it returns immediately.
+func StartServer(ctx context.Context) error {
+	mu.RLock()
+	address := cfg.Address
+	mu.RUnlock()
+	if address == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry(), promhttp.HandlerOpts{}))
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BridgeCollectors(t *testing.T) {
+	Init(DefaultConfig())
+	defer Init(DefaultConfig())
+
+	minimove := NewNodeMetrics("minimove")
+	miniwasm := NewNodeMetrics("miniwasm")
+
+	// the collectors of a bridge are shared by its nodes, but not by the other bridges
+	require.Same(t, minimove.PendingTxs, NewNodeMetrics("minimove").PendingTxs)
+	require.NotSame(t, minimove.PendingTxs, miniwasm.PendingTxs)
+	minimove.PendingTxs.WithLabelValues("host").Set(2)
+	miniwasm.PendingTxs.WithLabelValues("host").Set(3)
+
+	families, err := Registry().Gather()
+	require.NoError(t, err)
+	values := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "opinit_broadcaster_pending_txs" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			bridge := ""
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == BridgeLabel {
+					bridge = label.GetValue()
+				}
+			}
+			values[bridge] = metric.GetGauge().GetValue()
+		}
+	}
+	require.Equal(t, map[string]float64{"minimove": 2, "miniwasm": 3}, values)
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NodeMetrics are the collectors shared by the nodes and broadcasters of a bridge.
+// Each node is distinguished by the `node` label.
+type NodeMetrics struct {
+	LastProcessedHeight *prometheus.GaugeVec
+	LatestChainHeight   *prometheus.GaugeVec
+	PendingTxs          *prometheus.GaugeVec
+	Broadcasts          *prometheus.CounterVec
+	HandlerDuration     *prometheus.HistogramVec
+	RPCLatency          *prometheus.HistogramVec
+
+	EventHandlerDuration *prometheus.HistogramVec
+}
+
+func NewNodeMetrics(bridge string) *NodeMetrics {
+	namespace := Namespace()
+	return &NodeMetrics{
+		LastProcessedHeight: Register(bridge, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "last_processed_block_height",
+			Help:      "The last block height processed by the node.",
+		}, []string{"node"})),
+		LatestChainHeight: Register(bridge, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "latest_chain_height",
+			Help:      "The latest block height of the chain.",
+		}, []string{"node"})),
+		PendingTxs: Register(bridge, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "broadcaster",
+			Name:      "pending_txs",
+			Help:      "The number of broadcasted txs waiting to be included in a block.",
+		}, []string{"node"})),
+		Broadcasts: Register(bridge, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "broadcaster",
+			Name:      "broadcasts_total",
+			Help:      "The number of broadcasted txs by the result code.",
+		}, []string{"node", "code"})),
+		HandlerDuration: Register(bridge, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "handler_duration_seconds",
+			Help:      "The execution time of the handlers by the event type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"node", "event_type"})),
+		RPCLatency: Register(bridge, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "rpc_latency_seconds",
+			Help:      "The latency of the rpc calls by the method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"node", "method"})),
+		EventHandlerDuration: Register(bridge, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "event_handler_duration_seconds",
+			Help:      "The execution time of the event handlers with the metrics option by the event type and the result.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"node", "event_type", "result"})),
+	}
+}
@@ -0,0 +1,106 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// EventHandlerOption is the middleware wrapping the event handler registered to the node. The options are
+// applied in the order of the arguments, so the first option is the outermost one and runs first.
+type EventHandlerOption func(n *Node, eventType string, next nodetypes.EventHandlerFn) nodetypes.EventHandlerFn
+
+// wrapEventHandler wraps the handler with the options, the first option being the outermost.
+func (n *Node) wrapEventHandler(eventType string, fn nodetypes.EventHandlerFn, opts []EventHandlerOption) nodetypes.EventHandlerFn {
+	for i := len(opts) - 1; i >= 0; i-- {
+		fn = opts[i](n, eventType, fn)
+	}
+	return fn
+}
+
+// WithTimeout cancels the context of the handler after the timeout.
+func WithTimeout(timeout time.Duration) EventHandlerOption {
+	return func(_ *Node, _ string, next nodetypes.EventHandlerFn) nodetypes.EventHandlerFn {
+		return func(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, args)
+		}
+	}
+}
+
+// WithRetry calls the failed handler again up to `retries` times, sleeping `backoff * 2^retry` between the calls,
+// so the handler must be idempotent. ErrIgnoreAndTryLater and ErrRetryBlock are returned without the retry
+// to be handled by the block process looper.
+func WithRetry(retries int, backoff time.Duration) EventHandlerOption {
+	return func(n *Node, eventType string, next nodetypes.EventHandlerFn) nodetypes.EventHandlerFn {
+		return func(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+			var err error
+			for retry := 0; retry <= retries; retry++ {
+				if types.SleepWithBackoff(ctx, backoff, retry) {
+					return errors.Join(err, ctx.Err())
+				}
+
+				err = next(ctx, args)
+				if err == nil || errors.Is(err, nodetypes.ErrIgnoreAndTryLater) || errors.Is(err, nodetypes.ErrRetryBlock) {
+					return err
+				} else if retry < retries {
+					n.logger.Warn("retry event handler",
+						zap.String("event_type", eventType),
+						zap.Int64("height", args.BlockHeight),
+						zap.Int("retry", retry+1),
+						zap.String("error", err.Error()),
+					)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// WithAttributeFilter calls the handler only for the events with the attribute of the key and the value,
+// e.g. to scope the events of the host chain to the bridge id.
+func WithAttributeFilter(key string, value string) EventHandlerOption {
+	return func(_ *Node, _ string, next nodetypes.EventHandlerFn) nodetypes.EventHandlerFn {
+		return func(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+			if !hasAttribute(args.EventAttributes, key, value) {
+				return nil
+			}
+			return next(ctx, args)
+		}
+	}
+}
+
+func hasAttribute(attrs []abcitypes.EventAttribute, key string, value string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key && attr.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// WithMetrics records the execution time of the handler by the result in the `event_handler_duration_seconds`
+// metric. Unlike the `handler_duration_seconds` metric, the events dropped by the outer options are not recorded.
+func WithMetrics() EventHandlerOption {
+	return func(n *Node, eventType string, next nodetypes.EventHandlerFn) nodetypes.EventHandlerFn {
+		return func(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+			start := time.Now()
+			err := next(ctx, args)
+
+			result := "success"
+			if err != nil {
+				result = "error"
+			}
+			n.metrics.EventHandlerDuration.WithLabelValues(n.logger.Name(), eventType, result).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
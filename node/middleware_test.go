@@ -0,0 +1,152 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+	comettypes "github.com/cometbft/cometbft/types"
+
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_EventHandlerOptionOrder(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+
+	calls := make([]string, 0)
+	option := func(name string) EventHandlerOption {
+		return func(_ *Node, eventType string, next nodetypes.EventHandlerFn) nodetypes.EventHandlerFn {
+			return func(ctx context.Context, args nodetypes.EventHandlerArgs) error {
+				calls = append(calls, name+"/"+eventType)
+				return next(ctx, args)
+			}
+		}
+	}
+	n.RegisterEventHandler("transfer", func(context.Context, nodetypes.EventHandlerArgs) error {
+		calls = append(calls, "handler")
+		return nil
+	}, option("first"), option("second"))
+
+	require.NoError(t, n.EventHandler("transfer")(context.Background(), nodetypes.EventHandlerArgs{}))
+	require.Equal(t, []string{"first/transfer", "second/transfer", "handler"}, calls)
+}
+
+func Test_WithAttributeFilter(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+
+	handled := make([]int64, 0)
+	n.RegisterEventHandler("transfer", func(_ context.Context, args nodetypes.EventHandlerArgs) error {
+		handled = append(handled, args.BlockHeight)
+		return nil
+	}, WithAttributeFilter("bridge_id", "1"))
+
+	newBlock := func(height int64, attrs ...abcitypes.EventAttribute) (*rpccoretypes.ResultBlock, *rpccoretypes.ResultBlockResults) {
+		return &rpccoretypes.ResultBlock{
+			Block: &comettypes.Block{
+				Header: comettypes.Header{ChainID: "test-1", Height: height, Time: time.Unix(height, 0)},
+				Data:   comettypes.Data{Txs: comettypes.Txs{[]byte("tx0")}},
+			},
+		}, &rpccoretypes.ResultBlockResults{
+			Height:     height,
+			TxsResults: []*abcitypes.ExecTxResult{{Events: []abcitypes.Event{{Type: "transfer", Attributes: attrs}}}},
+		}
+	}
+
+	// the events of the other bridge and without the attribute are not handled
+	for height, attrs := range [][]abcitypes.EventAttribute{
+		{{Key: "bridge_id", Value: "2"}},
+		{{Key: "sender", Value: "1"}},
+		{{Key: "sender", Value: "init1sender"}, {Key: "bridge_id", Value: "1"}},
+	} {
+		block, blockResult := newBlock(int64(height+1), attrs...)
+		require.NoError(t, n.handleNewBlock(context.Background(), block, blockResult, 10))
+	}
+	require.Equal(t, []int64{3}, handled)
+}
+
+func Test_WithRetry(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+
+	failures := 2
+	calls := 0
+	n.RegisterEventHandler("transfer", func(context.Context, nodetypes.EventHandlerArgs) error {
+		calls++
+		if calls <= failures {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}, WithRetry(2, time.Millisecond))
+
+	require.NoError(t, n.EventHandler("transfer")(context.Background(), nodetypes.EventHandlerArgs{}))
+	require.Equal(t, 3, calls)
+
+	// the error after the retries is returned
+	calls, failures = 0, 5
+	require.ErrorContains(t, n.EventHandler("transfer")(context.Background(), nodetypes.EventHandlerArgs{}), "temporary failure")
+	require.Equal(t, 3, calls)
+
+	// the looper errors are not retried
+	n.RegisterEventHandler("transfer", func(context.Context, nodetypes.EventHandlerArgs) error {
+		calls++
+		return nodetypes.ErrIgnoreAndTryLater
+	}, WithRetry(2, time.Millisecond))
+	calls = 0
+	require.ErrorIs(t, n.EventHandler("transfer")(context.Background(), nodetypes.EventHandlerArgs{}), nodetypes.ErrIgnoreAndTryLater)
+	require.Equal(t, 1, calls)
+}
+
+func Test_WithTimeout(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+
+	n.RegisterEventHandler("transfer", func(ctx context.Context, _ nodetypes.EventHandlerArgs) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+	require.ErrorIs(t, n.EventHandler("transfer")(context.Background(), nodetypes.EventHandlerArgs{}), context.DeadlineExceeded)
+}
+
+func Test_WithMetrics(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+	n.metrics.EventHandlerDuration.Reset()
+
+	countSeries := func() int {
+		ch := make(chan prometheus.Metric, 10)
+		n.metrics.EventHandlerDuration.Collect(ch)
+		close(ch)
+		return len(ch)
+	}
+	handler := func(_ context.Context, args nodetypes.EventHandlerArgs) error {
+		if args.BlockHeight == 3 {
+			return errors.New("failure")
+		}
+		return nil
+	}
+	args := func(height int64, bridgeId string) nodetypes.EventHandlerArgs {
+		return nodetypes.EventHandlerArgs{
+			BlockHeight:     height,
+			EventAttributes: []abcitypes.EventAttribute{{Key: "bridge_id", Value: bridgeId}},
+		}
+	}
+
+	// the event filtered before the metrics is not recorded
+	n.RegisterEventHandler("transfer", handler, WithAttributeFilter("bridge_id", "1"), WithMetrics())
+	require.NoError(t, n.EventHandler("transfer")(context.Background(), args(1, "2")))
+	require.Zero(t, countSeries())
+
+	require.NoError(t, n.EventHandler("transfer")(context.Background(), args(2, "1")))
+	require.Equal(t, 1, countSeries())
+	require.Error(t, n.EventHandler("transfer")(context.Background(), args(3, "1")))
+	require.Equal(t, 2, countSeries())
+
+	// the event filtered after the metrics is recorded
+	n.RegisterEventHandler("send", handler, WithMetrics(), WithAttributeFilter("bridge_id", "1"))
+	require.NoError(t, n.EventHandler("send")(context.Background(), args(1, "2")))
+	require.Equal(t, 3, countSeries())
+}
@@ -0,0 +1,17 @@
+package node
+
+import (
+	"github.com/initia-labs/opinit-bots/db/migration"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// SchemaName is the name of the schema of the node db, which has the sync info, the pending txs and the processed msgs.
+const SchemaName = "node"
+
+// Schema returns the schema of the node db with its migrations in order.
+func Schema(db types.DB) migration.Schema {
+	return migration.Schema{
+		Name: SchemaName,
+		DB:   db,
+	}
+}
@@ -3,20 +3,29 @@ package node
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 
 	"cosmossdk.io/core/address"
 	"github.com/initia-labs/opinit-bots/node/broadcaster"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/metrics"
 	"github.com/initia-labs/opinit-bots/node/rpcclient"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/txutils"
 	"github.com/initia-labs/opinit-bots/types"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+
+	gogogrpc "github.com/cosmos/gogoproto/grpc"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
 )
 
 type Node struct {
@@ -27,15 +36,40 @@ type Node struct {
 	cdc      codec.Codec
 	txConfig client.TxConfig
 
-	rpcClient   *rpcclient.RPCClient
+	rpcClient rpcclient.Client
+	queryConn gogogrpc.ClientConn
+	// the grpc connection of the queries, nil if the queries use the rpc
+	grpcConn    *grpc.ClientConn
 	broadcaster *broadcaster.Broadcaster
+	metrics     *metrics.NodeMetrics
+	status      *statusSnapshot
 
 	// handlers
 	eventHandlers     map[string]nodetypes.EventHandlerFn
+	eventKeyFns       map[string]nodetypes.EventKeyFn
 	txHandler         nodetypes.TxHandlerFn
 	beginBlockHandler nodetypes.BeginBlockHandlerFn
 	endBlockHandler   nodetypes.EndBlockHandlerFn
 	rawBlockHandler   nodetypes.RawBlockHandlerFn
+	skipBlockHandler  nodetypes.SkipBlockHandlerFn
+	restartHandler    nodetypes.RestartHandlerFn
+	rewindHandler     nodetypes.RewindHandlerFn
+
+	txConfirmedHandlers []nodetypes.TxConfirmedHandlerFn
+
+	skipHeights map[int64]struct{}
+	replaying   *atomic.Bool
+
+	// replays requested to the running block process looper
+	replayMu       *sync.Mutex
+	replayRequests chan replayRequest
+
+	// requested height to rewind to, 0 if not requested
+	rewindHeight *atomic.Int64
+
+	// closed when the block process looper is stopped
+	blockProcessStopped chan struct{}
+	broadcasterStarted  *atomic.Bool
 
 	// status info
 	startHeightInitialized   bool
@@ -48,25 +82,69 @@ func NewNode(cfg nodetypes.NodeConfig, db types.DB, logger *zap.Logger, cdc code
 		return nil, err
 	}
 
-	rpcClient, err := rpcclient.NewRPCClient(cdc, cfg.RPC)
+	rpcClient, err := rpcclient.NewRPCClientWithTimeout(cdc, cfg.RPC, cfg.GetRPCTimeout())
 	if err != nil {
 		return nil, err
 	}
+	return NewNodeWithRPCClient(cfg, db, logger, cdc, txConfig, rpcClient)
+}
+
+// NewNodeWithRPCClient creates the node on the given rpc client instead of the one connected to the rpc
+// address of the config, which allows the node to run against the fake chain of the nodetest package.
+func NewNodeWithRPCClient(cfg nodetypes.NodeConfig, db types.DB, logger *zap.Logger, cdc codec.Codec, txConfig client.TxConfig, rpcClient rpcclient.Client) (*Node, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var queryConn gogogrpc.ClientConn = rpcClient
+	var grpcConn *grpc.ClientConn
+	if cfg.GRPCAddress != "" {
+		conn, err := rpcclient.NewGRPCConn(cdc, cfg.GRPCAddress, cfg.GRPCTLS)
+		if err != nil {
+			return nil, err
+		}
+		queryConn = rpcclient.NewQueryConn(conn, rpcClient, logger)
+		grpcConn = conn
+	}
 
 	n := &Node{
 		rpcClient: rpcClient,
+		queryConn: queryConn,
+		grpcConn:  grpcConn,
+		metrics:   metrics.NewNodeMetrics(cfg.Bridge),
+		status:    newStatusSnapshot(),
 
 		cfg:    cfg,
 		db:     db,
 		logger: logger,
 
 		eventHandlers: make(map[string]nodetypes.EventHandlerFn),
+		eventKeyFns:   make(map[string]nodetypes.EventKeyFn),
+
+		skipHeights: make(map[int64]struct{}),
+		replaying:   &atomic.Bool{},
+
+		replayMu:       &sync.Mutex{},
+		replayRequests: make(chan replayRequest),
+
+		rewindHeight: &atomic.Int64{},
+
+		blockProcessStopped: make(chan struct{}),
+		broadcasterStarted:  &atomic.Bool{},
 
 		cdc:      cdc,
 		txConfig: txConfig,
 	}
+	for _, height := range cfg.SkipHeights {
+		n.skipHeights[height] = struct{}{}
+	}
+	if !cfg.ProcessType.SyncsBlocks() {
+		close(n.blockProcessStopped)
+	}
+
 	// create broadcaster
 	if n.cfg.BroadcasterConfig != nil {
+		var err error
 		n.broadcaster, err = broadcaster.NewBroadcaster(
 			*n.cfg.BroadcasterConfig,
 			n.db,
@@ -78,6 +156,7 @@ func NewNode(cfg nodetypes.NodeConfig, db types.DB, logger *zap.Logger, cdc code
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create broadcaster")
 		}
+		n.broadcaster.SetQueryConn(n.queryConn)
 	}
 
 	return n, nil
@@ -86,22 +165,41 @@ func NewNode(cfg nodetypes.NodeConfig, db types.DB, logger *zap.Logger, cdc code
 // StartHeight is the height to start processing.
 // If it is 0, the latest height is used.
 // If the latest height exists in the database, this is ignored.
+//
+// If the node is catching up the chain, the preparation of the broadcaster and the block process
+// are deferred until the node catches up, which is waited in Start.
 func (n *Node) Initialize(ctx context.Context, processedHeight int64, keyringConfig []btypes.KeyringConfig) (err error) {
-	// check if node is catching up
-	status, err := n.rpcClient.Status(ctx)
-	if err != nil {
-		return err
+	if n.broadcaster != nil {
+		err = n.broadcaster.AddAccounts(keyringConfig)
+		if err != nil {
+			return err
+		}
 	}
-	if status.SyncInfo.CatchingUp {
-		return errors.New("node is catching up")
+
+	// the node only broadcasting the txs doesn't sync the blocks, so it doesn't wait for the node to catch up
+	caughtUp := true
+	if n.cfg.ProcessType.SyncsBlocks() {
+		caughtUp, err = n.checkCaughtUp(ctx)
+		if err != nil {
+			return err
+		}
 	}
-	if n.broadcaster != nil {
-		err = n.broadcaster.Initialize(ctx, status, keyringConfig)
+
+	if !caughtUp {
+		n.logger.Warn("node is catching up; wait for the node to catch up before broadcasting txs and processing blocks")
+		n.status.setCatchingUp(true)
+	} else if n.broadcaster != nil {
+		err = n.broadcaster.Prepare(ctx)
 		if err != nil {
 			return err
 		}
 	}
 
+	// the node only broadcasting the txs has no sync info
+	if !n.cfg.ProcessType.SyncsBlocks() {
+		return nil
+	}
+
 	// load sync info
 	return n.loadSyncInfo(processedHeight)
 }
@@ -116,8 +214,29 @@ func (n *Node) Start(ctx context.Context) {
 	}
 	n.running = true
 
+	if !n.status.isCatchingUp() {
+		n.start(ctx)
+		return
+	}
+
+	types.ErrGrp(ctx).Go(func() error {
+		err := n.waitCatchUp(ctx)
+		if err != nil || ctx.Err() != nil || types.ShuttingDown(ctx) {
+			if n.cfg.ProcessType.SyncsBlocks() {
+				close(n.blockProcessStopped)
+			}
+			return err
+		}
+		n.start(ctx)
+		return nil
+	})
+}
+
+// start runs the broadcaster, the block process and the tx checker loops.
+func (n *Node) start(ctx context.Context) {
 	errGrp := ctx.Value(types.ContextKeyErrGrp).(*errgroup.Group)
 	if n.broadcaster != nil {
+		n.broadcasterStarted.Store(true)
 		errGrp.Go(func() (err error) {
 			defer func() {
 				n.logger.Info("tx broadcast looper stopped")
@@ -131,22 +250,32 @@ func (n *Node) Start(ctx context.Context) {
 		})
 
 		// broadcast pending msgs first before executing block process looper
-		n.broadcaster.BroadcastPendingProcessedMsgs()
+		err := n.broadcaster.BroadcastPendingProcessedMsgs()
+		if err != nil {
+			if n.cfg.ProcessType.SyncsBlocks() {
+				close(n.blockProcessStopped)
+			}
+			errGrp.Go(func() error {
+				return err
+			})
+			return
+		}
 	}
 
 	enableEventHandler := true
-	if n.cfg.ProcessType != nodetypes.PROCESS_TYPE_ONLY_BROADCAST {
+	if n.cfg.ProcessType.SyncsBlocks() {
 		enableEventHandler = false
 		errGrp.Go(func() (err error) {
 			defer func() {
 				n.logger.Info("block process looper stopped")
+				close(n.blockProcessStopped)
 				if r := recover(); r != nil {
 					n.logger.Error("block process looper panic", zap.Any("recover", r))
 					err = fmt.Errorf("block process looper panic: %v", r)
 				}
 			}()
 
-			return n.blockProcessLooper(ctx, n.cfg.ProcessType)
+			return n.blockProcessLooperWithRestart(ctx, n.cfg.ProcessType)
 		})
 	}
 
@@ -163,6 +292,12 @@ func (n *Node) Start(ctx context.Context) {
 	})
 }
 
+// BlockProcessStopped returns the channel closed when the block process looper is stopped, so the
+// handlers of the last block are completed. It is closed from the beginning if the node doesn't sync the blocks.
+func (n Node) BlockProcessStopped() <-chan struct{} {
+	return n.blockProcessStopped
+}
+
 func (n Node) AccountCodec() address.Codec {
 	return n.cdc.InterfaceRegistry().SigningContext().AddressCodec()
 }
@@ -175,6 +310,29 @@ func (n Node) GetTxConfig() client.TxConfig {
 	return n.txConfig
 }
 
+// DecodeTx decodes the tx bytes, such as the ones of the pending txs, with the tx config of the chain.
+func (n Node) DecodeTx(txBytes []byte) (authsigning.Tx, error) {
+	return txutils.DecodeTx(n.txConfig, txBytes)
+}
+
+// DecodeTxMsgs decodes the msgs of the tx bytes. With the lenient tx decoding of the config, the tx
+// with the unknown type urls is decoded with a warning, leaving the msgs of the unknown type urls as
+// the packed any.
+func (n Node) DecodeTxMsgs(txBytes []byte) ([]sdk.Msg, error) {
+	msgs, unknownTypeURLs, err := txutils.DecodeTxMsgs(n.cdc, n.txConfig, txBytes, n.cfg.LenientTxDecoding)
+	if err != nil {
+		return nil, err
+	} else if len(unknownTypeURLs) != 0 {
+		n.logger.Warn("tx has msgs of unknown type urls", zap.Strings("type_urls", unknownTypeURLs))
+	}
+	return msgs, nil
+}
+
+// EncodeTx encodes the tx with the tx config of the chain.
+func (n Node) EncodeTx(tx authsigning.Tx) ([]byte, error) {
+	return txutils.EncodeTx(n.txConfig, tx)
+}
+
 func (n Node) HasBroadcaster() bool {
 	return n.broadcaster != nil
 }
@@ -187,6 +345,24 @@ func (n Node) GetBroadcaster() (*broadcaster.Broadcaster, error) {
 	return n.broadcaster, nil
 }
 
+// DrainBroadcaster waits for the broadcaster to stop on the shutdown, and persists the msgs left in its queue,
+// so they are broadcasted after the restart. It returns the number of the persisted and the discarded msgs.
+func (n Node) DrainBroadcaster(ctx context.Context) (persisted int, discarded int, err error) {
+	if n.broadcaster == nil {
+		return 0, 0, types.ErrKeyNotSet
+	}
+
+	// the broadcaster of the node stopped while catching up is never started
+	if n.broadcasterStarted.Load() {
+		select {
+		case <-ctx.Done():
+			return 0, 0, errors.Wrap(ctx.Err(), "failed to wait for the broadcaster to stop")
+		case <-n.broadcaster.Stopped():
+		}
+	}
+	return n.broadcaster.PersistQueuedMsgs()
+}
+
 func (n Node) MustGetBroadcaster() *broadcaster.Broadcaster {
 	if n.broadcaster == nil {
 		panic("cannot get broadcaster without broadcaster")
@@ -195,26 +371,127 @@ func (n Node) MustGetBroadcaster() *broadcaster.Broadcaster {
 	return n.broadcaster
 }
 
-func (n Node) GetRPCClient() *rpcclient.RPCClient {
+func (n Node) GetRPCClient() rpcclient.Client {
 	return n.rpcClient
 }
 
-func (n *Node) RegisterTxHandler(fn nodetypes.TxHandlerFn) {
+// QueryConn returns the connection for the grpc queries. It is the grpc connection
+// if the grpc address is configured, otherwise the rpc client.
+func (n Node) QueryConn() gogogrpc.ClientConn {
+	return n.queryConn
+}
+
+// Close closes the grpc connection of the queries. It is called when the node is shut down.
+func (n Node) Close() error {
+	if n.grpcConn == nil {
+		return nil
+	}
+	return n.grpcConn.Close()
+}
+
+// QueryContext returns the grpc query context pinned to the given height.
+// If the height is 0, the query is performed at the latest height.
+func (n Node) QueryContext(ctx context.Context, height int64) (context.Context, context.CancelFunc) {
+	return rpcclient.GetQueryContextWithTimeout(ctx, height, n.cfg.GetRPCTimeout())
+}
+
+func (n *Node) RegisterTxHandler(fn nodetypes.TxHandlerFn) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
 	n.txHandler = fn
+	return nil
+}
+
+// RegisterTxConfirmedHandler registers the handler called with the pending txs of the broadcaster
+// when they are included in a block. The node can be shared by the components, e.g. the host
+// which is also the DA, so the handlers are called in the order of registration.
+func (n *Node) RegisterTxConfirmedHandler(fn nodetypes.TxConfirmedHandlerFn) {
+	n.txConfirmedHandlers = append(n.txConfirmedHandlers, fn)
 }
 
-func (n *Node) RegisterEventHandler(eventType string, fn nodetypes.EventHandlerFn) {
-	n.eventHandlers[eventType] = fn
+// RegisterEventHandler registers the event handler wrapped with the options, e.g. WithMetrics.
+func (n *Node) RegisterEventHandler(eventType string, fn nodetypes.EventHandlerFn, opts ...EventHandlerOption) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
+	n.eventHandlers[eventType] = n.wrapEventHandler(eventType, fn, opts)
+	return nil
 }
 
-func (n *Node) RegisterBeginBlockHandler(fn nodetypes.BeginBlockHandlerFn) {
+// RegisterEventHandlerWithDedup registers the event handler which is called only once
+// for the events with the same key in the same tx. The duplicated events are dropped with a warning.
+func (n *Node) RegisterEventHandlerWithDedup(eventType string, fn nodetypes.EventHandlerFn, keyFn nodetypes.EventKeyFn, opts ...EventHandlerOption) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
+	n.eventHandlers[eventType] = n.wrapEventHandler(eventType, fn, opts)
+	n.eventKeyFns[eventType] = keyFn
+	return nil
+}
+
+// EventHandler returns the handler registered for the event type with its options, or nil if not registered.
+func (n Node) EventHandler(eventType string) nodetypes.EventHandlerFn {
+	return n.eventHandlers[eventType]
+}
+
+func (n *Node) RegisterBeginBlockHandler(fn nodetypes.BeginBlockHandlerFn) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
 	n.beginBlockHandler = fn
+	return nil
 }
 
-func (n *Node) RegisterEndBlockHandler(fn nodetypes.EndBlockHandlerFn) {
+func (n *Node) RegisterEndBlockHandler(fn nodetypes.EndBlockHandlerFn) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
 	n.endBlockHandler = fn
+	return nil
 }
 
-func (n *Node) RegisterRawBlockHandler(fn nodetypes.RawBlockHandlerFn) {
+// RegisterRawBlockHandler registers the handler to receive the raw block of each height.
+// The handler is called before the other handlers of the same height, and the height
+// is marked as processed(lastProcessedBlockHeight) only after all the handlers succeed.
+func (n *Node) RegisterRawBlockHandler(fn nodetypes.RawBlockHandlerFn) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
 	n.rawBlockHandler = fn
+	return nil
+}
+
+// RegisterSkipBlockHandler registers the handler called for the block skipped by the skip heights or after a handler panic.
+func (n *Node) RegisterSkipBlockHandler(fn nodetypes.SkipBlockHandlerFn) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
+	n.skipBlockHandler = fn
+	return nil
+}
+
+func (n *Node) RegisterRestartHandler(fn nodetypes.RestartHandlerFn) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
+	n.restartHandler = fn
+	return nil
+}
+
+func (n *Node) RegisterRewindHandler(fn nodetypes.RewindHandlerFn) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	}
+	n.rewindHandler = fn
+	return nil
+}
+
+// checkBlockSync returns ErrBlockSyncInactive if the node doesn't sync the blocks, so the block sync
+// methods of the broadcast-only node fail fast.
+func (n Node) checkBlockSync() error {
+	if !n.cfg.ProcessType.SyncsBlocks() {
+		return errors.Wrapf(nodetypes.ErrBlockSyncInactive, "process type: %s", n.cfg.ProcessType)
+	}
+	return nil
 }
@@ -0,0 +1,550 @@
+package nodetest
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/metadata"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/p2p"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	comettypes "github.com/cometbft/cometbft/types"
+
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+
+	"github.com/initia-labs/opinit-bots/node/rpcclient"
+)
+
+const (
+	MethodStatus          = "status"
+	MethodBlock           = "block"
+	MethodBlockResults    = "block_results"
+	MethodBlockBulk       = "block_bulk"
+	MethodHeader          = "header"
+	MethodValidators      = "validators"
+	MethodBroadcastTxSync = "broadcast_tx_sync"
+	MethodTx              = "tx"
+	MethodTxSearch        = "tx_search"
+	MethodABCIQuery       = "abci_query"
+	MethodCommit          = "commit"
+)
+
+var _ rpcclient.Client = &FakeClient{}
+
+var protoCodec = encoding.GetCodec(proto.Name)
+
+// CallHookFn is called before every call to the fake client with the rpc method name. If it returns
+// an error, the call fails with the error. It may block on the context to simulate a hung call.
+type CallHookFn func(ctx context.Context, method string) error
+
+// BroadcastFn returns the outcome of the broadcasted tx.
+type BroadcastFn func(tx comettypes.Tx) (*coretypes.ResultBroadcastTx, error)
+
+// QueryFn handles the abci query, which is also invoked by the grpc queries through the fake client.
+type QueryFn func(req abci.RequestQuery) (abci.ResponseQuery, error)
+
+// TxSearchFn handles the tx search query.
+type TxSearchFn func(query string, page, perPage int, orderBy string) (*coretypes.ResultTxSearch, error)
+
+// FakeClient is the scriptable fake of the rpc client, which serves the blocks, the events and the
+// broadcast outcomes programmed by the test instead of the live chain.
+type FakeClient struct {
+	mu sync.Mutex
+
+	chainID      string
+	latestHeight int64
+	catchingUp   bool
+	// autoIncrease increases the latest height on every latest header query.
+	autoIncrease bool
+
+	blocks       map[int64]*coretypes.ResultBlock
+	blockResults map[int64]*coretypes.ResultBlockResults
+	validators   map[int64][]*comettypes.Validator
+
+	broadcasted []comettypes.Tx
+	landed      map[string]*coretypes.ResultTx
+
+	callHook    CallHookFn
+	broadcastFn BroadcastFn
+	txSearchFn  TxSearchFn
+	queryFns    map[string]QueryFn
+
+	calls map[string]int
+}
+
+func NewFakeClient(chainID string) *FakeClient {
+	return &FakeClient{
+		chainID:      chainID,
+		blocks:       make(map[int64]*coretypes.ResultBlock),
+		blockResults: make(map[int64]*coretypes.ResultBlockResults),
+		validators:   make(map[int64][]*comettypes.Validator),
+		landed:       make(map[string]*coretypes.ResultTx),
+		queryFns:     make(map[string]QueryFn),
+		calls:        make(map[string]int),
+	}
+}
+
+// AddBlock sets the block and the block results of the height of the block, which replaces the existing
+// block of the height. The latest height is increased to the height of the block if it is lower.
+// If the block results are nil, the empty results of the txs in the block are served.
+func (c *FakeClient) AddBlock(block *coretypes.ResultBlock, blockResults *coretypes.ResultBlockResults) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	height := block.Block.Height
+	if blockResults == nil {
+		blockResults = &coretypes.ResultBlockResults{Height: height}
+		for range block.Block.Txs {
+			blockResults.TxsResults = append(blockResults.TxsResults, &abci.ExecTxResult{})
+		}
+	}
+	c.blocks[height] = block
+	c.blockResults[height] = blockResults
+	if height > c.latestHeight {
+		c.latestHeight = height
+	}
+}
+
+// AppendBlock creates the block of the next height linked to the latest block with the given txs,
+// and the results of the txs emitting the events. The finalize block events are emitted after the txs.
+func (c *FakeClient) AppendBlock(txs comettypes.Txs, txResults []*abci.ExecTxResult, finalizeBlockEvents ...abci.Event) *coretypes.ResultBlock {
+	c.mu.Lock()
+	height := c.latestHeight + 1
+	parent := comettypes.BlockID{}
+	if prev, ok := c.blocks[height-1]; ok {
+		parent = prev.BlockID
+	}
+	c.mu.Unlock()
+
+	header := comettypes.Header{
+		ChainID:        c.chainID,
+		Height:         height,
+		Time:           time.Unix(height, 0).UTC(),
+		LastBlockID:    parent,
+		ValidatorsHash: make([]byte, 32),
+	}
+	block := &coretypes.ResultBlock{
+		BlockID: comettypes.BlockID{Hash: header.Hash()},
+		Block:   &comettypes.Block{Header: header, Data: comettypes.Data{Txs: txs}},
+	}
+	if len(txResults) < len(txs) {
+		for range len(txs) - len(txResults) {
+			txResults = append(txResults, &abci.ExecTxResult{})
+		}
+	}
+	c.AddBlock(block, &coretypes.ResultBlockResults{
+		Height:              height,
+		TxsResults:          txResults,
+		FinalizeBlockEvents: finalizeBlockEvents,
+	})
+	return block
+}
+
+// AppendBlocks appends the given number of the empty blocks.
+func (c *FakeClient) AppendBlocks(count int) {
+	for range count {
+		c.AppendBlock(nil, nil)
+	}
+}
+
+// SetLatestHeight sets the latest height of the chain reported by the status and the latest header.
+func (c *FakeClient) SetLatestHeight(height int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latestHeight = height
+}
+
+func (c *FakeClient) LatestHeight() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latestHeight
+}
+
+// SetCatchingUp sets whether the status reports that the node is catching up the chain.
+func (c *FakeClient) SetCatchingUp(catchingUp bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.catchingUp = catchingUp
+}
+
+// SetAutoIncreaseHeight sets whether every latest header query produces a new height.
+func (c *FakeClient) SetAutoIncreaseHeight(increase bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.autoIncrease = increase
+}
+
+// SetValidators sets the validator set of the height.
+func (c *FakeClient) SetValidators(height int64, validators []*comettypes.Validator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validators[height] = validators
+}
+
+// SetCallHook sets the hook called before every call.
+func (c *FakeClient) SetCallHook(fn CallHookFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callHook = fn
+}
+
+// SetBroadcastFn sets the outcome of the broadcasted txs. By default, every tx is accepted to the mempool.
+func (c *FakeClient) SetBroadcastFn(fn BroadcastFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.broadcastFn = fn
+}
+
+// SetTxSearchFn sets the handler of the tx search. By default, no tx is found.
+func (c *FakeClient) SetTxSearchFn(fn TxSearchFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.txSearchFn = fn
+}
+
+// HandleQuery sets the handler of the abci query of the path, which is the full method name of the grpc query.
+func (c *FakeClient) HandleQuery(path string, fn QueryFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queryFns[path] = fn
+}
+
+// LandTx includes the tx of the hash in the latest block, so the tx query finds it.
+func (c *FakeClient) LandTx(txHash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	txHash = strings.ToUpper(txHash)
+	hash, _ := hex.DecodeString(txHash)
+	c.landed[txHash] = &coretypes.ResultTx{Hash: hash, Height: c.latestHeight}
+}
+
+// Broadcasted returns the txs broadcasted so far.
+func (c *FakeClient) Broadcasted() []comettypes.Tx {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]comettypes.Tx(nil), c.broadcasted...)
+}
+
+// Calls returns the number of the calls of the method.
+func (c *FakeClient) Calls(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+// call records the call of the method and runs the call hook out of the lock, so the hook can program the fake.
+func (c *FakeClient) call(ctx context.Context, method string) error {
+	c.mu.Lock()
+	c.calls[method]++
+	hook := c.callHook
+	c.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	} else if hook != nil {
+		return hook(ctx, method)
+	}
+	return nil
+}
+
+// height returns the given height or the latest height if it is nil, and checks the block of the height exists.
+func (c *FakeClient) height(height *int64) (int64, error) {
+	h := c.latestHeight
+	if height != nil && *height != 0 {
+		h = *height
+	}
+	if h > c.latestHeight {
+		return 0, fmt.Errorf("height %d must be less than or equal to the current blockchain height %d", h, c.latestHeight)
+	}
+	return h, nil
+}
+
+func (c *FakeClient) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	if err := c.call(ctx, MethodStatus); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := &coretypes.ResultStatus{
+		NodeInfo: p2p.DefaultNodeInfo{Network: c.chainID},
+		SyncInfo: coretypes.SyncInfo{
+			LatestBlockHeight: c.latestHeight,
+			CatchingUp:        c.catchingUp,
+		},
+	}
+	if block, ok := c.blocks[c.latestHeight]; ok {
+		status.SyncInfo.LatestBlockHash = block.BlockID.Hash
+		status.SyncInfo.LatestBlockTime = block.Block.Time
+	}
+	return status, nil
+}
+
+func (c *FakeClient) Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error) {
+	if err := c.call(ctx, MethodBlock); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, err := c.height(height)
+	if err != nil {
+		return nil, err
+	}
+	block, ok := c.blocks[h]
+	if !ok {
+		return nil, fmt.Errorf("block not found: height %d", h)
+	}
+	return block, nil
+}
+
+func (c *FakeClient) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
+	if err := c.call(ctx, MethodBlockResults); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, err := c.height(height)
+	if err != nil {
+		return nil, err
+	}
+	blockResults, ok := c.blockResults[h]
+	if !ok {
+		return nil, fmt.Errorf("block results not found: height %d", h)
+	}
+	return blockResults, nil
+}
+
+// Header returns the header of the block of the height. The header of the height without the block
+// has the current time, so the chain of the fake client can be driven by the heights only.
+func (c *FakeClient) Header(ctx context.Context, height *int64) (*coretypes.ResultHeader, error) {
+	if err := c.call(ctx, MethodHeader); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height == nil && c.autoIncrease {
+		c.latestHeight++
+	}
+	h, err := c.height(height)
+	if err != nil {
+		return nil, err
+	}
+	if block, ok := c.blocks[h]; ok {
+		return &coretypes.ResultHeader{Header: &block.Block.Header}, nil
+	}
+	return &coretypes.ResultHeader{Header: &comettypes.Header{ChainID: c.chainID, Height: h, Time: time.Now().UTC()}}, nil
+}
+
+func (c *FakeClient) Validators(ctx context.Context, height *int64, page, perPage *int) (*coretypes.ResultValidators, error) {
+	if err := c.call(ctx, MethodValidators); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, err := c.height(height)
+	if err != nil {
+		return nil, err
+	}
+	validators := c.validators[h]
+
+	p, pp := 1, 30
+	if page != nil {
+		p = *page
+	}
+	if perPage != nil {
+		pp = *perPage
+	}
+	start := min((p-1)*pp, len(validators))
+	end := min(start+pp, len(validators))
+	return &coretypes.ResultValidators{
+		BlockHeight: h,
+		Validators:  validators[start:end],
+		Count:       end - start,
+		Total:       len(validators),
+	}, nil
+}
+
+func (c *FakeClient) BroadcastTxSync(ctx context.Context, tx comettypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	if err := c.call(ctx, MethodBroadcastTxSync); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.broadcasted = append(c.broadcasted, tx)
+	broadcastFn := c.broadcastFn
+	c.mu.Unlock()
+
+	if broadcastFn != nil {
+		return broadcastFn(tx)
+	}
+	return &coretypes.ResultBroadcastTx{Hash: tx.Hash()}, nil
+}
+
+func (c *FakeClient) TxSearch(ctx context.Context, query string, _ bool, page, perPage *int, orderBy string) (*coretypes.ResultTxSearch, error) {
+	if err := c.call(ctx, MethodTxSearch); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	txSearchFn := c.txSearchFn
+	c.mu.Unlock()
+
+	if txSearchFn == nil {
+		return &coretypes.ResultTxSearch{}, nil
+	}
+	p, pp := 1, 30
+	if page != nil {
+		p = *page
+	}
+	if perPage != nil {
+		pp = *perPage
+	}
+	return txSearchFn(query, p, pp, orderBy)
+}
+
+// QueryABCI runs the handler of the query path. The response of the failed query is returned as the error
+// with the log, as the rpc client does.
+func (c *FakeClient) QueryABCI(ctx context.Context, req abci.RequestQuery) (abci.ResponseQuery, error) {
+	if err := c.call(ctx, MethodABCIQuery); err != nil {
+		return abci.ResponseQuery{}, err
+	}
+
+	c.mu.Lock()
+	queryFn, ok := c.queryFns[req.Path]
+	latestHeight := c.latestHeight
+	c.mu.Unlock()
+
+	if !ok {
+		return abci.ResponseQuery{}, fmt.Errorf("unknown query path: %s", req.Path)
+	}
+	res, err := queryFn(req)
+	if err != nil {
+		return abci.ResponseQuery{}, err
+	} else if !res.IsOK() {
+		return abci.ResponseQuery{}, rpcclient.NewABCIQueryError(res)
+	}
+	if res.Height == 0 {
+		res.Height = latestHeight
+		if req.Height != 0 {
+			res.Height = req.Height
+		}
+	}
+	return res, nil
+}
+
+// QueryTx returns the landed tx of the hash, or the error of the tx not found as the rpc node does.
+func (c *FakeClient) QueryTx(ctx context.Context, txHash []byte) (*coretypes.ResultTx, error) {
+	if err := c.call(ctx, MethodTx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hash := fmt.Sprintf("%X", txHash)
+	res, ok := c.landed[hash]
+	if !ok {
+		return nil, fmt.Errorf("RPC error -32603 - Internal error: tx (%s) not found", hash)
+	}
+	return res, nil
+}
+
+// QueryBlockBulk returns the proto encoded blocks of the range.
+func (c *FakeClient) QueryBlockBulk(ctx context.Context, start int64, end int64) ([][]byte, error) {
+	if err := c.call(ctx, MethodBlockBulk); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if end > c.latestHeight {
+		return nil, fmt.Errorf("height %d must be less than or equal to the current blockchain height %d", end, c.latestHeight)
+	}
+	blocks := make([][]byte, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		block, ok := c.blocks[height]
+		if !ok {
+			return nil, fmt.Errorf("block not found: height %d", height)
+		}
+		pb, err := block.Block.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		bz, err := pb.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, bz)
+	}
+	return blocks, nil
+}
+
+func (c *FakeClient) QueryBlockResults(ctx context.Context, height int64) (*coretypes.ResultBlockResults, error) {
+	return c.BlockResults(ctx, &height)
+}
+
+// QueryRawCommit returns the proto encoded commit of the block of the height.
+func (c *FakeClient) QueryRawCommit(ctx context.Context, height int64) ([]byte, error) {
+	if err := c.call(ctx, MethodCommit); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	block, ok := c.blocks[height]
+	if !ok {
+		return nil, fmt.Errorf("commit not found: height %d", height)
+	}
+	commit := &comettypes.Commit{Height: height, BlockID: block.BlockID}
+	return commit.ToProto().Marshal()
+}
+
+// Invoke implements the grpc ClientConn.Invoke method by the abci query of the method, as the rpc client does.
+func (c *FakeClient) Invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) error {
+	if reflect.ValueOf(req).IsNil() {
+		return errors.New("request cannot be nil")
+	}
+	reqBz, err := protoCodec.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	height, err := rpcclient.GetHeightFromMetadata(md)
+	if err != nil {
+		return err
+	}
+	res, err := c.QueryABCI(ctx, abci.RequestQuery{Path: method, Data: reqBz, Height: height})
+	if err != nil {
+		return err
+	}
+	if err := protoCodec.Unmarshal(res.Value, reply); err != nil {
+		return err
+	}
+
+	for _, opt := range opts {
+		if header, ok := opt.(grpc.HeaderCallOption); ok {
+			*header.HeaderAddr = metadata.Pairs(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(res.Height, 10))
+		}
+	}
+	return nil
+}
+
+// NewStream implements the grpc ClientConn.NewStream method
+func (c *FakeClient) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("streaming rpc not supported")
+}
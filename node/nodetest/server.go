@@ -0,0 +1,130 @@
+package nodetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	clienthttp "github.com/initia-labs/opinit-bots/client"
+	"github.com/initia-labs/opinit-bots/node/rpcclient"
+)
+
+// NewServer serves the status, the blocks, the block results, the headers, the block bulks, the tx searches and the abci queries
+// of the fake client through the json rpc, for the tests of the rpc client itself such as the timeouts, and of
+// the bots connected to the rpc address. The call hook of the fake client runs on the context of the request.
+func NewServer(t testing.TB, c *FakeClient) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage            `json:"id"`
+			Method string                     `json:"method"`
+			Params map[string]json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		param := func(name string) *int64 {
+			value, err := strconv.ParseInt(strings.Trim(string(req.Params[name]), `"`), 10, 64)
+			if err != nil {
+				return nil
+			}
+			return &value
+		}
+
+		var result any
+		var err error
+		switch req.Method {
+		case MethodStatus:
+			result, err = c.Status(r.Context())
+		case MethodBlock:
+			result, err = c.Block(r.Context(), param("height"))
+		case MethodBlockResults:
+			result, err = c.BlockResults(r.Context(), param("height"))
+		case MethodHeader:
+			result, err = c.Header(r.Context(), param("height"))
+		case MethodBlockBulk:
+			var blocks [][]byte
+			blocks, err = c.QueryBlockBulk(r.Context(), *param("start"), *param("end"))
+			result = &clienthttp.ResultBlockBulk{Blocks: blocks}
+		case MethodTxSearch:
+			result, err = serveTxSearch(r.Context(), c, req.Params, param("page"), param("per_page"))
+		case MethodABCIQuery:
+			result, err = serveABCIQuery(r.Context(), c, req.Params, param("height"))
+		default:
+			http.Error(w, "unknown method "+req.Method, http.StatusNotFound)
+			return
+		}
+
+		res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		if err != nil {
+			res["error"] = map[string]any{"code": -32603, "message": "Internal error", "data": err.Error()}
+		} else {
+			bz, err := cmtjson.Marshal(result)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			res["result"] = json.RawMessage(bz)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// serveTxSearch runs the tx search of the query in the params on the fake client.
+func serveTxSearch(ctx context.Context, c *FakeClient, params map[string]json.RawMessage, page, perPage *int64) (*coretypes.ResultTxSearch, error) {
+	var query, orderBy string
+	if err := json.Unmarshal(params["query"], &query); err != nil {
+		return nil, err
+	}
+	if raw, ok := params["order_by"]; ok {
+		if err := json.Unmarshal(raw, &orderBy); err != nil {
+			return nil, err
+		}
+	}
+	toInt := func(value *int64) *int {
+		if value == nil {
+			return nil
+		}
+		v := int(*value)
+		return &v
+	}
+	return c.TxSearch(ctx, query, false, toInt(page), toInt(perPage), orderBy)
+}
+
+// serveABCIQuery runs the abci query of the params on the fake client. The failed query is answered with
+// the code and the log of the response, as the rpc node does.
+func serveABCIQuery(ctx context.Context, c *FakeClient, params map[string]json.RawMessage, height *int64) (*coretypes.ResultABCIQuery, error) {
+	var path string
+	if err := json.Unmarshal(params["path"], &path); err != nil {
+		return nil, err
+	}
+	var data cmtbytes.HexBytes
+	if err := data.UnmarshalJSON(params["data"]); err != nil {
+		return nil, err
+	}
+	req := abci.RequestQuery{Path: path, Data: data}
+	if height != nil {
+		req.Height = *height
+	}
+
+	res, err := c.QueryABCI(ctx, req)
+	var queryErr rpcclient.ABCIQueryError
+	if errors.As(err, &queryErr) {
+		res = abci.ResponseQuery{Codespace: queryErr.Codespace, Code: queryErr.Code, Log: queryErr.Log}
+	} else if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultABCIQuery{Response: res}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 	"github.com/initia-labs/opinit-bots/types"
@@ -13,65 +14,202 @@ import (
 	"go.uber.org/zap"
 )
 
+// blockProcessLooperWithRestart runs the block process looper and re-enters it
+// with exponential backoff after transient errors. Fatal errors are returned.
+func (n *Node) blockProcessLooperWithRestart(ctx context.Context, processType nodetypes.BlockProcessType) error {
+	restarts := 0
+	for {
+		startHeight := n.lastProcessedBlockHeight
+		err := n.blockProcessLooper(ctx, processType)
+		n.status.setError(err)
+		if !nodetypes.IsTransientError(err) {
+			return err
+		}
+
+		// reset the restart count if the looper made progress
+		if n.lastProcessedBlockHeight > startHeight {
+			restarts = 0
+		}
+		restarts++
+
+		if n.cfg.RestartPolicy.MaxRestarts > 0 && restarts > n.cfg.RestartPolicy.MaxRestarts {
+			return errors.Wrap(err, "exceeded max restarts of block process looper")
+		}
+
+		failingHeight := n.lastProcessedBlockHeight + 1
+		var handlerErr *nodetypes.HandlerFailureError
+		if errors.As(err, &handlerErr) {
+			failingHeight = handlerErr.Height
+		}
+
+		backoff := n.cfg.RestartPolicy.Backoff(restarts)
+		n.logger.Debug("restart block process looper",
+			zap.Int64("height", failingHeight),
+			zap.Int("attempt", restarts),
+			zap.Duration("backoff", backoff),
+			zap.String("error", err.Error()),
+		)
+		if n.restartHandler != nil {
+			n.restartHandler(ctx, nodetypes.RestartArgs{
+				Height:  failingHeight,
+				Attempt: restarts,
+				Backoff: backoff,
+				Err:     err,
+			})
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-types.Shutdown(ctx):
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
 // blockProcessLooper fetches new blocks and processes them
 func (n *Node) blockProcessLooper(ctx context.Context, processType nodetypes.BlockProcessType) error {
-	timer := time.NewTicker(types.PollingInterval(ctx))
-	defer timer.Stop()
+	pollingInterval, maxPollingInterval := n.pollingIntervals(ctx)
+	interval := pollingInterval
 
 	consecutiveErrors := 0
 	for {
+		timer := time.NewTimer(interval)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-types.Shutdown(ctx):
+			// stop the block ingestion; the handlers of the last block are already completed
+			timer.Stop()
 			return nil
 		case <-timer.C:
-			if types.SleepWithRetry(ctx, consecutiveErrors) {
+			if types.SleepWithRetry(ctx, consecutiveErrors) || types.ShuttingDown(ctx) {
 				return nil
 			}
 			consecutiveErrors++
 		}
 
+		if _, err := n.applyRewind(ctx); err != nil {
+			return err
+		}
+		n.applyReplay(ctx)
+
+		rpcStart := time.Now()
 		status, err := n.rpcClient.Status(ctx)
+		n.observeRPC("status", rpcStart)
 		if err != nil {
 			n.logger.Error("failed to get node status ", zap.String("error", err.Error()))
+			n.status.setError(err)
 			continue
 		}
 
 		latestChainHeight := status.SyncInfo.LatestBlockHeight
+		n.metrics.LatestChainHeight.WithLabelValues(n.logger.Name()).Set(float64(latestChainHeight))
+		n.status.setLatestChainHeight(latestChainHeight)
 		if n.lastProcessedBlockHeight >= latestChainHeight {
+			// back off while the chain has no new block
+			consecutiveErrors = 0
+			interval = nextPollingInterval(interval, maxPollingInterval)
+			n.logger.Debug("no new block", zap.Int64("height", latestChainHeight), zap.Duration("polling_interval", interval))
 			continue
+		} else if interval != pollingInterval {
+			interval = pollingInterval
+			n.logger.Debug("new block", zap.Int64("height", latestChainHeight), zap.Duration("polling_interval", interval))
 		}
 
 		switch processType {
 		case nodetypes.PROCESS_TYPE_DEFAULT:
+			blockRetries := 0
 			for queryHeight := n.lastProcessedBlockHeight + 1; queryHeight <= latestChainHeight; {
-				select {
-				case <-ctx.Done():
+				// fetch the blocks without waiting while the node is behind
+				if ctx.Err() != nil || types.ShuttingDown(ctx) {
 					return nil
-				case <-timer.C:
 				}
+				if rewound, err := n.applyRewind(ctx); err != nil {
+					return err
+				} else if rewound {
+					queryHeight = n.lastProcessedBlockHeight + 1
+					blockRetries = 0
+					continue
+				}
+				n.applyReplay(ctx)
+				if n.isSkipHeight(queryHeight) {
+					n.logger.Error("skip block by config; handlers are not called", zap.Int64("height", queryHeight))
+					if err := n.skipBlock(ctx, queryHeight); err != nil {
+						n.logger.Error("failed to skip block", zap.Int64("height", queryHeight), zap.String("error", err.Error()))
+						return err
+					}
+					n.setLastProcessedBlockHeight(queryHeight)
+					queryHeight++
+					continue
+				}
+
 				// TODO: may fetch blocks in batch
 				block, blockResult, err := n.fetchNewBlock(ctx, queryHeight)
 				if err != nil {
-					// TODO: handle error
-					n.logger.Error("failed to fetch new block", zap.String("error", err.Error()))
-					break
+					n.logger.Error("failed to fetch new block", zap.Int64("height", queryHeight), zap.String("error", err.Error()))
+					return fmt.Errorf("%w: failed to fetch new block: height: %d; %w", nodetypes.ErrTransientRPC, queryHeight, err)
+				}
+
+				if err := n.checkParentHash(ctx, queryHeight, block.Block.LastBlockID.Hash); err != nil {
+					return err
 				}
 
 				err = n.handleNewBlock(ctx, block, blockResult, latestChainHeight)
-				if err != nil {
+				if errors.Is(err, nodetypes.ErrIgnoreAndTryLater) {
 					n.logger.Error("failed to handle new block", zap.String("error", err.Error()))
-					if errors.Is(err, nodetypes.ErrIgnoreAndTryLater) {
-						sleep := time.NewTimer(time.Minute)
-						select {
-						case <-ctx.Done():
-							return nil
-						case <-sleep.C:
-						}
+					sleep := time.NewTimer(time.Minute)
+					select {
+					case <-ctx.Done():
+						return nil
+					case <-types.Shutdown(ctx):
+						sleep.Stop()
+						return nil
+					case <-sleep.C:
 					}
 					break
+				} else if errors.Is(err, nodetypes.ErrRetryBlock) {
+					// the handler vetoed the height advancement, re-process the same height
+					blockRetries++
+					if blockRetries > n.cfg.RestartPolicy.GetMaxBlockRetries() {
+						n.logger.Error("failed to handle new block after retries", zap.Int64("height", queryHeight), zap.Int("retries", blockRetries-1), zap.String("error", err.Error()))
+						return fmt.Errorf("failed to handle new block after %d retries: height: %d; %w", blockRetries-1, queryHeight, err)
+					}
+
+					backoff := n.cfg.RestartPolicy.Backoff(blockRetries)
+					n.logger.Warn("retry block", zap.Int64("height", queryHeight), zap.Int("retry", blockRetries), zap.Duration("backoff", backoff), zap.String("error", err.Error()))
+					sleep := time.NewTimer(backoff)
+					select {
+					case <-ctx.Done():
+						sleep.Stop()
+						return nil
+					case <-types.Shutdown(ctx):
+						sleep.Stop()
+						return nil
+					case <-sleep.C:
+					}
+					continue
+				} else if errors.Is(err, nodetypes.ErrHandlerPanic) && n.cfg.HandlerPanicPolicy == nodetypes.HANDLER_PANIC_POLICY_SKIP {
+					n.logger.Warn("skip block after handler panic", zap.Int64("height", queryHeight), zap.String("error", err.Error()))
+					if err := n.skipBlock(ctx, queryHeight); err != nil {
+						n.logger.Error("failed to skip block", zap.Int64("height", queryHeight), zap.String("error", err.Error()))
+						return err
+					}
+				} else if err != nil {
+					n.logger.Error("failed to handle new block", zap.Int64("height", queryHeight), zap.String("error", err.Error()))
+					return err
+				}
+				if err := n.saveBlockHash(queryHeight, block.BlockID.Hash); err != nil {
+					return err
 				}
-				n.lastProcessedBlockHeight = queryHeight
+				n.setLastProcessedBlockHeight(queryHeight)
 				queryHeight++
+				blockRetries = 0
 			}
 
 		case nodetypes.PROCESS_TYPE_RAW:
@@ -81,30 +219,48 @@ func (n *Node) blockProcessLooper(ctx context.Context, processType nodetypes.Blo
 				end = latestChainHeight
 			}
 
+			rpcStart := time.Now()
 			blockBulk, err := n.rpcClient.QueryBlockBulk(ctx, start, end)
+			n.observeRPC("block_bulk", rpcStart)
 			if err != nil {
 				n.logger.Error("failed to fetch block bulk", zap.String("error", err.Error()))
-				continue
+				return fmt.Errorf("%w: failed to fetch block bulk: start: %d, end: %d; %w", nodetypes.ErrTransientRPC, start, end, err)
 			}
 
 			for i := start; i <= end; i++ {
 				select {
 				case <-ctx.Done():
 					return nil
+				case <-types.Shutdown(ctx):
+					return nil
 				default:
 				}
-				err := n.rawBlockHandler(ctx, nodetypes.RawBlockArgs{
-					BlockHeight:  i,
-					LatestHeight: latestChainHeight,
-					BlockBytes:   blockBulk[i-start],
+				n.applyReplay(ctx)
+
+				args, header, err := decodeRawBlock(i, latestChainHeight, blockBulk[i-start])
+				if err != nil {
+					n.logger.Error("failed to decode raw block", zap.Int64("height", i), zap.String("error", err.Error()))
+					return err
+				}
+				blockHash, parentHash := rawBlockHashes(header)
+				if err := n.checkParentHash(ctx, i, parentHash); err != nil {
+					return err
+				}
+				// the raw block handler always halts on a panic, as skipping the block loses the block data
+				err = n.callHandler(i, -1, "raw_block", func() error {
+					return n.rawBlockHandler(ctx, args)
 				})
 				if err != nil {
-					n.logger.Error("failed to handle raw block", zap.String("error", err.Error()))
-					break
+					n.logger.Error("failed to handle raw block", zap.Int64("height", i), zap.String("error", err.Error()))
+					return wrapHandlerFailure(i, "raw_block", err)
+				}
+				if err := n.saveBlockHash(i, blockHash); err != nil {
+					return err
 				}
-				n.lastProcessedBlockHeight = i
+				n.setLastProcessedBlockHeight(i)
 			}
 		}
+		n.metrics.LastProcessedHeight.WithLabelValues(n.logger.Name()).Set(float64(n.lastProcessedBlockHeight))
 		consecutiveErrors = 0
 	}
 }
@@ -112,13 +268,17 @@ func (n *Node) blockProcessLooper(ctx context.Context, processType nodetypes.Blo
 // fetch new block from the chain
 func (n *Node) fetchNewBlock(ctx context.Context, height int64) (block *rpccoretypes.ResultBlock, blockResult *rpccoretypes.ResultBlockResults, err error) {
 	n.logger.Debug("fetch new block", zap.Int64("height", height))
+	start := time.Now()
 	block, err = n.rpcClient.Block(ctx, &height)
+	n.observeRPC("block", start)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if len(n.eventHandlers) != 0 {
+		start = time.Now()
 		blockResult, err = n.rpcClient.BlockResults(ctx, &height)
+		n.observeRPC("block_results", start)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -132,38 +292,78 @@ func (n *Node) handleNewBlock(ctx context.Context, block *rpccoretypes.ResultBlo
 		return err
 	}
 
+	// raw block handler is called before the other handlers of the same height
+	if n.rawBlockHandler != nil {
+		blockBytes, err := protoBlock.Marshal()
+		if err != nil {
+			return err
+		}
+		err = n.callHandler(block.Block.Height, -1, "raw_block", func() error {
+			return n.rawBlockHandler(ctx, nodetypes.RawBlockArgs{
+				BlockHeight:  block.Block.Height,
+				BlockTime:    block.Block.Time,
+				LatestHeight: latestChainHeight,
+				BlockBytes:   blockBytes,
+				Txs:          protoBlock.Data.Txs,
+			})
+		})
+		if err != nil {
+			return wrapHandlerFailure(block.Block.Height, "raw_block", fmt.Errorf("failed to handle raw block; %w", err))
+		}
+	}
+
 	if n.beginBlockHandler != nil {
-		err := n.beginBlockHandler(ctx, nodetypes.BeginBlockArgs{
-			BlockID:      block.BlockID.Hash,
-			Block:        *protoBlock,
-			LatestHeight: latestChainHeight,
+		err := n.callHandler(block.Block.Height, -1, "begin_block", func() error {
+			return n.beginBlockHandler(ctx, nodetypes.BeginBlockArgs{
+				BlockID:      block.BlockID.Hash,
+				Block:        *protoBlock,
+				LatestHeight: latestChainHeight,
+			})
 		})
 		if err != nil {
-			return err
+			return wrapHandlerFailure(block.Block.Height, "begin_block", err)
 		}
 	}
 
 	for txIndex, tx := range block.Block.Txs {
 		if n.txHandler != nil {
-			err := n.txHandler(ctx, nodetypes.TxHandlerArgs{
-				BlockHeight:  block.Block.Height,
-				BlockTime:    block.Block.Time,
-				LatestHeight: latestChainHeight,
-				TxIndex:      int64(txIndex),
-				Tx:           tx,
-				Success:      blockResult.TxsResults[txIndex].Code == abcitypes.CodeTypeOK,
+			err := n.callHandler(block.Block.Height, int64(txIndex), "tx", func() error {
+				return n.txHandler(ctx, nodetypes.TxHandlerArgs{
+					BlockHeight:  block.Block.Height,
+					BlockTime:    block.Block.Time,
+					LatestHeight: latestChainHeight,
+					TxIndex:      int64(txIndex),
+					Tx:           tx,
+					Success:      blockResult.TxsResults[txIndex].Code == abcitypes.CodeTypeOK,
+				})
 			})
 			if err != nil {
-				return fmt.Errorf("failed to handle tx: tx_index: %d; %w", txIndex, err)
+				return wrapHandlerFailure(block.Block.Height, "tx", fmt.Errorf("failed to handle tx: tx_index: %d; %w", txIndex, err))
 			}
 		}
 
 		if len(n.eventHandlers) != 0 {
 			events := blockResult.TxsResults[txIndex].GetEvents()
+			eventKeys := make(map[string]struct{})
 			for eventIndex, event := range events {
-				err := n.handleEvent(ctx, block.Block.Height, block.Block.Time, latestChainHeight, event)
+				duplicated, err := n.isDuplicatedEvent(eventKeys, event)
 				if err != nil {
-					return fmt.Errorf("failed to handle event: tx_index: %d, event_index: %d; %w", txIndex, eventIndex, err)
+					return wrapHandlerFailure(block.Block.Height, event.GetType(), fmt.Errorf("failed to get event key: tx_index: %d, event_index: %d; %w", txIndex, eventIndex, err))
+				} else if duplicated {
+					n.logger.Warn("drop duplicated event",
+						zap.Int64("height", block.Block.Height),
+						zap.String("tx_hash", fmt.Sprintf("%X", tx.Hash())),
+						zap.Int("event_index", eventIndex),
+						zap.String("event_type", event.GetType()),
+					)
+					continue
+				}
+
+				err = n.callHandler(block.Block.Height, int64(txIndex), event.GetType(), func() error {
+					return n.handleEvent(ctx, block.Block.Height, block.Block.Time, latestChainHeight, event)
+				})
+				if err != nil {
+					return wrapHandlerFailure(block.Block.Height, event.GetType(), fmt.Errorf("failed to handle event: tx_index: %d, event_index: %d; %w", txIndex, eventIndex, err))
 				}
 			}
 		}
@@ -171,26 +371,156 @@ func (n *Node) handleNewBlock(ctx context.Context, block *rpccoretypes.ResultBlo
 
 	if len(n.eventHandlers) != 0 {
 		for eventIndex, event := range blockResult.FinalizeBlockEvents {
-			err := n.handleEvent(ctx, block.Block.Height, block.Block.Time, latestChainHeight, event)
+			err := n.callHandler(block.Block.Height, -1, event.GetType(), func() error {
+				return n.handleEvent(ctx, block.Block.Height, block.Block.Time, latestChainHeight, event)
+			})
 			if err != nil {
-				return fmt.Errorf("failed to handle event: finalize block, event_index: %d; %w", eventIndex, err)
+				return wrapHandlerFailure(block.Block.Height, event.GetType(), fmt.Errorf("failed to handle event: finalize block, event_index: %d; %w", eventIndex, err))
 			}
 		}
 	}
 
 	if n.endBlockHandler != nil {
-		err := n.endBlockHandler(ctx, nodetypes.EndBlockArgs{
-			BlockID:      block.BlockID.Hash,
-			Block:        *protoBlock,
-			LatestHeight: latestChainHeight,
+		err := n.callHandler(block.Block.Height, -1, "end_block", func() error {
+			return n.endBlockHandler(ctx, nodetypes.EndBlockArgs{
+				BlockID:      block.BlockID.Hash,
+				Block:        *protoBlock,
+				LatestHeight: latestChainHeight,
+			})
 		})
 		if err != nil {
-			return fmt.Errorf("failed to handle end block; %w", err)
+			return wrapHandlerFailure(block.Block.Height, "end_block", fmt.Errorf("failed to handle end block; %w", err))
 		}
 	}
 	return nil
 }
 
+// isDuplicatedEvent returns true if the key of the event is already in the event keys,
+// otherwise it adds the key to the event keys. The events without the key fn are never duplicated.
+func (n Node) isDuplicatedEvent(eventKeys map[string]struct{}, event abcitypes.Event) (bool, error) {
+	keyFn, ok := n.eventKeyFns[event.GetType()]
+	if !ok {
+		return false, nil
+	}
+
+	key, err := keyFn(event.GetAttributes())
+	if err != nil {
+		return false, err
+	}
+
+	key = event.GetType() + "/" + key
+	if _, ok := eventKeys[key]; ok {
+		return true, nil
+	}
+	eventKeys[key] = struct{}{}
+	return false, nil
+}
+
+// pollingIntervals returns the base and the max polling intervals.
+func (n Node) pollingIntervals(ctx context.Context) (time.Duration, time.Duration) {
+	pollingInterval := n.cfg.PollingInterval
+	if pollingInterval == 0 {
+		pollingInterval = types.PollingInterval(ctx)
+	}
+
+	maxPollingInterval := n.cfg.MaxPollingInterval
+	if maxPollingInterval == 0 {
+		maxPollingInterval = nodetypes.DefaultMaxPollingInterval
+	}
+	if maxPollingInterval < pollingInterval {
+		maxPollingInterval = pollingInterval
+	}
+	return pollingInterval, maxPollingInterval
+}
+
+// nextPollingInterval doubles the polling interval up to the max.
+func nextPollingInterval(interval time.Duration, maxInterval time.Duration) time.Duration {
+	interval *= 2
+	if interval > maxInterval {
+		return maxInterval
+	}
+	return interval
+}
+
+// observeRPC records the latency of the rpc call started at `start`.
+func (n *Node) observeRPC(method string, start time.Time) {
+	n.metrics.RPCLatency.WithLabelValues(n.logger.Name(), method).Observe(time.Since(start).Seconds())
+}
+
+// newRawBlockArgs decodes the raw block bytes to fill the block time and txs.
+func newRawBlockArgs(height int64, latestHeight int64, blockBytes []byte) (nodetypes.RawBlockArgs, error) {
+	args, _, err := decodeRawBlock(height, latestHeight, blockBytes)
+	return args, err
+}
+
+// decodeRawBlock decodes the raw block bytes to the raw block args and the header of the block.
+func decodeRawBlock(height int64, latestHeight int64, blockBytes []byte) (nodetypes.RawBlockArgs, *cmtproto.Header, error) {
+	pbb := new(cmtproto.Block)
+	err := pbb.Unmarshal(blockBytes)
+	if err != nil {
+		return nodetypes.RawBlockArgs{}, nil, fmt.Errorf("failed to unmarshal block: height: %d; %w", height, err)
+	}
+
+	return nodetypes.RawBlockArgs{
+		BlockHeight:  height,
+		BlockTime:    pbb.Header.Time,
+		LatestHeight: latestHeight,
+		BlockBytes:   blockBytes,
+		Txs:          pbb.Data.Txs,
+	}, &pbb.Header, nil
+}
+
+// skipBlock calls the skip block handler for the height skipped by the config or after a handler panic. The panic of the handler halts the node,
+// as the state of the height is not carried forward.
+func (n *Node) skipBlock(ctx context.Context, height int64) error {
+	if n.skipBlockHandler == nil {
+		return nil
+	}
+	err := n.callHandler(height, -1, "skip_block", func() error {
+		return n.skipBlockHandler(ctx, nodetypes.SkipBlockArgs{BlockHeight: height})
+	})
+	if err != nil {
+		return wrapHandlerFailure(height, "skip_block", err)
+	}
+	return nil
+}
+
+// callHandler calls the handler and converts a panic inside it
+// to HandlerPanicError annotated with the block context.
+func (n *Node) callHandler(height int64, txIndex int64, eventType string, fn func() error) (err error) {
+	start := time.Now()
+	defer func() {
+		n.metrics.HandlerDuration.WithLabelValues(n.logger.Name(), eventType).Observe(time.Since(start).Seconds())
+		if r := recover(); r != nil {
+			n.logger.Error("handler panic",
+				zap.String("chain_id", n.cfg.ChainID),
+				zap.Int64("height", height),
+				zap.Int64("tx_index", txIndex),
+				zap.String("event_type", eventType),
+				zap.Any("recover", r),
+				zap.Stack("stack"),
+			)
+			err = &nodetypes.HandlerPanicError{
+				ChainID:   n.cfg.ChainID,
+				Height:    height,
+				TxIndex:   txIndex,
+				EventType: eventType,
+				Recovered: r,
+			}
+		}
+	}()
+	return fn()
+}
+
+// wrapHandlerFailure wraps the handler error with the originating height and event.
+// ErrIgnoreAndTryLater, ErrRetryBlock and ErrHandlerPanic are returned as they are to be handled by the looper.
+func wrapHandlerFailure(height int64, event string, err error) error {
+	if errors.Is(err, nodetypes.ErrIgnoreAndTryLater) || errors.Is(err, nodetypes.ErrRetryBlock) || errors.Is(err, nodetypes.ErrHandlerPanic) {
+		return err
+	}
+	return nodetypes.NewHandlerFailureError(height, event, err)
+}
+
 func (n *Node) handleEvent(ctx context.Context, blockHeight int64, blockTime time.Time, latestHeight int64, event abcitypes.Event) error {
 	if n.eventHandlers[event.GetType()] == nil {
 		return nil
@@ -245,6 +575,14 @@ func (n *Node) txChecker(ctx context.Context, enableEventHandler bool) error {
 		if errors.Is(err, types.ErrTxNotFound) {
 			// tx not found
 			continue
+		} else if errors.Is(err, types.ErrTxExpired) {
+			// tx expired without being included
+			err = n.broadcaster.ResubmitPendingTx(pendingTx)
+			if err != nil {
+				return errors.Wrap(err, "failed to resubmit expired pending tx")
+			}
+			consecutiveErrors = 0
+			continue
 		} else if err != nil {
 			return errors.Wrap(err, "failed to check pending tx")
 		} else if res != nil {
@@ -262,11 +600,27 @@ func (n *Node) txChecker(ctx context.Context, enableEventHandler bool) error {
 
 					err := n.handleEvent(ctx, res.Height, blockTime, 0, event)
 					if err != nil {
-						n.logger.Error("failed to handle event", zap.String("tx_hash", pendingTx.TxHash), zap.Int("event_index", eventIndex), zap.String("error", err.Error()))
+						n.logger.Error("failed to handle event", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash), zap.Int("event_index", eventIndex), zap.String("error", err.Error()))
 						break
 					}
 				}
 			}
+
+			for _, txConfirmedHandler := range n.txConfirmedHandlers {
+				err := txConfirmedHandler(ctx, nodetypes.TxConfirmedArgs{
+					BlockHeight: res.Height,
+					BlockTime:   blockTime,
+					TxHash:      pendingTx.TxHash,
+					GasUsed:     res.TxResult.GasUsed,
+					TraceID:     pendingTx.TraceID,
+					Sender:      pendingTx.Sender,
+					MsgTypes:    pendingTx.MsgTypes,
+					Events:      res.TxResult.GetEvents(),
+				})
+				if err != nil {
+					n.logger.Error("failed to handle confirmed tx", zap.String("trace_id", pendingTx.TraceID), zap.String("tx_hash", pendingTx.TxHash), zap.String("error", err.Error()))
+				}
+			}
 		}
 
 		err = n.broadcaster.RemovePendingTx(pendingTx)
@@ -276,6 +630,7 @@ func (n *Node) txChecker(ctx context.Context, enableEventHandler bool) error {
 		n.logger.Info("tx inserted",
 			zap.Int64("height", height),
 			zap.Uint64("sequence", pendingTx.Sequence),
+			zap.String("trace_id", pendingTx.TraceID),
 			zap.String("tx_hash", pendingTx.TxHash),
 			zap.Strings("msg_types", pendingTx.MsgTypes),
 			zap.Int("pending_txs", n.broadcaster.LenLocalPendingTx()),
@@ -0,0 +1,569 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+	comettypes "github.com/cometbft/cometbft/types"
+
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/keys"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// newTestNode creates the node on the fake chain.
+func newTestNode(t *testing.T, chain *nodetest.FakeClient) *Node {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return newTestNodeWithDB(t, chain, db)
+}
+
+func newTestNodeWithDB(t *testing.T, chain *nodetest.FakeClient, db types.DB) *Node {
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces})
+	require.NoError(t, err)
+
+	n, err := NewNodeWithRPCClient(nodetypes.NodeConfig{
+		RPC:          "tcp://localhost:26657",
+		ChainID:      "test-1",
+		ProcessType:  nodetypes.PROCESS_TYPE_DEFAULT,
+		Bech32Prefix: "init",
+	}, db, zap.NewNop(), cdc, txConfig, chain)
+	require.NoError(t, err)
+	return n
+}
+
+func Test_HandlerPanic(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+	n.RegisterEventHandler("transfer", func(_ context.Context, _ nodetypes.EventHandlerArgs) error {
+		panic("unexpected event")
+	})
+
+	block := &rpccoretypes.ResultBlock{
+		Block: &comettypes.Block{
+			Header: comettypes.Header{ChainID: "test-1", Height: 10, Time: time.Now().UTC()},
+			Data:   comettypes.Data{Txs: comettypes.Txs{[]byte("tx0"), []byte("tx1")}},
+		},
+	}
+	blockResult := &rpccoretypes.ResultBlockResults{
+		Height: 10,
+		TxsResults: []*abcitypes.ExecTxResult{
+			{},
+			{Events: []abcitypes.Event{{Type: "transfer"}}},
+		},
+	}
+
+	err := n.handleNewBlock(context.Background(), block, blockResult, 10)
+	require.ErrorIs(t, err, nodetypes.ErrHandlerPanic)
+	require.False(t, nodetypes.IsTransientError(err))
+
+	var panicErr *nodetypes.HandlerPanicError
+	require.True(t, errors.As(err, &panicErr))
+	require.Equal(t, "test-1", panicErr.ChainID)
+	require.Equal(t, int64(10), panicErr.Height)
+	require.Equal(t, int64(1), panicErr.TxIndex)
+	require.Equal(t, "transfer", panicErr.EventType)
+	require.Equal(t, "unexpected event", panicErr.Recovered)
+
+	// the block is not processed
+	require.Equal(t, int64(0), n.lastProcessedBlockHeight)
+}
+
+// newTestBlockChain returns the fake chain whose latest block is the given block.
+func newTestBlockChain(block *rpccoretypes.ResultBlock, blockResult *rpccoretypes.ResultBlockResults) *nodetest.FakeClient {
+	chain := nodetest.NewFakeClient("test-1")
+	chain.AddBlock(block, blockResult)
+	return chain
+}
+
+func Test_RawBlockHandler(t *testing.T) {
+	block := &rpccoretypes.ResultBlock{
+		Block: &comettypes.Block{
+			Header: comettypes.Header{ChainID: "test-1", Height: 10, Time: time.Unix(0, 10000).UTC()},
+			Data:   comettypes.Data{Txs: comettypes.Txs{[]byte("tx0"), []byte("tx1")}},
+		},
+	}
+	blockResult := &rpccoretypes.ResultBlockResults{
+		Height: 10,
+		TxsResults: []*abcitypes.ExecTxResult{
+			{},
+			{Events: []abcitypes.Event{{Type: "transfer"}}},
+		},
+	}
+	n := newTestNode(t, newTestBlockChain(block, blockResult))
+
+	var called []string
+	var rawArgs nodetypes.RawBlockArgs
+	require.NoError(t, n.RegisterRawBlockHandler(func(_ context.Context, args nodetypes.RawBlockArgs) error {
+		called = append(called, "raw_block")
+		rawArgs = args
+		return nil
+	}))
+	n.RegisterEventHandler("transfer", func(_ context.Context, _ nodetypes.EventHandlerArgs) error {
+		called = append(called, "transfer")
+		return nil
+	})
+
+	fetchedBlock, fetchedBlockResult, err := n.fetchNewBlock(context.Background(), 10)
+	require.NoError(t, err)
+	err = n.handleNewBlock(context.Background(), fetchedBlock, fetchedBlockResult, 11)
+	require.NoError(t, err)
+
+	// raw block handler runs before the event handlers of the same height
+	require.Equal(t, []string{"raw_block", "transfer"}, called)
+	require.Equal(t, int64(10), rawArgs.BlockHeight)
+	require.Equal(t, int64(11), rawArgs.LatestHeight)
+	require.True(t, block.Block.Time.Equal(rawArgs.BlockTime))
+	require.Equal(t, [][]byte{[]byte("tx0"), []byte("tx1")}, rawArgs.Txs)
+
+	pbb := new(cmtproto.Block)
+	require.NoError(t, pbb.Unmarshal(rawArgs.BlockBytes))
+	require.Equal(t, int64(10), pbb.Header.Height)
+
+	// raw process type decodes the block bulk into the same args
+	blockBulk, err := n.rpcClient.QueryBlockBulk(context.Background(), 10, 10)
+	require.NoError(t, err)
+	bulkArgs, err := newRawBlockArgs(10, 11, blockBulk[0])
+	require.NoError(t, err)
+	require.Equal(t, rawArgs, bulkArgs)
+}
+
+func Test_RawBlockHandlerPanic(t *testing.T) {
+	block := &rpccoretypes.ResultBlock{
+		Block: &comettypes.Block{
+			Header: comettypes.Header{ChainID: "test-1", Height: 10, Time: time.Unix(0, 10000).UTC()},
+		},
+	}
+	n := newTestNode(t, newTestBlockChain(block, &rpccoretypes.ResultBlockResults{Height: 10}))
+	n.cfg.HandlerPanicPolicy = nodetypes.HANDLER_PANIC_POLICY_SKIP
+	n.SetSyncInfo(9)
+	require.NoError(t, n.RegisterRawBlockHandler(func(_ context.Context, _ nodetypes.RawBlockArgs) error {
+		panic("unexpected block")
+	}))
+
+	// the raw block is not skipped even with the skip policy
+	err := n.blockProcessLooper(context.Background(), nodetypes.PROCESS_TYPE_RAW)
+	require.ErrorIs(t, err, nodetypes.ErrHandlerPanic)
+	require.Equal(t, int64(9), n.lastProcessedBlockHeight)
+}
+
+func Test_SkipBlockAfterHandlerPanic(t *testing.T) {
+	chain := nodetest.NewFakeClient("test-1")
+	for range 2 {
+		chain.AppendBlock(comettypes.Txs{[]byte("tx0")}, []*abcitypes.ExecTxResult{{Events: []abcitypes.Event{{Type: "transfer"}}}})
+	}
+	n := newTestNode(t, chain)
+	n.cfg.HandlerPanicPolicy = nodetypes.HANDLER_PANIC_POLICY_SKIP
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var skipped, endBlocks []int64
+	require.NoError(t, n.RegisterEventHandler("transfer", func(_ context.Context, args nodetypes.EventHandlerArgs) error {
+		if args.BlockHeight == 1 {
+			panic("unexpected event")
+		}
+		return nil
+	}))
+	require.NoError(t, n.RegisterSkipBlockHandler(func(_ context.Context, args nodetypes.SkipBlockArgs) error {
+		skipped = append(skipped, args.BlockHeight)
+		return nil
+	}))
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, args nodetypes.EndBlockArgs) error {
+		endBlocks = append(endBlocks, args.Block.Header.Height)
+		cancel()
+		return nil
+	}))
+
+	// the skip block handler carries the state forward to the skipped block, and the next block is processed
+	require.NoError(t, n.blockProcessLooper(ctx, nodetypes.PROCESS_TYPE_DEFAULT))
+	require.Equal(t, []int64{1}, skipped)
+	require.Equal(t, []int64{2}, endBlocks)
+	require.Equal(t, int64(2), n.lastProcessedBlockHeight)
+
+	// the failed skip halts the node at the previous height
+	n = newTestNode(t, chain)
+	n.cfg.HandlerPanicPolicy = nodetypes.HANDLER_PANIC_POLICY_SKIP
+	require.NoError(t, n.RegisterEventHandler("transfer", func(_ context.Context, _ nodetypes.EventHandlerArgs) error {
+		panic("unexpected event")
+	}))
+	require.NoError(t, n.RegisterSkipBlockHandler(func(_ context.Context, _ nodetypes.SkipBlockArgs) error {
+		return errors.New("working tree not found")
+	}))
+	err := n.blockProcessLooper(context.Background(), nodetypes.PROCESS_TYPE_DEFAULT)
+	require.ErrorContains(t, err, "working tree not found")
+	require.Equal(t, int64(0), n.lastProcessedBlockHeight)
+}
+
+func Test_SkipHeights(t *testing.T) {
+	chain := nodetest.NewFakeClient("test-1")
+	chain.AppendBlocks(2)
+	n := newTestNode(t, chain)
+	n.skipHeights[1] = struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var skipped, endBlocks []int64
+	require.NoError(t, n.RegisterSkipBlockHandler(func(_ context.Context, args nodetypes.SkipBlockArgs) error {
+		skipped = append(skipped, args.BlockHeight)
+		return nil
+	}))
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, args nodetypes.EndBlockArgs) error {
+		endBlocks = append(endBlocks, args.Block.Header.Height)
+		cancel()
+		return nil
+	}))
+
+	// the skip block handler is called instead of the other handlers of the skip height
+	require.NoError(t, n.blockProcessLooper(ctx, nodetypes.PROCESS_TYPE_DEFAULT))
+	require.Equal(t, []int64{1}, skipped)
+	require.Equal(t, []int64{2}, endBlocks)
+	require.Equal(t, int64(2), n.lastProcessedBlockHeight)
+
+	// the raw process type doesn't skip the blocks, which are submitted in the batches
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces})
+	require.NoError(t, err)
+	_, err = NewNodeWithRPCClient(nodetypes.NodeConfig{
+		RPC:          "tcp://localhost:26657",
+		ChainID:      "test-1",
+		ProcessType:  nodetypes.PROCESS_TYPE_RAW,
+		Bech32Prefix: "init",
+		SkipHeights:  []int64{1},
+	}, n.db, zap.NewNop(), cdc, txConfig, chain)
+	require.ErrorContains(t, err, "skip heights are not supported")
+}
+
+func Test_ReplayRange(t *testing.T) {
+	block := &rpccoretypes.ResultBlock{
+		Block: &comettypes.Block{
+			Header: comettypes.Header{ChainID: "test-1", Height: 10, Time: time.Unix(0, 10000).UTC()},
+		},
+	}
+	n := newTestNode(t, newTestBlockChain(block, &rpccoretypes.ResultBlockResults{Height: 10}))
+	n.SetSyncInfo(20)
+
+	replayed := 0
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, args nodetypes.EndBlockArgs) error {
+		replayed++
+		kv := n.SyncInfoToRawKV(args.Block.Header.Height)
+		return n.db.RawBatchSet(kv)
+	}))
+
+	require.Error(t, n.ReplayRange(context.Background(), 11, 10, false))
+	require.NoError(t, n.ReplayRange(context.Background(), 10, 10, false))
+	require.Equal(t, 1, replayed)
+
+	// the running looper is paused to replay the blocks with the force flag
+	n.cfg.PollingInterval = time.Millisecond
+	n.running = true
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- n.blockProcessLooper(ctx, nodetypes.PROCESS_TYPE_DEFAULT)
+	}()
+	// the replay is refused while the looper is running unless it is forced
+	require.ErrorIs(t, n.ReplayRange(context.Background(), 10, 10, false), nodetypes.ErrBlockProcessRunning)
+	require.NoError(t, n.ReplayRange(context.Background(), 10, 10, true))
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, 2, replayed)
+
+	// last processed block height is not touched
+	require.Equal(t, int64(20), n.lastProcessedBlockHeight)
+	data, err := n.db.Get(nodetypes.LastProcessedBlockHeightKey)
+	require.NoError(t, err)
+	syncedHeight, err := dbtypes.ToInt64(data)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), syncedHeight)
+}
+
+func Test_RetryBlock(t *testing.T) {
+	block := &rpccoretypes.ResultBlock{
+		Block: &comettypes.Block{
+			Header: comettypes.Header{ChainID: "test-1", Height: 10, Time: time.Unix(0, 10000).UTC()},
+			Data:   comettypes.Data{Txs: comettypes.Txs{[]byte("tx0")}},
+		},
+	}
+	blockResult := &rpccoretypes.ResultBlockResults{
+		Height:     10,
+		TxsResults: []*abcitypes.ExecTxResult{{Events: []abcitypes.Event{{Type: "transfer"}}}},
+	}
+	chain := newTestBlockChain(block, blockResult)
+
+	newRetryNode := func(vetoes int, onSuccess func()) (*Node, *int, *int) {
+		n := newTestNode(t, chain)
+		n.cfg.RestartPolicy = nodetypes.RestartPolicy{
+			InitialBackoff:  time.Millisecond,
+			MaxBackoff:      time.Millisecond,
+			MaxBlockRetries: 3,
+		}
+		n.SetSyncInfo(9)
+
+		events, endBlocks := 0, 0
+		n.RegisterEventHandler("transfer", func(_ context.Context, _ nodetypes.EventHandlerArgs) error {
+			events++
+			return nil
+		})
+		require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, _ nodetypes.EndBlockArgs) error {
+			endBlocks++
+			if endBlocks <= vetoes {
+				return nodetypes.ErrRetryBlock
+			}
+			onSuccess()
+			return nil
+		}))
+		return n, &events, &endBlocks
+	}
+
+	// the block is re-processed until the end block handler succeeds
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n, events, endBlocks := newRetryNode(2, cancel)
+	require.NoError(t, n.blockProcessLooper(ctx, nodetypes.PROCESS_TYPE_DEFAULT))
+	require.Equal(t, int64(10), n.lastProcessedBlockHeight)
+	require.Equal(t, 3, *endBlocks)
+	// event handlers of the retried block are re-invoked
+	require.Equal(t, 3, *events)
+
+	// exceeding the max retries surfaces a fatal error
+	n, _, endBlocks = newRetryNode(10, func() {})
+	err := n.blockProcessLooper(context.Background(), nodetypes.PROCESS_TYPE_DEFAULT)
+	require.ErrorIs(t, err, nodetypes.ErrRetryBlock)
+	require.False(t, nodetypes.IsTransientError(err))
+	require.Equal(t, 4, *endBlocks)
+	require.Equal(t, int64(9), n.lastProcessedBlockHeight)
+}
+
+func Test_NextPollingInterval(t *testing.T) {
+	require.Equal(t, 200*time.Millisecond, nextPollingInterval(100*time.Millisecond, time.Second))
+	require.Equal(t, time.Second, nextPollingInterval(800*time.Millisecond, time.Second))
+	require.Equal(t, time.Second, nextPollingInterval(time.Second, time.Second))
+}
+
+func Test_AdaptivePolling(t *testing.T) {
+	block := &rpccoretypes.ResultBlock{
+		Block: &comettypes.Block{
+			Header: comettypes.Header{ChainID: "test-1", Height: 11, Time: time.Unix(0, 10000).UTC()},
+		},
+	}
+	chain := newTestBlockChain(block, &rpccoretypes.ResultBlockResults{Height: 11})
+
+	// the chain has no new block until the latest height is bumped
+	chain.SetLatestHeight(10)
+	statusCalls := make(chan time.Time, 100)
+	chain.SetCallHook(func(_ context.Context, method string) error {
+		if method == nodetest.MethodStatus {
+			statusCalls <- time.Now()
+		}
+		return nil
+	})
+
+	n := newTestNode(t, chain)
+	n.cfg.PollingInterval = 10 * time.Millisecond
+	n.cfg.MaxPollingInterval = 160 * time.Millisecond
+	n.SetSyncInfo(10)
+
+	processed := make(chan struct{})
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, _ nodetypes.EndBlockArgs) error {
+		close(processed)
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- n.blockProcessLooper(ctx, nodetypes.PROCESS_TYPE_DEFAULT)
+	}()
+
+	// the polling interval is doubled while there is no new block
+	var calls []time.Time
+	for len(calls) < 6 {
+		calls = append(calls, <-statusCalls)
+	}
+	require.Greater(t, calls[5].Sub(calls[4]), 100*time.Millisecond)
+	require.Greater(t, calls[5].Sub(calls[4]), calls[2].Sub(calls[1]))
+
+	// the polling interval is reset on a new block
+	chain.SetLatestHeight(11)
+	<-processed
+	for len(statusCalls) > 0 {
+		<-statusCalls
+	}
+	processedAt := time.Now()
+	require.Less(t, (<-statusCalls).Sub(processedAt), 100*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func Test_DedupEvents(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+
+	withdrawalEvent := func(sequence string) abcitypes.Event {
+		return abcitypes.Event{
+			Type: "initiate_token_withdrawal",
+			Attributes: []abcitypes.EventAttribute{
+				{Key: "l2_sequence", Value: sequence},
+				{Key: "amount", Value: "100"},
+			},
+		}
+	}
+
+	var sequences []string
+	n.RegisterEventHandlerWithDedup("initiate_token_withdrawal", func(_ context.Context, args nodetypes.EventHandlerArgs) error {
+		sequences = append(sequences, args.EventAttributes[0].Value)
+		return nil
+	}, func(attrs []abcitypes.EventAttribute) (string, error) {
+		for _, attr := range attrs {
+			if attr.Key == "l2_sequence" {
+				return attr.Value, nil
+			}
+		}
+		return "", errors.New("missing l2 sequence")
+	})
+
+	block := &rpccoretypes.ResultBlock{
+		Block: &comettypes.Block{
+			Header: comettypes.Header{ChainID: "test-1", Height: 10, Time: time.Now().UTC()},
+			Data:   comettypes.Data{Txs: comettypes.Txs{[]byte("tx0"), []byte("tx1")}},
+		},
+	}
+	blockResult := &rpccoretypes.ResultBlockResults{
+		Height: 10,
+		TxsResults: []*abcitypes.ExecTxResult{
+			// the same withdrawal is emitted twice in a tx
+			{Events: []abcitypes.Event{withdrawalEvent("1"), withdrawalEvent("1"), withdrawalEvent("2")}},
+			// the dedup is only applied within the same tx
+			{Events: []abcitypes.Event{withdrawalEvent("2")}},
+		},
+	}
+
+	require.NoError(t, n.handleNewBlock(context.Background(), block, blockResult, 10))
+	require.Equal(t, []string{"1", "2", "2"}, sequences)
+
+	// the event without the key fails the block
+	blockResult.TxsResults[1].Events = []abcitypes.Event{{Type: "initiate_token_withdrawal"}}
+	err := n.handleNewBlock(context.Background(), block, blockResult, 10)
+	require.ErrorContains(t, err, "missing l2 sequence")
+}
+
+func Test_Rewind(t *testing.T) {
+	n := newTestNode(t, nodetest.NewFakeClient("test-1"))
+	n.SetSyncInfo(20)
+
+	var rewindArgs []nodetypes.RewindArgs
+	require.NoError(t, n.RegisterRewindHandler(func(_ context.Context, args nodetypes.RewindArgs) error {
+		rewindArgs = append(rewindArgs, args)
+		if args.Height == 5 {
+			return errors.New("failed to rollback")
+		}
+		return nil
+	}))
+
+	require.Error(t, n.Rewind(0))
+
+	// no request
+	rewound, err := n.applyRewind(context.Background())
+	require.NoError(t, err)
+	require.False(t, rewound)
+
+	// rewind to the unprocessed height is ignored
+	require.NoError(t, n.Rewind(25))
+	rewound, err = n.applyRewind(context.Background())
+	require.NoError(t, err)
+	require.False(t, rewound)
+	require.Empty(t, rewindArgs)
+
+	require.NoError(t, n.Rewind(10))
+	rewound, err = n.applyRewind(context.Background())
+	require.NoError(t, err)
+	require.True(t, rewound)
+	require.Equal(t, []nodetypes.RewindArgs{{Height: 10, LastProcessedHeight: 20}}, rewindArgs)
+	require.Equal(t, int64(10), n.lastProcessedBlockHeight)
+
+	data, err := n.db.Get(nodetypes.LastProcessedBlockHeightKey)
+	require.NoError(t, err)
+	syncedHeight, err := dbtypes.ToInt64(data)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), syncedHeight)
+
+	// the height is kept if the handler fails
+	require.NoError(t, n.Rewind(5))
+	_, err = n.applyRewind(context.Background())
+	require.Error(t, err)
+	require.Equal(t, int64(10), n.lastProcessedBlockHeight)
+}
+
+func Test_RPCTimeout(t *testing.T) {
+	// the node hangs on the first block requests until the client gives up
+	chain := nodetest.NewFakeClient("test-1")
+	chain.AppendBlocks(4)
+	hangs := &atomic.Int32{}
+	hangs.Store(2)
+	chain.SetCallHook(func(ctx context.Context, method string) error {
+		if method == nodetest.MethodBlock && hangs.Add(-1) >= 0 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	server := nodetest.NewServer(t, chain)
+
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces})
+	require.NoError(t, err)
+	n, err := NewNode(nodetypes.NodeConfig{
+		RPC:           server.URL,
+		ChainID:       "test-1",
+		ProcessType:   nodetypes.PROCESS_TYPE_DEFAULT,
+		Bech32Prefix:  "init",
+		RPCTimeout:    50 * time.Millisecond,
+		RestartPolicy: nodetypes.RestartPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	}, db, zap.NewNop(), cdc, txConfig)
+	require.NoError(t, err)
+
+	restarts := make([]error, 0)
+	require.NoError(t, n.RegisterRestartHandler(func(_ context.Context, args nodetypes.RestartArgs) {
+		restarts = append(restarts, args.Err)
+	}))
+	ctx, cancel := context.WithTimeout(types.WithPollingInterval(context.Background(), time.Millisecond), 5*time.Second)
+	defer cancel()
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, args nodetypes.EndBlockArgs) error {
+		if args.Block.Header.Height == 4 {
+			cancel()
+		}
+		return nil
+	}))
+
+	// the looper recovers from the hung calls
+	require.NoError(t, n.blockProcessLooperWithRestart(ctx, nodetypes.PROCESS_TYPE_DEFAULT))
+	require.Equal(t, int64(4), n.lastProcessedBlockHeight)
+	require.Len(t, restarts, 2)
+	for _, err := range restarts {
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.True(t, nodetypes.IsTransientError(err))
+	}
+
+	// the query context is bounded by the rpc timeout
+	queryCtx, queryCancel := n.QueryContext(context.Background(), 0)
+	defer queryCancel()
+	deadline, ok := queryCtx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(50*time.Millisecond), deadline, 50*time.Millisecond)
+}
@@ -0,0 +1,103 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/keys"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func newTestNodeWithProcessType(t *testing.T, chain *nodetest.FakeClient, processType nodetypes.BlockProcessType, broadcasterConfig *btypes.BroadcasterConfig) *Node {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces})
+	require.NoError(t, err)
+
+	n, err := NewNodeWithRPCClient(nodetypes.NodeConfig{
+		RPC:               "tcp://localhost:26657",
+		ChainID:           "test-1",
+		ProcessType:       processType,
+		Bech32Prefix:      "init",
+		BroadcasterConfig: broadcasterConfig,
+	}, db, zap.NewNop(), cdc, txConfig, chain)
+	require.NoError(t, err)
+	return n
+}
+
+func Test_BroadcastOnlyNode(t *testing.T) {
+	chain := newCatchingUpChain(0)
+	n := newTestNodeWithProcessType(t, chain, nodetypes.PROCESS_TYPE_ONLY_BROADCAST, nil)
+
+	// the sync info in the db is not loaded
+	require.NoError(t, n.db.Set(nodetypes.LastProcessedBlockHeightKey, dbtypes.FromUint64(100)))
+	require.NoError(t, n.Initialize(context.Background(), 10, nil))
+	require.Zero(t, chain.Calls(nodetest.MethodStatus))
+	require.False(t, n.HeightInitialized())
+	require.Equal(t, int64(1), n.GetHeight())
+
+	// the block sync methods fail fast
+	require.ErrorIs(t, n.RegisterBeginBlockHandler(func(context.Context, nodetypes.BeginBlockArgs) error { return nil }), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.RegisterTxHandler(func(context.Context, nodetypes.TxHandlerArgs) error { return nil }), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.RegisterEndBlockHandler(func(context.Context, nodetypes.EndBlockArgs) error { return nil }), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.RegisterRawBlockHandler(func(context.Context, nodetypes.RawBlockArgs) error { return nil }), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.RegisterRestartHandler(func(context.Context, nodetypes.RestartArgs) {}), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.RegisterRewindHandler(func(context.Context, nodetypes.RewindArgs) error { return nil }), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.SaveSyncInfo(10), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.Rewind(10), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.ReplayRange(context.Background(), 1, 10, false), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.RegisterEventHandler("record_batch", func(context.Context, nodetypes.EventHandlerArgs) error { return nil }), nodetypes.ErrBlockSyncInactive)
+	require.ErrorIs(t, n.RegisterEventHandlerWithDedup("record_batch", func(context.Context, nodetypes.EventHandlerArgs) error { return nil }, nil), nodetypes.ErrBlockSyncInactive)
+	require.Nil(t, n.beginBlockHandler)
+	require.Nil(t, n.endBlockHandler)
+	require.Empty(t, n.eventHandlers)
+	require.Empty(t, n.eventKeyFns)
+
+	status, err := n.Status()
+	require.NoError(t, err)
+	require.Equal(t, "only_broadcast", status.ProcessType)
+	require.Empty(t, status.Subsystems)
+	require.Zero(t, n.GetStatus().LastBlockHeight)
+}
+
+func Test_DefaultNodeSubsystems(t *testing.T) {
+	n := newTestNodeWithProcessType(t, newCatchingUpChain(0), nodetypes.PROCESS_TYPE_DEFAULT, nil)
+	require.NoError(t, n.Initialize(context.Background(), 0, nil))
+
+	status, err := n.Status()
+	require.NoError(t, err)
+	require.Equal(t, "default", status.ProcessType)
+	require.Equal(t, []string{nodetypes.SubsystemBlockSync}, status.Subsystems)
+}
+
+func Test_CloseGRPCConn(t *testing.T) {
+	cdc, txConfig, err := keys.CreateCodec([]keys.RegisterInterfaces{authtypes.RegisterInterfaces})
+	require.NoError(t, err)
+
+	// the node without the grpc address queries through the rpc
+	n := newTestNodeWithProcessType(t, newCatchingUpChain(0), nodetypes.PROCESS_TYPE_DEFAULT, nil)
+	require.NoError(t, n.Close())
+
+	n, err = NewNodeWithRPCClient(nodetypes.NodeConfig{
+		RPC:          "tcp://localhost:26657",
+		GRPCAddress:  "localhost:9090",
+		ChainID:      "test-1",
+		ProcessType:  nodetypes.PROCESS_TYPE_DEFAULT,
+		Bech32Prefix: "init",
+	}, db.NewMemDB(), zap.NewNop(), cdc, txConfig, newCatchingUpChain(0))
+	require.NoError(t, err)
+	require.NoError(t, n.Close())
+	// the connection is already closed
+	require.Error(t, n.Close())
+}
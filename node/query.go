@@ -3,6 +3,11 @@ package node
 import (
 	"context"
 	"time"
+
+	comettypes "github.com/cometbft/cometbft/types"
+
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
 func (n Node) QueryBlockTime(ctx context.Context, height int64) (time.Time, error) {
@@ -12,3 +17,59 @@ func (n Node) QueryBlockTime(ctx context.Context, height int64) (time.Time, erro
 	}
 	return block.Block.Header.Time, nil
 }
+
+// QueryValidators returns the validator set at the given height.
+func (n Node) QueryValidators(ctx context.Context, height int64) ([]*comettypes.Validator, error) {
+	perPage := 100
+	validators := make([]*comettypes.Validator, 0)
+	for page := 1; ; page++ {
+		result, err := n.rpcClient.Validators(ctx, &height, &page, &perPage)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, result.Validators...)
+		if len(result.Validators) == 0 || len(validators) >= result.Total {
+			return validators, nil
+		}
+	}
+}
+
+// TraceTx returns the stored records of the msgs with the given trace id for debugging.
+func (n Node) TraceTx(traceID string) (btypes.TxTrace, error) {
+	if n.broadcaster == nil {
+		return btypes.TxTrace{}, types.ErrKeyNotSet
+	}
+	return n.broadcaster.TraceTx(traceID)
+}
+
+// DeadLetters returns the msgs parked by the broadcaster because they failed the simulation.
+func (n Node) DeadLetters() ([]btypes.DeadLetter, error) {
+	if n.broadcaster == nil {
+		return nil, types.ErrKeyNotSet
+	}
+	return n.broadcaster.DeadLetters()
+}
+
+// RetryDeadLetter broadcasts the parked msg of the given id again.
+func (n Node) RetryDeadLetter(id uint64) (btypes.ProcessedMsgs, error) {
+	if n.broadcaster == nil {
+		return btypes.ProcessedMsgs{}, types.ErrKeyNotSet
+	}
+	return n.broadcaster.RetryDeadLetter(id)
+}
+
+// PendingTxs returns the decoded summaries of the pending txs of the broadcaster.
+func (n Node) PendingTxs() ([]btypes.PendingTxSummary, error) {
+	if n.broadcaster == nil {
+		return nil, types.ErrKeyNotSet
+	}
+	return n.broadcaster.PendingTxs(), nil
+}
+
+// DropPendingTx drops the stuck pending tx of the given sender and sequence, and resubmits the later pending txs.
+func (n Node) DropPendingTx(sender string, sequence uint64, requeue bool) (btypes.PendingTxInfo, []btypes.PendingTxInfo, error) {
+	if n.broadcaster == nil {
+		return btypes.PendingTxInfo{}, nil, types.ErrKeyNotSet
+	}
+	return n.broadcaster.DropPendingTx(sender, sequence, requeue)
+}
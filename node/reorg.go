@@ -0,0 +1,116 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	comettypes "github.com/cometbft/cometbft/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// storedBlockHashes is the number of the last processed heights whose block hashes are kept to detect the reorgs.
+const storedBlockHashes = 100
+
+// BlockHash returns the stored block hash of the processed height. Only the hashes of
+// the last processed heights are kept.
+func (n Node) BlockHash(height int64) ([]byte, error) {
+	if height <= 0 {
+		return nil, dbtypes.ErrNotFound
+	}
+	return n.db.Get(nodetypes.PrefixedBlockHash(types.MustInt64ToUint64(height)))
+}
+
+// saveBlockHash stores the block hash of the processed height, and deletes the oldest stored hash.
+func (n Node) saveBlockHash(height int64, hash []byte) error {
+	if len(hash) == 0 {
+		return nil
+	}
+
+	kvs := []types.RawKV{{
+		Key:   n.db.PrefixedKey(nodetypes.PrefixedBlockHash(types.MustInt64ToUint64(height))),
+		Value: hash,
+	}}
+	if height > storedBlockHashes {
+		kvs = append(kvs, types.RawKV{
+			Key:   n.db.PrefixedKey(nodetypes.PrefixedBlockHash(types.MustInt64ToUint64(height - storedBlockHashes))),
+			Value: nil,
+		})
+	}
+	if err := n.db.RawBatchSet(kvs...); err != nil {
+		return fmt.Errorf("%w: failed to save block hash: height: %d; %w", nodetypes.ErrFatalDB, height, err)
+	}
+	return nil
+}
+
+// checkParentHash returns ChainReorgError if the parent hash of the block at the height differs from
+// the stored hash of the previous height. The check is skipped if the hash of the previous height is not stored.
+func (n Node) checkParentHash(ctx context.Context, height int64, parentHash []byte) error {
+	if len(parentHash) == 0 {
+		return nil
+	}
+
+	storedHash, err := n.BlockHash(height - 1)
+	if errors.Is(err, dbtypes.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if bytes.Equal(storedHash, parentHash) {
+		return nil
+	}
+
+	reorgErr := n.findDivergence(ctx, &nodetypes.ChainReorgError{
+		Height:     height - 1,
+		StoredHash: storedHash,
+		ChainHash:  parentHash,
+	})
+	n.logger.Error("chain reorg detected",
+		zap.Int64("divergence_height", reorgErr.Height),
+		zap.String("stored_hash", fmt.Sprintf("%X", reorgErr.StoredHash)),
+		zap.String("chain_hash", fmt.Sprintf("%X", reorgErr.ChainHash)),
+	)
+	return reorgErr
+}
+
+// findDivergence walks back the stored hashes below the replaced height to find the lowest replaced height.
+// It stops at the first height whose hash matches the chain, isn't stored or can't be queried.
+func (n Node) findDivergence(ctx context.Context, reorgErr *nodetypes.ChainReorgError) *nodetypes.ChainReorgError {
+	for height := reorgErr.Height - 1; height > 0; height-- {
+		storedHash, err := n.BlockHash(height)
+		if err != nil {
+			break
+		}
+		header, err := n.rpcClient.Header(ctx, &height)
+		if err != nil {
+			n.logger.Warn("failed to query header to find the reorg divergence", zap.Int64("height", height), zap.String("error", err.Error()))
+			break
+		}
+		chainHash := header.Header.Hash()
+		if bytes.Equal(storedHash, chainHash) {
+			break
+		}
+		reorgErr = &nodetypes.ChainReorgError{
+			Height:     height,
+			StoredHash: storedHash,
+			ChainHash:  chainHash,
+		}
+	}
+	return reorgErr
+}
+
+// rawBlockHashes returns the hash and the parent hash of the raw block header.
+// The hash is nil if the header is invalid to be hashed.
+func rawBlockHashes(header *cmtproto.Header) ([]byte, []byte) {
+	h, err := comettypes.HeaderFromProto(header)
+	if err != nil {
+		return nil, header.LastBlockId.Hash
+	}
+	return h.Hash(), header.LastBlockId.Hash
+}
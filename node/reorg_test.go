@@ -0,0 +1,108 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+	comettypes "github.com/cometbft/cometbft/types"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// newTestChain returns the linked blocks from the height 1 to the given height. The blocks from the fork height
+// are different from the blocks of the other chains with the different fork height.
+func newTestChain(latestHeight int64, forkHeight int64) map[int64]*rpccoretypes.ResultBlock {
+	blocks := make(map[int64]*rpccoretypes.ResultBlock)
+	parent := comettypes.BlockID{}
+	for height := int64(1); height <= latestHeight; height++ {
+		header := comettypes.Header{ChainID: "test-1", Height: height, Time: time.Unix(height, 0).UTC(), LastBlockID: parent, ValidatorsHash: make([]byte, 32)}
+		if height >= forkHeight {
+			header.AppHash = []byte("fork")
+		}
+		parent = comettypes.BlockID{Hash: header.Hash()}
+		blocks[height] = &rpccoretypes.ResultBlock{BlockID: parent, Block: &comettypes.Block{Header: header}}
+	}
+	return blocks
+}
+
+// newTestFakeChain returns the fake chain serving the blocks.
+func newTestFakeChain(blocks map[int64]*rpccoretypes.ResultBlock) *nodetest.FakeClient {
+	chain := nodetest.NewFakeClient("test-1")
+	for _, block := range blocks {
+		chain.AddBlock(block, nil)
+	}
+	return chain
+}
+
+func Test_ChainReorg(t *testing.T) {
+	// the node processed the heights 1-3 of the chain, and the chain is replaced from the height 2
+	processed := newTestChain(3, 10)
+	n := newTestNode(t, newTestFakeChain(newTestChain(4, 2)))
+	for height := int64(1); height <= 3; height++ {
+		require.NoError(t, n.saveBlockHash(height, processed[height].BlockID.Hash))
+	}
+	n.SetSyncInfo(3)
+
+	handled := 0
+	require.NoError(t, n.RegisterBeginBlockHandler(func(_ context.Context, _ nodetypes.BeginBlockArgs) error {
+		handled++
+		return nil
+	}))
+
+	ctx, cancel := context.WithTimeout(types.WithPollingInterval(context.Background(), time.Millisecond), 5*time.Second)
+	defer cancel()
+	err := n.blockProcessLooperWithRestart(ctx, nodetypes.PROCESS_TYPE_DEFAULT)
+	require.ErrorIs(t, err, nodetypes.ErrChainReorg)
+	require.False(t, nodetypes.IsTransientError(err))
+
+	var reorgErr *nodetypes.ChainReorgError
+	require.True(t, errors.As(err, &reorgErr))
+	require.Equal(t, int64(2), reorgErr.Height)
+	require.Equal(t, []byte(processed[2].BlockID.Hash), reorgErr.StoredHash)
+	require.NotEqual(t, reorgErr.StoredHash, reorgErr.ChainHash)
+
+	// the block after the reorg is not processed
+	require.Equal(t, 0, handled)
+	require.Equal(t, int64(3), n.lastProcessedBlockHeight)
+}
+
+func Test_BlockHashes(t *testing.T) {
+	blocks := newTestChain(4, 10)
+	n := newTestNode(t, newTestFakeChain(blocks))
+	for height := int64(1); height <= 3; height++ {
+		require.NoError(t, n.saveBlockHash(height, blocks[height].BlockID.Hash))
+	}
+	n.SetSyncInfo(3)
+
+	ctx, cancel := context.WithCancel(types.WithPollingInterval(context.Background(), time.Millisecond))
+	defer cancel()
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, args nodetypes.EndBlockArgs) error {
+		if args.Block.Header.Height == 4 {
+			cancel()
+		}
+		return nil
+	}))
+	require.NoError(t, n.blockProcessLooperWithRestart(ctx, nodetypes.PROCESS_TYPE_DEFAULT))
+	require.Equal(t, int64(4), n.lastProcessedBlockHeight)
+	hash, err := n.BlockHash(4)
+	require.NoError(t, err)
+	require.Equal(t, []byte(blocks[4].BlockID.Hash), hash)
+
+	// the missing hash is not checked
+	require.NoError(t, n.checkParentHash(ctx, 10, []byte("unknown")))
+
+	// only the hashes of the last heights are kept
+	require.NoError(t, n.saveBlockHash(storedBlockHashes+1, []byte("hash")))
+	_, err = n.BlockHash(1)
+	require.ErrorIs(t, err, dbtypes.ErrNotFound)
+	_, err = n.BlockHash(2)
+	require.NoError(t, err)
+}
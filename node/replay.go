@@ -0,0 +1,143 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// isSkipHeight returns true if the height is configured to be skipped.
+func (n Node) isSkipHeight(height int64) bool {
+	_, ok := n.skipHeights[height]
+	return ok
+}
+
+// replayRequest is the replay requested to the running block process looper.
+type replayRequest struct {
+	from, to uint64
+	done     chan error
+}
+
+// ReplayRange re-runs the registered handlers for the blocks from `from` to `to` (inclusive)
+// without touching lastProcessedBlockHeight. It returns ErrBlockProcessRunning while the node is
+// running unless it is forced; the forced replay is run by the block process looper before the
+// next block, so the looper is paused during the replay.
+func (n *Node) ReplayRange(ctx context.Context, from, to uint64, force bool) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	} else if from == 0 || from > to {
+		return fmt.Errorf("invalid replay range: from: %d, to: %d", from, to)
+	}
+
+	if !n.replayMu.TryLock() {
+		return errors.New("replay is already running")
+	}
+	defer n.replayMu.Unlock()
+
+	if !n.running {
+		return n.replay(ctx, from, to)
+	} else if !force {
+		return nodetypes.ErrBlockProcessRunning
+	}
+
+	req := replayRequest{from: from, to: to, done: make(chan error, 1)}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.blockProcessStopped:
+		return errors.New("block process is stopped")
+	case n.replayRequests <- req:
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-req.done:
+		return err
+	}
+}
+
+// applyReplay runs the replay requested while the block process looper is running.
+func (n *Node) applyReplay(ctx context.Context) {
+	select {
+	case req := <-n.replayRequests:
+		req.done <- n.replay(ctx, req.from, req.to)
+	default:
+	}
+}
+
+func (n *Node) replay(ctx context.Context, from, to uint64) error {
+	n.replaying.Store(true)
+	defer n.replaying.Store(false)
+
+	status, err := n.rpcClient.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get node status")
+	}
+	latestChainHeight := status.SyncInfo.LatestBlockHeight
+
+	start, end := types.MustUint64ToInt64(from), types.MustUint64ToInt64(to)
+	if end > latestChainHeight {
+		return fmt.Errorf("replay range exceeds the latest height: to: %d, latest_height: %d", to, latestChainHeight)
+	}
+
+	n.logger.Info("replay blocks", zap.Int64("from", start), zap.Int64("to", end))
+
+	switch n.cfg.ProcessType {
+	case nodetypes.PROCESS_TYPE_DEFAULT:
+		for height := start; height <= end; height++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			block, blockResult, err := n.fetchNewBlock(ctx, height)
+			if err != nil {
+				return errors.Wrapf(err, "failed to fetch block: height: %d", height)
+			}
+
+			err = n.handleNewBlock(ctx, block, blockResult, latestChainHeight)
+			if err != nil {
+				return errors.Wrapf(err, "failed to replay block: height: %d", height)
+			}
+		}
+	case nodetypes.PROCESS_TYPE_RAW:
+		for bulkStart := start; bulkStart <= end; bulkStart += 100 {
+			bulkEnd := bulkStart + 99
+			if bulkEnd > end {
+				bulkEnd = end
+			}
+
+			blockBulk, err := n.rpcClient.QueryBlockBulk(ctx, bulkStart, bulkEnd)
+			if err != nil {
+				return errors.Wrapf(err, "failed to fetch block bulk: start: %d, end: %d", bulkStart, bulkEnd)
+			}
+
+			for height := bulkStart; height <= bulkEnd; height++ {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				args, err := newRawBlockArgs(height, latestChainHeight, blockBulk[height-bulkStart])
+				if err != nil {
+					return err
+				}
+
+				err = n.callHandler(height, -1, "raw_block", func() error {
+					return n.rawBlockHandler(ctx, args)
+				})
+				if err != nil {
+					return errors.Wrapf(err, "failed to replay raw block: height: %d", height)
+				}
+			}
+		}
+	default:
+		return errors.New("replay is not supported for the process type")
+	}
+
+	n.logger.Info("replay finished", zap.Int64("from", start), zap.Int64("to", end))
+	return nil
+}
@@ -0,0 +1,54 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+// Rewind requests the block process looper to process the blocks after the given height again.
+// The request is applied by the looper before the next block, so it can be called from the other goroutines.
+func (n *Node) Rewind(height int64) error {
+	if err := n.checkBlockSync(); err != nil {
+		return err
+	} else if height <= 0 {
+		return fmt.Errorf("invalid rewind height: %d", height)
+	}
+	n.rewindHeight.Store(height)
+	return nil
+}
+
+// applyRewind applies the requested rewind, calling the rewind handler to roll back the states first.
+// It returns true if the last processed block height is rewound.
+func (n *Node) applyRewind(ctx context.Context) (bool, error) {
+	height := n.rewindHeight.Swap(0)
+	if height == 0 {
+		return false, nil
+	} else if height >= n.lastProcessedBlockHeight {
+		n.logger.Debug("ignore rewind to the unprocessed height", zap.Int64("height", height), zap.Int64("last_processed_height", n.lastProcessedBlockHeight))
+		return false, nil
+	}
+
+	if n.rewindHandler != nil {
+		err := n.rewindHandler(ctx, nodetypes.RewindArgs{
+			Height:              height,
+			LastProcessedHeight: n.lastProcessedBlockHeight,
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to rewind to height %d", height)
+		}
+	}
+
+	err := n.SaveSyncInfo(height)
+	if err != nil {
+		return false, err
+	}
+
+	n.logger.Warn("rewind block process", zap.Int64("from", n.lastProcessedBlockHeight), zap.Int64("to", height))
+	n.SetSyncInfo(height)
+	return true, nil
+}
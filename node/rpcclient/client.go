@@ -2,10 +2,10 @@ package rpcclient
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
@@ -17,17 +17,20 @@ import (
 	abci "github.com/cometbft/cometbft/abci/types"
 	client2 "github.com/cometbft/cometbft/rpc/client"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	comettypes "github.com/cometbft/cometbft/types"
 	gogogrpc "github.com/cosmos/gogoproto/grpc"
 
 	"github.com/cosmos/cosmos-sdk/codec"
-	"github.com/cosmos/cosmos-sdk/codec/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	legacyerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
 
 	clienthttp "github.com/initia-labs/opinit-bots/client"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
 )
 
-var _ gogogrpc.ClientConn = &RPCClient{}
+var _ Client = &RPCClient{}
 
 var protoCodec = encoding.GetCodec(proto.Name)
 
@@ -38,8 +41,33 @@ type RPCClient struct {
 	cdc codec.Codec
 }
 
+// Client is the narrow interface of the rpc client used by the node, the broadcaster and the
+// components depending on the node. The nodetest package provides the fake implementation.
+type Client interface {
+	gogogrpc.ClientConn
+
+	Status(ctx context.Context) (*coretypes.ResultStatus, error)
+	Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error)
+	BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error)
+	Header(ctx context.Context, height *int64) (*coretypes.ResultHeader, error)
+	Validators(ctx context.Context, height *int64, page, perPage *int) (*coretypes.ResultValidators, error)
+	BroadcastTxSync(ctx context.Context, tx comettypes.Tx) (*coretypes.ResultBroadcastTx, error)
+	TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*coretypes.ResultTxSearch, error)
+
+	QueryABCI(ctx context.Context, req abci.RequestQuery) (abci.ResponseQuery, error)
+	QueryTx(ctx context.Context, txHash []byte) (*coretypes.ResultTx, error)
+	QueryBlockBulk(ctx context.Context, start int64, end int64) ([][]byte, error)
+	QueryBlockResults(ctx context.Context, height int64) (*coretypes.ResultBlockResults, error)
+	QueryRawCommit(ctx context.Context, height int64) ([]byte, error)
+}
+
 func NewRPCClient(cdc codec.Codec, rpcAddr string) (*RPCClient, error) {
-	client, err := clienthttp.New(rpcAddr, "/websocket")
+	return NewRPCClientWithTimeout(cdc, rpcAddr, nodetypes.DefaultRPCTimeout)
+}
+
+// NewRPCClientWithTimeout creates the rpc client whose every call is bounded by the given timeout.
+func NewRPCClientWithTimeout(cdc codec.Codec, rpcAddr string, timeout time.Duration) (*RPCClient, error) {
+	client, err := clienthttp.NewWithTimeout(rpcAddr, "/websocket", timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +105,7 @@ func (q RPCClient) Invoke(ctx context.Context, method string, req, reply interfa
 	}
 
 	if q.cdc.InterfaceRegistry() != nil {
-		return types.UnpackInterfaces(reply, q.cdc)
+		return codectypes.UnpackInterfaces(reply, q.cdc)
 	}
 
 	return nil
@@ -152,12 +180,40 @@ func (q RPCClient) QueryABCI(ctx context.Context, req abci.RequestQuery) (abci.R
 	}
 
 	if !result.Response.IsOK() {
-		return abci.ResponseQuery{}, errors.New(result.Response.Log)
+		if req.Height > 0 && isHeightPrunedLog(result.Response.Log) {
+			return abci.ResponseQuery{}, fmt.Errorf("%w: height: %d; %s", types.ErrHeightPruned, req.Height, result.Response.Log)
+		}
+		return abci.ResponseQuery{}, NewABCIQueryError(result.Response)
 	}
 
 	return result.Response, nil
 }
 
+// ABCIQueryError is the error of the query answered by the chain with the non-zero code,
+// e.g. the simulation of the msgs failing their execution, unlike the errors of the rpc calls.
+type ABCIQueryError struct {
+	Codespace string
+	Code      uint32
+	Log       string
+}
+
+func NewABCIQueryError(res abci.ResponseQuery) ABCIQueryError {
+	return ABCIQueryError{
+		Codespace: res.Codespace,
+		Code:      res.Code,
+		Log:       res.Log,
+	}
+}
+
+func (e ABCIQueryError) Error() string {
+	return e.Log
+}
+
+// isHeightPrunedLog returns true if the query failed because the state of the height is pruned.
+func isHeightPrunedLog(log string) bool {
+	return strings.Contains(log, "failed to load state at height") || strings.Contains(log, "version does not exist")
+}
+
 func GetHeightFromMetadata(md metadata.MD) (int64, error) {
 	height := md.Get(grpctypes.GRPCBlockHeightHeader)
 	if len(height) == 1 {
@@ -169,8 +225,11 @@ func GetHeightFromMetadata(md metadata.MD) (int64, error) {
 // Canceling this context releases resources associated with it, so code should
 // call cancel as soon as the operations running in this [Context] complete:
 func GetQueryContext(ctx context.Context, height int64) (context.Context, context.CancelFunc) {
-	// TODO: configurable timeout
-	timeout := 10 * time.Second
+	return GetQueryContextWithTimeout(ctx, height, nodetypes.DefaultRPCTimeout)
+}
+
+// GetQueryContextWithTimeout returns the query context of GetQueryContext bounded by the given timeout.
+func GetQueryContextWithTimeout(ctx context.Context, height int64, timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 
 	strHeight := strconv.FormatInt(height, 10)
@@ -192,6 +251,13 @@ func (q RPCClient) QueryBlockBulk(ctx context.Context, start int64, end int64) (
 	return q.BlockBulk(ctx, &start, &end)
 }
 
+// QueryBlockResults queries the block results at a given height.
+func (q RPCClient) QueryBlockResults(ctx context.Context, height int64) (*coretypes.ResultBlockResults, error) {
+	ctx, cancel := GetQueryContext(ctx, height)
+	defer cancel()
+	return q.BlockResults(ctx, &height)
+}
+
 func (q RPCClient) QueryTx(ctx context.Context, txHash []byte) (*coretypes.ResultTx, error) {
 	ctx, cancel := GetQueryContext(ctx, 0)
 	defer cancel()
@@ -0,0 +1,116 @@
+package rpcclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	gogogrpc "github.com/cosmos/gogoproto/grpc"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+var _ gogogrpc.ClientConn = &QueryConn{}
+
+const (
+	grpcKeepaliveTime    = 30 * time.Second
+	grpcKeepaliveTimeout = 10 * time.Second
+)
+
+// NewGRPCConn creates a grpc client connection to the given address. The connection is
+// established lazily, so it does not fail when the node is not reachable yet.
+func NewGRPCConn(cdc codec.Codec, grpcAddr string, enableTLS bool) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if enableTLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                grpcKeepaliveTime,
+			Timeout:             grpcKeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	}
+
+	// use the codec of the chain to unpack the interfaces of the responses
+	if grpcCodec, ok := cdc.(interface{ GRPCCodec() encoding.Codec }); ok {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.ForceCodec(grpcCodec.GRPCCodec())))
+	}
+
+	conn, err := grpc.NewClient(trimGRPCScheme(grpcAddr), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc connection: %w", err)
+	}
+	return conn, nil
+}
+
+// trimGRPCScheme removes the scheme of the address, which is not accepted by the grpc target.
+func trimGRPCScheme(addr string) string {
+	for _, scheme := range []string{"http://", "https://", "tcp://"} {
+		addr = strings.TrimPrefix(addr, scheme)
+	}
+	return addr
+}
+
+// QueryConn invokes the queries through the grpc connection, and falls back to
+// the rpc client when the grpc connection fails.
+type QueryConn struct {
+	conn      *grpc.ClientConn
+	rpcClient Client
+	logger    *zap.Logger
+}
+
+func NewQueryConn(conn *grpc.ClientConn, rpcClient Client, logger *zap.Logger) *QueryConn {
+	return &QueryConn{
+		conn:      conn,
+		rpcClient: rpcClient,
+		logger:    logger,
+	}
+}
+
+// Invoke implements the grpc ClientConn.Invoke method
+func (q QueryConn) Invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) error {
+	err := q.conn.Invoke(ctx, method, req, reply, opts...)
+	if err == nil {
+		return nil
+	}
+
+	if status.Code(err) == codes.Unavailable {
+		q.logger.Warn("grpc connection failed; falling back to rpc", zap.String("method", method), zap.String("error", err.Error()))
+		return q.rpcClient.Invoke(ctx, method, req, reply, opts...)
+	}
+
+	// keep the error of the pruned height consistent with the rpc client
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if height, herr := GetHeightFromMetadata(md); herr == nil && height > 0 && isHeightPrunedLog(err.Error()) {
+		return fmt.Errorf("%w: height: %d; %s", types.ErrHeightPruned, height, err.Error())
+	}
+	return err
+}
+
+// NewStream implements the grpc ClientConn.NewStream method
+func (q QueryConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return q.conn.NewStream(ctx, desc, method, opts...)
+}
+
+// Close closes the grpc connection.
+func (q QueryConn) Close() error {
+	return q.conn.Close()
+}
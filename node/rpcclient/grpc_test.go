@@ -0,0 +1,161 @@
+package rpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+const (
+	grpcMaxMemoCharacters = 1
+	rpcMaxMemoCharacters  = 2
+)
+
+// mockAuthQueryServer serves the auth params query, and the heights below `prunedHeight` are pruned.
+type mockAuthQueryServer struct {
+	authtypes.UnimplementedQueryServer
+
+	prunedHeight int64
+}
+
+func (s *mockAuthQueryServer) Params(ctx context.Context, _ *authtypes.QueryParamsRequest) (*authtypes.QueryParamsResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	height, err := GetHeightFromMetadata(md)
+	if err != nil {
+		return nil, err
+	}
+	if height > 0 && height < s.prunedHeight {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to load state at height %d; version does not exist", height)
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))); err != nil {
+		return nil, err
+	}
+	return &authtypes.QueryParamsResponse{Params: authtypes.Params{MaxMemoCharacters: grpcMaxMemoCharacters}}, nil
+}
+
+func newMockGRPCServer(t *testing.T, cdc *codec.ProtoCodec, prunedHeight int64) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ForceServerCodec(cdc.GRPCCodec()))
+	authtypes.RegisterQueryServer(server, &mockAuthQueryServer{prunedHeight: prunedHeight})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+// newMockRPCServer serves the auth params query through the abci query.
+func newMockRPCServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "abci_query" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		bz, err := (&authtypes.QueryParamsResponse{Params: authtypes.Params{MaxMemoCharacters: rpcMaxMemoCharacters}}).Marshal()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result, err := cmtjson.Marshal(&rpccoretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: bz, Height: 10}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  json.RawMessage(result),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestQueryConn(t *testing.T, cdc *codec.ProtoCodec, grpcAddr string) *QueryConn {
+	rpcClient, err := NewRPCClient(cdc, newMockRPCServer(t).URL)
+	require.NoError(t, err)
+
+	conn, err := NewGRPCConn(cdc, grpcAddr, false)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewQueryConn(conn, rpcClient, zap.NewNop())
+}
+
+func Test_QueryConn_GRPC(t *testing.T) {
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	queryConn := newTestQueryConn(t, cdc, "tcp://"+newMockGRPCServer(t, cdc, 5))
+	queryClient := authtypes.NewQueryClient(queryConn)
+
+	ctx, cancel := GetQueryContext(context.Background(), 7)
+	defer cancel()
+
+	var header metadata.MD
+	res, err := queryClient.Params(ctx, &authtypes.QueryParamsRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Equal(t, uint64(grpcMaxMemoCharacters), res.Params.MaxMemoCharacters)
+
+	height, err := GetHeightFromMetadata(header)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), height)
+
+	// pruned height is reported as ErrHeightPruned like the rpc client
+	ctx, cancel = GetQueryContext(context.Background(), 3)
+	defer cancel()
+
+	_, err = queryClient.Params(ctx, &authtypes.QueryParamsRequest{})
+	require.ErrorIs(t, err, types.ErrHeightPruned)
+}
+
+func Test_QueryConn_FallbackToRPC(t *testing.T) {
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+
+	// reserve an address which is not served
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	queryConn := newTestQueryConn(t, cdc, addr)
+	queryClient := authtypes.NewQueryClient(queryConn)
+
+	ctx, cancel := GetQueryContext(context.Background(), 0)
+	defer cancel()
+
+	var header metadata.MD
+	res, err := queryClient.Params(ctx, &authtypes.QueryParamsRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Equal(t, uint64(rpcMaxMemoCharacters), res.Params.MaxMemoCharacters)
+	require.Equal(t, []string{"10"}, header.Get(grpctypes.GRPCBlockHeightHeader))
+}
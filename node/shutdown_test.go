@@ -0,0 +1,92 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/initia-labs/opinit-bots/db"
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+func Test_GracefulShutdown(t *testing.T) {
+	chain := nodetest.NewFakeClient("test-1")
+	chain.AppendBlocks(6)
+	database, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+
+	// the leaves are committed with the sync info at once, as the child does at the end block
+	leafDB := database.WithPrefix([]byte("leaf"))
+	leaves := func() []int64 {
+		heights := make([]int64, 0)
+		require.NoError(t, leafDB.PrefixedIterate(nil, nil, func(_, value []byte) (bool, error) {
+			height, err := dbtypes.ToInt64(value)
+			heights = append(heights, height)
+			return false, err
+		}))
+		return heights
+	}
+
+	// runNode runs the node on the db until the block process looper is stopped. The shutdown is signaled
+	// and the context is canceled at the begin block of the given heights.
+	runNode := func(shutdownHeight int64, cancelHeight int64) *Node {
+		n := newTestNodeWithDB(t, chain, database)
+		require.NoError(t, n.loadSyncInfo(0))
+
+		shutdown := make(chan struct{})
+		ctx, cancel := context.WithCancel(types.WithPollingInterval(context.Background(), time.Millisecond))
+		defer cancel()
+		errGrp, ctx := errgroup.WithContext(ctx)
+		ctx = types.WithShutdown(types.WithErrGrp(ctx, errGrp), shutdown)
+
+		require.NoError(t, n.RegisterBeginBlockHandler(func(_ context.Context, args nodetypes.BeginBlockArgs) error {
+			switch args.Block.Header.Height {
+			case shutdownHeight:
+				close(shutdown)
+			case cancelHeight:
+				cancel()
+			}
+			return nil
+		}))
+		require.NoError(t, n.RegisterEndBlockHandler(func(ctx context.Context, args nodetypes.EndBlockArgs) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return leafDB.RawBatchSet(
+				types.RawKV{Key: leafDB.PrefixedKey(dbtypes.FromUint64Key(uint64(len(leaves())))), Value: dbtypes.FromInt64(args.Block.Header.Height)},
+				n.SyncInfoToRawKV(args.Block.Header.Height),
+			)
+		}))
+
+		n.Start(ctx)
+		select {
+		case <-n.BlockProcessStopped():
+		case <-time.After(5 * time.Second):
+			t.Fatal("block process looper is not stopped")
+		}
+		require.NoError(t, errGrp.Wait())
+		return n
+	}
+
+	// the block in progress on the shutdown is completed, and no more blocks are processed
+	n := runNode(3, 0)
+	require.Equal(t, int64(3), n.lastProcessedBlockHeight)
+	require.Equal(t, []int64{1, 2, 3}, leaves())
+
+	// the restart resumes at the next height, and the block canceled in the middle is not committed
+	n = runNode(0, 5)
+	require.Equal(t, int64(4), n.lastProcessedBlockHeight)
+	require.Equal(t, []int64{1, 2, 3, 4}, leaves())
+
+	// the canceled block is processed again without the duplicate leaf
+	n = runNode(6, 0)
+	require.Equal(t, int64(6), n.lastProcessedBlockHeight)
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6}, leaves())
+}
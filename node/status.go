@@ -1,12 +1,133 @@
 package node
 
 import (
+	"errors"
+	"sync"
+	"time"
+
 	nodetypes "github.com/initia-labs/opinit-bots/node/types"
 )
 
+// statusSnapshot keeps the state of the loops, so the status can be read
+// without touching the fields owned by the loops.
+type statusSnapshot struct {
+	mu *sync.RWMutex
+
+	latestChainHeight   int64
+	lastProcessedHeight int64
+	lastError           string
+	lastErrorTime       *time.Time
+	lastFatalErrorTime  *time.Time
+	lastProgressTime    *time.Time
+	catchingUpSince     *time.Time
+}
+
+func newStatusSnapshot() *statusSnapshot {
+	return &statusSnapshot{mu: &sync.RWMutex{}}
+}
+
+func (s *statusSnapshot) setLatestChainHeight(height int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latestChainHeight = height
+	if s.lastProcessedHeight >= height {
+		// the loop is not stalled while the chain has no new block
+		s.setProgress()
+	}
+}
+
+func (s *statusSnapshot) setLastProcessedHeight(height int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastProcessedHeight = height
+	s.setProgress()
+	// the processed height is never ahead of the chain
+	if s.latestChainHeight < height {
+		s.latestChainHeight = height
+	}
+}
+
+func (s *statusSnapshot) setError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	s.lastError = err.Error()
+	s.lastErrorTime = &now
+	if !errors.Is(err, nodetypes.ErrTransientRPC) {
+		s.lastFatalErrorTime = &now
+	}
+}
+
+// setCatchingUp records whether the bot waits for the node to catch up the chain.
+func (s *statusSnapshot) setCatchingUp(catchingUp bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !catchingUp {
+		s.catchingUpSince = nil
+	} else if s.catchingUpSince == nil {
+		now := time.Now().UTC()
+		s.catchingUpSince = &now
+	}
+}
+
+func (s *statusSnapshot) isCatchingUp() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.catchingUpSince != nil
+}
+
+// setProgress records the time when the block process loop made progress. It must be called with the lock.
+func (s *statusSnapshot) setProgress() {
+	now := time.Now().UTC()
+	s.lastProgressTime = &now
+}
+
+func (s *statusSnapshot) load() nodetypes.NodeStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return nodetypes.NodeStatus{
+		LatestChainHeight:   s.latestChainHeight,
+		LastProcessedHeight: s.lastProcessedHeight,
+		LastError:           s.lastError,
+		LastErrorTime:       s.lastErrorTime,
+		LastFatalErrorTime:  s.lastFatalErrorTime,
+		LastProgressTime:    s.lastProgressTime,
+		CatchingUp:          s.catchingUpSince != nil,
+		CatchingUpSince:     s.catchingUpSince,
+	}
+}
+
+// Status returns the snapshot of the node status for the query server.
+// It does not block the block process and tx checker loops.
+func (n *Node) Status() (nodetypes.NodeStatus, error) {
+	s := n.status.load()
+	s.ChainID = n.cfg.ChainID
+	s.ProcessType = n.cfg.ProcessType.String()
+	s.Subsystems = make([]string, 0, 2)
+	if n.cfg.ProcessType.SyncsBlocks() {
+		s.Subsystems = append(s.Subsystems, nodetypes.SubsystemBlockSync)
+	}
+	if n.broadcaster != nil {
+		s.Subsystems = append(s.Subsystems, nodetypes.SubsystemBroadcaster)
+		broadcasterStatus := n.broadcaster.GetStatus()
+		s.Broadcaster = &broadcasterStatus
+	}
+	return s, nil
+}
+
 func (n Node) GetStatus() nodetypes.Status {
 	s := nodetypes.Status{}
-	if n.cfg.ProcessType != nodetypes.PROCESS_TYPE_ONLY_BROADCAST {
+	if n.cfg.ProcessType.SyncsBlocks() {
 		s.LastBlockHeight = n.GetHeight()
 	}
 
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_StatusSnapshot(t *testing.T) {
+	// the chain produces a new block on every status query
+	chain := nodetest.NewFakeClient("test-1")
+	chain.SetCallHook(func(_ context.Context, method string) error {
+		if method == nodetest.MethodStatus {
+			chain.AppendBlock(nil, nil)
+		}
+		return nil
+	})
+
+	n := newTestNode(t, chain)
+	n.cfg.PollingInterval = time.Millisecond
+	n.cfg.MaxPollingInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, n.RegisterEndBlockHandler(func(_ context.Context, args nodetypes.EndBlockArgs) error {
+		if args.Block.Header.Height >= 50 {
+			cancel()
+		}
+		return nil
+	}))
+
+	done := make(chan error)
+	go func() {
+		done <- n.blockProcessLooper(ctx, nodetypes.PROCESS_TYPE_DEFAULT)
+	}()
+
+	// the snapshot is consistent while the blocks are being processed
+	lastProcessedHeight := int64(0)
+	for running := true; running; {
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+			running = false
+		default:
+		}
+
+		status, err := n.Status()
+		require.NoError(t, err)
+		require.Equal(t, "test-1", status.ChainID)
+		require.Nil(t, status.Broadcaster)
+		require.LessOrEqual(t, status.LastProcessedHeight, status.LatestChainHeight)
+		require.GreaterOrEqual(t, status.LastProcessedHeight, lastProcessedHeight)
+		lastProcessedHeight = status.LastProcessedHeight
+	}
+
+	status, err := n.Status()
+	require.NoError(t, err)
+	require.Equal(t, int64(50), status.LastProcessedHeight)
+	require.Empty(t, status.LastError)
+	require.NotNil(t, status.LastProgressTime)
+
+	// stable json field names
+	bz, err := json.Marshal(status)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(bz, &fields))
+	for _, field := range []string{"chain_id", "latest_chain_height", "last_processed_height", "last_error", "last_error_time", "broadcaster", "last_fatal_error_time", "last_progress_time"} {
+		require.Contains(t, fields, field)
+	}
+}
+
+func Test_StatusSnapshotErrors(t *testing.T) {
+	s := newStatusSnapshot()
+	require.Nil(t, s.load().LastProgressTime)
+
+	// the caught up loop is not stalled
+	s.setLastProcessedHeight(10)
+	s.lastProgressTime = nil
+	s.setLatestChainHeight(11)
+	require.Nil(t, s.load().LastProgressTime)
+	s.setLatestChainHeight(10)
+	require.NotNil(t, s.load().LastProgressTime)
+
+	s.setError(fmt.Errorf("%w: connection refused", nodetypes.ErrTransientRPC))
+	status := s.load()
+	require.NotNil(t, status.LastErrorTime)
+	require.Nil(t, status.LastFatalErrorTime)
+
+	s.setError(fmt.Errorf("%w: bad block", nodetypes.ErrHandlerFailure))
+	status = s.load()
+	require.Equal(t, "handler failure: bad block", status.LastError)
+	require.NotNil(t, status.LastFatalErrorTime)
+}
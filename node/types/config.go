@@ -2,7 +2,9 @@ package types
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/initia-labs/opinit-bots/keys"
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 )
 
@@ -11,12 +13,55 @@ type BlockProcessType uint8
 const (
 	PROCESS_TYPE_DEFAULT BlockProcessType = iota
 	PROCESS_TYPE_RAW
+	// PROCESS_TYPE_ONLY_BROADCAST only broadcasts the txs without syncing the blocks, e.g. the DA node.
 	PROCESS_TYPE_ONLY_BROADCAST
 )
 
+// SyncsBlocks returns true if the node of the process type syncs the blocks, which loads and saves
+// the sync info and runs the block process looper with the block handlers.
+func (t BlockProcessType) SyncsBlocks() bool {
+	return t != PROCESS_TYPE_ONLY_BROADCAST
+}
+
+func (t BlockProcessType) String() string {
+	switch t {
+	case PROCESS_TYPE_DEFAULT:
+		return "default"
+	case PROCESS_TYPE_RAW:
+		return "raw"
+	case PROCESS_TYPE_ONLY_BROADCAST:
+		return "only_broadcast"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+type HandlerPanicPolicy uint8
+
+const (
+	// HANDLER_PANIC_POLICY_HALT halts the node when a handler panics.
+	HANDLER_PANIC_POLICY_HALT HandlerPanicPolicy = iota
+	// HANDLER_PANIC_POLICY_SKIP skips the block when a handler panics, calls the skip block handler and continues.
+	// It doesn't apply to the raw block handler of PROCESS_TYPE_RAW, which always halts.
+	HANDLER_PANIC_POLICY_SKIP
+)
+
 type NodeConfig struct {
 	RPC string
 
+	// Bridge is the id of the bridge instance run by the multi executor, which labels the metrics of the node.
+	Bridge string
+
+	// GRPCAddress is the grpc address of the node, which is used for the queries if it is set.
+	// Block streaming always uses the RPC, and the queries fall back to the RPC if the grpc connection fails.
+	GRPCAddress string
+
+	// GRPCTLS enables the TLS for the grpc connection.
+	GRPCTLS bool
+
+	// ChainID is the chain id, which is used to annotate the errors.
+	ChainID string
+
 	// BlockProcessType is the type of block process.
 	ProcessType BlockProcessType
 
@@ -25,6 +70,59 @@ type NodeConfig struct {
 
 	// You can leave it empty, then the bot will skip the transaction submission.
 	BroadcasterConfig *btypes.BroadcasterConfig
+
+	// RestartPolicy is the policy to restart the block process looper after transient errors.
+	RestartPolicy RestartPolicy
+
+	// HandlerPanicPolicy is the policy when a handler panics.
+	HandlerPanicPolicy HandlerPanicPolicy
+
+	// SkipHeights are the heights to skip without running the handlers except the skip block handler.
+	// They are not supported for PROCESS_TYPE_RAW.
+	SkipHeights []int64
+
+	// PollingInterval is the interval to poll new blocks while the node is behind the chain.
+	// If it is 0, the polling interval of the context is used.
+	PollingInterval time.Duration
+
+	// MaxPollingInterval is the upper bound of the polling interval which is
+	// exponentially increased while there is no new block.
+	// If it is 0, DefaultMaxPollingInterval is used.
+	MaxPollingInterval time.Duration
+
+	// CatchUpPollingInterval is the interval to poll the node while it is catching up the chain.
+	// If it is 0, DefaultCatchUpPollingInterval is used.
+	CatchUpPollingInterval time.Duration
+
+	// MaxCatchUpWait is the maximum time to wait for the node to catch up the chain at the start.
+	// If it is 0, the node is waited without limit.
+	MaxCatchUpWait time.Duration
+
+	// RPCTimeout is the timeout of each rpc call and query to the node.
+	// If it is 0, DefaultRPCTimeout is used.
+	RPCTimeout time.Duration
+
+	// CodecModules are the module sets whose interfaces are registered to the codec of the node,
+	// so the txs of the other modules can be decoded.
+	CodecModules []string
+
+	// LenientTxDecoding decodes the txs with the unknown type urls, leaving the msgs of the unknown
+	// type urls undecoded with a warning instead of failing.
+	LenientTxDecoding bool
+}
+
+const (
+	DefaultMaxPollingInterval     = 5 * time.Second
+	DefaultCatchUpPollingInterval = 5 * time.Second
+	DefaultRPCTimeout             = 10 * time.Second
+)
+
+// GetRPCTimeout returns the timeout of each rpc call and query to the node.
+func (nc NodeConfig) GetRPCTimeout() time.Duration {
+	if nc.RPCTimeout == 0 {
+		return DefaultRPCTimeout
+	}
+	return nc.RPCTimeout
 }
 
 func (nc NodeConfig) Validate() error {
@@ -32,6 +130,10 @@ func (nc NodeConfig) Validate() error {
 		return fmt.Errorf("rpc is empty")
 	}
 
+	if nc.GRPCTLS && nc.GRPCAddress == "" {
+		return fmt.Errorf("grpc tls is enabled but grpc address is empty")
+	}
+
 	if nc.ProcessType > PROCESS_TYPE_ONLY_BROADCAST {
 		return fmt.Errorf("invalid process type")
 	}
@@ -40,6 +142,39 @@ func (nc NodeConfig) Validate() error {
 		return fmt.Errorf("bech32 prefix is empty")
 	}
 
+	if err := keys.ValidateCodecModules(nc.CodecModules); err != nil {
+		return err
+	}
+
+	if nc.HandlerPanicPolicy > HANDLER_PANIC_POLICY_SKIP {
+		return fmt.Errorf("invalid handler panic policy")
+	}
+
+	if nc.PollingInterval < 0 || nc.MaxPollingInterval < 0 {
+		return fmt.Errorf("polling interval must be greater than or equal to 0")
+	}
+
+	if nc.CatchUpPollingInterval < 0 || nc.MaxCatchUpWait < 0 {
+		return fmt.Errorf("catch up polling interval and max catch up wait must be greater than or equal to 0")
+	}
+
+	if nc.RPCTimeout < 0 {
+		return fmt.Errorf("rpc timeout must be greater than or equal to 0")
+	}
+
+	for _, height := range nc.SkipHeights {
+		if height <= 0 {
+			return fmt.Errorf("skip height must be greater than 0")
+		}
+	}
+	if nc.ProcessType == PROCESS_TYPE_RAW && len(nc.SkipHeights) != 0 {
+		return fmt.Errorf("skip heights are not supported for the raw process type, which must process every block")
+	}
+
+	if err := nc.RestartPolicy.Validate(); err != nil {
+		return err
+	}
+
 	// Validated in broadcaster
 	//
 	// if nc.BroadcasterConfig != nil {
@@ -50,3 +185,74 @@ func (nc NodeConfig) Validate() error {
 
 	return nil
 }
+
+type RestartPolicy struct {
+	// MaxRestarts is the maximum number of consecutive restarts. 0 means unlimited.
+	MaxRestarts int
+
+	// InitialBackoff is the backoff before the first restart.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the backoff between restarts.
+	MaxBackoff time.Duration
+
+	// MaxBlockRetries is the maximum number of retries of a block vetoed by ErrRetryBlock.
+	// 0 means DefaultMaxBlockRetries.
+	MaxBlockRetries int
+}
+
+const DefaultMaxBlockRetries = 7
+
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		MaxRestarts:    0,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+func (rp RestartPolicy) Validate() error {
+	if rp.MaxRestarts < 0 {
+		return fmt.Errorf("max restarts must be greater than or equal to 0")
+	}
+
+	if rp.MaxBlockRetries < 0 {
+		return fmt.Errorf("max block retries must be greater than or equal to 0")
+	}
+
+	if rp.InitialBackoff < 0 || rp.MaxBackoff < 0 {
+		return fmt.Errorf("restart backoff must be greater than or equal to 0")
+	}
+
+	if rp.MaxBackoff != 0 && rp.InitialBackoff > rp.MaxBackoff {
+		return fmt.Errorf("initial backoff must be less than or equal to max backoff")
+	}
+	return nil
+}
+
+// GetMaxBlockRetries returns the maximum number of retries of a vetoed block.
+func (rp RestartPolicy) GetMaxBlockRetries() int {
+	if rp.MaxBlockRetries == 0 {
+		return DefaultMaxBlockRetries
+	}
+	return rp.MaxBlockRetries
+}
+
+// Backoff returns the backoff before the given restart attempt (1-based).
+func (rp RestartPolicy) Backoff(attempt int) time.Duration {
+	if rp.InitialBackoff == 0 {
+		rp.InitialBackoff = DefaultRestartPolicy().InitialBackoff
+	}
+	if rp.MaxBackoff == 0 {
+		rp.MaxBackoff = DefaultRestartPolicy().MaxBackoff
+	}
+
+	backoff := rp.InitialBackoff
+	for i := 1; i < attempt && backoff < rp.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > rp.MaxBackoff {
+		backoff = rp.MaxBackoff
+	}
+	return backoff
+}
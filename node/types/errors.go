@@ -1,5 +1,106 @@
 package types
 
-import "github.com/pkg/errors"
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 var ErrIgnoreAndTryLater = errors.New("try later")
+
+// ErrRetryBlock is returned by the begin/end block handlers to veto the height advancement.
+// The node re-processes the same height after a backoff, so all the handlers must be idempotent.
+var ErrRetryBlock = errors.New("retry block")
+
+// ErrBlockSyncInactive is returned by the block sync methods of the node which doesn't sync the blocks,
+// e.g. registering a block handler to the broadcast-only node.
+var ErrBlockSyncInactive = errors.New("block sync is not active")
+
+// ErrBlockProcessRunning is returned by the replay of the node whose block process looper is running,
+// unless the replay is forced.
+var ErrBlockProcessRunning = errors.New("block process is running")
+
+// ErrTransientRPC is returned when the looper fails to talk to the rpc node.
+// The block process looper is restarted after a transient error.
+var ErrTransientRPC = errors.New("transient rpc error")
+
+// ErrFatalDB is returned when the state can not be persisted to the database.
+// The block process looper is never restarted after a fatal error.
+var ErrFatalDB = errors.New("fatal db error")
+
+// ErrHandlerFailure is the sentinel error matched by HandlerFailureError.
+var ErrHandlerFailure = errors.New("handler failure")
+
+// HandlerFailureError wraps an error returned by a registered handler
+// with the height and the event which caused the failure.
+type HandlerFailureError struct {
+	Height int64
+	Event  string
+	Err    error
+}
+
+func NewHandlerFailureError(height int64, event string, err error) *HandlerFailureError {
+	return &HandlerFailureError{
+		Height: height,
+		Event:  event,
+		Err:    err,
+	}
+}
+
+func (e *HandlerFailureError) Error() string {
+	return fmt.Sprintf("%s: height: %d, event: %s; %s", ErrHandlerFailure.Error(), e.Height, e.Event, e.Err.Error())
+}
+
+func (e *HandlerFailureError) Unwrap() []error {
+	return []error{ErrHandlerFailure, e.Err}
+}
+
+// ErrHandlerPanic is the sentinel error matched by HandlerPanicError.
+var ErrHandlerPanic = errors.New("handler panic")
+
+// HandlerPanicError is converted from a panic inside a handler
+// with the block context where the panic occurred.
+type HandlerPanicError struct {
+	ChainID   string
+	Height    int64
+	TxIndex   int64
+	EventType string
+	Recovered any
+}
+
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("%s: chain_id: %s, height: %d, tx_index: %d, event_type: %s; %v", ErrHandlerPanic.Error(), e.ChainID, e.Height, e.TxIndex, e.EventType, e.Recovered)
+}
+
+func (e *HandlerPanicError) Unwrap() error {
+	return ErrHandlerPanic
+}
+
+// ErrChainReorg is the sentinel error matched by ChainReorgError.
+var ErrChainReorg = errors.New("chain reorg")
+
+// ChainReorgError is returned when a processed block is replaced on the chain. Height is the lowest
+// processed height whose block hash differs from the chain, so the states from the height must be rolled back.
+type ChainReorgError struct {
+	Height     int64
+	StoredHash []byte
+	ChainHash  []byte
+}
+
+func (e *ChainReorgError) Error() string {
+	return fmt.Sprintf("%s: divergence height: %d, stored hash: %X, chain hash: %X", ErrChainReorg.Error(), e.Height, e.StoredHash, e.ChainHash)
+}
+
+func (e *ChainReorgError) Unwrap() error {
+	return ErrChainReorg
+}
+
+// IsTransientError returns true if the looper can be restarted after the error.
+// The rpc calls exceeding the timeout are transient, as the node may recover.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, ErrFatalDB) || errors.Is(err, ErrHandlerPanic) {
+		return false
+	}
+	return errors.Is(err, ErrTransientRPC) || errors.Is(err, ErrHandlerFailure) || errors.Is(err, context.DeadlineExceeded)
+}
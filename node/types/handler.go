@@ -19,6 +19,10 @@ type EventHandlerArgs struct {
 
 type EventHandlerFn func(context.Context, EventHandlerArgs) error
 
+// EventKeyFn extracts the key identifying the event. The events with the same key
+// in the same tx are handled only once.
+type EventKeyFn func([]abcitypes.EventAttribute) (string, error)
+
 type TxHandlerArgs struct {
 	BlockHeight  int64
 	BlockTime    time.Time
@@ -30,12 +34,29 @@ type TxHandlerArgs struct {
 
 type TxHandlerFn func(context.Context, TxHandlerArgs) error
 
+type TxConfirmedArgs struct {
+	BlockHeight int64
+	BlockTime   time.Time
+	TxHash      string
+	GasUsed     int64
+	// TraceID is the trace id of the processed msgs included in the tx.
+	TraceID  string
+	Sender   string
+	MsgTypes []string
+	Events   []abcitypes.Event
+}
+
+// TxConfirmedHandlerFn is called when a pending tx broadcasted by the node is included in a block.
+type TxConfirmedHandlerFn func(context.Context, TxConfirmedArgs) error
+
 type BeginBlockArgs struct {
 	BlockID      []byte
 	Block        cmtproto.Block
 	LatestHeight int64
 }
 
+// BeginBlockHandlerFn can return ErrRetryBlock to re-process the same height after a backoff.
+// All the handlers of the height are re-invoked, so they must be idempotent.
 type BeginBlockHandlerFn func(context.Context, BeginBlockArgs) error
 
 type EndBlockArgs struct {
@@ -44,12 +65,43 @@ type EndBlockArgs struct {
 	LatestHeight int64
 }
 
+// EndBlockHandlerFn can return ErrRetryBlock to re-process the same height after a backoff.
+// All the handlers of the height are re-invoked, so they must be idempotent.
 type EndBlockHandlerFn func(context.Context, EndBlockArgs) error
 
 type RawBlockArgs struct {
 	BlockHeight  int64
+	BlockTime    time.Time
 	LatestHeight int64
-	BlockBytes   []byte
+	// BlockBytes is the protobuf encoded block(cmtproto.Block).
+	BlockBytes []byte
+	// Txs are the raw tx bytes included in the block.
+	Txs [][]byte
 }
 
 type RawBlockHandlerFn func(context.Context, RawBlockArgs) error
+
+type SkipBlockArgs struct {
+	BlockHeight int64
+}
+
+// SkipBlockHandlerFn is called instead of the other handlers when the block is skipped, to carry the state
+// of the previous height forward to the skipped height. The skip fails the block if it returns an error.
+type SkipBlockHandlerFn func(context.Context, SkipBlockArgs) error
+
+type RestartArgs struct {
+	Height  int64
+	Attempt int
+	Backoff time.Duration
+	Err     error
+}
+
+type RestartHandlerFn func(context.Context, RestartArgs)
+
+type RewindArgs struct {
+	// Height is the height to rewind to; the blocks after it are processed again.
+	Height              int64
+	LastProcessedHeight int64
+}
+
+type RewindHandlerFn func(context.Context, RewindArgs) error
@@ -1,6 +1,19 @@
 package types
 
+import (
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+)
+
 var (
 	// Keys
 	LastProcessedBlockHeightKey = []byte("last_processed_block_height")
 )
+
+var (
+	// BlockHashKey is the prefix of the block hashes of the last processed heights.
+	BlockHashKey = []byte("block_hash")
+)
+
+func PrefixedBlockHash(height uint64) []byte {
+	return append(append(BlockHashKey, dbtypes.Splitter), dbtypes.FromUint64Key(height)...)
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
 )
 
@@ -8,3 +10,36 @@ type Status struct {
 	LastBlockHeight int64                     `json:"last_block_height,omitempty"`
 	Broadcaster     *btypes.BroadcasterStatus `json:"broadcaster,omitempty"`
 }
+
+// NodeStatus is the snapshot of the node status.
+// The json field names are kept stable for the dashboards.
+type NodeStatus struct {
+	ChainID             string                    `json:"chain_id"`
+	LatestChainHeight   int64                     `json:"latest_chain_height"`
+	LastProcessedHeight int64                     `json:"last_processed_height"`
+	LastError           string                    `json:"last_error"`
+	LastErrorTime       *time.Time                `json:"last_error_time"`
+	Broadcaster         *btypes.BroadcasterStatus `json:"broadcaster"`
+
+	// LastFatalErrorTime is the time of the last error other than the rpc errors, e.g. a handler failure.
+	LastFatalErrorTime *time.Time `json:"last_fatal_error_time"`
+	// LastProgressTime is the time when the block process loop processed a block or caught up the chain.
+	LastProgressTime *time.Time `json:"last_progress_time"`
+
+	// CatchingUp is true while the bot waits for the node to catch up the chain at the start,
+	// and the broadcaster and the block process are not started yet.
+	CatchingUp bool `json:"catching_up"`
+	// CatchingUpSince is the time when the bot started to wait for the node to catch up the chain.
+	CatchingUpSince *time.Time `json:"catching_up_since,omitempty"`
+
+	// ProcessType is the process type of the node, which selects the active subsystems.
+	ProcessType string `json:"process_type"`
+	// Subsystems are the active subsystems of the node, "block_sync" and "broadcaster".
+	// The heights are not tracked without the block sync.
+	Subsystems []string `json:"subsystems"`
+}
+
+const (
+	SubsystemBlockSync   = "block_sync"
+	SubsystemBroadcaster = "broadcaster"
+)
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"slices"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -31,7 +35,9 @@ type BaseChild struct {
 	node *node.Node
 	mk   *merkle.Merkle
 
-	bridgeInfo ophosttypes.QueryBridgeResponse
+	// bridge info can be updated by the host events while the other components read it
+	bridgeInfoMu *sync.RWMutex
+	bridgeInfo   *ophosttypes.QueryBridgeResponse
 
 	initializeTreeFn func(int64) (bool, error)
 
@@ -43,6 +49,8 @@ type BaseChild struct {
 
 	processedMsgs []btypes.ProcessedMsgs
 	msgQueue      map[string][]sdk.Msg
+	// limits of a tx when the msg queue is flushed
+	msgQueueLimits btypes.MsgQueueLimits
 
 	baseAccountIndex     int
 	oracleAccountIndex   int
@@ -52,20 +60,20 @@ type BaseChild struct {
 func NewBaseChildV1(
 	cfg nodetypes.NodeConfig,
 	db types.DB, logger *zap.Logger,
-) *BaseChild {
-	appCodec, txConfig, err := GetCodec(cfg.Bech32Prefix)
+) (*BaseChild, error) {
+	appCodec, txConfig, err := GetCodec(cfg.Bech32Prefix, cfg.CodecModules...)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	node, err := node.NewNode(cfg, db, logger, appCodec, txConfig)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	mk, err := merkle.NewMerkle(db.WithPrefix([]byte(types.MerkleName)), ophosttypes.GenerateNodeHash)
+	mk, err := merkle.NewMerkle(db.WithPrefix([]byte(types.MerkleName)), logger.Named(types.MerkleName), ophosttypes.GenerateNodeHash)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	ch := &BaseChild{
@@ -74,11 +82,14 @@ func NewBaseChildV1(
 		node: node,
 		mk:   mk,
 
+		bridgeInfoMu: &sync.RWMutex{},
+		bridgeInfo:   &ophosttypes.QueryBridgeResponse{},
+
 		cfg:    cfg,
 		db:     db,
 		logger: logger,
 
-		opchildQueryClient: opchildtypes.NewQueryClient(node.GetRPCClient()),
+		opchildQueryClient: opchildtypes.NewQueryClient(node.QueryConn()),
 
 		processedMsgs: make([]btypes.ProcessedMsgs, 0),
 		msgQueue:      make(map[string][]sdk.Msg),
@@ -86,18 +97,17 @@ func NewBaseChildV1(
 		baseAccountIndex:   -1,
 		oracleAccountIndex: -1,
 	}
-	return ch
+	return ch, nil
 }
 
-func GetCodec(bech32Prefix string) (codec.Codec, client.TxConfig, error) {
-	unlock := keys.SetSDKConfigContext(bech32Prefix)
-	defer unlock()
-
-	return keys.CreateCodec([]keys.RegisterInterfaces{
+// GetCodec returns the codec of the child chain, which has the interfaces of the given module sets
+// in addition to the child interfaces. The codec is shared by the components of the same chain.
+func GetCodec(bech32Prefix string, modules ...string) (codec.Codec, client.TxConfig, error) {
+	return keys.GetCachedCodec(types.ChildName, bech32Prefix, []keys.RegisterInterfaces{
 		auth.AppModuleBasic{}.RegisterInterfaces,
 		authz.RegisterInterfaces,
 		opchild.AppModuleBasic{}.RegisterInterfaces,
-	})
+	}, modules)
 }
 
 func (b *BaseChild) Initialize(
@@ -107,11 +117,12 @@ func (b *BaseChild) Initialize(
 	bridgeInfo ophosttypes.QueryBridgeResponse,
 	keyringConfig *btypes.KeyringConfig,
 	oracleKeyringConfig *btypes.KeyringConfig,
+	routedKeyringConfigs []btypes.KeyringConfig,
 	disableDeleteFutureWithdrawals bool,
 ) (uint64, error) {
 	b.SetBridgeInfo(bridgeInfo)
 
-	err := b.node.Initialize(ctx, processedHeight, b.keyringConfigs(keyringConfig, oracleKeyringConfig))
+	err := b.node.Initialize(ctx, processedHeight, b.keyringConfigs(keyringConfig, oracleKeyringConfig, routedKeyringConfigs))
 	if err != nil {
 		return 0, err
 	}
@@ -125,11 +136,6 @@ func (b *BaseChild) Initialize(
 				if err != nil {
 					return 0, err
 				}
-
-				err = b.mk.DeleteFutureFinalizedTrees(l2Sequence)
-				if err != nil {
-					return 0, err
-				}
 			}
 			b.initializeTreeFn = func(blockHeight int64) (bool, error) {
 				if processedHeight+1 == blockHeight {
@@ -143,16 +149,10 @@ func (b *BaseChild) Initialize(
 				return false, nil
 			}
 		}
-
-		version := types.MustInt64ToUint64(processedHeight)
-		err = b.mk.DeleteFutureWorkingTrees(version + 1)
-		if err != nil {
-			return 0, err
-		}
 	}
 
 	if b.OracleEnabled() && oracleKeyringConfig != nil {
-		executors, err := b.QueryExecutors(ctx)
+		executors, err := b.QueryExecutors(ctx, 0)
 		if err != nil {
 			return 0, err
 		}
@@ -188,17 +188,42 @@ func (b *BaseChild) Initialize(
 	return l2Sequence, nil
 }
 
+// DeleteFutureWorkingTrees deletes the working trees after the processed height, which are built again by the synced blocks.
+// It is called after the destructive rewind is confirmed, not to delete the trees of the refused rewind.
+func (b BaseChild) DeleteFutureWorkingTrees(processedHeight int64) error {
+	if !b.node.HeightInitialized() {
+		return nil
+	}
+	version := types.MustInt64ToUint64(processedHeight)
+	return b.mk.DeleteFutureWorkingTrees(version + 1)
+}
+
 func (b *BaseChild) Start(ctx context.Context) {
 	b.logger.Info("child start", zap.Int64("height", b.Height()))
 	b.node.Start(ctx)
 }
 
-func (b BaseChild) BroadcastMsgs(msgs btypes.ProcessedMsgs) {
+func (b BaseChild) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
 	if len(msgs.Msgs) == 0 {
-		return
+		return nil
 	}
 
-	b.node.MustGetBroadcaster().BroadcastMsgs(msgs)
+	return b.node.MustGetBroadcaster().BroadcastMsgs(msgs)
+}
+
+// DropQueuedOracleMsgs removes the oracle msgs waiting to be broadcasted, which are superseded
+// by a fresher oracle update, and returns the number of the removed msgs.
+func (b BaseChild) DropQueuedOracleMsgs() (int, error) {
+	oracleAddress, err := b.OracleAccountAddressString()
+	if errors.Is(err, types.ErrKeyNotSet) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return b.node.MustGetBroadcaster().DropQueuedMsgs(oracleAddress, func(msgs btypes.ProcessedMsgs) bool {
+		return msgs.Lane == btypes.OracleLane
+	})
 }
 
 func (b BaseChild) ProcessedMsgsToRawKV(msgs []btypes.ProcessedMsgs, delete bool) ([]types.RawKV, error) {
@@ -210,10 +235,14 @@ func (b BaseChild) ProcessedMsgsToRawKV(msgs []btypes.ProcessedMsgs, delete bool
 }
 
 func (b BaseChild) BridgeId() uint64 {
+	b.bridgeInfoMu.RLock()
+	defer b.bridgeInfoMu.RUnlock()
 	return b.bridgeInfo.BridgeId
 }
 
 func (b BaseChild) OracleEnabled() bool {
+	b.bridgeInfoMu.RLock()
+	defer b.bridgeInfoMu.RUnlock()
 	return b.bridgeInfo.BridgeConfig.OracleEnabled
 }
 
@@ -222,11 +251,15 @@ func (b BaseChild) HasKey() bool {
 }
 
 func (b *BaseChild) SetBridgeInfo(bridgeInfo ophosttypes.QueryBridgeResponse) {
-	b.bridgeInfo = bridgeInfo
+	b.bridgeInfoMu.Lock()
+	defer b.bridgeInfoMu.Unlock()
+	*b.bridgeInfo = bridgeInfo
 }
 
 func (b BaseChild) BridgeInfo() ophosttypes.QueryBridgeResponse {
-	return b.bridgeInfo
+	b.bridgeInfoMu.RLock()
+	defer b.bridgeInfoMu.RUnlock()
+	return *b.bridgeInfo
 }
 
 func (b BaseChild) Height() int64 {
@@ -259,12 +292,44 @@ func (b *BaseChild) AppendMsgQueue(msg sdk.Msg, sender string) {
 	b.msgQueue[sender] = append(b.msgQueue[sender], msg)
 }
 
+func (b BaseChild) MsgQueueLimits() btypes.MsgQueueLimits {
+	return b.msgQueueLimits
+}
+
+func (b *BaseChild) SetMsgQueueLimits(limits btypes.MsgQueueLimits) {
+	b.msgQueueLimits = limits
+}
+
 func (b *BaseChild) EmptyMsgQueue() {
 	for sender := range b.msgQueue {
 		b.msgQueue[sender] = b.msgQueue[sender][:0]
 	}
 }
 
+// FlushMsgQueue splits the msg queue of each sender into the processed msgs fitting the msg queue limits.
+// The order of the msgs of a sender is preserved.
+func (b *BaseChild) FlushMsgQueue() error {
+	senders := maps.Keys(b.msgQueue)
+	slices.Sort(senders)
+
+	for _, sender := range senders {
+		chunks, err := b.msgQueueLimits.SplitMsgs(b.msgQueue[sender])
+		if err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			b.AppendProcessedMsgs(btypes.ProcessedMsgs{
+				Sender:    sender,
+				Msgs:      slices.Clone(chunk),
+				Timestamp: time.Now().UnixNano(),
+				Save:      true,
+			})
+		}
+	}
+	return nil
+}
+
 /// ProcessedMsgs
 
 func (b BaseChild) GetProcessedMsgs() []btypes.ProcessedMsgs {
@@ -272,7 +337,7 @@ func (b BaseChild) GetProcessedMsgs() []btypes.ProcessedMsgs {
 }
 
 func (b *BaseChild) AppendProcessedMsgs(msgs btypes.ProcessedMsgs) {
-	b.processedMsgs = append(b.processedMsgs, msgs)
+	b.processedMsgs = append(b.processedMsgs, msgs.WithTraceID())
 }
 
 func (b *BaseChild) EmptyProcessedMsgs() {
@@ -306,7 +371,7 @@ func (b BaseChild) GetWorkingTreeLeafCount() (uint64, error) {
 	return b.mk.GetWorkingTreeLeafCount()
 }
 
-func (b *BaseChild) keyringConfigs(baseConfig *btypes.KeyringConfig, oracleConfig *btypes.KeyringConfig) []btypes.KeyringConfig {
+func (b *BaseChild) keyringConfigs(baseConfig *btypes.KeyringConfig, oracleConfig *btypes.KeyringConfig, routedConfigs []btypes.KeyringConfig) []btypes.KeyringConfig {
 	var configs []btypes.KeyringConfig
 	if baseConfig != nil {
 		configs = append(configs, *baseConfig)
@@ -316,6 +381,10 @@ func (b *BaseChild) keyringConfigs(baseConfig *btypes.KeyringConfig, oracleConfi
 		configs = append(configs, *oracleConfig)
 		b.oracleAccountIndex = len(configs) - 1
 	}
+	// routed accounts sign the msgs of the configured msg types instead of the base account
+	if baseConfig != nil {
+		configs = append(configs, routedConfigs...)
+	}
 	return configs
 }
 
@@ -335,6 +404,22 @@ func (b BaseChild) BaseAccountAddressString() (string, error) {
 	return sender, nil
 }
 
+// AccountAddressStringByMsgType returns the address of the account routed to the msg type.
+// If there is no route for the msg type, it returns the base account address.
+func (b BaseChild) AccountAddressStringByMsgType(msgType string) (string, error) {
+	broadcaster, err := b.node.GetBroadcaster()
+	if err != nil {
+		return "", err
+	}
+	account, err := broadcaster.AccountByMsgType(msgType)
+	if errors.Is(err, types.ErrKeyNotSet) {
+		return b.BaseAccountAddressString()
+	} else if err != nil {
+		return "", err
+	}
+	return account.GetAddressString(), nil
+}
+
 func (b BaseChild) OracleAccountAddressString() (string, error) {
 	broadcaster, err := b.node.GetBroadcaster()
 	if err != nil {
@@ -19,7 +19,7 @@ func (b BaseChild) GetMsgFinalizeTokenDeposit(
 	l1Denom string,
 	data []byte,
 ) (sdk.Msg, string, error) {
-	sender, err := b.BaseAccountAddressString()
+	sender, err := b.AccountAddressStringByMsgType(sdk.MsgTypeURL(&opchildtypes.MsgFinalizeTokenDeposit{}))
 	if err != nil {
 		if errors.Is(err, types.ErrKeyNotSet) {
 			return nil, "", nil
@@ -101,6 +101,17 @@ func ParseUpdateOracle(eventAttrs []abcitypes.EventAttribute) (
 	return
 }
 
+// InitiateWithdrawalKey returns the l2 sequence of the initiate withdrawal event as the event key,
+// so a withdrawal emitted twice in a tx is handled once.
+func InitiateWithdrawalKey(eventAttrs []abcitypes.EventAttribute) (string, error) {
+	for _, attr := range eventAttrs {
+		if attr.Key == opchildtypes.AttributeKeyL2Sequence {
+			return attr.Value, nil
+		}
+	}
+	return "", missingAttrsError(map[string]struct{}{opchildtypes.AttributeKeyL2Sequence: {}})
+}
+
 func ParseInitiateWithdrawal(eventAttrs []abcitypes.EventAttribute) (
 	l2Sequence, amount uint64,
 	from, to, baseDenom string,
@@ -141,3 +152,24 @@ func ParseInitiateWithdrawal(eventAttrs []abcitypes.EventAttribute) (
 	err = missingAttrsError(missingAttrs)
 	return
 }
+
+func ParseSetBridgeInfo(eventAttrs []abcitypes.EventAttribute) (bridgeId uint64, err error) {
+	missingAttrs := map[string]struct{}{
+		opchildtypes.AttributeKeyBridgeId: {},
+	}
+
+	for _, attr := range eventAttrs {
+		switch attr.Key {
+		case opchildtypes.AttributeKeyBridgeId:
+			bridgeId, err = strconv.ParseUint(attr.Value, 10, 64)
+			if err != nil {
+				return
+			}
+		default:
+			continue
+		}
+		delete(missingAttrs, attr.Key)
+	}
+	err = missingAttrsError(missingAttrs)
+	return
+}
@@ -2,20 +2,23 @@ package child
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
+	abcitypes "github.com/cometbft/cometbft/abci/types"
 	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
 
 	"github.com/cosmos/cosmos-sdk/types/query"
 	"github.com/cosmos/cosmos-sdk/x/authz"
 
-	"github.com/initia-labs/opinit-bots/node/rpcclient"
 	"github.com/initia-labs/opinit-bots/types"
 )
 
-func (b BaseChild) QueryBridgeInfo(ctx context.Context) (opchildtypes.BridgeInfo, error) {
+// QueryBridgeInfo queries the bridge info at the given height. 0 means the latest height.
+func (b BaseChild) QueryBridgeInfo(ctx context.Context, height int64) (opchildtypes.BridgeInfo, error) {
 	req := &opchildtypes.QueryBridgeInfoRequest{}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
+	ctx, cancel := b.node.QueryContext(ctx, height)
 	defer cancel()
 
 	res, err := b.opchildQueryClient.BridgeInfo(ctx, req)
@@ -27,7 +30,7 @@ func (b BaseChild) QueryBridgeInfo(ctx context.Context) (opchildtypes.BridgeInfo
 
 func (b BaseChild) QueryNextL1Sequence(ctx context.Context, height int64) (uint64, error) {
 	req := &opchildtypes.QueryNextL1SequenceRequest{}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, height)
+	ctx, cancel := b.node.QueryContext(ctx, height)
 	defer cancel()
 
 	res, err := b.opchildQueryClient.NextL1Sequence(ctx, req)
@@ -39,7 +42,7 @@ func (b BaseChild) QueryNextL1Sequence(ctx context.Context, height int64) (uint6
 
 func (b BaseChild) QueryNextL2Sequence(ctx context.Context, height int64) (uint64, error) {
 	req := &opchildtypes.QueryNextL2SequenceRequest{}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, height)
+	ctx, cancel := b.node.QueryContext(ctx, height)
 	defer cancel()
 
 	res, err := b.opchildQueryClient.NextL2Sequence(ctx, req)
@@ -49,9 +52,43 @@ func (b BaseChild) QueryNextL2Sequence(ctx context.Context, height int64) (uint6
 	return res.NextL2Sequence, nil
 }
 
-func (b BaseChild) QueryExecutors(ctx context.Context) ([]string, error) {
+// QueryWithdrawalEvent searches the withdrawal tx of the given l2 sequence and returns the attributes
+// of the withdrawal event. The l2 node must index the txs.
+func (b BaseChild) QueryWithdrawalEvent(ctx context.Context, l2Sequence uint64) ([]abcitypes.EventAttribute, error) {
+	ctx, cancel := b.node.QueryContext(ctx, 0)
+	defer cancel()
+
+	query := fmt.Sprintf("%s.%s = %d",
+		opchildtypes.EventTypeInitiateTokenWithdrawal,
+		opchildtypes.AttributeKeyL2Sequence,
+		l2Sequence,
+	)
+	perPage := 1
+	res, err := b.node.GetRPCClient().TxSearch(ctx, query, false, nil, &perPage, "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	l2SequenceStr := strconv.FormatUint(l2Sequence, 10)
+	for _, tx := range res.Txs {
+		for _, event := range tx.TxResult.Events {
+			if event.Type != opchildtypes.EventTypeInitiateTokenWithdrawal {
+				continue
+			}
+			for _, attr := range event.Attributes {
+				if attr.Key == opchildtypes.AttributeKeyL2Sequence && attr.Value == l2SequenceStr {
+					return event.Attributes, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("withdrawal tx not found; l2 sequence: %d", l2Sequence)
+}
+
+// QueryExecutors queries the bridge executors at the given height. 0 means the latest height.
+func (b BaseChild) QueryExecutors(ctx context.Context, height int64) ([]string, error) {
 	req := &opchildtypes.QueryParamsRequest{}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
+	ctx, cancel := b.node.QueryContext(ctx, height)
 	defer cancel()
 
 	res, err := b.opchildQueryClient.Params(ctx, req)
@@ -67,10 +104,10 @@ func (b BaseChild) QueryGrantsRequest(ctx context.Context, granter, grantee, msg
 		Grantee:    grantee,
 		MsgTypeUrl: msgTypeUrl,
 	}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
+	ctx, cancel := b.node.QueryContext(ctx, 0)
 	defer cancel()
 
-	authzClient := authz.NewQueryClient(b.node.GetRPCClient())
+	authzClient := authz.NewQueryClient(b.node.QueryConn())
 	res, err := authzClient.Grants(ctx, req)
 	if err != nil {
 		return nil, err
@@ -85,10 +122,7 @@ func (b BaseChild) QueryGranteeGrants(ctx context.Context, grantee string) ([]*a
 			Limit: 100,
 		},
 	}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
-	defer cancel()
-
-	authzClient := authz.NewQueryClient(b.node.GetRPCClient())
+	authzClient := authz.NewQueryClient(b.node.QueryConn())
 
 	ticker := time.NewTicker(types.PollingInterval(ctx))
 	defer ticker.Stop()
@@ -102,7 +136,10 @@ func (b BaseChild) QueryGranteeGrants(ctx context.Context, grantee string) ([]*a
 		case <-ticker.C:
 		}
 
-		res, err := authzClient.GranteeGrants(ctx, req)
+		// each page is bounded by its own timeout
+		queryCtx, cancel := b.node.QueryContext(ctx, 0)
+		res, err := authzClient.GranteeGrants(queryCtx, req)
+		cancel()
 		if err != nil {
 			return nil, err
 		}
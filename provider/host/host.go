@@ -3,8 +3,12 @@ package host
 import (
 	"context"
 	"errors"
+	"slices"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -26,7 +30,9 @@ type BaseHost struct {
 
 	node *node.Node
 
-	bridgeInfo ophosttypes.QueryBridgeResponse
+	// bridge info can be updated by the host events while the other components read it
+	bridgeInfoMu *sync.RWMutex
+	bridgeInfo   *ophosttypes.QueryBridgeResponse
 
 	cfg    nodetypes.NodeConfig
 	db     types.DB
@@ -36,19 +42,24 @@ type BaseHost struct {
 
 	processedMsgs []btypes.ProcessedMsgs
 	msgQueue      map[string][]sdk.Msg
+	// limits of a tx when the msg queue is flushed
+	msgQueueLimits btypes.MsgQueueLimits
+
+	// accounts signing the msgs of the configured msg types instead of the base account
+	routedKeyringConfigs []btypes.KeyringConfig
 }
 
 func NewBaseHostV1(cfg nodetypes.NodeConfig,
 	db types.DB, logger *zap.Logger,
-) *BaseHost {
-	appCodec, txConfig, err := GetCodec(cfg.Bech32Prefix)
+) (*BaseHost, error) {
+	appCodec, txConfig, err := GetCodec(cfg.Bech32Prefix, cfg.CodecModules...)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	node, err := node.NewNode(cfg, db, logger, appCodec, txConfig)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	h := &BaseHost{
@@ -56,30 +67,41 @@ func NewBaseHostV1(cfg nodetypes.NodeConfig,
 
 		node: node,
 
+		bridgeInfoMu: &sync.RWMutex{},
+		bridgeInfo:   &ophosttypes.QueryBridgeResponse{},
+
 		cfg:    cfg,
 		db:     db,
 		logger: logger,
 
-		ophostQueryClient: ophosttypes.NewQueryClient(node.GetRPCClient()),
+		ophostQueryClient: ophosttypes.NewQueryClient(node.QueryConn()),
 
 		processedMsgs: make([]btypes.ProcessedMsgs, 0),
 		msgQueue:      make(map[string][]sdk.Msg),
 	}
 
-	return h
+	return h, nil
 }
 
-func GetCodec(bech32Prefix string) (codec.Codec, client.TxConfig, error) {
-	unlock := keys.SetSDKConfigContext(bech32Prefix)
-	defer unlock()
-
-	return keys.CreateCodec([]keys.RegisterInterfaces{
+// GetCodec returns the codec of the host chain, which has the interfaces of the given module sets
+// in addition to the host interfaces. The codec is shared by the components of the same chain.
+func GetCodec(bech32Prefix string, modules ...string) (codec.Codec, client.TxConfig, error) {
+	return keys.GetCachedCodec(types.HostName, bech32Prefix, []keys.RegisterInterfaces{
 		auth.AppModuleBasic{}.RegisterInterfaces,
 		ophost.AppModuleBasic{}.RegisterInterfaces,
-	})
+	}, modules)
 }
 
-func (b *BaseHost) Initialize(ctx context.Context, processedHeight int64, bridgeInfo ophosttypes.QueryBridgeResponse, keyringConfig *btypes.KeyringConfig) error {
+// Initialize initializes the node. If the expected chain info is given, the chain id and the bridge
+// are verified with it before the node is initialized.
+func (b *BaseHost) Initialize(ctx context.Context, processedHeight int64, bridgeInfo ophosttypes.QueryBridgeResponse, expectedChainInfo *ExpectedChainInfo, keyringConfig *btypes.KeyringConfig) error {
+	if expectedChainInfo != nil {
+		err := b.checkChainInfo(ctx, bridgeInfo, *expectedChainInfo)
+		if err != nil {
+			return err
+		}
+	}
+
 	err := b.node.Initialize(ctx, processedHeight, b.keyringConfigs(keyringConfig))
 	if err != nil {
 		return err
@@ -89,20 +111,54 @@ func (b *BaseHost) Initialize(ctx context.Context, processedHeight int64, bridge
 }
 
 func (b *BaseHost) Start(ctx context.Context) {
-	if b.cfg.ProcessType == nodetypes.PROCESS_TYPE_ONLY_BROADCAST {
-		b.logger.Info("host start")
-	} else {
+	if b.cfg.ProcessType.SyncsBlocks() {
 		b.logger.Info("host start", zap.Int64("height", b.node.GetHeight()))
+	} else {
+		b.logger.Info("host start")
 	}
 	b.node.Start(ctx)
 }
 
-func (b BaseHost) BroadcastMsgs(msgs btypes.ProcessedMsgs) {
+func (b BaseHost) BroadcastMsgs(msgs btypes.ProcessedMsgs) error {
 	if len(msgs.Msgs) == 0 {
-		return
+		return nil
 	}
 
-	b.node.MustGetBroadcaster().BroadcastMsgs(msgs)
+	return b.node.MustGetBroadcaster().BroadcastMsgs(msgs)
+}
+
+// RewriteQueuedBridgeMsgs replaces the old bridge id of the msgs waiting to be broadcasted by the base account
+// with the new bridge id, and returns the number of the rewritten msgs.
+func (b BaseHost) RewriteQueuedBridgeMsgs(oldBridgeId, newBridgeId uint64) (int, error) {
+	sender, err := b.BaseAccountAddressString()
+	if err != nil {
+		return 0, err
+	} else if sender == "" {
+		return 0, nil
+	}
+
+	return b.node.MustGetBroadcaster().RewriteQueuedMsgs(sender, func(msg sdk.Msg) (sdk.Msg, bool) {
+		switch msg := msg.(type) {
+		case *ophosttypes.MsgProposeOutput:
+			if msg.BridgeId == oldBridgeId {
+				rewritten := *msg
+				rewritten.BridgeId = newBridgeId
+				return &rewritten, true
+			}
+		case *ophosttypes.MsgFinalizeTokenWithdrawal:
+			if msg.BridgeId == oldBridgeId {
+				rewritten := *msg
+				rewritten.BridgeId = newBridgeId
+				return &rewritten, true
+			}
+		}
+		return msg, false
+	})
+}
+
+// CheckBalance returns ErrInsufficientBalance if the account of the address can't pay the fees of the queued txs.
+func (b BaseHost) CheckBalance(ctx context.Context, address string) error {
+	return b.node.MustGetBroadcaster().CheckBalance(ctx, address)
 }
 
 func (b BaseHost) ProcessedMsgsToRawKV(msgs []btypes.ProcessedMsgs, delete bool) ([]types.RawKV, error) {
@@ -113,20 +169,40 @@ func (b BaseHost) ProcessedMsgsToRawKV(msgs []btypes.ProcessedMsgs, delete bool)
 	return b.node.MustGetBroadcaster().ProcessedMsgsToRawKV(msgs, delete)
 }
 
+func (b BaseHost) UnconfirmedTraceIDs() (map[string]struct{}, error) {
+	return b.node.MustGetBroadcaster().UnconfirmedTraceIDs()
+}
+
+func (b BaseHost) DeadLetterTraceIDs() (map[string]struct{}, error) {
+	return b.node.MustGetBroadcaster().DeadLetterTraceIDs()
+}
+
+func (b BaseHost) RegisterTxConfirmedHandler(fn nodetypes.TxConfirmedHandlerFn) {
+	b.node.RegisterTxConfirmedHandler(fn)
+}
+
 func (b BaseHost) BridgeId() uint64 {
+	b.bridgeInfoMu.RLock()
+	defer b.bridgeInfoMu.RUnlock()
 	return b.bridgeInfo.BridgeId
 }
 
 func (b BaseHost) OracleEnabled() bool {
+	b.bridgeInfoMu.RLock()
+	defer b.bridgeInfoMu.RUnlock()
 	return b.bridgeInfo.BridgeConfig.OracleEnabled
 }
 
 func (b *BaseHost) SetBridgeInfo(bridgeInfo ophosttypes.QueryBridgeResponse) {
-	b.bridgeInfo = bridgeInfo
+	b.bridgeInfoMu.Lock()
+	defer b.bridgeInfoMu.Unlock()
+	*b.bridgeInfo = bridgeInfo
 }
 
 func (b BaseHost) BridgeInfo() ophosttypes.QueryBridgeResponse {
-	return b.bridgeInfo
+	b.bridgeInfoMu.RLock()
+	defer b.bridgeInfoMu.RUnlock()
+	return *b.bridgeInfo
 }
 
 func (b BaseHost) HasKey() bool {
@@ -145,6 +221,10 @@ func (b BaseHost) Node() *node.Node {
 	return b.node
 }
 
+func (b BaseHost) ChainId() string {
+	return b.cfg.ChainID
+}
+
 func (b BaseHost) Logger() *zap.Logger {
 	return b.logger
 }
@@ -163,12 +243,44 @@ func (b *BaseHost) AppendMsgQueue(msg sdk.Msg, sender string) {
 	b.msgQueue[sender] = append(b.msgQueue[sender], msg)
 }
 
+func (b BaseHost) MsgQueueLimits() btypes.MsgQueueLimits {
+	return b.msgQueueLimits
+}
+
+func (b *BaseHost) SetMsgQueueLimits(limits btypes.MsgQueueLimits) {
+	b.msgQueueLimits = limits
+}
+
 func (b *BaseHost) EmptyMsgQueue() {
 	for sender := range b.msgQueue {
 		b.msgQueue[sender] = b.msgQueue[sender][:0]
 	}
 }
 
+// FlushMsgQueue splits the msg queue of each sender into the processed msgs fitting the msg queue limits.
+// The order of the msgs of a sender is preserved.
+func (b *BaseHost) FlushMsgQueue() error {
+	senders := maps.Keys(b.msgQueue)
+	slices.Sort(senders)
+
+	for _, sender := range senders {
+		chunks, err := b.msgQueueLimits.SplitMsgs(b.msgQueue[sender])
+		if err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			b.AppendProcessedMsgs(btypes.ProcessedMsgs{
+				Sender:    sender,
+				Msgs:      slices.Clone(chunk),
+				Timestamp: time.Now().UnixNano(),
+				Save:      true,
+			})
+		}
+	}
+	return nil
+}
+
 /// ProcessedMsgs
 
 func (b BaseHost) GetProcessedMsgs() []btypes.ProcessedMsgs {
@@ -176,7 +288,7 @@ func (b BaseHost) GetProcessedMsgs() []btypes.ProcessedMsgs {
 }
 
 func (b *BaseHost) AppendProcessedMsgs(msgs btypes.ProcessedMsgs) {
-	b.processedMsgs = append(b.processedMsgs, msgs)
+	b.processedMsgs = append(b.processedMsgs, msgs.WithTraceID())
 }
 
 func (b *BaseHost) EmptyProcessedMsgs() {
@@ -199,10 +311,36 @@ func (b BaseHost) BaseAccountAddressString() (string, error) {
 	return sender, nil
 }
 
+// AccountAddressStringByMsgType returns the address of the account routed to the msg type.
+// If there is no route for the msg type, it returns the base account address.
+func (b BaseHost) AccountAddressStringByMsgType(msgType string) (string, error) {
+	broadcaster, err := b.node.GetBroadcaster()
+	if err != nil {
+		if errors.Is(err, types.ErrKeyNotSet) {
+			return "", nil
+		}
+		return "", err
+	}
+	account, err := broadcaster.AccountByMsgType(msgType)
+	if errors.Is(err, types.ErrKeyNotSet) {
+		return b.BaseAccountAddressString()
+	} else if err != nil {
+		return "", err
+	}
+	return account.GetAddressString(), nil
+}
+
+// SetRoutedKeyringConfigs sets the accounts signing the msgs of their msg types, which are
+// added to the broadcaster of the base account at the initialization.
+func (b *BaseHost) SetRoutedKeyringConfigs(configs []btypes.KeyringConfig) {
+	b.routedKeyringConfigs = configs
+}
+
 func (b BaseHost) keyringConfigs(baseConfig *btypes.KeyringConfig) []btypes.KeyringConfig {
 	var configs []btypes.KeyringConfig
 	if baseConfig != nil {
 		configs = append(configs, *baseConfig)
+		configs = append(configs, b.routedKeyringConfigs...)
 	}
 	return configs
 }
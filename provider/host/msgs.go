@@ -1,6 +1,7 @@
 package host
 
 import (
+	"context"
 	"errors"
 
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
@@ -37,6 +38,99 @@ func (b BaseHost) GetMsgProposeOutput(
 	return msg, sender, nil
 }
 
+// GetMsgDeleteOutput builds the msg deleting the output of the bridge, signed by the base account of the host,
+// which must be the challenger of the bridge.
+func (b BaseHost) GetMsgDeleteOutput(
+	bridgeId uint64,
+	outputIndex uint64,
+) (sdk.Msg, string, error) {
+	challenger, err := b.BaseAccountAddressString()
+	if err != nil {
+		if errors.Is(err, types.ErrKeyNotSet) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	} else if challenger == "" {
+		return nil, "", nil
+	}
+
+	msg := ophosttypes.NewMsgDeleteOutput(
+		challenger,
+		bridgeId,
+		outputIndex,
+	)
+	err = msg.Validate(b.node.AccountCodec())
+	if err != nil {
+		return nil, "", err
+	}
+	return msg, challenger, nil
+}
+
+// GetMsgFinalizeTokenWithdrawal builds the msg claiming the withdrawal of `sender` on l2 to `receiver` on l1 with the proofs.
+// The msg is signed by the base account of the host. The sender can be any format of address based on the l2 chain,
+// while the receiver must be the bech32 address of the host chain.
+func (b BaseHost) GetMsgFinalizeTokenWithdrawal(
+	bridgeId uint64,
+	outputIndex uint64,
+	l2Sequence uint64,
+	sender string,
+	receiver string,
+	amount sdk.Coin,
+	proofs [][]byte,
+	version []byte,
+	storageRoot []byte,
+	lastBlockHash []byte,
+) (sdk.Msg, string, error) {
+	signer, err := b.AccountAddressStringByMsgType(sdk.MsgTypeURL(&ophosttypes.MsgFinalizeTokenWithdrawal{}))
+	if err != nil {
+		if errors.Is(err, types.ErrKeyNotSet) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	} else if signer == "" {
+		return nil, "", nil
+	}
+
+	msg, err := b.newMsgFinalizeTokenWithdrawal(signer, bridgeId, outputIndex, l2Sequence, sender, receiver, amount, proofs, version, storageRoot, lastBlockHash)
+	if err != nil {
+		return nil, "", err
+	}
+	return msg, signer, nil
+}
+
+func (b BaseHost) newMsgFinalizeTokenWithdrawal(
+	signer string,
+	bridgeId uint64,
+	outputIndex uint64,
+	l2Sequence uint64,
+	sender string,
+	receiver string,
+	amount sdk.Coin,
+	proofs [][]byte,
+	version []byte,
+	storageRoot []byte,
+	lastBlockHash []byte,
+) (*ophosttypes.MsgFinalizeTokenWithdrawal, error) {
+	msg := ophosttypes.NewMsgFinalizeTokenWithdrawal(
+		signer,
+		bridgeId,
+		outputIndex,
+		l2Sequence,
+		proofs,
+		sender,
+		receiver,
+		amount,
+		version,
+		storageRoot,
+		lastBlockHash,
+	)
+	err := msg.Validate(b.node.AccountCodec())
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
 func (b BaseHost) CreateBatchMsg(batchBytes []byte) (sdk.Msg, string, error) {
 	submitter, err := b.BaseAccountAddressString()
 	if err != nil {
@@ -57,3 +151,17 @@ func (b BaseHost) CreateBatchMsg(batchBytes []byte) (sdk.Msg, string, error) {
 	}
 	return msg, submitter, nil
 }
+
+// SimulateBatchMsg simulates the batch msg with the broadcaster account of the sender, and returns the adjusted gas.
+func (b BaseHost) SimulateBatchMsg(ctx context.Context, msg sdk.Msg, sender string) (uint64, error) {
+	broadcaster, err := b.node.GetBroadcaster()
+	if err != nil {
+		return 0, err
+	}
+	account, err := broadcaster.AccountByAddress(sender)
+	if err != nil {
+		return 0, err
+	}
+	_, gas, err := account.CalculateGas(ctx, msg)
+	return gas, err
+}
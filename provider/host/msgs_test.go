@@ -0,0 +1,127 @@
+package host
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_GetMsgFinalizeTokenWithdrawal(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewBaseHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+
+	signer := sdk.MustBech32ifyAddressBytes("init", bytes.Repeat([]byte{1}, 20))
+	sender := sdk.MustBech32ifyAddressBytes("l2", bytes.Repeat([]byte{2}, 20))
+	receiver := sdk.MustBech32ifyAddressBytes("init", bytes.Repeat([]byte{3}, 20))
+	amount := sdk.NewCoin("uinit", math.NewInt(100))
+	proofs := [][]byte{bytes.Repeat([]byte{4}, 32), bytes.Repeat([]byte{5}, 32)}
+	storageRoot := bytes.Repeat([]byte{6}, 32)
+	lastBlockHash := bytes.Repeat([]byte{7}, 32)
+
+	// no key to sign
+	msg, signerAddr, err := h.GetMsgFinalizeTokenWithdrawal(1, 2, 3, sender, receiver, amount, proofs, []byte{1}, storageRoot, lastBlockHash)
+	require.NoError(t, err)
+	require.Nil(t, msg)
+	require.Empty(t, signerAddr)
+
+	finalizeMsg, err := h.newMsgFinalizeTokenWithdrawal(signer, 1, 2, 3, sender, receiver, amount, proofs, []byte{1}, storageRoot, lastBlockHash)
+	require.NoError(t, err)
+
+	// round trip
+	bz, err := finalizeMsg.Marshal()
+	require.NoError(t, err)
+	var decoded ophosttypes.MsgFinalizeTokenWithdrawal
+	require.NoError(t, decoded.Unmarshal(bz))
+	require.Equal(t, *finalizeMsg, decoded)
+	require.NoError(t, decoded.Validate(h.node.AccountCodec()))
+	require.Equal(t, signer, decoded.Sender)
+	require.Equal(t, sender, decoded.From)
+	require.Equal(t, receiver, decoded.To)
+	require.Equal(t, uint64(3), decoded.Sequence)
+	require.Equal(t, amount, decoded.Amount)
+
+	cases := []struct {
+		name          string
+		receiver      string
+		amount        sdk.Coin
+		proofs        [][]byte
+		version       []byte
+		storageRoot   []byte
+		lastBlockHash []byte
+	}{
+		{"invalid receiver prefix", sdk.MustBech32ifyAddressBytes("l2", bytes.Repeat([]byte{3}, 20)), amount, proofs, []byte{1}, storageRoot, lastBlockHash},
+		{"zero amount", receiver, sdk.NewCoin("uinit", math.ZeroInt()), proofs, []byte{1}, storageRoot, lastBlockHash},
+		{"invalid proof length", receiver, amount, [][]byte{{1}}, []byte{1}, storageRoot, lastBlockHash},
+		{"invalid version length", receiver, amount, proofs, []byte{1, 2}, storageRoot, lastBlockHash},
+		{"invalid storage root length", receiver, amount, proofs, []byte{1}, storageRoot[:31], lastBlockHash},
+		{"invalid last block hash length", receiver, amount, proofs, []byte{1}, storageRoot, nil},
+	}
+	for _, tc := range cases {
+		_, err := h.newMsgFinalizeTokenWithdrawal(signer, 1, 2, 3, sender, tc.receiver, tc.amount, tc.proofs, tc.version, tc.storageRoot, tc.lastBlockHash)
+		require.Error(t, err, tc.name)
+	}
+}
+
+func Test_RoutedKeyringConfigs(t *testing.T) {
+	h := &BaseHost{}
+	claimer := btypes.KeyringConfig{Name: "claimer", MsgTypes: []string{sdk.MsgTypeURL(&ophosttypes.MsgFinalizeTokenWithdrawal{})}}
+	h.SetRoutedKeyringConfigs([]btypes.KeyringConfig{claimer})
+
+	// the routed accounts are added only with the base account
+	require.Empty(t, h.keyringConfigs(nil))
+	proposer := btypes.KeyringConfig{Address: "init1proposer"}
+	require.Equal(t, []btypes.KeyringConfig{proposer, claimer}, h.keyringConfigs(&proposer))
+}
+
+func Test_FlushMsgQueue(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewBaseHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+	h.SetMsgQueueLimits(btypes.MsgQueueLimits{MaxMsgs: 100})
+
+	senders := []string{"sender-b", "sender-a"}
+	for i := 0; i < 3000; i++ {
+		for _, sender := range senders {
+			h.AppendMsgQueue(&ophosttypes.MsgInitiateTokenDeposit{Sender: sender, Amount: sdk.NewInt64Coin("uinit", int64(i))}, sender)
+		}
+	}
+	require.NoError(t, h.FlushMsgQueue())
+
+	processedMsgs := h.GetProcessedMsgs()
+	require.Len(t, processedMsgs, 60)
+	for i, msgs := range processedMsgs {
+		// senders are flushed in order, and the msgs of a sender keep the order of the queue
+		require.Equal(t, []string{"sender-a", "sender-b"}[i/30], msgs.Sender)
+		require.True(t, msgs.Save)
+		require.Len(t, msgs.Msgs, 100)
+		for j, msg := range msgs.Msgs {
+			require.Equal(t, int64((i%30)*100+j), msg.(*ophosttypes.MsgInitiateTokenDeposit).Amount.Amount.Int64())
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// OutputProposedTimeKey is the prefix of the l1 block times of the outputs proposed for the bridge.
+var OutputProposedTimeKey = []byte("output_proposed_time")
+
+func PrefixedOutputProposedTimeKey(outputIndex uint64) []byte {
+	return append(append(OutputProposedTimeKey, dbtypes.Splitter), dbtypes.FromUint64Key(outputIndex)...)
+}
+
+// OutputProposedTimeToRawKV returns the raw kv of the l1 block time of the output proposal, to be saved
+// with the other changes of the block where the output is proposed.
+func (b BaseHost) OutputProposedTimeToRawKV(outputIndex uint64, blockTime time.Time) types.RawKV {
+	return types.RawKV{
+		Key:   b.db.PrefixedKey(PrefixedOutputProposedTimeKey(outputIndex)),
+		Value: dbtypes.FromInt64(blockTime.UnixNano()),
+	}
+}
+
+// DeleteOutputProposedTimes deletes the l1 block times of the outputs from the given index,
+// which are deleted by the challenger.
+func (b BaseHost) DeleteOutputProposedTimes(fromIndex uint64) error {
+	_, err := b.db.PrefixedDeleteRange(OutputProposedTimeKey, PrefixedOutputProposedTimeKey(fromIndex), nil)
+	return err
+}
+
+// OutputProposedTime returns the l1 block time of the output proposal. If it is not recorded, e.g. the output
+// proposed before the bot started, it is queried from the output proposal and its l1 block, then recorded.
+func (b BaseHost) OutputProposedTime(ctx context.Context, outputIndex uint64) (time.Time, error) {
+	value, err := b.db.Get(PrefixedOutputProposedTimeKey(outputIndex))
+	if err == nil {
+		unixNano, err := dbtypes.ToInt64(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, unixNano).UTC(), nil
+	} else if !errors.Is(err, dbtypes.ErrNotFound) {
+		return time.Time{}, err
+	}
+
+	output, err := b.QueryOutput(ctx, b.BridgeId(), outputIndex, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	blockTime := output.OutputProposal.L1BlockTime
+	if blockTime.IsZero() {
+		// the proposal without the block time is resolved by the header of its l1 block
+		height := types.MustUint64ToInt64(output.OutputProposal.L1BlockNumber)
+		queryCtx, cancel := b.node.QueryContext(ctx, 0)
+		header, err := b.node.GetRPCClient().Header(queryCtx, &height)
+		cancel()
+		if err != nil {
+			return time.Time{}, err
+		}
+		blockTime = header.Header.Time
+	}
+
+	err = b.db.RawBatchSet(b.OutputProposedTimeToRawKV(outputIndex, blockTime))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return blockTime.UTC(), nil
+}
+
+// OutputClaimableAt returns the time when the withdrawals of the output become claimable on l1,
+// which is the l1 block time of the output proposal plus the finalization period of the bridge.
+func (b BaseHost) OutputClaimableAt(ctx context.Context, outputIndex uint64) (time.Time, error) {
+	proposedTime, err := b.OutputProposedTime(ctx, outputIndex)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return proposedTime.Add(b.BridgeInfo().BridgeConfig.FinalizationPeriod), nil
+}
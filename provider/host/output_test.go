@@ -0,0 +1,96 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	"github.com/initia-labs/opinit-bots/node"
+	"github.com/initia-labs/opinit-bots/node/nodetest"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+)
+
+func Test_OutputClaimableAt(t *testing.T) {
+	cfg := nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}
+	db := db.NewMemDB()
+	h, err := NewBaseHostV1(cfg, db, zap.NewNop())
+	require.NoError(t, err)
+
+	// the l1 block time of the block 5 is 5 seconds after the epoch
+	chain := nodetest.NewFakeClient("test-1")
+	chain.AppendBlocks(5)
+	cdc, txConfig, err := GetCodec(cfg.Bech32Prefix)
+	require.NoError(t, err)
+	h.node, err = node.NewNodeWithRPCClient(cfg, db, zap.NewNop(), cdc, txConfig, chain)
+	require.NoError(t, err)
+	h.ophostQueryClient = ophosttypes.NewQueryClient(h.node.QueryConn())
+	h.SetBridgeInfo(ophosttypes.QueryBridgeResponse{
+		BridgeId:     1,
+		BridgeConfig: ophosttypes.BridgeConfig{FinalizationPeriod: time.Hour},
+	})
+
+	// the output 2 is proposed with the block time, and the output 3 is resolved by the header of its block
+	outputs := map[uint64]ophosttypes.Output{
+		2: {L1BlockNumber: 4, L1BlockTime: time.Unix(100, 0)},
+		3: {L1BlockNumber: 5},
+	}
+	chain.HandleQuery("/opinit.ophost.v1.Query/OutputProposal", func(req abci.RequestQuery) (abci.ResponseQuery, error) {
+		var outputReq ophosttypes.QueryOutputProposalRequest
+		if err := outputReq.Unmarshal(req.Data); err != nil {
+			return abci.ResponseQuery{}, err
+		}
+		output, ok := outputs[outputReq.OutputIndex]
+		if !ok {
+			return abci.ResponseQuery{}, errors.New("output proposal: not found")
+		}
+		res := ophosttypes.QueryOutputProposalResponse{BridgeId: 1, OutputIndex: outputReq.OutputIndex, OutputProposal: output}
+		bz, err := res.Marshal()
+		return abci.ResponseQuery{Value: bz}, err
+	})
+
+	// the output observed live is served from the record without the query
+	require.NoError(t, db.RawBatchSet(h.OutputProposedTimeToRawKV(1, time.Unix(10, 0))))
+	claimableAt, err := h.OutputClaimableAt(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(10, 0).Add(time.Hour).UTC(), claimableAt)
+	require.Zero(t, chain.Calls(nodetest.MethodABCIQuery))
+
+	// the outputs proposed before the bot started are backfilled from the chain and recorded
+	claimableAt, err = h.OutputClaimableAt(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(100, 0).Add(time.Hour).UTC(), claimableAt)
+	claimableAt, err = h.OutputClaimableAt(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(5, 0).Add(time.Hour).UTC(), claimableAt)
+	require.Equal(t, 2, chain.Calls(nodetest.MethodABCIQuery))
+	require.Equal(t, 1, chain.Calls(nodetest.MethodHeader))
+
+	_, err = h.OutputClaimableAt(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, 2, chain.Calls(nodetest.MethodABCIQuery))
+
+	// the output not proposed yet
+	_, err = h.OutputClaimableAt(context.Background(), 4)
+	require.ErrorContains(t, err, "not found")
+
+	// the times of the deleted outputs are removed
+	require.NoError(t, h.DeleteOutputProposedTimes(2))
+	_, err = h.OutputProposedTime(context.Background(), 1)
+	require.NoError(t, err)
+	delete(outputs, 2)
+	_, err = h.OutputProposedTime(context.Background(), 2)
+	require.ErrorContains(t, err, "not found")
+}
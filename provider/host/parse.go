@@ -182,6 +182,40 @@ func ParseMsgProposeOutput(eventAttrs []abcitypes.EventAttribute) (
 	return
 }
 
+func ParseMsgDeleteOutput(eventAttrs []abcitypes.EventAttribute) (
+	bridgeId uint64,
+	outputIndex uint64,
+	challenger string,
+	err error) {
+	missingAttrs := map[string]struct{}{
+		ophosttypes.AttributeKeyChallenger:  {},
+		ophosttypes.AttributeKeyBridgeId:    {},
+		ophosttypes.AttributeKeyOutputIndex: {},
+	}
+
+	for _, attr := range eventAttrs {
+		switch attr.Key {
+		case ophosttypes.AttributeKeyChallenger:
+			challenger = attr.Value
+		case ophosttypes.AttributeKeyBridgeId:
+			bridgeId, err = strconv.ParseUint(attr.Value, 10, 64)
+			if err != nil {
+				return
+			}
+		case ophosttypes.AttributeKeyOutputIndex:
+			outputIndex, err = strconv.ParseUint(attr.Value, 10, 64)
+			if err != nil {
+				return
+			}
+		default:
+			continue
+		}
+		delete(missingAttrs, attr.Key)
+	}
+	err = missingAttrsError(missingAttrs)
+	return
+}
+
 func ParseMsgFinalizeWithdrawal(eventAttrs []abcitypes.EventAttribute) (
 	bridgeId, outputIndex, l2Sequence uint64,
 	from, to, l1Denom, l2Denom, amount string,
@@ -232,3 +266,58 @@ func ParseMsgFinalizeWithdrawal(eventAttrs []abcitypes.EventAttribute) (
 	err = missingAttrsError(missingAttrs)
 	return
 }
+
+// ParseBridgeUpdate parses the bridge id from the bridge config update events;
+// update_proposer, update_challenger, update_batch_info and update_oracle.
+func ParseBridgeUpdate(eventAttrs []abcitypes.EventAttribute) (
+	bridgeId uint64, err error,
+) {
+	missingAttrs := map[string]struct{}{
+		ophosttypes.AttributeKeyBridgeId: {},
+	}
+
+	for _, attr := range eventAttrs {
+		switch attr.Key {
+		case ophosttypes.AttributeKeyBridgeId:
+			bridgeId, err = strconv.ParseUint(attr.Value, 10, 64)
+			if err != nil {
+				return
+			}
+		default:
+			continue
+		}
+		delete(missingAttrs, attr.Key)
+	}
+	err = missingAttrsError(missingAttrs)
+	return
+}
+
+// the events of the bridge freeze; OPinit v0.6.1 doesn't emit them yet, so the handlers of the events
+// are inactive until the ophost module of the host emits them.
+const (
+	EventTypeFreezeBridge   = "freeze_bridge"
+	EventTypeUnfreezeBridge = "unfreeze_bridge"
+)
+
+func ParseFreezeBridge(eventAttrs []abcitypes.EventAttribute) (
+	bridgeId uint64, err error,
+) {
+	missingAttrs := map[string]struct{}{
+		ophosttypes.AttributeKeyBridgeId: {},
+	}
+
+	for _, attr := range eventAttrs {
+		switch attr.Key {
+		case ophosttypes.AttributeKeyBridgeId:
+			bridgeId, err = strconv.ParseUint(attr.Value, 10, 64)
+			if err != nil {
+				return
+			}
+		default:
+			continue
+		}
+		delete(missingAttrs, attr.Key)
+	}
+	err = missingAttrsError(missingAttrs)
+	return
+}
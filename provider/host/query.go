@@ -1,13 +1,22 @@
 package host
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+
 	query "github.com/cosmos/cosmos-sdk/types/query"
 
 	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
@@ -16,21 +25,23 @@ import (
 	"github.com/initia-labs/opinit-bots/types"
 )
 
-func (b BaseHost) QueryBridgeConfig(ctx context.Context, bridgeId uint64) (*ophosttypes.QueryBridgeResponse, error) {
+// QueryBridgeConfig queries the bridge config at the given height. 0 means the latest height.
+func (b BaseHost) QueryBridgeConfig(ctx context.Context, bridgeId uint64, height int64) (*ophosttypes.QueryBridgeResponse, error) {
 	req := &ophosttypes.QueryBridgeRequest{
 		BridgeId: bridgeId,
 	}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
+	ctx, cancel := b.node.QueryContext(ctx, height)
 	defer cancel()
 
 	return b.ophostQueryClient.Bridge(ctx, req)
 }
 
-func (b BaseHost) QueryLastFinalizedOutput(ctx context.Context, bridgeId uint64) (*ophosttypes.QueryLastFinalizedOutputResponse, error) {
+// QueryLastFinalizedOutput queries the last finalized output at the given height. 0 means the latest height.
+func (b BaseHost) QueryLastFinalizedOutput(ctx context.Context, bridgeId uint64, height int64) (*ophosttypes.QueryLastFinalizedOutputResponse, error) {
 	req := &ophosttypes.QueryLastFinalizedOutputRequest{
 		BridgeId: bridgeId,
 	}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
+	ctx, cancel := b.node.QueryContext(ctx, height)
 	defer cancel()
 
 	res, err := b.ophostQueryClient.LastFinalizedOutput(ctx, req)
@@ -43,7 +54,9 @@ func (b BaseHost) QueryLastFinalizedOutput(ctx context.Context, bridgeId uint64)
 	return res, nil
 }
 
-func (b BaseHost) QueryLastOutput(ctx context.Context, bridgeId uint64) (*ophosttypes.QueryOutputProposalResponse, error) {
+// QueryLastOutput queries the last output proposal at the given height. 0 means the latest height.
+// The transient errors are retried with backoff.
+func (b BaseHost) QueryLastOutput(ctx context.Context, bridgeId uint64, height int64) (*ophosttypes.QueryOutputProposalResponse, error) {
 	req := &ophosttypes.QueryOutputProposalsRequest{
 		BridgeId: bridgeId,
 		Pagination: &query.PageRequest{
@@ -51,10 +64,11 @@ func (b BaseHost) QueryLastOutput(ctx context.Context, bridgeId uint64) (*ophost
 			Reverse: true,
 		},
 	}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
-	defer cancel()
-
-	res, err := b.ophostQueryClient.OutputProposals(ctx, req)
+	res, err := queryWithRetry(ctx, b.logger, "output proposals", func(ctx context.Context) (*ophosttypes.QueryOutputProposalsResponse, error) {
+		ctx, cancel := b.node.QueryContext(ctx, height)
+		defer cancel()
+		return b.ophostQueryClient.OutputProposals(ctx, req)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -64,15 +78,98 @@ func (b BaseHost) QueryLastOutput(ctx context.Context, bridgeId uint64) (*ophost
 	return &res.OutputProposals[0], nil
 }
 
+// QueryOutput queries the output proposal of the given index at the given height. 0 means the latest height.
+// The transient errors are retried with backoff.
 func (b BaseHost) QueryOutput(ctx context.Context, bridgeId uint64, outputIndex uint64, height int64) (*ophosttypes.QueryOutputProposalResponse, error) {
 	req := &ophosttypes.QueryOutputProposalRequest{
 		BridgeId:    bridgeId,
 		OutputIndex: outputIndex,
 	}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, height)
-	defer cancel()
+	return queryWithRetry(ctx, b.logger, "output proposal", func(ctx context.Context) (*ophosttypes.QueryOutputProposalResponse, error) {
+		ctx, cancel := b.node.QueryContext(ctx, height)
+		defer cancel()
+		return b.ophostQueryClient.OutputProposal(ctx, req)
+	})
+}
+
+// outputsPageLimit is the number of the outputs queried in a page by QueryOutputs.
+const outputsPageLimit = 100
+
+// QueryOutputs queries at most `limit` output proposals from the given output index in ascending order,
+// following the pagination until the last page. 0 limit means all the outputs from the index.
+// The deleted outputs are skipped. The transient errors are retried with backoff.
+func (b BaseHost) QueryOutputs(ctx context.Context, bridgeId uint64, fromIndex uint64, limit uint64) ([]ophosttypes.QueryOutputProposalResponse, error) {
+	outputs := make([]ophosttypes.QueryOutputProposalResponse, 0)
+	err := b.IterateOutputs(ctx, bridgeId, fromIndex, limit, func(output ophosttypes.QueryOutputProposalResponse) (bool, error) {
+		outputs = append(outputs, output)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// IterateOutputs calls the callback with at most `limit` output proposals from the given output index
+// in ascending order, querying them page by page. 0 limit means all the outputs from the index.
+// The pages after the first page are queried at the same height as the first page.
+func (b BaseHost) IterateOutputs(ctx context.Context, bridgeId uint64, fromIndex uint64, limit uint64, cb func(ophosttypes.QueryOutputProposalResponse) (stop bool, err error)) error {
+	// the pagination key of the outputs is the big endian output index under the bridge id prefix
+	var nextKey []byte
+	if fromIndex > 0 {
+		nextKey = binary.BigEndian.AppendUint64(nil, fromIndex)
+	}
+
+	height := int64(0)
+	count := uint64(0)
+	for limit == 0 || count < limit {
+		pageLimit := uint64(outputsPageLimit)
+		if limit != 0 {
+			pageLimit = min(pageLimit, limit-count)
+		}
+		req := &ophosttypes.QueryOutputProposalsRequest{
+			BridgeId: bridgeId,
+			Pagination: &query.PageRequest{
+				Key:   nextKey,
+				Limit: pageLimit,
+			},
+		}
+
+		var md metadata.MD
+		res, err := queryWithRetry(ctx, b.logger, "output proposals", func(ctx context.Context) (*ophosttypes.QueryOutputProposalsResponse, error) {
+			ctx, cancel := b.node.QueryContext(ctx, height)
+			defer cancel()
+			return b.ophostQueryClient.OutputProposals(ctx, req, grpc.Header(&md))
+		})
+		if err != nil {
+			return err
+		}
 
-	return b.ophostQueryClient.OutputProposal(ctx, req)
+		for _, output := range res.OutputProposals {
+			if stop, err := cb(output); err != nil {
+				return err
+			} else if stop {
+				return nil
+			}
+			count++
+		}
+
+		// the empty next key means the last page, even if the page is full
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			break
+		} else if bytes.Equal(res.Pagination.NextKey, nextKey) {
+			return fmt.Errorf("output proposals pagination doesn't advance; next key: %X", nextKey)
+		}
+		nextKey = res.Pagination.NextKey
+
+		if height == 0 {
+			height, err = rpcclient.GetHeightFromMetadata(md)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // QueryOutputByL2BlockNumber queries the last output proposal before the given L2 block number
@@ -84,7 +181,7 @@ func (b BaseHost) QueryOutputByL2BlockNumber(ctx context.Context, bridgeId uint6
 		}
 		return nil, err
 	}
-	end, err := b.QueryLastOutput(ctx, bridgeId)
+	end, err := b.QueryLastOutput(ctx, bridgeId, 0)
 	if err != nil {
 		return nil, err
 	} else if end == nil {
@@ -118,8 +215,37 @@ func (b BaseHost) QueryOutputByL2BlockNumber(ctx context.Context, bridgeId uint6
 	}
 }
 
+// ValidateOutputProposal validates the output to propose against the outputs on chain. It returns true
+// if the same output root already exists at the output index, so the proposal can be skipped.
+// ErrOutputConflict is returned if a different output root exists at the output index.
+func (b BaseHost) ValidateOutputProposal(ctx context.Context, bridgeId uint64, outputIndex uint64, l2BlockNumber int64, outputRoot []byte) (bool, error) {
+	lastOutput, err := b.QueryLastOutput(ctx, bridgeId, 0)
+	if err != nil {
+		return false, err
+	} else if lastOutput == nil || outputIndex > lastOutput.OutputIndex {
+		// the outputs before ours can be still pending, so only the l2 block number is validated
+		if lastOutput != nil && types.MustInt64ToUint64(l2BlockNumber) <= lastOutput.OutputProposal.L2BlockNumber {
+			return false, fmt.Errorf(
+				"l2 block number %d of the output %d is not greater than the last output %d at l2 block number %d",
+				l2BlockNumber, outputIndex, lastOutput.OutputIndex, lastOutput.OutputProposal.L2BlockNumber,
+			)
+		}
+		return false, nil
+	}
+
+	output, err := b.QueryOutput(ctx, bridgeId, outputIndex, 0)
+	if err != nil {
+		return false, err
+	} else if !bytes.Equal(output.OutputProposal.OutputRoot, outputRoot) ||
+		output.OutputProposal.L2BlockNumber != types.MustInt64ToUint64(l2BlockNumber) {
+		return false, fmt.Errorf("%w: output index: %d, l2 block number: %d, proposed l2 block number: %d",
+			types.ErrOutputConflict, outputIndex, l2BlockNumber, output.OutputProposal.L2BlockNumber)
+	}
+	return true, nil
+}
+
 func (b BaseHost) QueryCreateBridgeHeight(ctx context.Context, bridgeId uint64) (int64, error) {
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
+	ctx, cancel := b.node.QueryContext(ctx, 0)
 	defer cancel()
 
 	query := fmt.Sprintf("%s.%s = %d",
@@ -139,23 +265,99 @@ func (b BaseHost) QueryCreateBridgeHeight(ctx context.Context, bridgeId uint64)
 	return res.Txs[0].Height, nil
 }
 
-func (b BaseHost) QueryBatchInfos(ctx context.Context, bridgeId uint64) (*ophosttypes.QueryBatchInfosResponse, error) {
-	req := &ophosttypes.QueryBatchInfosRequest{
-		BridgeId: bridgeId,
+// QueryBatchInfos queries all the batch infos at the given height, following the pagination until the last page.
+// 0 means the latest height. The transient errors are retried with backoff.
+func (b BaseHost) QueryBatchInfos(ctx context.Context, bridgeId uint64, height int64) (*ophosttypes.QueryBatchInfosResponse, error) {
+	batchInfos := make([]ophosttypes.BatchInfoWithOutput, 0)
+
+	var nextKey []byte
+	for {
+		req := &ophosttypes.QueryBatchInfosRequest{
+			BridgeId: bridgeId,
+			Pagination: &query.PageRequest{
+				Key: nextKey,
+			},
+		}
+
+		var md metadata.MD
+		res, err := queryWithRetry(ctx, b.logger, "batch infos", func(ctx context.Context) (*ophosttypes.QueryBatchInfosResponse, error) {
+			ctx, cancel := b.node.QueryContext(ctx, height)
+			defer cancel()
+			return b.ophostQueryClient.BatchInfos(ctx, req, grpc.Header(&md))
+		})
+		if err != nil {
+			return nil, err
+		}
+		batchInfos = append(batchInfos, res.BatchInfos...)
+
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = res.Pagination.NextKey
+
+		// query the next pages at the same height as the first page
+		if height == 0 {
+			height, err = rpcclient.GetHeightFromMetadata(md)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &ophosttypes.QueryBatchInfosResponse{BatchInfos: batchInfos}, nil
+}
+
+// maxConcurrentClaimedQueries is the maximum number of the concurrent queries of QueryClaimedBulk.
+const maxConcurrentClaimedQueries = 8
+
+// QueryClaimed returns true if the withdrawal of the given hash is already claimed on l1.
+// The unknown withdrawal is answered as not claimed by the chain, so every error is returned.
+func (b BaseHost) QueryClaimed(ctx context.Context, bridgeId uint64, withdrawalHash []byte) (bool, error) {
+	req := &ophosttypes.QueryClaimedRequest{
+		BridgeId:       bridgeId,
+		WithdrawalHash: withdrawalHash,
 	}
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
+	ctx, cancel := b.node.QueryContext(ctx, 0)
 	defer cancel()
-	return b.ophostQueryClient.BatchInfos(ctx, req)
+
+	res, err := b.ophostQueryClient.Claimed(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	return res.Claimed, nil
+}
+
+// QueryClaimedBulk queries the claimed status of the withdrawals of the given hashes concurrently.
+// The results are in the same order as the hashes.
+// It fails if any of the queries fails, with the same error handling as QueryClaimed.
+func (b BaseHost) QueryClaimedBulk(ctx context.Context, bridgeId uint64, withdrawalHashes [][]byte) ([]bool, error) {
+	claimed := make([]bool, len(withdrawalHashes))
+
+	errGrp, ctx := errgroup.WithContext(ctx)
+	errGrp.SetLimit(maxConcurrentClaimedQueries)
+	for i, withdrawalHash := range withdrawalHashes {
+		errGrp.Go(func() (err error) {
+			claimed[i], err = b.QueryClaimed(ctx, bridgeId, withdrawalHash)
+			return err
+		})
+	}
+	if err := errGrp.Wait(); err != nil {
+		return nil, err
+	}
+	return claimed, nil
 }
 
 func (b BaseHost) QueryDepositTxHeight(ctx context.Context, bridgeId uint64, l1Sequence uint64) (int64, error) {
+	height, _, err := b.QueryDepositTxEvent(ctx, bridgeId, l1Sequence)
+	return height, err
+}
+
+// QueryDepositTxEvent searches the deposit tx of the given l1 sequence and returns its height and
+// the attributes of the deposit event. It returns 0 and nil attributes if the tx is not found.
+func (b BaseHost) QueryDepositTxEvent(ctx context.Context, bridgeId uint64, l1Sequence uint64) (int64, []abcitypes.EventAttribute, error) {
 	if l1Sequence == 0 {
-		return 0, nil
+		return 0, nil, nil
 	}
 
-	ctx, cancel := rpcclient.GetQueryContext(ctx, 0)
-	defer cancel()
-
 	ticker := time.NewTicker(types.PollingInterval(ctx))
 	defer ticker.Stop()
 
@@ -164,28 +366,42 @@ func (b BaseHost) QueryDepositTxHeight(ctx context.Context, bridgeId uint64, l1S
 		ophosttypes.AttributeKeyL1Sequence,
 		l1Sequence,
 	)
+	bridgeIdStr := strconv.FormatUint(bridgeId, 10)
+	l1SequenceStr := strconv.FormatUint(l1Sequence, 10)
 	per_page := 100
 	for page := 1; ; page++ {
 		select {
 		case <-ctx.Done():
-			return 0, ctx.Err()
+			return 0, nil, ctx.Err()
 		case <-ticker.C:
 		}
 
-		res, err := b.node.GetRPCClient().TxSearch(ctx, query, false, &page, &per_page, "asc")
+		// each page is bounded by its own timeout
+		queryCtx, cancel := b.node.QueryContext(ctx, 0)
+		res, err := b.node.GetRPCClient().TxSearch(queryCtx, query, false, &page, &per_page, "asc")
+		cancel()
 		if err != nil {
-			return 0, err
+			return 0, nil, err
 		}
 
 		for _, tx := range res.Txs {
 			for _, event := range tx.TxResult.Events {
-				if event.Type == ophosttypes.EventTypeInitiateTokenDeposit {
-					for _, attr := range event.Attributes {
-						if attr.Key == ophosttypes.AttributeKeyBridgeId && attr.Value == strconv.FormatUint(bridgeId, 10) {
-							return tx.Height, nil
-						}
+				if event.Type != ophosttypes.EventTypeInitiateTokenDeposit {
+					continue
+				}
+
+				bridgeMatched, sequenceMatched := false, false
+				for _, attr := range event.Attributes {
+					switch attr.Key {
+					case ophosttypes.AttributeKeyBridgeId:
+						bridgeMatched = attr.Value == bridgeIdStr
+					case ophosttypes.AttributeKeyL1Sequence:
+						sequenceMatched = attr.Value == l1SequenceStr
 					}
 				}
+				if bridgeMatched && sequenceMatched {
+					return tx.Height, event.Attributes, nil
+				}
 			}
 		}
 
@@ -193,5 +409,37 @@ func (b BaseHost) QueryDepositTxHeight(ctx context.Context, bridgeId uint64, l1S
 			break
 		}
 	}
-	return 0, nil
+	return 0, nil, nil
+}
+
+// queryWithRetry runs the query, retrying with exponential backoff on the transient errors.
+func queryWithRetry[T any](ctx context.Context, logger *zap.Logger, name string, fn func(context.Context) (T, error)) (T, error) {
+	var res T
+	var err error
+	for retry := 0; retry <= types.MaxRetryCount; retry++ {
+		if types.SleepWithBackoff(ctx, types.PollingInterval(ctx), retry) || ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+
+		res, err = fn(ctx)
+		if err == nil || !isRetryableQueryError(ctx, err) {
+			return res, err
+		}
+		logger.Warn("failed to query; retrying", zap.String("query", name), zap.Int("retry", retry+1), zap.String("error", err.Error()))
+	}
+	return res, fmt.Errorf("failed to query %s after %d retries: %w", name, types.MaxRetryCount, err)
+}
+
+// isRetryableQueryError returns false for the errors which are not resolved by retrying;
+// the canceled context, the pruned height and the missing entries.
+func isRetryableQueryError(ctx context.Context, err error) bool {
+	switch {
+	case ctx.Err() != nil:
+		return false
+	case errors.Is(err, types.ErrHeightPruned):
+		return false
+	case strings.Contains(err.Error(), "not found"):
+		return false
+	}
+	return true
 }
@@ -0,0 +1,489 @@
+package host
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpccoretypes "github.com/cometbft/cometbft/rpc/core/types"
+
+	query "github.com/cosmos/cosmos-sdk/types/query"
+
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+
+	"github.com/initia-labs/opinit-bots/db"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// newMockABCIServer serves the batch infos query. The number of the batch infos is the queried height,
+// and the heights below `prunedHeight` are pruned.
+func newMockABCIServer(t *testing.T, latestHeight int64, prunedHeight int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Path   string `json:"path"`
+				Height string `json:"height"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "abci_query" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		height, err := strconv.ParseInt(req.Params.Height, 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if height == 0 {
+			height = latestHeight
+		}
+
+		var res abci.ResponseQuery
+		if height < prunedHeight {
+			res = abci.ResponseQuery{
+				Code: 18,
+				Log:  fmt.Sprintf("failed to load state at height %d; version does not exist (latest height: %d): invalid request", height, latestHeight),
+			}
+		} else {
+			batchInfos := make([]ophosttypes.BatchInfoWithOutput, height)
+			bz, err := (&ophosttypes.QueryBatchInfosResponse{BatchInfos: batchInfos}).Marshal()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			res = abci.ResponseQuery{Value: bz, Height: height}
+		}
+
+		result, err := cmtjson.Marshal(&rpccoretypes.ResultABCIQuery{Response: res})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  json.RawMessage(result),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_QueryAtHeight(t *testing.T) {
+	server := newMockABCIServer(t, 10, 5)
+
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewBaseHostV1(nodetypes.NodeConfig{
+		RPC:          server.URL,
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+
+	// latest height
+	res, err := h.QueryBatchInfos(context.Background(), 1, 0)
+	require.NoError(t, err)
+	require.Len(t, res.BatchInfos, 10)
+
+	// historical height
+	res, err = h.QueryBatchInfos(context.Background(), 1, 7)
+	require.NoError(t, err)
+	require.Len(t, res.BatchInfos, 7)
+
+	// pruned height
+	_, err = h.QueryBatchInfos(context.Background(), 1, 3)
+	require.ErrorIs(t, err, types.ErrHeightPruned)
+}
+
+// mockQueryClient serves the batch infos in pages of `pageSize` and fails the first `failures` queries.
+type mockQueryClient struct {
+	ophosttypes.QueryClient
+
+	batchInfos []ophosttypes.BatchInfoWithOutput
+	outputs    map[uint64]ophosttypes.QueryOutputProposalResponse
+	pageSize   int
+	failures   int
+	calls      int
+
+	// the output proposals requests, and whether the next key is returned for the full last page
+	outputsReqs     []*ophosttypes.QueryOutputProposalsRequest
+	boundaryNextKey bool
+}
+
+func (m *mockQueryClient) fail() error {
+	m.calls++
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (m *mockQueryClient) BatchInfos(_ context.Context, req *ophosttypes.QueryBatchInfosRequest, _ ...grpc.CallOption) (*ophosttypes.QueryBatchInfosResponse, error) {
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if req.Pagination != nil && len(req.Pagination.Key) > 0 {
+		start = int(binary.BigEndian.Uint64(req.Pagination.Key))
+	}
+	end := min(start+m.pageSize, len(m.batchInfos))
+
+	res := &ophosttypes.QueryBatchInfosResponse{
+		BatchInfos: m.batchInfos[start:end],
+		Pagination: &query.PageResponse{},
+	}
+	if end < len(m.batchInfos) {
+		res.Pagination.NextKey = binary.BigEndian.AppendUint64(nil, uint64(end))
+	}
+	return res, nil
+}
+
+func (m *mockQueryClient) OutputProposal(_ context.Context, req *ophosttypes.QueryOutputProposalRequest, _ ...grpc.CallOption) (*ophosttypes.QueryOutputProposalResponse, error) {
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+	output, ok := m.outputs[req.OutputIndex]
+	if !ok {
+		return nil, errors.New("output proposal: not found")
+	}
+	return &output, nil
+}
+
+func (m *mockQueryClient) OutputProposals(_ context.Context, req *ophosttypes.QueryOutputProposalsRequest, _ ...grpc.CallOption) (*ophosttypes.QueryOutputProposalsResponse, error) {
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+	m.outputsReqs = append(m.outputsReqs, req)
+
+	outputIndexes := make([]uint64, 0, len(m.outputs))
+	for outputIndex := range m.outputs {
+		outputIndexes = append(outputIndexes, outputIndex)
+	}
+	slices.Sort(outputIndexes)
+	if req.Pagination.Reverse {
+		slices.Reverse(outputIndexes)
+	}
+	if len(req.Pagination.Key) > 0 {
+		from := binary.BigEndian.Uint64(req.Pagination.Key)
+		outputIndexes = slices.DeleteFunc(outputIndexes, func(outputIndex uint64) bool {
+			return (!req.Pagination.Reverse && outputIndex < from) || (req.Pagination.Reverse && outputIndex > from)
+		})
+	}
+
+	limit := int(req.Pagination.Limit)
+	if m.pageSize > 0 && (limit == 0 || limit > m.pageSize) {
+		limit = m.pageSize
+	}
+	res := &ophosttypes.QueryOutputProposalsResponse{Pagination: &query.PageResponse{}}
+	for i, outputIndex := range outputIndexes {
+		if i == limit {
+			res.Pagination.NextKey = binary.BigEndian.AppendUint64(nil, outputIndex)
+			break
+		}
+		res.OutputProposals = append(res.OutputProposals, m.outputs[outputIndex])
+	}
+	if m.boundaryNextKey && len(res.Pagination.NextKey) == 0 && len(res.OutputProposals) == limit {
+		res.Pagination.NextKey = binary.BigEndian.AppendUint64(nil, res.OutputProposals[limit-1].OutputIndex+1)
+	}
+	return res, nil
+}
+
+func Test_QueryWithRetry(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewBaseHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+
+	batchInfos := make([]ophosttypes.BatchInfoWithOutput, 25)
+	for i := range batchInfos {
+		batchInfos[i].Output.L2BlockNumber = uint64(i)
+	}
+	client := &mockQueryClient{
+		batchInfos: batchInfos,
+		outputs: map[uint64]ophosttypes.QueryOutputProposalResponse{
+			1: {BridgeId: 1, OutputIndex: 1},
+			2: {BridgeId: 1, OutputIndex: 2},
+		},
+		pageSize: 10,
+		failures: 2,
+	}
+	h.ophostQueryClient = client
+
+	ctx := types.WithPollingInterval(context.Background(), time.Millisecond)
+
+	// all the pages are followed, retrying the transient failures
+	res, err := h.QueryBatchInfos(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, batchInfos, res.BatchInfos)
+	require.Equal(t, 5, client.calls)
+
+	client.failures = 3
+	output, err := h.QueryOutput(ctx, 1, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), output.OutputIndex)
+
+	client.failures = 1
+	output, err = h.QueryLastOutput(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), output.OutputIndex)
+
+	// not found is not retried
+	client.calls = 0
+	_, err = h.QueryOutput(ctx, 1, 3, 0)
+	require.ErrorContains(t, err, "not found")
+	require.Equal(t, 1, client.calls)
+
+	// give up after the max retry count
+	client.calls = 0
+	client.failures = types.MaxRetryCount + 1
+	_, err = h.QueryOutput(ctx, 1, 1, 0)
+	require.ErrorContains(t, err, "connection refused")
+	require.Equal(t, types.MaxRetryCount+1, client.calls)
+
+	// canceled context stops retrying
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	client.failures = 1
+	_, err = h.QueryBatchInfos(cancelCtx, 1, 0)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// mockClaimedQueryClient serves the claimed status, and the unknown withdrawals are not claimed as the chain
+// answers. The query of the failed withdrawals fails.
+type mockClaimedQueryClient struct {
+	ophosttypes.QueryClient
+
+	mu          sync.Mutex
+	claimed     map[string]bool
+	failed      map[string]error
+	inflight    int
+	maxInflight int
+}
+
+func (m *mockClaimedQueryClient) Claimed(_ context.Context, req *ophosttypes.QueryClaimedRequest, _ ...grpc.CallOption) (*ophosttypes.QueryClaimedResponse, error) {
+	m.mu.Lock()
+	m.inflight++
+	m.maxInflight = max(m.maxInflight, m.inflight)
+	claimed := m.claimed[string(req.WithdrawalHash)]
+	err, failed := m.failed[string(req.WithdrawalHash)]
+	m.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	m.mu.Lock()
+	m.inflight--
+	m.mu.Unlock()
+
+	if failed {
+		return nil, err
+	}
+	return &ophosttypes.QueryClaimedResponse{Claimed: claimed}, nil
+}
+
+func Test_QueryClaimed(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewBaseHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+
+	client := &mockClaimedQueryClient{claimed: make(map[string]bool), failed: make(map[string]error)}
+	withdrawalHashes := make([][]byte, 30)
+	expected := make([]bool, len(withdrawalHashes))
+	for i := range withdrawalHashes {
+		withdrawalHashes[i] = []byte(fmt.Sprintf("withdrawal-%d", i))
+		switch i % 3 {
+		case 0:
+			client.claimed[string(withdrawalHashes[i])] = true
+			expected[i] = true
+		case 1:
+			client.claimed[string(withdrawalHashes[i])] = false
+		}
+	}
+	h.ophostQueryClient = client
+
+	claimed, err := h.QueryClaimed(context.Background(), 1, withdrawalHashes[0])
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	// the unknown withdrawal is not claimed
+	claimed, err = h.QueryClaimed(context.Background(), 1, withdrawalHashes[2])
+	require.NoError(t, err)
+	require.False(t, claimed)
+
+	claimedList, err := h.QueryClaimedBulk(context.Background(), 1, withdrawalHashes)
+	require.NoError(t, err)
+	require.Equal(t, expected, claimedList)
+	require.LessOrEqual(t, client.maxInflight, maxConcurrentClaimedQueries)
+
+	// the failed query is not reported as unclaimed, even if its error says not found
+	client.failed[string(withdrawalHashes[2])] = status.Error(codes.NotFound, "account not found")
+	_, err = h.QueryClaimed(context.Background(), 1, withdrawalHashes[2])
+	require.Error(t, err)
+	client.failed[string(withdrawalHashes[2])] = errors.New("connection refused")
+	_, err = h.QueryClaimedBulk(context.Background(), 1, withdrawalHashes)
+	require.ErrorContains(t, err, "connection refused")
+}
+
+func Test_ValidateOutputProposal(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewBaseHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+
+	client := &mockQueryClient{
+		outputs: map[uint64]ophosttypes.QueryOutputProposalResponse{
+			1: {BridgeId: 1, OutputIndex: 1, OutputProposal: ophosttypes.Output{L2BlockNumber: 10, OutputRoot: []byte("root1")}},
+			2: {BridgeId: 1, OutputIndex: 2, OutputProposal: ophosttypes.Output{L2BlockNumber: 20, OutputRoot: []byte("root2")}},
+		},
+	}
+	h.ophostQueryClient = client
+
+	// the next output
+	exists, err := h.ValidateOutputProposal(context.Background(), 1, 3, 30, []byte("root3"))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// the previous output can be pending
+	exists, err = h.ValidateOutputProposal(context.Background(), 1, 4, 40, []byte("root4"))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// l2 block number is not increasing
+	_, err = h.ValidateOutputProposal(context.Background(), 1, 3, 20, []byte("root3"))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, types.ErrOutputConflict)
+
+	// the same output already exists
+	exists, err = h.ValidateOutputProposal(context.Background(), 1, 2, 20, []byte("root2"))
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// conflicting output exists
+	_, err = h.ValidateOutputProposal(context.Background(), 1, 2, 20, []byte("other"))
+	require.ErrorIs(t, err, types.ErrOutputConflict)
+	_, err = h.ValidateOutputProposal(context.Background(), 1, 1, 15, []byte("root1"))
+	require.ErrorIs(t, err, types.ErrOutputConflict)
+}
+
+func Test_QueryOutputs(t *testing.T) {
+	db, err := db.NewDB(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewBaseHostV1(nodetypes.NodeConfig{
+		RPC:          "http://localhost:26657",
+		ChainID:      "test-1",
+		Bech32Prefix: "init",
+	}, db, zap.NewNop())
+	require.NoError(t, err)
+
+	// the outputs 1-25 without the output 7 deleted by the challenger
+	outputs := make(map[uint64]ophosttypes.QueryOutputProposalResponse)
+	for outputIndex := uint64(1); outputIndex <= 25; outputIndex++ {
+		if outputIndex != 7 {
+			outputs[outputIndex] = ophosttypes.QueryOutputProposalResponse{BridgeId: 1, OutputIndex: outputIndex}
+		}
+	}
+	client := &mockQueryClient{outputs: outputs, pageSize: 10}
+	h.ophostQueryClient = client
+	outputIndexes := func(outputs []ophosttypes.QueryOutputProposalResponse) []uint64 {
+		indexes := make([]uint64, 0, len(outputs))
+		for _, output := range outputs {
+			indexes = append(indexes, output.OutputIndex)
+		}
+		return indexes
+	}
+
+	// the three pages are stitched
+	res, err := h.QueryOutputs(context.Background(), 1, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, res, 24)
+	require.NotContains(t, outputIndexes(res), uint64(7))
+	require.Equal(t, uint64(25), res[23].OutputIndex)
+	require.Len(t, client.outputsReqs, 3)
+	require.Equal(t, binary.BigEndian.AppendUint64(nil, 1), client.outputsReqs[0].Pagination.Key)
+	require.Equal(t, binary.BigEndian.AppendUint64(nil, 12), client.outputsReqs[1].Pagination.Key)
+
+	// the limit is applied across the pages
+	client.outputsReqs = nil
+	res, err = h.QueryOutputs(context.Background(), 1, 5, 12)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{5, 6, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17}, outputIndexes(res))
+	require.Len(t, client.outputsReqs, 2)
+	require.Equal(t, uint64(2), client.outputsReqs[1].Pagination.Limit)
+
+	// the full last page without the next key ends the query
+	client.outputsReqs = nil
+	res, err = h.QueryOutputs(context.Background(), 1, 17, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{17, 18, 19, 20, 21, 22, 23, 24, 25}, outputIndexes(res))
+	require.Len(t, client.outputsReqs, 1)
+
+	// the full last page with the next key is followed by an empty page
+	client.outputsReqs = nil
+	client.boundaryNextKey = true
+	res, err = h.QueryOutputs(context.Background(), 1, 16, 0)
+	require.NoError(t, err)
+	require.Len(t, res, 10)
+	require.Len(t, client.outputsReqs, 2)
+	client.boundaryNextKey = false
+
+	// no outputs after the last output
+	res, err = h.QueryOutputs(context.Background(), 1, 26, 0)
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	// the iteration is stopped by the callback
+	client.outputsReqs = nil
+	visited := make([]uint64, 0)
+	err = h.IterateOutputs(context.Background(), 1, 1, 0, func(output ophosttypes.QueryOutputProposalResponse) (bool, error) {
+		visited = append(visited, output.OutputIndex)
+		return output.OutputIndex == 12, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), visited[len(visited)-1])
+	require.Len(t, client.outputsReqs, 2)
+}
@@ -0,0 +1,48 @@
+package host
+
+import (
+	"context"
+	"fmt"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+)
+
+// ExpectedChainInfo is the chain info which the host is expected to serve. It is verified at Initialize
+// to fail fast when the host node points to the wrong network.
+type ExpectedChainInfo struct {
+	// L2ChainID is the configured chain id of the l2 chain.
+	L2ChainID string
+
+	// L2BridgeInfo is the bridge info registered on the l2 chain.
+	L2BridgeInfo opchildtypes.BridgeInfo
+}
+
+// checkChainInfo queries the chain id reported by the node and verifies it with the expected chain info.
+func (b BaseHost) checkChainInfo(ctx context.Context, bridgeInfo ophosttypes.QueryBridgeResponse, expected ExpectedChainInfo) error {
+	status, err := b.node.GetRPCClient().Status(ctx)
+	if err != nil {
+		return err
+	}
+	return verifyChainInfo(b.cfg.ChainID, status.NodeInfo.Network, bridgeInfo, expected)
+}
+
+// verifyChainInfo verifies the configured chain id, the chain id reported by the node and
+// the bridge registered on the l2 chain point to the same l1 chain and bridge.
+func verifyChainInfo(chainId string, nodeChainId string, bridgeInfo ophosttypes.QueryBridgeResponse, expected ExpectedChainInfo) error {
+	if chainId != nodeChainId || chainId != expected.L2BridgeInfo.L1ChainId {
+		return fmt.Errorf(
+			"l1 chain id mismatch: configured %q, reported by the node %q, registered on the l2 chain %q (%q)",
+			chainId, nodeChainId, expected.L2ChainID, expected.L2BridgeInfo.L1ChainId,
+		)
+	}
+
+	if bridgeInfo.BridgeId != expected.L2BridgeInfo.BridgeId || bridgeInfo.BridgeAddr != expected.L2BridgeInfo.BridgeAddr {
+		return fmt.Errorf(
+			"bridge mismatch on l1 chain %q: bridge %d (%s) on l1, bridge %d (%s) registered on the l2 chain %q",
+			chainId, bridgeInfo.BridgeId, bridgeInfo.BridgeAddr,
+			expected.L2BridgeInfo.BridgeId, expected.L2BridgeInfo.BridgeAddr, expected.L2ChainID,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	opchildtypes "github.com/initia-labs/OPinit/x/opchild/types"
+	ophosttypes "github.com/initia-labs/OPinit/x/ophost/types"
+)
+
+func Test_VerifyChainInfo(t *testing.T) {
+	bridgeInfo := ophosttypes.QueryBridgeResponse{
+		BridgeId:   1,
+		BridgeAddr: "init1bridge",
+	}
+	expected := ExpectedChainInfo{
+		L2ChainID: "l2-1",
+		L2BridgeInfo: opchildtypes.BridgeInfo{
+			BridgeId:   1,
+			BridgeAddr: "init1bridge",
+			L1ChainId:  "l1-1",
+		},
+	}
+
+	// matched
+	require.NoError(t, verifyChainInfo("l1-1", "l1-1", bridgeInfo, expected))
+
+	// mismatched bridge
+	err := verifyChainInfo("l1-1", "l1-1", ophosttypes.QueryBridgeResponse{BridgeId: 2, BridgeAddr: "init1other"}, expected)
+	require.ErrorContains(t, err, "bridge mismatch")
+	require.ErrorContains(t, err, "init1other")
+	require.ErrorContains(t, err, "l2-1")
+
+	err = verifyChainInfo("l1-1", "l1-1", ophosttypes.QueryBridgeResponse{BridgeId: 1, BridgeAddr: "init1other"}, expected)
+	require.ErrorContains(t, err, "bridge mismatch")
+
+	// mismatched chain id reported by the node
+	err = verifyChainInfo("l1-1", "l1-2", bridgeInfo, expected)
+	require.ErrorContains(t, err, "chain id mismatch")
+	require.ErrorContains(t, err, `configured "l1-1", reported by the node "l1-2", registered on the l2 chain "l2-1" ("l1-1")`)
+
+	// mismatched chain id registered on the l2 chain
+	expected.L2BridgeInfo.L1ChainId = "l1-3"
+	err = verifyChainInfo("l1-1", "l1-1", bridgeInfo, expected)
+	require.ErrorContains(t, err, `registered on the l2 chain "l2-1" ("l1-3")`)
+}
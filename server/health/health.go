@@ -0,0 +1,198 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	dbtypes "github.com/initia-labs/opinit-bots/db/types"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	servertypes "github.com/initia-labs/opinit-bots/server/types"
+	"github.com/initia-labs/opinit-bots/types"
+)
+
+// healthKey is the key read by the liveness probe to check the db is reachable.
+var healthKey = []byte("healthz")
+
+// Node is the node checked by the readiness probe.
+type Node interface {
+	Status() (nodetypes.NodeStatus, error)
+}
+
+// Status is the result of the readiness check, with the reasons why the bot is not ready.
+type Status struct {
+	Ready     bool      `json:"ready"`
+	Reasons   []string  `json:"reasons"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type namedNode struct {
+	name string
+	node Node
+}
+
+// broadcasterProgress is the last sum of the broadcaster account sequences of a node and the time it changed.
+type broadcasterProgress struct {
+	sequence uint64
+	time     time.Time
+}
+
+// Checker is the watchdog of the readiness of the bot. It checks the nodes at the interval, so the readiness
+// flips even without the probe requests, and the stalled broadcaster sequences are detected across the checks.
+type Checker struct {
+	cfg    servertypes.HealthConfig
+	db     types.DB
+	nodes  []namedNode
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu          sync.Mutex
+	startTime   time.Time
+	status      *Status
+	broadcaster map[string]broadcasterProgress
+}
+
+func NewChecker(cfg servertypes.HealthConfig, db types.DB, logger *zap.Logger) *Checker {
+	return &Checker{
+		cfg:         cfg,
+		db:          db,
+		nodes:       make([]namedNode, 0),
+		logger:      logger,
+		now:         time.Now,
+		startTime:   time.Now(),
+		broadcaster: make(map[string]broadcasterProgress),
+	}
+}
+
+// AddNode adds the node to the readiness check.
+func (c *Checker) AddNode(name string, node Node) {
+	c.nodes = append(c.nodes, namedNode{name: name, node: node})
+}
+
+// Start runs the watchdog until the context is done.
+func (c *Checker) Start(ctx context.Context) {
+	interval := time.Duration(c.cfg.CheckInterval) * time.Second
+	if interval == 0 {
+		interval = servertypes.DefaultHealthCheckInterval
+	}
+
+	types.ErrGrp(ctx).Go(func() error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			c.Check()
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Live returns an error if the db is not reachable.
+func (c *Checker) Live() error {
+	_, err := c.db.Get(healthKey)
+	if err != nil && !errors.Is(err, dbtypes.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Ready returns the status of the last check of the watchdog. The bot is not ready before the first check.
+func (c *Checker) Ready() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status == nil {
+		return Status{Reasons: []string{"not checked yet"}}
+	}
+	return *c.status
+}
+
+// Check evaluates the readiness of the nodes and stores the status, logging when the readiness flips.
+func (c *Checker) Check() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	status := Status{Reasons: make([]string, 0), CheckedAt: now}
+	for _, n := range c.nodes {
+		status.Reasons = append(status.Reasons, c.checkNode(now, n)...)
+	}
+	status.Ready = len(status.Reasons) == 0
+
+	if c.status == nil || c.status.Ready != status.Ready {
+		if status.Ready {
+			c.logger.Info("bot is ready")
+		} else {
+			c.logger.Warn("bot is not ready", zap.Strings("reasons", status.Reasons))
+		}
+	}
+	c.status = &status
+	return status
+}
+
+// checkNode returns the reasons why the node is not ready. It must be called with the lock.
+func (c *Checker) checkNode(now time.Time, n namedNode) []string {
+	status, err := n.node.Status()
+	if err != nil {
+		return []string{fmt.Sprintf("%s: failed to get status: %s", n.name, err.Error())}
+	}
+
+	reasons := make([]string, 0)
+	if status.CatchingUp {
+		reasons = append(reasons, fmt.Sprintf("%s: node is catching up the chain", n.name))
+	}
+	if behind := status.LatestChainHeight - status.LastProcessedHeight; c.cfg.MaxBlocksBehind > 0 && behind > c.cfg.MaxBlocksBehind {
+		reasons = append(reasons, fmt.Sprintf("%s: %d blocks behind the chain", n.name, behind))
+	}
+
+	stallTimeout := time.Duration(c.cfg.StallTimeout) * time.Second
+	if stallTimeout > 0 {
+		// the node has the stall timeout from the start of the bot to make the first progress
+		lastProgress := c.startTime
+		if status.LastProgressTime != nil {
+			lastProgress = *status.LastProgressTime
+		}
+		if stalled := now.Sub(lastProgress); stalled > stallTimeout {
+			reasons = append(reasons, fmt.Sprintf("%s: block process has not advanced for %s", n.name, stalled.Round(time.Second)))
+		}
+
+		if stalled := c.broadcasterStalled(now, n.name, status.Broadcaster); stalled > stallTimeout {
+			reasons = append(reasons, fmt.Sprintf("%s: broadcaster sequence has not advanced for %s", n.name, stalled.Round(time.Second)))
+		}
+	}
+
+	fatalErrorWindow := time.Duration(c.cfg.FatalErrorWindow) * time.Second
+	if fatalErrorWindow > 0 && status.LastFatalErrorTime != nil && now.Sub(*status.LastFatalErrorTime) < fatalErrorWindow {
+		reasons = append(reasons, fmt.Sprintf("%s: fatal error at %s: %s", n.name, status.LastFatalErrorTime.Format(time.RFC3339), status.LastError))
+	}
+	return reasons
+}
+
+// broadcasterStalled returns the time since the broadcaster sequences of the node advanced while the txs are pending.
+// It returns 0 if there is no pending tx. It must be called with the lock.
+func (c *Checker) broadcasterStalled(now time.Time, name string, status *btypes.BroadcasterStatus) time.Duration {
+	if status == nil || status.PendingTxs == 0 {
+		delete(c.broadcaster, name)
+		return 0
+	}
+
+	var sequence uint64
+	for _, account := range status.AccountsStatus {
+		sequence += account.Sequence
+	}
+	progress, ok := c.broadcaster[name]
+	if !ok || progress.sequence != sequence {
+		c.broadcaster[name] = broadcasterProgress{sequence: sequence, time: now}
+		return 0
+	}
+	return now.Sub(progress.time)
+}
@@ -0,0 +1,184 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/initia-labs/opinit-bots/db"
+	btypes "github.com/initia-labs/opinit-bots/node/broadcaster/types"
+	nodetypes "github.com/initia-labs/opinit-bots/node/types"
+	servertypes "github.com/initia-labs/opinit-bots/server/types"
+)
+
+type mockNode struct {
+	status nodetypes.NodeStatus
+	err    error
+}
+
+func (m *mockNode) Status() (nodetypes.NodeStatus, error) {
+	return m.status, m.err
+}
+
+func newTestChecker(t *testing.T, nodes ...*mockNode) (*Checker, *time.Time) {
+	now := time.Unix(1000, 0)
+	c := NewChecker(servertypes.HealthConfig{
+		MaxBlocksBehind:  10,
+		StallTimeout:     60,
+		FatalErrorWindow: 120,
+	}, db.NewMemDB(), zap.NewNop())
+	c.now = func() time.Time { return now }
+	c.startTime = now
+	for i, node := range nodes {
+		c.AddNode([]string{"host", "child"}[i], node)
+	}
+	return c, &now
+}
+
+func Test_ReadyBeforeCheck(t *testing.T) {
+	c, _ := newTestChecker(t)
+	require.False(t, c.Ready().Ready)
+
+	c.Check()
+	require.True(t, c.Ready().Ready)
+}
+
+func Test_StalledNode(t *testing.T) {
+	host := &mockNode{}
+	child := &mockNode{}
+	c, now := newTestChecker(t, host, child)
+
+	progress := func(node *mockNode, height int64) {
+		progressTime := *now
+		node.status.LastProcessedHeight = height
+		node.status.LatestChainHeight = height
+		node.status.LastProgressTime = &progressTime
+	}
+	progress(host, 100)
+	progress(child, 200)
+	require.True(t, c.Check().Ready)
+
+	// the child is wedged on a bad block while the host keeps processing blocks
+	*now = now.Add(30 * time.Second)
+	progress(host, 110)
+	child.status.LatestChainHeight = 205
+	require.True(t, c.Check().Ready)
+
+	*now = now.Add(31 * time.Second)
+	progress(host, 120)
+	status := c.Check()
+	require.False(t, status.Ready)
+	require.Equal(t, []string{"child: block process has not advanced for 1m1s"}, status.Reasons)
+	require.Equal(t, status, c.Ready())
+
+	// the readiness flips back when the child advances
+	progress(child, 205)
+	require.True(t, c.Check().Ready)
+}
+
+func Test_BlocksBehind(t *testing.T) {
+	progressTime := time.Unix(1000, 0)
+	host := &mockNode{status: nodetypes.NodeStatus{LatestChainHeight: 111, LastProcessedHeight: 100, LastProgressTime: &progressTime}}
+	c, _ := newTestChecker(t, host)
+
+	status := c.Check()
+	require.False(t, status.Ready)
+	require.Equal(t, []string{"host: 11 blocks behind the chain"}, status.Reasons)
+
+	host.status.LastProcessedHeight = 101
+	require.True(t, c.Check().Ready)
+}
+
+func Test_NoProgressSinceStart(t *testing.T) {
+	host := &mockNode{}
+	c, now := newTestChecker(t, host)
+	require.True(t, c.Check().Ready)
+
+	*now = now.Add(61 * time.Second)
+	require.False(t, c.Check().Ready)
+}
+
+func Test_BroadcasterStalled(t *testing.T) {
+	host := &mockNode{}
+	c, now := newTestChecker(t, host)
+
+	setStatus := func(pendingTxs int, sequence uint64) {
+		progressTime := *now
+		host.status.LastProgressTime = &progressTime
+		host.status.Broadcaster = &btypes.BroadcasterStatus{
+			PendingTxs:     pendingTxs,
+			AccountsStatus: []btypes.BroadcasterAccountStatus{{Sequence: sequence}},
+		}
+	}
+
+	setStatus(1, 5)
+	require.True(t, c.Check().Ready)
+
+	// the sequence advances
+	*now = now.Add(50 * time.Second)
+	setStatus(1, 6)
+	require.True(t, c.Check().Ready)
+
+	*now = now.Add(50 * time.Second)
+	setStatus(1, 6)
+	require.True(t, c.Check().Ready)
+
+	*now = now.Add(11 * time.Second)
+	setStatus(1, 6)
+	status := c.Check()
+	require.False(t, status.Ready)
+	require.Equal(t, []string{"host: broadcaster sequence has not advanced for 1m1s"}, status.Reasons)
+
+	// no pending tx
+	setStatus(0, 6)
+	require.True(t, c.Check().Ready)
+}
+
+func Test_FatalError(t *testing.T) {
+	host := &mockNode{}
+	c, now := newTestChecker(t, host)
+
+	progressTime := *now
+	errorTime := *now
+	host.status.LastProgressTime = &progressTime
+	host.status.LastError = "handler failure"
+	host.status.LastFatalErrorTime = &errorTime
+	require.False(t, c.Check().Ready)
+
+	*now = now.Add(121 * time.Second)
+	progressTime = *now
+	require.True(t, c.Check().Ready)
+
+	host.err = errors.New("node is not initialized")
+	require.False(t, c.Check().Ready)
+}
+
+func Test_DisabledChecks(t *testing.T) {
+	host := &mockNode{status: nodetypes.NodeStatus{LatestChainHeight: 1000}}
+	c, now := newTestChecker(t, host)
+	c.cfg = servertypes.HealthConfig{}
+
+	*now = now.Add(time.Hour)
+	require.True(t, c.Check().Ready)
+}
+
+func Test_Live(t *testing.T) {
+	c, _ := newTestChecker(t)
+	require.NoError(t, c.Live())
+}
+
+func Test_CatchingUp(t *testing.T) {
+	progressTime := time.Unix(1000, 0)
+	host := &mockNode{status: nodetypes.NodeStatus{CatchingUp: true, LastProgressTime: &progressTime}}
+	c, _ := newTestChecker(t, host)
+
+	status := c.Check()
+	require.False(t, status.Ready)
+	require.Equal(t, []string{"host: node is catching up the chain"}, status.Reasons)
+
+	host.status.CatchingUp = false
+	require.True(t, c.Check().Ready)
+}
@@ -3,11 +3,17 @@ package server
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+
+	"github.com/initia-labs/opinit-bots/db"
+	"github.com/initia-labs/opinit-bots/logging"
+	"github.com/initia-labs/opinit-bots/server/health"
 	"github.com/initia-labs/opinit-bots/server/types"
+	opinittypes "github.com/initia-labs/opinit-bots/types"
 )
 
 type Server struct {
-	address string
+	address          string
+	enableLocalAdmin bool
 	*fiber.App
 }
 
@@ -20,8 +26,9 @@ func NewServer(cfg types.ServerConfig) *Server {
 	}))
 
 	return &Server{
-		address: cfg.Address,
-		App:     app,
+		address:          cfg.Address,
+		enableLocalAdmin: cfg.EnableLocalAdmin,
+		App:              app,
 	}
 }
 
@@ -32,3 +39,83 @@ func (s *Server) Start() error {
 func (s *Server) RegisterQuerier(path string, fn func(c *fiber.Ctx) error) {
 	s.Get(path, fn)
 }
+
+// RegisterHandler registers the handler for the given http method, such as POST for the actions of the bot.
+func (s *Server) RegisterHandler(method string, path string, fn func(c *fiber.Ctx) error) {
+	s.Add(method, path, fn)
+}
+
+// RegisterAdminHandler registers the handler only if the local admin is enabled,
+// which rejects the requests from the non-loopback addresses.
+func (s *Server) RegisterAdminHandler(method string, path string, fn func(c *fiber.Ctx) error) {
+	if !s.enableLocalAdmin {
+		return
+	}
+
+	s.Add(method, path, func(c *fiber.Ctx) error {
+		if !c.Context().RemoteIP().IsLoopback() {
+			return fiber.ErrForbidden
+		}
+		return fn(c)
+	})
+}
+
+// RegisterBackupHandler registers the admin handler which writes the snapshot of the db
+// to the backup directory while the bot is running.
+func (s *Server) RegisterBackupHandler(database opinittypes.DB, backupDir string) {
+	s.RegisterAdminHandler(fiber.MethodPost, "/admin/db/backup", func(c *fiber.Ctx) error {
+		path, err := db.SnapshotPath(backupDir, c.Query("name"))
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if err := database.Snapshot(path); err != nil {
+			return err
+		}
+		manifest, err := db.ReadSnapshotManifest(path)
+		if err != nil {
+			return err
+		}
+		return c.JSON(types.BackupResponse{
+			Path:      path,
+			KeyCount:  manifest.KeyCount,
+			CreatedAt: manifest.CreatedAt,
+		})
+	})
+}
+
+// RegisterHealthHandlers registers the probes for the container orchestration. `/healthz` responds 200
+// while the db is reachable, and `/readyz` responds the last readiness check of the watchdog, 503 if not ready.
+func (s *Server) RegisterHealthHandlers(checker *health.Checker) {
+	s.RegisterQuerier("/healthz", func(c *fiber.Ctx) error {
+		if err := checker.Live(); err != nil {
+			return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+		}
+		return c.SendString("ok")
+	})
+	s.RegisterQuerier("/readyz", func(c *fiber.Ctx) error {
+		status := checker.Ready()
+		if !status.Ready {
+			c.Status(fiber.StatusServiceUnavailable)
+		}
+		return c.JSON(status)
+	})
+}
+
+// RegisterLogLevelHandlers registers the admin handlers to get the log levels of the components, and to change
+// the level of a component at runtime, e.g. `POST /admin/log/level?component=batch&level=debug`.
+// The empty component changes the default level.
+func (s *Server) RegisterLogLevelHandlers(levels *logging.Levels) {
+	if levels == nil {
+		return
+	}
+
+	s.RegisterAdminHandler(fiber.MethodGet, "/admin/log/levels", func(c *fiber.Ctx) error {
+		return c.JSON(levels.All())
+	})
+	s.RegisterAdminHandler(fiber.MethodPost, "/admin/log/level", func(c *fiber.Ctx) error {
+		if err := levels.SetLevel(c.Query("component"), c.Query("level")); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return c.JSON(levels.All())
+	})
+}
@@ -1,17 +1,65 @@
 package types
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type ServerConfig struct {
 	Address      string `json:"address"`
 	AllowOrigins string `json:"allow_origins"`
 	AllowHeaders string `json:"allow_headers"`
 	AllowMethods string `json:"allow_methods"`
+	// EnableLocalAdmin enables the admin endpoints, such as the db backup, which are served
+	// only to the requests from the loopback addresses.
+	EnableLocalAdmin bool `json:"enable_local_admin"`
+	// Health is the configuration of the readiness probe.
+	Health HealthConfig `json:"health"`
+}
+
+// HealthConfig is the thresholds of the readiness probe. Each check is disabled if its threshold is 0,
+// so the configs without the health section have only the liveness probe.
+type HealthConfig struct {
+	// MaxBlocksBehind is the maximum number of blocks of a node behind the chain tip.
+	MaxBlocksBehind int64 `json:"max_blocks_behind"`
+	// StallTimeout is the maximum time without the progress of the block process loop of a node,
+	// and without the advance of the broadcaster sequences while the txs are pending.
+	StallTimeout int64 `json:"stall_timeout"` // seconds
+	// FatalErrorWindow is the time after a fatal error of a node, e.g. a handler failure, while the bot is not ready.
+	FatalErrorWindow int64 `json:"fatal_error_window"` // seconds
+	// CheckInterval is the interval of the watchdog evaluating the readiness. If it is 0, 10 seconds is used.
+	CheckInterval int64 `json:"check_interval"` // seconds
+}
+
+// DefaultHealthCheckInterval is the interval of the watchdog if it is not configured.
+const DefaultHealthCheckInterval = 10 * time.Second
+
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		MaxBlocksBehind:  100,
+		StallTimeout:     5 * 60,  // 5 minutes
+		FatalErrorWindow: 10 * 60, // 10 minutes
+		CheckInterval:    10,
+	}
+}
+
+func (c HealthConfig) Validate() error {
+	if c.MaxBlocksBehind < 0 || c.StallTimeout < 0 || c.FatalErrorWindow < 0 || c.CheckInterval < 0 {
+		return errors.New("health thresholds must be greater than or equal to 0")
+	}
+	return nil
+}
+
+// BackupResponse is the response of the db backup endpoint.
+type BackupResponse struct {
+	Path      string    `json:"path"`
+	KeyCount  uint64    `json:"key_count"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func (s ServerConfig) Validate() error {
 	if s.Address == "" {
 		return errors.New("address is required")
 	}
-	return nil
+	return s.Health.Validate()
 }
@@ -1,8 +1,12 @@
 package txutils
 
 import (
+	"strings"
+
 	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
 )
 
@@ -37,3 +41,54 @@ func ChangeMsgsFromTx(txConfig client.TxConfig, tx authsigning.Tx, msgs []sdk.Ms
 
 	return builder.GetTx(), nil
 }
+
+// IsUnknownTypeURLErr returns true if the tx failed to be decoded because of the type url
+// which is not registered in the codec.
+func IsUnknownTypeURLErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "unable to resolve type URL")
+}
+
+// DecodeTxMsgs decodes the msgs of the tx bytes. If lenient is true, the tx with the unknown type urls
+// is decoded by DecodeTxMsgsLenient, and the unknown type urls are returned.
+func DecodeTxMsgs(cdc codec.Codec, txConfig client.TxConfig, txBytes []byte, lenient bool) ([]sdk.Msg, []string, error) {
+	tx, err := DecodeTx(txConfig, txBytes)
+	if err == nil {
+		return tx.GetMsgs(), nil, nil
+	} else if !lenient || !IsUnknownTypeURLErr(err) {
+		return nil, nil, err
+	}
+
+	msgs, unknownTypeURLs, lenientErr := DecodeTxMsgsLenient(cdc, txBytes)
+	if lenientErr != nil {
+		return nil, nil, err
+	}
+	return msgs, unknownTypeURLs, nil
+}
+
+// DecodeTxMsgsLenient decodes the msgs of the tx without failing on the unknown type urls.
+// The msgs of the unknown type urls are left as the packed any, so the number and the order of
+// the msgs are kept, and their type urls are returned.
+func DecodeTxMsgsLenient(cdc codec.Codec, txBytes []byte) ([]sdk.Msg, []string, error) {
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(txBytes); err != nil {
+		return nil, nil, err
+	}
+	// unmarshal without unpacking the msgs, which fails on the unknown type urls
+	var body txtypes.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return nil, nil, err
+	}
+
+	msgs := make([]sdk.Msg, 0, len(body.Messages))
+	unknownTypeURLs := make([]string, 0)
+	for _, anyMsg := range body.Messages {
+		var msg sdk.Msg
+		if err := cdc.InterfaceRegistry().UnpackAny(anyMsg, &msg); err != nil {
+			unknownTypeURLs = append(unknownTypeURLs, anyMsg.TypeUrl)
+			msgs = append(msgs, anyMsg)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, unknownTypeURLs, nil
+}
@@ -0,0 +1,598 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: opinit/batch/v1/batch.proto
+
+package batch
+
+import (
+	fmt "fmt"
+	proto "github.com/cosmos/gogoproto/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// CommittedBatchDataHeader is the header of a batch which commits to the lengths and the checksum
+// of the compressed payload. It follows the batch data type byte of the committed header.
+type CommittedBatchDataHeader struct {
+	Start uint64 `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   uint64 `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	// version is the batch version of the payload.
+	Version uint32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	// compression is the compression algorithm of the payload.
+	Compression uint32 `protobuf:"varint,4,opt,name=compression,proto3" json:"compression,omitempty"`
+	// raw_length is the length of the payload before the compression.
+	RawLength uint64 `protobuf:"varint,5,opt,name=raw_length,json=rawLength,proto3" json:"raw_length,omitempty"`
+	// compressed_length is the length of the compressed payload, the sum of the chunk lengths.
+	CompressedLength uint64 `protobuf:"varint,6,opt,name=compressed_length,json=compressedLength,proto3" json:"compressed_length,omitempty"`
+	// payload_checksum is the sha256 checksum of the compressed payload.
+	PayloadChecksum []byte `protobuf:"bytes,7,opt,name=payload_checksum,json=payloadChecksum,proto3" json:"payload_checksum,omitempty"`
+	// checksums are the sha256 checksums of the chunks.
+	Checksums [][]byte `protobuf:"bytes,8,rep,name=checksums,proto3" json:"checksums,omitempty"`
+}
+
+func (m *CommittedBatchDataHeader) Reset()         { *m = CommittedBatchDataHeader{} }
+func (m *CommittedBatchDataHeader) String() string { return proto.CompactTextString(m) }
+func (*CommittedBatchDataHeader) ProtoMessage()    {}
+func (*CommittedBatchDataHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_18fa09371b57a71e, []int{0}
+}
+func (m *CommittedBatchDataHeader) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CommittedBatchDataHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CommittedBatchDataHeader.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CommittedBatchDataHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommittedBatchDataHeader.Merge(m, src)
+}
+func (m *CommittedBatchDataHeader) XXX_Size() int {
+	return m.Size()
+}
+func (m *CommittedBatchDataHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommittedBatchDataHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommittedBatchDataHeader proto.InternalMessageInfo
+
+func (m *CommittedBatchDataHeader) GetStart() uint64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *CommittedBatchDataHeader) GetEnd() uint64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *CommittedBatchDataHeader) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *CommittedBatchDataHeader) GetCompression() uint32 {
+	if m != nil {
+		return m.Compression
+	}
+	return 0
+}
+
+func (m *CommittedBatchDataHeader) GetRawLength() uint64 {
+	if m != nil {
+		return m.RawLength
+	}
+	return 0
+}
+
+func (m *CommittedBatchDataHeader) GetCompressedLength() uint64 {
+	if m != nil {
+		return m.CompressedLength
+	}
+	return 0
+}
+
+func (m *CommittedBatchDataHeader) GetPayloadChecksum() []byte {
+	if m != nil {
+		return m.PayloadChecksum
+	}
+	return nil
+}
+
+func (m *CommittedBatchDataHeader) GetChecksums() [][]byte {
+	if m != nil {
+		return m.Checksums
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*CommittedBatchDataHeader)(nil), "opinit.batch.v1.CommittedBatchDataHeader")
+}
+
+func init() { proto.RegisterFile("opinit/batch/v1/batch.proto", fileDescriptor_18fa09371b57a71e) }
+
+var fileDescriptor_18fa09371b57a71e = []byte{
+	// 300 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0xbf, 0x6e, 0xf2, 0x30,
+	0x14, 0xc5, 0x31, 0x7f, 0x3f, 0xfc, 0x51, 0x41, 0xad, 0x0e, 0x96, 0xda, 0x5a, 0x51, 0xa7, 0x54,
+	0x15, 0x89, 0x50, 0xdf, 0x00, 0x3a, 0x30, 0x74, 0xca, 0xd8, 0x05, 0x39, 0x89, 0x45, 0xac, 0x92,
+	0x38, 0xb2, 0x2f, 0x20, 0xde, 0xa2, 0x52, 0x5f, 0xaa, 0x23, 0x63, 0xc7, 0x0a, 0x5e, 0xa4, 0x8a,
+	0x9d, 0x88, 0x6e, 0xf7, 0xfc, 0xce, 0x4f, 0x77, 0x38, 0xf8, 0x56, 0x95, 0xb2, 0x90, 0x10, 0xc6,
+	0x1c, 0x92, 0x2c, 0xdc, 0xcd, 0xdc, 0x11, 0x94, 0x5a, 0x81, 0x22, 0x63, 0x57, 0x06, 0x8e, 0xed,
+	0x66, 0x0f, 0x9f, 0x6d, 0x4c, 0x17, 0x2a, 0xcf, 0x25, 0x80, 0x48, 0xe7, 0x15, 0x7d, 0xe1, 0xc0,
+	0x97, 0x82, 0xa7, 0x42, 0x93, 0x1b, 0xdc, 0x33, 0xc0, 0x35, 0x50, 0xe4, 0x21, 0xbf, 0x1b, 0xb9,
+	0x40, 0x26, 0xb8, 0x23, 0x8a, 0x94, 0xb6, 0x2d, 0xab, 0x4e, 0x42, 0xf1, 0x60, 0x27, 0xb4, 0x91,
+	0xaa, 0xa0, 0x1d, 0x0f, 0xf9, 0x57, 0x51, 0x13, 0x89, 0x87, 0xff, 0x27, 0x2a, 0x2f, 0xb5, 0x30,
+	0xb6, 0xed, 0xda, 0xf6, 0x2f, 0x22, 0xf7, 0x18, 0x6b, 0xbe, 0x5f, 0x6d, 0x44, 0xb1, 0x86, 0x8c,
+	0xf6, 0xec, 0xd3, 0xa1, 0xe6, 0xfb, 0x57, 0x0b, 0xc8, 0x13, 0xbe, 0x6e, 0x6c, 0x91, 0x36, 0x56,
+	0xdf, 0x5a, 0x93, 0x4b, 0x51, 0xcb, 0x8f, 0x78, 0x52, 0xf2, 0xc3, 0x46, 0xf1, 0x74, 0x95, 0x64,
+	0x22, 0x79, 0x37, 0xdb, 0x9c, 0x0e, 0x3c, 0xe4, 0x8f, 0xa2, 0x71, 0xcd, 0x17, 0x35, 0x26, 0x77,
+	0x78, 0xd8, 0x28, 0x86, 0xfe, 0xf3, 0x3a, 0xfe, 0x28, 0xba, 0x80, 0xf9, 0xf2, 0xeb, 0xc4, 0xd0,
+	0xf1, 0xc4, 0xd0, 0xcf, 0x89, 0xa1, 0x8f, 0x33, 0x6b, 0x1d, 0xcf, 0xac, 0xf5, 0x7d, 0x66, 0xad,
+	0xb7, 0x60, 0x2d, 0x21, 0xdb, 0xc6, 0x41, 0xa2, 0xf2, 0xb0, 0x5a, 0x52, 0xf2, 0xe9, 0x86, 0xc7,
+	0x26, 0x74, 0xbb, 0x4e, 0x63, 0x05, 0x26, 0x84, 0x43, 0x29, 0x8c, 0x9b, 0x3d, 0xee, 0xdb, 0xdd,
+	0x9f, 0x7f, 0x07, 0x00, 0xb0, 0x6d, 0x0a, 0x4a, 0x96, 0x01, 0x00, 0x00,
+}
+
+func (m *CommittedBatchDataHeader) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CommittedBatchDataHeader) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CommittedBatchDataHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Checksums) > 0 {
+		for iNdEx := len(m.Checksums) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Checksums[iNdEx])
+			copy(dAtA[i:], m.Checksums[iNdEx])
+			i = encodeVarintBatch(dAtA, i, uint64(len(m.Checksums[iNdEx])))
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if len(m.PayloadChecksum) > 0 {
+		i -= len(m.PayloadChecksum)
+		copy(dAtA[i:], m.PayloadChecksum)
+		i = encodeVarintBatch(dAtA, i, uint64(len(m.PayloadChecksum)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.CompressedLength != 0 {
+		i = encodeVarintBatch(dAtA, i, uint64(m.CompressedLength))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.RawLength != 0 {
+		i = encodeVarintBatch(dAtA, i, uint64(m.RawLength))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Compression != 0 {
+		i = encodeVarintBatch(dAtA, i, uint64(m.Compression))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Version != 0 {
+		i = encodeVarintBatch(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.End != 0 {
+		i = encodeVarintBatch(dAtA, i, uint64(m.End))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Start != 0 {
+		i = encodeVarintBatch(dAtA, i, uint64(m.Start))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintBatch(dAtA []byte, offset int, v uint64) int {
+	offset -= sovBatch(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *CommittedBatchDataHeader) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Start != 0 {
+		n += 1 + sovBatch(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovBatch(uint64(m.End))
+	}
+	if m.Version != 0 {
+		n += 1 + sovBatch(uint64(m.Version))
+	}
+	if m.Compression != 0 {
+		n += 1 + sovBatch(uint64(m.Compression))
+	}
+	if m.RawLength != 0 {
+		n += 1 + sovBatch(uint64(m.RawLength))
+	}
+	if m.CompressedLength != 0 {
+		n += 1 + sovBatch(uint64(m.CompressedLength))
+	}
+	l = len(m.PayloadChecksum)
+	if l > 0 {
+		n += 1 + l + sovBatch(uint64(l))
+	}
+	if len(m.Checksums) > 0 {
+		for _, b := range m.Checksums {
+			l = len(b)
+			n += 1 + l + sovBatch(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovBatch(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozBatch(x uint64) (n int) {
+	return sovBatch(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *CommittedBatchDataHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowBatch
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CommittedBatchDataHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CommittedBatchDataHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			m.Compression = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Compression |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RawLength", wireType)
+			}
+			m.RawLength = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RawLength |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CompressedLength", wireType)
+			}
+			m.CompressedLength = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CompressedLength |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PayloadChecksum", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthBatch
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthBatch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PayloadChecksum = append(m.PayloadChecksum[:0], dAtA[iNdEx:postIndex]...)
+			if m.PayloadChecksum == nil {
+				m.PayloadChecksum = []byte{}
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checksums", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthBatch
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthBatch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Checksums = append(m.Checksums, make([]byte, postIndex-iNdEx))
+			copy(m.Checksums[len(m.Checksums)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipBatch(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthBatch
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipBatch(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowBatch
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowBatch
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthBatch
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupBatch
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthBatch
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthBatch        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowBatch          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupBatch = fmt.Errorf("proto: unexpected end of group")
+)
@@ -1,14 +1,19 @@
 package types
 
 const (
-	HostName   = "host"
-	ChildName  = "child"
-	BatchName  = "batch"
-	MerkleName = "merkle"
+	HostName    = "host"
+	ChildName   = "child"
+	BatchName   = "batch"
+	MerkleName  = "merkle"
+	ClaimerName = "claimer"
+	BridgesName = "bridges"
 
 	DAHostName     = "da_host"
 	DACelestiaName = "da_celestia"
 
+	SecondaryDAHostName     = "secondary_da_host"
+	SecondaryDACelestiaName = "secondary_da_celestia"
+
 	MsgUpdateOracleTypeUrl = "/opinit.opchild.v1.MsgUpdateOracle"
 	MsgAuthzExecTypeUrl    = "/cosmos.authz.v1beta1.MsgExec"
 )
@@ -10,9 +10,15 @@ import (
 type contextKey string
 
 var (
-	ContextKeyErrGrp          = contextKey("ErrGrp")
-	ContextKeyPollingInterval = contextKey("PollingInterval")
-	ContextKeyTxTimeout       = contextKey("TxTimeout")
+	ContextKeyErrGrp                   = contextKey("ErrGrp")
+	ContextKeyPollingInterval          = contextKey("PollingInterval")
+	ContextKeyTxTimeout                = contextKey("TxTimeout")
+	ContextKeyNoAutoRewind             = contextKey("NoAutoRewind")
+	ContextKeyRepairWithdrawalGap      = contextKey("RepairWithdrawalGap")
+	ContextKeyConfirmDestructiveRewind = contextKey("ConfirmDestructiveRewind")
+	ContextKeyRepairDB                 = contextKey("RepairDB")
+	ContextKeyFailFast                 = contextKey("FailFast")
+	ContextKeyShutdown                 = contextKey("Shutdown")
 )
 
 func WithErrGrp(ctx context.Context, errGrp *errgroup.Group) context.Context {
@@ -34,3 +40,76 @@ func PollingInterval(ctx context.Context) time.Duration {
 	}
 	return ctx.Value(ContextKeyPollingInterval).(time.Duration)
 }
+
+func WithNoAutoRewind(ctx context.Context, noAutoRewind bool) context.Context {
+	return context.WithValue(ctx, ContextKeyNoAutoRewind, noAutoRewind)
+}
+
+// NoAutoRewind returns true if the bot only reports the divergence from the chain without rewinding.
+func NoAutoRewind(ctx context.Context) bool {
+	noAutoRewind, ok := ctx.Value(ContextKeyNoAutoRewind).(bool)
+	return ok && noAutoRewind
+}
+
+func WithRepairWithdrawalGap(ctx context.Context, repair bool) context.Context {
+	return context.WithValue(ctx, ContextKeyRepairWithdrawalGap, repair)
+}
+
+// RepairWithdrawalGap returns true if the missing withdrawals are backfilled from the chain
+// instead of halting on a withdrawal sequence gap.
+func RepairWithdrawalGap(ctx context.Context) bool {
+	repair, ok := ctx.Value(ContextKeyRepairWithdrawalGap).(bool)
+	return ok && repair
+}
+
+func WithConfirmDestructiveRewind(ctx context.Context, confirm bool) context.Context {
+	return context.WithValue(ctx, ContextKeyConfirmDestructiveRewind, confirm)
+}
+
+// ConfirmDestructiveRewind returns true if the operator confirmed the deletion of the records on the rewind.
+func ConfirmDestructiveRewind(ctx context.Context) bool {
+	confirm, ok := ctx.Value(ContextKeyConfirmDestructiveRewind).(bool)
+	return ok && confirm
+}
+
+func WithRepairDB(ctx context.Context, repair bool) context.Context {
+	return context.WithValue(ctx, ContextKeyRepairDB, repair)
+}
+
+// RepairDB returns true if the undecodable records found by the integrity check are moved to the quarantine prefix.
+func RepairDB(ctx context.Context) bool {
+	repair, ok := ctx.Value(ContextKeyRepairDB).(bool)
+	return ok && repair
+}
+
+func WithFailFast(ctx context.Context, failFast bool) context.Context {
+	return context.WithValue(ctx, ContextKeyFailFast, failFast)
+}
+
+// FailFast returns true if the failure of a bridge instance stops the other bridge instances of the process.
+func FailFast(ctx context.Context) bool {
+	failFast, ok := ctx.Value(ContextKeyFailFast).(bool)
+	return ok && failFast
+}
+
+func WithShutdown(ctx context.Context, shutdown <-chan struct{}) context.Context {
+	return context.WithValue(ctx, ContextKeyShutdown, shutdown)
+}
+
+// Shutdown returns the channel closed when the graceful shutdown begins. The loopers stop taking
+// new work when it is closed, while the work in progress completes with the context still alive.
+// It returns nil, which is never closed, if the context has no shutdown channel.
+func Shutdown(ctx context.Context) <-chan struct{} {
+	shutdown, _ := ctx.Value(ContextKeyShutdown).(<-chan struct{})
+	return shutdown
+}
+
+// ShuttingDown returns true if the graceful shutdown has begun.
+func ShuttingDown(ctx context.Context) bool {
+	select {
+	case <-Shutdown(ctx):
+		return true
+	default:
+		return false
+	}
+}
@@ -12,6 +12,18 @@ type RawKV struct {
 	Value []byte
 }
 
+// PrefixStats is the approximate usage of the keys of a prefix in the database.
+type PrefixStats struct {
+	Prefix string `json:"prefix"`
+	// KeyCount is exact if the keys are not sampled, otherwise it is estimated from the sampled keys.
+	KeyCount uint64 `json:"key_count"`
+	// RawSize is the size of the keys and the values, which is estimated from the sampled keys as well.
+	RawSize uint64 `json:"raw_size"`
+	// DiskSize is the approximate size on disk after the compression, excluding the data not flushed yet.
+	DiskSize uint64 `json:"disk_size"`
+	Sampled  bool   `json:"sampled"`
+}
+
 type DB interface {
 	Get([]byte) ([]byte, error)
 	Set([]byte, []byte) error
@@ -21,10 +33,14 @@ type DB interface {
 	Close() error
 	PrefixedIterate([]byte, []byte, func([]byte, []byte) (bool, error)) error
 	PrefixedReverseIterate([]byte, []byte, func([]byte, []byte) (bool, error)) error
+	PrefixedRangeIterate([]byte, []byte, []byte, func([]byte, []byte) (bool, error)) error
 	SeekPrevInclusiveKey([]byte, []byte) ([]byte, []byte, error)
+	PrefixedDeleteRange([]byte, []byte, []byte) (int, error)
 	WithPrefix([]byte) DB
 	PrefixedKey([]byte) []byte
 	UnprefixedKey([]byte) []byte
 	GetPath() string
 	GetPrefix() []byte
+	Snapshot(string) error
+	Stats(...[]byte) ([]PrefixStats, error)
 }
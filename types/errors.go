@@ -1,7 +1,41 @@
 package types
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var ErrKeyNotSet = errors.New("key not set")
 var ErrAccountSequenceMismatch = errors.New("account sequence mismatch")
 var ErrTxNotFound = errors.New("tx not found")
+var ErrTxExpired = errors.New("tx expired")
+var ErrInsufficientBalance = errors.New("insufficient balance")
+var ErrHeightPruned = errors.New("height pruned")
+
+// ErrPermanentTxFailure is returned when the tx of the msgs can never be accepted, e.g. the tx is too large.
+var ErrPermanentTxFailure = errors.New("permanent tx failure")
+
+// ErrOutputConflict is returned when a different output root is already proposed at the output index.
+var ErrOutputConflict = errors.New("output conflict")
+
+// ErrDestructiveRewindNotConfirmed is returned when the bot refuses to delete the records without the confirmation.
+var ErrDestructiveRewindNotConfirmed = errors.New("destructive rewind not confirmed")
+
+// ErrWithdrawalSequenceGap is the sentinel error matched by WithdrawalSequenceGapError.
+var ErrWithdrawalSequenceGap = errors.New("withdrawal sequence gap")
+
+// WithdrawalSequenceGapError is returned when the l2 sequence of a withdrawal is not the next sequence
+// of the working tree, which means a withdrawal is missed or handled twice.
+type WithdrawalSequenceGapError struct {
+	Expected uint64
+	Received uint64
+	Height   int64
+}
+
+func (e *WithdrawalSequenceGapError) Error() string {
+	return fmt.Sprintf("%s: height: %d, expected: %d, received: %d", ErrWithdrawalSequenceGap.Error(), e.Height, e.Expected, e.Received)
+}
+
+func (e *WithdrawalSequenceGapError) Unwrap() error {
+	return ErrWithdrawalSequenceGap
+}
@@ -0,0 +1,18 @@
+package move
+
+import (
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RegisterInterfaces registers the msgs of the move module sent by the users.
+func RegisterInterfaces(registry codectypes.InterfaceRegistry) {
+	registry.RegisterImplementations(
+		(*sdk.Msg)(nil),
+		&MsgPublish{},
+		&MsgExecute{},
+		&MsgExecuteJSON{},
+		&MsgScript{},
+		&MsgScriptJSON{},
+	)
+}
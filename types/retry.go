@@ -10,6 +10,11 @@ import (
 const MaxRetryCount = 7
 
 func SleepWithRetry(ctx context.Context, retry int) bool {
+	return SleepWithBackoff(ctx, 2*time.Second, retry)
+}
+
+// SleepWithBackoff sleeps `base * 2^retry` with jitter. It returns true if the context is done while sleeping.
+func SleepWithBackoff(ctx context.Context, base time.Duration, retry int) bool {
 	// to avoid to sleep too long
 	if retry > MaxRetryCount {
 		retry = MaxRetryCount
@@ -17,9 +22,9 @@ func SleepWithRetry(ctx context.Context, retry int) bool {
 		return false
 	}
 
-	sleepTime := 2 * math.Exp2(float64(retry))
-	sleepTime += rand.Float64() * float64(sleepTime) * 0.5 //nolint:all
-	timer := time.NewTimer(time.Duration(sleepTime) * time.Second)
+	sleepTime := float64(base) * math.Exp2(float64(retry))
+	sleepTime += rand.Float64() * sleepTime * 0.5 //nolint:all
+	timer := time.NewTimer(time.Duration(sleepTime))
 	defer timer.Stop()
 	select {
 	case <-ctx.Done():
@@ -0,0 +1,30 @@
+package wasm
+
+import (
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RegisterInterfaces registers the msgs of the wasm module of the minitia.
+func RegisterInterfaces(registry codectypes.InterfaceRegistry) {
+	registry.RegisterImplementations(
+		(*sdk.Msg)(nil),
+		&MsgStoreCode{},
+		&MsgInstantiateContract{},
+		&MsgInstantiateContract2{},
+		&MsgExecuteContract{},
+		&MsgMigrateContract{},
+		&MsgUpdateAdmin{},
+		&MsgClearAdmin{},
+		&MsgUpdateInstantiateConfig{},
+		&MsgUpdateParams{},
+		&MsgSudoContract{},
+		&MsgPinCodes{},
+		&MsgUnpinCodes{},
+		&MsgStoreAndInstantiateContract{},
+		&MsgAddCodeUploadParamsAddresses{},
+		&MsgRemoveCodeUploadParamsAddresses{},
+		&MsgStoreAndMigrateContract{},
+		&MsgUpdateContractLabel{},
+	)
+}
@@ -0,0 +1,53 @@
+package wasm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RawContractMessage is the json encoded msg of a contract.
+type RawContractMessage []byte
+
+func (r RawContractMessage) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(r).MarshalJSON()
+}
+
+func (r *RawContractMessage) UnmarshalJSON(b []byte) error {
+	if r == nil {
+		return errors.New("unmarshalJSON on nil pointer")
+	}
+	*r = append((*r)[0:0], b...)
+	return nil
+}
+
+// Bytes returns the raw bytes of the msg.
+func (r RawContractMessage) Bytes() []byte {
+	return r
+}
+
+func (r RawContractMessage) Equal(o RawContractMessage) bool {
+	return bytes.Equal(r.Bytes(), o.Bytes())
+}
+
+func (a AccessType) String() string {
+	switch a {
+	case AccessTypeNobody:
+		return "Nobody"
+	case AccessTypeEverybody:
+		return "Everybody"
+	case AccessTypeAnyOfAddresses:
+		return "AnyOfAddresses"
+	}
+	return "Unspecified"
+}
+
+func (p Params) String() string {
+	out, err := yaml.Marshal(p)
+	if err != nil {
+		panic(err)
+	}
+	return string(out)
+}